@@ -0,0 +1,303 @@
+// Package app 组装与 Web 框架无关的部分
+// 本文件实现 GoAdmin 引擎的配置、数据模型和后台任务的初始化、后台页面和接口的路由以及退出时的清理，
+// 根目录的 main 包基于 Gin，cmd 下的 Echo、Fiber、Chi 版本共用这些代码，只有创建路由器和启动服务器的部分不同
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/engine"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/redis/go-redis/v9"
+)
+
+// ShutdownTimeout 收到退出信号后等待请求和后台任务结束的最长时间
+// 容器编排通常在发送 SIGTERM 30 秒后强制结束进程，这里留出关闭数据库连接的时间
+const ShutdownTimeout = 25 * time.Second
+
+// Configure 按启动参数配置 GoAdmin 引擎，返回值通过 Use 挂载到 Web 框架的路由器上
+//
+// 参数:
+//   - eng: GoAdmin 引擎，通常为 engine.Default()
+//   - opts: 启动参数，决定配置文件路径，以及覆盖配置文件的数据库 DSN 和路由前缀
+//
+// 使用示例:
+//
+//	eng := engine.Default()
+//	if err := app.Configure(eng, opts).Use(r); err != nil {
+//	    panic(err)
+//	}
+//
+// 注意事项:
+//   - 配置文件不存在或格式错误时 panic，与 eng.AddConfigFromYAML 相同
+func Configure(eng *engine.Engine, opts Options) *engine.Engine {
+	// 添加 Chart.js 图表组件支持，仪表板和图表示例页面使用
+	template.AddComp(chartjs.NewChart())
+
+	// ReadFromYaml: 从 opts.ConfigPath 读取配置文件，opts.apply 再用命令行参数和环境变量覆盖数据库 DSN 和路由前缀
+	// AddGenerators: 注册数据表生成器，用于自动生成管理界面；ManagerGenerators 替换 GoAdmin 自带的管理员表格，重置密码后踢出该管理员的登录
+	// AddGenerator: 添加外部表生成器
+	cfg := config.ReadFromYaml(opts.ConfigPath)
+	opts.apply(&cfg)
+	return eng.AddConfig(&cfg).
+		AddGenerators(tables.Generators, tables.ManagerGenerators(eng.DefaultConnection)).
+		AddGenerator("external", tables.GetExternalTable)
+}
+
+// Setup 初始化数据模型并读取本项目的各配置项，在 eng.Use 之后调用
+//
+// 参数:
+//   - eng: 已挂载到路由器上的 GoAdmin 引擎
+//   - opts: 启动参数
+//
+// 返回值:
+//   - error: 读取配置失败时返回错误；数据库连接或迁移失败时 models.Init 直接 panic
+func Setup(eng *engine.Engine, opts Options) error {
+	// 初始化数据库模型
+	// 使用配置文件中 default 数据库的连接，支持 sqlite、mysql、postgresql 和 mssql
+	// ormCfg: 配置文件中 orm 配置项的日志级别、预编译语句和连接池设置
+	// 注意: 必须在使用任何数据库操作之前调用
+	ormCfg, err := models.LoadORMConfig(opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+	models.Init(eng.DefaultConnection(), ormCfg)
+
+	// 配置仪表板统计数据缓存
+	// 默认使用进程内存缓存；设置 REDIS_ADDR 环境变量（如 127.0.0.1:6379）后改用 Redis，
+	// 多个实例部署时可以共享缓存并同步失效
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		models.SetCache(models.NewRedisCache(&redis.Options{
+			Addr:     addr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+		}))
+	}
+
+	cfg := config.GetService(eng.Services.Get("config"))
+
+	// goadmin_site 表中保存了第一次启动时的路由前缀，eng.Use 合并后以启动参数为准
+	// 路由已按启动参数的前缀注册，这里只需更新菜单等链接使用的前缀
+	if opts.Prefix != "" {
+		if err := keepURLPrefix(cfg, opts.Prefix); err != nil {
+			return err
+		}
+	}
+
+	// 使系统设置中的站点标题和 Logo 生效，之后在站点设置页面或系统设置表格中修改时自动更新
+	tables.UseSiteConfig(context.Background(), cfg)
+
+	loaders := []func(string) error{
+		// 文章表格的配置，决定文章内容使用富文本还是 Markdown 编辑器
+		tables.LoadPostsConfigFromYAML,
+		// 导出 PDF 使用的字体，未配置时导出的 PDF 只能显示西文字符
+		tables.LoadPDFConfigFromYAML,
+		// 用户头像的缩略图尺寸和存储方式（本地上传目录或 S3）
+		tables.LoadAvatarConfigFromYAML,
+		// 表单上传文件（表单页面的证书、文章内容中的图片）的大小限制和存储方式（本地上传目录或 S3）
+		tables.LoadUploadConfigFromYAML,
+		// 外部数据表格的远程接口，未配置时显示示例数据
+		tables.LoadExternalConfigFromYAML,
+		// 发送定时导出邮件的 SMTP 服务器
+		tables.LoadMailConfigFromYAML,
+		// 第三方账号登录的平台凭据和自动创建管理员的设置，未配置时登录页面只有密码登录
+		pages.LoadOAuthConfigFromYAML,
+		// JSON 接口登录令牌的签名密钥和有效期，未配置密钥时表格接口只接受 API 密钥
+		tables.LoadAPIConfigFromYAML,
+	}
+	for _, load := range loaders {
+		if err := load(opts.ConfigPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartWorkers 启动后台任务，ctx 取消后停止
+//
+// 启动的任务:
+//   - 每日计数汇总，为仪表板的日历热力图准备数据
+//   - 每小时统计数据汇总，为销售折线图和环比描述准备数据
+//   - 系统资源采集，每分钟采样一次 CPU、内存和协程数，为 CPU 信息框和系统资源组件准备数据
+//   - 定时导出的调度，每分钟检查一次到期的定时导出，导出后通过邮件发送
+func StartWorkers(ctx context.Context, eng *engine.Engine) {
+	models.StartDailyCountsRollup(ctx, 10*time.Minute)
+	models.StartStatisticsRollup(ctx, 5*time.Minute)
+	models.StartSystemStatsCollector(ctx, time.Minute)
+	tables.StartExportScheduler(ctx, eng.DefaultConnection(), time.Minute)
+}
+
+// RegisterRoutes 注册后台的自定义页面和接口
+// 这些路由都通过 GoAdmin 引擎注册，经过 GoAdmin 的登录验证，与使用哪个 Web 框架无关；panic 由 recover.go 恢复
+// 除表格的 JSON 接口外都注册在后台的路由前缀之下（config.Url），与 GoAdmin 自带的页面一起随 -prefix 启动参数移动
+func RegisterRoutes(eng *engine.Engine, opts Options) error {
+	// 页面和接口 panic 时记录调用栈，显示带有错误编号的提示，见 recover.go
+	routes := recoveredRoutes{eng}
+
+	// 注册 HTML 页面路由
+	// DashboardPage: 仪表板页面，显示系统概览信息
+	routes.HTML("GET", config.Url("/"), pages.DashboardPage)
+	// 主题仪表板：默认为销售、运维和市场，各自组合不同的组件，通过页面上的下拉菜单切换
+	// config.yml 中定义了 dashboards 配置项时以配置为准，每个仪表板按其 url 注册路由
+	if err := pages.LoadDashboardsFromYAML(opts.ConfigPath); err != nil {
+		return err
+	}
+	for _, d := range pages.Dashboards {
+		if d.URL != "/" {
+			routes.HTML("GET", config.Url(d.URL), pages.NewDashboardPage(d.Name))
+		}
+	}
+	// SaveDashboardLayout: 保存当前管理员的仪表板布局（拖拽排序、隐藏、调整宽度）
+	routes.Data("POST", config.Url("/dashboard/layout"), pages.SaveDashboardLayout)
+	// DeferredWidget: 返回延迟加载组件（图表、表格）的内容，仪表板页面显示后通过 AJAX 请求
+	routes.Data("GET", config.Url("/dashboard/widget"), pages.DeferredWidget)
+	// DashboardAPI: 以 JSON 返回仪表板数据，支持与页面相同的 ?from=&to= 参数
+	routes.Data("GET", config.Url("/api/dashboard"), pages.DashboardAPI)
+	// StatisticsAPI: 其他服务推送点赞、销售额、新会员计数，推送后仪表板立即可见
+	// 不使用后台登录会话，按 STATISTICS_API_TOKEN 环境变量中的 token 鉴权，未设置时不注册该接口
+	if token := os.Getenv("STATISTICS_API_TOKEN"); token != "" {
+		routes.Data("POST", config.Url("/api/statistics"), pages.StatisticsAPI(token), true)
+	}
+	// UnreadNotifications: 当前管理员的未读通知数，顶部导航栏的铃铛定时读取
+	routes.Data("GET", config.Url(pages.UnreadNotificationsURL), pages.UnreadNotifications)
+	// 顶部导航栏的通知铃铛，显示未读数，点击进入通知中心
+	eng.AddNavButtons(pages.NotificationBellTitle, icon.Bell, pages.NotificationBell())
+	// NotificationsPage: 当前管理员的通知中心；其余三个接口为通知中心中的标为已读、全部标为已读和删除
+	routes.HTML("GET", config.Url(pages.NotificationsURL), pages.NotificationsPage)
+	routes.Data("POST", config.Url(pages.NotificationReadURL), pages.ReadNotification)
+	routes.Data("POST", config.Url(pages.NotificationReadAllURL), pages.ReadAllNotifications)
+	routes.Data("POST", config.Url(pages.NotificationDeleteURL), pages.DeleteNotification)
+	// SearchPage: 全局搜索，同时搜索用户、文章和作者；顶部导航栏的搜索按钮和搜索框提交到该页面
+	routes.HTML("GET", config.Url(pages.SearchURL), pages.SearchPage)
+	eng.AddNavButtons("", icon.Search, pages.SearchButton())
+	// MarkdownPreview: 文章 Markdown 编辑器的实时预览，与列表中的显示使用同一个渲染器
+	routes.Data("POST", config.Url(tables.MarkdownPreviewURL), tables.MarkdownPreview)
+	// AvatarUpload: 用户表单和个人资料页面中裁剪后的头像，检查图片类型和尺寸后保存为缩略图
+	routes.Data("POST", config.Url(tables.AvatarUploadURL), tables.AvatarUpload)
+	// UserDuplicates: 新增用户提交前按电话和邮箱查找已有的用户
+	routes.Data("GET", config.Url(tables.UserDuplicatesURL), tables.UserDuplicates)
+	// ProfilePhotoUpload: 用户档案表单中照片的多图上传，每张照片单独上传以显示进度
+	routes.Data("POST", config.Url(tables.ProfilePhotoUploadURL), tables.ProfilePhotoUpload)
+	// ReorderCategories: 商品分类列表中拖拽排序后保存同级分类的顺序
+	routes.Data("POST", config.Url(tables.CategoryReorderURL), tables.ReorderCategories)
+	// Import: 表格"导入"弹窗的读取表头、试运行和开始导入；ImportProgress: 弹窗轮询导入进度
+	routes.Data("POST", config.Url(tables.ImportURL), tables.Import)
+	routes.Data("GET", config.Url(tables.ImportProgressURL), tables.ImportProgress)
+	// ExportPDF: 表格顶部"PDF"按钮的导出，按列表当前的筛选和排序生成 PDF
+	routes.Data("GET", config.Url(tables.PDFExportURL), tables.ExportPDF)
+	// ExportData: "导出"下拉菜单中的 JSON Lines 和 XML，按列表当前的筛选和排序导出
+	routes.Data("GET", config.Url(tables.DataExportURL), tables.ExportData)
+	// KanbanPage: 任务看板，按状态分列显示任务；MoveTask: 拖动卡片到其他列后保存任务的状态
+	routes.HTML("GET", config.Url("/kanban"), pages.KanbanPage)
+	routes.Data("POST", config.Url(tables.TaskMoveURL), tables.MoveTask)
+	// ReportsPage: 报表页面，选择报表、填写参数后显示图表和结果表格；ExportReport: 报表结果的 CSV、Excel 和 PDF 导出
+	routes.HTML("GET", config.Url(pages.ReportsURL), pages.ReportsPage)
+	routes.Data("GET", config.Url(pages.ReportExportURL), pages.ExportReport)
+	// ChartsPage: 图表示例，演示柱状图、环形图、雷达图、极地图、散点图和混合图
+	routes.HTML("GET", config.Url(pages.ChartsURL), pages.ChartsPage)
+	// SiteSettingsPage: 站点设置，修改站点标题、Logo、每页条数和维护模式；SaveSiteSettings: 保存站点设置表单
+	routes.HTML("GET", config.Url(pages.SiteSettingsURL), pages.SiteSettingsPage)
+	routes.Data("POST", config.Url(pages.SiteSettingsURL), pages.SaveSiteSettings)
+	// AccountPage: 当前管理员的个人资料；SaveAccount: 保存名称、邮箱、头像和界面语言；ChangeAccountPassword: 修改登录密码
+	routes.HTML("GET", config.Url(pages.AccountURL), pages.AccountPage)
+	routes.Data("POST", config.Url(pages.AccountURL), pages.SaveAccount)
+	routes.Data("POST", config.Url(pages.AccountPasswordURL), pages.ChangeAccountPassword)
+	// 顶部导航栏的个人资料按钮
+	eng.AddNavButtons("", icon.User, pages.AccountButton())
+	// 第三方账号登录（config.yml 的 oauth 配置项）：跳转到第三方平台授权和授权后的回调，不需要登录
+	// 配置了第三方平台时，登录页面改用 html/login.tmpl，在密码登录下方显示对应的登录按钮
+	if names := pages.OAuthProviderNames(); len(names) > 0 {
+		for _, name := range names {
+			routes.Data("GET", pages.OAuthLoginURL(name), pages.OAuthLogin(name), true)
+			routes.Data("GET", pages.OAuthCallbackURL(name), pages.OAuthCallback(name, eng.DefaultConnection()), true)
+		}
+		comp, err := pages.OAuthLoginComponent("./html/login.tmpl")
+		if err != nil {
+			return err
+		}
+		template.AddLoginComp(comp)
+	}
+	// 表格的 JSON 接口：不使用后台登录会话，按"API 密钥"表格中签发的密钥或登录接口签发的访问令牌鉴权，
+	// 以签发人或登录的管理员的权限访问表格
+	// APIList / APICreate: 列表和新增；APIGet / APIUpdate / APIDelete: 单条记录的读取、修改和删除
+	apiAuth := tables.APIAuth(eng.DefaultConnection())
+	routes.Data("GET", tables.APITableURL, apiAuth(tables.APIList), true)
+	routes.Data("POST", tables.APITableURL, apiAuth(tables.APICreate), true)
+	routes.Data("GET", tables.APIRecordURL, apiAuth(tables.APIGet), true)
+	routes.Data("PUT", tables.APIRecordURL, apiAuth(tables.APIUpdate), true)
+	routes.Data("DELETE", tables.APIRecordURL, apiAuth(tables.APIDelete), true)
+	// JSON 接口的登录（config.yml 的 api 配置项）：用登录名和密码换取访问令牌，刷新令牌和退出登录，不需要登录
+	if tables.APILoginEnabled() {
+		routes.Data("POST", tables.APILoginURL, tables.APILogin, true)
+		routes.Data("POST", tables.APIRefreshURL, tables.APIRefresh(eng.DefaultConnection()), true)
+		routes.Data("POST", tables.APILogoutURL, tables.APILogout, true)
+	}
+	// GetFormContent: 表单页面，展示各种表单字段类型
+	// 包含基础输入、日期时间、文件上传、富文本、选择控件等多种表单组件
+	// 使用标签页分组，分为input、select、multi三个标签页
+	routes.HTML("GET", config.Url("/form"), pages.GetFormContent)
+	// SubmitForm: 表单页面的提交，校验后保存到 demo_form_submissions；AJAX 提交时返回 JSON，否则跳转回表单页面
+	routes.Data("POST", config.Url("/form/update"), pages.SubmitForm)
+	// SaveFormDraft / DeleteFormDraft: 表单页面定时自动保存的草稿，以及丢弃草稿
+	routes.Data("POST", config.Url(pages.FormDraftURL), pages.SaveFormDraft)
+	routes.Data("POST", config.Url(pages.FormDraftDeleteURL), pages.DeleteFormDraft)
+	// DynamicFormPage / SubmitDynamicForm: 填写在"动态表单"中设计的表单，提交的内容保存到 form_submissions
+	routes.HTML("GET", config.Url(tables.DynamicFormURL), pages.DynamicFormPage)
+	routes.Data("POST", config.Url(tables.DynamicFormSubmitURL), pages.SubmitDynamicForm)
+	// GetTableContent: 表格页面，用于数据展示和管理
+	routes.HTML("GET", config.Url("/table"), pages.GetTableContent)
+	// 自定义模板文件路由
+	// 使用 Go 模板引擎渲染 hello.tmpl 文件
+	eng.HTMLFile("GET", config.Url("/hello"), "./html/hello.tmpl", map[string]interface{}{
+		"msg": "你好世界",
+	})
+
+	return nil
+}
+
+// WaitSignal 阻塞直到收到 SIGINT（Ctrl+C）或 SIGTERM
+// docker stop、Kubernetes 等发送 SIGTERM，两种信号都执行同样的关闭流程
+func WaitSignal() os.Signal {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	return <-quit
+}
+
+// Shutdown 在 HTTP 服务器关闭之后等待后台任务结束，再关闭数据库连接
+//
+// 参数:
+//   - ctx: 等待后台任务的超时，通常与关闭 HTTP 服务器共用 ShutdownTimeout
+//   - eng: GoAdmin 引擎，关闭其数据库连接
+//
+// 返回值:
+//   - []error: 等待超时和关闭连接失败的错误，只需记录日志
+//
+// 注意事项:
+//   - 调用前应先取消 StartWorkers 的 ctx，并关闭 HTTP 服务器，处理中的请求启动的任务同样会被等待
+//   - GoAdmin 的 info、error、access 日志和标准库 log 都直接写入文件或标准输出，没有需要刷新的缓冲
+func Shutdown(ctx context.Context, eng *engine.Engine) []error {
+	var errs []error
+	// 等待后台任务结束：汇总和调度、手动执行的导出、导入和页面访问记录
+	if err := models.WaitWorkers(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	// 关闭本项目的 ORM 连接池、只读副本和 Redis 缓存，以及 GoAdmin 自身的数据库连接
+	if err := models.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, err := range eng.DefaultConnection().Close() {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
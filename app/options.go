@@ -0,0 +1,150 @@
+// Package app 组装与 Web 框架无关的部分
+// 本文件实现分层的启动配置：命令行参数优先于环境变量，环境变量优先于配置文件
+//
+// 用法:
+//
+//	go run . -port 8080                                  监听 8080 端口
+//	GOADMIN_DB_DSN="..." go run .                        使用指定的 default 数据库
+//	go run . -config ./config.prod.yml migrate up       对另一个配置文件中的数据库执行迁移
+//
+// 可以覆盖的配置项:
+//
+//	命令行参数    环境变量          配置文件
+//	-config      -                 -（默认 ./config.yml）
+//	-port        GOADMIN_PORT      server.port（默认 9033）
+//	-db-dsn      GOADMIN_DB_DSN    database.default.dsn
+//	-prefix      GOADMIN_PREFIX    prefix
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultPort 配置文件中没有 server.port 时监听的端口
+const defaultPort = 9033
+
+// Options 启动参数，由 ParseOptions 按命令行参数、环境变量的顺序确定
+// 为空的项不覆盖配置文件
+type Options struct {
+	// ConfigPath 配置文件路径，GoAdmin 和本项目的各配置项都从该文件读取
+	ConfigPath string
+
+	// Port 监听的端口，为空时使用配置文件的 server.port
+	Port string
+
+	// DSN default 数据库的连接字符串，设置后 default 数据库除驱动外的连接信息都以此为准
+	DSN string
+
+	// Prefix 后台的路由前缀，如 admin
+	Prefix string
+}
+
+// ParseOptions 解析子命令之前的命令行参数
+//
+// 参数:
+//   - args: 程序名之后的命令行参数
+//
+// 返回值:
+//   - Options: 启动参数，命令行参数没有设置的项取对应的环境变量
+//   - []string: 剩余的参数，第一个为子命令（migrate、gen），没有时启动服务器
+//   - error: 端口无效时返回错误
+func ParseOptions(args []string) (Options, []string, error) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	opts := Options{}
+	fs.StringVar(&opts.ConfigPath, "config", "./config.yml", "配置文件路径")
+	fs.StringVar(&opts.Port, "port", "", "监听的端口，覆盖环境变量 GOADMIN_PORT 和配置文件的 server.port")
+	fs.StringVar(&opts.DSN, "db-dsn", "", "default 数据库的连接字符串，覆盖环境变量 GOADMIN_DB_DSN 和配置文件")
+	fs.StringVar(&opts.Prefix, "prefix", "", "后台的路由前缀，覆盖环境变量 GOADMIN_PREFIX 和配置文件的 prefix")
+	_ = fs.Parse(args)
+
+	opts.Port = firstNonEmpty(opts.Port, os.Getenv("GOADMIN_PORT"))
+	opts.DSN = firstNonEmpty(opts.DSN, os.Getenv("GOADMIN_DB_DSN"))
+	opts.Prefix = strings.Trim(firstNonEmpty(opts.Prefix, os.Getenv("GOADMIN_PREFIX")), "/")
+	if opts.Port != "" {
+		if _, err := parsePort(opts.Port); err != nil {
+			return opts, nil, err
+		}
+	}
+	return opts, fs.Args(), nil
+}
+
+// apply 用启动参数覆盖从配置文件读取的 GoAdmin 配置
+func (o Options) apply(cfg *config.Config) {
+	if o.DSN != "" {
+		if cfg.Databases == nil {
+			cfg.Databases = config.DatabaseList{}
+		}
+		d := cfg.Databases["default"]
+		d.Dsn = o.DSN
+		cfg.Databases["default"] = d
+	}
+	if o.Prefix != "" {
+		cfg.UrlPrefix = o.Prefix
+	}
+}
+
+// keepURLPrefix 把 GoAdmin 配置中的路由前缀恢复为启动参数指定的前缀
+// eng.Use 会用 goadmin_site 表中保存的配置覆盖 UrlPrefix，菜单、表单等链接使用该字段
+func keepURLPrefix(cfg *config.Config, prefix string) error {
+	// Config.Update 会重置没有传入的结构体字段（日志、动画等），先取出完整的配置再修改
+	m := cfg.ToMap()
+	m["url_prefix"] = prefix
+	return cfg.Update(m)
+}
+
+// ListenAddr 返回服务器的监听地址
+// 没有通过命令行参数或环境变量指定端口时读取配置文件的 server.port，也没有配置时使用 defaultPort
+func (o Options) ListenAddr() (string, error) {
+	if o.Port != "" {
+		port, err := parsePort(o.Port)
+		return ":" + strconv.Itoa(port), err
+	}
+
+	content, err := ioutil.ReadFile(o.ConfigPath)
+	if err != nil {
+		return "", err
+	}
+	cfg := struct {
+		Server struct {
+			Port int `yaml:"port"`
+		} `yaml:"server"`
+	}{}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return "", fmt.Errorf("解析服务器配置失败: %v", err)
+	}
+	port := cfg.Server.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	if port < 0 || port > 65535 {
+		return "", fmt.Errorf("server.port 无效: %d", port)
+	}
+	return ":" + strconv.Itoa(port), nil
+}
+
+// parsePort 解析命令行参数或环境变量中的端口
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("端口无效: %s", s)
+	}
+	return port, nil
+}
+
+// firstNonEmpty 返回第一个非空的值
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+// Package app 组装与 Web 框架无关的部分
+// 本文件为自定义页面和接口恢复 panic：记录错误和调用栈，页面显示带有错误编号的提示面板，接口返回 JSON，
+// 不把 panic 的原始信息（可能包含 SQL、文件路径等）显示给管理员
+package app
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/engine"
+	"github.com/purpose168/GoAdmin/modules/trace"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// recoveredRoutes 与 eng.HTML、eng.Data 用法相同，注册的页面和接口 panic 时由 recoverPanel、recoverHandler 恢复
+// GoAdmin 自身也会恢复这些 panic，但会把原始信息直接显示在页面上
+type recoveredRoutes struct {
+	eng *engine.Engine
+}
+
+// HTML 注册页面，见 engine.Engine.HTML
+func (r recoveredRoutes) HTML(method, url string, fn types.GetPanelInfoFn, noAuth ...bool) {
+	r.eng.HTML(method, url, recoverPanel(fn), noAuth...)
+}
+
+// Data 注册接口，见 engine.Engine.Data
+func (r recoveredRoutes) Data(method, url string, h context.Handler, noAuth ...bool) {
+	r.eng.Data(method, url, recoverHandler(h), noAuth...)
+}
+
+// recoverPanel 包装 eng.HTML 的页面函数，panic 时返回错误，由 GoAdmin 显示为警告面板
+func recoverPanel(fn types.GetPanelInfoFn) types.GetPanelInfoFn {
+	return func(ctx *context.Context) (panel types.Panel, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("页面加载出错，请稍后再试；如果问题持续出现，请把错误编号 %s 提供给系统管理员", logPanic(ctx, p))
+			}
+		}()
+		return fn(ctx)
+	}
+}
+
+// recoverHandler 包装 eng.Data 的处理器，panic 时 AJAX 请求返回 {"code": 500, "msg": ...}，其他请求返回错误提示
+func recoverHandler(h context.Handler) context.Handler {
+	return func(ctx *context.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			msg := fmt.Sprintf("处理请求出错（错误编号 %s）", logPanic(ctx, p))
+			if ctx.WantJSON() || ctx.Headers("X-Requested-With") == "XMLHttpRequest" {
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"code": http.StatusInternalServerError,
+					"msg":  msg,
+				})
+				return
+			}
+			ctx.HTML(http.StatusInternalServerError, html.EscapeString(msg))
+		}()
+		h(ctx)
+	}
+}
+
+// logPanic 记录 panic 的错误和调用栈，返回错误编号
+// GoAdmin 的页面已有 trace ID 时沿用，便于与 GoAdmin 的日志对照
+func logPanic(ctx *context.Context, p interface{}) string {
+	id := trace.GetTraceID(ctx)
+	if id == "" {
+		id = trace.GenerateTraceID()
+	}
+	log.Printf("处理请求 %s %s 时 panic（错误编号 %s）: %v\n%s", ctx.Method(), ctx.Path(), id, p, debug.Stack())
+	return id
+}
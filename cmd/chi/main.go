@@ -0,0 +1,86 @@
+//go:build chi
+
+// GoAdmin 示例项目 - Chi 版本
+// 与根目录基于 Gin 的版本使用同一套表格、页面和配置文件，只是把 GoAdmin 挂载到 Chi 上
+//
+// 用法（在项目根目录执行，配置文件和上传目录按相对路径读取）:
+//
+//	go run -tags chi ./cmd/chi -port 9033
+//
+// 与 Gin 版本的区别:
+//   - 只提供后台和 /uploads，页面访问统计、维护模式、界面语言、/healthz、/metrics、pprof 和公开站点
+//     基于 Gin 的中间件实现，这里没有注册
+//   - 不支持 migrate 和 gen 子命令，使用 go run . migrate 执行
+//   - Chi 基于 net/http，与 Gin 版本一样使用 http.Server 启动和关闭
+
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+
+	_ "github.com/purpose168/GoAdmin-themes/sword"                // Sword UI 主题
+	_ "github.com/purpose168/GoAdmin/adapter/chi"                 // Chi Web 框架适配器
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mssql"    // SQL Server 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mysql"    // MySQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/postgres" // PostgreSQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/sqlite"   // SQLite 数据库驱动
+
+	"github.com/go-chi/chi"
+	"github.com/purpose168/GoAdmin-example/app"
+	"github.com/purpose168/GoAdmin-example/middleware"
+	"github.com/purpose168/GoAdmin/engine"
+)
+
+func main() {
+	opts, args, err := app.ParseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(args) > 0 {
+		log.Fatalf("Chi 版本不支持子命令 %s，请在项目根目录执行 go run . %s", args[0], args[0])
+	}
+
+	r := chi.NewRouter()
+
+	eng := engine.Default()
+	if err := app.Configure(eng, opts).Use(r); err != nil {
+		log.Fatal(err)
+	}
+	if err := app.Setup(eng, opts); err != nil {
+		log.Fatal(err)
+	}
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	app.StartWorkers(workersCtx, eng)
+
+	r.Handle("/uploads/*", middleware.UploadGuardHandler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads")))))
+	if err := app.RegisterRoutes(eng, opts); err != nil {
+		log.Fatal(err)
+	}
+
+	addr, err := opts.ListenAddr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		log.Printf("监听 %s\n", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("监听: %s\n", err)
+		}
+	}()
+
+	log.Printf("收到 %s，开始关闭\n", app.WaitSignal())
+	ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout)
+	defer cancel()
+	stopWorkers()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("服务器关闭: %s\n", err)
+	}
+	for _, err := range app.Shutdown(ctx, eng) {
+		log.Printf("关闭: %s\n", err)
+	}
+	log.Println("服务器退出")
+}
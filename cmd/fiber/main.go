@@ -0,0 +1,90 @@
+//go:build fiber
+
+// GoAdmin 示例项目 - Fiber 版本
+// 与根目录基于 Gin 的版本使用同一套表格、页面和配置文件，只是把 GoAdmin 挂载到 Fiber 上
+//
+// 用法（在项目根目录执行，配置文件和上传目录按相对路径读取）:
+//
+//	go run -tags fiber ./cmd/fiber -port 9033
+//
+// 与 Gin 版本的区别:
+//   - 只提供后台和 /uploads，页面访问统计、维护模式、界面语言、/healthz、/metrics、pprof 和公开站点
+//     基于 Gin 的中间件实现，这里没有注册
+//   - 不支持 migrate 和 gen 子命令，使用 go run . migrate 执行
+//   - Fiber 基于 fasthttp，GoAdmin 的适配器在每个请求中把 fasthttp 的请求转换为 net/http 的请求
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	_ "github.com/purpose168/GoAdmin-themes/sword"                // Sword UI 主题
+	_ "github.com/purpose168/GoAdmin/adapter/gofiber"             // Fiber Web 框架适配器
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mssql"    // SQL Server 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mysql"    // MySQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/postgres" // PostgreSQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/sqlite"   // SQLite 数据库驱动
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/purpose168/GoAdmin-example/app"
+	"github.com/purpose168/GoAdmin-example/middleware"
+	"github.com/purpose168/GoAdmin/engine"
+)
+
+func main() {
+	opts, args, err := app.ParseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(args) > 0 {
+		log.Fatalf("Fiber 版本不支持子命令 %s，请在项目根目录执行 go run . %s", args[0], args[0])
+	}
+
+	f := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	eng := engine.Default()
+	if err := app.Configure(eng, opts).Use(f); err != nil {
+		log.Fatal(err)
+	}
+	if err := app.Setup(eng, opts); err != nil {
+		log.Fatal(err)
+	}
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	app.StartWorkers(workersCtx, eng)
+
+	f.Static("/uploads", "./uploads", fiber.Static{ModifyResponse: func(c *fiber.Ctx) error {
+		for k, v := range middleware.UploadHeaders(c.Path()) {
+			c.Set(k, v)
+		}
+		return nil
+	}})
+	if err := app.RegisterRoutes(eng, opts); err != nil {
+		log.Fatal(err)
+	}
+
+	addr, err := opts.ListenAddr()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		log.Printf("监听 %s\n", addr)
+		// ShutdownWithContext 之后 Listen 返回 nil
+		if err := f.Listen(addr); err != nil {
+			log.Fatalf("监听: %s\n", err)
+		}
+	}()
+
+	log.Printf("收到 %s，开始关闭\n", app.WaitSignal())
+	ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout)
+	defer cancel()
+	stopWorkers()
+	if err := f.ShutdownWithContext(ctx); err != nil {
+		log.Printf("服务器关闭: %s\n", err)
+	}
+	for _, err := range app.Shutdown(ctx, eng) {
+		log.Printf("关闭: %s\n", err)
+	}
+	log.Println("服务器退出")
+}
@@ -0,0 +1,314 @@
+// GoAdmin 示例项目 - 表格代码生成命令
+// 本文件实现 gen 子命令，读取配置文件（默认为 config.yml）中 default 数据库的表结构，生成 tables 包中的表格模型
+//
+// 用法:
+//
+//	go run . gen table <name>    生成 tables/<name>.go，文件的 init 中通过 tables.Register 注册为 /admin/info/<name>
+//
+// 生成的文件只是起点：字段名称默认为列名（MySQL 等数据库中取列的注释），
+// 菜单需要在管理后台中添加，字段的显示方式和表单控件可以按需修改
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/purpose168/GoAdmin-example/app"
+	"gorm.io/gorm"
+)
+
+// genUsage gen 子命令的用法说明
+const genUsage = `用法: go run . gen table <name>`
+
+// genTablesDir 生成的表格模型所在的目录
+const genTablesDir = "tables"
+
+// genTableName 可以生成的表名：小写字母开头，只包含小写字母、数字和下划线，同时用作文件名和 URL 前缀
+var genTableName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// genColumn 生成代码时使用的一列
+type genColumn struct {
+	// Name 列名
+	Name string
+
+	// Head 列表和表单中显示的字段名称
+	Head string
+
+	// Kind 字段的类别，决定数据类型、列表显示方式和表单控件，见 genColumnKind
+	Kind string
+
+	// PrimaryKey 是否为主键，主键在表单中只读
+	PrimaryKey bool
+
+	// Must 表单中是否必填：非空且没有默认值的列
+	Must bool
+}
+
+// genAutoColumns 由 ORM 或数据库维护的列，不出现在表单中；deleted_at 也不出现在列表中
+var genAutoColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// runGen 执行 gen 子命令
+//
+// 参数:
+//   - opts: 启动参数，决定配置文件路径和 default 数据库的 DSN
+//   - args: gen 之后的命令行参数
+//
+// 返回值:
+//   - error: 参数错误、表不存在、目标文件已存在或写入失败时返回错误
+func runGen(opts app.Options, args []string) error {
+	if len(args) != 2 || args[0] != "table" {
+		return errors.New(genUsage)
+	}
+	name := args[1]
+	if !genTableName.MatchString(name) {
+		return fmt.Errorf("表名只能包含小写字母、数字和下划线，并以字母开头: %s", name)
+	}
+
+	file := filepath.Join(genTablesDir, name+".go")
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%s 已存在", file)
+	}
+	if registered, err := genRegistered(name); err != nil {
+		return err
+	} else if registered != "" {
+		return fmt.Errorf("%s 中已经注册了 %s", registered, name)
+	}
+
+	db, _, err := openDefaultDatabase(opts)
+	if err != nil {
+		return err
+	}
+	columns, err := genColumns(db, name)
+	if err != nil {
+		return err
+	}
+
+	src, err := genTableSource(name, columns)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, src, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("已生成 %s，注册为 %s\n", file, name)
+	fmt.Printf("重新编译后访问后台路由前缀下的 /info/%s（默认为 /admin/info/%s），菜单需要在管理后台中添加\n", name, name)
+	return nil
+}
+
+// genColumns 读取表的列，按数据库中的顺序返回
+func genColumns(db *gorm.DB, name string) ([]genColumn, error) {
+	if !db.Migrator().HasTable(name) {
+		return nil, fmt.Errorf("数据库中没有 %s 表", name)
+	}
+	types, err := db.Migrator().ColumnTypes(name)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 的表结构失败: %v", name, err)
+	}
+
+	columns := make([]genColumn, 0, len(types))
+	for _, ct := range types {
+		col := genColumn{Name: ct.Name(), Head: ct.Name(), Kind: genColumnKind(ct.DatabaseTypeName())}
+		if comment, ok := ct.Comment(); ok && comment != "" {
+			col.Head = comment
+		}
+		col.PrimaryKey, _ = ct.PrimaryKey()
+		nullable, ok := ct.Nullable()
+		_, hasDefault := ct.DefaultValue()
+		col.Must = ok && !nullable && !hasDefault && !col.PrimaryKey && !genAutoColumns[col.Name]
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// genColumnKind 按数据库中的类型名称把列归为 int、decimal、bool、datetime、date、text 或 varchar
+// 各数据库的类型名称不同，这里只按常见的关键字匹配，无法识别的类型按 varchar 处理
+func genColumnKind(typeName string) string {
+	t := strings.ToUpper(typeName)
+	switch {
+	case strings.HasPrefix(t, "TINYINT"), strings.HasPrefix(t, "BOOL"), t == "BIT":
+		return "bool"
+	case strings.Contains(t, "INT"), t == "SERIAL", t == "BIGSERIAL":
+		return "int"
+	case strings.Contains(t, "DECIMAL"), strings.Contains(t, "NUMERIC"), strings.Contains(t, "REAL"),
+		strings.Contains(t, "FLOAT"), strings.Contains(t, "DOUBLE"), strings.Contains(t, "MONEY"):
+		return "decimal"
+	case strings.HasPrefix(t, "DATETIME"), strings.HasPrefix(t, "TIMESTAMP"):
+		return "datetime"
+	case t == "DATE":
+		return "date"
+	case strings.Contains(t, "TEXT"), strings.Contains(t, "CLOB"), strings.Contains(t, "JSON"):
+		return "text"
+	default:
+		return "varchar"
+	}
+}
+
+// genTableSource 生成表格模型的源码，并用 gofmt 格式化
+// 生成的表格与其他表格一样按 defaultDriver 使用 config.yml 中 default 数据库的驱动，不写死生成时的驱动
+func genTableSource(name string, columns []genColumn) ([]byte, error) {
+	camel := genCamel(name)
+	data := struct {
+		Name, Func, Var string
+		Columns         []genColumn
+		Auto            map[string]bool
+		UsesTypes       bool
+	}{
+		Name:    name,
+		Func:    "Get" + camel + "Table",
+		Var:     strings.ToLower(camel[:1]) + camel[1:] + "Table",
+		Columns: columns,
+		Auto:    genAutoColumns,
+	}
+	// 列表中字符串和时间的筛选、表单中布尔值的选项使用 types 包
+	for _, col := range columns {
+		if col.PrimaryKey || col.Name == "deleted_at" {
+			continue
+		}
+		if col.Kind == "varchar" || col.Kind == "datetime" || (col.Kind == "bool" && !genAutoColumns[col.Name]) {
+			data.UsesTypes = true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := genTableTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %v", err)
+	}
+	return src, nil
+}
+
+// genRegistered 查找 tables 包中已经用 name 注册的文件，没有时返回空字符串
+// 重复注册在启动时才会 panic，这里提前检查，避免生成一个无法启动的文件
+func genRegistered(name string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(genTablesDir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+	call := []byte(fmt.Sprintf("Register(%q,", name))
+	for _, f := range files {
+		src, err := ioutil.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+		if bytes.Contains(src, call) {
+			return f, nil
+		}
+	}
+	return "", nil
+}
+
+// genCamel 把下划线分隔的表名转换为驼峰形式，如 order_items 转换为 OrderItems
+func genCamel(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// genTableTemplate 表格模型的模板，生成的代码与 tables 包中手写的表格保持相同的结构
+//
+// 各类别的列:
+//   - 列表: 数字和时间可排序，字符串按包含筛选，时间按范围筛选，布尔值显示为 1/0，长文本不在列表中显示
+//   - 表单: 数字为 Number，布尔值为开关，时间和日期为选择器，长文本为多行文本框，其他为文本框
+var genTableTemplate = template.Must(template.New("table").Parse(`// Package tables 提供数据库表格模型定义
+// 本文件实现 {{.Name}} 表格的模型配置，由 go run . gen table {{.Name}} 根据表结构生成
+package tables
+
+import (
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+{{- if .UsesTypes}}
+	"github.com/purpose168/GoAdmin/template/types"
+{{- end}}
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 {{.Name}} 前缀
+// 访问路径: /admin/info/{{.Name}}
+func init() {
+	Register({{printf "%q" .Name}}, withAudit({{.Func}}))
+}
+
+// {{.Func}} 获取 {{.Name}} 表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+func {{.Func}}(ctx *context.Context) ({{.Var}} table.Table) {
+
+	{{.Var}} = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := {{.Var}}.GetInfo()
+{{range .Columns}}{{if and (ne .Kind "text") (ne .Name "deleted_at")}}
+	info.AddField({{printf "%q" .Head}}, {{printf "%q" .Name}}, {{template "dbType" .}})
+{{- if .PrimaryKey}}.FieldSortable()
+{{- else if eq .Kind "int" "decimal" "date"}}.FieldSortable()
+{{- else if eq .Kind "datetime"}}.FieldSortable().FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+{{- else if eq .Kind "bool"}}.FieldBool("1", "0")
+{{- else if eq .Kind "varchar"}}.FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+{{- end}}
+{{end}}{{end}}
+	info.SetTable({{printf "%q" .Name}}).SetTitle({{printf "%q" .Name}}).SetDescription({{printf "%q" .Name}})
+
+	formList := {{.Var}}.GetForm()
+{{range .Columns}}{{if not (index $.Auto .Name)}}
+	formList.AddField({{printf "%q" .Head}}, {{printf "%q" .Name}}, {{template "dbType" .}}, {{template "formType" .}})
+{{- if .PrimaryKey}}.FieldNotAllowEdit().FieldNotAllowAdd()
+{{- else if eq .Kind "bool"}}.
+		FieldOptions(types.FieldOptions{
+			{Text: "是", Value: "1"},
+			{Text: "否", Value: "0"},
+		}).FieldDefault("0")
+{{- end}}
+{{- if .Must}}.FieldMust(){{end}}
+{{end}}{{end}}
+	formList.SetTable({{printf "%q" .Name}}).SetTitle({{printf "%q" .Name}}).SetDescription({{printf "%q" .Name}})
+
+	return
+}
+{{define "dbType"}}
+{{- if eq .Kind "int"}}db.Int
+{{- else if eq .Kind "decimal"}}db.Decimal
+{{- else if eq .Kind "bool"}}db.Tinyint
+{{- else if eq .Kind "datetime"}}db.Datetime
+{{- else if eq .Kind "date"}}db.Date
+{{- else if eq .Kind "text"}}db.Text
+{{- else}}db.Varchar
+{{- end}}
+{{- end}}
+{{define "formType"}}
+{{- if .PrimaryKey}}form.Default
+{{- else if eq .Kind "int"}}form.Number
+{{- else if eq .Kind "bool"}}form.Switch
+{{- else if eq .Kind "datetime"}}form.Datetime
+{{- else if eq .Kind "date"}}form.Date
+{{- else if eq .Kind "text"}}form.TextArea
+{{- else}}form.Text
+{{- end}}
+{{- end}}
+`))
@@ -20,21 +20,82 @@ replace (
 // 直接依赖声明(Require Direct Dependencies)：列出项目直接使用的所有外部依赖包
 // 这些包在项目代码中被显式导入和使用
 require (
+	// Brotli 压缩库：纯 Go 实现的 Brotli 压缩算法
+	// 响应压缩中间件在浏览器支持时优先使用它，压缩率比 Gzip 更高
+	github.com/andybalholm/brotli v1.1.0
+	// Excelize Excel 文件处理库：用于读写 Excel 文件
+	// 表格的通用导入用它读取上传的 .xlsx 文件
+	github.com/360EntSecGroup-Skylar/excelize v1.4.1
 	// HTTP 测试库：用于编写 HTTP 服务的集成测试和端到端测试
 	// 提供了类似断言的 API，方便测试 HTTP 请求和响应
 	github.com/gavv/httpexpect v2.0.0+incompatible
 	// Gin Web 框架：高性能的 HTTP Web 框架，类似于 Martini 但性能更好
 	// 提供了路由、中间件、JSON 验证等功能，是 Go 社区最流行的 Web 框架之一
 	github.com/gin-gonic/gin v1.11.0
-	// GORM ORM 库：Go 语言的 Object-Relational Mapping (对象关系映射) 库
-	// 提供了友好的 API 来操作数据库，支持 MySQL、PostgreSQL、SQLite 等多种数据库
-	github.com/jinzhu/gorm v1.9.16
+	// Chi 路由库：轻量的 net/http 路由器
+	// cmd/chi 中的 Chi 版本使用（以 -tags chi 编译），与 GoAdmin 的 chi 适配器版本一致
+	github.com/go-chi/chi v1.5.5
+	// fpdf 库：纯 Go 实现的 PDF 生成库，支持嵌入 UTF-8 TrueType 字体
+	// 表格的"PDF"按钮用它把筛选后的数据导出为分页的 PDF
+	github.com/go-pdf/fpdf v0.9.0
+	// MySQL 驱动：MySQL 数据库的 Go 语言驱动
+	// 数据模型使用其中的 DSN 解析，为 GoAdmin 生成的连接字符串补上 parseTime
+	github.com/go-sql-driver/mysql v1.8.1
+	// Fiber Web 框架：基于 fasthttp 的 Web 框架
+	// cmd/fiber 中的 Fiber 版本使用（以 -tags fiber 编译），与 GoAdmin 的 gofiber 适配器版本一致
+	github.com/gofiber/fiber/v2 v2.52.4
+	// Echo Web 框架：高性能、简洁的 Web 框架
+	// cmd/echo 中的 Echo 版本使用（以 -tags echo 编译），与 GoAdmin 的 echo 适配器版本一致
+	github.com/labstack/echo/v4 v4.11.4
 	// GoAdmin 核心框架：一个基于 Go 语言的后台管理系统框架
 	// 提供了完整的后台管理功能，包括权限管理、菜单管理、数据表格等
 	github.com/purpose168/GoAdmin v1.2.26
 	// GoAdmin 主题包：为 GoAdmin 框架提供 UI 主题和样式
 	// 包含了多种预设主题，可以快速美化后台管理界面
 	github.com/purpose168/GoAdmin-themes v0.0.48
+	// Redis 客户端：用于连接 Redis 服务器
+	// 作为仪表板统计数据缓存的可选后端，多实例部署时共享缓存
+	github.com/redis/go-redis/v9 v9.7.0
+	// gopsutil 库：跨平台读取 CPU、内存、磁盘等系统信息
+	// 系统资源采集任务使用其中的 cpu 和 mem 包采样主机的使用率
+	github.com/shirou/gopsutil/v3 v3.24.5
+	// go-qrcode 库：纯 Go 实现的二维码生成库，没有其他依赖
+	// 表格的二维码列在服务端把字段值生成为 PNG 图片，列表中直接显示
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	// Goldmark 库：符合 CommonMark 规范的 Markdown 解析和渲染库
+	// 文章内容使用 Markdown 编辑时，列表、详情和编辑器预览用它渲染为 HTML
+	github.com/yuin/goldmark v1.8.6
+	// Go 扩展加密库：提供 bcrypt 等标准库之外的加密算法
+	// 用户密码使用其中的 bcrypt 保存哈希，与 GoAdmin 管理员密码的保存方式相同
+	golang.org/x/crypto v0.46.0
+	// Go 扩展图像库：提供标准库之外的图片缩放算法和 WebP 解码
+	// 用户头像上传后用其中的 draw 包按 CatmullRom 插值缩放为缩略图
+	golang.org/x/image v0.34.0
+	// Go Net 库：Go 网络扩展库，其中的 html 包是符合 HTML5 规范的解析器
+	// 公开站点显示文章的富文本正文前，用它解析 HTML 并按白名单过滤标签和属性
+	golang.org/x/net v0.47.0
+	// Go Sync 库：Go 并发扩展库
+	// 仪表板使用其中的 errgroup 并发加载各个组件
+	golang.org/x/sync v0.19.0
+	// Lumberjack 日志轮转库：按大小切割日志文件，压缩和清理旧文件
+	// 访问日志使用它写入 ./logs/access.log，与 GoAdmin 自身的日志使用同一个库
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	// YAML v2 库：YAML 格式的解析库（版本 2）
+	// 用于从 config.yml 读取仪表板的组件配置，与 GoAdmin 解析配置文件使用同一个库
+	gopkg.in/yaml.v2 v2.4.0
+	// GORM MySQL 驱动：GORM v2 的 MySQL 方言实现
+	gorm.io/driver/mysql v1.5.7
+	// GORM PostgreSQL 驱动：GORM v2 的 PostgreSQL 方言实现，底层使用 pgx
+	gorm.io/driver/postgres v1.5.11
+	// GORM SQLite 驱动：GORM v2 的 SQLite 方言实现
+	// 底层使用与 GoAdmin 相同的 mattn/go-sqlite3 驱动
+	gorm.io/driver/sqlite v1.5.7
+	// GORM SQL Server 驱动：GORM v2 的 SQL Server 方言实现
+	// 数据模型按 config.yml 中 default 数据库的 driver 选择上面的方言
+	gorm.io/driver/sqlserver v1.4.0
+	// GORM ORM 库（v2）：Go 语言的 Object-Relational Mapping (对象关系映射) 库
+	// 提供了友好的 API 来操作数据库，查询支持 context.Context，可以随请求取消
+	gorm.io/gorm v1.25.12
 )
 
 // 间接依赖声明(Require Indirect Dependencies)：列出项目间接使用的依赖包
@@ -44,18 +105,12 @@ require (
 	// Edwards25519 椭圆曲线加密库：实现了 Ed25519 签名算法
 	// 用于密码学操作，提供高性能的数字签名功能
 	filippo.io/edwards25519 v1.1.0 // indirect
-	// Excelize Excel 文件处理库：用于读写 Excel 文件
-	// 支持 .xlsx 格式，可以创建、修改和导出 Excel 电子表格
-	github.com/360EntSecGroup-Skylar/excelize v1.4.1 // indirect
 	// 快速随机数生成器：提供高性能的伪随机数生成
 	// 比标准库的 math/rand 更快，适合性能敏感的场景
 	github.com/NebulousLabs/fastrand v0.0.0-20181203155948-6fb6489aac4e // indirect
 	// 表单处理库：用于解析和编码 HTML 表单数据
 	// 支持 multipart/form-data 和 application/x-www-form-urlencoded 格式
 	github.com/ajg/form v1.5.1 // indirect
-	// Brotli 压缩库：实现了 Brotli 压缩算法
-	// Brotli 是一种高效的压缩格式，比 Gzip 压缩率更高
-	github.com/andybalholm/brotli v1.1.0 // indirect
 	// FastHTTP 路由器：为 FastHTTP 框架提供高性能的路由功能
 	// FastHTTP 是一个高性能的 HTTP 服务器实现，比标准库的 net/http 更快
 	github.com/buaazp/fasthttprouter v0.1.1 // indirect
@@ -73,7 +128,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	// SQL Server 驱动：Microsoft SQL Server 数据库的 Go 语言驱动
 	// 用于连接和操作 SQL Server 数据库，支持完整的 T-SQL 功能
-	github.com/denisenkom/go-mssqldb v0.0.0-20200206145737-bbfc9a55622e // indirect
+	github.com/denisenkom/go-mssqldb v0.12.2 // indirect
 	// 结构体工具库：提供结构体相关的实用函数
 	// 可以将结构体转换为 map、获取结构体字段信息等
 	github.com/fatih/structs v1.1.0 // indirect
@@ -83,6 +138,9 @@ require (
 	// Server-Sent Events (SSE) 库：用于实现服务器推送事件
 	// SSE 是一种单向服务器推送技术，用于实时更新客户端
 	github.com/gin-contrib/sse v1.1.0 // indirect
+	// Go OLE 库：Windows COM/OLE 接口的 Go 语言绑定
+	// gopsutil 在 Windows 上通过 WMI 查询系统信息时使用
+	github.com/go-ole/go-ole v1.2.6 // indirect
 	// 本地化库：提供多语言支持的数据
 	// 包含各种语言的日期、数字、货币等格式化规则
 	github.com/go-playground/locales v0.14.1 // indirect
@@ -92,9 +150,6 @@ require (
 	// 数据验证库：提供强大的结构体验证功能
 	// 支持自定义验证规则、错误消息国际化等
 	github.com/go-playground/validator/v10 v10.27.0 // indirect
-	// MySQL 驱动：MySQL 数据库的 Go 语言驱动
-	// 用于连接和操作 MySQL 数据库，支持完整的 MySQL 协议
-	github.com/go-sql-driver/mysql v1.8.1 // indirect
 	// Go-JSON 库：另一个高性能的 JSON 序列化/反序列化库
 	// 提供了流式处理、自定义编解码等功能
 	github.com/goccy/go-json v0.10.5 // indirect
@@ -125,6 +180,9 @@ require (
 	// 词形变化库：用于英语单词的单复数转换
 	// 常用于 ORM 框架中自动生成表名
 	github.com/jinzhu/inflection v1.0.0 // indirect
+	// 时间工具库：提供按天、周、月取起止时间等辅助函数
+	// 由 GORM v2 使用
+	github.com/jinzhu/now v1.1.5 // indirect
 	// JSON 迭代器库：高性能的 JSON 解析库
 	// 使用迭代器模式，内存占用更小，适合处理大型 JSON 数据
 	github.com/json-iterator/go v1.1.12 // indirect
@@ -137,18 +195,25 @@ require (
 	// CPUID 库：用于检测 CPU 特性和指令集支持
 	// 可以查询 CPU 的型号、缓存大小、支持的指令集等信息
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
+	// Labstack 公共库：Echo 使用的彩色输出和日志
+	github.com/labstack/gommon v0.4.2 // indirect
 	// URN 解析库：用于解析和验证 URN (统一资源名称)
 	// URN 是一种资源标识符，类似于 URL 但不包含位置信息
 	github.com/leodido/go-urn v1.4.0 // indirect
 	// PostgreSQL 驱动：PostgreSQL 数据库的 Go 语言驱动
 	// 用于连接和操作 PostgreSQL 数据库，支持完整的 PostgreSQL 协议
 	github.com/lib/pq v1.10.9 // indirect
+	// Plan 9 统计库：读取 Plan 9 系统的 CPU 和内存信息
+	// gopsutil 在 Plan 9 上使用
+	github.com/lufia/plan9stats v0.0.0-20211012122336-39d0f177ccd0 // indirect
 	// 终端颜色库：用于在终端中输出彩色文本
 	// 支持 ANSI 颜色代码，可以设置前景色、背景色等
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	// 终端检测库：用于检测输出是否为终端
 	// 判断标准输出/错误是否连接到终端设备
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	// 字符宽度库：计算字符串在终端中的显示宽度，Fiber 打印启动信息时使用
+	github.com/mattn/go-runewidth v0.0.15 // indirect
 	// SQLite3 驱动：SQLite3 数据库的 Go 语言驱动
 	// SQLite 是一个轻量级的嵌入式数据库，无需独立的服务器进程
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible // indirect
@@ -173,18 +238,26 @@ require (
 	// 差异比较库：用于比较两个数据结构的差异
 	// 可以生成详细的差异报告，适合测试断言
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	// AIX perfstat 库：读取 AIX 系统的性能统计数据
+	// gopsutil 在 AIX 上使用
+	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
 	// QPACK 库：HTTP/3 和 QUIC 协议的头部压缩库
 	// QPACK 是 HPACK 的改进版本，用于压缩 HTTP 头部
 	github.com/quic-go/qpack v0.5.1 // indirect
 	// QUIC-Go 库：QUIC 协议的 Go 语言实现
 	// QUIC 是一种基于 UDP 的传输协议，是 HTTP/3 的基础
 	github.com/quic-go/quic-go v0.54.0 // indirect
+	// Unicode 分词库：按字素簇切分字符串，go-runewidth 使用
+	github.com/rivo/uniseg v0.4.4 // indirect
 	// Agouti 测试库：用于 Web 应用的端到端测试
 	// 基于 WebDriver 协议，可以模拟浏览器操作
 	github.com/sclevine/agouti v3.0.0+incompatible // indirect
 	// 差异库：用于计算文本或数据结构的差异
 	// 类似于 Unix 的 diff 命令，但提供了更丰富的 API
 	github.com/sergi/go-diff v1.2.0 // indirect
+	// Apple M1 CPU 库：读取 Apple Silicon 处理器的型号和频率
+	// gopsutil 在 macOS 上使用
+	github.com/shoenig/go-m1cpu v0.1.6 // indirect
 	// 断言库：提供丰富的断言函数用于测试
 	// 支持相等性检查、异常检查、集合比较等
 	github.com/smarty/assertions v1.16.0 // indirect
@@ -200,6 +273,12 @@ require (
 	// Parse 库：用于解析 HTML、CSS、JavaScript 等文件
 	// 提供了词法分析和语法分析功能
 	github.com/tdewolff/parse/v2 v2.7.8 // indirect
+	// sysconf 库：不依赖 cgo 实现 POSIX sysconf
+	// gopsutil 读取时钟频率等系统参数时使用
+	github.com/tklauser/go-sysconf v0.3.12 // indirect
+	// CPU 数量库：读取在线和可用的 CPU 核心数
+	// 被 go-sysconf 使用
+	github.com/tklauser/numcpus v0.6.1 // indirect
 	// Go-ASM 库：Go 语言的汇编器
 	// 用于生成和操作机器码，支持多种架构
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
@@ -212,6 +291,10 @@ require (
 	// FastHTTP 库：高性能的 HTTP 服务器和客户端实现
 	// 比标准库的 net/http 性能更高，内存占用更少
 	github.com/valyala/fasthttp v1.52.0 // indirect
+	// 文本模板库：简单快速的占位符替换，Echo 的日志格式使用
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	// TCP 监听库：为 fasthttp 创建支持 SO_REUSEPORT 的监听，Fiber 的 Prefork 模式使用
+	github.com/valyala/tcplisten v1.0.0 // indirect
 	// JSON Pointer 库：实现 JSON Pointer 规范
 	// JSON Pointer 是一种引用 JSON 文档中特定值的方法
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
@@ -230,6 +313,9 @@ require (
 	// LCS (最长公共子序列) 库：用于计算两个序列的最长公共子序列
 	// 常用于文本比较、版本控制等场景
 	github.com/yudai/golcs v0.0.0-20170316035057-ecda9a501e82 // indirect
+	// WMI 库：在 Windows 上执行 WMI 查询
+	// gopsutil 在 Windows 上读取系统信息时使用
+	github.com/yusufpapurcu/wmi v1.2.4 // indirect
 	// Uber Mock 库：Uber 开源的模拟(mock)框架
 	// 用于单元测试中模拟依赖对象
 	go.uber.org/mock v0.5.0 // indirect
@@ -244,16 +330,9 @@ require (
 	golang.org/x/arch v0.20.0 // indirect
 	// Go Crypto 库：Go 语言的密码学扩展库
 	// 提供了各种加密算法、哈希函数、随机数生成等
-	golang.org/x/crypto v0.46.0 // indirect
 	// Go Mod 库：Go 模块系统的工具库
 	// 提供了模块解析、版本查询等功能
 	golang.org/x/mod v0.30.0 // indirect
-	// Go Net 库：Go 网络扩展库
-	// 提供了各种网络协议的实现，如 HTTP/2、WebSocket 等
-	golang.org/x/net v0.47.0 // indirect
-	// Go Sync 库：Go 并发扩展库
-	// 提供了额外的并发原语，如 ErrGroup、SingleFlight 等
-	golang.org/x/sync v0.19.0 // indirect
 	// Go Sys 库：Go 系统调用扩展库
 	// 提供了跨平台的系统调用接口，如文件系统、进程管理等
 	golang.org/x/sys v0.39.0 // indirect
@@ -269,12 +348,6 @@ require (
 	// INI 配置文件库：用于解析和生成 INI 格式的配置文件
 	// INI 是一种简单的配置文件格式，常用于 Windows 应用
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	// Lumberjack 日志轮转库：用于日志文件的轮转和压缩
-	// 可以自动切割、压缩和删除旧的日志文件
-	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
-	// YAML v2 库：YAML 格式的解析库（版本 2）
-	// YAML 是一种人类可读的数据序列化格式
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	// YAML v3 库：YAML 格式的解析库（版本 3）
 	// 版本 3 相比版本 2 有一些改进和变化
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -286,4 +359,13 @@ require (
 	xorm.io/xorm v1.3.11 // indirect
 )
 
-require github.com/GoAdminGroup/html v0.0.1 // indirect
+require (
+	github.com/GoAdminGroup/html v0.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/golang-sql/sqlexp v0.1.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.6.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+)
@@ -0,0 +1,47 @@
+// GoAdmin 示例项目 - 健康检查接口
+// 本文件实现 /healthz，供负载均衡和容器编排探测服务是否可用
+//
+// 用法:
+//
+//	curl http://127.0.0.1:9033/healthz
+
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+// healthCheckTimeout 健康检查的最长耗时，数据库无响应时按不可用返回，不让探测一直等待
+const healthCheckTimeout = 3 * time.Second
+
+// healthz 返回数据库的健康检查结果
+//
+// 返回值（JSON）:
+//   - 200: {"ok": true}，主库和所有副本都能连通，且没有缺少数据表
+//   - 503: {"ok": false}，有连接无法连通或缺少数据表，原因写入日志
+//
+// 注意事项:
+//   - 不需要登录，响应中只有 ok，连接名、错误和缺少的表名只写入日志；连接池的使用情况见 /metrics
+func healthz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	h, err := models.HealthCheck(ctx)
+	if err != nil {
+		log.Printf("健康检查失败: %v\n", err)
+		for _, d := range h.Databases {
+			if !d.OK {
+				log.Printf("健康检查: 数据库 %s 无法连通: %s\n", d.Name, d.Error)
+			}
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"ok": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -7,124 +7,174 @@
 package main
 
 import (
-	"context"   // 上下文包，用于管理请求范围的操作
-	"io/ioutil" // 输入/输出工具包，用于文件操作
-	"log"       // 日志包，用于记录应用运行时信息
-	"net/http"  // HTTP 包，用于处理 HTTP 请求和响应
-	"os"        // 操作系统包，用于访问环境变量和文件系统
-	"os/signal" // 信号处理包，用于捕获系统信号
-	"time"      // 时间包，用于处理时间相关操作
-
-	_ "github.com/purpose168/GoAdmin-themes/sword"              // Sword UI 主题
-	_ "github.com/purpose168/GoAdmin/adapter/gin"               // Gin Web 框架适配器
-	_ "github.com/purpose168/GoAdmin/modules/db/drivers/sqlite" // SQLite 数据库驱动
-
-	"github.com/gin-gonic/gin"                       // Gin Web 框架，用于处理 HTTP 请求
-	"github.com/purpose168/GoAdmin-example/models"   // 模型包，定义数据库表结构
-	"github.com/purpose168/GoAdmin-example/pages"    // 页面包，定义管理后台页面
-	"github.com/purpose168/GoAdmin-example/tables"   // 表格包，定义数据表格组件
-	"github.com/purpose168/GoAdmin/engine"           // 引擎包，负责初始化和运行 GoAdmin
-	"github.com/purpose168/GoAdmin/template"         // 模板包，定义页面模板和组件
-	"github.com/purpose168/GoAdmin/template/chartjs" // Chart.js 图表组件
+	"context"       // 上下文包，用于管理请求范围的操作
+	"io/ioutil"     // 输入/输出工具包，用于文件操作
+	"log"           // 日志包，用于记录应用运行时信息
+	"net/http"      // HTTP 包，用于处理 HTTP 请求和响应
+	"os"            // 操作系统包，用于访问环境变量和文件系统
+	"path/filepath" // 路径处理包，用于比较日志文件路径
+
+	_ "github.com/purpose168/GoAdmin-themes/sword"                // Sword UI 主题
+	_ "github.com/purpose168/GoAdmin/adapter/gin"                 // Gin Web 框架适配器
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mssql"    // SQL Server 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/mysql"    // MySQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/postgres" // PostgreSQL 数据库驱动
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/sqlite"   // SQLite 数据库驱动
+
+	"github.com/gin-gonic/gin"                         // Gin Web 框架，用于处理 HTTP 请求
+	"github.com/purpose168/GoAdmin-example/app"        // 与 Web 框架无关的引擎配置、初始化和后台路由
+	"github.com/purpose168/GoAdmin-example/middleware" // 中间件包，记录页面访问等
+	"github.com/purpose168/GoAdmin-example/site"       // 公开站点，展示后台中维护的文章
+	"github.com/purpose168/GoAdmin/engine"             // 引擎包，负责初始化和运行 GoAdmin
+	"github.com/purpose168/GoAdmin/modules/config"     // 配置包，读取 GoAdmin 生效的配置
 )
 
 // main 主函数 - 程序入口点
 // 负责启动服务器并初始化整个应用
+// 子命令之前可以指定启动参数（见 app.ParseOptions），例如 go run . -port 8080
+// 第一个参数为 migrate 时只执行数据库迁移，不启动服务器，例如 go run . migrate up
+// 第一个参数为 gen 时根据表结构生成表格模型，例如 go run . gen table goals
 func main() {
-	startServer()
+	opts, args, err := app.ParseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(opts, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "gen" {
+		if err := runGen(opts, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	startServer(opts)
 }
 
 // startServer 初始化并启动 GoAdmin 管理后台服务器
 // 该函数执行以下操作:
 // 1. 配置 Gin 框架为发布模式
 // 2. 创建 GoAdmin 引擎实例
-// 3. 从 YAML 配置文件加载配置
-// 4. 注册数据表生成器
-// 5. 设置路由和页面处理器
+// 3. 从 YAML 配置文件加载配置，再用命令行参数和环境变量覆盖，注册数据表生成器（app.Configure）
+// 4. 初始化数据模型和后台任务（app.Setup、app.StartWorkers）
+// 5. 设置 Gin 的路由和 GoAdmin 的页面处理器（app.RegisterRoutes）
 // 6. 启动 HTTP 服务器
-// 7. 实现优雅关闭机制
-func startServer() {
+// 7. 实现优雅关闭机制：收到 SIGINT 或 SIGTERM 后依次停止接受请求、等待后台任务结束、关闭数据库连接
+func startServer(opts app.Options) {
 	// 设置 Gin 为发布模式，禁用调试日志
 	gin.SetMode(gin.ReleaseMode)
 	// 丢弃 Gin 的默认输出，避免日志干扰
 	gin.DefaultWriter = ioutil.Discard
 
+	// 按配置文件的 access_logger 配置项把每个请求以 JSON 记录到 ./logs/access.log，按大小和时间轮转
+	accessLogCfg, err := middleware.LoadAccessLogConfig(opts.ConfigPath)
+	if err != nil {
+		panic(err)
+	}
+	accessLog := middleware.NewAccessLog(accessLogCfg)
+
 	// 创建 Gin 路由器实例
-	r := gin.Default()
+	// 不使用 gin.Default 自带的 Logger 和 Recovery：访问日志写入文件，panic 时记录调用栈并显示自定义的 500 页面
+	r := gin.New()
+	r.Use(accessLog.Handler(), middleware.Recovery())
+
+	// 按配置文件的 compression 配置项压缩 HTML、JSON 和 CSV 等文本响应，图片和已经压缩过的文件原样返回
+	// 注册在 Recovery 之后，panic 时由 Recovery 显示未压缩的 500 页面
+	compressionCfg, err := middleware.LoadCompressionConfig(opts.ConfigPath)
+	if err != nil {
+		panic(err)
+	}
+	r.Use(middleware.Compression(compressionCfg))
+
+	// 按路由和状态码统计请求数和耗时，由 /metrics 输出
+	// 与下面的中间件一样必须在 eng.Use(r) 之前添加，才能统计到后台的路由
+	r.Use(middleware.Metrics())
+
+	// 记录后台页面访问，用于仪表板的浏览器使用情况统计
+	// 必须在 eng.Use(r) 注册 GoAdmin 路由之前添加
+	r.Use(middleware.PageViewTracker())
 
 	// 创建 GoAdmin 引擎实例，使用默认配置
 	eng := engine.Default()
 
-	// 添加 Chart.js 图表组件支持
-	// Chart.js 是一个流行的 JavaScript 图表库，用于数据可视化
-	template.AddComp(chartjs.NewChart())
-
-	// 以下是被注释掉的数据库配置示例
-	// 实际配置从 config.yml 文件中读取
-	//cfg := config.Config{
-	//	Databases: config.DatabaseList{
-	//		"default": {
-	//			Host:       "127.0.0.1",
-	//			Port:       "3306",
-	//			User:       "root",
-	//			Pwd:        "root",
-	//			Name:       "go-admin",
-	//			MaxIdleCon: 50,
-	//			MaxOpenCon: 150,
-	//			Driver:     db.DriverMysql,
-	//		},
-	//	},
-	//	UrlPrefix: "admin",
-	//	IndexUrl:  "/",
-	//	Debug:     true,
-	//	Language:  language.CN,
-	//}
-
-	// 从 YAML 配置文件加载配置
-	// AddConfigFromYAML: 从指定路径读取配置文件
-	// AddGenerators: 注册数据表生成器，用于自动生成管理界面
-	// AddGenerator: 添加外部表生成器
-	// Use: 将 GoAdmin 引擎集成到 Gin 路由器中
-	if err := eng.AddConfigFromYAML("./config.yml").
-		AddGenerators(tables.Generators).
-		AddGenerator("external", tables.GetExternalTable).
-		Use(r); err != nil {
+	// 系统设置中开启维护模式后，只有超级管理员可以使用后台
+	// 与 PageViewTracker 一样必须在 eng.Use(r) 之前添加，数据库连接在处理请求时才读取
+	r.Use(middleware.Maintenance(eng.DefaultConnection))
+
+	// 按管理员在个人资料页面选择的界面语言显示后台，同样必须在 eng.Use(r) 之前添加
+	r.Use(middleware.Language(eng.DefaultConnection))
+
+	// 记录登录会话的 IP、浏览器和最近活动时间，在"登录会话"表格中显示，同样必须在 eng.Use(r) 之前添加
+	r.Use(middleware.SessionTracker(eng.DefaultConnection))
+
+	// 按启动参数配置 GoAdmin 引擎（配置文件、数据表生成器等见 app.Configure），再集成到 Gin 路由器中
+	if err := app.Configure(eng, opts).Use(r); err != nil {
 		panic(err)
 	}
 
-	// 初始化数据库模型
-	// 使用 SQLite 数据库连接
-	// models.Init: 初始化ORM实例，建立数据库连接
-	// eng.SqliteConnection(): 获取SQLite数据库连接配置
-	// 注意: 必须在使用任何数据库操作之前调用此函数
-	models.Init(eng.SqliteConnection())
+	// 初始化数据库模型，读取本项目的各配置项，使站点设置生效
+	if err := app.Setup(eng, opts); err != nil {
+		panic(err)
+	}
+
+	// GoAdmin 自己的日志也按 access_log 配置项轮转，两者使用同一个文件时会互相干扰
+	// 该配置项第一次启动后保存在 goadmin_site 表中，修改 config.yml 后需要在配置中心中一并修改
+	if p := accessLog.Path(); p != "" && filepath.Clean(p) == filepath.Clean(config.GetAccessLogPath()) {
+		log.Printf("警告: GoAdmin 的 access_log 与访问日志使用同一个文件 %s，请在配置中心中修改 access_log\n", p)
+	}
+
+	// 启动汇总、采集和定时导出等后台任务，收到退出信号后通过 stopWorkers 停止
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	app.StartWorkers(workersCtx, eng)
 
 	// 设置静态文件路由
 	// 将 /uploads 路径映射到本地 ./uploads 目录
-	// 用于处理用户上传的文件访问
-	r.Static("/uploads", "./uploads")
-
-	// 注册 HTML 页面路由
-	// DashboardPage: 仪表板页面，显示系统概览信息
-	eng.HTML("GET", "/admin", pages.DashboardPage)
-	// GetFormContent: 表单页面，展示各种表单字段类型
-	// 包含基础输入、日期时间、文件上传、富文本、选择控件等多种表单组件
-	// 使用标签页分组，分为input、select、multi三个标签页
-	eng.HTML("GET", "/admin/form", pages.GetFormContent)
-	// GetTableContent: 表格页面，用于数据展示和管理
-	eng.HTML("GET", "/admin/table", pages.GetTableContent)
-	// 自定义模板文件路由
-	// 使用 Go 模板引擎渲染 hello.tmpl 文件
-	eng.HTMLFile("GET", "/admin/hello", "./html/hello.tmpl", map[string]interface{}{
-		"msg": "你好世界",
-	})
+	// 用于处理用户上传的文件访问，HTML、SVG 等文件只能下载，不在后台的域名下打开
+	r.Group("/uploads", middleware.UploadGuard()).Static("/", "./uploads")
+
+	// 注册健康检查路由，不经过 GoAdmin 的登录验证
+	// 数据库可连通且数据表齐全时返回 200，否则返回 503
+	r.GET("/healthz", healthz)
+
+	// 注册 Prometheus 指标路由：请求数和耗时、数据库连接池和缓存命中次数
+	// 设置 METRICS_USERNAME 和 METRICS_PASSWORD 环境变量后需要 Basic 认证
+	r.GET("/metrics", middleware.MetricsHandler(os.Getenv("METRICS_USERNAME"), os.Getenv("METRICS_PASSWORD")))
+
+	// 注册 pprof 性能分析接口，只有超级管理员可以访问
+	// symbol 接口同时接受 POST
+	r.GET(config.Url(middleware.PprofPath+"*name"), middleware.SuperAdmin(eng.DefaultConnection), middleware.Pprof())
+	r.POST(config.Url(middleware.PprofPath+"*name"), middleware.SuperAdmin(eng.DefaultConnection), middleware.Pprof())
+
+	// 注册公开站点（首页和文章详情），与后台共用数据库，不需要登录
+	site.Register(r, "./html/site/*.tmpl")
+
+	// 没有注册路由的路径显示自定义的 404 页面
+	r.NoRoute(middleware.NotFound())
+
+	// 注册后台的自定义页面和接口：仪表板、表单、看板、报表等，见 app.RegisterRoutes
+	if err := app.RegisterRoutes(eng, opts); err != nil {
+		panic(err)
+	}
+
+	// 按配置文件的 request_timeout 配置项限制请求的处理时间，超时返回 503 页面并取消请求的 context
+	timeoutCfg, err := middleware.LoadTimeoutConfig(opts.ConfigPath)
+	if err != nil {
+		panic(err)
+	}
 
 	// 创建 HTTP 服务器配置
-	// Addr: 监听地址和端口，9033 是默认端口
-	// Handler: 使用 Gin 路由器作为请求处理器
+	// Addr: 监听地址和端口，按 -port 参数、GOADMIN_PORT 环境变量、配置文件的 server.port 的顺序确定，默认为 9033
+	// Handler: 使用 Gin 路由器作为请求处理器，外层加上处理时间的限制
+	addr, err := opts.ListenAddr()
+	if err != nil {
+		panic(err)
+	}
 	srv := &http.Server{
-		Addr:    ":9033",
-		Handler: r,
+		Addr:    addr,
+		Handler: middleware.Timeout(r, timeoutCfg),
 	}
 
 	// 在新的 goroutine 中启动服务器
@@ -133,33 +183,40 @@ func startServer() {
 	go func() {
 		// ListenAndServe 启动 HTTP 服务器
 		// 如果端口被占用或其他错误，会返回错误
-		if err := srv.ListenAndServe(); err != nil {
+		log.Printf("监听 %s\n", addr)
+		// Shutdown 之后返回 http.ErrServerClosed，不是错误
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Printf("监听: %s\n", err)
 		}
 	}()
 
 	// 实现优雅关闭机制
-	// 创建一个信号通道，用于接收操作系统信号
-	quit := make(chan os.Signal, 1)
-	// 监听中断信号（Ctrl+C）
-	// 当用户按下 Ctrl+C 时，会向通道发送 os.Interrupt 信号
-	signal.Notify(quit, os.Interrupt)
-	// 阻塞等待退出信号
-	<-quit
+	// 阻塞等待退出信号（SIGINT 或 SIGTERM）
+	sig := app.WaitSignal()
+	log.Printf("收到 %s，开始关闭\n", sig)
 
 	// 收到退出信号后，执行优雅关闭
-	// 创建一个带有超时的上下文
-	// 5 秒超时：如果服务器在 5 秒内没有关闭，将强制关闭
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 创建一个带有超时的上下文，以下各步骤共用
+	// app.ShutdownTimeout 内没有完成时不再等待，直接关闭数据库连接并退出
+	ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout)
 	// defer 确保在函数返回前调用取消函数，释放资源
 	defer cancel()
-	// Shutdown 方法优雅地关闭服务器
-	// 它会：
-	// 1. 停止接受新的连接
-	// 2. 等待所有活跃的请求完成（最多等待超时时间）
-	// 3. 关闭所有连接
+
+	// 1. 停止汇总、采集和定时导出的调度，正在执行的一轮随之取消或执行完
+	stopWorkers()
+
+	// 2. Shutdown 方法优雅地关闭服务器
+	// 它会停止接受新的连接，等待所有活跃的请求完成（最多等待超时时间），然后关闭所有连接
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("服务器关闭:", err)
+		log.Printf("服务器关闭: %s\n", err)
+	}
+	if err := accessLog.Close(); err != nil {
+		log.Printf("关闭访问日志: %s\n", err)
+	}
+
+	// 3. 等待后台任务结束，关闭数据库连接和 Redis 缓存
+	for _, err := range app.Shutdown(ctx, eng) {
+		log.Printf("关闭: %s\n", err)
 	}
 	log.Println("服务器退出")
 }
@@ -10,6 +10,7 @@ import (
 	"log"
 	"testing"
 
+	"github.com/purpose168/GoAdmin-example/app"
 	"github.com/purpose168/GoAdmin-example/tables"
 	"github.com/gavv/httpexpect"
 	"github.com/purpose168/GoAdmin/modules/config"
@@ -63,7 +64,7 @@ func TestExampleUserAcceptance(t *testing.T) {
 		// 启动服务器：
 		// ....
 		// 在新的 goroutine 中启动服务器，避免阻塞测试
-		go startServer()
+		go startServer(app.Options{ConfigPath: "./config.yml"})
 		// 等待退出信号
 		<-quit
 		log.Print("test quit")
@@ -0,0 +1,256 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现访问日志：每个请求以一行 JSON 写入 access_logger 配置项指定的文件，按大小和时间轮转并清理旧文件，
+// 容器中运行时可以同时输出到标准输出，由容器的日志驱动收集
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"gopkg.in/yaml.v2"
+)
+
+// AccessLogConfig 访问日志配置，对应 config.yml 的 access_logger 配置项
+// GoAdmin 自己的 access_log、logger.rotate 配置项只影响 GoAdmin 的日志，与这里无关
+type AccessLogConfig struct {
+	// Enabled 是否记录访问日志
+	Enabled bool `yaml:"enabled"`
+
+	// Path 日志文件路径，为空时不写入文件，只在 Stdout 为 true 时输出到标准输出
+	Path string `yaml:"path"`
+
+	// MaxSize 单个日志文件的最大大小（MB），超过后轮转
+	MaxSize int `yaml:"max_size"`
+
+	// RotateInterval 按时间轮转的间隔，按整点对齐（如 24h 在每天 UTC 0 点轮转），0 表示只按大小轮转
+	RotateInterval time.Duration `yaml:"rotate_interval"`
+
+	// MaxAge 轮转出的旧文件保留的天数，0 表示不按时间删除
+	MaxAge int `yaml:"max_age"`
+
+	// MaxBackups 保留的旧文件个数，0 表示不按个数删除
+	MaxBackups int `yaml:"max_backups"`
+
+	// Compress 是否把轮转出的旧文件压缩为 .gz
+	Compress bool `yaml:"compress"`
+
+	// LocalTime 旧文件名中的时间是否使用本地时区，默认为 UTC
+	LocalTime bool `yaml:"local_time"`
+
+	// Stdout 是否同时以 JSON 输出到标准输出
+	Stdout bool `yaml:"stdout"`
+
+	// SkipPaths 不记录的路径，如健康检查和 Prometheus 抓取的 /healthz、/metrics
+	SkipPaths []string `yaml:"skip_paths"`
+}
+
+// DefaultAccessLogConfig 配置文件中没有 access_logger 配置项时使用的默认配置
+var DefaultAccessLogConfig = AccessLogConfig{
+	Enabled:        true,
+	Path:           "./logs/access.log",
+	MaxSize:        100,
+	RotateInterval: 24 * time.Hour,
+	MaxAge:         30,
+	MaxBackups:     30,
+	Compress:       true,
+}
+
+// LoadAccessLogConfig 从 YAML 配置文件的 access_logger 配置项读取访问日志配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - AccessLogConfig: 配置项中未设置的字段使用 DefaultAccessLogConfig 中的值
+//   - error: 读取或解析失败，或大小、保留时间等为负数时返回错误
+func LoadAccessLogConfig(path string) (AccessLogConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return AccessLogConfig{}, err
+	}
+
+	cfg := struct {
+		AccessLog AccessLogConfig `yaml:"access_logger"`
+	}{AccessLog: DefaultAccessLogConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return AccessLogConfig{}, fmt.Errorf("解析访问日志配置失败: %v", err)
+	}
+	c := cfg.AccessLog
+	if c.MaxSize < 0 || c.MaxAge < 0 || c.MaxBackups < 0 || c.RotateInterval < 0 {
+		return AccessLogConfig{}, fmt.Errorf("访问日志配置的 max_size、max_age、max_backups 和 rotate_interval 不能为负数")
+	}
+	return c, nil
+}
+
+// AccessLog 访问日志，由 NewAccessLog 创建，Handler 返回记录日志的中间件
+type AccessLog struct {
+	cfg AccessLogConfig
+
+	// file 按大小轮转的日志文件，Path 为空或没有开启时为 nil
+	file *lumberjack.Logger
+
+	// skip 不记录的路径
+	skip map[string]bool
+
+	// stop 通知按时间轮转的 goroutine 退出，done 在其退出后关闭；没有按时间轮转时都为 nil
+	stop, done chan struct{}
+}
+
+// accessLogEntry 一条访问日志，以一行 JSON 写出
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Route     string  `json:"route,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Bytes     int     `json:"bytes"`
+	ClientIP  string  `json:"client_ip"`
+	UserAgent string  `json:"user_agent,omitempty"`
+	Referer   string  `json:"referer,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// NewAccessLog 按配置创建访问日志，RotateInterval 大于 0 时启动按时间轮转的 goroutine
+//
+// 参数:
+//   - cfg: 访问日志配置，通常由 LoadAccessLogConfig 读取
+//
+// 返回值:
+//   - *AccessLog: 访问日志，退出前调用 Close 停止轮转并关闭文件
+//
+// 使用示例:
+//
+//	accessLog := middleware.NewAccessLog(accessLogCfg)
+//	defer accessLog.Close()
+//	r.Use(accessLog.Handler(), middleware.Recovery())
+//
+// 注意事项:
+//   - 日志文件及其目录在写入第一条日志时创建
+func NewAccessLog(cfg AccessLogConfig) *AccessLog {
+	l := &AccessLog{cfg: cfg, skip: map[string]bool{}}
+	if !cfg.Enabled {
+		return l
+	}
+	for _, p := range cfg.SkipPaths {
+		l.skip[p] = true
+	}
+	if cfg.Path == "" {
+		return l
+	}
+
+	l.file = &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSize,
+		MaxAge:     cfg.MaxAge,
+		MaxBackups: cfg.MaxBackups,
+		LocalTime:  cfg.LocalTime,
+		Compress:   cfg.Compress,
+	}
+	if cfg.RotateInterval > 0 {
+		l.stop, l.done = make(chan struct{}), make(chan struct{})
+		go l.rotateEvery(cfg.RotateInterval)
+	}
+	return l
+}
+
+// rotateEvery 每隔 interval 轮转一次日志文件，直到 Close
+func (l *AccessLog) rotateEvery(interval time.Duration) {
+	defer close(l.done)
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+		select {
+		case <-timer.C:
+			if err := l.file.Rotate(); err != nil {
+				log.Printf("轮转访问日志失败: %s\n", err)
+			}
+		case <-l.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Handler 返回记录访问日志的中间件
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件，没有开启访问日志时不做任何处理
+//
+// 功能说明:
+//  1. 请求处理完成后记录方法、路径、注册路由时的路径模式、状态码、耗时、响应字节数、客户端 IP、User-Agent 和 Referer
+//  2. 查询参数可能包含令牌等敏感信息，不写入日志
+//  3. 响应经过压缩时，字节数为压缩后实际发送的大小
+//
+// 注意事项:
+//   - 应当第一个注册，才能记录到 Recovery 显示的 500 页面和之后所有中间件的耗时
+func (l *AccessLog) Handler() gin.HandlerFunc {
+	if !l.cfg.Enabled || (l.file == nil && !l.cfg.Stdout) {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if l.skip[c.Request.URL.Path] {
+			return
+		}
+		entry := accessLogEntry{
+			Time:      start.Format(time.RFC3339Nano),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Route:     c.FullPath(),
+			Status:    responseStatus(c),
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			Bytes:     c.Writer.Size(),
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Referer:   c.Request.Referer(),
+			Error:     c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+		if entry.Bytes < 0 {
+			entry.Bytes = 0
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		line = append(line, '\n')
+		if l.file != nil {
+			_, _ = l.file.Write(line)
+		}
+		if l.cfg.Stdout {
+			_, _ = os.Stdout.Write(line)
+		}
+	}
+}
+
+// Path 返回日志文件路径，没有写入文件时为空
+func (l *AccessLog) Path() string {
+	if l.file == nil {
+		return ""
+	}
+	return l.file.Filename
+}
+
+// Close 停止按时间轮转并关闭日志文件，在服务器停止接受请求之后调用
+func (l *AccessLog) Close() error {
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+		l.stop = nil
+	}
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,376 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现响应压缩：按 config.yml 的 compression 配置项，对 HTML、JSON、CSV 等文本响应使用 brotli 或 gzip 压缩，
+// 图片、xlsx、PDF 等本身已经压缩过的内容原样返回
+package middleware
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+)
+
+// CompressionConfig 响应压缩配置，对应 config.yml 的 compression 配置项
+type CompressionConfig struct {
+	// Enabled 是否压缩响应
+	Enabled bool `yaml:"enabled"`
+
+	// Brotli 浏览器同时支持时是否优先使用 brotli，关闭后只使用 gzip
+	Brotli bool `yaml:"brotli"`
+
+	// GzipLevel gzip 压缩级别，1（最快）到 9（最小）
+	GzipLevel int `yaml:"gzip_level"`
+
+	// BrotliLevel brotli 压缩级别，0（最快）到 11（最小）
+	BrotliLevel int `yaml:"brotli_level"`
+
+	// MinSize 响应小于该字节数时不压缩，压缩很小的响应得不偿失
+	MinSize int `yaml:"min_size"`
+
+	// Types 需要压缩的内容类型，不在列表中的类型（图片、xlsx、PDF、zip 等）原样返回
+	Types []string `yaml:"types"`
+
+	// ExcludePaths 不压缩的路径前缀，如需要边生成边输出的接口
+	ExcludePaths []string `yaml:"exclude_paths"`
+}
+
+// DefaultCompressionConfig 配置文件中没有 compression 配置项时使用的默认配置
+// 导出下载中 CSV、JSON Lines、XML 是文本，会被压缩；xlsx 本身是 zip 格式，PDF 的内容流已经压缩，不在列表中
+var DefaultCompressionConfig = CompressionConfig{
+	Enabled:     true,
+	Brotli:      true,
+	GzipLevel:   5,
+	BrotliLevel: 4,
+	MinSize:     1024,
+	Types: []string{
+		"text/html",
+		"text/css",
+		"text/plain",
+		"text/csv",
+		"text/javascript",
+		"text/xml",
+		"application/javascript",
+		"application/json",
+		"application/x-ndjson",
+		"application/xml",
+		"image/svg+xml",
+	},
+}
+
+// LoadCompressionConfig 从 YAML 配置文件的 compression 配置项读取响应压缩配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - CompressionConfig: 配置项中未设置的字段使用 DefaultCompressionConfig 中的值
+//   - error: 读取或解析失败，或压缩级别超出范围时返回错误
+func LoadCompressionConfig(path string) (CompressionConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return CompressionConfig{}, err
+	}
+
+	cfg := struct {
+		Compression CompressionConfig `yaml:"compression"`
+	}{Compression: DefaultCompressionConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return CompressionConfig{}, fmt.Errorf("解析压缩配置失败: %v", err)
+	}
+	if l := cfg.Compression.GzipLevel; l < gzip.BestSpeed || l > gzip.BestCompression {
+		return CompressionConfig{}, fmt.Errorf("压缩配置的 gzip_level 应在 1 到 9 之间: %d", l)
+	}
+	if l := cfg.Compression.BrotliLevel; l < brotli.BestSpeed || l > brotli.BestCompression {
+		return CompressionConfig{}, fmt.Errorf("压缩配置的 brotli_level 应在 0 到 11 之间: %d", l)
+	}
+	return cfg.Compression, nil
+}
+
+// Compression 返回压缩响应的中间件
+//
+// 参数:
+//   - cfg: 压缩配置，通常由 LoadCompressionConfig 读取
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件，cfg.Enabled 为 false 时不做任何处理
+//
+// 功能说明:
+//  1. 按请求的 Accept-Encoding 选择 brotli 或 gzip，两者都不支持时不压缩
+//  2. 响应先缓存在内存中，达到 MinSize 后才开始压缩，内容类型不在 Types 中的响应原样返回
+//  3. 已经设置了 Content-Encoding 的响应（如 GoAdmin 预先压缩的静态资源）、Range 请求和 WebSocket 升级不压缩
+//
+// 使用示例:
+//
+//	compressionCfg, err := middleware.LoadCompressionConfig("./config.yml")
+//	if err != nil {
+//	    panic(err)
+//	}
+//	r.Use(gin.Logger(), middleware.Recovery())
+//	r.Use(middleware.Compression(compressionCfg))
+//
+// 注意事项:
+//   - 需要注册在 Recovery 之后、eng.Use(r) 之前；处理器 panic 时丢弃还没有写出的内容，由 Recovery 显示 500 页面
+func Compression(cfg CompressionConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	types := make(map[string]bool, len(cfg.Types))
+	for _, t := range cfg.Types {
+		types[strings.ToLower(t)] = true
+	}
+	gzipWriters := sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(ioutil.Discard, cfg.GzipLevel)
+		return w
+	}}
+	brotliWriters := sync.Pool{New: func() interface{} {
+		return brotli.NewWriterLevel(ioutil.Discard, cfg.BrotliLevel)
+	}}
+
+	return func(c *gin.Context) {
+		req := c.Request
+		if req.Method == http.MethodHead || req.Header.Get("Range") != "" || req.Header.Get("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		for _, prefix := range cfg.ExcludePaths {
+			if strings.HasPrefix(req.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"), cfg.Brotli)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			types:          types,
+			minSize:        cfg.MinSize,
+		}
+		w.newEncoder = func(dst io.Writer) io.WriteCloser {
+			if encoding == "br" {
+				bw := brotliWriters.Get().(*brotli.Writer)
+				bw.Reset(dst)
+				return pooledEncoder{bw, func() { brotliWriters.Put(bw) }}
+			}
+			gw := gzipWriters.Get().(*gzip.Writer)
+			gw.Reset(dst)
+			return pooledEncoder{gw, func() { gzipWriters.Put(gw) }}
+		}
+		c.Writer = w
+
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if completed {
+				w.finish()
+			} else {
+				w.discard()
+			}
+		}()
+		c.Next()
+		completed = true
+	}
+}
+
+// negotiateEncoding 按请求的 Accept-Encoding 选择压缩算法
+// 返回 br、gzip，或者空字符串表示不压缩；q=0 表示不接受该算法，两者权重相同时优先 brotli
+func negotiateEncoding(acceptEncoding string, allowBrotli bool) string {
+	var gzipQ, brQ float64
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip":
+			gzipQ = q
+		case "br":
+			brQ = q
+		}
+	}
+	if allowBrotli && brQ > 0 && brQ >= gzipQ {
+		return "br"
+	}
+	if gzipQ > 0 {
+		return "gzip"
+	}
+	return ""
+}
+
+// pooledEncoder 从 sync.Pool 取出的压缩器，Close 之后放回
+type pooledEncoder struct {
+	encoder interface {
+		io.WriteCloser
+		Flush() error
+	}
+	release func()
+}
+
+func (e pooledEncoder) Write(p []byte) (int, error) { return e.encoder.Write(p) }
+
+func (e pooledEncoder) Flush() error { return e.encoder.Flush() }
+
+func (e pooledEncoder) Close() error {
+	err := e.encoder.Close()
+	e.release()
+	return err
+}
+
+// compressWriter 替换 gin.Context 的 Writer，决定是否压缩之前先把响应缓存在 buf 中
+type compressWriter struct {
+	gin.ResponseWriter
+
+	// encoding 协商的压缩算法：br 或 gzip
+	encoding string
+
+	// types 需要压缩的内容类型
+	types map[string]bool
+
+	// minSize 开始压缩的最小字节数
+	minSize int
+
+	// newEncoder 创建写入 dst 的压缩器
+	newEncoder func(dst io.Writer) io.WriteCloser
+
+	// decided 是否已经决定了压缩还是原样返回，决定之后 buf 不再使用
+	decided bool
+
+	// encoder 压缩器，原样返回时为 nil
+	encoder io.WriteCloser
+
+	// buf 还没有写出的响应内容
+	buf []byte
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	if !w.compressible(p) {
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 立即写出响应头（如 c.AbortWithStatus），之后的内容不再压缩
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush 边生成边输出的响应（如 c.Stream）无法预知长度，内容类型符合时不论大小都开始压缩
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) > 0 && w.compressible(nil))
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// compressible 按状态码和响应头判断是否可以压缩
+// 没有设置 Content-Type 时按内容推断并写入响应头，压缩后 net/http 无法再从内容推断
+func (w *compressWriter) compressible(p []byte) bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified || status == http.StatusPartialContent {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || strings.Contains(header.Get("Cache-Control"), "no-transform") {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		if len(p) == 0 {
+			return false
+		}
+		contentType = http.DetectContentType(p)
+		header.Set("Content-Type", contentType)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && w.types[mediaType]
+}
+
+// decide 决定是否压缩并写出已经缓存的内容
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	buf := w.buf
+	w.buf = nil
+
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.encoder = w.newEncoder(w.ResponseWriter)
+		if len(buf) > 0 {
+			_, err := w.encoder.Write(buf)
+			return err
+		}
+		return nil
+	}
+	if len(buf) > 0 {
+		_, err := w.ResponseWriter.Write(buf)
+		return err
+	}
+	return nil
+}
+
+// finish 请求处理完成后调用：不足 MinSize 的内容原样写出，压缩的内容写出结尾
+func (w *compressWriter) finish() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
+
+// discard 处理器 panic 时调用：丢弃还没有写出的内容，已经开始压缩的响应写出结尾
+func (w *compressWriter) discard() {
+	w.buf = nil
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
@@ -0,0 +1,105 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现自定义错误页面：不存在的路径显示 404 页面，处理请求时 panic 记录调用栈并显示 500 页面
+package middleware
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin/modules/config"
+)
+
+// NotFound 返回不存在的路径的处理器，显示带有站点标题和返回仪表板链接的 404 页面
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 处理器
+//
+// 使用示例:
+//
+//	r.NoRoute(middleware.NotFound())
+//
+// 注意事项:
+//   - 只处理没有注册路由的路径；GoAdmin 自己的路由中不存在的表格等由 GoAdmin 显示错误面板
+//   - AJAX 请求返回 JSON
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeErrorPage(c, http.StatusNotFound, "页面不存在", "您访问的页面不存在或已被删除。")
+	}
+}
+
+// Recovery 返回 panic 恢复中间件，替代 Gin 自带的 Recovery
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. 后续处理器 panic 时把错误和调用栈写入日志
+//  2. 响应还没有写出时显示 500 页面，AJAX 请求返回 JSON；已经写出部分响应时只中断请求
+//
+// 使用示例:
+//
+//	r := gin.New()
+//	r.Use(gin.Logger(), middleware.Recovery())
+//
+// 注意事项:
+//   - 应当第一个注册，之后注册的中间件和路由中的 panic 都会被恢复
+//   - GoAdmin 的页面和接口自己恢复 panic 并显示错误面板，不会经过这里
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			log.Printf("处理请求 %s %s 时 panic: %v\n%s", c.Request.Method, c.Request.URL.Path, err, debug.Stack())
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			writeErrorPage(c, http.StatusInternalServerError, "系统错误", "服务器处理请求时出错，请稍后再试。")
+		}()
+		c.Next()
+	}
+}
+
+// writeErrorPage 返回错误页面并中断请求，AJAX 请求（PJAX 除外）返回 {"code": status, "msg": title}
+func writeErrorPage(c *gin.Context, status int, title, message string) {
+	if isAJAX(c.Request) {
+		c.AbortWithStatusJSON(status, gin.H{
+			"code": status,
+			"msg":  title,
+		})
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", []byte(ErrorPage(status, title, message)))
+	c.Abort()
+}
+
+// isAJAX 判断是否是 AJAX 请求，PJAX 页面切换需要完整的页面，不算在内
+func isAJAX(r *http.Request) bool {
+	return r.Header.Get("X-Requested-With") == "XMLHttpRequest" && r.Header.Get("X-PJAX") == ""
+}
+
+// ErrorPage 生成带有站点标题和返回仪表板链接的错误页面，GoAdmin 的页面之外需要显示错误时使用
+func ErrorPage(status int, title, message string) string {
+	site := html.EscapeString(config.GetTitle())
+	return fmt.Sprintf(errorPageHTML, html.EscapeString(title), site, site,
+		status, html.EscapeString(title), html.EscapeString(message), config.Url("/"))
+}
+
+// errorPageHTML 错误页面，参数依次为错误标题、站点标题、站点标题、状态码、错误标题、说明和仪表板的地址
+const errorPageHTML = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>%s - %s</title></head>
+<body style="font-family:sans-serif;text-align:center;padding-top:12%%;color:#444">
+<div style="font-size:20px;color:#999">%s</div>
+<h1 style="font-size:72px;margin:0;color:#3c8dbc">%d</h1>
+<h2>%s</h2>
+<p>%s</p>
+<p><a href="%s">返回仪表板</a> · <a href="javascript:history.back()">返回上一页</a></p>
+</body>
+</html>`
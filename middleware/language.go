@@ -0,0 +1,113 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现界面语言中间件，按管理员在个人资料页面选择的语言显示后台
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/modules/language"
+)
+
+// LanguageCookie 保存管理员所选界面语言的 Cookie 名称，值为空表示跟随 config.yml 的 language
+const LanguageCookie = "ga_lang"
+
+// langQuery GoAdmin 读取请求语言的查询参数（见 context.Context.Lang）
+const langQuery = "__ga_lang"
+
+// Language 返回界面语言中间件
+//
+// 参数:
+//   - conn: 返回 GoAdmin 数据库连接的函数，用于按登录会话读取管理员，通常传入 eng.DefaultConnection
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. GoAdmin 按查询参数 __ga_lang 选择每个请求的语言，请求中已经带有该参数时不做任何处理
+//  2. 否则读取 LanguageCookie，把其中的语言加入请求的查询参数
+//  3. 没有该 Cookie 的已登录管理员（例如在新的浏览器中登录）从 admin_preferences 表读取一次语言并写入 Cookie
+//  4. 退出登录时清除该 Cookie
+//
+// 使用示例:
+//
+//	r.Use(middleware.Language(eng.DefaultConnection))
+//
+// 注意事项:
+//   - 与 PageViewTracker 一样必须在 eng.Use(r) 之前注册
+//   - 只处理后台路径；Cookie 中不是 language.Langs 之一的值会被忽略
+func Language(conn func() db.Connection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == config.Url("/logout") {
+			// 退出后可能由其他管理员在同一浏览器中登录，清除上一个管理员的语言
+			cookie := NewLanguageCookie("")
+			cookie.MaxAge = -1
+			http.SetCookie(c.Writer, cookie)
+			c.Next()
+			return
+		}
+		if !isAdminPath(c.Request.URL.Path) || c.Query(langQuery) != "" {
+			c.Next()
+			return
+		}
+
+		lang, err := c.Cookie(LanguageCookie)
+		if err != nil {
+			lang = preferredLanguage(c, conn)
+		}
+		if isLanguage(lang) {
+			q := c.Request.URL.Query()
+			q.Set(langQuery, lang)
+			c.Request.URL.RawQuery = q.Encode()
+		}
+		c.Next()
+	}
+}
+
+// preferredLanguage 读取已登录管理员保存的界面语言并写入 LanguageCookie，未登录时返回空字符串
+func preferredLanguage(c *gin.Context, conn func() db.Connection) string {
+	sesKey, err := c.Cookie(auth.DefaultCookieKey)
+	if err != nil || sesKey == "" {
+		return ""
+	}
+	user, ok := auth.GetCurUser(sesKey, conn())
+	if !ok {
+		return ""
+	}
+	lang := models.AdminLanguage(c.Request.Context(), user.Id)
+	http.SetCookie(c.Writer, NewLanguageCookie(lang))
+	return lang
+}
+
+// isAdminPath 判断路径是否在后台的 URL 前缀之下
+func isAdminPath(p string) bool {
+	prefix := strings.TrimSuffix(config.Url("/"), "/")
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// NewLanguageCookie 返回保存界面语言的 Cookie，有效期一年，个人资料页面修改语言时写入
+func NewLanguageCookie(lang string) *http.Cookie {
+	return &http.Cookie{
+		Name:     LanguageCookie,
+		Value:    lang,
+		Path:     "/",
+		MaxAge:   365 * 24 * 3600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// isLanguage 判断是否为 GoAdmin 支持的界面语言
+func isLanguage(lang string) bool {
+	for _, l := range language.Langs {
+		if lang == l {
+			return true
+		}
+	}
+	return false
+}
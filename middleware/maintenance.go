@@ -0,0 +1,92 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现维护模式中间件，系统设置中开启维护模式后只有超级管理员可以使用后台
+package middleware
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+)
+
+// Maintenance 返回维护模式中间件
+//
+// 参数:
+//   - conn: 返回 GoAdmin 数据库连接的函数，用于按登录会话读取管理员，通常传入 eng.DefaultConnection
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. 系统设置 maintenance_mode 关闭时不做任何处理
+//  2. 开启时，已登录的非超级管理员访问后台返回 503 和维护提示，AJAX 请求返回 JSON
+//  3. 后台以外的路径（健康检查、上传文件）、登录和退出页面、静态资源以及未登录的请求不受影响，
+//     超级管理员可以登录后关闭维护模式
+//
+// 使用示例:
+//
+//	r.Use(middleware.Maintenance(eng.DefaultConnection))
+//
+// 注意事项:
+//   - 与 PageViewTracker 一样必须在 eng.Use(r) 之前注册
+//   - 设置通过 models.Settings 读取并缓存，只有开启维护模式时才按会话读取管理员
+func Maintenance(conn func() db.Connection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !inMaintenance(c) {
+			c.Next()
+			return
+		}
+
+		sesKey, err := c.Cookie(auth.DefaultCookieKey)
+		if err != nil || sesKey == "" {
+			c.Next()
+			return
+		}
+		user, ok := auth.GetCurUser(sesKey, conn())
+		if !ok || user.IsSuperAdmin() {
+			c.Next()
+			return
+		}
+
+		if c.GetHeader("X-Requested-With") == "XMLHttpRequest" && c.GetHeader("X-PJAX") == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code": http.StatusServiceUnavailable,
+				"msg":  "系统维护中，请稍后再试",
+			})
+			return
+		}
+		title := html.EscapeString(config.GetTitle())
+		c.Data(http.StatusServiceUnavailable, "text/html; charset=utf-8", []byte(fmt.Sprintf(maintenanceHTML, title, title)))
+		c.Abort()
+	}
+}
+
+// inMaintenance 判断当前请求是否需要按维护模式处理
+func inMaintenance(c *gin.Context) bool {
+	p := c.Request.URL.Path
+	if !isAdminPath(p) || path.Ext(p) != "" {
+		return false
+	}
+	switch p {
+	case config.Url(config.GetLoginUrl()), config.Url("/signin"), config.Url("/logout"):
+		return false
+	}
+	return models.Settings.Bool(c.Request.Context(), models.SettingMaintenance, false)
+}
+
+// maintenanceHTML 维护模式下返回的页面，两个参数均为站点标题
+const maintenanceHTML = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>%s</title></head>
+<body style="font-family:sans-serif;text-align:center;padding-top:15%%;color:#444">
+<h1>%s</h1>
+<p>系统维护中，请稍后再试。</p>
+<p><a href="javascript:location.reload()">刷新</a></p>
+</body>
+</html>`
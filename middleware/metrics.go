@@ -0,0 +1,236 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现 Prometheus 指标：按路由和状态码统计请求数和耗时，与数据库连接池、缓存的指标一起以文本格式输出到 /metrics
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+// metricsBuckets 请求耗时直方图的桶上限，单位为秒，与 Prometheus 客户端库的默认值相同
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// unmatchedRoute 没有匹配路由（404）的请求使用的 route 标签，避免按任意路径产生大量时间序列
+const unmatchedRoute = "unmatched"
+
+// requestSeries 一组标签（方法、路由、状态码）的请求统计
+type requestSeries struct {
+	method, route, status string
+
+	// count 请求数
+	count uint64
+
+	// sum 累计耗时，单位为秒
+	sum float64
+
+	// buckets 每个桶中的请求数，与 metricsBuckets 一一对应，不累加
+	buckets []uint64
+}
+
+// requestMetrics 全部请求统计，键为 method、route、status 以换行连接
+var requestMetrics = struct {
+	sync.Mutex
+	series map[string]*requestSeries
+}{series: map[string]*requestSeries{}}
+
+// Metrics 返回统计请求数和耗时的中间件
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. route 标签取注册路由时的路径模式（如 /admin/info/:__prefix），不是实际的请求路径
+//  2. 没有匹配路由的请求统一记为 unmatched
+//
+// 使用示例:
+//
+//	r.Use(middleware.Metrics())
+//	r.GET("/metrics", middleware.MetricsHandler(os.Getenv("METRICS_USERNAME"), os.Getenv("METRICS_PASSWORD")))
+//
+// 注意事项:
+//   - 与 PageViewTracker 一样必须在 eng.Use(r) 之前注册
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		observeRequest(c.Request.Method, c.FullPath(), responseStatus(c), time.Since(start))
+	}
+}
+
+// observeRequest 记录一次请求
+func observeRequest(method, route string, status int, d time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	statusText := strconv.Itoa(status)
+	key := method + "\n" + route + "\n" + statusText
+	seconds := d.Seconds()
+
+	requestMetrics.Lock()
+	defer requestMetrics.Unlock()
+	s, ok := requestMetrics.series[key]
+	if !ok {
+		s = &requestSeries{method: method, route: route, status: statusText, buckets: make([]uint64, len(metricsBuckets))}
+		requestMetrics.series[key] = s
+	}
+	s.count++
+	s.sum += seconds
+	for i, le := range metricsBuckets {
+		if seconds <= le {
+			s.buckets[i]++
+			break
+		}
+	}
+}
+
+// MetricsHandler 返回以 Prometheus 文本格式输出指标的处理器
+//
+// 参数:
+//   - username: Basic 认证的用户名，为空时不需要认证
+//   - password: Basic 认证的密码
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 处理器
+//
+// 输出的指标:
+//   - http_requests_total、http_request_duration_seconds: 按 method、route、status 统计的请求数和耗时
+//   - goadmin_db_*: 主库和只读副本的连接池，按 database 区分（见 models.DatabasePools）
+//   - goadmin_cache_hits_total、goadmin_cache_misses_total: 统计数据缓存的命中次数
+func MetricsHandler(username, password string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if username != "" && !checkBasicAuth(c, username, password) {
+			c.Header("WWW-Authenticate", `Basic realm="metrics"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		var b strings.Builder
+		writeRequestMetrics(&b)
+		writeDatabaseMetrics(&b)
+		writeCacheMetrics(&b)
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+	}
+}
+
+// checkBasicAuth 检查请求的 Basic 认证，比较时间与内容无关
+func checkBasicAuth(c *gin.Context, username, password string) bool {
+	u, p, ok := c.Request.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(u), []byte(username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+	return userOK && passOK
+}
+
+// writeRequestMetrics 输出请求数和耗时直方图，按标签排序保证每次输出的顺序一致
+func writeRequestMetrics(w io.Writer) {
+	requestMetrics.Lock()
+	list := make([]requestSeries, 0, len(requestMetrics.series))
+	for _, s := range requestMetrics.series {
+		cp := *s
+		cp.buckets = append([]uint64(nil), s.buckets...)
+		list = append(list, cp)
+	}
+	requestMetrics.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].route != list[j].route {
+			return list[i].route < list[j].route
+		}
+		if list[i].method != list[j].method {
+			return list[i].method < list[j].method
+		}
+		return list[i].status < list[j].status
+	})
+
+	writeHeader(w, "http_requests_total", "counter", "按路由和状态码统计的请求数")
+	for _, s := range list {
+		fmt.Fprintf(w, "http_requests_total{%s} %d\n", s.labels(), s.count)
+	}
+
+	writeHeader(w, "http_request_duration_seconds", "histogram", "按路由和状态码统计的请求耗时")
+	for _, s := range list {
+		labels := s.labels()
+		var cumulative uint64
+		for i, le := range metricsBuckets {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+}
+
+// labels 返回该组统计的标签
+func (s requestSeries) labels() string {
+	return fmt.Sprintf(`method="%s",route="%s",status="%s"`, escapeLabel(s.method), escapeLabel(s.route), s.status)
+}
+
+// writeDatabaseMetrics 输出主库和只读副本的连接池指标
+func writeDatabaseMetrics(w io.Writer) {
+	pools := models.DatabasePools()
+	metrics := []struct {
+		name, kind, help string
+		value            func(p models.DatabasePool) string
+	}{
+		{"goadmin_db_max_open_connections", "gauge", "最大打开连接数，0 表示不限制", func(p models.DatabasePool) string {
+			return strconv.Itoa(p.Stats.MaxOpenConnections)
+		}},
+		{"goadmin_db_open_connections", "gauge", "当前打开的连接数", func(p models.DatabasePool) string {
+			return strconv.Itoa(p.Stats.OpenConnections)
+		}},
+		{"goadmin_db_in_use_connections", "gauge", "正在使用的连接数", func(p models.DatabasePool) string {
+			return strconv.Itoa(p.Stats.InUse)
+		}},
+		{"goadmin_db_idle_connections", "gauge", "空闲的连接数", func(p models.DatabasePool) string {
+			return strconv.Itoa(p.Stats.Idle)
+		}},
+		{"goadmin_db_wait_count_total", "counter", "因连接数达到上限而等待的次数", func(p models.DatabasePool) string {
+			return strconv.FormatInt(p.Stats.WaitCount, 10)
+		}},
+		{"goadmin_db_wait_duration_seconds_total", "counter", "等待连接的累计耗时", func(p models.DatabasePool) string {
+			return formatFloat(p.Stats.WaitDuration.Seconds())
+		}},
+	}
+	for _, m := range metrics {
+		writeHeader(w, m.name, m.kind, m.help)
+		for _, p := range pools {
+			fmt.Fprintf(w, "%s{database=\"%s\"} %s\n", m.name, escapeLabel(p.Name), m.value(p))
+		}
+	}
+}
+
+// writeCacheMetrics 输出统计数据缓存的命中次数
+func writeCacheMetrics(w io.Writer) {
+	s := models.CacheMetrics()
+	writeHeader(w, "goadmin_cache_hits_total", "counter", "缓存命中次数")
+	fmt.Fprintf(w, "goadmin_cache_hits_total %d\n", s.Hits)
+	writeHeader(w, "goadmin_cache_misses_total", "counter", "缓存未命中次数")
+	fmt.Fprintf(w, "goadmin_cache_misses_total %d\n", s.Misses)
+}
+
+// writeHeader 输出指标的说明和类型
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat 以最短的形式输出浮点数
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
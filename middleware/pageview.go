@@ -0,0 +1,64 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现页面访问统计中间件，为仪表板的浏览器使用情况组件提供数据
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+// PageViewTracker 返回记录后台页面访问的中间件
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. 先执行后续处理器，只在响应为 200 且内容为 HTML 时记录访问
+//  2. 跳过静态资源（路径带扩展名）和 AJAX 请求，PJAX 页面切换仍然计入
+//  3. 访问记录在新的 goroutine 中写入，不阻塞响应
+//
+// 使用示例:
+//
+//	r := gin.Default()
+//	r.Use(middleware.PageViewTracker())
+//
+// 注意事项:
+//   - 必须在 eng.Use(r) 之前注册，Gin 只对之后注册的路由应用中间件
+//   - 写入依赖 models.Init 初始化的ORM实例，启动阶段 models.Init 之前不会有页面请求
+func PageViewTracker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !shouldTrack(c) {
+			return
+		}
+
+		p := c.Request.URL.Path
+		ua := c.Request.UserAgent()
+		models.RunWorker(func() {
+			if err := models.RecordPageView(context.Background(), p, ua); err != nil {
+				log.Printf("记录页面访问失败: %s\n", err)
+			}
+		})
+	}
+}
+
+// shouldTrack 判断当前请求是否是一次需要记录的页面访问
+func shouldTrack(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet || c.Writer.Status() != http.StatusOK {
+		return false
+	}
+	if path.Ext(c.Request.URL.Path) != "" {
+		return false
+	}
+	if c.GetHeader("X-Requested-With") == "XMLHttpRequest" && c.GetHeader("X-PJAX") == "" {
+		return false
+	}
+	return strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/html")
+}
@@ -0,0 +1,80 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现只允许超级管理员访问的 pprof 性能分析接口，可以在接近生产的环境中采集 CPU 和内存的分析数据
+package middleware
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+)
+
+// SuperAdmin 返回只允许超级管理员访问的中间件，用于不经过 GoAdmin 路由的接口
+//
+// 参数:
+//   - conn: 返回 GoAdmin 数据库连接的函数，用于按登录会话读取管理员，通常传入 eng.DefaultConnection
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. 未登录时跳转到登录页面
+//  2. 已登录的非超级管理员返回 403 页面
+func SuperAdmin(conn func() db.Connection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sesKey, _ := c.Cookie(auth.DefaultCookieKey)
+		user, ok := auth.GetCurUser(sesKey, conn())
+		if !ok {
+			c.Redirect(http.StatusFound, config.Url(config.GetLoginUrl()))
+			c.Abort()
+			return
+		}
+		if !user.IsSuperAdmin() {
+			writeErrorPage(c, http.StatusForbidden, "没有权限", "只有超级管理员可以访问该页面。")
+			return
+		}
+		c.Next()
+	}
+}
+
+// PprofPath pprof 性能分析接口在后台 URL 前缀下的路径，注册路由时经过 config.Url
+const PprofPath = "/debug/pprof/"
+
+// Pprof 返回 pprof 性能分析接口的处理器，路由的最后一段为通配参数 name
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 处理器
+//
+// 功能说明:
+//  1. name 为空时显示分析数据的列表
+//  2. profile（CPU，默认采样 30 秒）、trace、cmdline、symbol 以及 heap、goroutine 等运行时分析数据与 net/http/pprof 相同
+//
+// 使用示例:
+//
+//	r.GET(config.Url(middleware.PprofPath+"*name"), middleware.SuperAdmin(eng.DefaultConnection), middleware.Pprof())
+//
+// 注意事项:
+//   - net/http/pprof 的 Index 只识别 /debug/pprof/ 开头的路径，这里按 name 分别调用对应的处理器
+//   - go tool pprof 不能携带登录会话，需要先用浏览器或带 go_admin_session Cookie 的 curl 下载分析数据
+func Pprof() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch name := strings.Trim(c.Param("name"), "/"); name {
+		case "":
+			pprof.Index(c.Writer, c.Request)
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+		}
+	}
+}
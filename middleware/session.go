@@ -0,0 +1,104 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现登录会话的记录中间件，为"登录会话"表格记录每个会话的 IP、浏览器和最近活动时间
+package middleware
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/db"
+)
+
+// sessionTouchInterval 同一个会话两次记录的最短间隔，避免每个请求都读写数据库
+const sessionTouchInterval = time.Minute
+
+// sessionTouchLimit 内存中记录上次写入时间的会话数上限，超过时清空重新计时
+const sessionTouchLimit = 10000
+
+// sessionTouches 各会话上次写入的时间
+var sessionTouches = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: map[string]time.Time{}}
+
+// SessionTracker 返回记录登录会话的中间件
+//
+// 参数:
+//   - conn: 返回 GoAdmin 数据库连接的函数，用于按会话编号读取登录的管理员，通常传入 eng.DefaultConnection
+//
+// 返回值:
+//   - gin.HandlerFunc: Gin 中间件
+//
+// 功能说明:
+//  1. 先执行后续处理器，只处理后台路径下带有 go_admin_session Cookie 的请求
+//  2. 同一个会话每 sessionTouchInterval 最多记录一次，按会话编号读取登录的管理员，未登录的会话不记录
+//  3. 在新的 goroutine 中写入客户端 IP、User-Agent 和时间（见 models.TouchAdminSession），不阻塞响应
+//
+// 使用示例:
+//
+//	r.Use(middleware.SessionTracker(eng.DefaultConnection))
+//
+// 注意事项:
+//   - 与 PageViewTracker 一样必须在 eng.Use(r) 之前注册
+//   - 客户端 IP 取自 gin.Context.ClientIP，经过反向代理时需要配置 Gin 信任的代理
+func SessionTracker(conn func() db.Connection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !isAdminPath(c.Request.URL.Path) {
+			return
+		}
+		sid, err := c.Cookie(auth.DefaultCookieKey)
+		if err != nil || sid == "" || !sessionTouchDue(sid, time.Now()) {
+			return
+		}
+
+		ip, ua := c.ClientIP(), c.Request.UserAgent()
+		models.RunWorker(func() {
+			v, err := auth.GetSessionByKey(sid, "user_id", conn())
+			if err != nil {
+				log.Printf("读取登录会话失败: %s\n", err)
+				return
+			}
+			userID := sessionUserID(v)
+			if userID == 0 {
+				return
+			}
+			if err := models.TouchAdminSession(context.Background(), sid, userID, ip, ua); err != nil {
+				log.Printf("记录登录会话失败: %s\n", err)
+			}
+		})
+	}
+}
+
+// sessionTouchDue 判断会话是否需要再次记录，需要时把上次写入时间设为 now
+func sessionTouchDue(sid string, now time.Time) bool {
+	sessionTouches.Lock()
+	defer sessionTouches.Unlock()
+	if last, ok := sessionTouches.last[sid]; ok && now.Sub(last) < sessionTouchInterval {
+		return false
+	}
+	if len(sessionTouches.last) >= sessionTouchLimit {
+		sessionTouches.last = map[string]time.Time{}
+	}
+	sessionTouches.last[sid] = now
+	return true
+}
+
+// sessionUserID 读取会话中的管理员 ID，GoAdmin 按 JSON 保存会话内容，数字读出为 float64
+func sessionUserID(v interface{}) int64 {
+	switch v := v.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		id, _ := strconv.ParseInt(v, 10, 64)
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+// Package middleware 提供注册在 Gin 路由器上的 HTTP 中间件
+// 本文件实现请求超时：处理时间超过 request_timeout 配置项的请求直接返回 503 页面，
+// 请求的 context 同时被取消，仍在执行的数据库查询随之中止
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gopkg.in/yaml.v2"
+)
+
+// TimeoutConfig 请求超时配置，对应 config.yml 的 request_timeout 配置项
+type TimeoutConfig struct {
+	// Timeout 单个请求的最长处理时间，0 表示不限制
+	Timeout time.Duration `yaml:"timeout"`
+
+	// ExcludePaths 不限制处理时间的路径前缀，如上传文件的下载
+	// pprof 的 CPU 分析默认采样 30 秒，后台 URL 前缀下的 PprofPath 总是不限制，不需要加入
+	ExcludePaths []string `yaml:"exclude_paths"`
+}
+
+// DefaultTimeoutConfig 配置文件中没有 request_timeout 配置项时使用的默认配置
+var DefaultTimeoutConfig = TimeoutConfig{
+	Timeout:      30 * time.Second,
+	ExcludePaths: []string{"/uploads/"},
+}
+
+// LoadTimeoutConfig 从 YAML 配置文件的 request_timeout 配置项读取请求超时配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - TimeoutConfig: 配置项中未设置的字段使用 DefaultTimeoutConfig 中的值
+//   - error: 读取或解析失败，或超时时间为负数时返回错误
+func LoadTimeoutConfig(path string) (TimeoutConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return TimeoutConfig{}, err
+	}
+
+	cfg := struct {
+		Timeout TimeoutConfig `yaml:"request_timeout"`
+	}{Timeout: DefaultTimeoutConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return TimeoutConfig{}, fmt.Errorf("解析请求超时配置失败: %v", err)
+	}
+	if cfg.Timeout.Timeout < 0 {
+		return TimeoutConfig{}, fmt.Errorf("请求超时配置的 timeout 不能为负数: %s", cfg.Timeout.Timeout)
+	}
+	return cfg.Timeout, nil
+}
+
+// Timeout 为 HTTP 处理器加上处理时间的限制
+//
+// 参数:
+//   - h: 处理器，通常为 Gin 路由器，GoAdmin 的页面和本项目的自定义页面都注册在上面
+//   - cfg: 请求超时配置，通常由 LoadTimeoutConfig 读取
+//
+// 返回值:
+//   - http.Handler: cfg.Timeout 为 0 时直接返回 h
+//
+// 功能说明:
+//  1. 使用 http.TimeoutHandler：超时后立即返回 503，之后处理器写入的内容被丢弃
+//  2. 请求的 context 在超时时被取消，使用 ctx 的数据库查询（models 包的各查询）随之中止
+//  3. 超时页面与 404、500 页面样式相同，AJAX 请求返回 {"code": 503, "msg": "请求超时"}
+//
+// 使用示例:
+//
+//	srv := &http.Server{
+//	    Addr:    addr,
+//	    Handler: middleware.Timeout(r, timeoutCfg),
+//	}
+//
+// 注意事项:
+//   - 响应在处理完成前缓存在内存中，不能边生成边输出，也不支持 WebSocket，这类路径需要加入 ExcludePaths
+//   - 需要在 GoAdmin 的配置生效之后调用，pprof 接口按当时的后台 URL 前缀排除
+//   - 超时后处理器仍在后台执行到返回为止，只是结果不再发送；访问日志和指标中记为 503
+func Timeout(h http.Handler, cfg TimeoutConfig) http.Handler {
+	if cfg.Timeout <= 0 {
+		return h
+	}
+
+	jsonBody, _ := json.Marshal(gin.H{"code": http.StatusServiceUnavailable, "msg": "请求超时"})
+	exclude := append([]string{config.Url(PprofPath)}, cfg.ExcludePaths...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range exclude {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+		// TimeoutHandler 不设置超时响应的 Content-Type，由 net/http 按内容推断为 text/html 或 text/plain
+		body := string(jsonBody)
+		if !isAJAX(r) {
+			body = ErrorPage(http.StatusServiceUnavailable, "请求超时", "服务器处理请求的时间过长，请稍后再试或缩小查询范围。")
+		}
+		http.TimeoutHandler(h, cfg.Timeout, body).ServeHTTP(w, r)
+	})
+}
+
+// timedOut 判断请求是否已经被 Timeout 中止，此时客户端收到的是 503，而不是处理器设置的状态码
+func timedOut(c *gin.Context) bool {
+	return errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
+
+// responseStatus 返回客户端实际收到的状态码，访问日志和指标使用
+func responseStatus(c *gin.Context) int {
+	if timedOut(c) {
+		return http.StatusServiceUnavailable
+	}
+	return c.Writer.Status()
+}
@@ -0,0 +1,138 @@
+// GoAdmin 示例项目 - 数据库迁移命令
+// 本文件实现 migrate 子命令，不启动服务器，直接对配置文件（默认为 config.yml）中的 default 数据库执行迁移
+//
+// 用法:
+//
+//	go run . migrate up          执行所有未执行的迁移
+//	go run . migrate down [n]    回滚最近 n 个迁移，默认为 1
+//	go run . migrate status      查看每个迁移是否已执行
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/purpose168/GoAdmin-example/app"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/models/migrations"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+)
+
+// migrateUsage migrate 子命令的用法说明
+const migrateUsage = `用法: go run . migrate <up|down [n]|status>`
+
+// runMigrate 执行 migrate 子命令
+//
+// 参数:
+//   - opts: 启动参数，决定配置文件路径和 default 数据库的 DSN
+//   - args: migrate 之后的命令行参数
+//
+// 返回值:
+//   - error: 参数错误、读取配置失败或迁移失败时返回错误
+func runMigrate(opts app.Options, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, _, err := openDefaultDatabase(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		ran, err := migrations.Up(ctx, db)
+		for _, m := range ran {
+			fmt.Printf("已执行 %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ran) == 0 {
+			fmt.Println("没有需要执行的迁移")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("回滚数量无效: %s", args[1])
+			}
+		}
+		rolled, err := migrations.Down(ctx, db, steps)
+		for _, m := range rolled {
+			fmt.Printf("已回滚 %s_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(rolled) == 0 {
+			fmt.Println("没有可以回滚的迁移")
+		}
+	case "status":
+		list, err := migrations.List(ctx, db)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "版本\t名称\t执行时间")
+		for _, s := range list {
+			appliedAt := "未执行"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// openDefaultDatabase 连接配置文件中的 default 数据库，供不启动服务器的子命令使用
+// 与启动服务器时一样，opts.DSN 非空时以其为准
+// 语句超时是为请求设置的，迁移中重建表等语句可能超过该时间，这里不设置超时
+func openDefaultDatabase(opts app.Options) (*gorm.DB, config.Database, error) {
+	dbCfg, err := defaultDatabase(opts.ConfigPath)
+	if err != nil {
+		return nil, dbCfg, err
+	}
+	if opts.DSN != "" {
+		dbCfg.Dsn = opts.DSN
+	}
+	ormCfg, err := models.LoadORMConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, dbCfg, err
+	}
+	ormCfg.QueryTimeout = 0
+	db, err := models.Open(dbCfg.Driver, dbCfg.GetDSN(), ormCfg)
+	return db, dbCfg, err
+}
+
+// defaultDatabase 从配置文件读取 default 数据库的驱动和连接信息
+// 不经过 GoAdmin 引擎，迁移时不需要 goadmin_site 等框架表已经存在
+func defaultDatabase(path string) (config.Database, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config.Database{}, err
+	}
+	var cfg struct {
+		Database config.DatabaseList `yaml:"database"`
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return config.Database{}, err
+	}
+	if _, ok := cfg.Database["default"]; !ok {
+		return config.Database{}, fmt.Errorf("配置文件中没有 default 数据库")
+	}
+	return cfg.Database.GetDefault(), nil
+}
@@ -0,0 +1,235 @@
+// models 包 - 数据模型层
+// 本文件实现当前管理员的个人资料：显示名称、头像和密码保存在 GoAdmin 的 goadmin_users 表中，
+// 邮箱和界面语言等个人偏好保存在 admin_preferences 表中
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrWrongPassword 修改密码时填写的当前密码不正确
+var ErrWrongPassword = errors.New("当前密码不正确")
+
+// AdminProfile 管理员的个人资料
+type AdminProfile struct {
+	// ID 管理员ID，即 goadmin_users.id
+	ID int64 `gorm:"column:id"`
+
+	// Username 登录名，个人资料页面中不能修改
+	Username string `gorm:"column:username"`
+
+	// Name 显示名称，显示在顶部导航栏和审计日志中
+	Name string `gorm:"column:name"`
+
+	// Avatar 头像，格式与头像存储返回的地址相同（见 AvatarStorage），为空表示未上传
+	Avatar string `gorm:"column:avatar"`
+
+	// Email 邮箱，使用第三方账号登录时按邮箱关联管理员（见 OAuthLogin），来自 admin_preferences 表
+	Email string `gorm:"-"`
+
+	// Language 界面语言，为空表示跟随 config.yml 的 language，来自 admin_preferences 表
+	Language string `gorm:"-"`
+}
+
+// AdminPreference 管理员个人偏好模型
+// 该结构体映射到 admin_preferences 表，每个管理员最多一条记录
+type AdminPreference struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id，唯一
+	UserID int64 `gorm:"column:user_id"`
+
+	// Language 界面语言，为空表示跟随 config.yml 的 language
+	Language string `gorm:"column:language"`
+
+	// Email 邮箱，可以为空，非空时不能与其他管理员重复
+	Email string `gorm:"column:email"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 AdminPreference 对应的数据库表名
+func (AdminPreference) TableName() string {
+	return "admin_preferences"
+}
+
+// FindAdminProfile 读取管理员的个人资料
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - id: 管理员ID
+//
+// 返回值:
+//   - AdminProfile: 个人资料，没有保存过个人偏好时 Email 和 Language 为空
+//   - error: 管理员不存在时返回 gorm.ErrRecordNotFound
+//
+// 注意事项:
+//   - 修改后页面立即重新读取，与仪表板布局一样从主库读取
+func FindAdminProfile(ctx context.Context, id int64) (AdminProfile, error) {
+	var p AdminProfile
+	if err := orm.WithContext(ctx).Table("goadmin_users").
+		Select("id, username, name, avatar").Where("id = ?", id).Take(&p).Error; err != nil {
+		return p, err
+	}
+	var pref AdminPreference
+	if err := orm.WithContext(ctx).Where("user_id = ?", id).Take(&pref).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return p, err
+	}
+	p.Email, p.Language = pref.Email, pref.Language
+	return p, nil
+}
+
+// AdminLanguage 返回管理员选择的界面语言，没有选择过或读取失败时返回空字符串
+func AdminLanguage(ctx context.Context, id int64) string {
+	var pref AdminPreference
+	if err := orm.WithContext(ctx).Where("user_id = ?", id).Take(&pref).Error; err != nil {
+		return ""
+	}
+	return pref.Language
+}
+
+// UpdateAdminProfile 修改管理员的显示名称、邮箱、头像和界面语言
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 管理员ID
+//   - name: 显示名称，必填，不超过 50 个字符
+//   - email: 邮箱，可以为空，非空时不能与其他管理员重复
+//   - avatar: 新头像的地址，为空表示不修改头像
+//   - language: 界面语言，为空表示跟随 config.yml 的 language；调用方负责检查是否是支持的语言
+//
+// 返回值:
+//   - error: 名称或邮箱不符合规则时返回 ValidationErrors，管理员不存在时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+func UpdateAdminProfile(ctx context.Context, id int64, name, email, avatar, language string) error {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	var errs ValidationErrors
+	if msg := userNameError(name); msg != "" {
+		errs = append(errs, FieldError{Field: "name", Label: "名称", Message: msg})
+	}
+	msg, err := adminEmailError(ctx, id, email)
+	if err != nil {
+		return err
+	}
+	if msg != "" {
+		errs = append(errs, FieldError{Field: "email", Label: "邮箱", Message: msg})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// goadmin_users 由 GoAdmin 创建，updated_at 不是 GORM 模型的字段，需要手动填写
+	values := map[string]interface{}{"name": name, "updated_at": time.Now()}
+	if avatar != "" {
+		values["avatar"] = avatar
+	}
+	res := writer(ctx).Table("goadmin_users").Where("id = ?", id).Updates(values)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	var pref AdminPreference
+	// Assign 使用 map，email、language 为空字符串时同样会更新
+	return writer(ctx).Where(AdminPreference{UserID: id}).
+		Assign(map[string]interface{}{"email": email, "language": language}).
+		FirstOrCreate(&pref).Error
+}
+
+// adminEmailError 校验管理员邮箱的格式，并检查是否已被其他管理员使用，通过时返回空字符串
+// 规则与用户表单的邮箱相同（见 userEmailError），比较时不区分大小写
+func adminEmailError(ctx context.Context, id int64, email string) (string, error) {
+	if email == "" {
+		return "", nil
+	}
+	if utf8.RuneCountInString(email) > 100 {
+		return "不能超过 100 个字符", nil
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return "格式不正确", nil
+	}
+
+	var count int64
+	if err := writer(ctx).Model(&AdminPreference{}).
+		Where("LOWER(email) = ? AND user_id <> ?", strings.ToLower(email), id).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "已被其他管理员使用", nil
+	}
+	return "", nil
+}
+
+// ChangeAdminPassword 修改管理员的登录密码
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 管理员ID
+//   - current: 当前密码，必须与保存的密码一致
+//   - password, confirm: 新密码和确认密码，规则与用户表单相同（见 ValidateUser）
+//   - keepSID: 修改密码的当前会话编号，修改后该管理员的其他登录会话全部失效
+//
+// 返回值:
+//   - error: 当前密码不正确时返回 ErrWrongPassword，新密码为空时返回 ErrEmptyPassword，
+//     不符合规则时返回 ValidationErrors，管理员不存在时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+//
+// 注意事项:
+//   - 密码以 bcrypt 哈希保存，与 GoAdmin 登录时的校验方式相同；除 keepSID 之外的会话被踢出（见 RevokeAdminSessions）
+//   - JSON 接口的刷新令牌全部吊销（见 RevokeRefreshTokens），已签发的访问令牌在过期前仍然有效
+func ChangeAdminPassword(ctx context.Context, id int64, current, password, confirm, keepSID string) error {
+	var hash string
+	if err := writer(ctx).Table("goadmin_users").Select("password").Where("id = ?", id).Row().Scan(&hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(current)) != nil {
+		return ErrWrongPassword
+	}
+
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	var errs ValidationErrors
+	passwordMsg, confirmMsg := userPasswordErrors(password, confirm)
+	if passwordMsg != "" {
+		errs = append(errs, FieldError{Field: "password", Label: "新密码", Message: passwordMsg})
+	}
+	if confirmMsg != "" {
+		errs = append(errs, FieldError{Field: "password_again", Label: "确认密码", Message: confirmMsg})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	newHash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	// 修改密码后其他浏览器中的登录会话和 JSON 接口的刷新令牌随之失效，需要用新密码重新登录
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		err := writer(ctx).Table("goadmin_users").Where("id = ?", id).
+			Updates(map[string]interface{}{"password": newHash, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return RevokeAdminLogins(ctx, id, keepSID)
+	})
+}
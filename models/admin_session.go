@@ -0,0 +1,147 @@
+// models 包 - 数据模型层
+// 本文件实现后台登录会话的管理：GoAdmin 把会话保存在 goadmin_session 表中，只有会话编号和管理员 ID，
+// 这里在 admin_sessions 表中另外记录每个会话的 IP、浏览器和最近活动时间，并提供踢出会话的操作
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// goadminSession GoAdmin 保存登录会话的 goadmin_session 表，只用于按会话编号查询和删除
+type goadminSession struct {
+	Sid string `gorm:"column:sid"`
+}
+
+// TableName 指定 goadminSession 对应的数据库表名
+func (goadminSession) TableName() string {
+	return "goadmin_session"
+}
+
+// AdminSession 后台登录会话的附加信息模型
+type AdminSession struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Sid 会话编号，即 go_admin_session Cookie 的值和 goadmin_session.sid
+	Sid string `gorm:"column:sid"`
+
+	// UserID 登录的管理员 ID，对应 goadmin_users 表的 id
+	UserID int64 `gorm:"column:user_id"`
+
+	// IP 最近一次请求的客户端 IP
+	IP string `gorm:"column:ip"`
+
+	// UserAgent 最近一次请求的 User-Agent
+	UserAgent string `gorm:"column:user_agent"`
+
+	// LastActiveAt 最近一次请求的时间，精确到记录的最短间隔（见 middleware.SessionTracker）
+	LastActiveAt time.Time `gorm:"column:last_active_at"`
+
+	// CreatedAt 第一次记录到该会话的时间，即登录后的第一个请求
+	CreatedAt time.Time
+}
+
+// TableName 指定 AdminSession 对应的数据库表名
+func (AdminSession) TableName() string {
+	return "admin_sessions"
+}
+
+// TouchAdminSession 记录会话的一次请求，更新 IP、浏览器和最近活动时间，第一次记录时新增
+//
+// 参数:
+//   - ctx: 上下文
+//   - sid: 会话编号
+//   - userID: 会话中登录的管理员 ID
+//   - ip, userAgent: 请求的客户端 IP 和 User-Agent
+//
+// 注意事项:
+//   - 新增记录时顺便删除 goadmin_session 中已经不存在的会话（退出登录、过期或被踢出）的记录
+func TouchAdminSession(ctx context.Context, sid string, userID int64, ip, userAgent string) error {
+	now := time.Now()
+	res := writer(ctx).Model(&AdminSession{}).Where("sid = ?", sid).
+		UpdateColumns(map[string]interface{}{"user_id": userID, "ip": ip, "user_agent": userAgent, "last_active_at": now})
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+
+	err := writer(ctx).Create(&AdminSession{
+		Sid:          sid,
+		UserID:       userID,
+		IP:           ip,
+		UserAgent:    userAgent,
+		LastActiveAt: now,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return writer(ctx).Where("sid NOT IN (?)", writer(ctx).Model(&goadminSession{}).Select("sid")).
+		Delete(&AdminSession{}).Error
+}
+
+// FindAdminSession 按编号读取会话记录，不存在时返回 gorm.ErrRecordNotFound
+func FindAdminSession(ctx context.Context, id string) (AdminSession, error) {
+	var s AdminSession
+	err := writer(ctx).Where("id = ?", id).Take(&s).Error
+	return s, err
+}
+
+// AdminSessionID 返回会话编号对应的记录编号，没有记录时返回 0，用于在列表中标出当前会话
+func AdminSessionID(ctx context.Context, sid string) uint {
+	var s AdminSession
+	writer(ctx).Select("id").Where("sid = ?", sid).Limit(1).Find(&s)
+	return s.ID
+}
+
+// KickAdminSession 踢出会话：删除 GoAdmin 的会话记录，使用该会话的浏览器下一次请求时需要重新登录
+func KickAdminSession(ctx context.Context, sid string) error {
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("sid = ?", sid).Delete(&goadminSession{}).Error; err != nil {
+			return err
+		}
+		return writer(ctx).Where("sid = ?", sid).Delete(&AdminSession{}).Error
+	})
+}
+
+// RevokeAdminSessions 踢出管理员除 keepSID 之外的全部会话，修改密码后调用
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - userID: 管理员 ID
+//   - keepSID: 保留的会话编号，通常为修改密码的当前会话；为空时踢出全部会话
+//
+// 注意事项:
+//   - 除了 admin_sessions 中记录的会话，还按 GoAdmin 写入的会话内容（{"user_id":ID}）匹配，
+//     没有经过 middleware.SessionTracker 记录的会话同样会被踢出
+func RevokeAdminSessions(ctx context.Context, userID int64, keepSID string) error {
+	values, err := json.Marshal(map[string]interface{}{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	tracked := writer(ctx).Model(&AdminSession{}).Select("sid").Where("user_id = ?", userID)
+	err = writer(ctx).
+		Where("sid <> ?", keepSID).
+		Where(writer(ctx).Where(clause.Eq{Column: clause.Column{Name: "values"}, Value: string(values)}).
+			Or("sid IN (?)", tracked)).
+		Delete(&goadminSession{}).Error
+	if err != nil {
+		return err
+	}
+	return writer(ctx).Where("user_id = ? AND sid <> ?", userID, keepSID).Delete(&AdminSession{}).Error
+}
+
+// RevokeAdminLogins 在一个事务中踢出管理员除 keepSID 之外的全部会话，并吊销 JSON 接口的全部刷新令牌
+// 管理员的密码被修改或重置后调用（见 ChangeAdminPassword 和 tables.ManagerGenerators），已签发的访问令牌在过期前仍然有效
+func RevokeAdminLogins(ctx context.Context, userID int64, keepSID string) error {
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := RevokeAdminSessions(ctx, userID, keepSID); err != nil {
+			return err
+		}
+		return RevokeRefreshTokens(ctx, userID)
+	})
+}
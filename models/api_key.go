@@ -0,0 +1,218 @@
+// models 包 - 数据模型层
+// 本文件定义 API 密钥模型：JSON 接口的调用方在请求头中携带密钥，密钥代表签发它的管理员，
+// 按权限范围（read、write）和允许的表格进一步限制；数据库中只保存密钥的 SHA-256
+
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API 密钥的权限范围，与 api_keys 表 scopes 字段中保存的值一致
+const (
+	// APIScopeRead 读取列表和单条记录
+	APIScopeRead = "read"
+
+	// APIScopeWrite 新增、修改和删除记录，不包含读取
+	APIScopeWrite = "write"
+)
+
+// APIKeyTokenPrefix 密钥明文的固定开头，便于在代码和日志中识别泄露的密钥
+const APIKeyTokenPrefix = "gak_"
+
+// apiKeyLength 密钥明文的长度：固定开头加 32 字节随机数的十六进制
+const apiKeyLength = len(APIKeyTokenPrefix) + 64
+
+// apiKeyDisplayLength 列表中显示的密钥开头部分的长度，用于辨认是哪一个密钥
+const apiKeyDisplayLength = len(APIKeyTokenPrefix) + 8
+
+// apiKeyTouchInterval 更新最近使用时间的最短间隔，避免每个请求都写一次数据库
+const apiKeyTouchInterval = time.Minute
+
+// ErrInvalidAPIKey 密钥不存在、已吊销或已过期
+var ErrInvalidAPIKey = errors.New("API 密钥无效、已吊销或已过期")
+
+// APIKey API 密钥模型
+type APIKey struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 名称，说明密钥的用途，如"库存同步脚本"
+	Name string `gorm:"column:name"`
+
+	// Prefix 密钥明文的开头部分，在列表中辨认密钥
+	Prefix string `gorm:"column:prefix"`
+
+	// KeyHash 密钥明文的 SHA-256（十六进制），见 HashAPIKey
+	KeyHash string `gorm:"column:key_hash"`
+
+	// Scopes 权限范围，逗号分隔的 APIScopeRead、APIScopeWrite
+	Scopes string `gorm:"column:scopes"`
+
+	// Tables 允许访问的表格前缀，逗号分隔，为空表示创建人有权限的全部表格
+	Tables string `gorm:"column:tables"`
+
+	// CreatedBy 签发密钥的管理员 ID，接口以该管理员的角色和权限访问表格
+	CreatedBy int64 `gorm:"column:created_by"`
+
+	// ExpiresAt 过期时间，为空表示永不过期
+	ExpiresAt *time.Time `gorm:"column:expires_at"`
+
+	// LastUsedAt 最近一次通过验证的时间，精确到 apiKeyTouchInterval
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+
+	// RevokedAt 吊销时间，吊销后密钥立即失效，不能恢复
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time
+}
+
+// TableName 指定 APIKey 对应的数据库表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope 判断密钥是否有该权限范围
+func (k APIKey) HasScope(scope string) bool {
+	return containsItem(k.Scopes, scope)
+}
+
+// AllowsTable 判断密钥是否可以访问该表格，Tables 为空时不限制
+// 是否真正可以访问还取决于创建人的权限
+func (k APIKey) AllowsTable(prefix string) bool {
+	return strings.TrimSpace(k.Tables) == "" || containsItem(k.Tables, prefix)
+}
+
+// Active 判断密钥在 now 时是否可用：没有吊销，也没有过期
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// containsItem 判断逗号分隔的列表中是否有该项
+func containsItem(list, item string) bool {
+	for _, s := range strings.Split(list, ",") {
+		if strings.TrimSpace(s) == item {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIKeyToken 生成新的密钥明文，格式为 APIKeyTokenPrefix 加 64 位十六进制
+// 明文不写入数据库，签发时显示给管理员一次，之后无法再查看
+func NewAPIKeyToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return APIKeyTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// ValidAPIKeyToken 判断字符串是否符合密钥明文的格式，见 NewAPIKeyToken
+func ValidAPIKeyToken(token string) bool {
+	if len(token) != apiKeyLength || !strings.HasPrefix(token, APIKeyTokenPrefix) {
+		return false
+	}
+	_, err := hex.DecodeString(token[len(APIKeyTokenPrefix):])
+	return err == nil
+}
+
+// HashAPIKey 返回密钥明文的 SHA-256（十六进制），数据库中只保存该值
+// 密钥是 32 字节的随机数，不需要加盐和慢哈希，验证时可以直接按该值查询
+func HashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyDisplayPrefix 返回密钥明文在列表中显示的开头部分
+func APIKeyDisplayPrefix(token string) string {
+	if len(token) < apiKeyDisplayLength {
+		return token
+	}
+	return token[:apiKeyDisplayLength]
+}
+
+// SetAPIKeyExpiry 在签发密钥后设置过期时间和创建、修改时间
+// 表单通过 SaveRow 按字段写入，不会自动填充时间字段
+//
+// 参数:
+//   - ctx: 上下文，在表单的事务中调用时与写入在同一个事务中
+//   - id: 密钥的编号
+//   - ttl: 有效期，为 0 时永不过期
+func SetAPIKeyExpiry(ctx context.Context, id string, ttl time.Duration) error {
+	now := time.Now()
+	var expires *time.Time
+	if ttl > 0 {
+		t := now.Add(ttl)
+		expires = &t
+	}
+	return writer(ctx).Exec(`UPDATE api_keys SET expires_at = ?, updated_at = ?, created_at = COALESCE(created_at, ?) WHERE id = ?`,
+		expires, now, now, id).Error
+}
+
+// TouchAPIKey 在修改密钥的名称、权限范围或表格后更新修改时间
+func TouchAPIKey(ctx context.Context, id string) error {
+	return writer(ctx).Exec(`UPDATE api_keys SET updated_at = ? WHERE id = ?`, time.Now(), id).Error
+}
+
+// AuthenticateAPIKey 验证请求携带的密钥
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - token: 密钥明文
+//
+// 返回值:
+//   - APIKey: 密钥的记录，调用方再按 HasScope、AllowsTable 和创建人的权限判断能否访问
+//   - error: 格式不对、不存在、已吊销或已过期时返回 ErrInvalidAPIKey，查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 从主库读取，吊销后立即生效，不受只读副本复制延迟的影响
+//   - 最近使用时间最多每分钟更新一次，更新失败不影响本次验证
+func AuthenticateAPIKey(ctx context.Context, token string) (APIKey, error) {
+	var k APIKey
+	if !ValidAPIKeyToken(token) {
+		return k, ErrInvalidAPIKey
+	}
+	err := writer(ctx).Where("key_hash = ?", HashAPIKey(token)).Take(&k).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return k, ErrInvalidAPIKey
+		}
+		return k, err
+	}
+	now := time.Now()
+	if !k.Active(now) {
+		return k, ErrInvalidAPIKey
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval {
+		if writer(ctx).Model(&APIKey{}).Where("id = ?", k.ID).UpdateColumn("last_used_at", now).Error == nil {
+			k.LastUsedAt = &now
+		}
+	}
+	return k, nil
+}
+
+// RevokeAPIKey 吊销密钥，吊销后使用该密钥的请求立即被拒绝
+//
+// 返回值:
+//   - bool: 是否吊销了密钥；密钥不存在或已经吊销时返回 false
+//   - error: 写入失败时返回数据库错误
+func RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	res := writer(ctx).Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumns(map[string]interface{}{"revoked_at": now, "updated_at": now})
+	return res.RowsAffected == 1, res.Error
+}
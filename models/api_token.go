@@ -0,0 +1,256 @@
+// models 包 - 数据模型层
+// 本文件实现 JSON 接口的登录令牌：管理员用登录名和密码换取短期有效的 JWT（访问令牌）和长期有效的刷新令牌，
+// 访问令牌过期后用刷新令牌换取新的一对令牌；数据库中只保存刷新令牌的 SHA-256
+
+package models
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidLogin 登录名不存在或密码不正确，不区分两种情况，避免泄露哪些登录名存在
+var ErrInvalidLogin = errors.New("登录名或密码不正确")
+
+// ErrInvalidAPIToken 访问令牌或刷新令牌无效、已过期或已吊销
+var ErrInvalidAPIToken = errors.New("登录令牌无效或已过期，请重新登录")
+
+// apiTokenHeader 访问令牌的 JWT 头部，只使用 HS256
+var apiTokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// apiTokenLeeway 校验签发时间时允许的时钟误差
+const apiTokenLeeway = time.Minute
+
+// APITokenClaims 访问令牌中的声明
+type APITokenClaims struct {
+	// Subject 管理员 ID（goadmin_users.id），按 JWT 的约定为字符串
+	Subject string `json:"sub"`
+
+	// IssuedAt 签发时间，Unix 秒
+	IssuedAt int64 `json:"iat"`
+
+	// ExpiresAt 过期时间，Unix 秒
+	ExpiresAt int64 `json:"exp"`
+}
+
+// SignAPIToken 签发访问令牌（HS256 签名的 JWT）
+//
+// 参数:
+//   - secret: 签名密钥，见 config.yml 的 api.jwt_secret
+//   - userID: 登录的管理员 ID
+//   - now: 签发时间
+//   - ttl: 有效期
+func SignAPIToken(secret []byte, userID int64, now time.Time, ttl time.Duration) (string, error) {
+	payload, err := json.Marshal(APITokenClaims{
+		Subject:   strconv.FormatInt(userID, 10),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signing := apiTokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signing + "." + apiTokenSignature(secret, signing), nil
+}
+
+// ParseAPIToken 校验访问令牌的签名和有效期，返回令牌中的管理员 ID
+//
+// 返回值:
+//   - int64: 管理员 ID，管理员是否仍然存在由调用方判断
+//   - error: 格式不对、签名算法不是 HS256、签名不一致或已过期时返回 ErrInvalidAPIToken
+//
+// 注意事项:
+//   - 访问令牌不保存在数据库中，签发后无法吊销，只能等待过期，有效期应设置得较短
+func ParseAPIToken(secret []byte, token string, now time.Time) (int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != apiTokenHeader {
+		return 0, ErrInvalidAPIToken
+	}
+	expected := apiTokenSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return 0, ErrInvalidAPIToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return 0, ErrInvalidAPIToken
+	}
+	var claims APITokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return 0, ErrInvalidAPIToken
+	}
+	if now.Unix() >= claims.ExpiresAt || time.Unix(claims.IssuedAt, 0).After(now.Add(apiTokenLeeway)) {
+		return 0, ErrInvalidAPIToken
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidAPIToken
+	}
+	return id, nil
+}
+
+// apiTokenSignature 返回 JWT 的 HS256 签名（base64url，无填充）
+func apiTokenSignature(secret []byte, signing string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signing))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// AuthenticateAdmin 按登录名和密码验证管理员，与后台登录页面使用同一个 goadmin_users 表
+//
+// 返回值:
+//   - int64: 管理员 ID
+//   - error: 登录名不存在或密码不正确时返回 ErrInvalidLogin，查询失败时返回数据库错误
+func AuthenticateAdmin(ctx context.Context, username, password string) (int64, error) {
+	if username == "" {
+		return 0, ErrInvalidLogin
+	}
+	var (
+		id   int64
+		hash string
+	)
+	err := writer(ctx).Table("goadmin_users").Select("id", "password").
+		Where("username = ?", username).Row().Scan(&id, &hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidLogin
+		}
+		return 0, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return 0, ErrInvalidLogin
+	}
+	return id, nil
+}
+
+// APIRefreshToken 刷新令牌模型，每个令牌只能使用一次
+type APIRefreshToken struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 登录的管理员 ID，对应 goadmin_users 表的 id
+	UserID int64 `gorm:"column:user_id"`
+
+	// TokenHash 令牌明文的 SHA-256（十六进制）
+	TokenHash string `gorm:"column:token_hash"`
+
+	// ExpiresAt 过期时间
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+
+	// RevokedAt 吊销时间：刷新后、退出登录或修改密码时设置
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 APIRefreshToken 对应的数据库表名
+func (APIRefreshToken) TableName() string {
+	return "api_refresh_tokens"
+}
+
+// IssueRefreshToken 为管理员签发新的刷新令牌，返回令牌明文
+//
+// 参数:
+//   - ctx: 上下文，携带事务时在该事务中写入
+//   - userID: 登录的管理员 ID
+//   - ttl: 有效期
+func IssueRefreshToken(ctx context.Context, userID int64, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	err := writer(ctx).Create(&APIRefreshToken{
+		UserID:    userID,
+		TokenHash: HashAPIKey(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}).Error
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken 使用刷新令牌：吊销该令牌并签发新的刷新令牌
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - token: 刷新令牌明文
+//   - ttl: 新令牌的有效期
+//
+// 返回值:
+//   - int64: 令牌所属的管理员 ID
+//   - string: 新的刷新令牌明文
+//   - error: 令牌不存在、已过期或已吊销时返回 ErrInvalidAPIToken，写入失败时返回数据库错误
+//
+// 注意事项:
+//   - 已经使用过的令牌再次出现说明令牌可能被盗用，此时吊销该管理员的全部刷新令牌，所有客户端都需要重新登录
+func RotateRefreshToken(ctx context.Context, token string, ttl time.Duration) (int64, string, error) {
+	var (
+		old   APIRefreshToken
+		next  string
+		reuse bool
+	)
+	err := Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("token_hash = ?", HashAPIKey(token)).Take(&old).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidAPIToken
+			}
+			return err
+		}
+		now := time.Now()
+		if !now.Before(old.ExpiresAt) {
+			return ErrInvalidAPIToken
+		}
+		// 按 revoked_at 条件更新，同一个令牌同时刷新两次时只有一次成功
+		res := writer(ctx).Model(&APIRefreshToken{}).Where("id = ? AND revoked_at IS NULL", old.ID).
+			UpdateColumn("revoked_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			reuse = true
+			return ErrInvalidAPIToken
+		}
+		var err error
+		next, err = IssueRefreshToken(ctx, old.UserID, ttl)
+		return err
+	})
+	if reuse {
+		if err := RevokeRefreshTokens(ctx, old.UserID); err != nil {
+			return 0, "", err
+		}
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return old.UserID, next, nil
+}
+
+// RevokeRefreshToken 吊销一个刷新令牌，用于退出登录；令牌不存在或已经吊销时不做处理
+func RevokeRefreshToken(ctx context.Context, token string) error {
+	return writer(ctx).Model(&APIRefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", HashAPIKey(token)).
+		UpdateColumn("revoked_at", time.Now()).Error
+}
+
+// RevokeRefreshTokens 吊销管理员的全部刷新令牌，修改密码或发现令牌被盗用时调用
+// 已经签发的访问令牌在过期前仍然有效
+func RevokeRefreshTokens(ctx context.Context, userID int64) error {
+	return writer(ctx).Model(&APIRefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", time.Now()).Error
+}
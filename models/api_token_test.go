@@ -0,0 +1,163 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signTestToken 用指定的头部和声明签发令牌，用于构造篡改过的令牌
+func signTestToken(secret []byte, header, claims string) string {
+	signing := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return signing + "." + apiTokenSignature(secret, signing)
+}
+
+func TestParseAPIToken(t *testing.T) {
+	secret := []byte("test-secret")
+	issued := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	token, err := SignAPIToken(secret, 42, issued, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	const hs256 = `{"alg":"HS256","typ":"JWT"}`
+
+	cases := []struct {
+		name  string
+		token string
+		now   time.Time
+		id    int64
+	}{
+		{"有效", token, issued, 42},
+		{"过期前一秒", token, issued.Add(15*time.Minute - time.Second), 42},
+		{"到达过期时间", token, issued.Add(15 * time.Minute), 0},
+		{"签发时间在允许的时钟误差内", token, issued.Add(-apiTokenLeeway), 42},
+		{"签发时间超出允许的时钟误差", token, issued.Add(-apiTokenLeeway - time.Second), 0},
+		{"其他密钥签名", strings.Replace(token, parts[2], apiTokenSignature([]byte("other"), parts[0]+"."+parts[1]), 1), issued, 0},
+		{"篡改签名", parts[0] + "." + parts[1] + "." + parts[2][:len(parts[2])-2] + "AA", issued, 0},
+		{"篡改声明", parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","iat":1709287200,"exp":1709288100}`)) + "." + parts[2], issued, 0},
+		{"篡改头部", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT","kid":"x"}`)) + "." + parts[1] + "." + parts[2], issued, 0},
+		{"alg 为 none", signTestToken(secret, `{"alg":"none","typ":"JWT"}`, `{"sub":"42","iat":1709287200,"exp":1709288100}`), issued, 0},
+		{"alg 为 none 且没有签名", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".", issued, 0},
+		{"alg 为 HS512", signTestToken(secret, `{"alg":"HS512","typ":"JWT"}`, `{"sub":"42","iat":1709287200,"exp":1709288100}`), issued, 0},
+		{"管理员 ID 不是数字", signTestToken(secret, hs256, `{"sub":"admin","iat":1709287200,"exp":1709288100}`), issued, 0},
+		{"管理员 ID 为 0", signTestToken(secret, hs256, `{"sub":"0","iat":1709287200,"exp":1709288100}`), issued, 0},
+		{"声明不是 JSON", signTestToken(secret, hs256, `sub=42`), issued, 0},
+		{"缺少过期时间", signTestToken(secret, hs256, `{"sub":"42","iat":1709287200}`), issued, 0},
+		{"段数不对", parts[0] + "." + parts[1], issued, 0},
+		{"空令牌", "", issued, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id, err := ParseAPIToken(secret, c.token, c.now)
+			if c.id == 0 {
+				if !errors.Is(err, ErrInvalidAPIToken) {
+					t.Fatalf("ParseAPIToken() = %d, %v, want ErrInvalidAPIToken", id, err)
+				}
+				return
+			}
+			if err != nil || id != c.id {
+				t.Fatalf("ParseAPIToken() = %d, %v, want %d", id, err, c.id)
+			}
+		})
+	}
+}
+
+// useTestDB 把包内的数据库连接换成临时的 SQLite 数据库，测试结束后恢复
+func useTestDB(t *testing.T) {
+	t.Helper()
+	gdb, err := Open("sqlite", filepath.Join(t.TempDir(), "test.db"), DefaultORMConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.AutoMigrate(&APIRefreshToken{}); err != nil {
+		t.Fatal(err)
+	}
+	prev := orm
+	orm = gdb
+	t.Cleanup(func() { orm = prev })
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	useTestDB(t)
+	ctx := context.Background()
+	issue := func(userID int64, ttl time.Duration) string {
+		t.Helper()
+		token, err := IssueRefreshToken(ctx, userID, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	// 使用失效的令牌可能吊销该管理员的全部令牌，每种情况使用不同的管理员
+	expired := issue(1, -time.Minute)
+	revoked := issue(2, time.Hour)
+	if err := RevokeRefreshToken(ctx, revoked); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		id    int64
+	}{
+		{"不存在的令牌", "unknown", 0},
+		{"已过期", expired, 0},
+		{"已退出登录", revoked, 0},
+		{"有效", issue(3, time.Hour), 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id, next, err := RotateRefreshToken(ctx, c.token, time.Hour)
+			if c.id == 0 {
+				if !errors.Is(err, ErrInvalidAPIToken) {
+					t.Fatalf("RotateRefreshToken() error = %v, want ErrInvalidAPIToken", err)
+				}
+				return
+			}
+			if err != nil || id != c.id || next == "" || next == c.token {
+				t.Fatalf("RotateRefreshToken() = %d, %q, %v", id, next, err)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshTokenReuse(t *testing.T) {
+	useTestDB(t)
+	ctx := context.Background()
+	first, err := IssueRefreshToken(ctx, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := IssueRefreshToken(ctx, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherUser, err := IssueRefreshToken(ctx, 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, second, err := RotateRefreshToken(ctx, first, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 再次使用已经刷新过的令牌，视为被盗用
+	if _, _, err := RotateRefreshToken(ctx, first, time.Hour); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("重复使用令牌 error = %v, want ErrInvalidAPIToken", err)
+	}
+
+	for name, token := range map[string]string{"刷新得到的令牌": second, "同一管理员的其他令牌": other} {
+		if _, _, err := RotateRefreshToken(ctx, token, time.Hour); !errors.Is(err, ErrInvalidAPIToken) {
+			t.Errorf("%s error = %v, want ErrInvalidAPIToken", name, err)
+		}
+	}
+	if id, _, err := RotateRefreshToken(ctx, otherUser, time.Hour); err != nil || id != 2 {
+		t.Errorf("其他管理员的令牌 = %d, %v, want 2", id, err)
+	}
+}
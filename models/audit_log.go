@@ -0,0 +1,258 @@
+// models 包 - 数据模型层
+// 本文件定义审计日志模型
+// 管理后台的表格新增、修改、删除记录后各写入一条审计日志，保存修改人以及修改前后的整行数据
+
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// 审计日志的操作类型
+const (
+	// AuditCreate 新增记录
+	AuditCreate = "create"
+
+	// AuditUpdate 修改记录
+	AuditUpdate = "update"
+
+	// AuditDelete 删除记录，软删除的表同样使用该类型
+	AuditDelete = "delete"
+
+	// AuditRestore 恢复已软删除的记录
+	AuditRestore = "restore"
+
+	// AuditMerge 重复的记录合并到另一条记录，被合并的记录随后软删除
+	AuditMerge = "merge"
+
+	// AuditRevoke 吊销 API 密钥
+	AuditRevoke = "revoke"
+)
+
+// AuditLog 审计日志模型
+// 每条记录对应一行数据的一次修改
+type AuditLog struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Table 被修改的数据表，如 users
+	Table string `gorm:"column:table_name"`
+
+	// RecordID 被修改记录的主键
+	RecordID string `gorm:"column:record_id"`
+
+	// Action 操作类型：create、update、delete、restore
+	Action string `gorm:"column:action"`
+
+	// ActorID 执行操作的管理员 ID
+	ActorID int64 `gorm:"column:actor_id"`
+
+	// ActorName 执行操作的管理员名称，管理员被删除后仍可以看出是谁
+	ActorName string `gorm:"column:actor_name"`
+
+	// Before 修改前整行数据的 JSON，新增时为空
+	Before *string `gorm:"column:before_json"`
+
+	// After 修改后整行数据的 JSON，物理删除时为空
+	After *string `gorm:"column:after_json"`
+
+	// CreatedAt 操作时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 AuditLog 对应的数据库表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// auditRedactedColumns 审计快照中不保存原值的字段，键为表名
+var auditRedactedColumns = map[string][]string{
+	"users": {"password"},
+}
+
+// redactAuditValue 用摘要代替敏感字段的原值
+// 摘要只取哈希的前 8 位，无法还原原值，但值改变时摘要随之改变，修改前后的对比中可以看出字段被修改过
+func redactAuditValue(v interface{}) interface{} {
+	s := fmt.Sprint(v)
+	if v == nil || s == "" {
+		return v
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "已设置（" + hex.EncodeToString(sum[:4]) + "）"
+}
+
+// SnapshotRows 读取数据表中主键在 ids 中的整行数据
+//
+// 参数:
+//   - ctx: 上下文
+//   - table: 数据表名，来自表格配置
+//   - pk: 主键字段名
+//   - ids: 主键
+//
+// 返回值:
+//   - map[string]map[string]interface{}: 以主键的字符串形式为键，不存在的记录不包含在内
+//   - error: 查询失败时返回错误
+//
+// 注意事项:
+//   - 直接按表名查询，不经过模型的软删除条件，已软删除的记录同样返回
+//   - auditRedactedColumns 中的字段（如密码哈希）以摘要代替原值
+//   - 读取主库，修改后立即读取时不受副本复制延迟的影响；ctx 携带事务时在事务中读取，可以读到未提交的修改
+func SnapshotRows(ctx context.Context, table, pk string, ids []string) (map[string]map[string]interface{}, error) {
+	snapshots := make(map[string]map[string]interface{}, len(ids))
+	if len(ids) == 0 {
+		return snapshots, nil
+	}
+
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+
+	var rows []map[string]interface{}
+	err := writer(ctx).Table(table).
+		Where(clause.IN{Column: clause.Column{Name: pk}, Values: values}).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		// 文本字段可能以字节切片读出，转换为字符串，否则 JSON 中会是 base64
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+		for _, col := range auditRedactedColumns[table] {
+			if v, ok := row[col]; ok {
+				row[col] = redactAuditValue(v)
+			}
+		}
+		snapshots[fmt.Sprint(row[pk])] = row
+	}
+	return snapshots, nil
+}
+
+// CreateAuditLogs 批量写入审计日志
+//
+// 参数:
+//   - ctx: 上下文，携带事务时在该事务中写入；钩子在请求结束后异步执行时应传入 context.Background()
+//   - logs: 审计日志，CreatedAt 为零值时使用当前时间
+//
+// 返回值:
+//   - error: 数据库写入失败时返回错误
+func CreateAuditLogs(ctx context.Context, logs []AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return writer(ctx).Create(&logs).Error
+}
+
+// AuditFieldChange 审计日志中一个字段修改前后的值
+type AuditFieldChange struct {
+	// Field 字段名
+	Field string
+
+	// Before 修改前的值，修改前没有该字段（如新增记录）时为空
+	Before string
+
+	// After 修改后的值，修改后没有该字段（如物理删除记录）时为空
+	After string
+
+	// Changed 修改前后的值不同，或只有一侧有该字段
+	Changed bool
+}
+
+// Changes 逐个字段对比修改前后的整行数据
+//
+// 返回值:
+//   - []AuditFieldChange: 两侧出现过的全部字段，按字段名排序
+//   - error: Before 或 After 不是合法的 JSON 时返回解析错误
+//
+// 注意事项:
+//   - 字符串显示原文，其他类型显示为 JSON，如数字 3、null
+func (l AuditLog) Changes() ([]AuditFieldChange, error) {
+	before, err := auditRow(l.Before)
+	if err != nil {
+		return nil, fmt.Errorf("解析修改前的数据失败: %w", err)
+	}
+	after, err := auditRow(l.After)
+	if err != nil {
+		return nil, fmt.Errorf("解析修改后的数据失败: %w", err)
+	}
+
+	fields := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		fields = append(fields, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]AuditFieldChange, 0, len(fields))
+	for _, f := range fields {
+		b, inBefore := before[f]
+		a, inAfter := after[f]
+		c := AuditFieldChange{Field: f, Before: auditValue(b, inBefore), After: auditValue(a, inAfter)}
+		c.Changed = inBefore != inAfter || string(b) != string(a)
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// auditRow 解析整行数据的 JSON，s 为空时返回空的结果
+// 各字段保留原始的 JSON 文本，对比时不受数字精度的影响
+func auditRow(s *string) (map[string]json.RawMessage, error) {
+	row := make(map[string]json.RawMessage)
+	if s == nil || *s == "" {
+		return row, nil
+	}
+	if err := json.Unmarshal([]byte(*s), &row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// auditValue 返回字段值的显示文字，字段不存在时返回空字符串
+func auditValue(raw json.RawMessage, ok bool) string {
+	if !ok {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// FindAuditLog 按编号读取一条审计日志
+//
+// 参数:
+//   - ctx: 上下文
+//   - id: 审计日志编号
+//
+// 返回值:
+//   - AuditLog: 审计日志
+//   - error: 不存在时返回 gorm.ErrRecordNotFound
+func FindAuditLog(ctx context.Context, id string) (AuditLog, error) {
+	var l AuditLog
+	err := reader(ctx).Where("id = ?", id).First(&l).Error
+	return l, err
+}
+
+// AuditedTables 返回审计日志中出现过的数据表名，按名称排序，查询失败时返回空列表
+func AuditedTables(ctx context.Context) []string {
+	var names []string
+	reader(ctx).Model(&AuditLog{}).Distinct("table_name").Order("table_name").Pluck("table_name", &names)
+	return names
+}
@@ -0,0 +1,111 @@
+// models 包 - 数据模型层
+// 本文件定义作者模型和重复作者的合并
+// authors 表通过 tables.GetAuthorsTable 在管理后台增删改，作者列表从 author_stats 视图读取
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuthorNotFound 作者不存在或已被删除
+var ErrAuthorNotFound = errors.New("作者不存在")
+
+// ErrMergeSameAuthor 合并时保留的作者和被合并的作者是同一个
+var ErrMergeSameAuthor = errors.New("不能把作者合并到自身")
+
+// Author 作者模型
+// 该结构体映射到 authors 表，DeletedAt 不为空的作者已被合并到其他作者
+type Author struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// FirstName、LastName 名和姓
+	FirstName string `gorm:"column:first_name"`
+	LastName  string `gorm:"column:last_name"`
+
+	// Email 邮箱
+	Email string `gorm:"column:email"`
+
+	// Birthdate 出生日期
+	Birthdate time.Time `gorm:"column:birthdate;type:date"`
+
+	// Added 添加时间
+	Added time.Time `gorm:"column:added"`
+
+	// DeletedAt 删除时间，GORM 的查询自动排除已删除的作者
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_authors_deleted_at"`
+}
+
+// TableName 指定 Author 对应的数据库表名
+func (Author) TableName() string {
+	return "authors"
+}
+
+// Name 返回作者的姓名，与作者列表中的"姓名"列一致
+func (a Author) Name() string {
+	return a.FirstName + " " + a.LastName
+}
+
+// ActiveAuthors 返回未删除的作者，按编号排序，用于选择要合并的作者
+func ActiveAuthors(ctx context.Context) []Author {
+	var authors []Author
+	reader(ctx).Order("id").Find(&authors)
+	return authors
+}
+
+// MergeAuthors 把重复的作者合并到保留的作者
+//
+// 参数:
+//   - ctx: 上下文，调用方应在事务中调用（见 Transaction），文章的修改和作者的删除一起提交或回滚
+//   - survivorID: 保留的作者编号
+//   - duplicateID: 被合并的作者编号
+//
+// 返回值:
+//   - []string: 改为保留作者的文章编号，包括已软删除的文章
+//   - error: 两个编号相同时返回 ErrMergeSameAuthor，任一作者不存在或已删除时返回包装了 ErrAuthorNotFound 的错误，
+//     写入失败时返回数据库错误
+//
+// 功能说明:
+//   - 被合并作者的全部文章改为保留的作者，被合并的作者软删除，不再出现在作者列表中
+func MergeAuthors(ctx context.Context, survivorID, duplicateID string) ([]string, error) {
+	if survivorID == duplicateID {
+		return nil, ErrMergeSameAuthor
+	}
+	db := writer(ctx)
+	for _, id := range []string{survivorID, duplicateID} {
+		var n int64
+		if err := db.Model(&Author{}).Where("id = ?", id).Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAuthorNotFound, id)
+		}
+	}
+
+	var ids []uint
+	if err := db.Unscoped().Model(&Post{}).Where("author_id = ?", duplicateID).
+		Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	postIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		postIDs = append(postIDs, strconv.FormatUint(uint64(id), 10))
+	}
+	if len(ids) > 0 {
+		if err := db.Unscoped().Model(&Post{}).Where("id IN ?", ids).
+			Update("author_id", survivorID).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := db.Where("id = ?", duplicateID).Delete(&Author{}).Error; err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
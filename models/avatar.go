@@ -0,0 +1,133 @@
+// models 包 - 数据模型层
+// 本文件实现用户头像的缩略图生成和存储
+// 上传的图片居中裁剪为正方形并缩放为 JPEG 缩略图，按 config.yml 的 avatar 配置项保存在本地上传目录或 S3 兼容的对象存储中
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// 注册 GIF、PNG 和 WebP 解码器，image.Decode 按文件内容识别格式
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// 头像的存储方式，对应 avatar 配置项的 storage
+const (
+	// AvatarStorageLocal 保存在本地上传目录的 avatars 子目录中，通过 /uploads 路由访问
+	AvatarStorageLocal = StorageLocal
+	// AvatarStorageS3 上传到 S3 或兼容 S3 接口的对象存储（如 MinIO），需要存储桶允许公开读取
+	AvatarStorageS3 = StorageS3
+)
+
+// avatarDir 头像在上传目录和存储桶中的子目录
+const avatarDir = "avatars"
+
+// avatarMaxPixels 允许解码的最大像素数，防止很小的文件解码出超大的图片耗尽内存
+const avatarMaxPixels = 40_000_000
+
+// ErrInvalidImage 上传的文件不是可以识别的图片
+var ErrInvalidImage = errors.New("不是有效的图片，支持 JPEG、PNG、GIF 和 WebP 格式")
+
+// AvatarConfig 头像的缩略图尺寸和存储配置，对应 config.yml 的 avatar 配置项
+type AvatarConfig struct {
+	// Storage 存储方式：local 或 s3，为空时为 local
+	Storage string `yaml:"storage"`
+
+	// Size 缩略图的边长，单位为像素
+	Size int `yaml:"size"`
+
+	// S3 Storage 为 s3 时的对象存储配置
+	S3 S3Config `yaml:"s3"`
+}
+
+// DefaultAvatarConfig 配置文件中没有 avatar 配置项时使用的默认配置
+var DefaultAvatarConfig = AvatarConfig{
+	Storage: AvatarStorageLocal,
+	Size:    200,
+}
+
+// AvatarStorage 头像缩略图的存储
+type AvatarStorage interface {
+	// Put 保存名为 name 的缩略图，返回写入 users.avatar 的地址
+	// 本地存储返回上传目录中的相对路径，S3 返回完整的 URL，两者都可以交给 config.Store.URL 生成访问地址
+	Put(ctx context.Context, name string, data []byte) (string, error)
+}
+
+// NewAvatarStorage 按配置创建头像的存储
+//
+// 参数:
+//   - cfg: 头像配置
+//   - dir: 本地上传目录，即 config.yml 的 store.path
+//
+// 返回值:
+//   - AvatarStorage: 本地或 S3 存储
+//   - error: 存储方式未知，或 S3 缺少存储桶、区域、密钥时返回错误
+func NewAvatarStorage(cfg AvatarConfig, dir string) (AvatarStorage, error) {
+	storage, err := NewStorage(cfg.Storage, cfg.S3, dir, "avatar")
+	if err != nil {
+		return nil, err
+	}
+	return avatarStorage{storage: storage}, nil
+}
+
+// avatarStorage 把缩略图以 JPEG 保存在存储的 avatars 目录中
+type avatarStorage struct {
+	storage Storage
+}
+
+// Put 保存到 avatars 目录
+func (s avatarStorage) Put(ctx context.Context, name string, data []byte) (string, error) {
+	return s.storage.Put(ctx, avatarDir+"/"+name, "image/jpeg", data)
+}
+
+// ResizeAvatar 将上传的图片转换为头像缩略图
+//
+// 参数:
+//   - data: 上传的图片内容，支持 JPEG、PNG、GIF（取第一帧）和 WebP
+//   - size: 缩略图的边长，小于该边长的图片不放大
+//
+// 返回值:
+//   - []byte: JPEG 格式的缩略图
+//   - error: 无法识别的图片或像素数过大时返回 ErrInvalidImage
+//
+// 功能说明:
+//   - 居中裁剪为正方形后按 CatmullRom 插值缩放，透明部分填充为白色
+func ResizeAvatar(data []byte, size int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > avatarMaxPixels {
+		return nil, ErrInvalidImage
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+	if side < size {
+		size = side
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -8,15 +8,26 @@
 package models
 
 import (
-	"github.com/jinzhu/gorm"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models/migrations"
 	"github.com/purpose168/GoAdmin/modules/db"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var (
 	// orm GORM数据库实例
 	// 这是一个全局的ORM实例，用于执行数据库操作
 	// 支持链式调用，提供丰富的查询接口
-	// 示例: orm.Find(&users), orm.Create(&user)
+	// 示例: orm.WithContext(ctx).Find(&users), orm.WithContext(ctx).Create(&user)
 	orm *gorm.DB
 
 	// err 错误变量
@@ -25,40 +36,177 @@ var (
 	err error
 )
 
+// ORMConfig ORM 的日志、预编译语句和连接池配置，对应 config.yml 的 orm 配置项
+// ORM 使用独立的连接池，这里的连接池设置不影响 GoAdmin 自身的数据库连接
+type ORMConfig struct {
+	// LogLevel 日志级别：silent、error、warn、info，info 会输出所有 SQL
+	LogLevel string `yaml:"log_level"`
+
+	// SlowThreshold 慢查询阈值，超过该耗时的 SQL 以 warn 级别输出
+	SlowThreshold time.Duration `yaml:"slow_threshold"`
+
+	// QueryTimeout 单条 SQL 语句的最长执行时间，超时的语句被取消并返回错误，0 表示不限制（见 timeout.go）
+	// 语句同时受请求上下文的控制，客户端断开时正在执行的查询会被取消
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+
+	// PrepareStmt 是否缓存预编译语句，仪表板反复执行相同的统计查询，开启后可以省去重复解析
+	PrepareStmt bool `yaml:"prepare_stmt"`
+
+	// MaxIdleConns 连接池中保留的最大空闲连接数
+	MaxIdleConns int `yaml:"max_idle_con"`
+
+	// MaxOpenConns 最大打开连接数，0 表示不限制
+	MaxOpenConns int `yaml:"max_open_con"`
+
+	// ConnMaxLifetime 连接的最长存活时间，0 表示不限制
+	ConnMaxLifetime time.Duration `yaml:"conn_max_life_time"`
+
+	// ConnMaxIdleTime 连接的最长空闲时间，0 表示不限制
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// Replicas 只读副本的连接名，对应 database 配置项下的数据库连接，驱动必须与 default 相同
+	// 配置后表格列表、导出和仪表板统计从副本读取（见 replica.go），为空时只使用主库
+	Replicas []string `yaml:"replicas"`
+}
+
+// DefaultORMConfig 配置文件中没有 orm 配置项时使用的默认配置
+var DefaultORMConfig = ORMConfig{
+	LogLevel:      "warn",
+	SlowThreshold: 200 * time.Millisecond,
+	PrepareStmt:   true,
+	MaxIdleConns:  2,
+}
+
+// ormLogLevels 配置中的日志级别名称到 GORM 日志级别的映射
+var ormLogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// LoadORMConfig 从 YAML 配置文件的 orm 配置项读取 ORM 配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - ORMConfig: 配置项中未设置的字段使用 DefaultORMConfig 中的值
+//   - error: 读取或解析失败时返回错误
+//
+// 使用示例:
+//
+//	ormCfg, err := models.LoadORMConfig("./config.yml")
+//	if err != nil {
+//	    panic(err)
+//	}
+//	models.Init(eng.DefaultConnection(), ormCfg)
+func LoadORMConfig(path string) (ORMConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ORMConfig{}, err
+	}
+
+	cfg := struct {
+		ORM ORMConfig `yaml:"orm"`
+	}{ORM: DefaultORMConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return ORMConfig{}, err
+	}
+	return cfg.ORM, nil
+}
+
+// Open 按 ORM 配置打开一个数据库连接
+//
+// 参数:
+//   - driver: GoAdmin 的数据库驱动名称：sqlite、mysql、postgresql 或 mssql
+//   - dsn: 数据库连接字符串，通常由 config.Database.GetDSN 生成，SQLite 为数据库文件路径
+//   - cfg: ORM 的日志、预编译语句和连接池配置
+//
+// 返回值:
+//   - *gorm.DB: 新的ORM实例，与全局的 orm 相互独立
+//   - error: 驱动不受支持或打开连接失败时返回错误
+//
+// 注意事项:
+//   - 供 Init 和不启动后台服务的命令（如 migrate）使用
+func Open(driver, dsn string, cfg ORMConfig) (*gorm.DB, error) {
+	level, ok := ormLogLevels[cfg.LogLevel]
+	if !ok {
+		level = logger.Warn
+	}
+
+	dialect, err := dialector(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	gdb, err := gorm.Open(dialect, &gorm.Config{
+		PrepareStmt: cfg.PrepareStmt,
+		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold: cfg.SlowThreshold,
+			LogLevel:      level,
+			// 查询不到记录是正常情况（如管理员还没有保存过布局），不输出日志
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerQueryTimeout(gdb, cfg.QueryTimeout); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return gdb, nil
+}
+
 // Init 初始化数据库ORM实例
 // 该函数建立与数据库的连接并初始化GORM实例
 //
 // 参数:
 //   - c: 数据库连接配置对象，包含数据库连接信息
+//   - cfg: ORM 的日志、预编译语句和连接池配置，通常由 LoadORMConfig 读取
 //
 // 功能说明:
-//  1. 使用SQLite3驱动打开数据库连接
-//  2. 从配置对象中获取名为"default"的数据库配置
-//  3. 如果初始化失败，程序会panic并终止运行
+//  1. 按名为"default"的数据库配置，使用与其驱动对应的 GORM 方言为 ORM 打开独立的连接池（见 Open）
+//  2. 执行所有未执行的版本化迁移（见 migrations 包），缺失的业务表会被创建
+//  3. 打开 cfg.Replicas 中配置的只读副本，迁移和示例数据只写入主库
+//  4. 执行健康检查（见 HealthCheck），有连接无法连通或缺少数据表时终止启动，错误中列出缺少的表
+//  5. goals、categories 表为空时写入示例目标和示例分类
+//  6. 如果初始化失败，程序会panic并终止运行
 //
 // 使用示例:
 //
 //	import "github.com/purpose168/GoAdmin-example/models"
 //
 //	// 在main函数中调用
-//	models.Init(eng.SqliteConnection())
+//	models.Init(eng.DefaultConnection(), models.DefaultORMConfig)
 //
 // 注意事项:
 //   - 必须在使用任何数据库操作之前调用此函数
-//   - 数据库连接信息必须在配置文件中正确配置
+//   - 数据库连接信息必须在配置文件中正确配置，支持 sqlite、mysql、postgresql 和 mssql
 //   - SQLite数据库文件路径由配置决定
 //   - 如果连接失败，程序会立即终止，不会继续执行
 //
 // 错误处理:
 //   - 如果数据库连接失败，会触发panic
-//   - 常见失败原因: 数据库文件路径错误、权限不足、磁盘空间不足
+//   - 常见失败原因: 数据库文件路径错误、权限不足、磁盘空间不足、驱动不受支持
 //   - 建议在生产环境中添加更详细的错误日志
-func Init(c db.Connection) {
-	// 使用GORM打开SQLite3数据库连接
-	// "sqlite3": 指定使用SQLite数据库驱动
-	// c.GetDB("default"): 从配置中获取默认数据库连接信息
+func Init(c db.Connection, cfg ORMConfig) {
+	// 使用GORM打开数据库连接
+	// 与 GoAdmin 使用同一个数据库（驱动和 DSN），但连接池相互独立
 	// 返回值: *gorm.DB (ORM实例), error (错误信息)
-	orm, err = gorm.Open("sqlite3", c.GetDB("default"))
+	conf := c.GetConfig("default")
+	orm, err = Open(conf.Driver, conf.GetDSN(), cfg)
 
 	// 检查数据库初始化是否成功
 	// Go语言的标准错误处理模式
@@ -67,6 +215,78 @@ func Init(c db.Connection) {
 		// panic会立即终止程序执行
 		// 在生产环境中，建议使用日志记录并优雅退出
 		// panic("initialize orm failed") 表示ORM初始化失败
-		panic("initialize orm failed")
+		panic("initialize orm failed: " + err.Error())
+	}
+
+	// 执行版本化迁移，已执行的迁移会被跳过
+	// 也可以在启动前通过 go run . migrate up 单独执行
+	// 迁移和示例数据不受 cfg.QueryTimeout 限制
+	ctx := withoutQueryTimeout(context.Background())
+	ran, err := migrations.Up(ctx, orm)
+	if err != nil {
+		panic("migrate tables failed: " + err.Error())
+	}
+	for _, m := range ran {
+		log.Printf("已执行迁移 %s_%s\n", m.Version, m.Name)
+	}
+
+	// 副本的表结构由复制从主库同步，不在副本上执行迁移
+	replicas, err = openReplicas(c, cfg.Replicas, cfg)
+	if err != nil {
+		panic("initialize replicas failed: " + err.Error())
+	}
+
+	// 迁移记录与实际表结构不一致时（如表被手动删除），迁移会跳过已记录的版本，在这里尽早发现
+	if _, err = HealthCheck(ctx); err != nil {
+		panic("database health check failed: " + err.Error())
+	}
+
+	if err = seedGoals(ctx); err != nil {
+		panic("seed goals failed")
+	}
+	if err = seedCategories(ctx); err != nil {
+		panic("seed categories failed")
+	}
+	if err = seedTags(ctx); err != nil {
+		panic("seed tags failed")
+	}
+	if err = seedCustomers(ctx); err != nil {
+		panic("seed customers failed")
+	}
+	if err = seedSettings(ctx); err != nil {
+		panic("seed settings failed")
+	}
+	if err = seedTasks(ctx); err != nil {
+		panic("seed tasks failed")
+	}
+}
+
+// Close 关闭主库和只读副本的连接池，缓存后端为 Redis 时同时关闭 Redis 连接
+//
+// 返回值:
+//   - error: 各连接关闭失败的错误，合并后返回
+//
+// 注意事项:
+//   - 在 WaitWorkers 之后调用，关闭后正在运行的后台任务的查询都会失败
+func Close() error {
+	var errs []error
+	closeDB := func(gdb *gorm.DB) {
+		sqlDB, err := gdb.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err)
+	}
+	if orm != nil {
+		closeDB(orm)
+	}
+	for _, r := range replicas {
+		closeDB(r.db)
+	}
+	if m, ok := cache.(meteredCache); ok {
+		if c, ok := m.Cache.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
 	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,211 @@
+// models 包 - 数据模型层
+// 本文件实现仪表板聚合数据的缓存层
+// 默认使用进程内存缓存，多实例部署时可以切换为 Redis 后端共享缓存
+// 统计数据写入时会主动失效相关缓存，TTL 只作为兜底
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultCacheTTL 缓存条目的默认有效期
+const DefaultCacheTTL = time.Minute
+
+// statisticsCachePrefix 统计数据相关缓存键的公共前缀
+// 失效时按前缀批量删除，新增统计查询时缓存键应以该前缀开头
+const statisticsCachePrefix = "statistics:"
+
+// Cache 缓存后端接口
+// 缓存的值统一以 JSON 序列化后保存，因此内存和 Redis 后端的行为一致
+type Cache interface {
+	// Get 读取缓存并反序列化到 dst，未命中、已过期或反序列化失败时返回 false
+	Get(key string, dst interface{}) bool
+
+	// Set 写入缓存，ttl 为有效期
+	Set(key string, value interface{}, ttl time.Duration)
+
+	// DeletePrefix 删除所有以 prefix 开头的缓存键
+	DeletePrefix(prefix string)
+}
+
+// cache 当前使用的缓存后端，默认为进程内存缓存
+// 包装为 meteredCache，统计命中次数（见 CacheMetrics）
+var cache Cache = meteredCache{NewMemoryCache()}
+
+// SetCache 设置缓存后端
+//
+// 参数:
+//   - c: 缓存后端，为 nil 时恢复为进程内存缓存
+//
+// 使用示例:
+//
+//	// 在 models.Init 之后调用
+//	models.SetCache(models.NewRedisCache(&redis.Options{Addr: "127.0.0.1:6379"}))
+//
+// 注意事项:
+//   - 应在启动阶段、处理请求之前调用，运行中切换后端不是并发安全的
+func SetCache(c Cache) {
+	if c == nil {
+		c = NewMemoryCache()
+	}
+	cache = meteredCache{c}
+}
+
+// InvalidateStatistics 清除所有统计数据相关的缓存
+// Statistics 的保存和删除钩子会自动调用该函数，
+// 通过原生 SQL 批量写入统计表时需要手动调用
+func InvalidateStatistics() {
+	cache.DeletePrefix(statisticsCachePrefix)
+}
+
+// remember 从缓存读取 key 对应的值，未命中时调用 load 加载并写入缓存
+//
+// 参数:
+//   - ctx: load 中查询使用的上下文
+//   - key: 缓存键
+//   - load: 缓存未命中时加载数据的函数
+//
+// 返回值:
+//   - T: 缓存中的值或 load 的返回值
+//
+// 注意事项:
+//   - ctx 在加载过程中被取消或超时时，查询返回的是空结果，此时不写入缓存，
+//     避免一次超时的请求让后续请求在缓存有效期内都读到空数据
+func remember[T any](ctx context.Context, key string, load func() T) T {
+	var v T
+	if cache.Get(key, &v) {
+		return v
+	}
+	v = load()
+	if ctx.Err() == nil {
+		cache.Set(key, v, DefaultCacheTTL)
+	}
+	return v
+}
+
+// memoryEntry 内存缓存中的一个条目
+type memoryEntry struct {
+	data     []byte
+	expireAt time.Time
+}
+
+// MemoryCache 进程内存缓存
+// 过期条目在读取时惰性删除，适合单实例部署
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryCache 创建一个空的进程内存缓存
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryEntry)}
+}
+
+// Get 实现 Cache 接口
+func (m *MemoryCache) Get(key string, dst interface{}) bool {
+	m.mu.RLock()
+	entry, ok := m.entries[key]
+	m.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+	if time.Now().After(entry.expireAt) {
+		m.mu.Lock()
+		delete(m.entries, key)
+		m.mu.Unlock()
+		return false
+	}
+	return json.Unmarshal(entry.data, dst) == nil
+}
+
+// Set 实现 Cache 接口
+func (m *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.entries[key] = memoryEntry{data: data, expireAt: time.Now().Add(ttl)}
+	m.mu.Unlock()
+}
+
+// DeletePrefix 实现 Cache 接口
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// RedisCache 基于 Redis 的缓存后端
+// 多个服务实例共享同一份缓存，任一实例写入统计数据后其他实例的缓存同样失效
+type RedisCache struct {
+	client *redis.Client
+
+	// namespace 所有缓存键的前缀，避免与同一 Redis 中的其他数据冲突
+	namespace string
+}
+
+// NewRedisCache 创建 Redis 缓存后端
+//
+// 参数:
+//   - opts: Redis 连接参数
+//
+// 返回值:
+//   - *RedisCache: 缓存键统一以 "goadmin-example:" 为前缀
+//
+// 注意事项:
+//   - 该函数不会检查连接是否可用，Redis 不可用时所有读取都视为未命中，
+//     仪表板退化为直接查询数据库
+func NewRedisCache(opts *redis.Options) *RedisCache {
+	return &RedisCache{
+		client:    redis.NewClient(opts),
+		namespace: "goadmin-example:",
+	}
+}
+
+// Get 实现 Cache 接口
+func (r *RedisCache) Get(key string, dst interface{}) bool {
+	data, err := r.client.Get(context.Background(), r.namespace+key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, dst) == nil
+}
+
+// Set 实现 Cache 接口
+func (r *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	r.client.Set(context.Background(), r.namespace+key, data, ttl)
+}
+
+// Close 关闭 Redis 连接，退出时由 models.Close 调用
+func (r *RedisCache) Close() error {
+	return r.client.Close()
+}
+
+// DeletePrefix 实现 Cache 接口
+// 使用 SCAN 逐批查找匹配的键，避免 KEYS 命令阻塞 Redis
+func (r *RedisCache) DeletePrefix(prefix string) {
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, r.namespace+prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		r.client.Del(ctx, iter.Val())
+	}
+}
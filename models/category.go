@@ -0,0 +1,264 @@
+// models 包 - 数据模型层
+// 本文件定义商品分类模型，分类通过 parent_id 组成一棵树
+// categories 表通过 tables.GetCategoriesTable 在管理后台增删改，商品表单按树形结构选择分类
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CategoryPathSeparator 分类完整路径中各级名称之间的分隔符
+const CategoryPathSeparator = " / "
+
+// ErrCategoryCycle 上级分类是分类自身或其下级分类，保存后分类会脱离分类树
+var ErrCategoryCycle = errors.New("上级分类不能是该分类自身或其下级分类")
+
+// ErrCategoryNotFound 分类或上级分类不存在
+var ErrCategoryNotFound = errors.New("分类不存在")
+
+// ErrCategoryHasChildren 分类下还有子分类，删除后子分类会脱离分类树
+var ErrCategoryHasChildren = errors.New("分类下还有子分类")
+
+// ErrCategoryOrderStale 提交的排序与当前的同级分类不一致，通常是其他管理员同时修改了分类
+var ErrCategoryOrderStale = errors.New("同级分类已经变化，请刷新后重新排序")
+
+// Category 商品分类模型
+type Category struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 分类名称
+	Name string `gorm:"column:name"`
+
+	// ParentID 上级分类的编号，0 表示顶级分类
+	ParentID uint `gorm:"column:parent_id"`
+
+	// SortOrder 在同级分类中的顺序，从 1 开始；0 表示未手动排序，排在已排序的同级分类之后
+	SortOrder int `gorm:"column:sort_order"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Category 对应的数据库表名
+func (Category) TableName() string {
+	return "categories"
+}
+
+// CategoryNode 分类树中的一个节点
+type CategoryNode struct {
+	Category
+
+	// Depth 层级，顶级分类为 0
+	Depth int
+
+	// Path 从顶级分类到该分类的名称，用 CategoryPathSeparator 连接，如 "电子产品 / 手机"
+	Path string
+}
+
+// defaultCategories 首次创建 categories 表时写入的示例分类，子分类按上级分类的名称挂载
+var defaultCategories = []struct {
+	name, parent string
+}{
+	{"电子产品", ""},
+	{"手机", "电子产品"},
+	{"电脑", "电子产品"},
+	{"笔记本", "电脑"},
+	{"服装", ""},
+	{"男装", "服装"},
+	{"女装", "服装"},
+}
+
+// CategoryTree 获取全部分类，按树的先序排列
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//
+// 返回值:
+//   - []CategoryNode: 每个分类紧跟在其上级分类之后，同级分类按 SortOrder 排列，未排序的按编号排在最后，查询失败时返回空列表
+//
+// 注意事项:
+//   - 从主库读取，管理员新增分类后在商品表单中立即可以选择
+//   - 上级分类不存在或形成环的分类无法从顶级分类到达，不会出现在结果中
+func CategoryTree(ctx context.Context) []CategoryNode {
+	var categories []Category
+	orm.WithContext(ctx).Order("CASE WHEN sort_order = 0 THEN 1 ELSE 0 END, sort_order, id").Find(&categories)
+
+	children := make(map[uint][]Category, len(categories))
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+
+	nodes := make([]CategoryNode, 0, len(categories))
+	var walk func(parent uint, depth int, path []string)
+	walk = func(parent uint, depth int, path []string) {
+		for _, c := range children[parent] {
+			p := append(path[:len(path):len(path)], c.Name)
+			nodes = append(nodes, CategoryNode{Category: c, Depth: depth, Path: strings.Join(p, CategoryPathSeparator)})
+			walk(c.ID, depth+1, p)
+		}
+	}
+	walk(0, 0, nil)
+	return nodes
+}
+
+// seedCategories 在 categories 表为空时写入示例分类
+func seedCategories(ctx context.Context) error {
+	var count int64
+	if err := orm.WithContext(ctx).Model(&Category{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	ids := make(map[string]uint, len(defaultCategories))
+	for _, d := range defaultCategories {
+		c := Category{Name: d.name, ParentID: ids[d.parent]}
+		if err := orm.WithContext(ctx).Create(&c).Error; err != nil {
+			return err
+		}
+		ids[d.name] = c.ID
+	}
+	return nil
+}
+
+// CheckCategoryParent 检查将分类 id 的上级分类设为 parentID 是否会形成环
+//
+// 参数:
+//   - ctx: 上下文
+//   - id: 分类编号，新增分类时为空
+//   - parentID: 上级分类编号，"0" 或空字符串表示顶级分类
+//
+// 返回值:
+//   - error: parentID 是 id 自身或其下级分类时返回 ErrCategoryCycle，上级分类不存在时返回 ErrCategoryNotFound
+//
+// 注意事项:
+//   - 按 CategoryTree 的结果检查，已经脱离分类树的分类不能作为上级分类
+func CheckCategoryParent(ctx context.Context, id, parentID string) error {
+	if parentID == "" || parentID == "0" {
+		return nil
+	}
+
+	parents := make(map[string]string)
+	for _, n := range CategoryTree(ctx) {
+		parents[strconv.FormatUint(uint64(n.ID), 10)] = strconv.FormatUint(uint64(n.ParentID), 10)
+	}
+	if _, ok := parents[parentID]; !ok {
+		return fmt.Errorf("%w: 上级分类 %s", ErrCategoryNotFound, parentID)
+	}
+
+	// 从上级分类向上走到顶级分类，途中遇到 id 说明 id 是上级分类的祖先（或就是上级分类）
+	for p := parentID; p != "0"; p = parents[p] {
+		if p == id {
+			return ErrCategoryCycle
+		}
+	}
+	return nil
+}
+
+// MoveCategory 将分类移动到新的上级分类下
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 分类编号
+//   - parentID: 新的上级分类编号，"0" 表示移动为顶级分类
+//
+// 返回值:
+//   - error: 会形成环时返回 ErrCategoryCycle，分类或上级分类不存在时返回 ErrCategoryNotFound，写入失败时返回数据库错误
+//
+// 使用示例:
+//
+//	err := models.MoveCategory(ctx, "4", "1")
+func MoveCategory(ctx context.Context, id, parentID string) error {
+	if parentID == "" {
+		parentID = "0"
+	}
+	if err := CheckCategoryParent(ctx, id, parentID); err != nil {
+		return err
+	}
+
+	// 移动后排在新的同级分类之后，原来的顺序在新的上级分类下没有意义
+	res := writer(ctx).Model(&Category{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"parent_id": parentID, "sort_order": 0, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+	}
+	return nil
+}
+
+// CheckCategoriesDeletable 检查分类能否删除
+//
+// 参数:
+//   - ctx: 上下文
+//   - ids: 要删除的分类编号
+//
+// 返回值:
+//   - error: 有分类的子分类不在 ids 中时返回包装了 ErrCategoryHasChildren 的错误，其中列出该分类的名称
+func CheckCategoriesDeletable(ctx context.Context, ids []string) error {
+	var children []Category
+	if err := orm.WithContext(ctx).Where("parent_id IN ? AND id NOT IN ?", ids, ids).Find(&children).Error; err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	var parent Category
+	orm.WithContext(ctx).First(&parent, children[0].ParentID)
+	return fmt.Errorf("%w: 请先删除或移走 %s 的子分类", ErrCategoryHasChildren, parent.Name)
+}
+
+// ReorderCategories 按 ids 的顺序重新排列 parentID 下的同级分类
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - parentID: 上级分类编号，"0" 表示顶级分类
+//   - ids: parentID 下全部子分类的编号，按新的顺序排列
+//
+// 返回值:
+//   - error: ids 与当前的同级分类不一致时返回 ErrCategoryOrderStale，写入失败时返回数据库错误
+//
+// 使用示例:
+//
+//	err := models.ReorderCategories(ctx, "1", []string{"3", "2"})
+//
+// 注意事项:
+//   - 同级分类的 SortOrder 依次写为 1、2、3……，顺序没有变化的分类也会写入
+func ReorderCategories(ctx context.Context, parentID string, ids []string) error {
+	var siblings []uint
+	if err := writer(ctx).Model(&Category{}).Where("parent_id = ?", parentID).Pluck("id", &siblings).Error; err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(siblings))
+	for _, id := range siblings {
+		current[strconv.FormatUint(uint64(id), 10)] = true
+	}
+	if len(ids) != len(current) {
+		return ErrCategoryOrderStale
+	}
+	for _, id := range ids {
+		if !current[id] {
+			return ErrCategoryOrderStale
+		}
+		delete(current, id)
+	}
+
+	now := time.Now()
+	for i, id := range ids {
+		err := writer(ctx).Model(&Category{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"sort_order": i + 1, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
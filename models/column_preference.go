@@ -0,0 +1,79 @@
+// models 包 - 数据模型层
+// 本文件定义列表显示列偏好的模型和读写方法
+// 每个管理员在每个表格上保存一份显示列的选择，保存在 column_preferences 表中
+
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ColumnPreference 列表显示列偏好模型
+// 该结构体映射到 column_preferences 表，每个管理员在每个表格上最多一条记录
+type ColumnPreference struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id
+	// 与 Table 组成联合唯一索引，保证每个管理员在每个表格上只有一份选择
+	UserID int64 `gorm:"column:user_id;uniqueIndex:idx_column_preferences_user_table"`
+
+	// Table 表格前缀，如 users、posts
+	Table string `gorm:"column:table_name;uniqueIndex:idx_column_preferences_user_table"`
+
+	// Columns 显示的列，字段名以逗号分隔，格式与列表页的 __columns 参数相同
+	Columns string `gorm:"column:columns;type:text"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 ColumnPreference 对应的数据库表名
+func (ColumnPreference) TableName() string {
+	return "column_preferences"
+}
+
+// GetColumnPreference 获取指定管理员在某个表格上保存的显示列
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - table: 表格前缀
+//
+// 返回值:
+//   - []string: 显示的列的字段名；从未保存过或选择了全部列时返回 nil，表示显示全部列
+func GetColumnPreference(ctx context.Context, userID int64, table string) []string {
+	var pref ColumnPreference
+	// 选择列后列表立即重新加载，与仪表板布局一样从主库读取
+	if err := orm.WithContext(ctx).Where("user_id = ? AND table_name = ?", userID, table).First(&pref).Error; err != nil {
+		return nil
+	}
+	if pref.Columns == "" {
+		return nil
+	}
+	return strings.Split(pref.Columns, ",")
+}
+
+// SaveColumnPreference 保存指定管理员在某个表格上选择的显示列
+// 如果已有记录则更新，否则新建一条记录；columns 为空表示恢复显示全部列
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - table: 表格前缀
+//   - columns: 显示的列的字段名
+//
+// 返回值:
+//   - error: 数据库写入失败时返回错误
+func SaveColumnPreference(ctx context.Context, userID int64, table string, columns []string) error {
+	var pref ColumnPreference
+	// Assign 使用 map，columns 为空字符串时同样会更新
+	return orm.WithContext(ctx).Where(ColumnPreference{UserID: userID, Table: table}).
+		Assign(map[string]interface{}{"columns": strings.Join(columns, ",")}).
+		FirstOrCreate(&pref).Error
+}
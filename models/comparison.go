@@ -0,0 +1,161 @@
+// models 包 - 数据模型层
+// 本文件实现统计指标的环比计算：按指定粒度划分当前周期和上一周期，
+// 分别汇总后计算变化百分比，供仪表板的描述组件使用
+
+package models
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Granularity 环比计算的周期粒度
+type Granularity string
+
+const (
+	// GranularityRange 以所选日期范围为当前周期，与紧邻其前的等长范围比较
+	GranularityRange Granularity = "range"
+
+	// GranularityDay 以结束日期当天与前一天比较
+	GranularityDay Granularity = "day"
+
+	// GranularityWeek 本周至今（周一开始）与上周同期比较
+	GranularityWeek Granularity = "week"
+
+	// GranularityMonth 本月至今与上月同期比较
+	GranularityMonth Granularity = "month"
+)
+
+// Granularities 所有支持的粒度及其显示名称，顺序即选择器中的顺序
+var Granularities = []struct {
+	Value Granularity
+	Label string
+}{
+	{GranularityRange, "与上一等长周期比较"},
+	{GranularityDay, "按日环比"},
+	{GranularityWeek, "按周环比"},
+	{GranularityMonth, "按月环比"},
+}
+
+// ParseGranularity 解析粒度参数，无法识别时返回 GranularityRange
+func ParseGranularity(s string) Granularity {
+	for _, g := range Granularities {
+		if string(g.Value) == s {
+			return g.Value
+		}
+	}
+	return GranularityRange
+}
+
+// Period 一个统计周期，From 和 To 都包含在内
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// ComparisonPeriods 按粒度计算当前周期和上一周期
+//
+// 参数:
+//   - from: 所选范围的开始时间
+//   - to: 所选范围的结束时间，按日、周、月粒度计算时以该时间所在的日期为准
+//   - g: 周期粒度
+//
+// 返回值:
+//   - current: 当前周期
+//   - previous: 上一周期，长度与当前周期相同
+//
+// 注意事项:
+//   - 按周、月粒度时比较的是"至今"的部分周期，例如 3月1日-3月15日 对比 2月1日-2月15日，
+//     避免用不完整的本月与完整的上月比较
+func ComparisonPeriods(from, to time.Time, g Granularity) (current, previous Period) {
+	dayStart := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+	dayEnd := dayStart.Add(24*time.Hour - time.Second)
+
+	switch g {
+	case GranularityDay:
+		current = Period{From: dayStart, To: dayEnd}
+		previous = Period{From: dayStart.AddDate(0, 0, -1), To: dayEnd.AddDate(0, 0, -1)}
+	case GranularityWeek:
+		// time.Weekday 以周日为 0，换算为以周一为一周的第一天
+		offset := (int(dayStart.Weekday()) + 6) % 7
+		weekStart := dayStart.AddDate(0, 0, -offset)
+		current = Period{From: weekStart, To: dayEnd}
+		previous = Period{From: weekStart.AddDate(0, 0, -7), To: dayEnd.AddDate(0, 0, -7)}
+	case GranularityMonth:
+		monthStart := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+		prevStart := monthStart.AddDate(0, -1, 0)
+		// 上月天数不足时（如 3月31日 对应 2月），截止到上月最后一天
+		prevEnd := prevStart.AddDate(0, 0, dayStart.Day()).Add(-time.Second)
+		if prevEnd.After(monthStart) {
+			prevEnd = monthStart.Add(-time.Second)
+		}
+		current = Period{From: monthStart, To: dayEnd}
+		previous = Period{From: prevStart, To: prevEnd}
+	default:
+		length := to.Sub(from) + time.Second
+		current = Period{From: from, To: to}
+		previous = Period{From: from.Add(-length), To: to.Add(-length)}
+	}
+	return
+}
+
+// KPIComparison 一个指标的环比结果
+type KPIComparison struct {
+	// Name 指标名称，如 总销售额
+	Name string
+
+	// Current 当前周期的值
+	Current float64
+
+	// Previous 上一周期的值
+	Previous float64
+}
+
+// Change 返回相对上一周期的变化百分比，下降时为负数
+// 上一周期为 0 时无法计算比例：当前值大于 0 记为上升 100%，否则为 0
+func (c KPIComparison) Change() float64 {
+	switch {
+	case c.Previous != 0:
+		return (c.Current - c.Previous) / math.Abs(c.Previous) * 100
+	case c.Current > 0:
+		return 100
+	}
+	return 0
+}
+
+// Up 当前值是否不低于上一周期
+func (c KPIComparison) Up() bool {
+	return c.Change() >= 0
+}
+
+// CompareStatistics 按粒度对统计数据的各项指标做环比
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 所选范围的开始时间
+//   - to: 所选范围的结束时间
+//   - g: 周期粒度
+//
+// 返回值:
+//   - current: 当前周期
+//   - kpis: 依次为总销售额、总点赞、新会员、平均CPU 四个指标的环比结果
+//
+// 使用示例:
+//
+//	_, kpis := models.CompareStatistics(ctx, from, to, models.GranularityWeek)
+//	for _, k := range kpis {
+//	    fmt.Printf("%s: %.0f (%+.1f%%)\n", k.Name, k.Current, k.Change())
+//	}
+func CompareStatistics(ctx context.Context, from, to time.Time, g Granularity) (current Period, kpis []KPIComparison) {
+	current, previous := ComparisonPeriods(from, to, g)
+	cur := SummarizeStatistics(ctx, current.From, current.To)
+	prev := SummarizeStatistics(ctx, previous.From, previous.To)
+
+	return current, []KPIComparison{
+		{Name: "总销售额", Current: cur.Sales, Previous: prev.Sales},
+		{Name: "总点赞", Current: cur.Likes, Previous: prev.Likes},
+		{Name: "新会员", Current: cur.NewMembers, Previous: prev.NewMembers},
+		{Name: "平均CPU", Current: cur.CPU, Previous: prev.CPU},
+	}
+}
@@ -0,0 +1,173 @@
+// models 包 - 数据模型层
+// 本文件实现标准五段式 cron 表达式的解析和下一次执行时间的计算，供定时导出使用（见 export_schedule.go）
+// 只支持分钟精度，不支持秒、年以及 L、W、# 等扩展写法
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron cron 表达式无效
+var ErrInvalidCron = errors.New("cron 表达式无效")
+
+// cronMacros 可以代替五段式表达式的简写
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// cronField 一段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+}
+
+// cronFields 五段依次为分钟、小时、日、月、星期；星期的 7 与 0 相同，都表示星期日
+var cronFields = [5]cronField{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"星期", 0, 7},
+}
+
+// CronSchedule 解析后的 cron 表达式，每一段保存允许的取值
+type CronSchedule struct {
+	minute, hour, dom, month, dow [64]bool
+
+	// domAny、dowAny 日和星期是否以 * 开头（*、*/2 等）
+	// 两者都不是 * 时按 cron 的惯例满足其一即可，如 "0 9 1 * 1" 表示每月 1 日和每个星期一
+	domAny, dowAny bool
+}
+
+// ParseCron 解析 cron 表达式
+//
+// 参数:
+//   - expr: 五段式表达式"分 时 日 月 星期"，每段可以是 *、数字、范围（1-5）、步长（*/15、1-10/2）以及用逗号分隔的组合；
+//     也可以使用 @hourly、@daily、@weekly、@monthly、@yearly
+//
+// 返回值:
+//   - CronSchedule: 解析结果
+//   - error: 段数不是 5、取值超出范围或格式不正确时返回包装了 ErrInvalidCron 的错误
+//
+// 使用示例:
+//
+//	s, err := models.ParseCron("30 8 * * 1-5") // 工作日 8:30
+func ParseCron(expr string) (CronSchedule, error) {
+	var s CronSchedule
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return s, fmt.Errorf("%w: 需要 5 段（分 时 日 月 星期），实际为 %d 段", ErrInvalidCron, len(parts))
+	}
+
+	sets := [5]*[64]bool{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, part := range parts {
+		if err := parseCronField(part, cronFields[i], sets[i]); err != nil {
+			return s, err
+		}
+	}
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+	s.domAny = strings.HasPrefix(parts[2], "*")
+	s.dowAny = strings.HasPrefix(parts[4], "*")
+	return s, nil
+}
+
+// parseCronField 解析一段，把允许的取值写入 set
+func parseCronField(part string, f cronField, set *[64]bool) error {
+	for _, item := range strings.Split(part, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("%w: %s的步长不正确: %s", ErrInvalidCron, f.name, item)
+			}
+			rng, step = item[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || lo > hi {
+				return fmt.Errorf("%w: %s的范围不正确: %s", ErrInvalidCron, f.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return fmt.Errorf("%w: %s不是数字: %s", ErrInvalidCron, f.name, item)
+			}
+			lo, hi = n, n
+			// 单个数字带步长时表示从该值开始到最大值，如 5/15
+			if step > 1 {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max {
+			return fmt.Errorf("%w: %s应在 %d 到 %d 之间: %s", ErrInvalidCron, f.name, f.min, f.max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+// Next 返回 t 之后（不含 t 所在的分钟）第一个满足表达式的时间，使用 t 的时区
+// 五年内没有满足的时间（如 2 月 30 日）时返回零值
+func (s CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日和星期是否满足表达式
+func (s CronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	cases := []struct {
+		name  string
+		expr  string
+		valid bool
+	}{
+		{"每分钟", "* * * * *", true},
+		{"数字、范围、步长和逗号组合", "0,30 8-18/2 1-15 */3 1-5", true},
+		{"单个数字带步长", "5/15 * * * *", true},
+		{"星期 7 表示星期日", "0 0 * * 7", true},
+		{"简写", "@daily", true},
+		{"简写不区分大小写", " @Hourly ", true},
+		{"段数不足", "* * * *", false},
+		{"段数过多", "0 * * * * *", false},
+		{"空表达式", "", false},
+		{"分钟超出范围", "60 * * * *", false},
+		{"日为 0", "0 0 0 * *", false},
+		{"范围颠倒", "0 18-8 * * *", false},
+		{"步长为 0", "*/0 * * * *", false},
+		{"不是数字", "a * * * *", false},
+		{"不支持的扩展写法", "0 0 L * *", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseCron(c.expr)
+			if c.valid && err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", c.expr, err)
+			}
+			if !c.valid && !errors.Is(err, ErrInvalidCron) {
+				t.Fatalf("ParseCron(%q) error = %v, want ErrInvalidCron", c.expr, err)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	const layout = "2006-01-02 15:04"
+	cases := []struct {
+		name     string
+		expr     string
+		from     string
+		expected string
+	}{
+		{"下一分钟", "* * * * *", "2024-03-01 10:15", "2024-03-01 10:16"},
+		{"不含当前分钟", "15 10 * * *", "2024-03-01 10:15", "2024-03-02 10:15"},
+		{"步长", "*/15 * * * *", "2024-03-01 10:16", "2024-03-01 10:30"},
+		{"跨小时", "0 * * * *", "2024-03-01 10:59", "2024-03-01 11:00"},
+		{"跨年", "@yearly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"工作日跳过周末", "30 8 * * 1-5", "2024-03-01 09:00", "2024-03-04 08:30"},
+		{"星期 7 表示星期日", "0 0 * * 7", "2024-03-01 00:00", "2024-03-03 00:00"},
+		{"日和星期满足其一即可", "0 9 15 * 1", "2024-03-12 10:00", "2024-03-15 09:00"},
+		{"月末跳过没有 31 日的月份", "0 0 31 * *", "2024-04-01 00:00", "2024-05-31 00:00"},
+		{"闰年 2 月 29 日", "0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"不存在的日期返回零值", "0 0 30 2 *", "2024-01-01 00:00", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := ParseCron(c.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", c.expr, err)
+			}
+			from, _ := time.ParseInLocation(layout, c.from, time.Local)
+			next := s.Next(from)
+			if c.expected == "" {
+				if !next.IsZero() {
+					t.Errorf("Next(%s) = %s, want zero time", c.from, next.Format(layout))
+				}
+				return
+			}
+			if got := next.Format(layout); got != c.expected {
+				t.Errorf("Next(%s) = %s, want %s", c.from, got, c.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// models 包 - 数据模型层
+// 本文件提供导出 CSV 时的单元格处理，防止单元格内容在 Excel 等表格软件中被当作公式执行
+
+package models
+
+import "strings"
+
+// csvFormulaPrefixes 表格软件会当作公式解析的开头字符
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// EscapeCSVFormula 单元格以 =、+、-、@、制表符或回车开头时在前面加上单引号，使表格软件按文本显示
+//
+// 参数:
+//   - s: 单元格内容
+//
+// 返回值:
+//   - string: 处理后的内容，其他情况原样返回
+//
+// 使用示例:
+//
+//	models.EscapeCSVFormula("=HYPERLINK(\"http://x\")") // "'=HYPERLINK(\"http://x\")"
+//
+// 注意事项:
+//   - 负数（如 -12）也会加上单引号，在表格软件中显示为文本
+func EscapeCSVFormula(s string) string {
+	if s != "" && strings.IndexByte(csvFormulaPrefixes, s[0]) >= 0 {
+		return "'" + s
+	}
+	return s
+}
+
+// escapeCSVRow 对一行中的每个单元格执行 EscapeCSVFormula，返回新的切片，不修改传入的 row
+func escapeCSVRow(row []string) []string {
+	cells := make([]string, len(row), len(row)+1)
+	for i, v := range row {
+		cells[i] = EscapeCSVFormula(v)
+	}
+	return cells
+}
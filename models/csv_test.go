@@ -0,0 +1,54 @@
+package models
+
+import "testing"
+
+func TestEscapeCSVFormula(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"", ""},
+		{"张三", "张三"},
+		{"a=b", "a=b"},
+		{"=1+1", "'=1+1"},
+		{"+86 138", "'+86 138"},
+		{"-12", "'-12"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+	}
+	for _, c := range cases {
+		if got := EscapeCSVFormula(c.in); got != c.expected {
+			t.Errorf("EscapeCSVFormula(%q) = %q, want %q", c.in, got, c.expected)
+		}
+	}
+}
+
+func TestImportSheetErrorReport(t *testing.T) {
+	sheet := ImportSheet{
+		Header: []string{"=名称", "数量"},
+		Records: []ImportRecord{
+			{Line: 2, Cells: []string{"正常", "1"}},
+			{Line: 3, Cells: []string{"=HYPERLINK(\"http://x\")", "-1"}},
+		},
+	}
+	got := string(sheet.ErrorReport([]string{"", "数量不能为负数"}))
+	expected := "\uFEFF'=名称,数量,错误\n\"'=HYPERLINK(\"\"http://x\"\")\",'-1,数量不能为负数\n"
+	if got != expected {
+		t.Errorf("ErrorReport() = %q, want %q", got, expected)
+	}
+}
+
+func TestUserImportErrorReport(t *testing.T) {
+	im := UserImport{
+		Header: []string{"姓名", "@城市"},
+		Rows: []UserImportRow{
+			{Line: 2, Record: []string{"张三", "北京"}},
+			{Line: 3, Record: []string{"+李四", "=1+1"}, Errors: []string{"姓名不合法"}},
+		},
+	}
+	got := string(im.ErrorReport())
+	expected := "\uFEFF姓名,'@城市,错误\n'+李四,'=1+1,姓名不合法\n"
+	if got != expected {
+		t.Errorf("ErrorReport() = %q, want %q", got, expected)
+	}
+}
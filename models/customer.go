@@ -0,0 +1,230 @@
+// models 包 - 数据模型层
+// 本文件定义客户模型和客户备注
+// customers 表通过 tables.GetCustomersTable 在管理后台维护，订单通过 orders.customer_id 关联到客户
+
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrEmptyNote 备注内容为空
+var ErrEmptyNote = errors.New("备注内容不能为空")
+
+// Customer 客户模型
+type Customer struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 客户姓名
+	Name string `gorm:"column:name"`
+
+	// Email 邮箱
+	Email string `gorm:"column:email"`
+
+	// Phone 电话
+	Phone string `gorm:"column:phone"`
+
+	// Company 公司
+	Company string `gorm:"column:company"`
+
+	// City 城市
+	City string `gorm:"column:city"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Customer 对应的数据库表名
+func (Customer) TableName() string {
+	return "customers"
+}
+
+// CustomerNote 客户备注，如沟通记录，只能添加不能修改
+type CustomerNote struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// CustomerID 所属客户的编号
+	CustomerID uint `gorm:"column:customer_id"`
+
+	// Content 备注内容，纯文本
+	Content string `gorm:"column:content"`
+
+	// AuthorName 添加备注的管理员名称
+	AuthorName string `gorm:"column:author_name"`
+
+	// CreatedAt 添加时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 CustomerNote 对应的数据库表名
+func (CustomerNote) TableName() string {
+	return "customer_notes"
+}
+
+// CustomerOrder 客户的一个订单及其明细的件数
+type CustomerOrder struct {
+	Order
+
+	// Quantity 订单明细的数量之和
+	Quantity int
+}
+
+// defaultCustomers 首次创建 customers 表时写入的示例客户
+var defaultCustomers = []Customer{
+	{Name: "张伟", Email: "zhangwei@example.com", Phone: "13800000001", Company: "星辰科技", City: "北京"},
+	{Name: "李娜", Email: "lina@example.com", Phone: "13800000002", Company: "云帆贸易", City: "上海"},
+	{Name: "王芳", Email: "wangfang@example.com", Phone: "13800000003", Company: "", City: "广州"},
+}
+
+// FindCustomer 按编号读取客户
+//
+// 返回值:
+//   - Customer: 客户
+//   - error: 不存在时返回 gorm.ErrRecordNotFound
+func FindCustomer(ctx context.Context, id string) (Customer, error) {
+	var c Customer
+	err := orm.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+// AllCustomers 获取全部客户，按编号升序排列，查询失败时返回空列表
+func AllCustomers(ctx context.Context) []Customer {
+	var customers []Customer
+	orm.WithContext(ctx).Order("id").Find(&customers)
+	return customers
+}
+
+// customerSearchColumns 按关键词查找客户时匹配的字段
+var customerSearchColumns = []string{"name", "email", "phone", "company"}
+
+// SearchCustomers 按关键词分页查找客户，用于订单表单中按输入远程搜索的客户下拉框
+//
+// 参数:
+//   - ctx: 上下文，从只读副本读取
+//   - q: 关键词，以空白分隔的多个词需要同时匹配，每个词包含在姓名、邮箱、电话或公司中任一字段中即可
+//   - page: 页码，从 1 开始
+//   - size: 每页的条数
+//
+// 返回值:
+//   - []Customer: 按编号升序排列的一页客户；q 为空时返回 nil
+//   - bool: 是否还有下一页
+//   - error: 查询失败时返回数据库错误
+func SearchCustomers(ctx context.Context, q string, page, size int) ([]Customer, bool, error) {
+	terms := strings.Fields(q)
+	if len(terms) == 0 {
+		return nil, false, nil
+	}
+	if len(terms) > userSearchMaxTerms {
+		terms = terms[:userSearchMaxTerms]
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	tx := reader(ctx).Model(&Customer{})
+	for _, t := range terms {
+		ors := make([]string, len(customerSearchColumns))
+		args := make([]interface{}, len(customerSearchColumns))
+		for i, c := range customerSearchColumns {
+			ors[i] = c + " LIKE ? ESCAPE '!'"
+			args[i] = "%" + escapeLike(t) + "%"
+		}
+		tx = tx.Where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+
+	var customers []Customer
+	if err := tx.Order("id").Offset((page - 1) * size).Limit(size + 1).Find(&customers).Error; err != nil {
+		return nil, false, err
+	}
+	more := len(customers) > size
+	if more {
+		customers = customers[:size]
+	}
+	return customers, more, nil
+}
+
+// CustomerOrders 获取客户的订单
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - customerID: 客户编号
+//
+// 返回值:
+//   - []CustomerOrder: 按下单时间倒序排列，关联 order_items 汇总每个订单的件数，查询失败时返回空列表
+func CustomerOrders(ctx context.Context, customerID string) []CustomerOrder {
+	var orders []CustomerOrder
+	reader(ctx).Model(&Order{}).
+		Select("orders.*, COALESCE(SUM(order_items.quantity), 0) AS quantity").
+		Joins("LEFT JOIN order_items ON order_items.order_id = orders.id").
+		Where("orders.customer_id = ?", customerID).
+		Group("orders.id").
+		Order("orders.created_at DESC").
+		Find(&orders)
+	return orders
+}
+
+// CustomerNotes 获取客户的备注，最新的在前，查询失败时返回空列表
+// 从主库读取，添加备注后立即可以看到
+func CustomerNotes(ctx context.Context, customerID string) []CustomerNote {
+	var notes []CustomerNote
+	orm.WithContext(ctx).Where("customer_id = ?", customerID).Order("id DESC").Find(&notes)
+	return notes
+}
+
+// AddCustomerNote 为客户添加一条备注
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - customerID: 客户编号
+//   - content: 备注内容，去掉首尾空白后不能为空
+//   - authorName: 添加备注的管理员名称
+//
+// 返回值:
+//   - error: 内容为空时返回 ErrEmptyNote，客户不存在时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+func AddCustomerNote(ctx context.Context, customerID, content, authorName string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ErrEmptyNote
+	}
+	c, err := FindCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	return writer(ctx).Create(&CustomerNote{CustomerID: c.ID, Content: content, AuthorName: authorName}).Error
+}
+
+// seedCustomers 在 customers 表为空时写入示例客户，已有订单依次分配给示例客户
+func seedCustomers(ctx context.Context) error {
+	var count int64
+	if err := orm.WithContext(ctx).Model(&Customer{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	ids := make([]uint, 0, len(defaultCustomers))
+	for i := range defaultCustomers {
+		c := defaultCustomers[i]
+		if err := orm.WithContext(ctx).Create(&c).Error; err != nil {
+			return err
+		}
+		ids = append(ids, c.ID)
+	}
+
+	var orderIDs []uint
+	if err := orm.WithContext(ctx).Model(&Order{}).Where("customer_id = 0").Order("id").Pluck("id", &orderIDs).Error; err != nil {
+		return err
+	}
+	for i, id := range orderIDs {
+		if err := orm.WithContext(ctx).Model(&Order{}).Where("id = ?", id).
+			Update("customer_id", ids[i%len(ids)]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// models 包 - 数据模型层
+// 本文件定义按天汇总的计数模型（daily_counts 表）及其汇总任务
+// 日历热力图需要一整年的每日数据，直接对明细表分组统计代价较高，
+// 因此由后台任务定期把明细表的计数汇总到 daily_counts，热力图只读汇总表
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// 支持汇总的指标名称
+const (
+	// MetricAdminActions 管理员操作次数，来源为 goadmin_operation_log 表
+	MetricAdminActions = "admin_actions"
+
+	// MetricOrders 订单数，来源为 orders 表
+	MetricOrders = "orders"
+)
+
+// dailyCountSources 指标名称到明细表的映射，明细表必须包含 created_at 字段
+var dailyCountSources = map[string]string{
+	MetricAdminActions: "goadmin_operation_log",
+	MetricOrders:       "orders",
+}
+
+// rollupBatchSize 汇总结果每批写入的行数
+// SQL Server 单条语句最多 2100 个参数，按每行不超过 10 个字段留出余量
+const rollupBatchSize = 200
+
+// dayLayout daily_counts 表 day 字段的格式
+// 汇总起始时间也只格式化到日期，保证从当天 00:00 开始汇总
+const dayLayout = "2006-01-02"
+
+// DailyCount 某个指标在某一天的计数
+type DailyCount struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Metric 指标名称，如 admin_actions、orders
+	// 与 Day 组成联合唯一索引，汇总时按该索引覆盖旧值
+	Metric string `gorm:"column:metric;uniqueIndex:idx_daily_counts_metric_day"`
+
+	// Day 日期，格式为 2006-01-02
+	Day string `gorm:"column:day;uniqueIndex:idx_daily_counts_metric_day"`
+
+	// Count 当天的计数
+	Count int `gorm:"column:count"`
+}
+
+// TableName 指定 DailyCount 对应的数据库表名
+func (DailyCount) TableName() string {
+	return "daily_counts"
+}
+
+// RollupDailyCounts 将指标明细表中 since 之后的记录按天汇总到 daily_counts 表
+//
+// 参数:
+//   - ctx: 上下文
+//   - metric: 指标名称，必须是 dailyCountSources 中定义的指标
+//   - since: 从该时间所在的日期开始重新汇总
+//
+// 返回值:
+//   - error: 指标未定义或数据库写入失败时返回错误
+//
+// 注意事项:
+//   - 日期取 created_at 的前 10 位，即写入时的本地日期，不做时区换算
+//   - 已存在的日期会被新的计数覆盖，可以重复执行
+func RollupDailyCounts(ctx context.Context, metric string, since time.Time) error {
+	source, ok := dailyCountSources[metric]
+	if !ok {
+		return fmt.Errorf("未定义的汇总指标: %s", metric)
+	}
+
+	// source 只来自 dailyCountSources，可以安全地拼接到 SQL 中
+	// 从主库读取明细，避免副本的复制延迟使汇总结果少计
+	day := datePrefix("created_at", len(dayLayout))
+	var counts []DailyCount
+	err := orm.WithContext(ctx).Table(source).
+		Select(day+" AS day, COUNT(*) AS count").
+		Where("created_at >= ?", since.Format(dayLayout)).
+		Group(day).
+		Scan(&counts).Error
+	if err != nil || len(counts) == 0 {
+		return err
+	}
+
+	for i := range counts {
+		counts[i].Metric = metric
+	}
+	// 按 (metric, day) 唯一索引覆盖旧值，GORM 按数据库生成对应的 upsert 语句
+	return orm.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "metric"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count"}),
+	}).CreateInBatches(&counts, rollupBatchSize).Error
+}
+
+// DailyCountsBetween 获取指标在指定日期区间内的每日计数
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - metric: 指标名称
+//   - from: 区间开始时间（包含当天）
+//   - to: 区间结束时间（包含当天）
+//
+// 返回值:
+//   - []DailyCount: 按日期升序排列，只包含有计数的日期
+func DailyCountsBetween(ctx context.Context, metric string, from, to time.Time) []DailyCount {
+	var counts []DailyCount
+	reader(ctx).Where("metric = ? AND day BETWEEN ? AND ?", metric, from.Format(dayLayout), to.Format(dayLayout)).
+		Order("day").
+		Find(&counts)
+	return counts
+}
+
+// StartDailyCountsRollup 启动后台汇总任务
+//
+// 参数:
+//   - ctx: 上下文，取消后停止汇总任务
+//   - interval: 汇总间隔
+//
+// 功能说明:
+//  1. 启动时汇总最近一年的数据，补齐热力图需要的历史
+//  2. 之后每隔 interval 重新汇总昨天和今天，昨天的数据可能在零点前后才写完
+//
+// 使用示例:
+//
+//	models.Init(eng.DefaultConnection(), models.DefaultORMConfig)
+//	models.StartDailyCountsRollup(context.Background(), 10 * time.Minute)
+//
+// 注意事项:
+//   - 必须在 Init 之后调用
+//   - 汇总失败只记录日志，下一次执行时会重试
+func StartDailyCountsRollup(ctx context.Context, interval time.Duration) {
+	rollupAll := func(since time.Time) {
+		for metric := range dailyCountSources {
+			if err := RollupDailyCounts(ctx, metric, since); err != nil {
+				log.Printf("汇总每日计数 %s 失败: %s\n", metric, err)
+			}
+		}
+	}
+
+	rollupAll(time.Now().AddDate(-1, 0, 0))
+
+	RunWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rollupAll(time.Now().AddDate(0, 0, -1))
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// models 包 - 数据模型层
+// 本文件定义仪表板布局的模型和读写方法
+// 每个管理员在每个仪表板上拥有独立的布局记录，保存在 dashboard_layouts 表中
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// LayoutWidget 布局中单个组件的配置
+// 一个布局由若干个 LayoutWidget 按顺序组成，顺序即页面上的显示顺序
+type LayoutWidget struct {
+	// Name 组件名称，对应仪表板中注册的组件标识
+	Name string `json:"name"`
+
+	// Width 组件宽度，取值 1-12，对应 Bootstrap 栅格的列数
+	Width int `json:"width"`
+
+	// Hidden 是否隐藏该组件
+	Hidden bool `json:"hidden"`
+}
+
+// DashboardLayout 仪表板布局模型
+// 该结构体映射到 dashboard_layouts 表，每个管理员在每个仪表板上最多一条记录
+type DashboardLayout struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id
+	// 与 Dashboard 组成联合唯一索引，保证每个管理员在每个仪表板上只有一份布局
+	UserID int64 `gorm:"column:user_id;uniqueIndex:idx_dashboard_layouts_user_dashboard"`
+
+	// Dashboard 仪表板名称，如 overview、sales
+	Dashboard string `gorm:"column:dashboard;uniqueIndex:idx_dashboard_layouts_user_dashboard"`
+
+	// Widgets 组件列表的 JSON 序列化结果
+	// 使用 JSON 存储可以在增加组件属性时无需修改表结构
+	Widgets string `gorm:"column:widgets;type:text"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 DashboardLayout 对应的数据库表名
+func (DashboardLayout) TableName() string {
+	return "dashboard_layouts"
+}
+
+// GetDashboardLayout 获取指定管理员在某个仪表板上保存的布局
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消时查询随之取消
+//   - userID: 管理员ID
+//   - dashboard: 仪表板名称
+//
+// 返回值:
+//   - []LayoutWidget: 保存的组件列表；如果管理员从未保存过布局或数据损坏，返回 nil
+//
+// 使用示例:
+//
+//	user := auth.Auth(ctx)
+//	layout := models.GetDashboardLayout(ctx.Request.Context(), user.Id, "overview")
+//	if layout == nil {
+//	    // 使用默认布局
+//	}
+func GetDashboardLayout(ctx context.Context, userID int64, dashboard string) []LayoutWidget {
+	var layout DashboardLayout
+	// 保存布局后页面立即重新加载，从主库读取以免读到副本上的旧布局
+	if err := orm.WithContext(ctx).Where("user_id = ? AND dashboard = ?", userID, dashboard).First(&layout).Error; err != nil {
+		return nil
+	}
+
+	var widgets []LayoutWidget
+	if err := json.Unmarshal([]byte(layout.Widgets), &widgets); err != nil {
+		return nil
+	}
+	return widgets
+}
+
+// SaveDashboardLayout 保存指定管理员在某个仪表板上的布局
+// 如果已有布局记录则更新，否则新建一条记录
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - dashboard: 仪表板名称
+//   - widgets: 按显示顺序排列的组件列表
+//
+// 返回值:
+//   - error: 序列化或数据库写入失败时返回错误
+func SaveDashboardLayout(ctx context.Context, userID int64, dashboard string, widgets []LayoutWidget) error {
+	data, err := json.Marshal(widgets)
+	if err != nil {
+		return err
+	}
+
+	var layout DashboardLayout
+	return orm.WithContext(ctx).Where(DashboardLayout{UserID: userID, Dashboard: dashboard}).
+		Assign(DashboardLayout{Widgets: string(data)}).
+		FirstOrCreate(&layout).Error
+}
@@ -0,0 +1,92 @@
+// models 包 - 数据模型层
+// 本文件定义表单页面（/admin/form）的提交记录，每次提交成功保存在 demo_form_submissions 表中
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DemoFormSubmission 表单页面的一次提交
+// 该结构体映射到 demo_form_submissions 表
+type DemoFormSubmission struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 提交表单的管理员ID，对应 goadmin_users 表的 id
+	UserID int64 `gorm:"column:user_id"`
+
+	// Name 表单中的姓名，单独保存以便在列表中筛选
+	Name string `gorm:"column:name"`
+
+	// Email 表单中的邮箱
+	Email string `gorm:"column:email"`
+
+	// Data 其余字段值的 JSON 对象，多值字段的值为数组，其余为字符串
+	Data string `gorm:"column:data;type:text"`
+
+	// Certificates 上传的证书地址的 JSON 数组
+	Certificates string `gorm:"column:certificates;type:text"`
+
+	// CreatedAt 提交时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 DemoFormSubmission 对应的数据库表名
+func (DemoFormSubmission) TableName() string {
+	return "demo_form_submissions"
+}
+
+// CreateDemoFormSubmission 保存表单页面的一次提交
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - userID: 提交表单的管理员ID
+//   - data: 字段名到值的映射，值为字符串或字符串数组；其中的 name 和 email 同时保存到对应的列
+//   - certificates: 上传的证书地址
+//
+// 返回值:
+//   - DemoFormSubmission: 保存的提交记录
+//   - error: 写入失败时返回数据库错误
+func CreateDemoFormSubmission(ctx context.Context, userID int64, data map[string]interface{}, certificates []string) (DemoFormSubmission, error) {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return DemoFormSubmission{}, err
+	}
+	if certificates == nil {
+		certificates = []string{}
+	}
+	rawCertificates, err := json.Marshal(certificates)
+	if err != nil {
+		return DemoFormSubmission{}, err
+	}
+	s := DemoFormSubmission{
+		UserID:       userID,
+		Data:         string(rawData),
+		Certificates: string(rawCertificates),
+	}
+	if v, ok := data["name"]; ok {
+		s.Name = fmt.Sprint(v)
+	}
+	if v, ok := data["email"]; ok {
+		s.Email = fmt.Sprint(v)
+	}
+	return s, writer(ctx).Create(&s).Error
+}
+
+// LatestDemoFormSubmission 读取管理员最近一次提交的表单，表单页面据此预先选择上次提交的值
+//
+// 返回值:
+//   - DemoFormSubmission: 最近一次提交
+//   - error: 没有提交过时返回 gorm.ErrRecordNotFound
+//
+// 注意事项:
+//   - 与工作经历一样从主库读取，提交后跳转回表单页面时能看到刚提交的值
+func LatestDemoFormSubmission(ctx context.Context, userID int64) (DemoFormSubmission, error) {
+	var s DemoFormSubmission
+	err := orm.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Take(&s).Error
+	return s, err
+}
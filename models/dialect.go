@@ -0,0 +1,76 @@
+// models 包 - 数据模型层
+// 本文件定义 GoAdmin 数据库驱动到 GORM 方言的映射，以及少量需要按数据库区分的 SQL 表达式
+// 模型查询尽量使用各数据库通用的写法，只有取日期、小时这类字符串处理需要在这里区分
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/purpose168/GoAdmin/modules/db"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// ormDialectors GoAdmin 的数据库驱动名称到 GORM 方言的映射，键与 config.yml 中的 driver 一致
+var ormDialectors = map[string]func(dsn string) (gorm.Dialector, error){
+	db.DriverSqlite: func(dsn string) (gorm.Dialector, error) {
+		return sqlite.Open(dsn), nil
+	},
+	db.DriverMysql: mysqlDialector,
+	db.DriverPostgresql: func(dsn string) (gorm.Dialector, error) {
+		return postgres.Open(dsn), nil
+	},
+	db.DriverMssql: func(dsn string) (gorm.Dialector, error) {
+		return sqlserver.Open(dsn), nil
+	},
+}
+
+// dialector 返回驱动对应的 GORM 方言
+func dialector(driver, dsn string) (gorm.Dialector, error) {
+	open, ok := ormDialectors[driver]
+	if !ok {
+		return nil, fmt.Errorf("ORM 不支持的数据库驱动: %s", driver)
+	}
+	return open(dsn)
+}
+
+// mysqlDialector 返回 MySQL 方言
+// GoAdmin 生成的 DSN 不带 parseTime，时间字段会以字节读出，这里补上 parseTime 并使用本地时区，
+// 与 SQLite 中按本地时间保存的文本保持一致
+func mysqlDialector(dsn string) (gorm.Dialector, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	if cfg.Loc == time.UTC {
+		cfg.Loc = time.Local
+	}
+	return gormmysql.Open(cfg.FormatDSN()), nil
+}
+
+// datePrefix 返回取字段前 n 个字符的 SQL 表达式
+// 时间字段先按 2006-01-02 15:04:05 的格式转换为文本，n 为 10 时得到日期，为 13 时得到日期和小时；
+// 文本字段（如 statistics.hour）直接截取
+//
+// 注意事项:
+//   - column 和 n 直接拼接到 SQL 中，只能传入代码中的常量
+//   - 取的是数据库中保存的本地时间，不做时区换算
+func datePrefix(column string, n int) string {
+	switch orm.Dialector.Name() {
+	case "mysql":
+		return fmt.Sprintf("LEFT(CAST(%s AS CHAR), %d)", column, n)
+	case "postgres":
+		return fmt.Sprintf("LEFT(CAST(%s AS TEXT), %d)", column, n)
+	case "sqlserver":
+		// 样式 120 即 yyyy-mm-dd hh:mi:ss，对文本字段不起作用
+		return fmt.Sprintf("LEFT(CONVERT(VARCHAR(30), %s, 120), %d)", column, n)
+	}
+	return fmt.Sprintf("substr(%s, 1, %d)", column, n)
+}
@@ -0,0 +1,213 @@
+// models 包 - 数据模型层
+// 本文件定义定时导出模型：每个定时导出按 cron 表达式定期导出一个表格，生成的文件通过邮件发给收件人
+// 每次执行写入一条执行记录（export_runs）；调度和文件的生成见 tables.StartExportScheduler
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// 导出的文件格式，与 export_schedules 表 format 字段中保存的值一致，也用作文件的扩展名
+const (
+	// ExportCSV UTF-8 编码、带 BOM 的 CSV
+	ExportCSV = "csv"
+
+	// ExportXLSX Excel 工作簿
+	ExportXLSX = "xlsx"
+
+	// ExportPDF 与列表"PDF"按钮相同的 PDF
+	ExportPDF = "pdf"
+
+	// ExportJSONL JSON Lines，每行一个 JSON 对象
+	ExportJSONL = "jsonl"
+
+	// ExportXML XML
+	ExportXML = "xml"
+)
+
+// 执行记录的状态，与 export_runs 表 status 字段中保存的值一致
+const (
+	// ExportRunRunning 正在导出或发送
+	ExportRunRunning = "running"
+
+	// ExportRunSuccess 已经发送给收件人
+	ExportRunSuccess = "success"
+
+	// ExportRunFailed 导出或发送失败
+	ExportRunFailed = "failed"
+)
+
+// ExportSchedule 定时导出模型
+type ExportSchedule struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 名称，用作邮件主题和附件的文件名
+	Name string `gorm:"column:name"`
+
+	// Prefix 导出的表格前缀，如 orders
+	Prefix string `gorm:"column:prefix"`
+
+	// Query 列表页地址中 ? 之后的部分，导出时按它筛选和排序，为空时导出全部
+	Query string `gorm:"column:query"`
+
+	// Format 文件格式，见 ExportCSV 等常量
+	Format string `gorm:"column:format"`
+
+	// Cron 执行时间的 cron 表达式，见 ParseCron
+	Cron string `gorm:"column:cron"`
+
+	// Recipients 收件人，用逗号、分号或换行分隔，见 ParseMailAddresses
+	Recipients string `gorm:"column:recipients"`
+
+	// Enabled 是否启用，停用后不再按时执行，仍可以手动执行
+	Enabled bool `gorm:"column:enabled"`
+
+	// CreatedBy 创建该定时导出的管理员 ID，导出时以该管理员的权限读取表格
+	CreatedBy int64 `gorm:"column:created_by"`
+
+	// LastRunAt 最近一次按时执行的时间，手动执行不更新
+	LastRunAt *time.Time `gorm:"column:last_run_at"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+
+	// UpdatedAt 最近一次修改配置的时间
+	UpdatedAt time.Time
+}
+
+// TableName 指定 ExportSchedule 对应的数据库表名
+func (ExportSchedule) TableName() string {
+	return "export_schedules"
+}
+
+// NextRun 返回下一次按时执行的时间
+// 从最近一次执行和最近一次修改中较晚的时间开始计算：修改了 cron 表达式后按新的表达式计算，不补执行修改之前错过的时间
+//
+// 返回值:
+//   - time.Time: 下一次执行的时间，表达式永远不会满足时为零值
+//   - error: cron 表达式无效时返回错误
+func (s ExportSchedule) NextRun() (time.Time, error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	base := s.UpdatedAt
+	if s.LastRunAt != nil && s.LastRunAt.After(base) {
+		base = *s.LastRunAt
+	}
+	return cron.Next(base.In(time.Local)), nil
+}
+
+// FindExportSchedule 按编号读取定时导出
+func FindExportSchedule(ctx context.Context, id string) (ExportSchedule, error) {
+	var s ExportSchedule
+	err := orm.WithContext(ctx).Where("id = ?", id).Take(&s).Error
+	return s, err
+}
+
+// DueExportSchedules 返回到 now 为止应该执行的定时导出（已启用、下一次执行时间不晚于 now）
+// 停机期间错过的多次执行只补执行一次；cron 表达式无效的定时导出被跳过
+func DueExportSchedules(ctx context.Context, now time.Time) ([]ExportSchedule, error) {
+	var list []ExportSchedule
+	if err := orm.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	due := list[:0]
+	for _, s := range list {
+		if next, err := s.NextRun(); err == nil && !next.IsZero() && !next.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+// ClaimExportSchedule 把定时导出的最近执行时间设为 now，表示由当前实例执行这一次
+//
+// 返回值:
+//   - bool: 是否认领成功；同一分钟内已经被其他实例认领时返回 false，多实例部署时同一时间只发送一次
+//   - error: 写入失败时返回数据库错误
+//
+// 注意事项:
+//   - 不修改 updated_at，updated_at 只表示配置的修改时间，见 NextRun
+func ClaimExportSchedule(ctx context.Context, id uint, now time.Time) (bool, error) {
+	res := writer(ctx).Model(&ExportSchedule{}).
+		Where("id = ? AND (last_run_at IS NULL OR last_run_at < ?)", id, now.Truncate(time.Minute)).
+		UpdateColumn("last_run_at", now)
+	return res.RowsAffected == 1, res.Error
+}
+
+// TouchExportSchedule 在新增或修改定时导出后更新修改时间，新增时同时填充创建时间
+// 表单通过 SaveRow 按字段写入，不会自动填充这两个时间
+func TouchExportSchedule(ctx context.Context, id string) error {
+	now := time.Now()
+	return writer(ctx).Exec(`UPDATE export_schedules SET updated_at = ?, created_at = COALESCE(created_at, ?) WHERE id = ?`,
+		now, now, id).Error
+}
+
+// ExportRun 定时导出的执行记录模型
+type ExportRun struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// ScheduleID 定时导出的编号
+	ScheduleID uint `gorm:"column:schedule_id"`
+
+	// Status 执行状态，见 ExportRunRunning 等常量
+	Status string `gorm:"column:status"`
+
+	// FileName 生成的文件名
+	FileName string `gorm:"column:file_name"`
+
+	// RowCount 导出的行数
+	RowCount int `gorm:"column:row_count"`
+
+	// Recipients 本次发送的收件人
+	Recipients string `gorm:"column:recipients"`
+
+	// Error 失败的原因
+	Error string `gorm:"column:error"`
+
+	// Manual 是否为手动执行
+	Manual bool `gorm:"column:manual"`
+
+	// StartedAt 开始执行的时间
+	StartedAt time.Time `gorm:"column:started_at"`
+
+	// FinishedAt 执行结束的时间，执行中为空
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+// TableName 指定 ExportRun 对应的数据库表名
+func (ExportRun) TableName() string {
+	return "export_runs"
+}
+
+// CreateExportRun 开始一次执行，写入状态为 ExportRunRunning 的执行记录，r.ID 被填充为新记录的编号
+func CreateExportRun(ctx context.Context, r *ExportRun) error {
+	r.Status = ExportRunRunning
+	r.StartedAt = time.Now()
+	return writer(ctx).Create(r).Error
+}
+
+// FinishExportRun 结束一次执行
+//
+// 参数:
+//   - id: 执行记录的编号
+//   - fileName、rows: 生成的文件名和导出的行数，生成文件之前失败时为空
+//   - cause: 失败的原因，为 nil 时状态为 ExportRunSuccess，否则为 ExportRunFailed
+func FinishExportRun(ctx context.Context, id uint, fileName string, rows int, cause error) error {
+	values := map[string]interface{}{
+		"status":      ExportRunSuccess,
+		"file_name":   fileName,
+		"row_count":   rows,
+		"finished_at": time.Now(),
+	}
+	if cause != nil {
+		values["status"] = ExportRunFailed
+		values["error"] = cause.Error()
+	}
+	return writer(ctx).Model(&ExportRun{}).Where("id = ?", id).Updates(values).Error
+}
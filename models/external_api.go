@@ -0,0 +1,432 @@
+// models 包 - 数据模型层
+// 本文件实现外部数据表格使用的 REST 接口客户端
+// 列表的分页、排序和筛选原样转换为查询参数转发给远程接口，响应中的数据列表和总数按配置的路径取出
+// 新增、修改和删除分别以 POST、PUT、DELETE 发送给远程接口，接口返回的校验错误原样带回表单
+// 列表的响应按查询参数缓存在 cache.go 的缓存后端中，写入成功或手动刷新时清除
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExternalAPIConfig 远程接口的配置，对应 config.yml 的 external 配置项
+type ExternalAPIConfig struct {
+	// URL 列表接口的地址，如 https://api.example.com/articles；为空时外部数据表格使用内置的示例数据
+	// 详情、修改和删除接口为 URL/{id}，新增接口为 URL
+	URL string `yaml:"url"`
+
+	// Timeout 单次请求的超时时间，如 5s
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Headers 每个请求附加的请求头，如 Authorization
+	Headers map[string]string `yaml:"headers"`
+
+	// Params 分页和排序的查询参数名，远程接口使用不同的名称时修改
+	Params ExternalAPIParams `yaml:"params"`
+
+	// ListPath 列表响应中数据数组的路径，用 . 分隔，如 data.items；为空时响应本身就是数组
+	ListPath string `yaml:"list_path"`
+
+	// TotalPath 列表响应中总数的路径，为空或取不到时以数组长度作为总数
+	TotalPath string `yaml:"total_path"`
+
+	// DetailPath 详情响应中记录对象的路径，为空时响应本身就是记录
+	DetailPath string `yaml:"detail_path"`
+
+	// CacheTTL 列表响应的缓存时间，为 0 时不缓存
+	// 详情不缓存，编辑表单总是显示远程接口中最新的数据
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// ExternalAPIParams 转发给远程接口的查询参数名
+type ExternalAPIParams struct {
+	Page     string `yaml:"page"`
+	PageSize string `yaml:"page_size"`
+	Sort     string `yaml:"sort"`
+	Order    string `yaml:"order"`
+}
+
+// DefaultExternalAPIConfig 配置文件中没有 external 配置项或未设置某一项时使用的默认值
+var DefaultExternalAPIConfig = ExternalAPIConfig{
+	Timeout: 5 * time.Second,
+	Params: ExternalAPIParams{
+		Page:     "page",
+		PageSize: "page_size",
+		Sort:     "sort",
+		Order:    "order",
+	},
+	ListPath:  "data",
+	TotalPath: "total",
+	CacheTTL:  30 * time.Second,
+}
+
+// ExternalQuery 一次列表查询
+type ExternalQuery struct {
+	// Page、PageSize 页码（从 1 开始）和每页条数
+	Page     int
+	PageSize int
+
+	// SortField、SortType 排序字段和方向（asc 或 desc），SortField 为空时不转发排序
+	SortField string
+	SortType  string
+
+	// Filters 筛选条件，键为字段名，原样作为查询参数转发
+	Filters map[string]string
+}
+
+// ExternalPage 一页列表数据
+type ExternalPage struct {
+	// Rows 当前页的记录，键为远程接口返回的字段名
+	Rows []map[string]interface{}
+
+	// Total 总记录数，用于分页
+	Total int
+
+	// FetchedAt 从远程接口获取数据的时间，读取缓存时为缓存写入的时间
+	FetchedAt time.Time
+}
+
+// externalCachePrefix 外部数据列表缓存键的公共前缀，后接接口地址和查询参数
+const externalCachePrefix = "external:"
+
+// externalCacheEntry 缓存中保存的一页数据
+// 记录保存为 JSON 原文，读取时按 json.Number 解析，与直接请求接口的结果一致
+type externalCacheEntry struct {
+	Rows      json.RawMessage `json:"rows"`
+	Total     int             `json:"total"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// ExternalAPI 远程接口的客户端
+type ExternalAPI struct {
+	cfg    ExternalAPIConfig
+	client *http.Client
+}
+
+// NewExternalAPI 按配置创建客户端，未设置的参数名、超时和路径使用 DefaultExternalAPIConfig 中的值
+//
+// 返回值:
+//   - error: URL 不是 http 或 https 地址时返回错误
+func NewExternalAPI(cfg ExternalAPIConfig) (*ExternalAPI, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("external.url 应为 http 或 https 地址: %q", cfg.URL)
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+
+	def := DefaultExternalAPIConfig
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.Params.Page == "" {
+		cfg.Params.Page = def.Params.Page
+	}
+	if cfg.Params.PageSize == "" {
+		cfg.Params.PageSize = def.Params.PageSize
+	}
+	if cfg.Params.Sort == "" {
+		cfg.Params.Sort = def.Params.Sort
+	}
+	if cfg.Params.Order == "" {
+		cfg.Params.Order = def.Params.Order
+	}
+	return &ExternalAPI{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// List 查询一页数据，CacheTTL 大于 0 时先读取缓存，相同的查询参数在缓存有效期内不再请求远程接口
+//
+// 返回值:
+//   - ExternalPage: 当前页的记录、总数和获取时间
+//   - error: 请求失败、状态码不是 2xx 或响应格式不符合配置时返回错误，失败的结果不缓存
+//
+// 使用示例:
+//
+//	page, err := api.List(ctx, models.ExternalQuery{Page: 1, PageSize: 10, SortField: "id", SortType: "desc"})
+func (a *ExternalAPI) List(ctx context.Context, q ExternalQuery) (ExternalPage, error) {
+	query := url.Values{}
+	for k, v := range q.Filters {
+		query.Set(k, v)
+	}
+	query.Set(a.cfg.Params.Page, strconv.Itoa(q.Page))
+	query.Set(a.cfg.Params.PageSize, strconv.Itoa(q.PageSize))
+	if q.SortField != "" {
+		query.Set(a.cfg.Params.Sort, q.SortField)
+		query.Set(a.cfg.Params.Order, q.SortType)
+	}
+	u := a.cfg.URL + "?" + query.Encode()
+
+	if a.cfg.CacheTTL > 0 {
+		if page, ok := a.cachedPage(u); ok {
+			return page, nil
+		}
+	}
+
+	page, err := a.fetchPage(ctx, u)
+	if err != nil {
+		return ExternalPage{}, err
+	}
+	if a.cfg.CacheTTL > 0 {
+		if rows, err := json.Marshal(page.Rows); err == nil {
+			cache.Set(externalCachePrefix+u, externalCacheEntry{Rows: rows, Total: page.Total, FetchedAt: page.FetchedAt}, a.cfg.CacheTTL)
+		}
+	}
+	return page, nil
+}
+
+// ClearCache 清除该接口的全部列表缓存，下次查询时重新请求远程接口
+func (a *ExternalAPI) ClearCache() {
+	cache.DeletePrefix(externalCachePrefix + a.cfg.URL + "?")
+}
+
+// cachedPage 读取地址 u 的列表缓存
+func (a *ExternalAPI) cachedPage(u string) (ExternalPage, bool) {
+	var entry externalCacheEntry
+	if !cache.Get(externalCachePrefix+u, &entry) {
+		return ExternalPage{}, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(entry.Rows))
+	dec.UseNumber()
+	var rows []map[string]interface{}
+	if err := dec.Decode(&rows); err != nil {
+		return ExternalPage{}, false
+	}
+	return ExternalPage{Rows: rows, Total: entry.Total, FetchedAt: entry.FetchedAt}, true
+}
+
+// fetchPage 请求远程接口的列表并按配置的路径取出记录和总数
+func (a *ExternalAPI) fetchPage(ctx context.Context, u string) (ExternalPage, error) {
+	body, err := a.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return ExternalPage{}, err
+	}
+
+	list, ok := jsonPath(body, a.cfg.ListPath).([]interface{})
+	if !ok {
+		return ExternalPage{}, fmt.Errorf("外部接口的响应中 %q 不是数组", a.cfg.ListPath)
+	}
+	rows := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return ExternalPage{}, fmt.Errorf("外部接口的响应中 %q 的元素不是对象", a.cfg.ListPath)
+		}
+		rows = append(rows, row)
+	}
+
+	total := len(rows)
+	if n, ok := jsonPath(body, a.cfg.TotalPath).(json.Number); ok && a.cfg.TotalPath != "" {
+		if v, err := n.Int64(); err == nil {
+			total = int(v)
+		}
+	}
+	return ExternalPage{Rows: rows, Total: total, FetchedAt: time.Now()}, nil
+}
+
+// Get 按编号查询一条记录
+//
+// 返回值:
+//   - map[string]interface{}: 记录，远程接口返回 404 时返回 nil 和 ErrExternalNotFound
+func (a *ExternalAPI) Get(ctx context.Context, id string) (map[string]interface{}, error) {
+	body, err := a.do(ctx, http.MethodGet, a.itemURL(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	row, ok := jsonPath(body, a.cfg.DetailPath).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("外部接口的响应中 %q 不是对象", a.cfg.DetailPath)
+	}
+	return row, nil
+}
+
+// Create 新增一条记录，row 以 JSON 对象作为请求体 POST 到 URL
+// Create、Update、Delete 成功后都会清除列表缓存
+//
+// 返回值:
+//   - error: 远程接口返回 400 或 422 时为 *ExternalValidationError，其他失败时为普通错误
+func (a *ExternalAPI) Create(ctx context.Context, row map[string]interface{}) error {
+	_, err := a.do(ctx, http.MethodPost, a.cfg.URL, row)
+	if err == nil {
+		a.ClearCache()
+	}
+	return err
+}
+
+// Update 修改一条记录，row 以 JSON 对象作为请求体 PUT 到 URL/{id}
+//
+// 返回值:
+//   - error: 同 Create，记录不存在时为 ErrExternalNotFound
+func (a *ExternalAPI) Update(ctx context.Context, id string, row map[string]interface{}) error {
+	_, err := a.do(ctx, http.MethodPut, a.itemURL(id), row)
+	if err == nil {
+		a.ClearCache()
+	}
+	return err
+}
+
+// Delete 删除一条记录，向 URL/{id} 发送 DELETE
+// 记录已经不存在时视为删除成功
+func (a *ExternalAPI) Delete(ctx context.Context, id string) error {
+	_, err := a.do(ctx, http.MethodDelete, a.itemURL(id), nil)
+	if errors.Is(err, ErrExternalNotFound) {
+		err = nil
+	}
+	if err == nil {
+		a.ClearCache()
+	}
+	return err
+}
+
+// ErrExternalNotFound 远程接口中没有该记录
+var ErrExternalNotFound = errors.New("外部数据不存在")
+
+// ExternalValidationError 远程接口拒绝了提交的数据（状态码 400 或 422）
+//
+// 响应格式:
+//
+//	{"message": "数据无效", "errors": {"title": ["不能为空"]}}
+//
+// errors 中每个字段的错误可以是字符串或字符串数组；响应不是这个格式时整个响应体作为 Message
+type ExternalValidationError struct {
+	// Message 整体的错误信息
+	Message string
+
+	// Fields 各字段的错误信息，键为远程接口返回的字段名
+	Fields map[string][]string
+}
+
+// Error 按"字段: 错误"的格式拼接错误信息，字段按名称排序
+func (e *ExternalValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields)+1)
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		parts = append(parts, field+": "+strings.Join(e.Fields[field], "，"))
+	}
+	if len(parts) == 0 {
+		return "外部接口拒绝了提交的数据"
+	}
+	return strings.Join(parts, "；")
+}
+
+// parseExternalValidationError 解析远程接口返回的校验错误
+func parseExternalValidationError(body []byte) *ExternalValidationError {
+	var resp struct {
+		Message string                     `json:"message"`
+		Errors  map[string]json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return &ExternalValidationError{Message: strings.TrimSpace(string(body))}
+	}
+
+	e := &ExternalValidationError{Message: resp.Message, Fields: make(map[string][]string, len(resp.Errors))}
+	for field, raw := range resp.Errors {
+		var msgs []string
+		if err := json.Unmarshal(raw, &msgs); err != nil {
+			var msg string
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				msg = string(raw)
+			}
+			msgs = []string{msg}
+		}
+		e.Fields[field] = msgs
+	}
+	return e
+}
+
+// externalMaxBody 响应的最大字节数，超过时按格式错误处理
+const externalMaxBody = 10 << 20
+
+// itemURL 单条记录的地址
+func (a *ExternalAPI) itemURL(id string) string {
+	return a.cfg.URL + "/" + url.PathEscape(id)
+}
+
+// do 发送请求并解析 JSON 响应，payload 不为 nil 时以 JSON 作为请求体
+// 数字解析为 json.Number，较大的编号显示时不会变成科学计数法；响应体为空（如 204）时返回 nil
+func (a *ExternalAPI) do(ctx context.Context, method, u string, payload interface{}) (interface{}, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range a.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求外部接口失败: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, externalMaxBody))
+	if err != nil {
+		return nil, fmt.Errorf("读取外部接口的响应失败: %v", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrExternalNotFound
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity:
+		return nil, parseExternalValidationError(data)
+	case resp.StatusCode/100 != 2:
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		return nil, fmt.Errorf("请求外部接口失败: %s %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var body interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("外部接口的响应不是有效的 JSON: %v", err)
+	}
+	return body, nil
+}
+
+// jsonPath 按 . 分隔的路径取出 JSON 中的值，路径为空时返回 v 本身，取不到时返回 nil
+func jsonPath(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
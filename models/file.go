@@ -0,0 +1,235 @@
+// models 包 - 数据模型层
+// 本文件定义上传文件模型
+// 文件本身保存在上传目录（config.yml 的 store.path）中，files 表记录文件的名称、大小、类型和上传人，
+// 通过 tables.GetFilesTable 在管理后台浏览和删除
+
+package models
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// File 上传文件模型
+type File struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 显示名称，默认为文件名
+	Name string `gorm:"column:name"`
+
+	// Path 文件在上传目录中的相对路径，访问地址由存储配置的前缀加上该路径组成
+	Path string `gorm:"column:path"`
+
+	// Size 文件大小，单位为字节
+	Size int64 `gorm:"column:size"`
+
+	// MimeType 文件类型，如 image/png
+	MimeType string `gorm:"column:mime_type"`
+
+	// UploaderID 上传人的管理员 ID，扫描目录登记的文件为 0
+	UploaderID int64 `gorm:"column:uploader_id"`
+
+	// UploaderName 上传人的管理员名称
+	UploaderName string `gorm:"column:uploader_name"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 File 对应的数据库表名
+func (File) TableName() string {
+	return "files"
+}
+
+// IsImage 文件是否为图片，列表显示缩略图、预览直接显示图片
+func (f File) IsImage() bool {
+	return strings.HasPrefix(f.MimeType, "image/")
+}
+
+// FilePath 返回文件在磁盘上的路径
+// rel 先按根目录清理，含有 ".." 的路径不会指向上传目录之外
+func FilePath(dir, rel string) string {
+	return filepath.Join(dir, filepath.Clean("/"+rel))
+}
+
+// fileInfo 读取磁盘上文件的大小和类型
+// 类型优先按扩展名判断，无法判断时读取文件开头的内容识别
+func fileInfo(dir, rel string) (int64, string, error) {
+	p := FilePath(dir, rel)
+	st, err := os.Stat(p)
+	if err != nil {
+		return 0, "", err
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(p))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+		if f, err := os.Open(p); err == nil {
+			buf := make([]byte, 512)
+			n, _ := f.Read(buf)
+			_ = f.Close()
+			mimeType = http.DetectContentType(buf[:n])
+		}
+	}
+	return st.Size(), mimeType, nil
+}
+
+// FindFile 按编号读取一个文件的记录
+//
+// 返回值:
+//   - File: 文件记录
+//   - error: 不存在时返回 gorm.ErrRecordNotFound
+func FindFile(ctx context.Context, id string) (File, error) {
+	var f File
+	err := orm.WithContext(ctx).Where("id = ?", id).First(&f).Error
+	return f, err
+}
+
+// RecordUpload 补全刚上传的文件记录的大小、类型和上传人
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 文件记录编号
+//   - dir: 上传目录
+//   - uploaderID: 上传人的管理员 ID
+//   - uploaderName: 上传人的管理员名称
+//
+// 返回值:
+//   - error: 记录不存在、文件不在上传目录中或写入失败时返回错误
+//
+// 注意事项:
+//   - 名称为空时使用文件名，创建时间为空时使用当前时间
+func RecordUpload(ctx context.Context, id, dir string, uploaderID int64, uploaderName string) error {
+	var f File
+	if err := writer(ctx).Where("id = ?", id).First(&f).Error; err != nil {
+		return err
+	}
+	size, mimeType, err := fileInfo(dir, f.Path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"size":          size,
+		"mime_type":     mimeType,
+		"uploader_id":   uploaderID,
+		"uploader_name": uploaderName,
+		"updated_at":    now,
+	}
+	if f.Name == "" {
+		updates["name"] = filepath.Base(f.Path)
+	}
+	// 表单按行写入，不经过模型，不会自动填充创建时间
+	if f.CreatedAt.IsZero() {
+		updates["created_at"] = now
+	}
+	return writer(ctx).Model(&File{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// SyncFiles 登记上传目录中还没有记录的文件
+//
+// 参数:
+//   - ctx: 上下文
+//   - dir: 上传目录
+//
+// 返回值:
+//   - int: 新登记的文件数
+//   - error: 读取目录或写入失败时返回错误，此前登记的文件不会回滚
+//
+// 注意事项:
+//   - 包含子目录中的文件，跳过以 "." 开头的文件和目录
+//   - 登记的文件没有上传人
+func SyncFiles(ctx context.Context, dir string) (int, error) {
+	var known []string
+	if err := orm.WithContext(ctx).Model(&File{}).Pluck("path", &known).Error; err != nil {
+		return 0, err
+	}
+	exists := make(map[string]bool, len(known))
+	for _, p := range known {
+		exists[p] = true
+	}
+
+	added := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if exists[rel] {
+			return nil
+		}
+		size, mimeType, err := fileInfo(dir, rel)
+		if err != nil {
+			return err
+		}
+		f := File{Name: d.Name(), Path: rel, Size: size, MimeType: mimeType}
+		if err := orm.WithContext(ctx).Create(&f).Error; err != nil {
+			return err
+		}
+		added++
+		return nil
+	})
+	return added, err
+}
+
+// DeleteFiles 删除文件的记录和磁盘上的文件
+//
+// 参数:
+//   - ctx: 上下文
+//   - dir: 上传目录
+//   - ids: 要删除的文件记录编号
+//
+// 返回值:
+//   - error: 删除记录失败时返回数据库错误，此时磁盘上的文件保持不变
+//
+// 注意事项:
+//   - 记录删除成功后才删除磁盘上的文件；文件已经不存在时忽略，其他删除失败只记录日志，
+//     记录已经删除，留下的文件可以通过扫描目录重新登记后再删除
+func DeleteFiles(ctx context.Context, dir string, ids []string) error {
+	var files []File
+	err := Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("id IN ?", ids).Find(&files).Error; err != nil {
+			return err
+		}
+		return writer(ctx).Where("id IN ?", ids).Delete(&File{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := os.Remove(FilePath(dir, f.Path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("删除文件 %s 失败: %s\n", f.Path, err)
+		}
+	}
+	return nil
+}
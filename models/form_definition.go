@@ -0,0 +1,272 @@
+// models 包 - 数据模型层
+// 本文件定义动态表单：表单的字段保存在 form_definitions 表中，由后台设计，
+// 填写后的内容保存在 form_submissions 表中
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 动态表单的字段类型
+const (
+	FormFieldText     = "text"
+	FormFieldTextArea = "textarea"
+	FormFieldNumber   = "number"
+	FormFieldEmail    = "email"
+	FormFieldDate     = "date"
+	FormFieldSelect   = "select"
+	FormFieldRadio    = "radio"
+	FormFieldCheckbox = "checkbox"
+	FormFieldSwitch   = "switch"
+)
+
+// FormFieldTypes 字段类型和显示名称，按表单设计器中的顺序排列
+var FormFieldTypes = []struct {
+	Type string
+	Name string
+}{
+	{FormFieldText, "文本"},
+	{FormFieldTextArea, "多行文本"},
+	{FormFieldNumber, "数字"},
+	{FormFieldEmail, "邮箱"},
+	{FormFieldDate, "日期"},
+	{FormFieldSelect, "下拉框"},
+	{FormFieldRadio, "单选"},
+	{FormFieldCheckbox, "多选"},
+	{FormFieldSwitch, "开关"},
+}
+
+// formFieldNamePattern 字段名的格式：小写字母开头，由小写字母、数字和下划线组成
+var formFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// formFieldMax 一个表单最多的字段数
+const formFieldMax = 50
+
+// FormFieldOption 选择类字段的一个选项
+type FormFieldOption struct {
+	Value string `json:"value"`
+	Text  string `json:"text"`
+}
+
+// FormFieldDef 动态表单的一个字段
+type FormFieldDef struct {
+	// Field 字段名，提交记录中以它为键
+	Field string `json:"field"`
+
+	// Label 字段在表单中显示的名称
+	Label string `json:"label"`
+
+	// Type 字段类型，见 FormFieldText 等常量
+	Type string `json:"type"`
+
+	// Options 下拉框、单选和多选的选项；开关的第一个选项为打开时的值，为空时为“是”（1）和“否”（0）
+	Options []FormFieldOption `json:"options,omitempty"`
+
+	// Default 默认值
+	Default string `json:"default,omitempty"`
+
+	// Rules 校验规则，格式见 tables.FormRules，如 required|max:50
+	Rules string `json:"rules,omitempty"`
+
+	// Help 字段下方的说明
+	Help string `json:"help,omitempty"`
+}
+
+// defaultSwitchOptions 开关没有设置选项时的选项，第一个为打开时的值
+var defaultSwitchOptions = []FormFieldOption{{Value: "1", Text: "是"}, {Value: "0", Text: "否"}}
+
+// FieldOptions 字段的选项，开关没有设置选项时返回“是”（1）和“否”（0）
+func (f FormFieldDef) FieldOptions() []FormFieldOption {
+	if f.Type == FormFieldSwitch && len(f.Options) == 0 {
+		return defaultSwitchOptions
+	}
+	return f.Options
+}
+
+// Multiple 字段是否可以提交多个值，即多选
+func (f FormFieldDef) Multiple() bool {
+	return f.Type == FormFieldCheckbox
+}
+
+// FormDefinition 动态表单的定义
+// 该结构体映射到 form_definitions 表
+type FormDefinition struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Title 表单的标题
+	Title string `gorm:"column:title"`
+
+	// Description 表单的说明，显示在标题旁
+	Description string `gorm:"column:description"`
+
+	// Fields 字段定义的 JSON 数组，见 ParseFormFields
+	Fields string `gorm:"column:fields;type:text"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+
+	// UpdatedAt 修改时间
+	UpdatedAt time.Time
+}
+
+// TableName 指定 FormDefinition 对应的数据库表名
+func (FormDefinition) TableName() string {
+	return "form_definitions"
+}
+
+// FormSubmission 动态表单的一次提交
+// 该结构体映射到 form_submissions 表
+type FormSubmission struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// FormID 表单定义的编号
+	FormID uint `gorm:"column:form_id"`
+
+	// SubmittedBy 提交表单的管理员ID，对应 goadmin_users 表的 id
+	SubmittedBy int64 `gorm:"column:submitted_by"`
+
+	// Data 提交的字段值的 JSON 对象，多选字段的值为数组，其余为字符串
+	Data string `gorm:"column:data;type:text"`
+
+	// CreatedAt 提交时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 FormSubmission 对应的数据库表名
+func (FormSubmission) TableName() string {
+	return "form_submissions"
+}
+
+// ParseFormFields 解析并检查字段定义的 JSON
+//
+// 参数:
+//   - s: 字段定义的 JSON 数组
+//
+// 返回值:
+//   - []FormFieldDef: 字段定义，字段名和标签去掉了首尾空白
+//   - error: 格式不正确、没有字段、字段名无效或重复、类型未知、选择类字段没有选项时返回错误
+//
+// 注意事项:
+//   - 不检查校验规则的写法，规则由 tables 包解析（见 tables.ParseFieldRules）
+func ParseFormFields(s string) ([]FormFieldDef, error) {
+	var fields []FormFieldDef
+	if err := json.Unmarshal([]byte(s), &fields); err != nil {
+		return nil, fmt.Errorf("字段定义不是有效的 JSON 数组: %v", err)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("表单至少需要一个字段")
+	}
+	if len(fields) > formFieldMax {
+		return nil, fmt.Errorf("表单最多 %d 个字段", formFieldMax)
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		f.Field = strings.TrimSpace(f.Field)
+		f.Label = strings.TrimSpace(f.Label)
+		switch {
+		case !formFieldNamePattern.MatchString(f.Field):
+			return nil, fmt.Errorf("第 %d 个字段的字段名 %q 无效，只能使用小写字母、数字和下划线，以字母开头", i+1, f.Field)
+		case seen[f.Field]:
+			return nil, fmt.Errorf("字段名 %s 重复", f.Field)
+		case f.Label == "" || utf8.RuneCountInString(f.Label) > 50:
+			return nil, fmt.Errorf("字段 %s 的标签不能为空，不能超过 50 个字符", f.Field)
+		case !validFormFieldType(f.Type):
+			return nil, fmt.Errorf("字段 %s 的类型 %q 无效", f.Field, f.Type)
+		}
+		seen[f.Field] = true
+
+		switch f.Type {
+		case FormFieldSelect, FormFieldRadio, FormFieldCheckbox:
+			if len(f.Options) == 0 {
+				return nil, fmt.Errorf("字段 %s 需要至少一个选项", f.Field)
+			}
+		case FormFieldSwitch:
+			if len(f.Options) != 0 && len(f.Options) != 2 {
+				return nil, fmt.Errorf("开关 %s 需要两个选项（打开、关闭）或不设置选项", f.Field)
+			}
+		default:
+			f.Options = nil
+		}
+	}
+	return fields, nil
+}
+
+// validFormFieldType 判断是否为 FormFieldTypes 中的字段类型
+func validFormFieldType(t string) bool {
+	for _, ft := range FormFieldTypes {
+		if ft.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// FindFormDefinition 按编号读取表单定义
+//
+// 返回值:
+//   - FormDefinition: 表单定义
+//   - error: 表单不存在时返回 gorm.ErrRecordNotFound
+func FindFormDefinition(ctx context.Context, id uint) (FormDefinition, error) {
+	var d FormDefinition
+	err := reader(ctx).Where("id = ?", id).Take(&d).Error
+	return d, err
+}
+
+// TouchFormDefinition 在新增或修改表单定义后更新修改时间，新增时同时填充创建时间
+// 表单通过 SaveRow 按字段写入，不会自动填充这两个时间
+func TouchFormDefinition(ctx context.Context, id string) error {
+	now := time.Now()
+	return writer(ctx).Exec(`UPDATE form_definitions SET updated_at = ?, created_at = COALESCE(created_at, ?) WHERE id = ?`,
+		now, now, id).Error
+}
+
+// DeleteFormDefinitions 删除表单定义，并在同一个事务中删除这些表单的提交记录
+//
+// 参数:
+//   - ctx: 上下文
+//   - ids: 要删除的表单编号
+//
+// 返回值:
+//   - error: 删除失败时返回数据库错误，此时表单和提交记录都不会被删除
+func DeleteFormDefinitions(ctx context.Context, ids []string) error {
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("form_id IN ?", ids).Delete(&FormSubmission{}).Error; err != nil {
+			return err
+		}
+		return writer(ctx).Where("id IN ?", ids).Delete(&FormDefinition{}).Error
+	})
+}
+
+// CreateFormSubmission 保存一次表单提交
+//
+// 参数:
+//   - ctx: 上下文
+//   - formID: 表单定义的编号
+//   - submittedBy: 提交表单的管理员ID
+//   - data: 字段名到值的映射，调用方负责按表单定义校验并只保留定义中的字段
+//
+// 返回值:
+//   - FormSubmission: 保存的提交记录
+//   - error: 写入失败时返回数据库错误
+func CreateFormSubmission(ctx context.Context, formID uint, submittedBy int64, data map[string]interface{}) (FormSubmission, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return FormSubmission{}, err
+	}
+	s := FormSubmission{FormID: formID, SubmittedBy: submittedBy, Data: string(raw)}
+	return s, writer(ctx).Create(&s).Error
+}
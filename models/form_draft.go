@@ -0,0 +1,76 @@
+// models 包 - 数据模型层
+// 本文件定义表单草稿的模型和读写方法
+// 较长的表单定时把填写的内容保存为草稿，每个管理员在每个表单上最多一份，保存在 form_drafts 表中
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// FormDraft 表单草稿模型
+// 该结构体映射到 form_drafts 表
+type FormDraft struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id
+	// 与 Form 组成联合唯一索引，保证每个管理员在每个表单上只有一份草稿
+	UserID int64 `gorm:"column:user_id"`
+
+	// Form 表单的标识，由页面指定，如 demo
+	Form string `gorm:"column:form"`
+
+	// Data 表单字段值的 JSON，键为字段名，值为字段的所有值
+	Data string `gorm:"column:data;type:text"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，即最近一次保存草稿的时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 FormDraft 对应的数据库表名
+func (FormDraft) TableName() string {
+	return "form_drafts"
+}
+
+// FindFormDraft 读取管理员在表单上保存的草稿
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - form: 表单的标识
+//
+// 返回值:
+//   - FormDraft: 保存的草稿
+//   - error: 没有草稿时返回 gorm.ErrRecordNotFound
+//
+// 注意事项:
+//   - 草稿随时会被自动保存覆盖，与仪表板布局一样从主库读取
+func FindFormDraft(ctx context.Context, userID int64, form string) (FormDraft, error) {
+	var d FormDraft
+	err := orm.WithContext(ctx).Where("user_id = ? AND form = ?", userID, form).Take(&d).Error
+	return d, err
+}
+
+// SaveFormDraft 保存管理员在表单上的草稿，已有草稿时覆盖
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - form: 表单的标识
+//   - data: 表单字段值的 JSON，调用方负责检查格式和大小
+func SaveFormDraft(ctx context.Context, userID int64, form, data string) error {
+	var d FormDraft
+	return writer(ctx).Where(FormDraft{UserID: userID, Form: form}).
+		Assign(FormDraft{Data: data}).
+		FirstOrCreate(&d).Error
+}
+
+// DeleteFormDraft 删除管理员在表单上的草稿，表单提交成功或放弃草稿时调用；没有草稿时不返回错误
+func DeleteFormDraft(ctx context.Context, userID int64, form string) error {
+	return writer(ctx).Where("user_id = ? AND form = ?", userID, form).Delete(&FormDraft{}).Error
+}
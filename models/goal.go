@@ -0,0 +1,79 @@
+// models 包 - 数据模型层
+// 本文件定义目标模型，仪表板"目标完成情况"的进度条从 goals 表读取
+// goals 表通过 tables.GetGoalsTable 在管理后台增删改
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Goal 目标模型
+// 每条记录对应仪表板上的一个进度条，进度 = Current / Target
+type Goal struct {
+	// ID 主键字段，进度条按 ID 升序排列
+	ID uint `gorm:"primaryKey"`
+
+	// Name 目标名称，显示为进度条标题
+	Name string `gorm:"column:name"`
+
+	// Target 目标值，显示为进度条的分母
+	Target float64 `gorm:"column:target"`
+
+	// Current 当前完成值，显示为进度条的分子
+	Current float64 `gorm:"column:current"`
+
+	// Color 进度条颜色，如 #76b2d4
+	Color string `gorm:"column:color"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Goal 对应的数据库表名
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// defaultGoals 首次创建 goals 表时写入的示例目标
+var defaultGoals = []Goal{
+	{Name: "添加商品到购物车", Target: 200, Current: 160, Color: "#76b2d4"},
+	{Name: "完成购买", Target: 400, Current: 310, Color: "#f17c6e"},
+	{Name: "访问高级页面", Target: 800, Current: 490, Color: "#ace0ae"},
+	{Name: "发送咨询", Target: 500, Current: 250, Color: "#fdd698"},
+}
+
+// AllGoals 获取全部目标，按 ID 升序排列
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//
+// 返回值:
+//   - []Goal: 目标列表，查询失败时返回空列表
+//
+// 注意事项:
+//   - 目标通过管理后台的表格直接写库，不经过GORM钩子，因此这里不使用缓存
+func AllGoals(ctx context.Context) []Goal {
+	var goals []Goal
+	reader(ctx).Order("id").Find(&goals)
+	return goals
+}
+
+// seedGoals 在 goals 表为空时写入示例目标
+func seedGoals(ctx context.Context) error {
+	var count int64
+	if err := orm.WithContext(ctx).Model(&Goal{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	for i := range defaultGoals {
+		g := defaultGoals[i]
+		if err := orm.WithContext(ctx).Create(&g).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
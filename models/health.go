@@ -0,0 +1,200 @@
+// models 包 - 数据模型层
+// 本文件实现数据库的健康检查
+// 检查主库和只读副本能否连通、本包使用的数据表是否存在，并汇报连接池的使用情况，
+// 供 /healthz 接口和启动时的自检使用
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// requiredTables 本包的模型使用的数据表，均由 migrations 包创建
+var requiredTables = []string{
+	"users",
+	"authors",
+	"posts",
+	"orders",
+	"order_items",
+	"payments",
+	"statistics",
+	"statistics_events",
+	"daily_counts",
+	"page_views",
+	"goals",
+	"categories",
+	"products",
+	"tags",
+	"post_tags",
+	"files",
+	"customers",
+	"customer_notes",
+	"notifications",
+	"messages",
+	"settings",
+	"dashboard_layouts",
+	"column_preferences",
+	"audit_logs",
+	"user_locations",
+	"tasks",
+	"admin_preferences",
+	"form_drafts",
+	"form_definitions",
+	"form_submissions",
+	"locations",
+	"work_experiences",
+	"demo_form_submissions",
+	"regions",
+	"admin_oauth_accounts",
+	"api_keys",
+	"api_refresh_tokens",
+	"admin_sessions",
+}
+
+// ErrMissingTables 数据库中缺少本包使用的数据表
+var ErrMissingTables = errors.New("缺少数据表")
+
+// PoolStats 一个连接池的使用情况
+type PoolStats struct {
+	// MaxOpen 最大打开连接数，0 表示不限制
+	MaxOpen int `json:"max_open"`
+
+	// Open 当前打开的连接数，等于 InUse 与 Idle 之和
+	Open int `json:"open"`
+
+	// InUse 正在使用的连接数
+	InUse int `json:"in_use"`
+
+	// Idle 空闲的连接数
+	Idle int `json:"idle"`
+
+	// WaitCount 因连接数达到上限而等待过的次数
+	WaitCount int64 `json:"wait_count"`
+
+	// WaitDuration 等待连接的累计耗时
+	WaitDuration string `json:"wait_duration"`
+}
+
+// DatabaseHealth 一个数据库连接的检查结果
+type DatabaseHealth struct {
+	// Name config.yml 中 database 配置项下的连接名
+	Name string `json:"name"`
+
+	// OK 能否连通
+	OK bool `json:"ok"`
+
+	// Error 连接失败的原因，连通时为空
+	Error string `json:"error,omitempty"`
+
+	// Latency Ping 的耗时
+	Latency string `json:"latency"`
+
+	// Pool 连接池的使用情况
+	Pool PoolStats `json:"pool"`
+}
+
+// Health 健康检查的结果
+type Health struct {
+	// OK 主库和所有副本都能连通，且没有缺少数据表
+	OK bool `json:"ok"`
+
+	// Databases 主库和只读副本的检查结果，主库在第一个
+	Databases []DatabaseHealth `json:"databases"`
+
+	// MissingTables 主库中缺少的数据表
+	MissingTables []string `json:"missing_tables,omitempty"`
+}
+
+// HealthCheck 检查数据库的健康状况
+//
+// 参数:
+//   - ctx: 上下文，Ping 和查询表结构随之取消
+//
+// 返回值:
+//   - Health: 每个连接的检查结果和缺少的数据表，出错时同样完整填写
+//   - error: 有连接无法连通时返回该连接的错误，缺少数据表时返回包装了 ErrMissingTables 的错误，其中列出表名
+//
+// 使用示例:
+//
+//	if _, err := models.HealthCheck(ctx); err != nil {
+//	    log.Println(err) // 缺少数据表: audit_logs, goals
+//	}
+//
+// 注意事项:
+//   - 必须在 Init 之后调用
+//   - 副本的表结构由复制从主库同步，只检查能否连通
+func HealthCheck(ctx context.Context) (Health, error) {
+	h := Health{OK: true}
+	var firstErr error
+
+	check := func(name string, gdb *gorm.DB) {
+		d, err := pingDatabase(ctx, name, gdb)
+		h.Databases = append(h.Databases, d)
+		if err != nil {
+			h.OK = false
+			if firstErr == nil {
+				firstErr = fmt.Errorf("数据库 %s 无法连通: %w", name, err)
+			}
+		}
+	}
+	check(primaryConnection, orm)
+	for _, r := range replicas {
+		check(r.name, r.db)
+	}
+
+	// 主库无法连通时查询表结构也会失败，不再重复报错
+	if !h.Databases[0].OK {
+		return h, firstErr
+	}
+
+	m := orm.WithContext(ctx).Migrator()
+	for _, name := range requiredTables {
+		if !m.HasTable(name) {
+			h.MissingTables = append(h.MissingTables, name)
+		}
+	}
+	if len(h.MissingTables) > 0 {
+		h.OK = false
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%w: %s", ErrMissingTables, strings.Join(h.MissingTables, ", "))
+		}
+	}
+	return h, firstErr
+}
+
+// pingDatabase Ping 一个连接并读取其连接池的使用情况
+func pingDatabase(ctx context.Context, name string, gdb *gorm.DB) (DatabaseHealth, error) {
+	d := DatabaseHealth{Name: name}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		d.Error = err.Error()
+		return d, err
+	}
+
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	d.Latency = time.Since(start).String()
+
+	s := sqlDB.Stats()
+	d.Pool = PoolStats{
+		MaxOpen:      s.MaxOpenConnections,
+		Open:         s.OpenConnections,
+		InUse:        s.InUse,
+		Idle:         s.Idle,
+		WaitCount:    s.WaitCount,
+		WaitDuration: s.WaitDuration.String(),
+	}
+
+	if err != nil {
+		d.Error = err.Error()
+		return d, err
+	}
+	d.OK = true
+	return d, nil
+}
@@ -0,0 +1,243 @@
+// models 包 - 数据模型层
+// 本文件实现通用的表格导入：读取 CSV 或 XLSX 文件，以及记录每次导入的导入历史（import_logs）
+// 列与字段的对应、逐行校验和分批写入由 tables 包按表单的字段完成（见 tables.withImport），
+// 这里只负责与具体表格无关的部分
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+// MaxImportRows 通用导入一次最多读取的行数（不含表头）
+const MaxImportRows = 5000
+
+// ErrInvalidImportFile 导入文件无法读取，或不是支持的格式
+var ErrInvalidImportFile = errors.New("导入文件无效")
+
+// 导入历史的状态，与 import_logs 表 status 字段中保存的值一致
+const (
+	// ImportRunning 正在分批写入
+	ImportRunning = "running"
+
+	// ImportDone 全部写入完成
+	ImportDone = "done"
+
+	// ImportFailed 写入中途失败，失败之前的批次已经写入
+	ImportFailed = "failed"
+)
+
+// ImportRecord 导入文件中的一行
+type ImportRecord struct {
+	// Line 在文件中的行号，表头为第 1 行
+	Line int
+
+	// Cells 各列的内容，已去掉首尾空白
+	Cells []string
+}
+
+// Cell 返回第 i 列的内容，i 为负数或超出该行的列数时返回空字符串
+func (r ImportRecord) Cell(i int) string {
+	if i < 0 || i >= len(r.Cells) {
+		return ""
+	}
+	return r.Cells[i]
+}
+
+// ImportSheet 导入文件的内容
+type ImportSheet struct {
+	// Header 表头，即文件的第一行
+	Header []string
+
+	// Records 表头之后的每一行，空行被跳过
+	Records []ImportRecord
+}
+
+// ReadImportFile 读取 CSV 或 XLSX 格式的导入文件
+//
+// 参数:
+//   - name: 上传时的文件名，按扩展名（.csv、.xlsx）判断格式
+//   - r: 文件内容；CSV 使用 UTF-8 编码，可以带 BOM；XLSX 只读取第一个工作表
+//
+// 返回值:
+//   - ImportSheet: 表头和数据行
+//   - error: 格式不支持、文件无法解析、没有表头或数据行、超过 MaxImportRows 行时返回包装了 ErrInvalidImportFile 的错误
+func ReadImportFile(name string, r io.Reader) (ImportSheet, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		var err error
+		if rows, err = reader.ReadAll(); err != nil {
+			return ImportSheet{}, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+	case ".xlsx":
+		// excelize 需要随机读取 zip 的目录，先把文件读入内存
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return ImportSheet{}, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		book, err := excelize.OpenReader(bytes.NewReader(content))
+		if err != nil {
+			return ImportSheet{}, fmt.Errorf("%w: 无法读取 Excel 文件: %v", ErrInvalidImportFile, err)
+		}
+		rows = book.GetRows(book.GetSheetName(1))
+	default:
+		return ImportSheet{}, fmt.Errorf("%w: 只支持 .csv 和 .xlsx 文件", ErrInvalidImportFile)
+	}
+	return newImportSheet(rows)
+}
+
+// newImportSheet 把读取到的各行整理为 ImportSheet，第一行为表头
+func newImportSheet(rows [][]string) (ImportSheet, error) {
+	var sheet ImportSheet
+	if len(rows) == 0 || isBlankRecord(rows[0]) {
+		return sheet, fmt.Errorf("%w: 缺少表头", ErrInvalidImportFile)
+	}
+	sheet.Header = trimCells(rows[0])
+	sheet.Header[0] = strings.TrimPrefix(sheet.Header[0], "\uFEFF")
+
+	for i, row := range rows[1:] {
+		if isBlankRecord(row) {
+			continue
+		}
+		if len(sheet.Records) == MaxImportRows {
+			return sheet, fmt.Errorf("%w: 一次最多导入 %d 行", ErrInvalidImportFile, MaxImportRows)
+		}
+		sheet.Records = append(sheet.Records, ImportRecord{Line: i + 2, Cells: trimCells(row)})
+	}
+	if len(sheet.Records) == 0 {
+		return sheet, fmt.Errorf("%w: 没有数据行", ErrInvalidImportFile)
+	}
+	return sheet, nil
+}
+
+// ErrorReport 生成错误报告：有错误的行按原样输出，末尾加上“错误”列
+// errs 与 Records 一一对应，为空字符串的行没有错误，不输出
+// 表头和单元格来自上传的文件，经过 EscapeCSVFormula 处理，避免在 Excel 中被当作公式执行
+func (s ImportSheet) ErrorReport(errs []string) []byte {
+	var buf bytes.Buffer
+	// 带 BOM，Excel 打开时按 UTF-8 识别中文
+	buf.WriteString("\uFEFF")
+	w := csv.NewWriter(&buf)
+	_ = w.Write(append(escapeCSVRow(s.Header), "错误"))
+	for i, r := range s.Records {
+		if i < len(errs) && errs[i] != "" {
+			_ = w.Write(append(escapeCSVRow(r.Cells), errs[i]))
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// trimCells 去掉每个单元格首尾的空白
+func trimCells(row []string) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = strings.TrimSpace(v)
+	}
+	return cells
+}
+
+// ImportLog 导入历史模型
+// 每次导入对应一条记录，分批写入的过程中更新已写入的行数，导入对话框据此显示进度
+type ImportLog struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Prefix 导入的表格前缀，如 products
+	Prefix string `gorm:"column:prefix"`
+
+	// Table 写入的数据表
+	Table string `gorm:"column:table_name"`
+
+	// FileName 上传时的文件名
+	FileName string `gorm:"column:file_name"`
+
+	// Status 导入状态，见 ImportRunning 等常量
+	Status string `gorm:"column:status"`
+
+	// Total 校验通过、准备写入的行数
+	Total int `gorm:"column:total"`
+
+	// Imported 已经写入的行数
+	Imported int `gorm:"column:imported"`
+
+	// Skipped 校验未通过而跳过的行数
+	Skipped int `gorm:"column:skipped"`
+
+	// Error 导入失败的原因
+	Error string `gorm:"column:error"`
+
+	// AdminID 执行导入的管理员 ID
+	AdminID int64 `gorm:"column:admin_id"`
+
+	// AdminName 执行导入的管理员名称
+	AdminName string `gorm:"column:admin_name"`
+
+	// CreatedAt 开始导入的时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 最近一次更新进度的时间，由GORM自动填充
+	UpdatedAt time.Time
+
+	// FinishedAt 导入结束的时间，导入中为空
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+// TableName 指定 ImportLog 对应的数据库表名
+func (ImportLog) TableName() string {
+	return "import_logs"
+}
+
+// Finished 导入是否已经结束（完成或失败）
+func (l ImportLog) Finished() bool {
+	return l.Status != ImportRunning
+}
+
+// CreateImportLog 开始一次导入，写入状态为 ImportRunning 的导入历史，l.ID 被填充为新记录的编号
+func CreateImportLog(ctx context.Context, l *ImportLog) error {
+	l.Status = ImportRunning
+	return writer(ctx).Create(l).Error
+}
+
+// UpdateImportProgress 更新已写入的行数
+// 每个批次的事务提交之后调用，不在批次的事务中执行，轮询进度时可以立即读到
+func UpdateImportProgress(ctx context.Context, id uint, imported int) error {
+	return orm.WithContext(ctx).Model(&ImportLog{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"imported": imported, "updated_at": time.Now()}).Error
+}
+
+// FinishImportLog 结束一次导入
+//
+// 参数:
+//   - id: 导入历史的编号
+//   - cause: 导入失败的原因，为 nil 时状态为 ImportDone，否则为 ImportFailed
+func FinishImportLog(ctx context.Context, id uint, cause error) error {
+	now := time.Now()
+	values := map[string]interface{}{"status": ImportDone, "finished_at": now, "updated_at": now}
+	if cause != nil {
+		values["status"] = ImportFailed
+		values["error"] = cause.Error()
+	}
+	return orm.WithContext(ctx).Model(&ImportLog{}).Where("id = ?", id).Updates(values).Error
+}
+
+// FindImportLog 按编号读取导入历史，从主库读取，进度不受副本延迟的影响
+func FindImportLog(ctx context.Context, id string) (ImportLog, error) {
+	var l ImportLog
+	err := orm.WithContext(ctx).Where("id = ?", id).Take(&l).Error
+	return l, err
+}
@@ -0,0 +1,47 @@
+// models 包 - 数据模型层
+// 本文件定义地点模型，地点的坐标在表单中通过地图选择（见 tables.GetLocationsTable）
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Location 地点模型
+// 该结构体映射到 locations 表
+type Location struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 地点名称
+	Name string `gorm:"column:name"`
+
+	// Address 地址
+	Address string `gorm:"column:address"`
+
+	// Latitude 纬度，WGS84 坐标，范围 -90 到 90
+	Latitude float64 `gorm:"column:latitude"`
+
+	// Longitude 经度，WGS84 坐标，范围 -180 到 180
+	Longitude float64 `gorm:"column:longitude"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+
+	// UpdatedAt 修改时间
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Location 对应的数据库表名
+func (Location) TableName() string {
+	return "locations"
+}
+
+// TouchLocation 在新增或修改地点后更新修改时间，新增时同时填充创建时间
+// 表单通过 SaveRow 按字段写入，不会自动填充这两个时间
+func TouchLocation(ctx context.Context, id string) error {
+	now := time.Now()
+	return writer(ctx).Exec(`UPDATE locations SET updated_at = ?, created_at = COALESCE(created_at, ?) WHERE id = ?`,
+		now, now, id).Error
+}
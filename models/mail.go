@@ -0,0 +1,206 @@
+// models 包 - 数据模型层
+// 本文件实现通过 SMTP 发送带附件的邮件，定时导出用它把生成的文件发给收件人
+
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMailNotConfigured 没有配置邮件服务器
+var ErrMailNotConfigured = errors.New("未配置邮件服务器（config.yml 的 mail.host）")
+
+// MailConfig 邮件服务器的配置，对应 config.yml 的 mail 配置项
+type MailConfig struct {
+	// Host SMTP 服务器地址，为空时不能发送邮件
+	Host string `yaml:"host"`
+
+	// Port SMTP 端口，服务器支持 STARTTLS 时自动加密
+	Port int `yaml:"port"`
+
+	// Username、Password 登录用户名和密码，用户名为空时不登录
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// From 发件人，如 GoAdmin <noreply@example.com>
+	From string `yaml:"from"`
+}
+
+// DefaultMailConfig 配置文件中没有 mail 配置项或未设置某一项时使用的默认值
+var DefaultMailConfig = MailConfig{Port: 587}
+
+// MailAttachment 邮件附件
+type MailAttachment struct {
+	// Name 附件的文件名，可以包含中文
+	Name string
+
+	// ContentType 附件的 MIME 类型，如 text/csv
+	ContentType string
+
+	// Data 附件的内容
+	Data []byte
+}
+
+// Mail 一封邮件
+type Mail struct {
+	// To 收件人地址
+	To []string
+
+	// Subject 主题
+	Subject string
+
+	// Body 纯文本正文
+	Body string
+
+	// Attachments 附件
+	Attachments []MailAttachment
+}
+
+// Mailer 通过 SMTP 发送邮件
+type Mailer struct {
+	cfg  MailConfig
+	from *mail.Address
+}
+
+// NewMailer 创建邮件发送器
+//
+// 返回值:
+//   - *Mailer: 发送器；cfg.Host 为空时返回的发送器每次发送都返回 ErrMailNotConfigured
+//   - error: 发件人地址无效时返回错误
+func NewMailer(cfg MailConfig) (*Mailer, error) {
+	if cfg.Port == 0 {
+		cfg.Port = DefaultMailConfig.Port
+	}
+	m := &Mailer{cfg: cfg}
+	if cfg.Host == "" {
+		return m, nil
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %s", cfg.From)
+	}
+	m.from = from
+	return m, nil
+}
+
+// ParseMailAddresses 解析用逗号、分号或换行分隔的收件人地址，去掉空项和重复的地址
+// 任何一个地址无效时返回错误
+func ParseMailAddresses(s string) ([]string, error) {
+	seen := make(map[string]bool)
+	var list []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '\r' || r == '，' || r == '；'
+	}) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(part)
+		if err != nil {
+			return nil, fmt.Errorf("邮件地址无效: %s", part)
+		}
+		if key := strings.ToLower(addr.Address); !seen[key] {
+			seen[key] = true
+			list = append(list, addr.Address)
+		}
+	}
+	return list, nil
+}
+
+// Send 发送邮件
+//
+// 返回值:
+//   - error: 未配置邮件服务器时返回 ErrMailNotConfigured；连接、登录或投递失败时返回错误
+func (m *Mailer) Send(msg Mail) error {
+	if m == nil || m.cfg.Host == "" {
+		return ErrMailNotConfigured
+	}
+	if len(msg.To) == 0 {
+		return errors.New("没有收件人")
+	}
+	body, err := m.message(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	return smtp.SendMail(addr, auth, m.from.Address, msg.To, body)
+}
+
+// mailPart multipart 邮件中的一部分
+type mailPart struct {
+	header textproto.MIMEHeader
+	data   []byte
+}
+
+// message 生成邮件的原文：multipart/mixed，第一部分为正文，之后每个附件一部分，均使用 base64 编码
+func (m *Mailer) message(msg Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headers := [][2]string{
+		{"From", m.from.String()},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.BEncoding.Encode("UTF-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/mixed; boundary=" + w.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	parts := []mailPart{{
+		header: textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}},
+		data:   []byte(msg.Body),
+	}}
+	for _, a := range msg.Attachments {
+		parts = append(parts, mailPart{
+			header: textproto.MIMEHeader{
+				"Content-Type":        {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Name})},
+				"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+			},
+			data: a.Data,
+		})
+	}
+
+	for _, p := range parts {
+		p.header.Set("Content-Transfer-Encoding", "base64")
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(p.data)
+		// 每行不超过 76 个字符
+		for len(encoded) > 76 {
+			if _, err := pw.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := pw.Write([]byte(encoded + "\r\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,97 @@
+// models 包 - 数据模型层
+// 本文件定义管理员之间的站内信模型
+// 每个管理员只能读取和删除发给自己的站内信，这里的查询都带上收件人条件，
+// 管理后台的收件箱（tables.GetMessagesTable）按当前登录的管理员过滤
+
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotRecipient 站内信不存在，或者不是发给该管理员的
+var ErrNotRecipient = errors.New("站内信不存在或不是发给你的")
+
+// Message 站内信模型
+type Message struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// SenderID 发件人的管理员 ID
+	SenderID int64 `gorm:"column:sender_id"`
+
+	// SenderName 发件人的管理员名称，发送时写入
+	SenderName string `gorm:"column:sender_name"`
+
+	// RecipientID 收件人的管理员 ID
+	RecipientID int64 `gorm:"column:recipient_id"`
+
+	// Subject 主题
+	Subject string `gorm:"column:subject"`
+
+	// Content 正文
+	Content string `gorm:"column:content"`
+
+	// IsRead 收件人是否已读
+	IsRead bool `gorm:"column:is_read"`
+
+	// CreatedAt 发送时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Message 对应的数据库表名
+func (Message) TableName() string {
+	return "messages"
+}
+
+// ReadMessage 读取发给管理员的一封站内信，并将其标为已读
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - id: 站内信编号
+//   - recipientID: 当前管理员的 ID
+//
+// 返回值:
+//   - Message: 站内信，IsRead 为读取前的状态
+//   - error: 站内信不存在或收件人不是该管理员时返回 ErrNotRecipient，标记失败时返回数据库错误
+func ReadMessage(ctx context.Context, id string, recipientID int64) (Message, error) {
+	var m Message
+	res := orm.WithContext(ctx).Where("id = ? AND recipient_id = ?", id, recipientID).Limit(1).Find(&m)
+	if res.Error != nil {
+		return m, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return m, ErrNotRecipient
+	}
+	if !m.IsRead {
+		err := writer(ctx).Model(&Message{}).Where("id = ?", m.ID).
+			Updates(map[string]interface{}{"is_read": true, "updated_at": time.Now()}).Error
+		if err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// CheckMessageRecipient 检查一组站内信是否都是发给该管理员的，删除前调用
+//
+// 返回值:
+//   - error: 有任何一封不存在或不是发给该管理员的时返回 ErrNotRecipient，查询失败时返回数据库错误
+func CheckMessageRecipient(ctx context.Context, ids []string, recipientID int64) error {
+	var n int64
+	err := orm.WithContext(ctx).Model(&Message{}).
+		Where("id IN ? AND recipient_id = ?", ids, recipientID).
+		Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n != int64(len(ids)) {
+		return ErrNotRecipient
+	}
+	return nil
+}
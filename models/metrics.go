@@ -0,0 +1,75 @@
+// models 包 - 数据模型层
+// 本文件汇总供 /metrics 输出的运行指标：主库和只读副本的连接池使用情况，以及统计数据缓存的命中次数
+
+package models
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// DatabasePool 一个数据库连接的连接池统计
+type DatabasePool struct {
+	// Name config.yml 中 database 配置项下的连接名
+	Name string
+
+	// Stats database/sql 的连接池统计
+	Stats sql.DBStats
+}
+
+// DatabasePools 返回主库和只读副本的连接池统计，主库在第一个
+// Init 之前，或取不到底层连接的数据库不在返回值中
+func DatabasePools() []DatabasePool {
+	var pools []DatabasePool
+	add := func(name string, sqlDB *sql.DB, err error) {
+		if err == nil {
+			pools = append(pools, DatabasePool{Name: name, Stats: sqlDB.Stats()})
+		}
+	}
+	if orm == nil {
+		return pools
+	}
+	sqlDB, err := orm.DB()
+	add(primaryConnection, sqlDB, err)
+	for _, r := range replicas {
+		sqlDB, err := r.db.DB()
+		add(r.name, sqlDB, err)
+	}
+	return pools
+}
+
+// CacheStats 缓存的累计读取结果，切换缓存后端（SetCache）后继续累计
+type CacheStats struct {
+	// Hits 命中次数
+	Hits uint64
+
+	// Misses 未命中、已过期或反序列化失败的次数
+	Misses uint64
+}
+
+// cacheHits、cacheMisses 由 meteredCache 累计
+var cacheHits, cacheMisses uint64
+
+// CacheMetrics 返回缓存的累计命中次数
+func CacheMetrics() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&cacheHits),
+		Misses: atomic.LoadUint64(&cacheMisses),
+	}
+}
+
+// meteredCache 统计命中次数的缓存后端，包装实际使用的内存或 Redis 缓存，Set 和 DeletePrefix 直接交给后者
+type meteredCache struct {
+	Cache
+}
+
+// Get 读取缓存并累计命中或未命中的次数
+func (c meteredCache) Get(key string, dst interface{}) bool {
+	ok := c.Cache.Get(key, dst)
+	if ok {
+		atomic.AddUint64(&cacheHits, 1)
+	} else {
+		atomic.AddUint64(&cacheMisses, 1)
+	}
+	return ok
+}
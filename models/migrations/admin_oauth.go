@@ -0,0 +1,90 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为第三方账号登录增加管理员邮箱字段 admin_preferences.email，并定义第三方账号关联表 admin_oauth_accounts
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// adminPreferenceEmail 0050 版本为 admin_preferences 表增加的字段
+type adminPreferenceEmail struct {
+	Email string `gorm:"size:100;not null;default:'';index:idx_admin_preferences_email"`
+}
+
+func (adminPreferenceEmail) TableName() string { return "admin_preferences" }
+
+// adminOAuthAccount 0051 版本的 admin_oauth_accounts 表结构
+type adminOAuthAccount struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"not null;index:idx_admin_oauth_accounts_user_id"`
+	Provider  string `gorm:"size:20;not null;uniqueIndex:idx_admin_oauth_accounts_subject"`
+	Subject   string `gorm:"size:100;not null;uniqueIndex:idx_admin_oauth_accounts_subject"`
+	Email     string `gorm:"size:100;not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (adminOAuthAccount) TableName() string { return "admin_oauth_accounts" }
+
+func init() {
+	register(
+		Migration{
+			// 第三方账号第一次登录时按邮箱关联管理员，已有管理员的邮箱为空，在个人资料页面中填写；
+			// 非空邮箱不能重复，由个人资料页面的校验保证，这里只建普通索引
+			Version: "0050",
+			Name:    "add_admin_preferences_email",
+			Up: sqliteOr(exec(`ALTER TABLE "admin_preferences" ADD COLUMN "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT ''`,
+				`CREATE INDEX IF NOT EXISTS "idx_admin_preferences_email" ON "admin_preferences"("email")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.AddColumn(&adminPreferenceEmail{}, "Email"); err != nil {
+						return err
+					}
+					return m.CreateIndex(&adminPreferenceEmail{}, "idx_admin_preferences_email")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0041 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_admin_preferences_email"`,
+				`DROP INDEX IF EXISTS "idx_admin_preferences_user_id"`,
+				`CREATE TABLE "admin_preferences_old" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "language" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`INSERT INTO "admin_preferences_old" ("id", "user_id", "language", "created_at", "updated_at")
+SELECT "id", "user_id", "language", "created_at", "updated_at" FROM "admin_preferences"`,
+				`DROP TABLE "admin_preferences"`,
+				`ALTER TABLE "admin_preferences_old" RENAME TO "admin_preferences"`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_admin_preferences_user_id" ON "admin_preferences"("user_id")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.DropIndex(&adminPreferenceEmail{}, "idx_admin_preferences_email"); err != nil {
+						return err
+					}
+					return m.DropColumn(&adminPreferenceEmail{}, "Email")
+				}),
+		},
+		Migration{
+			// 管理员（goadmin_users.id）关联的第三方账号，provider 为 github、google、gitee，
+			// subject 为第三方平台的用户 ID，同一个第三方账号只能关联一个管理员；email 为最近一次登录时的邮箱
+			Version: "0051",
+			Name:    "create_admin_oauth_accounts",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "admin_oauth_accounts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "provider" CHAR(20) NOT NULL,
+  "subject" CHAR(100) NOT NULL,
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_admin_oauth_accounts_subject" ON "admin_oauth_accounts"("provider", "subject")`,
+				`CREATE INDEX IF NOT EXISTS "idx_admin_oauth_accounts_user_id" ON "admin_oauth_accounts"("user_id")`),
+				createTable(&adminOAuthAccount{})),
+			Down: dropTable("admin_oauth_accounts"),
+		},
+	)
+}
@@ -0,0 +1,36 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义保存管理员个人偏好的 admin_preferences 表
+package migrations
+
+import "time"
+
+// adminPreference 0041 版本的 admin_preferences 表结构
+type adminPreference struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"not null;uniqueIndex:idx_admin_preferences_user_id"`
+	Language  string `gorm:"size:20;not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (adminPreference) TableName() string { return "admin_preferences" }
+
+func init() {
+	register(
+		Migration{
+			// 每个管理员（goadmin_users.id）一条记录，language 为空表示跟随 config.yml 的 language
+			Version: "0041",
+			Name:    "create_admin_preferences",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "admin_preferences" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "language" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_admin_preferences_user_id" ON "admin_preferences"("user_id")`),
+				createTable(&adminPreference{})),
+			Down: dropTable("admin_preferences"),
+		},
+	)
+}
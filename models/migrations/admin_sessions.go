@@ -0,0 +1,42 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义记录后台登录会话的 IP、浏览器和最近活动时间的表 admin_sessions
+package migrations
+
+import "time"
+
+// adminSession 0054 版本的 admin_sessions 表结构
+type adminSession struct {
+	ID           uint   `gorm:"primaryKey"`
+	Sid          string `gorm:"size:50;not null;uniqueIndex:idx_admin_sessions_sid"`
+	UserID       int64  `gorm:"not null;index:idx_admin_sessions_user_id"`
+	IP           string `gorm:"column:ip;size:50;not null;default:''"`
+	UserAgent    string `gorm:"size:500;not null;default:''"`
+	LastActiveAt time.Time
+	CreatedAt    time.Time
+}
+
+func (adminSession) TableName() string { return "admin_sessions" }
+
+func init() {
+	register(
+		Migration{
+			// sid 与 GoAdmin 的 goadmin_session.sid 对应，会话本身仍由 GoAdmin 保存和校验，这里只记录附加信息；
+			// goadmin_session 中已经不存在的会话（退出登录、过期或被踢出）在列表中不显示
+			Version: "0054",
+			Name:    "create_admin_sessions",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "admin_sessions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "sid" CHAR(50) NOT NULL,
+  "user_id" integer NOT NULL,
+  "ip" CHAR(50) NOT NULL DEFAULT '',
+  "user_agent" text NOT NULL DEFAULT '',
+  "last_active_at" datetime,
+  "created_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_admin_sessions_sid" ON "admin_sessions"("sid")`,
+				`CREATE INDEX IF NOT EXISTS "idx_admin_sessions_user_id" ON "admin_sessions"("user_id")`),
+				createTable(&adminSession{})),
+			Down: dropTable("admin_sessions"),
+		},
+	)
+}
@@ -0,0 +1,53 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义 JSON 接口使用的 API 密钥表 api_keys
+package migrations
+
+import "time"
+
+// apiKey 0052 版本的 api_keys 表结构
+type apiKey struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"size:100;not null;default:''"`
+	Prefix     string `gorm:"size:16;not null;default:''"`
+	KeyHash    string `gorm:"size:64;not null;uniqueIndex:idx_api_keys_key_hash"`
+	Scopes     string `gorm:"size:100;not null;default:''"`
+	Tables     string `gorm:"size:1000;not null;default:''"`
+	CreatedBy  int64  `gorm:"not null;default:0;index:idx_api_keys_created_by"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (apiKey) TableName() string { return "api_keys" }
+
+func init() {
+	register(
+		Migration{
+			// 只保存密钥的 SHA-256（key_hash）和开头几位（prefix，用于在列表中辨认），明文只在签发时显示一次
+			// scopes 为逗号分隔的 read、write；tables 为逗号分隔的表格前缀，为空表示创建人有权限的全部表格
+			// created_by 为签发密钥的管理员（goadmin_users.id），接口以该管理员的角色和权限访问表格
+			Version: "0052",
+			Name:    "create_api_keys",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "api_keys" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "prefix" text NOT NULL DEFAULT '',
+  "key_hash" CHAR(64) NOT NULL,
+  "scopes" text NOT NULL DEFAULT '',
+  "tables" text NOT NULL DEFAULT '',
+  "created_by" integer NOT NULL DEFAULT 0,
+  "expires_at" datetime,
+  "last_used_at" datetime,
+  "revoked_at" datetime,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys"("key_hash")`,
+				`CREATE INDEX IF NOT EXISTS "idx_api_keys_created_by" ON "api_keys"("created_by")`),
+				createTable(&apiKey{})),
+			Down: dropTable("api_keys"),
+		},
+	)
+}
@@ -0,0 +1,40 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义 JSON 接口登录后签发的刷新令牌表 api_refresh_tokens
+package migrations
+
+import "time"
+
+// apiRefreshToken 0053 版本的 api_refresh_tokens 表结构
+type apiRefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    int64     `gorm:"not null;index:idx_api_refresh_tokens_user_id"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex:idx_api_refresh_tokens_token_hash"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (apiRefreshToken) TableName() string { return "api_refresh_tokens" }
+
+func init() {
+	register(
+		Migration{
+			// 只保存刷新令牌的 SHA-256（token_hash）；user_id 为登录的管理员（goadmin_users.id）
+			// 每次刷新吊销旧令牌（revoked_at）并签发新令牌，过期和吊销的记录保留，用于发现被盗用的令牌
+			Version: "0053",
+			Name:    "create_api_refresh_tokens",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "api_refresh_tokens" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "token_hash" CHAR(64) NOT NULL,
+  "expires_at" datetime NOT NULL,
+  "revoked_at" datetime,
+  "created_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_refresh_tokens_token_hash" ON "api_refresh_tokens"("token_hash")`,
+				`CREATE INDEX IF NOT EXISTS "idx_api_refresh_tokens_user_id" ON "api_refresh_tokens"("user_id")`),
+				createTable(&apiRefreshToken{})),
+			Down: dropTable("api_refresh_tokens"),
+		},
+	)
+}
@@ -0,0 +1,44 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义记录管理后台数据修改的 audit_logs 表
+package migrations
+
+import "time"
+
+// auditLog 0014 版本的 audit_logs 表结构
+type auditLog struct {
+	ID        uint   `gorm:"primaryKey"`
+	Table     string `gorm:"column:table_name;size:64;index:idx_audit_logs_record"`
+	RecordID  string `gorm:"size:64;index:idx_audit_logs_record"`
+	Action    string `gorm:"size:16"`
+	ActorID   int64
+	ActorName string    `gorm:"size:100"`
+	Before    *string   `gorm:"column:before_json"`
+	After     *string   `gorm:"column:after_json"`
+	CreatedAt time.Time `gorm:"index:idx_audit_logs_created_at"`
+}
+
+func (auditLog) TableName() string { return "audit_logs" }
+
+func init() {
+	register(
+		Migration{
+			// 按表和主键查询一条记录的修改历史，按时间查询最近的修改
+			Version: "0014",
+			Name:    "create_audit_logs",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "audit_logs" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "table_name" text,
+  "record_id" text,
+  "action" text,
+  "actor_id" integer,
+  "actor_name" text,
+  "before_json" text,
+  "after_json" text,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_audit_logs_record" ON "audit_logs"("table_name", "record_id")`,
+				`CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs"("created_at")`), createTable(&auditLog{})),
+			Down: dropTable("audit_logs"),
+		},
+	)
+}
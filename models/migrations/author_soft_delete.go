@@ -0,0 +1,69 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 authors 表增加软删除使用的 deleted_at 字段，合并重复的作者时软删除被合并的作者
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// authorSoftDelete 0035 版本为 authors 表增加的字段
+type authorSoftDelete struct {
+	DeletedAt *time.Time `gorm:"index:idx_authors_deleted_at"`
+}
+
+func (authorSoftDelete) TableName() string { return "authors" }
+
+// activeAuthorStatsQuery 0035 版本的 author_stats 视图，在 0034 的基础上排除已删除的作者
+const activeAuthorStatsQuery = authorStatsQuery + `
+WHERE a.deleted_at IS NULL`
+
+func init() {
+	register(
+		Migration{
+			// 作者列表从 author_stats 视图读取，视图需要重建才能排除已删除的作者
+			Version: "0035",
+			Name:    "soft_delete_authors",
+			Up: sqliteOr(exec(`ALTER TABLE "authors" ADD COLUMN "deleted_at" TIMESTAMP`,
+				`CREATE INDEX IF NOT EXISTS "idx_authors_deleted_at" ON "authors"("deleted_at")`,
+				`DROP VIEW IF EXISTS "author_stats"`,
+				`CREATE VIEW "author_stats" AS `+activeAuthorStatsQuery),
+				func(tx *gorm.DB) error {
+					if err := addDeletedAt(&authorSoftDelete{}, "idx_authors_deleted_at")(tx); err != nil {
+						return err
+					}
+					if err := tx.Exec(`DROP VIEW author_stats`).Error; err != nil {
+						return err
+					}
+					return tx.Exec(`CREATE VIEW author_stats AS ` + activeAuthorStatsQuery).Error
+				}),
+			// SQLite 通过重建表删除字段，重建前先删除引用 authors 的视图，之后按 0034 的定义重新创建；
+			// 回滚时已合并的作者会重新出现在列表中，其文章不会移回
+			Down: sqliteOr(exec(`DROP VIEW IF EXISTS "author_stats"`,
+				`DROP INDEX IF EXISTS "idx_authors_deleted_at"`,
+				`CREATE TABLE "authors_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "first_name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "last_name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "birthdate" DATE NOT NULL,
+  "added" TIMESTAMP default CURRENT_TIMESTAMP
+)`,
+				`INSERT INTO "authors_old" ("id", "first_name", "last_name", "email", "birthdate", "added")
+SELECT "id", "first_name", "last_name", "email", "birthdate", "added" FROM "authors"`,
+				`DROP TABLE "authors"`,
+				`ALTER TABLE "authors_old" RENAME TO "authors"`,
+				`CREATE VIEW "author_stats" AS `+authorStatsQuery),
+				func(tx *gorm.DB) error {
+					if err := tx.Exec(`DROP VIEW author_stats`).Error; err != nil {
+						return err
+					}
+					if err := dropDeletedAt(&authorSoftDelete{}, "idx_authors_deleted_at")(tx); err != nil {
+						return err
+					}
+					return tx.Exec(`CREATE VIEW author_stats AS ` + authorStatsQuery).Error
+				}),
+		},
+	)
+}
@@ -0,0 +1,47 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义作者列表使用的 author_stats 视图：每个作者的文章数和最近一篇文章的日期
+package migrations
+
+import "gorm.io/gorm"
+
+// postAuthorIndex 0034 版本为 posts 表增加的索引，视图按作者统计文章时使用
+type postAuthorIndex struct {
+	AuthorID int64 `gorm:"index:idx_posts_author_id"`
+}
+
+func (postAuthorIndex) TableName() string { return "posts" }
+
+// authorStatsQuery author_stats 视图的查询，各数据库通用
+// 视图列出 authors 的全部字段，authors 以后增加字段时需要新增迁移重建视图；
+// 重建 posts 或 authors 表的迁移（如 SQLite 删除字段）需要先删除视图
+const authorStatsQuery = `SELECT a.id, a.first_name, a.last_name, a.email, a.birthdate, a.added,
+  (SELECT COUNT(*) FROM posts p WHERE p.author_id = a.id AND p.deleted_at IS NULL) AS post_count,
+  (SELECT MAX(p.date) FROM posts p WHERE p.author_id = a.id AND p.deleted_at IS NULL) AS last_post_date
+FROM authors a`
+
+func init() {
+	register(
+		Migration{
+			// 文章数和最近发表日期由视图中的子查询计算，不保存在 authors 表中，已删除的文章不计入
+			// 作者列表从视图读取，可以按这两列排序；作者的新增、修改和删除仍写入 authors 表
+			Version: "0034",
+			Name:    "create_author_stats",
+			Up: sqliteOr(exec(`CREATE INDEX IF NOT EXISTS "idx_posts_author_id" ON "posts"("author_id")`,
+				`CREATE VIEW IF NOT EXISTS "author_stats" AS `+authorStatsQuery),
+				func(tx *gorm.DB) error {
+					if err := tx.Migrator().CreateIndex(&postAuthorIndex{}, "idx_posts_author_id"); err != nil {
+						return err
+					}
+					return tx.Exec(`CREATE VIEW author_stats AS ` + authorStatsQuery).Error
+				}),
+			Down: sqliteOr(exec(`DROP VIEW IF EXISTS "author_stats"`,
+				`DROP INDEX IF EXISTS "idx_posts_author_id"`),
+				func(tx *gorm.DB) error {
+					if err := tx.Exec(`DROP VIEW author_stats`).Error; err != nil {
+						return err
+					}
+					return tx.Migrator().DropIndex(&postAuthorIndex{}, "idx_posts_author_id")
+				}),
+		},
+	)
+}
@@ -0,0 +1,43 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 categories 表增加手动排序使用的 sort_order 字段
+package migrations
+
+import "gorm.io/gorm"
+
+// categorySortOrder 0036 版本为 categories 表增加的字段
+type categorySortOrder struct {
+	SortOrder int `gorm:"not null;default:0"`
+}
+
+func (categorySortOrder) TableName() string { return "categories" }
+
+func init() {
+	register(
+		Migration{
+			// 分类列表中拖拽排序后写入同级分类的顺序，0 表示未排序，排在已排序的同级分类之后
+			Version: "0036",
+			Name:    "add_categories_sort_order",
+			Up: sqliteOr(exec(`ALTER TABLE "categories" ADD COLUMN "sort_order" integer NOT NULL DEFAULT 0`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&categorySortOrder{}, "SortOrder")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0018 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_categories_parent_id"`,
+				`CREATE TABLE "categories_old" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "parent_id" integer NOT NULL DEFAULT 0,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`INSERT INTO "categories_old" ("id", "name", "parent_id", "created_at", "updated_at")
+SELECT "id", "name", "parent_id", "created_at", "updated_at" FROM "categories"`,
+				`DROP TABLE "categories"`,
+				`ALTER TABLE "categories_old" RENAME TO "categories"`,
+				`CREATE INDEX IF NOT EXISTS "idx_categories_parent_id" ON "categories"("parent_id")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&categorySortOrder{}, "SortOrder")
+				}),
+		},
+	)
+}
@@ -0,0 +1,38 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义保存列表显示列偏好的 column_preferences 表
+package migrations
+
+import "time"
+
+// columnPreference 0032 版本的 column_preferences 表结构
+type columnPreference struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"uniqueIndex:idx_column_preferences_user_table"`
+	Table     string `gorm:"column:table_name;size:191;uniqueIndex:idx_column_preferences_user_table"`
+	Columns   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (columnPreference) TableName() string { return "column_preferences" }
+
+func init() {
+	register(
+		Migration{
+			// 每个管理员在每个表格上一条记录，columns 为逗号分隔的字段名，为空表示显示全部列
+			Version: "0032",
+			Name:    "create_column_preferences",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "column_preferences" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer,
+  "table_name" text,
+  "columns" text,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_column_preferences_user_table" ON "column_preferences"("user_id", "table_name")`),
+				createTable(&columnPreference{})),
+			Down: dropTable("column_preferences"),
+		},
+	)
+}
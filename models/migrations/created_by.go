@@ -0,0 +1,108 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 和 posts 表增加记录创建人的 created_by 字段
+package migrations
+
+import "gorm.io/gorm"
+
+// userCreatedBy 0030 版本为 users 表增加的字段
+type userCreatedBy struct {
+	CreatedBy int64 `gorm:"not null;default:0;index:idx_users_created_by"`
+}
+
+func (userCreatedBy) TableName() string { return "users" }
+
+// postCreatedBy 0030 版本为 posts 表增加的字段
+type postCreatedBy struct {
+	CreatedBy int64 `gorm:"not null;default:0;index:idx_posts_created_by"`
+}
+
+func (postCreatedBy) TableName() string { return "posts" }
+
+// addCreatedBy 返回增加 created_by 字段及其索引的迁移函数
+func addCreatedBy(model interface{}, index string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.AddColumn(model, "CreatedBy"); err != nil {
+			return err
+		}
+		return m.CreateIndex(model, index)
+	}
+}
+
+// dropCreatedBy 返回删除 created_by 字段及其索引的迁移函数
+func dropCreatedBy(model interface{}, index string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropIndex(model, index); err != nil {
+			return err
+		}
+		return m.DropColumn(model, "CreatedBy")
+	}
+}
+
+func init() {
+	register(
+		Migration{
+			// 保存创建记录的管理员 ID（goadmin_users.id），非超级管理员只能修改和删除自己创建的用户和文章
+			// 已有记录为 0，表示创建人未知，只有超级管理员可以修改
+			Version: "0030",
+			Name:    "add_users_posts_created_by",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "created_by" integer NOT NULL DEFAULT 0`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_created_by" ON "users"("created_by")`,
+				`ALTER TABLE "posts" ADD COLUMN "created_by" integer NOT NULL DEFAULT 0`,
+				`CREATE INDEX IF NOT EXISTS "idx_posts_created_by" ON "posts"("created_by")`),
+				func(tx *gorm.DB) error {
+					if err := addCreatedBy(&userCreatedBy{}, "idx_users_created_by")(tx); err != nil {
+						return err
+					}
+					return addCreatedBy(&postCreatedBy{}, "idx_posts_created_by")(tx)
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0029 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_created_by"`,
+				`DROP INDEX IF EXISTS "idx_users_email"`,
+				`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP,
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "password" CHAR(100) NOT NULL DEFAULT '',
+  "avatar" VARCHAR(255) NOT NULL DEFAULT ''
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password", "avatar")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password", "avatar" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`,
+				`DROP INDEX IF EXISTS "idx_posts_created_by"`,
+				`DROP INDEX IF EXISTS "idx_posts_deleted_at"`,
+				`CREATE TABLE "posts_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "author_id" integer NOT NULL,
+  "title" CHAR(255) COLLATE NOCASE NOT NULL DEFAULT '',
+  "description" CHAR(500) COLLATE NOCASE NOT NULL,
+  "content" text COLLATE NOCASE NOT NULL,
+  "date" DATE NOT NULL,
+  "deleted_at" TIMESTAMP
+)`,
+				`INSERT INTO "posts_old" ("id", "author_id", "title", "description", "content", "date", "deleted_at")
+SELECT "id", "author_id", "title", "description", "content", "date", "deleted_at" FROM "posts"`,
+				`DROP TABLE "posts"`,
+				`ALTER TABLE "posts_old" RENAME TO "posts"`,
+				`CREATE INDEX IF NOT EXISTS "idx_posts_deleted_at" ON "posts"("deleted_at")`),
+				func(tx *gorm.DB) error {
+					if err := dropCreatedBy(&postCreatedBy{}, "idx_posts_created_by")(tx); err != nil {
+						return err
+					}
+					return dropCreatedBy(&userCreatedBy{}, "idx_users_created_by")(tx)
+				}),
+		},
+	)
+}
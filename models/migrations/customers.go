@@ -0,0 +1,117 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义客户表 customers、客户备注表 customer_notes，并为 orders 表增加所属客户
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// customer 0021 版本的 customers 表结构
+type customer struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:100;not null;default:''"`
+	Email     string `gorm:"size:191;not null;default:''"`
+	Phone     string `gorm:"size:50;not null;default:''"`
+	Company   string `gorm:"size:255;not null;default:''"`
+	City      string `gorm:"size:100;not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (customer) TableName() string { return "customers" }
+
+// customerNote 0021 版本的 customer_notes 表结构
+type customerNote struct {
+	ID         uint   `gorm:"primaryKey"`
+	CustomerID uint   `gorm:"not null;index:idx_customer_notes_customer_id"`
+	Content    string `gorm:"not null;default:''"`
+	AuthorName string `gorm:"size:100;not null;default:''"`
+	CreatedAt  time.Time
+}
+
+func (customerNote) TableName() string { return "customer_notes" }
+
+// orderCustomer 0021 版本为 orders 表增加的字段
+type orderCustomer struct {
+	CustomerID uint `gorm:"not null;default:0;index:idx_orders_customer_id"`
+}
+
+func (orderCustomer) TableName() string { return "orders" }
+
+func init() {
+	register(
+		Migration{
+			// 已有订单的 customer_id 为 0，表示未关联客户
+			Version: "0021",
+			Name:    "create_customers",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "customers" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "email" text NOT NULL DEFAULT '',
+  "phone" text NOT NULL DEFAULT '',
+  "company" text NOT NULL DEFAULT '',
+  "city" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE TABLE IF NOT EXISTS "customer_notes" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "customer_id" integer NOT NULL,
+  "content" text NOT NULL DEFAULT '',
+  "author_name" text NOT NULL DEFAULT '',
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_customer_notes_customer_id" ON "customer_notes"("customer_id")`,
+				`ALTER TABLE "orders" ADD COLUMN "customer_id" integer NOT NULL DEFAULT 0`,
+				`CREATE INDEX IF NOT EXISTS "idx_orders_customer_id" ON "orders"("customer_id")`),
+				func(tx *gorm.DB) error {
+					if err := createTable(&customer{})(tx); err != nil {
+						return err
+					}
+					if err := createTable(&customerNote{})(tx); err != nil {
+						return err
+					}
+					m := tx.Migrator()
+					if err := m.AddColumn(&orderCustomer{}, "CustomerID"); err != nil {
+						return err
+					}
+					return m.CreateIndex(&orderCustomer{}, "idx_orders_customer_id")
+				}),
+			// SQLite 通过重建表删除 orders.customer_id，表结构与 0007 版本一致
+			Down: func(tx *gorm.DB) error {
+				err := sqliteOr(exec(`DROP INDEX IF EXISTS "idx_orders_customer_id"`,
+					`CREATE TABLE "orders_old" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "order_no" text,
+  "product" text,
+  "status" text,
+  "amount" real,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+					`INSERT INTO "orders_old" ("id", "order_no", "product", "status", "amount", "created_at", "updated_at")
+SELECT "id", "order_no", "product", "status", "amount", "created_at", "updated_at" FROM "orders"`,
+					`DROP TABLE "orders"`,
+					`ALTER TABLE "orders_old" RENAME TO "orders"`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS "idx_orders_order_no" ON "orders"("order_no")`,
+					`CREATE INDEX IF NOT EXISTS "idx_orders_created_at" ON "orders"("created_at")`),
+					func(tx *gorm.DB) error {
+						m := tx.Migrator()
+						if err := m.DropIndex(&orderCustomer{}, "idx_orders_customer_id"); err != nil {
+							return err
+						}
+						return m.DropColumn(&orderCustomer{}, "CustomerID")
+					})(tx)
+				if err != nil {
+					return err
+				}
+				if err := dropTable("customer_notes")(tx); err != nil {
+					return err
+				}
+				return dropTable("customers")(tx)
+			},
+		},
+	)
+}
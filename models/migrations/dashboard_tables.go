@@ -0,0 +1,166 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义仪表板相关的数据表，这些表以前由 models.Init 中的 AutoMigrate 创建，
+// 字段和索引名称与 GORM 自动迁移的结果一致，已有的数据库执行迁移时不会重复建表
+// 建立索引的文本字段限制为 191 个字符，MySQL 使用 utf8mb4 时索引长度不超过 767 字节
+package migrations
+
+import "time"
+
+// dashboardLayout 0006 版本的 dashboard_layouts 表结构
+type dashboardLayout struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"uniqueIndex:idx_dashboard_layouts_user_dashboard"`
+	Dashboard string `gorm:"size:191;uniqueIndex:idx_dashboard_layouts_user_dashboard"`
+	Widgets   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (dashboardLayout) TableName() string { return "dashboard_layouts" }
+
+// order 0007 版本的 orders 表结构
+type order struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrderNo   string `gorm:"size:191;uniqueIndex:idx_orders_order_no"`
+	Product   string `gorm:"size:255"`
+	Status    string `gorm:"size:255"`
+	Amount    float64
+	CreatedAt time.Time `gorm:"index:idx_orders_created_at"`
+	UpdatedAt time.Time
+}
+
+func (order) TableName() string { return "orders" }
+
+// pageView 0008 版本的 page_views 表结构
+type pageView struct {
+	ID        uint   `gorm:"primaryKey"`
+	Path      string `gorm:"size:255"`
+	Browser   string `gorm:"size:191;index:idx_page_views_browser"`
+	UserAgent string
+	CreatedAt time.Time `gorm:"index:idx_page_views_created_at"`
+}
+
+func (pageView) TableName() string { return "page_views" }
+
+// goal 0009 版本的 goals 表结构
+type goal struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:255"`
+	Target    float64
+	Current   float64
+	Color     string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (goal) TableName() string { return "goals" }
+
+// userLocation 0010 版本的 user_locations 表结构
+type userLocation struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index:idx_user_locations_user_id"`
+	Country   string    `gorm:"size:191;index:idx_user_locations_country"`
+	CreatedAt time.Time `gorm:"index:idx_user_locations_created_at"`
+}
+
+func (userLocation) TableName() string { return "user_locations" }
+
+// dailyCount 0011 版本的 daily_counts 表结构
+type dailyCount struct {
+	ID     uint   `gorm:"primaryKey"`
+	Metric string `gorm:"size:64;uniqueIndex:idx_daily_counts_metric_day"`
+	Day    string `gorm:"size:10;uniqueIndex:idx_daily_counts_metric_day"`
+	Count  int
+}
+
+func (dailyCount) TableName() string { return "daily_counts" }
+
+func init() {
+	register(
+		Migration{
+			Version: "0006",
+			Name:    "create_dashboard_layouts",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "dashboard_layouts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer,
+  "dashboard" text,
+  "widgets" text,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_dashboard_layouts_user_dashboard" ON "dashboard_layouts"("user_id", "dashboard")`), createTable(&dashboardLayout{})),
+			Down: dropTable("dashboard_layouts"),
+		},
+		Migration{
+			Version: "0007",
+			Name:    "create_orders",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "orders" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "order_no" text,
+  "product" text,
+  "status" text,
+  "amount" real,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_orders_order_no" ON "orders"("order_no")`,
+				`CREATE INDEX IF NOT EXISTS "idx_orders_created_at" ON "orders"("created_at")`), createTable(&order{})),
+			Down: dropTable("orders"),
+		},
+		Migration{
+			Version: "0008",
+			Name:    "create_page_views",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "page_views" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "path" text,
+  "browser" text,
+  "user_agent" text,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_page_views_browser" ON "page_views"("browser")`,
+				`CREATE INDEX IF NOT EXISTS "idx_page_views_created_at" ON "page_views"("created_at")`), createTable(&pageView{})),
+			Down: dropTable("page_views"),
+		},
+		Migration{
+			// 示例目标由 models.Init 在表为空时写入，不属于表结构
+			Version: "0009",
+			Name:    "create_goals",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "goals" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text,
+  "target" real,
+  "current" real,
+  "color" text,
+  "created_at" datetime,
+  "updated_at" datetime
+)`), createTable(&goal{})),
+			Down: dropTable("goals"),
+		},
+		Migration{
+			Version: "0010",
+			Name:    "create_user_locations",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "user_locations" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer,
+  "country" text,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_user_locations_user_id" ON "user_locations"("user_id")`,
+				`CREATE INDEX IF NOT EXISTS "idx_user_locations_country" ON "user_locations"("country")`,
+				`CREATE INDEX IF NOT EXISTS "idx_user_locations_created_at" ON "user_locations"("created_at")`), createTable(&userLocation{})),
+			Down: dropTable("user_locations"),
+		},
+		Migration{
+			Version: "0011",
+			Name:    "create_daily_counts",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "daily_counts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "metric" text,
+  "day" text,
+  "count" integer
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_daily_counts_metric_day" ON "daily_counts"("metric", "day")`), createTable(&dailyCount{})),
+			Down: dropTable("daily_counts"),
+		},
+	)
+}
@@ -0,0 +1,41 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义表单页面的提交记录表 demo_form_submissions
+package migrations
+
+import "time"
+
+// demoFormSubmission 0048 版本的 demo_form_submissions 表结构
+type demoFormSubmission struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       int64  `gorm:"not null;index:idx_demo_form_submissions_user_id"`
+	Name         string `gorm:"size:50;not null;default:''"`
+	Email        string `gorm:"size:100;not null;default:''"`
+	Data         string `gorm:"type:text;not null"`
+	Certificates string `gorm:"type:text;not null"`
+	CreatedAt    time.Time
+}
+
+func (demoFormSubmission) TableName() string { return "demo_form_submissions" }
+
+func init() {
+	register(
+		Migration{
+			// 表单页面（/admin/form）每次提交成功保存一行，user_id 为提交的管理员（goadmin_users.id）
+			// data 为其余字段的 JSON 对象，certificates 为上传的证书地址的 JSON 数组
+			Version: "0048",
+			Name:    "create_demo_form_submissions",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "demo_form_submissions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "name" text NOT NULL DEFAULT '',
+  "email" text NOT NULL DEFAULT '',
+  "data" text NOT NULL,
+  "certificates" text NOT NULL,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_demo_form_submissions_user_id" ON "demo_form_submissions"("user_id")`),
+				createTable(&demoFormSubmission{})),
+			Down: dropTable("demo_form_submissions"),
+		},
+	)
+}
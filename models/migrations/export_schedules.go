@@ -0,0 +1,85 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义定时导出的配置表 export_schedules 和执行记录表 export_runs
+package migrations
+
+import "time"
+
+// exportSchedule 0038 版本的 export_schedules 表结构
+type exportSchedule struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"size:100;not null;default:''"`
+	Prefix     string `gorm:"size:64;not null;default:''"`
+	Query      string `gorm:"size:1000;not null;default:''"`
+	Format     string `gorm:"size:10;not null;default:'csv'"`
+	Cron       string `gorm:"size:100;not null;default:''"`
+	Recipients string `gorm:"size:1000;not null;default:''"`
+	Enabled    bool   `gorm:"not null;default:true"`
+	CreatedBy  int64  `gorm:"not null;default:0"`
+	LastRunAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (exportSchedule) TableName() string { return "export_schedules" }
+
+// exportRun 0039 版本的 export_runs 表结构
+type exportRun struct {
+	ID         uint   `gorm:"primaryKey"`
+	ScheduleID uint   `gorm:"not null;default:0;index:idx_export_runs_schedule_id"`
+	Status     string `gorm:"size:16;not null;default:'running'"`
+	FileName   string `gorm:"size:255;not null;default:''"`
+	RowCount   int    `gorm:"not null;default:0"`
+	Recipients string `gorm:"size:1000;not null;default:''"`
+	Error      string `gorm:"size:1000;not null;default:''"`
+	Manual     bool   `gorm:"not null;default:false"`
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (exportRun) TableName() string { return "export_runs" }
+
+func init() {
+	register(
+		Migration{
+			// query 为列表页地址中 ? 之后的部分，导出时按它筛选和排序
+			// created_by 为创建定时导出的管理员（goadmin_users.id），导出时以该管理员的权限读取数据
+			Version: "0038",
+			Name:    "create_export_schedules",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "export_schedules" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "prefix" text NOT NULL DEFAULT '',
+  "query" text NOT NULL DEFAULT '',
+  "format" text NOT NULL DEFAULT 'csv',
+  "cron" text NOT NULL DEFAULT '',
+  "recipients" text NOT NULL DEFAULT '',
+  "enabled" integer NOT NULL DEFAULT 1,
+  "created_by" integer NOT NULL DEFAULT 0,
+  "last_run_at" datetime,
+  "created_at" datetime,
+  "updated_at" datetime
+)`), createTable(&exportSchedule{})),
+			Down: dropTable("export_schedules"),
+		},
+		Migration{
+			// 每次执行定时导出写入一条，manual 表示在列表中手动执行
+			Version: "0039",
+			Name:    "create_export_runs",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "export_runs" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "schedule_id" integer NOT NULL DEFAULT 0,
+  "status" text NOT NULL DEFAULT 'running',
+  "file_name" text NOT NULL DEFAULT '',
+  "row_count" integer NOT NULL DEFAULT 0,
+  "recipients" text NOT NULL DEFAULT '',
+  "error" text NOT NULL DEFAULT '',
+  "manual" integer NOT NULL DEFAULT 0,
+  "started_at" datetime,
+  "finished_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_export_runs_schedule_id" ON "export_runs"("schedule_id")`),
+				createTable(&exportRun{})),
+			Down: dropTable("export_runs"),
+		},
+	)
+}
@@ -0,0 +1,44 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义上传文件的元数据表 files
+package migrations
+
+import "time"
+
+// file 0020 版本的 files 表结构
+type file struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"size:255;not null;default:''"`
+	Path         string `gorm:"size:191;not null;uniqueIndex:idx_files_path"`
+	Size         int64  `gorm:"not null;default:0"`
+	MimeType     string `gorm:"size:100;not null;default:''"`
+	UploaderID   int64  `gorm:"not null;default:0"`
+	UploaderName string `gorm:"size:100;not null;default:''"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (file) TableName() string { return "files" }
+
+func init() {
+	register(
+		Migration{
+			// path 为文件在上传目录中的相对路径，同一个文件只登记一次
+			Version: "0020",
+			Name:    "create_files",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "files" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "path" text NOT NULL,
+  "size" integer NOT NULL DEFAULT 0,
+  "mime_type" text NOT NULL DEFAULT '',
+  "uploader_id" integer NOT NULL DEFAULT 0,
+  "uploader_name" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_files_path" ON "files"("path")`),
+				createTable(&file{})),
+			Down: dropTable("files"),
+		},
+	)
+}
@@ -0,0 +1,62 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义动态表单的定义表 form_definitions 和提交记录表 form_submissions
+package migrations
+
+import "time"
+
+// formDefinition 0043 版本的 form_definitions 表结构
+type formDefinition struct {
+	ID          uint   `gorm:"primaryKey"`
+	Title       string `gorm:"size:100;not null;default:''"`
+	Description string `gorm:"size:500;not null;default:''"`
+	Fields      string `gorm:"type:text;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (formDefinition) TableName() string { return "form_definitions" }
+
+// formSubmission 0044 版本的 form_submissions 表结构
+type formSubmission struct {
+	ID          uint   `gorm:"primaryKey"`
+	FormID      uint   `gorm:"not null;default:0;index:idx_form_submissions_form_id"`
+	SubmittedBy int64  `gorm:"not null;default:0"`
+	Data        string `gorm:"type:text;not null"`
+	CreatedAt   time.Time
+}
+
+func (formSubmission) TableName() string { return "form_submissions" }
+
+func init() {
+	register(
+		Migration{
+			// fields 为字段定义的 JSON 数组，每个字段包含字段名、标签、类型、选项、默认值和校验规则
+			Version: "0043",
+			Name:    "create_form_definitions",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "form_definitions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "title" text NOT NULL DEFAULT '',
+  "description" text NOT NULL DEFAULT '',
+  "fields" text NOT NULL DEFAULT '[]',
+  "created_at" datetime,
+  "updated_at" datetime
+)`), createTable(&formDefinition{})),
+			Down: dropTable("form_definitions"),
+		},
+		Migration{
+			// submitted_by 为提交表单的管理员（goadmin_users.id），data 为提交的字段值的 JSON 对象
+			Version: "0044",
+			Name:    "create_form_submissions",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "form_submissions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "form_id" integer NOT NULL DEFAULT 0,
+  "submitted_by" integer NOT NULL DEFAULT 0,
+  "data" text NOT NULL DEFAULT '{}',
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_form_submissions_form_id" ON "form_submissions"("form_id")`),
+				createTable(&formSubmission{})),
+			Down: dropTable("form_submissions"),
+		},
+	)
+}
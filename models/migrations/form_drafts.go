@@ -0,0 +1,38 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义保存表单草稿的 form_drafts 表
+package migrations
+
+import "time"
+
+// formDraft 0042 版本的 form_drafts 表结构
+type formDraft struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    int64  `gorm:"not null;uniqueIndex:idx_form_drafts_user_form"`
+	Form      string `gorm:"size:100;not null;uniqueIndex:idx_form_drafts_user_form"`
+	Data      string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (formDraft) TableName() string { return "form_drafts" }
+
+func init() {
+	register(
+		Migration{
+			// 每个管理员（goadmin_users.id）在每个表单上最多一份草稿，data 为表单字段值的 JSON
+			Version: "0042",
+			Name:    "create_form_drafts",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "form_drafts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "form" text NOT NULL,
+  "data" text NOT NULL,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_form_drafts_user_form" ON "form_drafts"("user_id", "form")`),
+				createTable(&formDraft{})),
+			Down: dropTable("form_drafts"),
+		},
+	)
+}
@@ -0,0 +1,55 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义记录表格导入历史的 import_logs 表
+package migrations
+
+import "time"
+
+// importLog 0037 版本的 import_logs 表结构
+type importLog struct {
+	ID         uint   `gorm:"primaryKey"`
+	Prefix     string `gorm:"size:64;not null;default:'';index:idx_import_logs_prefix"`
+	Table      string `gorm:"column:table_name;size:64;not null;default:''"`
+	FileName   string `gorm:"size:255;not null;default:''"`
+	Status     string `gorm:"size:16;not null;default:'running'"`
+	Total      int    `gorm:"not null;default:0"`
+	Imported   int    `gorm:"not null;default:0"`
+	Skipped    int    `gorm:"not null;default:0"`
+	Error      string `gorm:"size:1000;not null;default:''"`
+	AdminID    int64  `gorm:"not null;default:0"`
+	AdminName  string `gorm:"size:100;not null;default:''"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (importLog) TableName() string { return "import_logs" }
+
+func init() {
+	register(
+		Migration{
+			// prefix 为导入的表格前缀（/admin/info/{prefix}），导入历史按它筛选
+			// total 为校验通过、准备写入的行数，skipped 为校验未通过而跳过的行数
+			Version: "0037",
+			Name:    "create_import_logs",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "import_logs" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "prefix" text NOT NULL DEFAULT '',
+  "table_name" text NOT NULL DEFAULT '',
+  "file_name" text NOT NULL DEFAULT '',
+  "status" text NOT NULL DEFAULT 'running',
+  "total" integer NOT NULL DEFAULT 0,
+  "imported" integer NOT NULL DEFAULT 0,
+  "skipped" integer NOT NULL DEFAULT 0,
+  "error" text NOT NULL DEFAULT '',
+  "admin_id" integer NOT NULL DEFAULT 0,
+  "admin_name" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime,
+  "finished_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_import_logs_prefix" ON "import_logs"("prefix")`),
+				createTable(&importLog{})),
+			Down: dropTable("import_logs"),
+		},
+	)
+}
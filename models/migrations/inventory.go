@@ -0,0 +1,45 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 products 表增加安全库存字段 min_stock
+package migrations
+
+import "gorm.io/gorm"
+
+// productMinStock 0022 版本为 products 表增加的字段
+type productMinStock struct {
+	MinStock int `gorm:"not null;default:10"`
+}
+
+func (productMinStock) TableName() string { return "products" }
+
+func init() {
+	register(
+		Migration{
+			// 库存低于安全库存的商品在库存列表中高亮，并出现在补货提醒中
+			Version: "0022",
+			Name:    "add_products_min_stock",
+			Up: sqliteOr(exec(`ALTER TABLE "products" ADD COLUMN "min_stock" integer NOT NULL DEFAULT 10`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&productMinStock{}, "MinStock")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0018 版本一致
+			Down: sqliteOr(exec(`CREATE TABLE "products_old" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "price" real NOT NULL DEFAULT 0,
+  "stock" integer NOT NULL DEFAULT 0,
+  "category_id" integer NOT NULL DEFAULT 0,
+  "images" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`INSERT INTO "products_old" ("id", "name", "price", "stock", "category_id", "images", "created_at", "updated_at")
+SELECT "id", "name", "price", "stock", "category_id", "images", "created_at", "updated_at" FROM "products"`,
+				`DROP TABLE "products"`,
+				`ALTER TABLE "products_old" RENAME TO "products"`,
+				`CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products"("category_id")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&productMinStock{}, "MinStock")
+				}),
+		},
+	)
+}
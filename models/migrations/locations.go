@@ -0,0 +1,38 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义地点表 locations
+package migrations
+
+import "time"
+
+// location 0046 版本的 locations 表结构
+type location struct {
+	ID        uint    `gorm:"primaryKey"`
+	Name      string  `gorm:"size:100;not null;default:''"`
+	Address   string  `gorm:"size:255;not null;default:''"`
+	Latitude  float64 `gorm:"not null;default:0"`
+	Longitude float64 `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (location) TableName() string { return "locations" }
+
+func init() {
+	register(
+		Migration{
+			// latitude / longitude 为 WGS84 坐标（与 OpenStreetMap 相同），单位为度
+			Version: "0046",
+			Name:    "create_locations",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "locations" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "address" text NOT NULL DEFAULT '',
+  "latitude" real NOT NULL DEFAULT 0,
+  "longitude" real NOT NULL DEFAULT 0,
+  "created_at" datetime,
+  "updated_at" datetime
+)`), createTable(&location{})),
+			Down: dropTable("locations"),
+		},
+	)
+}
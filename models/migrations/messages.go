@@ -0,0 +1,44 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义管理员之间的站内信表 messages
+package migrations
+
+import "time"
+
+// message 0025 版本的 messages 表结构
+type message struct {
+	ID          uint   `gorm:"primaryKey"`
+	SenderID    int64  `gorm:"not null;default:0"`
+	SenderName  string `gorm:"size:100;not null;default:''"`
+	RecipientID int64  `gorm:"not null;default:0;index:idx_messages_recipient_id"`
+	Subject     string `gorm:"size:255;not null;default:''"`
+	Content     string `gorm:"type:text"`
+	IsRead      bool   `gorm:"not null;default:false"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (message) TableName() string { return "messages" }
+
+func init() {
+	register(
+		Migration{
+			// 发件人名称在发送时写入，收件箱不需要关联 goadmin_users
+			Version: "0025",
+			Name:    "create_messages",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "messages" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "sender_id" integer NOT NULL DEFAULT 0,
+  "sender_name" text NOT NULL DEFAULT '',
+  "recipient_id" integer NOT NULL DEFAULT 0,
+  "subject" text NOT NULL DEFAULT '',
+  "content" text,
+  "is_read" integer NOT NULL DEFAULT 0,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_messages_recipient_id" ON "messages"("recipient_id")`),
+				createTable(&message{})),
+			Down: dropTable("messages"),
+		},
+	)
+}
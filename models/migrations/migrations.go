@@ -0,0 +1,240 @@
+// Package migrations 管理本项目数据表的版本化迁移
+//
+// 每个迁移有唯一的版本号和一对 Up/Down 函数，按版本号顺序执行，
+// 已执行的版本记录在 schema_migrations 表中。新建一个空的 SQLite 文件后执行全部迁移，
+// 即可得到与 admin.db 相同的业务表结构，不再依赖预先生成的数据库文件。
+//
+// 使用示例:
+//
+//	go run . migrate up       # 执行所有未执行的迁移
+//	go run . migrate down     # 回滚最近一次迁移
+//	go run . migrate down 3   # 回滚最近三次迁移
+//	go run . migrate status   # 查看每个迁移是否已执行
+//
+// 注意事项:
+//   - goadmin_ 开头的框架表由 GoAdmin 管理，不在这里迁移
+//   - SQLite 使用原生 SQL，其他数据库（MySQL、PostgreSQL、SQL Server）按迁移中固定的表结构
+//     由 GORM 生成对应的建表语句；两者都不依赖 models 包中的模型，模型结构以后变化时不会改变已有迁移的结果，
+//     修改模型字段时需要新增一个迁移，不能修改已经发布的迁移
+//   - 表已存在时不会重复建表，已有的 admin.db 执行 up 时只会补记版本
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一个版本化迁移
+type Migration struct {
+	// Version 版本号，按字符串顺序执行，使用固定位数的数字，如 0001
+	Version string
+
+	// Name 迁移名称，说明迁移的内容，如 create_users
+	Name string
+
+	// Up 执行迁移，在事务中调用
+	Up func(tx *gorm.DB) error
+
+	// Down 回滚迁移，在事务中调用，应完全撤销 Up 的修改
+	Down func(tx *gorm.DB) error
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Migration
+
+	// Applied 是否已执行
+	Applied bool
+
+	// AppliedAt 执行时间，未执行时为零值
+	AppliedAt time.Time
+}
+
+// schemaMigration schema_migrations 表的一条记录
+type schemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:32"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定 schemaMigration 对应的数据库表名
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations 所有迁移，由各文件的 init 函数通过 register 添加
+var migrations []Migration
+
+// register 添加迁移，版本号重复时 panic
+func register(ms ...Migration) {
+	for _, m := range ms {
+		for _, existing := range migrations {
+			if existing.Version == m.Version {
+				panic("migrations: duplicate version " + m.Version)
+			}
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// All 返回所有迁移，按版本号升序排列
+func All() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// exec 返回依次执行多条 SQL 的迁移函数
+func exec(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, s := range statements {
+			if err := tx.Exec(s).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// sqliteOr 返回按数据库选择实现的迁移函数：SQLite 执行 sqlite，其他数据库执行 other
+// 已发布的迁移在 SQLite 上的结果不能改变，其他数据库的实现通过 other 补充
+func sqliteOr(sqlite, other func(tx *gorm.DB) error) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "sqlite" {
+			return sqlite(tx)
+		}
+		return other(tx)
+	}
+}
+
+// createTable 返回按模型建表的迁移函数，表已存在时跳过
+// model 必须是迁移中定义的固定结构体，不能使用 models 包中会继续变化的模型
+func createTable(model interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(model) {
+			return nil
+		}
+		return tx.Migrator().CreateTable(model)
+	}
+}
+
+// dropTable 返回删除数据表的迁移函数，表不存在时跳过
+func dropTable(name string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(name)
+	}
+}
+
+// ensureTable 创建 schema_migrations 表
+func ensureTable(ctx context.Context, db *gorm.DB) error {
+	return createTable(&schemaMigration{})(db.WithContext(ctx))
+}
+
+// applied 返回已执行的版本及其记录
+func applied(ctx context.Context, db *gorm.DB) (map[string]schemaMigration, error) {
+	if err := ensureTable(ctx, db); err != nil {
+		return nil, err
+	}
+	var records []schemaMigration
+	if err := db.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]schemaMigration, len(records))
+	for _, r := range records {
+		done[r.Version] = r
+	}
+	return done, nil
+}
+
+// Up 按版本号顺序执行所有未执行的迁移
+//
+// 参数:
+//   - ctx: 上下文
+//   - db: 数据库连接
+//
+// 返回值:
+//   - []Migration: 本次执行的迁移
+//   - error: 某个迁移失败时返回错误，该迁移已回滚，之前的迁移保持已执行
+func Up(ctx context.Context, db *gorm.DB) ([]Migration, error) {
+	done, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("执行迁移 %s_%s 失败: %v", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Down 按版本号倒序回滚最近执行的 steps 个迁移
+//
+// 参数:
+//   - ctx: 上下文
+//   - db: 数据库连接
+//   - steps: 回滚的迁移数，超过已执行的数量时回滚全部
+//
+// 返回值:
+//   - []Migration: 本次回滚的迁移，按回滚顺序排列
+//   - error: 某个迁移回滚失败时返回错误
+//
+// 注意事项:
+//   - 回滚建表迁移会删除表及其中的数据
+func Down(ctx context.Context, db *gorm.DB, steps int) ([]Migration, error) {
+	done, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolled []Migration
+	for i := len(migrations) - 1; i >= 0 && len(rolled) < steps; i-- {
+		m := migrations[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return rolled, fmt.Errorf("回滚迁移 %s_%s 失败: %v", m.Version, m.Name, err)
+		}
+		rolled = append(rolled, m)
+	}
+	return rolled, nil
+}
+
+// List 返回所有迁移及其执行状态，按版本号升序排列
+func List(ctx context.Context, db *gorm.DB) ([]Status, error) {
+	done, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		r, ok := done[m.Version]
+		list = append(list, Status{Migration: m, Applied: ok, AppliedAt: r.AppliedAt})
+	}
+	return list, nil
+}
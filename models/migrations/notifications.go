@@ -0,0 +1,40 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义站内通知表 notifications
+package migrations
+
+import "time"
+
+// notification 0024 版本的 notifications 表结构
+type notification struct {
+	ID        uint   `gorm:"primaryKey"`
+	AdminID   int64  `gorm:"not null;default:0;index:idx_notifications_admin_id"`
+	Title     string `gorm:"size:255;not null;default:''"`
+	Level     string `gorm:"size:20;not null;default:'info'"`
+	IsRead    bool   `gorm:"not null;default:false"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (notification) TableName() string { return "notifications" }
+
+func init() {
+	register(
+		Migration{
+			// admin_id 为接收通知的管理员（goadmin_users.id），顶部铃铛按它统计未读数
+			Version: "0024",
+			Name:    "create_notifications",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "notifications" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "admin_id" integer NOT NULL DEFAULT 0,
+  "title" text NOT NULL DEFAULT '',
+  "level" text NOT NULL DEFAULT 'info',
+  "is_read" integer NOT NULL DEFAULT 0,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_notifications_admin_id" ON "notifications"("admin_id")`),
+				createTable(&notification{})),
+			Down: dropTable("notifications"),
+		},
+	)
+}
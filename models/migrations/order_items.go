@@ -0,0 +1,53 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义订单明细表 order_items
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderItem 0017 版本的 order_items 表结构
+type orderItem struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrderID   uint   `gorm:"not null;index:idx_order_items_order_id"`
+	Product   string `gorm:"size:255"`
+	Quantity  int    `gorm:"not null;default:1"`
+	Price     float64
+	CreatedAt time.Time
+}
+
+func (orderItem) TableName() string { return "order_items" }
+
+// backfillOrderItems 为已有的每个订单生成一条明细，数量为 1，单价为订单金额
+// 只使用各数据库通用的 INSERT ... SELECT 语法
+const backfillOrderItems = `INSERT INTO order_items (order_id, product, quantity, price, created_at)
+SELECT id, product, 1, amount, created_at FROM orders`
+
+func init() {
+	register(
+		Migration{
+			// 订单详情按 order_id 列出明细，已有订单各补一条与订单金额一致的明细
+			Version: "0017",
+			Name:    "create_order_items",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "order_items" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "order_id" integer NOT NULL,
+  "product" text,
+  "quantity" integer NOT NULL DEFAULT 1,
+  "price" real,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_order_items_order_id" ON "order_items"("order_id")`,
+				backfillOrderItems),
+				func(tx *gorm.DB) error {
+					if err := createTable(&orderItem{})(tx); err != nil {
+						return err
+					}
+					return tx.Exec(backfillOrderItems).Error
+				}),
+			Down: dropTable("order_items"),
+		},
+	)
+}
@@ -0,0 +1,40 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义支付记录表 payments
+package migrations
+
+import "time"
+
+// payment 0023 版本的 payments 表结构
+type payment struct {
+	ID        uint    `gorm:"primaryKey"`
+	OrderID   uint    `gorm:"not null;default:0;index:idx_payments_order_id"`
+	Amount    float64 `gorm:"not null;default:0"`
+	Method    string  `gorm:"size:50;not null;default:''"`
+	Status    string  `gorm:"size:50;not null;default:'已授权'"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (payment) TableName() string { return "payments" }
+
+func init() {
+	register(
+		Migration{
+			// 新建的支付记录处于已授权状态，之后只能通过收款、退款、作废按钮改变状态
+			Version: "0023",
+			Name:    "create_payments",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "payments" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "order_id" integer NOT NULL DEFAULT 0,
+  "amount" real NOT NULL DEFAULT 0,
+  "method" text NOT NULL DEFAULT '',
+  "status" text NOT NULL DEFAULT '已授权',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_payments_order_id" ON "payments"("order_id")`),
+				createTable(&payment{})),
+			Down: dropTable("payments"),
+		},
+	)
+}
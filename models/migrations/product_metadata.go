@@ -0,0 +1,46 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 products 表增加扩展属性字段 metadata
+package migrations
+
+import "gorm.io/gorm"
+
+// productMetadata 0045 版本为 products 表增加的字段
+type productMetadata struct {
+	Metadata string `gorm:"type:text;not null;default:'{}'"`
+}
+
+func (productMetadata) TableName() string { return "products" }
+
+func init() {
+	register(
+		Migration{
+			// metadata 为商品扩展属性的 JSON 对象，如品牌、产地、重量和尺寸，格式见 tables.ProductMetadataSchema
+			Version: "0045",
+			Name:    "add_products_metadata",
+			Up: sqliteOr(exec(`ALTER TABLE "products" ADD COLUMN "metadata" text NOT NULL DEFAULT '{}'`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&productMetadata{}, "Metadata")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0022 版本一致
+			Down: sqliteOr(exec(`CREATE TABLE "products_old" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "price" real NOT NULL DEFAULT 0,
+  "stock" integer NOT NULL DEFAULT 0,
+  "category_id" integer NOT NULL DEFAULT 0,
+  "images" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime,
+  "min_stock" integer NOT NULL DEFAULT 10
+)`,
+				`INSERT INTO "products_old" ("id", "name", "price", "stock", "category_id", "images", "created_at", "updated_at", "min_stock")
+SELECT "id", "name", "price", "stock", "category_id", "images", "created_at", "updated_at", "min_stock" FROM "products"`,
+				`DROP TABLE "products"`,
+				`ALTER TABLE "products_old" RENAME TO "products"`,
+				`CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products"("category_id")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&productMetadata{}, "Metadata")
+				}),
+		},
+	)
+}
@@ -0,0 +1,75 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义商品表 products 和商品分类表 categories
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// category 0018 版本的 categories 表结构
+type category struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:100;not null;default:''"`
+	ParentID  uint   `gorm:"not null;default:0;index:idx_categories_parent_id"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (category) TableName() string { return "categories" }
+
+// product 0018 版本的 products 表结构
+type product struct {
+	ID         uint    `gorm:"primaryKey"`
+	Name       string  `gorm:"size:255;not null;default:''"`
+	Price      float64 `gorm:"not null;default:0"`
+	Stock      int     `gorm:"not null;default:0"`
+	CategoryID uint    `gorm:"not null;default:0;index:idx_products_category_id"`
+	Images     string  `gorm:"size:1000;not null;default:''"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (product) TableName() string { return "products" }
+
+func init() {
+	register(
+		Migration{
+			// parent_id 为 0 的是顶级分类
+			Version: "0018",
+			Name:    "create_products_and_categories",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "categories" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "parent_id" integer NOT NULL DEFAULT 0,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_categories_parent_id" ON "categories"("parent_id")`,
+				`CREATE TABLE IF NOT EXISTS "products" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "price" real NOT NULL DEFAULT 0,
+  "stock" integer NOT NULL DEFAULT 0,
+  "category_id" integer NOT NULL DEFAULT 0,
+  "images" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products"("category_id")`),
+				func(tx *gorm.DB) error {
+					if err := createTable(&category{})(tx); err != nil {
+						return err
+					}
+					return createTable(&product{})(tx)
+				}),
+			Down: func(tx *gorm.DB) error {
+				if err := dropTable("products")(tx); err != nil {
+					return err
+				}
+				return dropTable("categories")(tx)
+			},
+		},
+	)
+}
@@ -0,0 +1,86 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义行政区划表 regions，并写入表单页面省、市、区三级联动使用的示例数据
+package migrations
+
+import "gorm.io/gorm"
+
+// region 0049 版本的 regions 表结构
+// id 使用行政区划代码，不自增，示例数据中的上下级关系按代码固定
+type region struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	ParentID uint   `gorm:"not null;default:0;index:idx_regions_parent_id"`
+	Name     string `gorm:"size:50;not null;default:''"`
+	Level    int    `gorm:"not null;default:0"`
+}
+
+func (region) TableName() string { return "regions" }
+
+// regionSeed 示例数据：省（level 1）、市（level 2）、区（level 3），直辖市的市一级与省同名
+var regionSeed = []region{
+	{ID: 110000, Name: "北京市", Level: 1},
+	{ID: 110100, ParentID: 110000, Name: "北京市", Level: 2},
+	{ID: 110101, ParentID: 110100, Name: "东城区", Level: 3},
+	{ID: 110102, ParentID: 110100, Name: "西城区", Level: 3},
+	{ID: 110105, ParentID: 110100, Name: "朝阳区", Level: 3},
+	{ID: 110108, ParentID: 110100, Name: "海淀区", Level: 3},
+
+	{ID: 310000, Name: "上海市", Level: 1},
+	{ID: 310100, ParentID: 310000, Name: "上海市", Level: 2},
+	{ID: 310101, ParentID: 310100, Name: "黄浦区", Level: 3},
+	{ID: 310104, ParentID: 310100, Name: "徐汇区", Level: 3},
+	{ID: 310115, ParentID: 310100, Name: "浦东新区", Level: 3},
+
+	{ID: 440000, Name: "广东省", Level: 1},
+	{ID: 440100, ParentID: 440000, Name: "广州市", Level: 2},
+	{ID: 440104, ParentID: 440100, Name: "越秀区", Level: 3},
+	{ID: 440105, ParentID: 440100, Name: "海珠区", Level: 3},
+	{ID: 440106, ParentID: 440100, Name: "天河区", Level: 3},
+	{ID: 440300, ParentID: 440000, Name: "深圳市", Level: 2},
+	{ID: 440303, ParentID: 440300, Name: "罗湖区", Level: 3},
+	{ID: 440304, ParentID: 440300, Name: "福田区", Level: 3},
+	{ID: 440305, ParentID: 440300, Name: "南山区", Level: 3},
+	{ID: 440306, ParentID: 440300, Name: "宝安区", Level: 3},
+
+	{ID: 500000, Name: "重庆市", Level: 1},
+	{ID: 500100, ParentID: 500000, Name: "重庆市", Level: 2},
+	{ID: 500103, ParentID: 500100, Name: "渝中区", Level: 3},
+	{ID: 500105, ParentID: 500100, Name: "江北区", Level: 3},
+	{ID: 500106, ParentID: 500100, Name: "沙坪坝区", Level: 3},
+}
+
+// seedRegions 表中没有数据时写入 regionSeed，已有数据（如手工导入的完整区划）时跳过
+func seedRegions(tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&region{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(&regionSeed).Error
+}
+
+func init() {
+	register(
+		Migration{
+			// 表单页面的省、市、区按 parent_id 逐级加载，顶级（省）的 parent_id 为 0
+			Version: "0049",
+			Name:    "create_regions",
+			Up: func(tx *gorm.DB) error {
+				create := sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "regions" (
+  "id" integer PRIMARY KEY,
+  "parent_id" integer NOT NULL DEFAULT 0,
+  "name" text NOT NULL DEFAULT '',
+  "level" integer NOT NULL DEFAULT 0
+)`,
+					`CREATE INDEX IF NOT EXISTS "idx_regions_parent_id" ON "regions"("parent_id")`),
+					createTable(&region{}))
+				if err := create(tx); err != nil {
+					return err
+				}
+				return seedRegions(tx)
+			},
+			Down: dropTable("regions"),
+		},
+	)
+}
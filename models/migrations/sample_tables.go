@@ -0,0 +1,151 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义示例表格页面使用的数据表：users、authors、posts、profile、statistics
+// SQLite 的字段定义与 admin.db 中的表结构一致，其他数据库按下面的结构体建表
+package migrations
+
+import "time"
+
+// user 0001 版本的 users 表结构
+type user struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:50;not null;default:''"`
+	Gender    int
+	City      string `gorm:"size:50;not null;default:''"`
+	IP        string `gorm:"column:ip;size:20;not null;default:''"`
+	Phone     string `gorm:"size:100;not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (user) TableName() string { return "users" }
+
+// author 0002 版本的 authors 表结构
+type author struct {
+	ID        uint      `gorm:"primaryKey"`
+	FirstName string    `gorm:"size:50;not null;default:''"`
+	LastName  string    `gorm:"size:50;not null;default:''"`
+	Email     string    `gorm:"size:100;not null;default:''"`
+	Birthdate time.Time `gorm:"type:date;not null"`
+	Added     time.Time `gorm:"autoCreateTime"`
+}
+
+func (author) TableName() string { return "authors" }
+
+// post 0003 版本的 posts 表结构
+type post struct {
+	ID          uint      `gorm:"primaryKey"`
+	AuthorID    uint      `gorm:"not null"`
+	Title       string    `gorm:"size:255;not null;default:''"`
+	Description string    `gorm:"size:500;not null"`
+	Content     string    `gorm:"not null"`
+	Date        time.Time `gorm:"type:date;not null"`
+}
+
+func (post) TableName() string { return "posts" }
+
+// profile 0004 版本的 profile 表结构
+type profile struct {
+	ID             uint    `gorm:"primaryKey"`
+	UUID           *string `gorm:"column:uuid;size:100"`
+	Photos         *string `gorm:"size:3000"`
+	Resume         *string `gorm:"size:1000"`
+	ResumeSize     int     `gorm:"not null;default:0"`
+	FinishState    int     `gorm:"not null;default:0"`
+	FinishProgress int     `gorm:"not null;default:0"`
+	Pass           int     `gorm:"not null;default:0"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (profile) TableName() string { return "profile" }
+
+// statistics 0005 版本的 statistics 表结构
+type statistics struct {
+	ID         uint `gorm:"primaryKey"`
+	CPU        int  `gorm:"column:cpu"`
+	Likes      int
+	Sales      int
+	NewMembers int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (statistics) TableName() string { return "statistics" }
+
+func init() {
+	register(
+		Migration{
+			Version: "0001",
+			Name:    "create_users",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "users" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP
+)`), createTable(&user{})),
+			Down: dropTable("users"),
+		},
+		Migration{
+			Version: "0002",
+			Name:    "create_authors",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "authors" (
+  "id" integer PRIMARY KEY autoincrement,
+  "first_name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "last_name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "birthdate" DATE NOT NULL,
+  "added" TIMESTAMP default CURRENT_TIMESTAMP
+)`), createTable(&author{})),
+			Down: dropTable("authors"),
+		},
+		Migration{
+			// posts.author_id 对应 authors.id，原表没有外键约束，这里保持一致
+			Version: "0003",
+			Name:    "create_posts",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "posts" (
+  "id" integer PRIMARY KEY autoincrement,
+  "author_id" integer NOT NULL,
+  "title" CHAR(255) COLLATE NOCASE NOT NULL DEFAULT '',
+  "description" CHAR(500) COLLATE NOCASE NOT NULL,
+  "content" text COLLATE NOCASE NOT NULL,
+  "date" DATE NOT NULL
+)`), createTable(&post{})),
+			Down: dropTable("posts"),
+		},
+		Migration{
+			Version: "0004",
+			Name:    "create_profile",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "profile" (
+  "id" integer PRIMARY KEY autoincrement,
+  "uuid" CHAR(100) COLLATE NOCASE DEFAULT NULL,
+  "photos" CHAR(3000) COLLATE NOCASE DEFAULT NULL,
+  "resume" CHAR(1000) COLLATE NOCASE DEFAULT NULL,
+  "resume_size" INT NOT NULL DEFAULT '0',
+  "finish_state" INT NOT NULL DEFAULT '0',
+  "finish_progress" INT NOT NULL DEFAULT '0',
+  "pass" INT NOT NULL DEFAULT '0',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP
+)`), createTable(&profile{})),
+			Down: dropTable("profile"),
+		},
+		Migration{
+			Version: "0005",
+			Name:    "create_statistics",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "statistics" (
+  "id" integer PRIMARY KEY autoincrement,
+  "cpu" integer,
+  "likes" integer,
+  "sales" integer,
+  "new_members" integer,
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP
+)`), createTable(&statistics{})),
+			Down: dropTable("statistics"),
+		},
+	)
+}
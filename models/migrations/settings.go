@@ -0,0 +1,38 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义系统设置表 settings
+package migrations
+
+import "time"
+
+// setting 0026 版本的 settings 表结构
+type setting struct {
+	ID          uint   `gorm:"primaryKey"`
+	Key         string `gorm:"size:100;not null;uniqueIndex:idx_settings_key"`
+	Type        string `gorm:"size:20;not null;default:'text'"`
+	Value       string `gorm:"type:text"`
+	Description string `gorm:"size:255;not null;default:''"`
+	UpdatedAt   time.Time
+}
+
+func (setting) TableName() string { return "settings" }
+
+func init() {
+	register(
+		Migration{
+			// 设置项按 key 读取，type 决定管理后台编辑 value 时使用的控件
+			Version: "0026",
+			Name:    "create_settings",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "settings" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "key" text NOT NULL,
+  "type" text NOT NULL DEFAULT 'text',
+  "value" text,
+  "description" text NOT NULL DEFAULT '',
+  "updated_at" datetime
+)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_settings_key" ON "settings"("key")`),
+				createTable(&setting{})),
+			Down: dropTable("settings"),
+		},
+	)
+}
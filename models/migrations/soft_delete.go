@@ -0,0 +1,100 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 和 posts 表增加软删除使用的 deleted_at 字段
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// userSoftDelete 0013 版本为 users 表增加的字段
+type userSoftDelete struct {
+	DeletedAt *time.Time `gorm:"index:idx_users_deleted_at"`
+}
+
+func (userSoftDelete) TableName() string { return "users" }
+
+// postSoftDelete 0013 版本为 posts 表增加的字段
+type postSoftDelete struct {
+	DeletedAt *time.Time `gorm:"index:idx_posts_deleted_at"`
+}
+
+func (postSoftDelete) TableName() string { return "posts" }
+
+// addDeletedAt 返回增加 deleted_at 字段及其索引的迁移函数
+func addDeletedAt(model interface{}, index string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.AddColumn(model, "DeletedAt"); err != nil {
+			return err
+		}
+		return m.CreateIndex(model, index)
+	}
+}
+
+// dropDeletedAt 返回删除 deleted_at 字段及其索引的迁移函数
+func dropDeletedAt(model interface{}, index string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropIndex(model, index); err != nil {
+			return err
+		}
+		return m.DropColumn(model, "DeletedAt")
+	}
+}
+
+func init() {
+	register(
+		Migration{
+			// deleted_at 为 NULL 表示未删除，管理后台的删除操作只写入删除时间，可以从回收站恢复
+			Version: "0013",
+			Name:    "soft_delete_users_posts",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "deleted_at" TIMESTAMP`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`,
+				`ALTER TABLE "posts" ADD COLUMN "deleted_at" TIMESTAMP`,
+				`CREATE INDEX IF NOT EXISTS "idx_posts_deleted_at" ON "posts"("deleted_at")`),
+				func(tx *gorm.DB) error {
+					if err := addDeletedAt(&userSoftDelete{}, "idx_users_deleted_at")(tx); err != nil {
+						return err
+					}
+					return addDeletedAt(&postSoftDelete{}, "idx_posts_deleted_at")(tx)
+				}),
+			// 与 0012 相同，SQLite 通过重建表删除字段；回滚时已软删除的记录会重新出现在列表中
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`DROP INDEX IF EXISTS "idx_posts_deleted_at"`,
+				`CREATE TABLE "posts_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "author_id" integer NOT NULL,
+  "title" CHAR(255) COLLATE NOCASE NOT NULL DEFAULT '',
+  "description" CHAR(500) COLLATE NOCASE NOT NULL,
+  "content" text COLLATE NOCASE NOT NULL,
+  "date" DATE NOT NULL
+)`,
+				`INSERT INTO "posts_old" ("id", "author_id", "title", "description", "content", "date")
+SELECT "id", "author_id", "title", "description", "content", "date" FROM "posts"`,
+				`DROP TABLE "posts"`,
+				`ALTER TABLE "posts_old" RENAME TO "posts"`),
+				func(tx *gorm.DB) error {
+					if err := dropDeletedAt(&postSoftDelete{}, "idx_posts_deleted_at")(tx); err != nil {
+						return err
+					}
+					return dropDeletedAt(&userSoftDelete{}, "idx_users_deleted_at")(tx)
+				}),
+		},
+	)
+}
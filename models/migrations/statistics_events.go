@@ -0,0 +1,33 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义其他服务推送的统计增量明细表 statistics_events
+package migrations
+
+import "time"
+
+// statisticsEvent 0016 版本的 statistics_events 表结构
+type statisticsEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	Metric    string    `gorm:"size:32"`
+	Value     float64   `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"index:idx_statistics_events_created_at"`
+}
+
+func (statisticsEvent) TableName() string { return "statistics_events" }
+
+func init() {
+	register(
+		Migration{
+			// 汇总任务按 created_at 所在的小时把增量加到 statistics 表
+			Version: "0016",
+			Name:    "create_statistics_events",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "statistics_events" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "metric" text,
+  "value" real NOT NULL DEFAULT 0,
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_statistics_events_created_at" ON "statistics_events"("created_at")`), createTable(&statisticsEvent{})),
+			Down: dropTable("statistics_events"),
+		},
+	)
+}
@@ -0,0 +1,129 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件把 statistics 表改为按小时的时间序列：每小时一行，由 hour 字段唯一标识
+package migrations
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// statisticsHourly 0012 版本的 statistics 表结构
+type statisticsHourly struct {
+	ID         uint `gorm:"primaryKey"`
+	CPU        int  `gorm:"column:cpu"`
+	Likes      int
+	Sales      int
+	NewMembers int
+	Hour       *string `gorm:"size:19;uniqueIndex:idx_statistics_hour"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (statisticsHourly) TableName() string { return "statistics" }
+
+// mergeStatisticsHours 为已有的统计记录填写 hour，并把同一小时的多条记录合并为一条
+// 合并规则与 SQLite 的迁移语句一致：保留 id 最大的记录，销售额、点赞、新会员相加，CPU 取平均值
+func mergeStatisticsHours(tx *gorm.DB) error {
+	var rows []statisticsHourly
+	if err := tx.Order("id").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	groups := make(map[string][]statisticsHourly)
+	var hours []string
+	for _, r := range rows {
+		if r.CreatedAt.IsZero() {
+			continue
+		}
+		h := r.CreatedAt.In(time.Local).Format("2006-01-02 15:00:00")
+		if _, ok := groups[h]; !ok {
+			hours = append(hours, h)
+		}
+		groups[h] = append(groups[h], r)
+	}
+
+	for _, h := range hours {
+		group := groups[h]
+		keep := group[len(group)-1]
+		var sales, likes, members, cpu int
+		for _, r := range group {
+			sales += r.Sales
+			likes += r.Likes
+			members += r.NewMembers
+			cpu += r.CPU
+		}
+		err := tx.Model(&statisticsHourly{}).Where("id = ?", keep.ID).Updates(map[string]interface{}{
+			"hour":        h,
+			"sales":       sales,
+			"likes":       likes,
+			"new_members": members,
+			"cpu":         int(math.Round(float64(cpu) / float64(len(group)))),
+		}).Error
+		if err != nil {
+			return err
+		}
+		for _, r := range group[:len(group)-1] {
+			if err := tx.Delete(&statisticsHourly{}, r.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(
+		Migration{
+			// 已有的记录按 created_at 所在的小时归入 hour，同一小时的多条记录合并到 id 最大的一条：
+			// 销售额、点赞、新会员相加，CPU 取平均值
+			// 小时取 created_at 文本的前 13 位，与汇总任务一致，不做时区换算
+			Version: "0012",
+			Name:    "statistics_hourly",
+			Up: sqliteOr(exec(`ALTER TABLE "statistics" ADD COLUMN "hour" text`,
+				`UPDATE "statistics" SET "hour" = substr("created_at", 1, 13) || ':00:00' WHERE "created_at" IS NOT NULL`,
+				`UPDATE "statistics" SET
+  "sales" = (SELECT SUM(s."sales") FROM "statistics" s WHERE s."hour" = "statistics"."hour"),
+  "likes" = (SELECT SUM(s."likes") FROM "statistics" s WHERE s."hour" = "statistics"."hour"),
+  "new_members" = (SELECT SUM(s."new_members") FROM "statistics" s WHERE s."hour" = "statistics"."hour"),
+  "cpu" = (SELECT CAST(ROUND(AVG(s."cpu")) AS integer) FROM "statistics" s WHERE s."hour" = "statistics"."hour")
+WHERE "id" IN (SELECT MAX("id") FROM "statistics" WHERE "hour" IS NOT NULL GROUP BY "hour" HAVING COUNT(*) > 1)`,
+				`DELETE FROM "statistics" WHERE "hour" IS NOT NULL
+  AND "id" NOT IN (SELECT MAX("id") FROM "statistics" WHERE "hour" IS NOT NULL GROUP BY "hour")`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_statistics_hour" ON "statistics"("hour")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.AddColumn(&statisticsHourly{}, "Hour"); err != nil {
+						return err
+					}
+					if err := mergeStatisticsHours(tx); err != nil {
+						return err
+					}
+					return m.CreateIndex(&statisticsHourly{}, "idx_statistics_hour")
+				}),
+			// 内置的 SQLite 版本不支持 DROP COLUMN，回滚时重建表；合并掉的记录无法恢复
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_statistics_hour"`,
+				`CREATE TABLE "statistics_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "cpu" integer,
+  "likes" integer,
+  "sales" integer,
+  "new_members" integer,
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP
+)`,
+				`INSERT INTO "statistics_old" ("id", "cpu", "likes", "sales", "new_members", "created_at", "updated_at")
+SELECT "id", "cpu", "likes", "sales", "new_members", "created_at", "updated_at" FROM "statistics"`,
+				`DROP TABLE "statistics"`,
+				`ALTER TABLE "statistics_old" RENAME TO "statistics"`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.DropIndex(&statisticsHourly{}, "idx_statistics_hour"); err != nil {
+						return err
+					}
+					return m.DropColumn(&statisticsHourly{}, "Hour")
+				}),
+		},
+	)
+}
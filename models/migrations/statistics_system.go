@@ -0,0 +1,56 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 statistics 表增加系统资源采集任务写入的内存使用率和协程数
+package migrations
+
+import "gorm.io/gorm"
+
+// statisticsSystem 0015 版本为 statistics 表增加的字段
+type statisticsSystem struct {
+	Memory     int `gorm:"column:memory"`
+	Goroutines int `gorm:"column:goroutines"`
+}
+
+func (statisticsSystem) TableName() string { return "statistics" }
+
+func init() {
+	register(
+		Migration{
+			// 与 cpu 一样按小时保存采样的平均值，已有的小时为 0
+			Version: "0015",
+			Name:    "statistics_system_metrics",
+			Up: sqliteOr(exec(`ALTER TABLE "statistics" ADD COLUMN "memory" integer NOT NULL DEFAULT 0`,
+				`ALTER TABLE "statistics" ADD COLUMN "goroutines" integer NOT NULL DEFAULT 0`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.AddColumn(&statisticsSystem{}, "Memory"); err != nil {
+						return err
+					}
+					return m.AddColumn(&statisticsSystem{}, "Goroutines")
+				}),
+			// SQLite 重建为 0012 版本的表结构
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_statistics_hour"`,
+				`CREATE TABLE "statistics_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "cpu" integer,
+  "likes" integer,
+  "sales" integer,
+  "new_members" integer,
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "hour" text
+)`,
+				`INSERT INTO "statistics_old" ("id", "cpu", "likes", "sales", "new_members", "created_at", "updated_at", "hour")
+SELECT "id", "cpu", "likes", "sales", "new_members", "created_at", "updated_at", "hour" FROM "statistics"`,
+				`DROP TABLE "statistics"`,
+				`ALTER TABLE "statistics_old" RENAME TO "statistics"`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_statistics_hour" ON "statistics"("hour")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.DropColumn(&statisticsSystem{}, "Goroutines"); err != nil {
+						return err
+					}
+					return m.DropColumn(&statisticsSystem{}, "Memory")
+				}),
+		},
+	)
+}
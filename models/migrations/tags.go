@@ -0,0 +1,63 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义标签表 tags 和文章与标签的关联表 post_tags
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tag 0019 版本的 tags 表结构
+type tag struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:100;not null;default:''"`
+	Color     string `gorm:"size:20;not null;default:''"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (tag) TableName() string { return "tags" }
+
+// postTag 0019 版本的 post_tags 表结构
+type postTag struct {
+	PostID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID  uint `gorm:"primaryKey;autoIncrement:false;index:idx_post_tags_tag_id"`
+}
+
+func (postTag) TableName() string { return "post_tags" }
+
+func init() {
+	register(
+		Migration{
+			// 一篇文章的同一个标签只记录一次，按标签查文章时使用 tag_id 索引
+			Version: "0019",
+			Name:    "create_tags",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "tags" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" text NOT NULL DEFAULT '',
+  "color" text NOT NULL DEFAULT '',
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE TABLE IF NOT EXISTS "post_tags" (
+  "post_id" integer NOT NULL,
+  "tag_id" integer NOT NULL,
+  PRIMARY KEY ("post_id", "tag_id")
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_post_tags_tag_id" ON "post_tags"("tag_id")`),
+				func(tx *gorm.DB) error {
+					if err := createTable(&tag{})(tx); err != nil {
+						return err
+					}
+					return createTable(&postTag{})(tx)
+				}),
+			Down: func(tx *gorm.DB) error {
+				if err := dropTable("post_tags")(tx); err != nil {
+					return err
+				}
+				return dropTable("tags")(tx)
+			},
+		},
+	)
+}
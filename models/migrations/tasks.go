@@ -0,0 +1,41 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义任务表 tasks，看板页面按状态分列显示
+package migrations
+
+import "time"
+
+// task 0040 版本的 tasks 表结构
+type task struct {
+	ID         uint   `gorm:"primaryKey"`
+	Title      string `gorm:"size:255;not null;default:''"`
+	AssigneeID int64  `gorm:"not null;default:0"`
+	Status     string `gorm:"size:20;not null;default:'todo';index:idx_tasks_status"`
+	Priority   int    `gorm:"not null;default:2"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (task) TableName() string { return "tasks" }
+
+func init() {
+	register(
+		Migration{
+			// assignee_id 为负责人（goadmin_users.id），0 表示未分配
+			// priority 为 1（低）、2（中）、3（高），看板中同一列按优先级从高到低排列
+			Version: "0040",
+			Name:    "create_tasks",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "tasks" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "title" text NOT NULL DEFAULT '',
+  "assignee_id" integer NOT NULL DEFAULT 0,
+  "status" text NOT NULL DEFAULT 'todo',
+  "priority" integer NOT NULL DEFAULT 2,
+  "created_at" datetime,
+  "updated_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_tasks_status" ON "tasks"("status")`),
+				createTable(&task{})),
+			Down: dropTable("tasks"),
+		},
+	)
+}
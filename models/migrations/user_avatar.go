@@ -0,0 +1,51 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 表增加头像字段 avatar
+package migrations
+
+import "gorm.io/gorm"
+
+// userAvatar 0029 版本为 users 表增加的字段
+type userAvatar struct {
+	Avatar string `gorm:"size:255;not null;default:''"`
+}
+
+func (userAvatar) TableName() string { return "users" }
+
+func init() {
+	register(
+		Migration{
+			// 保存头像缩略图的地址：本地存储为上传目录中的相对路径，S3 存储为完整的 URL，为空表示未上传头像
+			Version: "0029",
+			Name:    "add_users_avatar",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "avatar" VARCHAR(255) NOT NULL DEFAULT ''`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&userAvatar{}, "Avatar")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0028 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_email"`,
+				`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP,
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "password" CHAR(100) NOT NULL DEFAULT ''
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&userAvatar{}, "Avatar")
+				}),
+		},
+	)
+}
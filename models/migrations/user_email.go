@@ -0,0 +1,58 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 表增加邮箱字段 email
+package migrations
+
+import "gorm.io/gorm"
+
+// userEmail 0027 版本为 users 表增加的字段
+type userEmail struct {
+	Email string `gorm:"size:100;not null;default:'';index:idx_users_email"`
+}
+
+func (userEmail) TableName() string { return "users" }
+
+func init() {
+	register(
+		Migration{
+			// 已有用户的邮箱为空；非空邮箱不能重复，由用户表单的校验保证（见 models.ValidateUser），
+			// 空字符串允许重复，因此这里只建普通索引
+			Version: "0027",
+			Name:    "add_users_email",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT ''`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.AddColumn(&userEmail{}, "Email"); err != nil {
+						return err
+					}
+					return m.CreateIndex(&userEmail{}, "idx_users_email")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0013 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_email"`,
+				`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`),
+				func(tx *gorm.DB) error {
+					m := tx.Migrator()
+					if err := m.DropIndex(&userEmail{}, "idx_users_email"); err != nil {
+						return err
+					}
+					return m.DropColumn(&userEmail{}, "Email")
+				}),
+		},
+	)
+}
@@ -0,0 +1,50 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 表增加密码字段 password
+package migrations
+
+import "gorm.io/gorm"
+
+// userPassword 0028 版本为 users 表增加的字段
+type userPassword struct {
+	Password string `gorm:"size:100;not null;default:''"`
+}
+
+func (userPassword) TableName() string { return "users" }
+
+func init() {
+	register(
+		Migration{
+			// 保存 bcrypt 哈希（60 个字符），已有用户的密码为空，表示未设置密码
+			Version: "0028",
+			Name:    "add_users_password",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "password" CHAR(100) NOT NULL DEFAULT ''`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&userPassword{}, "Password")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0027 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_email"`,
+				`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP,
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT ''
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&userPassword{}, "Password")
+				}),
+		},
+	)
+}
@@ -0,0 +1,53 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 表建立 SQLite FTS5 全文索引，用于用户列表的搜索框
+package migrations
+
+import (
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// userSearchUp 创建 users_fts 全文索引及同步索引的触发器，并为已有用户建立索引
+//
+// 注意事项:
+//   - mattn/go-sqlite3 需要以 -tags sqlite_fts5 编译才包含 FTS5，没有时跳过建立索引，
+//     搜索使用 LIKE 查询；之后改用 sqlite_fts5 编译需要回滚并重新执行本迁移
+//   - 其他数据库不建立索引，同样使用 LIKE 查询
+func userSearchUp(tx *gorm.DB) error {
+	err := tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS "users_fts" USING fts5(name, phone, city, content='users', content_rowid='id')`).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			log.Println("SQLite 不支持 FTS5（需要以 -tags sqlite_fts5 编译），用户搜索使用 LIKE 查询")
+			return nil
+		}
+		return err
+	}
+	return exec(`CREATE TRIGGER IF NOT EXISTS "users_fts_ai" AFTER INSERT ON "users" BEGIN
+  INSERT INTO "users_fts"(rowid, name, phone, city) VALUES (new.id, new.name, new.phone, new.city);
+END`,
+		`CREATE TRIGGER IF NOT EXISTS "users_fts_ad" AFTER DELETE ON "users" BEGIN
+  INSERT INTO "users_fts"("users_fts", rowid, name, phone, city) VALUES ('delete', old.id, old.name, old.phone, old.city);
+END`,
+		`CREATE TRIGGER IF NOT EXISTS "users_fts_au" AFTER UPDATE OF name, phone, city ON "users" BEGIN
+  INSERT INTO "users_fts"("users_fts", rowid, name, phone, city) VALUES ('delete', old.id, old.name, old.phone, old.city);
+  INSERT INTO "users_fts"(rowid, name, phone, city) VALUES (new.id, new.name, new.phone, new.city);
+END`,
+		`INSERT INTO "users_fts"("users_fts") VALUES ('rebuild')`)(tx)
+}
+
+func init() {
+	register(
+		Migration{
+			// 外部内容表：索引只保存分词结果，内容从 users 表读取，rowid 即 users.id
+			Version: "0033",
+			Name:    "create_users_fts",
+			Up:      sqliteOr(userSearchUp, exec()),
+			Down: sqliteOr(exec(`DROP TRIGGER IF EXISTS "users_fts_au"`,
+				`DROP TRIGGER IF EXISTS "users_fts_ad"`,
+				`DROP TRIGGER IF EXISTS "users_fts_ai"`,
+				`DROP TABLE IF EXISTS "users_fts"`), exec()),
+		},
+	)
+}
@@ -0,0 +1,55 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件为 users 表增加启用状态字段 status
+package migrations
+
+import "gorm.io/gorm"
+
+// userStatus 0031 版本为 users 表增加的字段
+type userStatus struct {
+	Status int `gorm:"not null;default:1"`
+}
+
+func (userStatus) TableName() string { return "users" }
+
+func init() {
+	register(
+		Migration{
+			// 1 为启用，0 为禁用，已有用户默认启用；用户列表可以勾选多个用户批量启用或禁用
+			Version: "0031",
+			Name:    "add_users_status",
+			Up: sqliteOr(exec(`ALTER TABLE "users" ADD COLUMN "status" integer NOT NULL DEFAULT 1`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().AddColumn(&userStatus{}, "Status")
+				}),
+			// SQLite 通过重建表删除字段，表结构与 0030 版本一致
+			Down: sqliteOr(exec(`DROP INDEX IF EXISTS "idx_users_created_by"`,
+				`DROP INDEX IF EXISTS "idx_users_email"`,
+				`DROP INDEX IF EXISTS "idx_users_deleted_at"`,
+				`CREATE TABLE "users_old" (
+  "id" integer PRIMARY KEY autoincrement,
+  "name" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "gender" integer,
+  "city" CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+  "ip" CHAR(20) COLLATE NOCASE NOT NULL DEFAULT '',
+  "phone" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP default CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP,
+  "email" CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+  "password" CHAR(100) NOT NULL DEFAULT '',
+  "avatar" VARCHAR(255) NOT NULL DEFAULT '',
+  "created_by" integer NOT NULL DEFAULT 0
+)`,
+				`INSERT INTO "users_old" ("id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password", "avatar", "created_by")
+SELECT "id", "name", "gender", "city", "ip", "phone", "created_at", "updated_at", "deleted_at", "email", "password", "avatar", "created_by" FROM "users"`,
+				`DROP TABLE "users"`,
+				`ALTER TABLE "users_old" RENAME TO "users"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users"("deleted_at")`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_created_by" ON "users"("created_by")`),
+				func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&userStatus{}, "Status")
+				}),
+		},
+	)
+}
@@ -0,0 +1,45 @@
+// Package migrations 管理本项目数据表的版本化迁移
+// 本文件定义管理员工作经历表 work_experiences
+package migrations
+
+import "time"
+
+// workExperience 0047 版本的 work_experiences 表结构
+type workExperience struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      int64  `gorm:"not null;index:idx_work_experiences_user_id"`
+	SortOrder   int    `gorm:"not null;default:0"`
+	Company     string `gorm:"size:100;not null;default:''"`
+	Title       string `gorm:"size:100;not null;default:''"`
+	StartDate   string `gorm:"size:10;not null;default:''"`
+	EndDate     string `gorm:"size:10;not null;default:''"`
+	Description string `gorm:"size:500;not null;default:''"`
+	CreatedAt   time.Time
+}
+
+func (workExperience) TableName() string { return "work_experiences" }
+
+func init() {
+	register(
+		Migration{
+			// 表单页面中“工作经历”的每一段对应一行，user_id 为管理员（goadmin_users.id），sort_order 为表单中的顺序
+			// 日期以 YYYY-MM-DD 保存，end_date 为空表示至今
+			Version: "0047",
+			Name:    "create_work_experiences",
+			Up: sqliteOr(exec(`CREATE TABLE IF NOT EXISTS "work_experiences" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "user_id" integer NOT NULL,
+  "sort_order" integer NOT NULL DEFAULT 0,
+  "company" text NOT NULL DEFAULT '',
+  "title" text NOT NULL DEFAULT '',
+  "start_date" text NOT NULL DEFAULT '',
+  "end_date" text NOT NULL DEFAULT '',
+  "description" text NOT NULL DEFAULT '',
+  "created_at" datetime
+)`,
+				`CREATE INDEX IF NOT EXISTS "idx_work_experiences_user_id" ON "work_experiences"("user_id")`),
+				createTable(&workExperience{})),
+			Down: dropTable("work_experiences"),
+		},
+	)
+}
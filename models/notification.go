@@ -0,0 +1,168 @@
+// models 包 - 数据模型层
+// 本文件定义站内通知模型
+// 每条通知发给一个管理员，顶部导航栏的铃铛定时读取当前管理员的未读数，
+// 通知中心（pages.NotificationsPage）中可以把自己的通知逐条或全部标为已读、删除，
+// 通知列表（tables.GetNotificationsTable）用于给管理员发送通知
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 通知级别，与 notifications 表 level 字段中保存的值一致，也是列表中标签的颜色
+const (
+	// NotificationInfo 一般消息
+	NotificationInfo = "info"
+
+	// NotificationSuccess 操作成功
+	NotificationSuccess = "success"
+
+	// NotificationWarning 需要留意
+	NotificationWarning = "warning"
+
+	// NotificationDanger 需要立即处理
+	NotificationDanger = "danger"
+)
+
+// Notification 站内通知模型
+type Notification struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// AdminID 接收通知的管理员 ID（goadmin_users.id）
+	AdminID int64 `gorm:"column:admin_id"`
+
+	// Title 通知标题
+	Title string `gorm:"column:title"`
+
+	// Level 通知级别，见 NotificationInfo 等常量
+	Level string `gorm:"column:level"`
+
+	// IsRead 是否已读
+	IsRead bool `gorm:"column:is_read"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Notification 对应的数据库表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Notify 给管理员发送一条通知
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行，事务回滚时通知也不会发出
+//   - adminID: 接收通知的管理员 ID
+//   - title: 通知标题
+//   - level: 通知级别，为空时使用 NotificationInfo
+//
+// 使用示例:
+//
+//	err := models.Notify(ctx, 1, "库存不足的商品有 3 件", models.NotificationWarning)
+func Notify(ctx context.Context, adminID int64, title, level string) error {
+	if level == "" {
+		level = NotificationInfo
+	}
+	return writer(ctx).Create(&Notification{AdminID: adminID, Title: title, Level: level}).Error
+}
+
+// UnreadNotificationCount 返回管理员的未读通知数，查询失败时返回 0
+// 从主库读取，标为已读后铃铛上的数字立即更新
+func UnreadNotificationCount(ctx context.Context, adminID int64) int64 {
+	var n int64
+	orm.WithContext(ctx).Model(&Notification{}).
+		Where("admin_id = ? AND is_read = ?", adminID, false).
+		Count(&n)
+	return n
+}
+
+// MarkAllNotificationsRead 将管理员的全部未读通知标为已读
+//
+// 返回值:
+//   - int64: 本次标为已读的通知数
+//   - error: 写入失败时返回数据库错误
+func MarkAllNotificationsRead(ctx context.Context, adminID int64) (int64, error) {
+	res := writer(ctx).Model(&Notification{}).
+		Where("admin_id = ? AND is_read = ?", adminID, false).
+		Updates(map[string]interface{}{"is_read": true, "updated_at": time.Now()})
+	return res.RowsAffected, res.Error
+}
+
+// NotificationsPageSize 通知中心每页显示的通知数
+const NotificationsPageSize = 20
+
+// ListNotifications 按创建时间从新到旧分页读取管理员的通知
+//
+// 参数:
+//   - ctx: 上下文
+//   - adminID: 管理员 ID，只返回该管理员的通知
+//   - unreadOnly: 为 true 时只返回未读通知
+//   - page: 页码，从 1 开始，小于 1 时按 1 处理
+//
+// 返回值:
+//   - []Notification: 当前页的通知，每页 NotificationsPageSize 条
+//   - int64: 符合条件的通知总数
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 与未读数一样从主库读取，标为已读或删除后刷新列表立即看到结果
+func ListNotifications(ctx context.Context, adminID int64, unreadOnly bool, page int) ([]Notification, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	q := orm.WithContext(ctx).Model(&Notification{}).Where("admin_id = ?", adminID)
+	if unreadOnly {
+		q = q.Where("is_read = ?", false)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Notification
+	err := q.Order("created_at DESC, id DESC").
+		Offset((page - 1) * NotificationsPageSize).Limit(NotificationsPageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// MarkNotificationRead 将管理员的一条通知标为已读，已读的通知再次标记不会报错
+//
+// 返回值:
+//   - error: 通知不存在或不属于该管理员时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+func MarkNotificationRead(ctx context.Context, adminID int64, id uint) error {
+	res := writer(ctx).Model(&Notification{}).
+		Where("id = ? AND admin_id = ?", id, adminID).
+		Updates(map[string]interface{}{"is_read": true, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteNotification 删除管理员的一条通知
+//
+// 返回值:
+//   - error: 通知不存在或不属于该管理员时返回 gorm.ErrRecordNotFound，删除失败时返回数据库错误
+func DeleteNotification(ctx context.Context, adminID int64, id uint) error {
+	res := writer(ctx).Where("id = ? AND admin_id = ?", id, adminID).Delete(&Notification{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
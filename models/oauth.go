@@ -0,0 +1,259 @@
+// models 包 - 数据模型层
+// 本文件实现第三方账号登录的账号关联：第三方账号按平台的用户 ID 关联管理员，第一次登录时按邮箱关联已有管理员，
+// 没有对应的管理员时可以自动创建管理员并分配默认角色
+
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ErrOAuthNoAdmin 第三方账号没有关联的管理员，且没有开启自动创建或邮箱不在允许的域名中
+var ErrOAuthNoAdmin = errors.New("该账号没有对应的管理员，请先使用密码登录并在个人资料中填写相同的邮箱，或联系系统管理员")
+
+// ErrOAuthProvision 自动创建管理员失败：默认角色不存在或登录名已被占用
+// 返回的错误包含具体原因，可以直接展示给登录的用户
+var ErrOAuthProvision = errors.New("无法自动创建管理员")
+
+// OAuthAccount 第三方账号关联模型
+// 该结构体映射到 admin_oauth_accounts 表，同一个第三方账号只能关联一个管理员
+type OAuthAccount struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id
+	UserID int64 `gorm:"column:user_id"`
+
+	// Provider 第三方平台，如 github、google、gitee
+	Provider string `gorm:"column:provider"`
+
+	// Subject 第三方平台的用户 ID
+	Subject string `gorm:"column:subject"`
+
+	// Email 最近一次登录时第三方平台返回的邮箱
+	Email string `gorm:"column:email"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 OAuthAccount 对应的数据库表名
+func (OAuthAccount) TableName() string {
+	return "admin_oauth_accounts"
+}
+
+// OAuthIdentity 第三方平台返回的用户信息
+type OAuthIdentity struct {
+	// Provider 第三方平台，如 github
+	Provider string
+
+	// Subject 第三方平台的用户 ID
+	Subject string
+
+	// Email 已验证的邮箱，平台没有返回已验证的邮箱时为空，此时只能使用已经关联的账号登录
+	Email string
+
+	// Name 显示名称，自动创建管理员时使用，为空时使用邮箱
+	Name string
+
+	// Avatar 头像的完整地址，自动创建管理员时使用
+	Avatar string
+}
+
+// OAuthProvisioning 自动创建管理员的设置，对应 config.yml 的 oauth 配置项
+type OAuthProvisioning struct {
+	// Enabled 没有对应的管理员时是否自动创建
+	Enabled bool
+
+	// Role 自动创建的管理员的角色，goadmin_roles 表的 slug
+	Role string
+
+	// Domains 只为这些域名的邮箱自动创建管理员，为空表示不限制
+	Domains []string
+}
+
+// allows 判断是否可以为该邮箱自动创建管理员
+func (p OAuthProvisioning) allows(email string) bool {
+	if !p.Enabled {
+		return false
+	}
+	if len(p.Domains) == 0 {
+		return true
+	}
+	email = strings.ToLower(email)
+	for _, d := range p.Domains {
+		if strings.HasSuffix(email, "@"+strings.ToLower(strings.TrimPrefix(d, "@"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// oauthAdmin 自动创建管理员时写入 goadmin_users 表的字段
+type oauthAdmin struct {
+	ID        int64  `gorm:"column:id;primaryKey"`
+	Username  string `gorm:"column:username"`
+	Password  string `gorm:"column:password"`
+	Name      string `gorm:"column:name"`
+	Avatar    string `gorm:"column:avatar"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (oauthAdmin) TableName() string { return "goadmin_users" }
+
+// OAuthLogin 返回第三方账号对应的管理员，必要时关联或创建管理员
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - id: 第三方平台返回的用户信息
+//   - prov: 自动创建管理员的设置
+//
+// 返回值:
+//   - int64: 管理员ID
+//   - bool: 是否新创建了管理员
+//   - error: 没有对应的管理员时返回 ErrOAuthNoAdmin，默认角色不存在或登录名冲突时返回包装了 ErrOAuthProvision 的错误
+//
+// 查找顺序:
+//  1. 已关联的第三方账号，更新最近一次登录的邮箱
+//  2. 个人资料中邮箱与第三方账号已验证的邮箱相同（不区分大小写）的管理员，关联后登录
+//  3. 开启自动创建且邮箱在允许的域名中时，以邮箱为登录名创建管理员，分配默认角色并关联
+//
+// 注意事项:
+//   - 在一个事务中执行，创建管理员的各步骤要么全部完成，要么全部撤销
+//   - 自动创建的管理员的密码是随机生成的，只能使用第三方账号登录，超级管理员可以在管理员列表中重设密码
+//   - 关联的管理员已被删除时，删除失效的关联后按邮箱重新查找
+func OAuthLogin(ctx context.Context, id OAuthIdentity, prov OAuthProvisioning) (int64, bool, error) {
+	var (
+		userID  int64
+		created bool
+	)
+	err := Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		var acc OAuthAccount
+		err := tx.Where("provider = ? AND subject = ?", id.Provider, id.Subject).Take(&acc).Error
+		switch {
+		case err == nil:
+			ok, err := adminExists(tx, acc.UserID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				userID = acc.UserID
+				if id.Email == "" || id.Email == acc.Email {
+					return nil
+				}
+				return tx.Model(&acc).Update("email", id.Email).Error
+			}
+			if err := tx.Delete(&acc).Error; err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if id.Email == "" {
+			return ErrOAuthNoAdmin
+		}
+		var pref AdminPreference
+		err = tx.Where("LOWER(email) = ?", strings.ToLower(id.Email)).Take(&pref).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			ok, err := adminExists(tx, pref.UserID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				userID = pref.UserID
+				return tx.Create(&OAuthAccount{UserID: userID, Provider: id.Provider, Subject: id.Subject, Email: id.Email}).Error
+			}
+		}
+
+		if !prov.allows(id.Email) {
+			return ErrOAuthNoAdmin
+		}
+		uid, err := createOAuthAdmin(tx, id, prov.Role)
+		if err != nil {
+			return err
+		}
+		userID, created = uid, true
+		return tx.Create(&OAuthAccount{UserID: userID, Provider: id.Provider, Subject: id.Subject, Email: id.Email}).Error
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return userID, created, nil
+}
+
+// adminExists 判断管理员是否存在
+func adminExists(tx *gorm.DB, id int64) (bool, error) {
+	var count int64
+	if err := tx.Table("goadmin_users").Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// createOAuthAdmin 为第三方账号创建管理员：写入 goadmin_users、goadmin_role_users 和保存邮箱的 admin_preferences
+func createOAuthAdmin(tx *gorm.DB, id OAuthIdentity, role string) (int64, error) {
+	var roleID int64
+	if err := tx.Table("goadmin_roles").Select("id").Where("slug = ?", role).Row().Scan(&roleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: 角色 %s 不存在，请检查 oauth.default_role 配置", ErrOAuthProvision, role)
+		}
+		return 0, err
+	}
+
+	var count int64
+	if err := tx.Table("goadmin_users").Where("username = ?", id.Email).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		return 0, fmt.Errorf("%w: 登录名 %s 已被其他管理员使用，请在该管理员的个人资料中填写相同的邮箱后再登录", ErrOAuthProvision, id.Email)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return 0, err
+	}
+	hash, err := HashPassword(hex.EncodeToString(secret))
+	if err != nil {
+		return 0, err
+	}
+
+	name := strings.TrimSpace(id.Name)
+	if name == "" {
+		name = id.Email
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		name = string([]rune(name)[:50])
+	}
+	now := time.Now()
+	admin := oauthAdmin{Username: id.Email, Password: hash, Name: name, Avatar: id.Avatar, CreatedAt: now, UpdatedAt: now}
+	if err := tx.Create(&admin).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Table("goadmin_role_users").Create(map[string]interface{}{
+		"role_id": roleID, "user_id": admin.ID, "created_at": now, "updated_at": now,
+	}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Create(&AdminPreference{UserID: admin.ID, Email: id.Email}).Error; err != nil {
+		return 0, err
+	}
+	return admin.ID, nil
+}
@@ -0,0 +1,117 @@
+// models 包 - 数据模型层
+// 本文件定义订单模型
+// orders 表由 Init 自动迁移创建，管理后台通过 tables.GetOrdersTable 查看和编辑
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Order 订单模型
+// 该结构体映射到 orders 表，仪表板的销售额信息框可以跳转到按时间筛选的订单列表
+type Order struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// OrderNo 订单号，如 OR9842
+	OrderNo string `gorm:"column:order_no;uniqueIndex"`
+
+	// Product 商品名称
+	Product string `gorm:"column:product"`
+
+	// Status 订单状态，如 已发货、待处理、已送达
+	Status string `gorm:"column:status"`
+
+	// Amount 订单金额
+	Amount float64 `gorm:"column:amount"`
+
+	// CustomerID 下单的客户，对应 customers.id，0 表示未关联客户
+	CustomerID uint `gorm:"column:customer_id"`
+
+	// CreatedAt 下单时间，由GORM自动填充，订单列表按该字段筛选时间范围
+	CreatedAt time.Time `gorm:"index"`
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Order 对应的数据库表名
+func (Order) TableName() string {
+	return "orders"
+}
+
+// RecentOrders 获取最新的订单
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - limit: 最多返回的订单数
+//
+// 返回值:
+//   - []Order: 按下单时间倒序排列的订单，查询失败时返回空列表
+func RecentOrders(ctx context.Context, limit int) []Order {
+	var orders []Order
+	reader(ctx).Order("created_at DESC").Limit(limit).Find(&orders)
+	return orders
+}
+
+// ProductRevenue 一个商品在统计区间内的销售情况
+type ProductRevenue struct {
+	// Product 商品名称
+	Product string
+
+	// Revenue 销售额，即区间内该商品订单金额之和
+	Revenue float64
+
+	// Orders 订单数
+	Orders int
+
+	// Share 占区间内全部商品销售额的百分比（0-100），查询后计算，不对应数据库字段
+	Share float64 `gorm:"-"`
+}
+
+// TopProductsByRevenue 获取指定时间区间内销售额最高的商品
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//   - limit: 最多返回的商品数
+//
+// 返回值:
+//   - []ProductRevenue: 按销售额降序排列，Share 按区间内所有商品（不只是前 limit 个）的销售额计算
+//
+// 注意事项:
+//   - 订单写入时不主动失效缓存，排行最多延迟 DefaultCacheTTL
+func TopProductsByRevenue(ctx context.Context, from, to time.Time, limit int) []ProductRevenue {
+	key := fmt.Sprintf("orders:top_products:%s:%s:%d", from.Format(statisticsTimeLayout), to.Format(statisticsTimeLayout), limit)
+	return remember(ctx, key, func() []ProductRevenue {
+		var (
+			products []ProductRevenue
+			total    struct{ Revenue float64 }
+		)
+
+		// Session 使两个查询可以复用同一个条件，而不会互相追加 SELECT 等子句
+		between := reader(ctx).Model(&Order{}).
+			Where("created_at BETWEEN ? AND ?", from.Format(statisticsTimeLayout), to.Format(statisticsTimeLayout)).
+			Session(&gorm.Session{})
+
+		between.Select("product, SUM(amount) AS revenue, COUNT(*) AS orders").
+			Group("product").
+			Order("revenue DESC").
+			Limit(limit).
+			Scan(&products)
+		between.Select("SUM(amount) AS revenue").Scan(&total)
+
+		for i := range products {
+			if total.Revenue > 0 {
+				products[i].Share = products[i].Revenue / total.Revenue * 100
+			}
+		}
+		return products
+	})
+}
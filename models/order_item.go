@@ -0,0 +1,117 @@
+// models 包 - 数据模型层
+// 本文件定义订单明细模型和订单状态的流转
+// 订单按 待处理 → 已发货 → 已送达 的顺序流转，管理后台通过订单表格每行的按钮推进状态
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 订单状态，与 orders 表 status 字段中保存的值一致
+const (
+	// OrderPending 待处理
+	OrderPending = "待处理"
+
+	// OrderShipped 已发货
+	OrderShipped = "已发货"
+
+	// OrderDone 已送达
+	OrderDone = "已送达"
+)
+
+// orderTransitions 每个状态可以从哪个状态流转而来
+var orderTransitions = map[string]string{
+	OrderShipped: OrderPending,
+	OrderDone:    OrderShipped,
+}
+
+// ErrOrderTransition 订单的当前状态不能流转到目标状态
+var ErrOrderTransition = errors.New("订单状态不能流转")
+
+// OrderItem 订单明细模型
+// 该结构体映射到 order_items 表，一个订单有一条或多条明细
+type OrderItem struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// OrderID 所属订单的编号
+	OrderID uint `gorm:"column:order_id"`
+
+	// Product 商品名称
+	Product string `gorm:"column:product"`
+
+	// Quantity 数量
+	Quantity int `gorm:"column:quantity"`
+
+	// Price 单价
+	Price float64 `gorm:"column:price"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 OrderItem 对应的数据库表名
+func (OrderItem) TableName() string {
+	return "order_items"
+}
+
+// Subtotal 返回该明细的小计，即单价乘以数量
+func (i OrderItem) Subtotal() float64 {
+	return i.Price * float64(i.Quantity)
+}
+
+// OrderItems 获取一个订单的明细
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - orderID: 订单编号
+//
+// 返回值:
+//   - []OrderItem: 按编号升序排列，即添加明细的顺序，查询失败时返回空列表
+//
+// 注意事项:
+//   - 从主库读取，订单详情通常在修改订单之后立即打开
+func OrderItems(ctx context.Context, orderID string) []OrderItem {
+	var items []OrderItem
+	orm.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&items)
+	return items
+}
+
+// TransitionOrder 将订单流转到下一个状态
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 订单编号
+//   - to: 目标状态，OrderShipped 或 OrderDone
+//
+// 返回值:
+//   - error: 订单不存在或当前状态不是 to 的上一个状态时返回包装了 ErrOrderTransition 的错误，
+//     写入失败时返回数据库错误
+//
+// 使用示例:
+//
+//	err := models.TransitionOrder(ctx, "12", models.OrderShipped)
+//
+// 注意事项:
+//   - 通过 UPDATE ... WHERE status = 上一个状态 完成，两个管理员同时操作时只有一个成功
+func TransitionOrder(ctx context.Context, id string, to string) error {
+	from, ok := orderTransitions[to]
+	if !ok {
+		return fmt.Errorf("%w: 未知的目标状态 %s", ErrOrderTransition, to)
+	}
+
+	res := writer(ctx).Model(&Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: 只有%s的订单可以改为%s", ErrOrderTransition, from, to)
+	}
+	return nil
+}
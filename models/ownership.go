@@ -0,0 +1,41 @@
+// models 包 - 数据模型层
+// 本文件实现按创建人校验记录的归属
+// users 和 posts 表的 created_by 保存创建记录的管理员 ID，非超级管理员只能修改和删除自己创建的记录
+
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotOwner 记录不存在，或者不是该管理员创建的
+var ErrNotOwner = errors.New("只能修改或删除自己创建的记录")
+
+// CheckOwner 检查一组记录是否都是该管理员创建的，修改、删除和恢复前调用
+//
+// 参数:
+//   - ctx: 上下文
+//   - table: 数据表名，表中需要有 id 和 created_by 字段
+//   - ids: 记录编号
+//   - adminID: 管理员 ID（goadmin_users.id）
+//
+// 返回值:
+//   - error: 有任何一条不存在或不是该管理员创建的时返回 ErrNotOwner，查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 已软删除的记录同样参与校验，恢复前也可以调用
+//   - 从主库读取，刚创建的记录不会因为副本的复制延迟被误判
+func CheckOwner(ctx context.Context, table string, ids []string, adminID int64) error {
+	var n int64
+	err := writer(ctx).Table(table).
+		Where("id IN ? AND created_by = ?", ids, adminID).
+		Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n != int64(len(ids)) {
+		return ErrNotOwner
+	}
+	return nil
+}
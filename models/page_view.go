@@ -0,0 +1,123 @@
+// models 包 - 数据模型层
+// 本文件定义页面访问记录模型和浏览器使用情况的统计方法
+// 访问记录由 middleware.PageViewTracker 写入 page_views 表
+
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// PageView 页面访问记录模型
+// 每次成功渲染一个后台页面记录一条，写入时即解析出浏览器类型，统计时无需再解析 User-Agent
+type PageView struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Path 访问的页面路径，不包含查询参数
+	Path string `gorm:"column:path"`
+
+	// Browser 浏览器类型，由 BrowserFamily 从 User-Agent 解析得到
+	Browser string `gorm:"column:browser;index"`
+
+	// UserAgent 原始 User-Agent，便于日后调整解析规则后重新统计
+	UserAgent string `gorm:"column:user_agent;type:text"`
+
+	// CreatedAt 访问时间，由GORM自动填充
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName 指定 PageView 对应的数据库表名
+func (PageView) TableName() string {
+	return "page_views"
+}
+
+// BrowserUsage 某种浏览器在统计区间内的访问次数
+type BrowserUsage struct {
+	// Browser 浏览器类型
+	Browser string `gorm:"column:browser"`
+
+	// Views 访问次数
+	Views float64 `gorm:"column:views"`
+}
+
+// browserFamilies User-Agent 特征到浏览器类型的匹配规则
+// 按顺序匹配，顺序很重要：Edge 和 Opera 的 User-Agent 中同样包含 Chrome，
+// Chrome 的 User-Agent 中同样包含 Safari
+var browserFamilies = []struct {
+	token  string
+	family string
+}{
+	{"Edg", "Edge"},
+	{"OPR/", "欧朋"},
+	{"Opera", "欧朋"},
+	{"Firefox/", "火狐"},
+	{"MSIE ", "IE"},
+	{"Trident/", "IE"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// BrowserFamily 从 User-Agent 中解析浏览器类型
+//
+// 参数:
+//   - userAgent: 请求头中的 User-Agent
+//
+// 返回值:
+//   - string: Edge、欧朋、火狐、IE、Chrome、Safari 之一，无法识别时返回 "其他"
+func BrowserFamily(userAgent string) string {
+	for _, f := range browserFamilies {
+		if strings.Contains(userAgent, f.token) {
+			return f.family
+		}
+	}
+	return "其他"
+}
+
+// RecordPageView 记录一次页面访问
+//
+// 参数:
+//   - ctx: 上下文，中间件在请求结束后异步写入，不能使用已结束的请求的上下文
+//   - path: 页面路径
+//   - userAgent: 请求头中的 User-Agent
+//
+// 返回值:
+//   - error: 数据库写入失败时返回错误
+func RecordPageView(ctx context.Context, path, userAgent string) error {
+	return orm.WithContext(ctx).Create(&PageView{
+		Path:      path,
+		Browser:   BrowserFamily(userAgent),
+		UserAgent: userAgent,
+	}).Error
+}
+
+// BrowserUsageBetween 统计指定时间区间内各浏览器的访问次数
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//
+// 返回值:
+//   - []BrowserUsage: 按访问次数降序排列
+//
+// 注意事项:
+//   - 访问记录写入频繁，写入时不主动失效缓存，统计结果最多延迟 DefaultCacheTTL
+func BrowserUsageBetween(ctx context.Context, from, to time.Time) []BrowserUsage {
+	key := "page_views:browsers:" + from.Format(statisticsTimeLayout) + ":" + to.Format(statisticsTimeLayout)
+	return remember(ctx, key, func() []BrowserUsage {
+		var usage []BrowserUsage
+
+		reader(ctx).Model(&PageView{}).
+			Select("browser, COUNT(*) AS views").
+			Where("created_at BETWEEN ? AND ?", from.Format(statisticsTimeLayout), to.Format(statisticsTimeLayout)).
+			Group("browser").
+			Order("views DESC").
+			Scan(&usage)
+
+		return usage
+	})
+}
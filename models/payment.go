@@ -0,0 +1,132 @@
+// models 包 - 数据模型层
+// 本文件定义支付记录模型和支付状态的流转
+// 支付记录创建后处于已授权状态：收款后可以退款，收款前可以作废，已退款和已作废的记录不能再改变
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 支付状态，与 payments 表 status 字段中保存的值一致
+const (
+	// PaymentAuthorized 已授权，尚未收款
+	PaymentAuthorized = "已授权"
+
+	// PaymentCaptured 已收款
+	PaymentCaptured = "已收款"
+
+	// PaymentRefunded 已退款
+	PaymentRefunded = "已退款"
+
+	// PaymentVoided 已作废
+	PaymentVoided = "已作废"
+)
+
+// 支付操作，即管理后台每行的按钮
+const (
+	// PaymentCapture 收款：已授权 → 已收款
+	PaymentCapture = "capture"
+
+	// PaymentRefund 退款：已收款 → 已退款
+	PaymentRefund = "refund"
+
+	// PaymentVoid 作废：已授权 → 已作废
+	PaymentVoid = "void"
+)
+
+// paymentTransition 一个支付操作要求的当前状态和操作后的状态
+type paymentTransition struct {
+	from, to string
+}
+
+// paymentTransitions 每个支付操作的状态流转
+var paymentTransitions = map[string]paymentTransition{
+	PaymentCapture: {from: PaymentAuthorized, to: PaymentCaptured},
+	PaymentRefund:  {from: PaymentCaptured, to: PaymentRefunded},
+	PaymentVoid:    {from: PaymentAuthorized, to: PaymentVoided},
+}
+
+// paymentActionOrder 支付操作的固定顺序，PaymentActions 按该顺序返回
+var paymentActionOrder = []string{PaymentCapture, PaymentRefund, PaymentVoid}
+
+// ErrPaymentTransition 支付记录的当前状态不允许该操作
+var ErrPaymentTransition = errors.New("支付状态不能流转")
+
+// Payment 支付记录模型
+type Payment struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// OrderID 所属订单的编号
+	OrderID uint `gorm:"column:order_id"`
+
+	// Amount 支付金额
+	Amount float64 `gorm:"column:amount"`
+
+	// Method 支付方式，如 支付宝、微信、银行卡
+	Method string `gorm:"column:method"`
+
+	// Status 支付状态，见 PaymentAuthorized 等常量
+	Status string `gorm:"column:status"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Payment 对应的数据库表名
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// PaymentActions 返回处于 status 状态的支付记录可以执行的操作，按收款、退款、作废的顺序排列
+func PaymentActions(status string) []string {
+	actions := make([]string, 0, len(paymentActionOrder))
+	for _, a := range paymentActionOrder {
+		if paymentTransitions[a].from == status {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// TransitionPayment 对支付记录执行一个操作
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 支付记录编号
+//   - action: 支付操作，PaymentCapture、PaymentRefund 或 PaymentVoid
+//
+// 返回值:
+//   - error: 未知的操作、记录不存在或当前状态不允许该操作时返回包装了 ErrPaymentTransition 的错误，
+//     写入失败时返回数据库错误
+//
+// 使用示例:
+//
+//	err := models.TransitionPayment(ctx, "3", models.PaymentCapture)
+//
+// 注意事项:
+//   - 与 TransitionOrder 相同，通过 UPDATE ... WHERE status = 当前状态 完成，并发操作时只有一个成功
+func TransitionPayment(ctx context.Context, id, action string) error {
+	t, ok := paymentTransitions[action]
+	if !ok {
+		return fmt.Errorf("%w: 未知的操作 %s", ErrPaymentTransition, action)
+	}
+
+	res := writer(ctx).Model(&Payment{}).
+		Where("id = ? AND status = ?", id, t.from).
+		Updates(map[string]interface{}{"status": t.to, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: 只有%s的支付记录可以改为%s", ErrPaymentTransition, t.from, t.to)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// models 包 - 数据模型层
+// 本文件定义文章模型
+// posts 表由迁移创建，管理后台通过 tables.GetPostsTable 查看和编辑，这里用于软删除、恢复、列表中展开文章和公开站点
+
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Post 文章模型
+// 该结构体映射到 posts 表，DeletedAt 不为空的记录已被软删除
+type Post struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// AuthorID 作者，对应 authors.id
+	AuthorID uint `gorm:"column:author_id"`
+
+	// Title 标题
+	Title string `gorm:"column:title"`
+
+	// Description 摘要
+	Description string `gorm:"column:description"`
+
+	// Content 正文，富文本 HTML
+	Content string `gorm:"column:content"`
+
+	// Date 发布日期
+	Date time.Time `gorm:"column:date;type:date"`
+
+	// CreatedBy 创建该文章的管理员 ID，0 表示创建人未知（见 ownership.go）
+	CreatedBy int64 `gorm:"column:created_by"`
+
+	// DeletedAt 删除时间，GORM 的查询自动排除已删除的记录，Delete 只写入该字段
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_posts_deleted_at"`
+}
+
+// TableName 指定 Post 对应的数据库表名
+func (Post) TableName() string {
+	return "posts"
+}
+
+// PostAuthor 文章作者，对应 authors 表中展开文章时需要的字段
+type PostAuthor struct {
+	// ID 作者编号
+	ID uint `gorm:"column:id"`
+
+	// FirstName、LastName 作者的名和姓
+	FirstName string `gorm:"column:first_name"`
+	LastName  string `gorm:"column:last_name"`
+
+	// Email 作者邮箱
+	Email string `gorm:"column:email"`
+}
+
+// FindPostWithAuthor 按编号读取文章及其作者，用于在列表中展开文章
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - id: 文章编号，已删除的文章同样可以读取（列表可以筛选已删除的文章）
+//
+// 返回值:
+//   - Post: 文章
+//   - PostAuthor: 作者，作者已被删除时 ID 为 0
+//   - error: 文章不存在时返回 gorm.ErrRecordNotFound
+func FindPostWithAuthor(ctx context.Context, id string) (Post, PostAuthor, error) {
+	var (
+		post   Post
+		author PostAuthor
+	)
+	if err := reader(ctx).Unscoped().Where("id = ?", id).Take(&post).Error; err != nil {
+		return post, author, err
+	}
+	err := reader(ctx).Table("authors").Where("id = ?", post.AuthorID).Take(&author).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return post, author, err
+	}
+	return post, author, nil
+}
+
+// PublicPost 公开站点中显示的文章，包含作者的姓名
+type PublicPost struct {
+	// ID 文章编号
+	ID uint `gorm:"column:id"`
+
+	// Title 标题
+	Title string `gorm:"column:title"`
+
+	// Description 摘要
+	Description string `gorm:"column:description"`
+
+	// Content 正文，格式取决于后台使用的编辑器（富文本 HTML 或 Markdown）
+	Content string `gorm:"column:content"`
+
+	// Date 发布日期
+	Date time.Time `gorm:"column:date"`
+
+	// AuthorFirstName、AuthorLastName 作者的名和姓，作者不存在时为空
+	AuthorFirstName string `gorm:"column:first_name"`
+	AuthorLastName  string `gorm:"column:last_name"`
+}
+
+// publicPosts 公开站点的文章查询：未删除的文章及其作者
+func publicPosts(ctx context.Context) *gorm.DB {
+	return reader(ctx).Table("posts").
+		Select("posts.id, posts.title, posts.description, posts.content, posts.date, authors.first_name, authors.last_name").
+		Joins("LEFT JOIN authors ON authors.id = posts.author_id").
+		Where("posts.deleted_at IS NULL")
+}
+
+// ListPublicPosts 按发布日期从新到旧分页读取公开站点的文章
+//
+// 参数:
+//   - ctx: 请求的上下文，可以从只读副本读取
+//   - page: 页码，从 1 开始，小于 1 时按 1 处理
+//   - size: 每页的文章数
+//
+// 返回值:
+//   - []PublicPost: 当前页的文章
+//   - int64: 文章总数
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 软删除的文章不出现在公开站点中
+func ListPublicPosts(ctx context.Context, page, size int) ([]PublicPost, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	var total int64
+	if err := reader(ctx).Model(&Post{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var posts []PublicPost
+	err := publicPosts(ctx).Order("posts.date DESC, posts.id DESC").
+		Offset((page - 1) * size).Limit(size).Scan(&posts).Error
+	return posts, total, err
+}
+
+// FindPublicPost 按编号读取公开站点的文章
+//
+// 返回值:
+//   - error: 文章不存在或已删除时返回 gorm.ErrRecordNotFound
+func FindPublicPost(ctx context.Context, id uint) (PublicPost, error) {
+	var post PublicPost
+	res := publicPosts(ctx).Where("posts.id = ?", id).Limit(1).Scan(&post)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return post, gorm.ErrRecordNotFound
+	}
+	return post, res.Error
+}
@@ -0,0 +1,77 @@
+// models 包 - 数据模型层
+// 本文件定义商品模型和库存预警
+// products 表通过 tables.GetProductsTable 维护商品信息，通过 tables.GetInventoryTable 维护库存
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Product 商品模型
+type Product struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 商品名称
+	Name string `gorm:"column:name"`
+
+	// Price 价格
+	Price float64 `gorm:"column:price"`
+
+	// Stock 当前库存
+	Stock int `gorm:"column:stock"`
+
+	// MinStock 安全库存，库存低于该值时需要补货
+	MinStock int `gorm:"column:min_stock"`
+
+	// CategoryID 所属分类，对应 categories.id
+	CategoryID uint `gorm:"column:category_id"`
+
+	// Images 商品图片，多张以逗号分隔
+	Images string `gorm:"column:images"`
+
+	// Metadata 商品扩展属性的 JSON 对象，如品牌、产地、重量和尺寸
+	Metadata string `gorm:"column:metadata;type:text"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Product 对应的数据库表名
+func (Product) TableName() string {
+	return "products"
+}
+
+// LowStock 库存是否低于安全库存
+func (p Product) LowStock() bool {
+	return p.Stock < p.MinStock
+}
+
+// Shortage 补足到安全库存还需要的数量，库存充足时为 0
+func (p Product) Shortage() int {
+	if !p.LowStock() {
+		return 0
+	}
+	return p.MinStock - p.Stock
+}
+
+// LowStockProducts 获取库存低于安全库存的商品
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//
+// 返回值:
+//   - []Product: 按缺口从大到小排列，查询失败时返回空列表
+//
+// 注意事项:
+//   - 从主库读取，管理员修改库存后立即打开补货提醒时不受副本复制延迟的影响
+func LowStockProducts(ctx context.Context) []Product {
+	var products []Product
+	orm.WithContext(ctx).Where("stock < min_stock").Order("min_stock - stock DESC, id").Find(&products)
+	return products
+}
@@ -0,0 +1,53 @@
+// models 包 - 数据模型层
+// 本文件定义行政区划模型，表单页面的省、市、区三级联动按上级逐级读取 regions 表
+
+package models
+
+import "context"
+
+// 行政区划的级别，对应 regions 表的 level
+const (
+	RegionProvince = 1
+	RegionCity     = 2
+	RegionDistrict = 3
+)
+
+// Region 行政区划
+// 该结构体映射到 regions 表
+type Region struct {
+	// ID 行政区划代码，如 440100
+	ID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// ParentID 上级区划的代码，省的上级为 0
+	ParentID uint `gorm:"column:parent_id"`
+
+	// Name 名称
+	Name string `gorm:"column:name"`
+
+	// Level 级别：RegionProvince、RegionCity 或 RegionDistrict
+	Level int `gorm:"column:level"`
+}
+
+// TableName 指定 Region 对应的数据库表名
+func (Region) TableName() string {
+	return "regions"
+}
+
+// FindRegions 读取上级为 parentID 的行政区划，按代码排列；parentID 为 0 时读取全部省
+// 区划数据很少变化，从只读副本读取
+func FindRegions(ctx context.Context, parentID uint) ([]Region, error) {
+	var list []Region
+	err := reader(ctx).Where("parent_id = ?", parentID).Order("id").Find(&list).Error
+	return list, err
+}
+
+// FindRegion 按代码读取行政区划
+//
+// 返回值:
+//   - Region: 行政区划
+//   - error: 代码不存在时返回 gorm.ErrRecordNotFound
+func FindRegion(ctx context.Context, id uint) (Region, error) {
+	var r Region
+	err := reader(ctx).Where("id = ?", id).Take(&r).Error
+	return r, err
+}
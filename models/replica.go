@@ -0,0 +1,108 @@
+// models 包 - 数据模型层
+// 本文件实现只读副本的路由
+// 表格列表、导出和仪表板统计这类只读查询发往只读副本，写入和需要立即读到写入结果的查询仍使用主库，
+// 没有配置只读副本时所有查询都使用主库
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/purpose168/GoAdmin/modules/db"
+	"gorm.io/gorm"
+)
+
+// primaryConnection 主库在 config.yml 中的连接名
+const primaryConnection = "default"
+
+// replica 一个只读副本
+type replica struct {
+	// name config.yml 中 database 配置项下的连接名，GoAdmin 的表格按该名称查询副本
+	name string
+
+	// db 副本的 ORM 实例，连接池与主库相互独立
+	db *gorm.DB
+}
+
+var (
+	// replicas 已打开的只读副本，由 Init 按 ORMConfig.Replicas 打开
+	replicas []replica
+
+	// replicaNext 轮询副本的计数器
+	replicaNext uint32
+)
+
+// openReplicas 按连接名打开只读副本
+//
+// 参数:
+//   - c: GoAdmin 的数据库连接，副本的驱动和 DSN 取自其中同名的数据库配置
+//   - names: 副本的连接名
+//   - cfg: ORM 配置，副本与主库使用相同的日志和连接池设置
+//
+// 返回值:
+//   - []replica: 按 names 顺序打开的副本
+//   - error: 连接名不存在、副本的驱动与主库不同或打开失败时返回错误
+func openReplicas(c db.Connection, names []string, cfg ORMConfig) ([]replica, error) {
+	primary := c.GetConfig(primaryConnection).Driver
+	opened := make([]replica, 0, len(names))
+	for _, name := range names {
+		// GoAdmin 按驱动分组初始化连接，驱动不同的副本在 c 中查不到配置；
+		// 副本的表结构也必须与主库一致，因此要求驱动相同
+		conf := c.GetConfig(name)
+		if conf.Driver == "" {
+			return nil, fmt.Errorf("只读副本 %s 没有对应的数据库配置，或其驱动与主库不同", name)
+		}
+		if conf.Driver != primary {
+			return nil, fmt.Errorf("只读副本 %s 的驱动 %q 与主库的驱动 %q 不一致", name, conf.Driver, primary)
+		}
+		gdb, err := Open(conf.Driver, conf.GetDSN(), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("打开只读副本 %s 失败: %w", name, err)
+		}
+		opened = append(opened, replica{name: name, db: gdb})
+	}
+	return opened, nil
+}
+
+// nextReplica 轮询返回下一个只读副本，没有配置副本时返回 nil
+func nextReplica() *replica {
+	if len(replicas) == 0 {
+		return nil
+	}
+	n := atomic.AddUint32(&replicaNext, 1)
+	return &replicas[int((n-1)%uint32(len(replicas)))]
+}
+
+// reader 返回执行只读查询的 ORM 实例
+// 配置了只读副本时轮询选择一个副本，否则返回主库
+//
+// 注意事项:
+//   - 副本存在复制延迟，写入后需要立即读到的数据（如管理员刚保存的仪表板布局）仍使用 orm
+//   - 汇总任务读取的明细直接决定写入的结果，同样使用 orm
+//   - 统计缓存在写入时失效，失效后的首次查询可能从副本读到旧值并写入缓存，最长保留 DefaultCacheTTL
+func reader(ctx context.Context) *gorm.DB {
+	if r := nextReplica(); r != nil {
+		return r.db.WithContext(ctx)
+	}
+	return orm.WithContext(ctx)
+}
+
+// ReadConnection 返回 GoAdmin 表格执行列表和导出查询时使用的数据库连接名
+//
+// 返回值:
+//   - string: 配置了只读副本时轮询返回副本的连接名，否则返回 default
+//
+// 使用示例:
+//
+//	reader := table.NewDefaultTable(ctx, table.DefaultConfigWithDriverAndConnection(driver, models.ReadConnection()))
+//
+// 注意事项:
+//   - 必须在 Init 之后调用，之前总是返回 default
+func ReadConnection() string {
+	if r := nextReplica(); r != nil {
+		return r.name
+	}
+	return primaryConnection
+}
@@ -0,0 +1,51 @@
+// models 包 - 数据模型层
+// 本文件提供报表页面（pages.ReportsPage）执行查询使用的函数
+// 报表的 SQL 写在各报表的定义中，这里只负责执行并把结果转换为按列名取值的行
+
+package models
+
+import (
+	"context"
+)
+
+// ReportMaxRows 报表最多返回的行数，超出的部分被截断，报表页面中注明
+const ReportMaxRows = 1000
+
+// ReportRows 执行报表的查询
+//
+// 参数:
+//   - ctx: 上下文
+//   - query: SQL 语句，参数使用 ? 占位，不能把页面提交的值直接拼接到语句中
+//   - args: 占位符对应的参数
+//
+// 返回值:
+//   - []map[string]interface{}: 每行以列名为键，文本列以字符串返回；最多 ReportMaxRows 行
+//   - bool: 结果是否因超过 ReportMaxRows 行被截断
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 从只读副本读取（见 reader），报表不需要读到刚写入的数据
+func ReportRows(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, bool, error) {
+	var rows []map[string]interface{}
+	if err := reader(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, false, err
+	}
+	for _, row := range rows {
+		// 文本列可能以字节切片读出，转换为字符串
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+	}
+	if len(rows) > ReportMaxRows {
+		return rows[:ReportMaxRows], true, nil
+	}
+	return rows, false, nil
+}
+
+// ReportDateExpr 返回取时间字段日期部分（2006-01-02）的 SQL 表达式，报表按天分组时使用
+// column 直接拼接到 SQL 中，只能传入代码中的常量
+func ReportDateExpr(column string) string {
+	return datePrefix(column, 10)
+}
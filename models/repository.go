@@ -0,0 +1,199 @@
+// models 包 - 数据模型层
+// 本文件定义仪表板使用的仓储接口及其 GORM 实现
+// 页面和组件通过 Repositories 读取数据，而不是直接调用包级查询函数，
+// 测试时可以传入内存中的假实现，不需要准备 SQLite 数据库文件
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// StatisticsRepo 统计数据的查询接口
+type StatisticsRepo interface {
+	// First 返回第一条统计记录，没有记录时返回零值
+	First(ctx context.Context) *Statistics
+
+	// Summarize 汇总时间区间内的统计数据
+	Summarize(ctx context.Context, from, to time.Time) StatisticsSummary
+
+	// Compare 按粒度计算各项指标的环比，返回当前周期和环比结果
+	Compare(ctx context.Context, from, to time.Time, g Granularity) (Period, []KPIComparison)
+
+	// SalesSeries 返回连续的销售额时间序列及实际使用的粒度
+	SalesSeries(ctx context.Context, from, to time.Time, g Granularity) ([]SeriesPoint, Granularity)
+
+	// LatestSystem 返回最近一个有系统资源采样的小时，没有记录时返回零值
+	LatestSystem(ctx context.Context) *Statistics
+
+	// SystemSeries 返回时间区间内按小时的系统资源序列
+	SystemSeries(ctx context.Context, from, to time.Time) []SystemPoint
+
+	// Increment 将各项增量累加到当前小时
+	Increment(ctx context.Context, inc StatisticsIncrement) error
+}
+
+// OrdersRepo 订单的查询接口
+type OrdersRepo interface {
+	// Recent 返回最新的 limit 个订单
+	Recent(ctx context.Context, limit int) []Order
+
+	// TopProducts 返回时间区间内销售额最高的 limit 个商品
+	TopProducts(ctx context.Context, from, to time.Time, limit int) []ProductRevenue
+}
+
+// GoalsRepo 目标的查询接口
+type GoalsRepo interface {
+	// All 返回所有目标
+	All(ctx context.Context) []Goal
+}
+
+// PageViewsRepo 页面访问记录的查询接口
+type PageViewsRepo interface {
+	// BrowserUsage 按浏览器统计时间区间内的访问次数
+	BrowserUsage(ctx context.Context, from, to time.Time) []BrowserUsage
+}
+
+// MembersRepo 会员的查询接口
+type MembersRepo interface {
+	// ByCountry 按国家统计时间区间内的新会员数
+	ByCountry(ctx context.Context, from, to time.Time) []CountryCount
+}
+
+// DailyCountsRepo 每日计数的查询接口
+type DailyCountsRepo interface {
+	// Between 返回指标在日期区间内的每日计数
+	Between(ctx context.Context, metric string, from, to time.Time) []DailyCount
+}
+
+// LayoutsRepo 仪表板布局的读写接口
+type LayoutsRepo interface {
+	// Get 返回管理员在仪表板上保存的布局，没有保存过时返回 nil
+	Get(ctx context.Context, userID int64, dashboard string) []LayoutWidget
+
+	// Save 保存管理员在仪表板上的布局
+	Save(ctx context.Context, userID int64, dashboard string, widgets []LayoutWidget) error
+}
+
+// SettingsRepo 系统设置的查询接口
+type SettingsRepo interface {
+	// String 返回文本设置，设置项不存在或为空时返回 def
+	String(ctx context.Context, key, def string) string
+}
+
+// Repositories 页面使用的所有仓储
+// 字段为 nil 时使用该字段的页面或组件会 panic，测试时只需要填充被测页面用到的仓储
+type Repositories struct {
+	Statistics  StatisticsRepo
+	Orders      OrdersRepo
+	Goals       GoalsRepo
+	PageViews   PageViewsRepo
+	Members     MembersRepo
+	DailyCounts DailyCountsRepo
+	Layouts     LayoutsRepo
+	Settings    SettingsRepo
+}
+
+// NewGormRepositories 返回基于 GORM 的仓储实现
+//
+// 使用示例:
+//
+//	models.Init(eng.DefaultConnection(), models.DefaultORMConfig)
+//	pages.Repos = models.NewGormRepositories()
+//
+// 注意事项:
+//   - 各实现使用 Init 创建的全局 ORM 实例和统计缓存，可以在 Init 之前创建，但必须在 Init 之后使用
+func NewGormRepositories() Repositories {
+	return Repositories{
+		Statistics:  gormStatisticsRepo{},
+		Orders:      gormOrdersRepo{},
+		Goals:       gormGoalsRepo{},
+		PageViews:   gormPageViewsRepo{},
+		Members:     gormMembersRepo{},
+		DailyCounts: gormDailyCountsRepo{},
+		Layouts:     gormLayoutsRepo{},
+		Settings:    Settings,
+	}
+}
+
+// gormStatisticsRepo StatisticsRepo 的 GORM 实现
+type gormStatisticsRepo struct{}
+
+func (gormStatisticsRepo) First(ctx context.Context) *Statistics {
+	return FirstStatics(ctx)
+}
+
+func (gormStatisticsRepo) Summarize(ctx context.Context, from, to time.Time) StatisticsSummary {
+	return SummarizeStatistics(ctx, from, to)
+}
+
+func (gormStatisticsRepo) Compare(ctx context.Context, from, to time.Time, g Granularity) (Period, []KPIComparison) {
+	return CompareStatistics(ctx, from, to, g)
+}
+
+func (gormStatisticsRepo) SalesSeries(ctx context.Context, from, to time.Time, g Granularity) ([]SeriesPoint, Granularity) {
+	return SalesSeries(ctx, from, to, g)
+}
+
+func (gormStatisticsRepo) LatestSystem(ctx context.Context) *Statistics {
+	return LatestSystemStatistics(ctx)
+}
+
+func (gormStatisticsRepo) SystemSeries(ctx context.Context, from, to time.Time) []SystemPoint {
+	return SystemSeries(ctx, from, to)
+}
+
+func (gormStatisticsRepo) Increment(ctx context.Context, inc StatisticsIncrement) error {
+	return IncrementStatistics(ctx, inc)
+}
+
+// gormOrdersRepo OrdersRepo 的 GORM 实现
+type gormOrdersRepo struct{}
+
+func (gormOrdersRepo) Recent(ctx context.Context, limit int) []Order {
+	return RecentOrders(ctx, limit)
+}
+
+func (gormOrdersRepo) TopProducts(ctx context.Context, from, to time.Time, limit int) []ProductRevenue {
+	return TopProductsByRevenue(ctx, from, to, limit)
+}
+
+// gormGoalsRepo GoalsRepo 的 GORM 实现
+type gormGoalsRepo struct{}
+
+func (gormGoalsRepo) All(ctx context.Context) []Goal {
+	return AllGoals(ctx)
+}
+
+// gormPageViewsRepo PageViewsRepo 的 GORM 实现
+type gormPageViewsRepo struct{}
+
+func (gormPageViewsRepo) BrowserUsage(ctx context.Context, from, to time.Time) []BrowserUsage {
+	return BrowserUsageBetween(ctx, from, to)
+}
+
+// gormMembersRepo MembersRepo 的 GORM 实现
+type gormMembersRepo struct{}
+
+func (gormMembersRepo) ByCountry(ctx context.Context, from, to time.Time) []CountryCount {
+	return NewMembersByCountry(ctx, from, to)
+}
+
+// gormDailyCountsRepo DailyCountsRepo 的 GORM 实现
+type gormDailyCountsRepo struct{}
+
+func (gormDailyCountsRepo) Between(ctx context.Context, metric string, from, to time.Time) []DailyCount {
+	return DailyCountsBetween(ctx, metric, from, to)
+}
+
+// gormLayoutsRepo LayoutsRepo 的 GORM 实现
+type gormLayoutsRepo struct{}
+
+func (gormLayoutsRepo) Get(ctx context.Context, userID int64, dashboard string) []LayoutWidget {
+	return GetDashboardLayout(ctx, userID, dashboard)
+}
+
+func (gormLayoutsRepo) Save(ctx context.Context, userID int64, dashboard string, widgets []LayoutWidget) error {
+	return SaveDashboardLayout(ctx, userID, dashboard, widgets)
+}
@@ -0,0 +1,28 @@
+// models 包 - 数据模型层
+// 本文件提供数据表行数的缓存读取，用于判断列表是否需要按主键翻页
+
+package models
+
+import "context"
+
+// rowCountCachePrefix 数据表行数在缓存中的键前缀，完整的键为前缀加表名
+const rowCountCachePrefix = "rows:"
+
+// TableRowCount 返回数据表的行数（包括已软删除的记录）
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - table: 数据表名称，只能传入代码中的常量，不能来自请求
+//
+// 返回值:
+//   - int64: 行数，查询失败时返回 0
+//
+// 注意事项:
+//   - 大表上 count(*) 同样需要扫描，结果缓存 DefaultCacheTTL，每分钟最多查询一次，只用于粗略判断数据量
+func TableRowCount(ctx context.Context, table string) int64 {
+	return remember(ctx, rowCountCachePrefix+table, func() int64 {
+		var n int64
+		reader(ctx).Table(table).Count(&n)
+		return n
+	})
+}
@@ -0,0 +1,130 @@
+// models 包 - 数据模型层
+// 本文件实现全局搜索的查询：按搜索配置在一个表的多个字段中查找包含搜索词的记录
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SearchSpec 一个表的搜索配置
+// 表名和字段名直接拼接到 SQL 中，只能使用代码中的常量
+type SearchSpec struct {
+	// Table 表名
+	Table string
+
+	// Columns 搜索的字段，每个搜索词包含在其中任一字段中即匹配
+	Columns []string
+
+	// TitleColumns 结果标题的字段，多个字段的值以空格连接，例如作者的名和姓
+	TitleColumns []string
+
+	// DetailColumn 结果标题下方显示的字段，为空时不显示
+	DetailColumn string
+
+	// SoftDelete 表有 deleted_at 字段时为 true，已删除的记录不出现在结果中
+	SoftDelete bool
+
+	// OwnerColumn 保存创建人的字段，不为空时只返回 OwnerID 创建的记录
+	// 由 tables.Searchable.ScopedSpec 按当前管理员设置，注册搜索范围时不需要填写
+	OwnerColumn string
+
+	// OwnerID 创建人的管理员 ID，OwnerColumn 不为空时使用
+	OwnerID int64
+}
+
+// SearchHit 一条搜索结果
+type SearchHit struct {
+	// ID 记录的主键
+	ID string
+
+	// Title 结果标题
+	Title string
+
+	// Detail 标题下方的说明，可以为空
+	Detail string
+}
+
+// Search 按搜索配置查找包含搜索词的记录
+//
+// 参数:
+//   - ctx: 上下文，可以从只读副本读取
+//   - spec: 表的搜索配置
+//   - q: 搜索内容，以空白分隔的多个词需要同时匹配，最多取前 5 个词
+//   - limit: 最多返回的条数
+//
+// 返回值:
+//   - []SearchHit: 按主键从大到小排列的结果，即较新的记录在前；q 为空时返回 nil
+//   - bool: 匹配的记录是否多于 limit 条
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 与用户列表的搜索框（UserSearchCondition）一样使用 LIKE 按包含匹配，不使用全文索引
+func Search(ctx context.Context, spec SearchSpec, q string, limit int) ([]SearchHit, bool, error) {
+	terms := strings.Fields(q)
+	if len(terms) == 0 {
+		return nil, false, nil
+	}
+	if len(terms) > userSearchMaxTerms {
+		terms = terms[:userSearchMaxTerms]
+	}
+
+	tx := reader(ctx).Table(spec.Table)
+	for _, t := range terms {
+		ors := make([]string, len(spec.Columns))
+		args := make([]interface{}, len(spec.Columns))
+		for i, c := range spec.Columns {
+			ors[i] = spec.Table + "." + c + " LIKE ? ESCAPE '!'"
+			args[i] = "%" + escapeLike(t) + "%"
+		}
+		tx = tx.Where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+	if spec.SoftDelete {
+		tx = tx.Where(spec.Table + ".deleted_at IS NULL")
+	}
+	if spec.OwnerColumn != "" {
+		tx = tx.Where(spec.Table+"."+spec.OwnerColumn+" = ?", spec.OwnerID)
+	}
+
+	columns := append([]string{"id"}, spec.TitleColumns...)
+	if spec.DetailColumn != "" {
+		columns = append(columns, spec.DetailColumn)
+	}
+	var rows []map[string]interface{}
+	if err := tx.Select(columns).Order("id DESC").Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, false, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	hits := make([]SearchHit, len(rows))
+	for i, row := range rows {
+		title := make([]string, 0, len(spec.TitleColumns))
+		for _, c := range spec.TitleColumns {
+			if s := searchValue(row[c]); s != "" {
+				title = append(title, s)
+			}
+		}
+		hits[i] = SearchHit{ID: searchValue(row["id"]), Title: strings.Join(title, " ")}
+		if spec.DetailColumn != "" {
+			hits[i].Detail = searchValue(row[spec.DetailColumn])
+		}
+	}
+	return hits, more, nil
+}
+
+// searchValue 把查询结果中的值转换为字符串，NULL 转换为空字符串
+// MySQL 驱动把文本字段读为 []byte，直接格式化会得到字节数组
+func searchValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,269 @@
+// models 包 - 数据模型层
+// 本文件定义系统设置模型和设置缓存
+// 设置以键值对保存在 settings 表中，type 决定值的格式和管理后台的编辑控件；
+// 页面通过 Settings 读取设置，读取结果缓存在统计数据使用的同一个缓存后端中，
+// 管理后台修改设置后调用 Settings.Invalidate 清除缓存
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 设置项的类型，与 settings 表 type 字段中保存的值一致
+const (
+	// SettingBool 开关，值为 true 或 false
+	SettingBool = "bool"
+
+	// SettingNumber 数字
+	SettingNumber = "number"
+
+	// SettingColor 颜色，值为 #RRGGBB 格式
+	SettingColor = "color"
+
+	// SettingText 文本
+	SettingText = "text"
+)
+
+// 页面读取的设置项
+const (
+	// SettingDashboardTitle 首页仪表板的标题
+	SettingDashboardTitle = "dashboard_title"
+
+	// SettingDefaultPageSize 数据表格默认每页显示的条数
+	SettingDefaultPageSize = "default_page_size"
+
+	// SettingCursorPaginationThreshold 数据表的行数超过该值时列表改为按主键翻页
+	SettingCursorPaginationThreshold = "cursor_pagination_threshold"
+
+	// SettingSiteTitle 后台的站点标题，为空时使用 config.yml 中的 title
+	SettingSiteTitle = "site_title"
+
+	// SettingLogoURL 侧边栏顶部 Logo 图片的地址，为空时使用 config.yml 中的 logo
+	SettingLogoURL = "logo_url"
+
+	// SettingMaintenance 维护模式，开启后只有超级管理员可以使用后台
+	SettingMaintenance = "maintenance_mode"
+)
+
+// settingsCacheKey 全部设置在缓存中的键
+const settingsCacheKey = "settings:all"
+
+// colorPattern #RRGGBB 格式的颜色
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ErrInvalidSetting 设置值与设置项的类型不符
+var ErrInvalidSetting = errors.New("设置值无效")
+
+// Setting 系统设置模型
+type Setting struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Key 设置项的键，唯一
+	Key string `gorm:"column:key"`
+
+	// Type 设置项的类型，见 SettingBool 等常量
+	Type string `gorm:"column:type"`
+
+	// Value 设置值，统一以字符串保存
+	Value string `gorm:"column:value"`
+
+	// Description 设置项的说明
+	Description string `gorm:"column:description"`
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Setting 对应的数据库表名
+func (Setting) TableName() string {
+	return "settings"
+}
+
+// defaultSettings 启动时补齐的设置项，已存在的设置项不会被覆盖
+var defaultSettings = []Setting{
+	{Key: SettingDashboardTitle, Type: SettingText, Value: "仪表板", Description: "首页仪表板的标题"},
+	{Key: SettingDefaultPageSize, Type: SettingNumber, Value: "10", Description: "数据表格默认每页显示的条数"},
+	{Key: SettingCursorPaginationThreshold, Type: SettingNumber, Value: "100000", Description: "用户列表的行数超过该值时按编号翻页，不再显示页码，0 表示不启用"},
+	{Key: SettingSiteTitle, Type: SettingText, Value: "", Description: "后台的站点标题，为空时使用 config.yml 中的 title"},
+	{Key: SettingLogoURL, Type: SettingText, Value: "", Description: "侧边栏顶部 Logo 图片的地址，为空时使用 config.yml 中的 logo"},
+	{Key: SettingMaintenance, Type: SettingBool, Value: "false", Description: "维护模式，开启后只有超级管理员可以使用后台"},
+}
+
+// NormalizeSettingValue 按设置项的类型校验并规范化设置值
+//
+// 参数:
+//   - typ: 设置项的类型
+//   - value: 提交的设置值
+//
+// 返回值:
+//   - string: 规范化后的值，开关为 true 或 false，颜色为小写的 #rrggbb
+//   - error: 类型未知或值与类型不符时返回包装了 ErrInvalidSetting 的错误
+func NormalizeSettingValue(typ, value string) (string, error) {
+	switch typ {
+	case SettingBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: 开关只能是 true 或 false", ErrInvalidSetting)
+		}
+		return strconv.FormatBool(b), nil
+	case SettingNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%w: %q 不是数字", ErrInvalidSetting, value)
+		}
+		return value, nil
+	case SettingColor:
+		if !colorPattern.MatchString(value) {
+			return "", fmt.Errorf("%w: 颜色应为 #RRGGBB 格式", ErrInvalidSetting)
+		}
+		return strings.ToLower(value), nil
+	case SettingText:
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: 未知的类型 %s", ErrInvalidSetting, typ)
+}
+
+// SaveSettings 修改多个设置项的值
+//
+// 参数:
+//   - ctx: 上下文，携带事务时在该事务中修改（见 Transaction）
+//   - values: 设置项的键到提交的值，值按设置项的类型经 NormalizeSettingValue 校验
+//
+// 返回值:
+//   - []string: 值有变化的设置项的主键，按主键排列；没有变化的设置项不修改
+//   - error: 设置项不存在或值与类型不符时返回包装了 ErrInvalidSetting 的错误，此时不修改任何设置项
+//
+// 注意事项:
+//   - 不清除设置缓存，调用方在事务提交后调用 Settings.Invalidate
+func SaveSettings(ctx context.Context, values map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	var rows []Setting
+	if err := writer(ctx).Where(map[string]interface{}{"key": keys}).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(rows))
+	changed := make(map[uint]string)
+	for _, s := range rows {
+		found[s.Key] = true
+		v, err := NormalizeSettingValue(s.Type, values[s.Key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Key, err)
+		}
+		if v != s.Value {
+			changed[s.ID] = v
+		}
+	}
+	for _, k := range keys {
+		if !found[k] {
+			return nil, fmt.Errorf("%w: 设置项 %s 不存在", ErrInvalidSetting, k)
+		}
+	}
+
+	ids := make([]string, 0, len(changed))
+	for _, s := range rows {
+		v, ok := changed[s.ID]
+		if !ok {
+			continue
+		}
+		if err := writer(ctx).Model(&Setting{ID: s.ID}).Update("value", v).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, strconv.FormatUint(uint64(s.ID), 10))
+	}
+	return ids, nil
+}
+
+// settingsCache 设置的读取入口，见 Settings
+type settingsCache struct{}
+
+// Settings 读取系统设置
+// 第一次读取时加载全部设置并缓存，缓存在 DefaultCacheTTL 后过期，或在 Invalidate 后立即失效
+//
+// 使用示例:
+//
+//	title := models.Settings.String(ctx, models.SettingDashboardTitle, "仪表板")
+//	size := models.Settings.Int(ctx, models.SettingDefaultPageSize, 10)
+var Settings settingsCache
+
+// all 返回全部设置值，键为设置项的键
+func (settingsCache) all(ctx context.Context) map[string]string {
+	return remember(ctx, settingsCacheKey, func() map[string]string {
+		var rows []Setting
+		orm.WithContext(ctx).Find(&rows)
+		values := make(map[string]string, len(rows))
+		for _, s := range rows {
+			values[s.Key] = s.Value
+		}
+		return values
+	})
+}
+
+// Get 返回设置值，设置项不存在时第二个返回值为 false
+func (s settingsCache) Get(ctx context.Context, key string) (string, bool) {
+	v, ok := s.all(ctx)[key]
+	return v, ok
+}
+
+// String 返回文本设置，设置项不存在或为空时返回 def
+func (s settingsCache) String(ctx context.Context, key, def string) string {
+	if v, ok := s.Get(ctx, key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Int 返回整数设置，设置项不存在或不是整数时返回 def
+func (s settingsCache) Int(ctx context.Context, key string, def int) int {
+	v, ok := s.Get(ctx, key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Bool 返回开关设置，设置项不存在或不是 true、false 时返回 def
+func (s settingsCache) Bool(ctx context.Context, key string, def bool) bool {
+	v, ok := s.Get(ctx, key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Invalidate 清除设置缓存，修改 settings 表后调用，下次读取时重新加载
+func (settingsCache) Invalidate() {
+	cache.DeletePrefix(settingsCacheKey)
+}
+
+// seedSettings 补齐 defaultSettings 中缺少的设置项
+func seedSettings(ctx context.Context) error {
+	for i := range defaultSettings {
+		s := defaultSettings[i]
+		err := orm.WithContext(ctx).Where(Setting{Key: s.Key}).
+			Attrs(Setting{Type: s.Type, Value: s.Value, Description: s.Description}).
+			FirstOrCreate(&s).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// models 包 - 数据模型层
+// 本文件实现软删除模型的删除和恢复
+// 管理后台的表格通过 GoAdmin 直接读写数据表，删除操作改为调用这里的函数，只写入删除时间
+
+package models
+
+import (
+	"context"
+)
+
+// SoftDelete 软删除主键在 ids 中的记录
+//
+// 参数:
+//   - ctx: 上下文
+//   - model: 包含 gorm.DeletedAt 字段的模型，如 &User{}、&Post{}
+//   - ids: 主键，通常来自 GoAdmin 表格的删除操作
+//
+// 返回值:
+//   - int64: 实际删除的记录数，已删除或不存在的记录不计入
+//   - error: 数据库写入失败时返回错误
+//
+// 使用示例:
+//
+//	info.SetDeleteFn(func(ids []string) error {
+//	    _, err := models.SoftDelete(context.Background(), &models.User{}, ids)
+//	    return err
+//	})
+//
+// 注意事项:
+//   - model 没有 DeletedAt 字段时会物理删除记录
+//   - 已删除的记录再次删除时保持原来的删除时间
+func SoftDelete(ctx context.Context, model interface{}, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := writer(ctx).Where("id IN ?", ids).Delete(model)
+	return res.RowsAffected, res.Error
+}
+
+// Restore 恢复主键在 ids 中的已软删除记录
+//
+// 参数:
+//   - ctx: 上下文
+//   - model: 包含 gorm.DeletedAt 字段的模型
+//   - ids: 主键
+//
+// 返回值:
+//   - int64: 实际恢复的记录数，未删除的记录不计入
+//   - error: 数据库写入失败时返回错误
+func Restore(ctx context.Context, model interface{}, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := writer(ctx).Unscoped().Model(model).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Update("deleted_at", nil)
+	return res.RowsAffected, res.Error
+}
@@ -8,14 +8,19 @@
 package models
 
 import (
+	"context"
 	"html/template"
 	"strconv"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Statistics 统计数据模型
 // 该结构体用于存储系统运行时的各种统计数据
 // 包括CPU使用率、点赞数、销售额和新会员数等关键指标
+// 每小时一条记录，由 Hour 唯一标识，销售额和新会员数由汇总任务写入（见 statistics_series.go），
+// CPU、内存和协程数由系统资源采集任务写入（见 system_stats.go）
 // 通过GORM自动映射到数据库表
 type Statistics struct {
 	// ID 主键字段
@@ -24,10 +29,16 @@ type Statistics struct {
 	ID uint `gorm:"primary_key,column:cpu"`
 
 	// CPU CPU使用率
-	// 表示系统当前的CPU使用情况，通常以百分比形式存储
+	// 以百分比形式存储，由系统资源采集任务写入该小时采样的平均值（见 system_stats.go）
 	// GORM标签: column=cpu 指定数据库列名为cpu
 	CPU uint `gorm:"column:cpu"`
 
+	// Memory 内存使用率，百分比，与 CPU 一样为该小时采样的平均值
+	Memory uint `gorm:"column:memory"`
+
+	// Goroutines 本进程的协程数，该小时采样的平均值
+	Goroutines uint `gorm:"column:goroutines"`
+
 	// Likes 点赞数
 	// 记录用户点赞的总数，用于衡量内容的受欢迎程度
 	// GORM标签: column=likes 指定数据库列名为likes
@@ -43,6 +54,10 @@ type Statistics struct {
 	// GORM标签: column=new_members 指定数据库列名为new_members
 	NewMembers uint `gorm:"column:new_members"`
 
+	// Hour 记录所属的小时，格式为 2006-01-02 15:00:00
+	// 按时间区间查询统计数据时使用该字段，写入时为空则由 BeforeSave 按 CreatedAt 填充
+	Hour string `gorm:"column:hour;uniqueIndex:idx_statistics_hour"`
+
 	// CreatedAt 创建时间
 	// GORM自动管理的字段，记录数据创建的时间戳
 	// 在插入数据时自动填充当前时间
@@ -57,6 +72,9 @@ type Statistics struct {
 // FirstStatics 获取第一条统计数据
 // 该函数从数据库中查询并返回第一条Statistics记录
 //
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//
 // 返回值:
 //   - *Statistics: 指向第一条统计数据的指针，如果数据库中没有记录则返回空结构体
 //
@@ -70,7 +88,7 @@ type Statistics struct {
 //	import "github.com/purpose168/GoAdmin-example/models"
 //
 //	// 获取统计数据
-//	stats := models.FirstStatics()
+//	stats := models.FirstStatics(ctx)
 //	if stats.ID != 0 {
 //	    fmt.Printf("CPU使用率: %d%%\n", stats.CPU)
 //	}
@@ -82,21 +100,48 @@ type Statistics struct {
 //
 // GORM说明:
 //   - First方法会按主键升序查询第一条记录
-//   - 如果没有找到记录，不会返回错误，而是返回空结果
-func FirstStatics() *Statistics {
-	// 创建一个新的Statistics实例
-	// new()函数分配内存并返回指向该类型的指针
-	// 所有字段将被初始化为零值
-	s := new(Statistics)
-
-	// 使用GORM的First方法查询第一条记录
-	// orm是全局的GORM实例，在base.go中初始化
-	// First方法会生成SQL: SELECT * FROM statistics ORDER BY id LIMIT 1
-	orm.First(s)
-
-	// 返回查询结果
-	// 如果查询失败或没有记录，s将保持零值状态
-	return s
+//   - 如果没有找到记录，会返回 gorm.ErrRecordNotFound，这里忽略该错误并返回空结果
+func FirstStatics(ctx context.Context) *Statistics {
+	// 查询结果会被缓存，统计数据写入时缓存自动失效（见 cache.go）
+	return remember(ctx, statisticsCachePrefix+"first", func() *Statistics {
+		// 创建一个新的Statistics实例
+		// new()函数分配内存并返回指向该类型的指针
+		// 所有字段将被初始化为零值
+		s := new(Statistics)
+
+		// 使用GORM的First方法查询第一条记录
+		// reader 返回全局的GORM实例或只读副本，见 replica.go
+		// First方法会生成SQL: SELECT * FROM statistics ORDER BY id LIMIT 1
+		reader(ctx).First(s)
+
+		// 返回查询结果
+		// 如果查询失败或没有记录，s将保持零值状态
+		return s
+	})
+}
+
+// BeforeSave GORM钩子，没有指定 Hour 时使用创建时间所在的小时
+func (s *Statistics) BeforeSave(tx *gorm.DB) error {
+	if s.Hour == "" {
+		t := s.CreatedAt
+		if t.IsZero() {
+			t = time.Now()
+		}
+		s.Hour = t.Format(hourLayout)
+	}
+	return nil
+}
+
+// AfterSave GORM钩子，在创建或更新统计数据后清除统计缓存
+func (s *Statistics) AfterSave(tx *gorm.DB) error {
+	InvalidateStatistics()
+	return nil
+}
+
+// AfterDelete GORM钩子，在删除统计数据后清除统计缓存
+func (s *Statistics) AfterDelete(tx *gorm.DB) error {
+	InvalidateStatistics()
+	return nil
 }
 
 // CPUTmpl 将CPU使用率转换为HTML模板格式
@@ -244,3 +289,59 @@ func (s *Statistics) NewMembersTmpl() template.HTML {
 	// template.HTML确保字符串在HTML模板中不会被转义
 	return template.HTML(strconv.Itoa(int(s.NewMembers)))
 }
+
+// StatisticsSummary 统计数据汇总结果
+// 该结构体保存某个时间区间内统计数据的聚合值，用于仪表板的日期范围筛选
+type StatisticsSummary struct {
+	// Sales 区间内销售额合计
+	Sales float64 `gorm:"column:sales"`
+
+	// Likes 区间内点赞数合计
+	Likes float64 `gorm:"column:likes"`
+
+	// NewMembers 区间内新会员数合计
+	NewMembers float64 `gorm:"column:new_members"`
+
+	// CPU 区间内CPU使用率的平均值
+	CPU float64 `gorm:"column:cpu"`
+}
+
+// statisticsTimeLayout 统计表时间字段的存储格式
+// SQLite 中 created_at 以文本形式保存，按该格式比较可以得到正确的时间顺序
+const statisticsTimeLayout = "2006-01-02 15:04:05"
+
+// SummarizeStatistics 汇总指定时间区间内的统计数据
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//
+// 返回值:
+//   - StatisticsSummary: 区间内各项指标的聚合值，没有数据时各字段为零值
+//
+// 使用示例:
+//
+//	from := time.Now().AddDate(0, 0, -30)
+//	summary := models.SummarizeStatistics(ctx, from, time.Now())
+//	fmt.Printf("近30天销售额: %.0f\n", summary.Sales)
+//
+// 注意事项:
+//   - 与 FirstStatics 一致，查询失败时返回零值而不是错误
+//   - 结果按时间区间缓存，缓存有效期为 DefaultCacheTTL
+func SummarizeStatistics(ctx context.Context, from, to time.Time) StatisticsSummary {
+	key := statisticsCachePrefix + "summary:" + from.Format(statisticsTimeLayout) + ":" + to.Format(statisticsTimeLayout)
+	return remember(ctx, key, func() StatisticsSummary {
+		var summary StatisticsSummary
+
+		// SUM/AVG 在没有匹配行时返回 NULL，使用 COALESCE 转换为 0
+		// 按记录所属的小时筛选，from 所在小时的记录也计入区间
+		reader(ctx).Model(&Statistics{}).
+			Select("COALESCE(SUM(sales), 0) AS sales, COALESCE(SUM(likes), 0) AS likes, "+
+				"COALESCE(SUM(new_members), 0) AS new_members, COALESCE(AVG(cpu), 0) AS cpu").
+			Where("hour BETWEEN ? AND ?", from.Format(hourLayout), to.Format(statisticsTimeLayout)).
+			Scan(&summary)
+
+		return summary
+	})
+}
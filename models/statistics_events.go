@@ -0,0 +1,161 @@
+// models 包 - 数据模型层
+// 本文件实现其他服务推送统计计数的写入方法
+// 每次推送在 statistics_events 表保存一条明细，同时原子地累加到当前小时的 statistics 记录，
+// 仪表板立即可以看到；汇总任务之后按明细重新计算，两者结果一致
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 可以推送的统计指标，同时是 statistics 表中的字段名
+const (
+	// MetricLikes 点赞数
+	MetricLikes = "likes"
+
+	// MetricSales 销售额
+	MetricSales = "sales"
+
+	// MetricNewMembers 新会员数
+	MetricNewMembers = "new_members"
+)
+
+// ErrInvalidIncrement 推送的增量为负数，或点赞数、新会员数不是整数
+var ErrInvalidIncrement = errors.New("无效的统计增量")
+
+// StatisticsEvent 推送的统计增量明细
+type StatisticsEvent struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Metric 指标，MetricLikes、MetricSales 或 MetricNewMembers
+	Metric string `gorm:"column:metric"`
+
+	// Value 增量
+	Value float64 `gorm:"column:value"`
+
+	// CreatedAt 推送时间，决定增量计入哪个小时
+	CreatedAt time.Time
+}
+
+// TableName 指定 StatisticsEvent 对应的数据库表名
+func (StatisticsEvent) TableName() string {
+	return "statistics_events"
+}
+
+// StatisticsIncrement 一次推送的各项增量，为 0 的指标不写入
+type StatisticsIncrement struct {
+	// Likes 点赞数增量
+	Likes float64 `json:"likes"`
+
+	// Sales 销售额增量
+	Sales float64 `json:"sales"`
+
+	// NewMembers 新会员数增量
+	NewMembers float64 `json:"new_members"`
+}
+
+// IncrementStatistics 在一个事务中写入各项增量
+//
+// 参数:
+//   - ctx: 上下文
+//   - inc: 各项增量，不能为负数，点赞数和新会员数必须是整数
+//
+// 返回值:
+//   - error: 增量无效时返回 ErrInvalidIncrement，写入失败时返回数据库错误，失败时所有指标都不会写入
+//
+// 使用示例:
+//
+//	err := models.IncrementStatistics(ctx, models.StatisticsIncrement{Sales: 199.5, NewMembers: 1})
+//
+// 注意事项:
+//   - 累加通过 UPDATE statistics SET likes = likes + ? 完成，并发推送不会丢失计数
+//   - statistics 表的销售额为整数，每次累加时四舍五入，下一次汇总按明细的合计重新取整
+func IncrementStatistics(ctx context.Context, inc StatisticsIncrement) error {
+	values := []struct {
+		metric string
+		value  float64
+	}{
+		{MetricLikes, inc.Likes},
+		{MetricSales, inc.Sales},
+		{MetricNewMembers, inc.NewMembers},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return fmt.Errorf("%w: %s 不能为负数 %v", ErrInvalidIncrement, v.metric, v.value)
+		}
+		if v.metric != MetricSales && v.value != math.Trunc(v.value) {
+			return fmt.Errorf("%w: %s 必须是整数 %v", ErrInvalidIncrement, v.metric, v.value)
+		}
+	}
+
+	now := time.Now()
+	err := Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		for _, v := range values {
+			if v.value == 0 {
+				continue
+			}
+			if err := writer(ctx).Create(&StatisticsEvent{Metric: v.metric, Value: v.value, CreatedAt: now}).Error; err != nil {
+				return err
+			}
+			if err := incrementHour(ctx, now, v.metric, uint(math.Round(v.value))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	InvalidateStatistics()
+	return nil
+}
+
+// incrementHour 将 metric 字段在 t 所在小时的记录上累加 n，该小时没有记录时新建
+func incrementHour(ctx context.Context, t time.Time, metric string, n uint) error {
+	s := Statistics{Hour: t.Format(hourLayout), UpdatedAt: t}
+	s.CreatedAt, _ = time.ParseInLocation(hourLayout, s.Hour, time.Local)
+	switch metric {
+	case MetricLikes:
+		s.Likes = n
+	case MetricSales:
+		s.Sales = n
+	case MetricNewMembers:
+		s.NewMembers = n
+	}
+
+	// metric 只来自本文件的常量，可以安全地拼接到 SQL 中
+	// 字段加上表名限定，SQL Server 的 MERGE 语句中源数据与目标表的字段同名
+	// 跳过 Statistics 的钩子，事务提交后再清除缓存
+	return writer(ctx).Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			metric:       gorm.Expr("statistics."+metric+" + ?", n),
+			"updated_at": t,
+		}),
+	}).Create(&s).Error
+}
+
+// IncrementLikes 增加当前小时的点赞数
+func IncrementLikes(ctx context.Context, n int) error {
+	return IncrementStatistics(ctx, StatisticsIncrement{Likes: float64(n)})
+}
+
+// RecordSale 记录一笔销售额
+func RecordSale(ctx context.Context, amount float64) error {
+	return IncrementStatistics(ctx, StatisticsIncrement{Sales: amount})
+}
+
+// AddNewMembers 增加当前小时的新会员数
+func AddNewMembers(ctx context.Context, n int) error {
+	return IncrementStatistics(ctx, StatisticsIncrement{NewMembers: float64(n)})
+}
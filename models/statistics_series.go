@@ -0,0 +1,322 @@
+// models 包 - 数据模型层
+// 本文件实现统计数据的按小时汇总任务和时间序列查询
+// statistics 表每小时一行，后台任务定期把订单、会员明细和推送的增量汇总到对应的小时，
+// 仪表板的销售折线图再按小时、天、周或月读取连续的时间序列
+
+package models
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GranularityHour 按小时划分时间序列
+// 只用于 SalesSeries，环比计算不支持该粒度
+const GranularityHour Granularity = "hour"
+
+// hourLayout statistics 表 hour 字段的格式
+const hourLayout = "2006-01-02 15:00:00"
+
+// statisticsRollup 一项由明细表汇总得到的统计字段
+type statisticsRollup struct {
+	// column statistics 表中的字段
+	column string
+
+	// source 明细表，必须包含 created_at 字段
+	source string
+
+	// value 每小时的聚合表达式，各数据库通用
+	value string
+
+	// where 明细表的筛选条件，为空时汇总整个表
+	where string
+}
+
+// statisticsRollups 汇总任务写入的字段
+// 同一字段有多个来源时按小时相加；CPU 等没有明细表的字段保持写入时的值
+var statisticsRollups = []statisticsRollup{
+	{column: "sales", source: "orders", value: "SUM(amount)"},
+	// user_locations 每个会员一条记录，创建时间即注册时间
+	{column: "new_members", source: "user_locations", value: "COUNT(*)"},
+	// 其他服务推送的增量（见 statistics_events.go）
+	{column: "likes", source: "statistics_events", value: "SUM(value)", where: "metric = '" + MetricLikes + "'"},
+	{column: "sales", source: "statistics_events", value: "SUM(value)", where: "metric = '" + MetricSales + "'"},
+	{column: "new_members", source: "statistics_events", value: "SUM(value)", where: "metric = '" + MetricNewMembers + "'"},
+}
+
+// RollupHourlyStatistics 将明细表中 since 之后的记录按小时汇总到 statistics 表
+//
+// 参数:
+//   - ctx: 上下文
+//   - since: 从该时间所在的小时开始重新汇总
+//
+// 返回值:
+//   - error: 数据库写入失败时返回错误
+//
+// 注意事项:
+//   - 小时取 created_at 的前 13 位，即写入时的本地时间，不做时区换算
+//   - 已存在的小时只覆盖汇总的字段；所有来源在某小时都没有记录时，该小时的字段保持原值
+//   - 可以重复执行，执行后清除统计缓存
+func RollupHourlyStatistics(ctx context.Context, since time.Time) error {
+	bucket := datePrefix("created_at", len("2006-01-02 15"))
+
+	// 字段 -> 小时 -> 各来源的合计，columns 保持字段第一次出现的顺序
+	var columns []string
+	totals := make(map[string]map[string]float64)
+	for _, r := range statisticsRollups {
+		// source、value、where 只来自 statisticsRollups，可以安全地拼接到 SQL 中
+		// 与写入一样使用主库，汇总结果不受副本复制延迟的影响
+		var rows []struct {
+			Bucket string  `gorm:"column:bucket"`
+			Value  float64 `gorm:"column:value"`
+		}
+		q := orm.WithContext(ctx).Table(r.source).
+			Select(bucket+" AS bucket, "+r.value+" AS value").
+			Where("created_at >= ?", since.Format(hourLayout))
+		if r.where != "" {
+			q = q.Where(r.where)
+		}
+		if err := q.Group(bucket).Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		if _, ok := totals[r.column]; !ok {
+			columns = append(columns, r.column)
+			totals[r.column] = make(map[string]float64)
+		}
+		for _, row := range rows {
+			totals[r.column][row.Bucket] += row.Value
+		}
+	}
+
+	for _, column := range columns {
+		buckets := totals[column]
+		if len(buckets) == 0 {
+			continue
+		}
+
+		// 新建的小时其余字段为 0，已存在的小时只覆盖本次汇总的字段
+		// statistics 的字段为整数，汇总后四舍五入
+		now := time.Now()
+		stats := make([]Statistics, 0, len(buckets))
+		for b, v := range buckets {
+			s := Statistics{Hour: b + ":00:00", UpdatedAt: now}
+			s.CreatedAt, _ = time.ParseInLocation(hourLayout, s.Hour, time.Local)
+			value := uint(math.Round(v))
+			switch column {
+			case "sales":
+				s.Sales = value
+			case "new_members":
+				s.NewMembers = value
+			case "likes":
+				s.Likes = value
+			}
+			stats = append(stats, s)
+		}
+		// 跳过 Statistics 的钩子，汇总结束后统一清除一次缓存
+		err := orm.WithContext(ctx).Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "hour"}},
+			DoUpdates: clause.AssignmentColumns([]string{column, "updated_at"}),
+		}).CreateInBatches(&stats, rollupBatchSize).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	InvalidateStatistics()
+	return nil
+}
+
+// StartStatisticsRollup 启动统计数据的后台汇总任务
+//
+// 参数:
+//   - ctx: 上下文，取消后停止汇总任务
+//   - interval: 汇总间隔
+//
+// 功能说明:
+//  1. 启动时汇总最近一年的数据
+//  2. 之后每隔 interval 重新汇总上一小时和当前小时
+//
+// 使用示例:
+//
+//	models.StartStatisticsRollup(context.Background(), 5*time.Minute)
+//
+// 注意事项:
+//   - 必须在 Init 之后调用
+//   - 汇总失败只记录日志，下一次执行时会重试
+func StartStatisticsRollup(ctx context.Context, interval time.Duration) {
+	rollup := func(since time.Time) {
+		if err := RollupHourlyStatistics(ctx, since); err != nil {
+			log.Printf("汇总每小时统计数据失败: %s\n", err)
+		}
+	}
+
+	rollup(time.Now().AddDate(-1, 0, 0))
+
+	RunWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rollup(time.Now().Add(-time.Hour))
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// SeriesPoint 时间序列中的一个点
+type SeriesPoint struct {
+	// Start 该点所在周期的开始时间
+	Start time.Time
+
+	// Label 坐标轴标签，格式随粒度变化，如 15:00、01-02、2006-01
+	Label string
+
+	// Sales 周期内的销售额合计
+	Sales float64
+}
+
+// ParseSeriesGranularity 解析时间序列的粒度参数
+// 支持 hour、day、week、month，无法识别时返回 GranularityRange，由 SalesSeries 按区间长度选择
+func ParseSeriesGranularity(s string) Granularity {
+	switch g := Granularity(s); g {
+	case GranularityHour, GranularityDay, GranularityWeek, GranularityMonth:
+		return g
+	}
+	return GranularityRange
+}
+
+// SeriesGranularityFor 按区间长度选择合适的时间序列粒度
+// 两天以内按小时，约三个月以内按天，一年以内按周，更长按月，避免折线图的点过多或过少
+func SeriesGranularityFor(from, to time.Time) Granularity {
+	switch span := to.Sub(from); {
+	case span <= 48*time.Hour:
+		return GranularityHour
+	case span <= 92*24*time.Hour:
+		return GranularityDay
+	case span <= 366*24*time.Hour:
+		return GranularityWeek
+	}
+	return GranularityMonth
+}
+
+// periodStart 返回 t 所在周期的开始时间，周以周一为第一天
+func periodStart(t time.Time, g Granularity) time.Time {
+	switch g {
+	case GranularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod 返回 start 之后下一个周期的开始时间
+func nextPeriod(start time.Time, g Granularity) time.Time {
+	switch g {
+	case GranularityHour:
+		return start.Add(time.Hour)
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// periodLabel 返回周期的坐标轴标签
+// 按小时的序列跨天时带上日期，按周的序列使用周一的日期
+func periodLabel(start time.Time, g Granularity, multiDay bool) string {
+	switch g {
+	case GranularityHour:
+		if multiDay {
+			return start.Format("01-02 15:00")
+		}
+		return start.Format("15:00")
+	case GranularityMonth:
+		return start.Format("2006-01")
+	}
+	return start.Format("01-02")
+}
+
+// SalesSeries 按粒度返回指定时间区间内连续的销售额序列
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//   - g: 粒度，GranularityHour、GranularityDay、GranularityWeek 或 GranularityMonth，
+//     传入 GranularityRange 时由 SeriesGranularityFor 选择
+//
+// 返回值:
+//   - []SeriesPoint: 按时间升序排列，没有数据的周期补 0，保证折线图的 X 轴连续
+//   - Granularity: 实际使用的粒度
+//
+// 使用示例:
+//
+//	points, _ := models.SalesSeries(ctx, from, to, models.GranularityDay)
+//	for _, p := range points {
+//	    fmt.Printf("%s: %.0f\n", p.Label, p.Sales)
+//	}
+//
+// 注意事项:
+//   - 第一个和最后一个周期可能只有一部分落在区间内，只统计区间内的小时
+//   - 结果按区间和粒度缓存，统计数据写入或汇总后失效
+func SalesSeries(ctx context.Context, from, to time.Time, g Granularity) ([]SeriesPoint, Granularity) {
+	if g == GranularityRange || g == "" {
+		g = SeriesGranularityFor(from, to)
+	}
+
+	key := statisticsCachePrefix + "series:" + string(g) + ":" + from.Format(statisticsTimeLayout) + ":" + to.Format(statisticsTimeLayout)
+	points := remember(ctx, key, func() []SeriesPoint {
+		// 按小时的序列直接读取每小时的记录，其他粒度先按天汇总，再在内存中归入周或月
+		bucketLayout := "2006-01-02"
+		if g == GranularityHour {
+			bucketLayout = "2006-01-02 15"
+		}
+		bucket := datePrefix("hour", len(bucketLayout))
+
+		var rows []struct {
+			Bucket string  `gorm:"column:bucket"`
+			Sales  float64 `gorm:"column:sales"`
+		}
+		reader(ctx).Model(&Statistics{}).
+			Select(bucket+" AS bucket, COALESCE(SUM(sales), 0) AS sales").
+			Where("hour BETWEEN ? AND ?", from.Format(hourLayout), to.Format(statisticsTimeLayout)).
+			Group(bucket).
+			Scan(&rows)
+
+		salesByPeriod := make(map[int64]float64, len(rows))
+		for _, r := range rows {
+			t, err := time.ParseInLocation(bucketLayout, r.Bucket, from.Location())
+			if err != nil {
+				continue
+			}
+			salesByPeriod[periodStart(t, g).Unix()] += r.Sales
+		}
+
+		multiDay := !periodStart(from, GranularityDay).Equal(periodStart(to, GranularityDay))
+		var series []SeriesPoint
+		for start := periodStart(from, g); !start.After(to); start = nextPeriod(start, g) {
+			series = append(series, SeriesPoint{
+				Start: start,
+				Label: periodLabel(start, g, multiDay),
+				Sales: salesByPeriod[start.Unix()],
+			})
+		}
+		return series
+	})
+
+	return points, g
+}
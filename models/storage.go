@@ -0,0 +1,110 @@
+// models 包 - 数据模型层
+// 本文件定义上传文件的存储：保存在本地上传目录或 S3 兼容的对象存储中，按配置选择
+// 头像缩略图、表单上传的文件和富文本编辑器中插入的图片都通过它保存
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// 上传文件的存储方式，对应 config.yml 中 upload、avatar 配置项的 storage
+const (
+	// StorageLocal 保存在本地上传目录（store.path）中，通过 /uploads 路由访问
+	StorageLocal = "local"
+	// StorageS3 上传到 S3 或兼容 S3 接口的对象存储（如 MinIO），需要存储桶允许公开读取
+	StorageS3 = "s3"
+)
+
+// S3Config S3 兼容对象存储的连接配置
+type S3Config struct {
+	// Endpoint 服务地址，如 https://minio.example.com:9000；为空时使用 AWS 对应区域的地址
+	Endpoint string `yaml:"endpoint"`
+
+	// Region 区域，用于请求签名，MinIO 通常为 us-east-1
+	Region string `yaml:"region"`
+
+	// Bucket 存储桶名称，对象按路径风格（Endpoint/Bucket/Key）访问
+	Bucket string `yaml:"bucket"`
+
+	// AccessKey、SecretKey 访问密钥，为空时读取环境变量 AWS_ACCESS_KEY_ID 和 AWS_SECRET_ACCESS_KEY
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+
+	// PublicURL 文件的访问地址前缀，如 CDN 地址；为空时使用 Endpoint/Bucket
+	PublicURL string `yaml:"public_url"`
+}
+
+// UploadConfig 表单上传文件的存储配置，对应 config.yml 的 upload 配置项
+type UploadConfig struct {
+	// Storage 存储方式：local 或 s3，为空时为 local
+	Storage string `yaml:"storage"`
+
+	// MaxSize 单个文件的最大大小，单位为 MB
+	MaxSize int `yaml:"max_size"`
+
+	// S3 Storage 为 s3 时的对象存储配置
+	S3 S3Config `yaml:"s3"`
+}
+
+// DefaultUploadConfig 配置文件中没有 upload 配置项时使用的默认配置
+var DefaultUploadConfig = UploadConfig{
+	Storage: StorageLocal,
+	MaxSize: 10,
+}
+
+// Storage 上传文件的存储
+type Storage interface {
+	// Put 保存路径为 key 的文件，key 为以 / 分隔的相对路径，如 posts/xxx.png
+	// 返回保存的地址：本地存储为上传目录中的相对路径，S3 为完整的 URL，两者都可以交给 config.Store.URL 生成访问地址
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+}
+
+// NewStorage 按存储方式创建存储
+//
+// 参数:
+//   - storage: 存储方式，StorageLocal 或 StorageS3，为空时为 StorageLocal
+//   - s3: storage 为 StorageS3 时的对象存储配置
+//   - dir: 本地上传目录，即 config.yml 的 store.path
+//   - section: 配置项的名称，如 upload，用于错误信息
+//
+// 返回值:
+//   - Storage: 本地或 S3 存储
+//   - error: 存储方式未知，或 S3 缺少存储桶、区域、密钥时返回错误
+func NewStorage(storage string, s3 S3Config, dir, section string) (Storage, error) {
+	switch storage {
+	case "", StorageLocal:
+		return localStorage{dir: dir}, nil
+	case StorageS3:
+		return newS3Storage(s3, section)
+	default:
+		return nil, fmt.Errorf("%s 配置的 storage 应为 %s 或 %s: %s", section, StorageLocal, StorageS3, storage)
+	}
+}
+
+// localStorage 保存在本地上传目录中的文件
+type localStorage struct {
+	dir string
+}
+
+// Put 写入上传目录，key 中的子目录不存在时创建
+func (s localStorage) Put(_ context.Context, key, _ string, data []byte) (string, error) {
+	// 去掉 .. 等路径成分，文件只能写在上传目录中
+	key = path.Clean("/" + key)[1:]
+	if key == "" {
+		return "", errors.New("无效的文件路径")
+	}
+	name := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,144 @@
+// models 包 - 数据模型层
+// 本文件实现上传文件的 S3 存储：用 AWS Signature Version 4 签名的 PUT 请求上传对象
+// 只需要上传单个对象，没有引入完整的 AWS SDK；兼容 MinIO 等实现了 S3 接口的对象存储
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3UploadTimeout 上传一个文件的最长时间
+const s3UploadTimeout = 30 * time.Second
+
+// s3Storage 上传到 S3 存储桶的文件
+type s3Storage struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+// newS3Storage 检查 S3 配置，补全默认的服务地址、访问地址和环境变量中的密钥
+// section 为配置项的名称，如 upload，用于错误信息
+func newS3Storage(cfg S3Config, section string) (*s3Storage, error) {
+	if cfg.AccessKey == "" {
+		cfg.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.SecretKey == "" {
+		cfg.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	switch {
+	case cfg.Bucket == "":
+		return nil, fmt.Errorf("使用 S3 存储时需要配置 %s.s3.bucket", section)
+	case cfg.Region == "":
+		return nil, fmt.Errorf("使用 S3 存储时需要配置 %s.s3.region", section)
+	case cfg.AccessKey == "" || cfg.SecretKey == "":
+		return nil, fmt.Errorf("使用 S3 存储时需要配置 %s.s3 的密钥或环境变量 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY", section)
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
+	return &s3Storage{cfg: cfg, client: &http.Client{Timeout: s3UploadTimeout}}, nil
+}
+
+// Put 上传到存储桶，返回公开访问的 URL
+// 文件名由调用方生成、不会重复，响应头允许浏览器和 CDN 长期缓存
+func (s *s3Storage) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.cfg.Endpoint+"/"+s.cfg.Bucket+"/"+key, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	signS3Request(req, data, s.cfg, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("上传到 S3 失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("上传到 S3 失败: %s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return s.cfg.PublicURL + "/" + key, nil
+}
+
+// signS3Request 按 AWS Signature Version 4 为请求添加 Authorization 头
+// 签名包含 Host 和请求中已经设置的全部请求头，请求体的 SHA-256 写入 X-Amz-Content-Sha256
+func signS3Request(req *http.Request, payload []byte, cfg S3Config, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), day)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,200 @@
+// models 包 - 数据模型层
+// 本文件实现系统资源的采集任务和查询
+// 后台任务定期采样主机的 CPU、内存使用率和本进程的协程数，
+// 按小时取平均值写入 statistics 表，与销售额等指标共用同一个时间序列
+
+package models
+
+import (
+	"context"
+	"log"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"gorm.io/gorm/clause"
+)
+
+// systemSample 一次系统资源采样
+type systemSample struct {
+	cpu        float64
+	memory     float64
+	goroutines float64
+}
+
+// sampleSystem 采样当前的系统资源
+// CPU 使用率为距上一次采样（第一次为进程启动）以来所有核心的平均值
+func sampleSystem(ctx context.Context) (systemSample, error) {
+	percents, err := cpu.PercentWithContext(ctx, 0, false)
+	if err != nil {
+		return systemSample{}, err
+	}
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return systemSample{}, err
+	}
+
+	s := systemSample{memory: vm.UsedPercent, goroutines: float64(runtime.NumGoroutine())}
+	if len(percents) > 0 {
+		s.cpu = percents[0]
+	}
+	return s, nil
+}
+
+// hourlySamples 当前小时内采样的累计值，用于计算平均值
+type hourlySamples struct {
+	hour  string
+	count int
+	sum   systemSample
+}
+
+// add 累计一次采样并返回该小时的平均值，进入新的小时时重新开始累计
+func (h *hourlySamples) add(hour string, s systemSample) systemSample {
+	if hour != h.hour {
+		*h = hourlySamples{hour: hour}
+	}
+	h.count++
+	h.sum.cpu += s.cpu
+	h.sum.memory += s.memory
+	h.sum.goroutines += s.goroutines
+
+	n := float64(h.count)
+	return systemSample{cpu: h.sum.cpu / n, memory: h.sum.memory / n, goroutines: h.sum.goroutines / n}
+}
+
+// saveSystemStatistics 将小时的系统资源平均值写入 statistics 表
+// 已存在的小时只覆盖系统资源字段，销售额等由汇总任务写入的字段保持不变
+func saveSystemStatistics(ctx context.Context, hour string, avg systemSample) error {
+	s := Statistics{
+		Hour:       hour,
+		CPU:        uint(math.Round(avg.cpu)),
+		Memory:     uint(math.Round(avg.memory)),
+		Goroutines: uint(math.Round(avg.goroutines)),
+		UpdatedAt:  time.Now(),
+	}
+	s.CreatedAt, _ = time.ParseInLocation(hourLayout, hour, time.Local)
+
+	// 保存后 Statistics 的钩子会清除统计缓存，仪表板随即显示新的采样结果
+	return orm.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hour"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cpu", "memory", "goroutines", "updated_at"}),
+	}).Create(&s).Error
+}
+
+// StartSystemStatsCollector 启动系统资源的后台采集任务
+//
+// 参数:
+//   - ctx: 上下文，取消后停止采集
+//   - interval: 采样间隔
+//
+// 功能说明:
+//  1. 每隔 interval 采样一次 CPU、内存使用率和协程数
+//  2. 当前小时内的采样取平均值，写入该小时的 cpu、memory、goroutines 字段
+//
+// 使用示例:
+//
+//	models.StartSystemStatsCollector(context.Background(), time.Minute)
+//
+// 注意事项:
+//   - 必须在 Init 之后调用
+//   - 平均值只在进程内累计，进程在一小时中间重启时，该小时的值只包含重启后的采样
+//   - 多个实例共用一个数据库时各自覆盖同一小时的记录，仪表板显示的是最后写入的实例
+//   - 采样或写入失败只记录日志，不影响下一次采样
+func StartSystemStatsCollector(ctx context.Context, interval time.Duration) {
+	RunWorker(func() {
+		var samples hourlySamples
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s, err := sampleSystem(ctx)
+				if err != nil {
+					log.Printf("采集系统资源失败: %s\n", err)
+					continue
+				}
+				hour := time.Now().Format(hourLayout)
+				if err := saveSystemStatistics(ctx, hour, samples.add(hour, s)); err != nil {
+					log.Printf("写入系统资源统计失败: %s\n", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// LatestSystemStatistics 返回系统资源采集任务最近写入的一小时
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//
+// 返回值:
+//   - *Statistics: 最近一个有采样的小时，没有记录时返回零值
+//
+// 注意事项:
+//   - 采集任务写入的小时协程数至少为 1，以此区分只有汇总数据的小时
+func LatestSystemStatistics(ctx context.Context) *Statistics {
+	return remember(ctx, statisticsCachePrefix+"system:latest", func() *Statistics {
+		s := new(Statistics)
+		reader(ctx).Where("goroutines > 0").Order("hour DESC").First(s)
+		return s
+	})
+}
+
+// SystemPoint 系统资源时间序列中的一个点
+type SystemPoint struct {
+	// Start 该点所在的小时
+	Start time.Time
+
+	// Label 坐标轴标签，区间跨天时带上日期
+	Label string
+
+	// CPU、Memory 该小时 CPU 和内存使用率的平均值（百分比）
+	CPU    float64
+	Memory float64
+
+	// Goroutines 该小时协程数的平均值
+	Goroutines float64
+}
+
+// SystemSeries 返回时间区间内按小时的系统资源序列
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//
+// 返回值:
+//   - []SystemPoint: 按时间升序排列，只包含有采样的小时
+//
+// 注意事项:
+//   - 与 SalesSeries 不同，没有采样的小时不补 0，避免把进程未运行的时段显示为空闲
+func SystemSeries(ctx context.Context, from, to time.Time) []SystemPoint {
+	key := statisticsCachePrefix + "system:series:" + from.Format(statisticsTimeLayout) + ":" + to.Format(statisticsTimeLayout)
+	return remember(ctx, key, func() []SystemPoint {
+		var rows []Statistics
+		reader(ctx).Where("goroutines > 0 AND hour BETWEEN ? AND ?", from.Format(hourLayout), to.Format(statisticsTimeLayout)).
+			Order("hour").
+			Find(&rows)
+
+		multiDay := !periodStart(from, GranularityDay).Equal(periodStart(to, GranularityDay))
+		points := make([]SystemPoint, 0, len(rows))
+		for _, r := range rows {
+			start, err := time.ParseInLocation(hourLayout, r.Hour, from.Location())
+			if err != nil {
+				continue
+			}
+			points = append(points, SystemPoint{
+				Start:      start,
+				Label:      periodLabel(start, GranularityHour, multiDay),
+				CPU:        float64(r.CPU),
+				Memory:     float64(r.Memory),
+				Goroutines: float64(r.Goroutines),
+			})
+		}
+		return points
+	})
+}
@@ -0,0 +1,155 @@
+// models 包 - 数据模型层
+// 本文件定义标签模型，文章和标签通过 post_tags 表多对多关联
+// tags 表通过 tables.GetTagsTable 在管理后台维护，文章表单中可以为文章选择多个标签
+
+package models
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tag 标签模型
+type Tag struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 标签名称
+	Name string `gorm:"column:name"`
+
+	// Color 标签的颜色，如 "#3c8dbc"，文章列表中的标签按该颜色显示
+	Color string `gorm:"column:color"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Tag 对应的数据库表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// PostTag 文章与标签的关联，一行表示文章带有一个标签
+type PostTag struct {
+	// PostID 文章编号，对应 posts.id
+	PostID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	// TagID 标签编号，对应 tags.id
+	TagID uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// TableName 指定 PostTag 对应的数据库表名
+func (PostTag) TableName() string {
+	return "post_tags"
+}
+
+// defaultTags 首次创建 tags 表时写入的示例标签
+var defaultTags = []Tag{
+	{Name: "新闻", Color: "#3c8dbc"},
+	{Name: "教程", Color: "#00a65a"},
+	{Name: "公告", Color: "#f39c12"},
+	{Name: "随笔", Color: "#605ca8"},
+}
+
+// AllTags 获取全部标签，按编号升序排列，查询失败时返回空列表
+// 从主库读取，管理员新增标签后在文章表单中立即可以选择
+func AllTags(ctx context.Context) []Tag {
+	var tags []Tag
+	orm.WithContext(ctx).Order("id").Find(&tags)
+	return tags
+}
+
+// PostTags 获取文章的标签，按标签编号升序排列，查询失败时返回空列表
+func PostTags(ctx context.Context, postID string) []Tag {
+	var tags []Tag
+	orm.WithContext(ctx).
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id = ?", postID).
+		Order("tags.id").
+		Find(&tags)
+	return tags
+}
+
+// SetPostTags 将文章的标签替换为 tagIDs
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行，与文章的写入一起提交或回滚
+//   - postID: 文章编号
+//   - tagIDs: 标签编号，空字符串和重复的编号会被忽略，为空时清空文章的标签
+//
+// 返回值:
+//   - error: 编号不是数字时返回解析错误，写入失败时返回数据库错误
+//
+// 使用示例:
+//
+//	err := models.SetPostTags(ctx, "3", []string{"1", "2"})
+func SetPostTags(ctx context.Context, postID string, tagIDs []string) error {
+	pid, err := strconv.ParseUint(postID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]PostTag, 0, len(tagIDs))
+	seen := make(map[uint64]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		if id == "" {
+			continue
+		}
+		tid, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return err
+		}
+		if seen[tid] {
+			continue
+		}
+		seen[tid] = true
+		rows = append(rows, PostTag{PostID: uint(pid), TagID: uint(tid)})
+	}
+
+	db := writer(ctx)
+	if err := db.Where("post_id = ?", pid).Delete(&PostTag{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Create(&rows).Error
+}
+
+// seedTags 在 tags 表为空时写入示例标签
+func seedTags(ctx context.Context) error {
+	var count int64
+	if err := orm.WithContext(ctx).Model(&Tag{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	for i := range defaultTags {
+		t := defaultTags[i]
+		if err := orm.WithContext(ctx).Create(&t).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTags 删除标签，并在同一个事务中删除文章与这些标签的关联
+//
+// 参数:
+//   - ctx: 上下文
+//   - ids: 要删除的标签编号
+//
+// 返回值:
+//   - error: 删除失败时返回数据库错误，此时标签和关联都不会被删除
+func DeleteTags(ctx context.Context, ids []string) error {
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("tag_id IN ?", ids).Delete(&PostTag{}).Error; err != nil {
+			return err
+		}
+		return writer(ctx).Where("id IN ?", ids).Delete(&Tag{}).Error
+	})
+}
@@ -0,0 +1,165 @@
+// models 包 - 数据模型层
+// 本文件定义任务模型
+// 任务在任务表格（tables.GetTasksTable）中维护，看板页面（pages.KanbanPage）按状态分列显示，拖动卡片即修改状态
+
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 任务状态，与 tasks 表 status 字段中保存的值一致
+const (
+	// TaskTodo 待处理
+	TaskTodo = "todo"
+
+	// TaskDoing 进行中
+	TaskDoing = "doing"
+
+	// TaskDone 已完成
+	TaskDone = "done"
+)
+
+// 任务优先级，与 tasks 表 priority 字段中保存的值一致，数值越大越优先
+const (
+	// TaskPriorityLow 低
+	TaskPriorityLow = 1
+
+	// TaskPriorityNormal 中
+	TaskPriorityNormal = 2
+
+	// TaskPriorityHigh 高
+	TaskPriorityHigh = 3
+)
+
+// TaskStatuses 全部任务状态，顺序即看板中各列从左到右的顺序
+var TaskStatuses = []string{TaskTodo, TaskDoing, TaskDone}
+
+// ErrInvalidTaskStatus 任务状态不在 TaskStatuses 中
+var ErrInvalidTaskStatus = errors.New("任务状态无效")
+
+// Task 任务模型
+type Task struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Title 标题
+	Title string `gorm:"column:title"`
+
+	// AssigneeID 负责人的管理员 ID（goadmin_users.id），0 表示未分配
+	AssigneeID int64 `gorm:"column:assignee_id"`
+
+	// Status 状态，见 TaskTodo 等常量
+	Status string `gorm:"column:status"`
+
+	// Priority 优先级，见 TaskPriorityLow 等常量
+	Priority int `gorm:"column:priority"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+}
+
+// TableName 指定 Task 对应的数据库表名
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// TaskCard 看板上的一张任务卡片
+type TaskCard struct {
+	Task
+
+	// AssigneeName 负责人的名称，未分配或负责人已被删除时为空
+	AssigneeName string `gorm:"column:assignee_name"`
+}
+
+// ValidTaskStatus 判断 status 是否为有效的任务状态
+func ValidTaskStatus(status string) bool {
+	for _, s := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskBoard 读取全部任务，按状态分组
+//
+// 返回值:
+//   - map[string][]TaskCard: 以状态为键，每组按优先级从高到低、再按编号升序排列；状态无效的任务不包含在内
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 从主库读取，拖动卡片后刷新页面立即可以看到新的状态
+func TaskBoard(ctx context.Context) (map[string][]TaskCard, error) {
+	var cards []TaskCard
+	err := orm.WithContext(ctx).Table("tasks").
+		Select("tasks.*, goadmin_users.name AS assignee_name").
+		Joins("LEFT JOIN goadmin_users ON goadmin_users.id = tasks.assignee_id").
+		Order("tasks.priority DESC, tasks.id").
+		Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+	board := make(map[string][]TaskCard, len(TaskStatuses))
+	for _, c := range cards {
+		if ValidTaskStatus(c.Status) {
+			board[c.Status] = append(board[c.Status], c)
+		}
+	}
+	return board, nil
+}
+
+// MoveTask 修改任务的状态
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 任务编号
+//   - status: 新的状态
+//
+// 返回值:
+//   - error: 状态无效时返回 ErrInvalidTaskStatus，任务不存在时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+func MoveTask(ctx context.Context, id, status string) error {
+	if !ValidTaskStatus(status) {
+		return ErrInvalidTaskStatus
+	}
+	res := writer(ctx).Model(&Task{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// defaultTasks 首次创建 tasks 表时写入的示例任务
+var defaultTasks = []Task{
+	{Title: "整理本月的订单报表", Status: TaskTodo, Priority: TaskPriorityHigh},
+	{Title: "补充新商品的图片", Status: TaskTodo, Priority: TaskPriorityNormal},
+	{Title: "回复客户的退货咨询", Status: TaskDoing, Priority: TaskPriorityHigh},
+	{Title: "更新帮助文档", Status: TaskDoing, Priority: TaskPriorityLow},
+	{Title: "配置定时导出的收件人", Status: TaskDone, Priority: TaskPriorityNormal},
+}
+
+// seedTasks 在 tasks 表为空时写入示例任务
+func seedTasks(ctx context.Context) error {
+	var count int64
+	if err := orm.WithContext(ctx).Model(&Task{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	for i := range defaultTasks {
+		t := defaultTasks[i]
+		if err := orm.WithContext(ctx).Create(&t).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
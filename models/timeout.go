@@ -0,0 +1,90 @@
+// models 包 - 数据模型层
+// 本文件实现单条 SQL 语句的执行超时
+// 查询本身已经使用请求的上下文（客户端断开时随之取消），这里再为每条语句加上一个上限，
+// 防止客户端一直等待时慢查询长时间占用连接
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryTimeoutKey 语句超时在 Statement 中保存的键
+const queryTimeoutKey = "example:query_timeout"
+
+// noTimeoutKey 上下文中标记不设置语句超时的键
+type noTimeoutKey struct{}
+
+// withoutQueryTimeout 返回不设置语句超时的上下文
+// 用于启动时的迁移和示例数据，迁移中重建表等语句的耗时与数据量有关，不应受请求的超时限制
+func withoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+// queryTimeout 执行语句前替换的上下文，执行后用于释放计时器并还原原来的上下文
+type queryTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout 为 db 执行的每条语句设置超时
+//
+// 参数:
+//   - db: ORM 实例
+//   - timeout: 单条语句的最长执行时间，不大于 0 时不注册
+//
+// 返回值:
+//   - error: 注册回调失败时返回错误
+//
+// 注意事项:
+//   - 超时的上下文派生自语句原来的上下文，请求取消时语句同样会被取消
+//   - 执行后还原原来的上下文，同一个查询链继续执行下一条语句（如 Count 之后 Find）时重新计时
+//   - Row、Rows 返回的结果在回调之后才读取，不设置超时，仍受请求的上下文控制
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	before := func(tx *gorm.DB) {
+		if tx.Statement.Context.Value(noTimeoutKey{}) != nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+		tx.InstanceSet(queryTimeoutKey, queryTimeout{parent: tx.Statement.Context, cancel: cancel})
+		tx.Statement.Context = ctx
+	}
+	after := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(queryTimeoutKey)
+		if !ok {
+			return
+		}
+		t := v.(queryTimeout)
+		t.cancel()
+		tx.Statement.Context = t.parent
+	}
+
+	// 超时在其他回调之前设置、在所有回调之后释放，覆盖钩子中执行的语句
+	cb := db.Callback()
+	steps := []struct {
+		name          string
+		before, after func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	}
+	for _, step := range steps {
+		if err := step.before("example:query_timeout_before_"+step.name, before); err != nil {
+			return err
+		}
+		if err := step.after("example:query_timeout_after_"+step.name, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// models 包 - 数据模型层
+// 本文件实现跨多个写入操作的数据库事务
+// Transaction 把事务放入 ctx，本包的写入函数从 ctx 中取出事务执行，
+// 调用方不需要为每个函数准备接收 *gorm.DB 的版本
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// txKey ctx 中保存事务的键
+type txKey struct{}
+
+// Transaction 在一个数据库事务中执行 fn
+//
+// 参数:
+//   - ctx: 上下文
+//   - fn: 事务中执行的逻辑，收到的 ctx 携带事务，传给本包的写入函数（如 SaveRow、CreateAuditLogs）时
+//     这些函数在同一个事务中执行；也可以直接使用 tx 执行其他语句
+//
+// 返回值:
+//   - error: fn 返回的错误，或提交事务失败的错误；fn 返回错误或 panic 时回滚整个事务
+//
+// 使用示例:
+//
+//	err := models.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+//	    id, err := models.SaveRow(ctx, "goals", "id", "", row)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return models.CreateAuditLogs(ctx, []models.AuditLog{{Table: "goals", RecordID: id, Action: models.AuditCreate}})
+//	})
+//
+// 注意事项:
+//   - 事务只覆盖 ORM 的主库连接，GoAdmin 自身的数据库连接不在事务中
+//   - 在 fn 中嵌套调用 Transaction 时使用保存点，内层回滚不影响外层
+func Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return writer(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	})
+}
+
+// writer 返回执行写入的 ORM 实例：ctx 携带事务时返回该事务，否则返回主库
+func writer(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return orm.WithContext(ctx)
+}
+
+// SaveRow 按表名写入一行数据
+//
+// 参数:
+//   - ctx: 上下文，携带事务时在该事务中写入
+//   - table: 数据表名
+//   - pk: 主键字段名
+//   - id: 为空时新增记录，否则修改主键为 id 的记录
+//   - row: 字段名到值的映射，表中不存在的字段被忽略
+//
+// 返回值:
+//   - string: 写入记录的主键
+//   - error: 写入失败时返回错误
+//
+// 注意事项:
+//   - 新增时的主键由 insertRow 取回：PostgreSQL 和 SQLite 使用 RETURNING，SQL Server 使用 OUTPUT INSERTED，
+//     MySQL 使用驱动返回的自增 ID
+func SaveRow(ctx context.Context, table, pk, id string, row map[string]interface{}) (string, error) {
+	db := writer(ctx)
+
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[string]interface{}, len(row))
+	for _, c := range columns {
+		if v, ok := row[c.Name()]; ok && c.Name() != pk {
+			values[c.Name()] = v
+		}
+	}
+
+	if id != "" {
+		if len(values) == 0 {
+			return id, nil
+		}
+		return id, db.Table(table).Where(clause.Eq{Column: clause.Column{Name: pk}, Value: id}).Updates(values).Error
+	}
+
+	return insertRow(db, table, pk, values)
+}
+
+// insertRow 新增一行并返回数据库生成的主键
+//
+// 功能说明:
+//   - 按表名写入映射时 GORM 没有模型，不会自动添加 RETURNING，只能从驱动的 LastInsertId 取回主键；
+//     PostgreSQL（pgx）和 SQL Server 的驱动不支持 LastInsertId，这里按数据库分别处理
+//   - 支持 RETURNING 的方言（PostgreSQL、SQLite）由 insertQuery 添加 RETURNING 子句，主键写回映射的 pk 键；
+//     不支持的方言（MySQL）忽略该子句，GORM 把自增 ID 写回映射的 @id 键
+//   - GORM 的 SQL Server 方言写入映射时不生成 OUTPUT 子句，这里使用 outputInsertSQL 生成的语句
+func insertRow(db *gorm.DB, table, pk string, values map[string]interface{}) (string, error) {
+	if db.Dialector.Name() == "sqlserver" {
+		sql, args := outputInsertSQL(db, table, pk, values)
+		var id interface{}
+		if err := db.Raw(sql, args...).Row().Scan(&id); err != nil {
+			return "", err
+		}
+		return fmt.Sprint(id), nil
+	}
+
+	if err := insertQuery(db, table, pk, values).Error; err != nil {
+		return "", err
+	}
+	newID, ok := values[pk]
+	if !ok {
+		newID, ok = values["@id"]
+	}
+	if !ok {
+		return "", fmt.Errorf("%s 新增的记录没有返回主键", table)
+	}
+	return fmt.Sprint(newID), nil
+}
+
+// insertQuery 执行带 RETURNING 主键的新增语句，db 为 DryRun 会话时只生成 SQL
+func insertQuery(db *gorm.DB, table, pk string, values map[string]interface{}) *gorm.DB {
+	return db.Table(table).Clauses(clause.Returning{Columns: []clause.Column{{Name: pk}}}).Create(values)
+}
+
+// outputInsertSQL 生成 SQL Server 新增一行并通过 OUTPUT INSERTED 返回主键的语句，字段按名称排序
+func outputInsertSQL(db *gorm.DB, table, pk string, values map[string]interface{}) (string, []interface{}) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := " OUTPUT INSERTED." + db.Statement.Quote(pk)
+	if len(names) == 0 {
+		return "INSERT INTO " + db.Statement.Quote(table) + output + " DEFAULT VALUES", nil
+	}
+	columns := make([]string, len(names))
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		columns[i] = db.Statement.Quote(name)
+		args[i] = values[name]
+	}
+	return "INSERT INTO " + db.Statement.Quote(table) + " (" + strings.Join(columns, ",") + ")" + output +
+		" VALUES (" + strings.TrimSuffix(strings.Repeat("?,", len(names)), ",") + ")", args
+}
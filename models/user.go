@@ -0,0 +1,339 @@
+// models 包 - 数据模型层
+// 本文件定义用户模型
+// users 表由迁移创建，管理后台通过 tables.GetUserTable 查看和编辑，这里用于软删除和恢复、
+// CSV 导入（见 user_import.go）以及用户表单的服务端校验
+
+package models
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrEmptyPassword 重置密码时没有填写新密码
+var ErrEmptyPassword = errors.New("密码不能为空")
+
+// 用户的启用状态，对应 users.status
+const (
+	// UserDisabled 已禁用
+	UserDisabled = 0
+	// UserEnabled 已启用，新用户的默认状态
+	UserEnabled = 1
+)
+
+// User 用户模型
+// 该结构体映射到 users 表，DeletedAt 不为空的记录已被软删除
+type User struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// Name 用户名
+	Name string `gorm:"column:name"`
+
+	// Gender 性别：0 男，1 女
+	Gender int `gorm:"column:gender"`
+
+	// City 城市
+	City string `gorm:"column:city"`
+
+	// IP IP 地址
+	IP string `gorm:"column:ip"`
+
+	// Phone 电话号码
+	Phone string `gorm:"column:phone"`
+
+	// Email 邮箱，可以为空，非空时不能与其他用户重复
+	Email string `gorm:"column:email"`
+
+	// Password 密码的 bcrypt 哈希，为空表示未设置密码；列表、表单和审计日志中都不显示
+	Password string `gorm:"column:password"`
+
+	// Avatar 头像缩略图的地址，本地存储为上传目录中的相对路径，S3 存储为完整的 URL（见 avatar.go）
+	Avatar string `gorm:"column:avatar"`
+
+	// CreatedBy 创建该用户的管理员 ID，0 表示创建人未知（见 ownership.go）
+	CreatedBy int64 `gorm:"column:created_by"`
+
+	// Status 启用状态：UserEnabled 或 UserDisabled
+	Status int `gorm:"column:status"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间，由GORM自动填充
+	UpdatedAt time.Time
+
+	// DeletedAt 删除时间，GORM 的查询自动排除已删除的记录，Delete 只写入该字段
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_users_deleted_at"`
+}
+
+// TableName 指定 User 对应的数据库表名
+func (User) TableName() string {
+	return "users"
+}
+
+// FieldError 表单中一个字段的校验错误
+type FieldError struct {
+	// Field 字段名，与表单字段的 name 相同
+	Field string
+
+	// Label 字段在表单中显示的名称
+	Label string
+
+	// Message 错误信息
+	Message string
+}
+
+// ValidationErrors 表单的校验错误，按字段在表单中的顺序排列
+// 作为表单校验函数的返回值时，GoAdmin 在表单上方显示 Error() 的内容，每个字段一条
+type ValidationErrors []FieldError
+
+// Error 返回所有字段的错误信息，格式为"字段名：错误信息"，多个字段以分号分隔
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Label + "：" + fe.Message
+	}
+	return strings.Join(msgs, "；")
+}
+
+// userPhonePattern 电话号码的格式：可以以 + 开头，由数字、空格和 - 组成，至少包含 5 位数字
+var userPhonePattern = regexp.MustCompile(`^\+?[0-9][0-9 -]*[0-9]$`)
+
+// userNameError 校验姓名，通过时返回空字符串
+func userNameError(name string) string {
+	switch {
+	case name == "":
+		return "不能为空"
+	case utf8.RuneCountInString(name) > 50:
+		return "不能超过 50 个字符"
+	}
+	return ""
+}
+
+// userPhoneError 校验电话号码，可以为空，通过时返回空字符串
+func userPhoneError(phone string) string {
+	if phone == "" {
+		return ""
+	}
+	digits := 0
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if !userPhonePattern.MatchString(phone) || digits < 5 || digits > 20 {
+		return "格式不正确，只能包含数字、空格、+ 和 -，5 到 20 位数字"
+	}
+	return ""
+}
+
+// userPasswordErrors 校验密码和确认密码，通过时返回空字符串
+// bcrypt 只使用前 72 个字节，更长的密码会被拒绝
+func userPasswordErrors(password, confirm string) (passwordMsg, confirmMsg string) {
+	switch {
+	case utf8.RuneCountInString(password) < 8:
+		return "至少 8 个字符", ""
+	case len(password) > 72:
+		return "不能超过 72 个字节", ""
+	case password != confirm:
+		return "", "两次输入的密码不一致"
+	}
+	return "", ""
+}
+
+// ValidateUser 校验用户表单提交的字段
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - id: 编辑的用户编号，新增时为空，检查邮箱是否重复时排除该用户
+//   - values: 提交的字段值，键为字段名；只校验提交了的字段，列表中单独修改姓名时只校验姓名
+//
+// 返回值:
+//   - ValidationErrors: 每个不通过的字段一条，全部通过时为 nil
+//   - error: 查询邮箱是否重复失败时返回数据库错误
+//
+// 校验规则:
+//   - 姓名（name）必填，不超过 50 个字符
+//   - 电话（phone）可以为空，只能包含数字、空格、+ 和 -，5 到 20 位数字
+//   - 邮箱（email）可以为空，格式正确、不超过 100 个字符，不能与其他用户（包括已删除的用户）重复
+//   - 密码（password）新增时必填，编辑时留空表示不修改；至少 8 个字符，与确认密码（password_again）一致
+func ValidateUser(ctx context.Context, id string, values map[string]string) (ValidationErrors, error) {
+	var errs ValidationErrors
+	add := func(field, label, msg string) {
+		if msg != "" {
+			errs = append(errs, FieldError{Field: field, Label: label, Message: msg})
+		}
+	}
+
+	if name, ok := values["name"]; ok {
+		add("name", "姓名", userNameError(strings.TrimSpace(name)))
+	}
+	if phone, ok := values["phone"]; ok {
+		add("phone", "电话", userPhoneError(strings.TrimSpace(phone)))
+	}
+	if password, ok := values["password"]; ok && (password != "" || id == "") {
+		if password == "" {
+			add("password", "密码", "不能为空")
+		} else {
+			passwordMsg, confirmMsg := userPasswordErrors(password, values["password_again"])
+			add("password", "密码", passwordMsg)
+			add("password_again", "确认密码", confirmMsg)
+		}
+	}
+	if email, ok := values["email"]; ok {
+		email = strings.TrimSpace(email)
+		msg, err := userEmailError(ctx, id, email)
+		if err != nil {
+			return nil, err
+		}
+		add("email", "邮箱", msg)
+	}
+	return errs, nil
+}
+
+// userEmailError 校验邮箱的格式，并检查是否已被其他用户使用，通过时返回空字符串
+// 已删除的用户恢复后仍使用原来的邮箱，因此同样参与重复检查
+func userEmailError(ctx context.Context, id, email string) (string, error) {
+	if email == "" {
+		return "", nil
+	}
+	if utf8.RuneCountInString(email) > 100 {
+		return "不能超过 100 个字符", nil
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return "格式不正确", nil
+	}
+
+	q := orm.WithContext(ctx).Unscoped().Model(&User{}).Where("email = ?", email)
+	if id != "" {
+		q = q.Where("id <> ?", id)
+	}
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "已被其他用户使用", nil
+	}
+	return "", nil
+}
+
+// userDuplicateLimit 查找重复用户时最多返回的条数
+const userDuplicateLimit = 5
+
+// FindDuplicateUsers 查找电话或邮箱与给定值相同的用户，用于新增用户前提示可能重复
+//
+// 参数:
+//   - ctx: 上下文
+//   - phone: 电话，为空时不按电话查找
+//   - email: 邮箱，为空时不按邮箱查找
+//   - createdBy: 大于 0 时只查找该管理员创建的用户（见 tables.withOwnership），为 0 时查找全部用户
+//
+// 返回值:
+//   - []User: 最多 5 个未删除的用户，按编号升序排列；phone 和 email 都为空时返回 nil
+//   - error: 查询失败时返回数据库错误
+//
+// 注意事项:
+//   - 与邮箱的重复校验一样从主库读取，刚新增的用户也能查到
+//   - 已删除的用户不返回：无法打开编辑，邮箱重复时仍由 ValidateUser 拒绝保存
+func FindDuplicateUsers(ctx context.Context, phone, email string, createdBy int64) ([]User, error) {
+	phone, email = strings.TrimSpace(phone), strings.TrimSpace(email)
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if phone != "" {
+		conds = append(conds, "phone = ?")
+		args = append(args, phone)
+	}
+	if email != "" {
+		conds = append(conds, "email = ?")
+		args = append(args, email)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	q := orm.WithContext(ctx).Where("("+strings.Join(conds, " OR ")+")", args...)
+	if createdBy > 0 {
+		q = q.Where("created_by = ?", createdBy)
+	}
+	var users []User
+	err := q.Order("id").Limit(userDuplicateLimit).Find(&users).Error
+	return users, err
+}
+
+// UserAvatar 返回用户当前保存的头像地址，包括已删除的用户
+//
+// 返回值:
+//   - string: 头像地址，未上传时为空
+//   - error: 用户不存在时返回 gorm.ErrRecordNotFound
+func UserAvatar(ctx context.Context, id string) (string, error) {
+	var avatars []string
+	if err := orm.WithContext(ctx).Unscoped().Model(&User{}).Where("id = ?", id).
+		Limit(1).Pluck("avatar", &avatars).Error; err != nil {
+		return "", err
+	}
+	if len(avatars) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return avatars[0], nil
+}
+
+// HashPassword 返回密码的 bcrypt 哈希
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// ResetUserPassword 重置用户的密码
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行
+//   - id: 用户编号
+//   - password, confirm: 新密码和确认密码，规则与用户表单相同（见 ValidateUser）
+//
+// 返回值:
+//   - error: 新密码为空时返回 ErrEmptyPassword，不符合规则时返回 ValidationErrors，
+//     用户不存在时返回 gorm.ErrRecordNotFound，写入失败时返回数据库错误
+func ResetUserPassword(ctx context.Context, id, password, confirm string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	var errs ValidationErrors
+	passwordMsg, confirmMsg := userPasswordErrors(password, confirm)
+	if passwordMsg != "" {
+		errs = append(errs, FieldError{Field: "password", Label: "密码", Message: passwordMsg})
+	}
+	if confirmMsg != "" {
+		errs = append(errs, FieldError{Field: "password_again", Label: "确认密码", Message: confirmMsg})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	res := writer(ctx).Model(&User{}).Where("id = ?", id).Update("password", hash)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
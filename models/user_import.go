@@ -0,0 +1,247 @@
+// models 包 - 数据模型层
+// 本文件实现用户的 CSV 批量导入
+// 导入分两步：ParseUserCSV 解析并逐行校验，管理后台据此显示预览；
+// ImportUsers 在一个事务中写入校验通过的行，有错误的行不写入，由调用方生成错误报告
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxUserImportRows 一次导入的最大行数（不含表头）
+const MaxUserImportRows = 1000
+
+// ErrInvalidCSV CSV 文件无法读取，或缺少必需的列
+var ErrInvalidCSV = errors.New("CSV 文件无效")
+
+// userImportColumns 表头中可以使用的列名，中文和英文字段名都可以，键为 users 表的字段名
+var userImportColumns = map[string][]string{
+	"name":   {"name", "姓名"},
+	"gender": {"gender", "性别"},
+	"city":   {"city", "城市"},
+	"ip":     {"ip", "IP"},
+	"phone":  {"phone", "电话"},
+}
+
+// UserImportRow CSV 中的一行及其校验结果
+type UserImportRow struct {
+	// Line 在 CSV 文件中的行号，表头为第 1 行
+	Line int
+
+	// Record 该行的原始内容，生成错误报告时原样写回
+	Record []string
+
+	// User 解析出的用户，Errors 不为空时不会写入
+	User User
+
+	// Errors 该行的校验错误
+	Errors []string
+}
+
+// Valid 该行是否通过校验
+func (r UserImportRow) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// UserImport 一个 CSV 文件的解析结果
+type UserImport struct {
+	// Header CSV 的表头
+	Header []string
+
+	// Rows 表头之后的每一行，空行被跳过
+	Rows []UserImportRow
+}
+
+// ValidCount 校验通过的行数
+func (im UserImport) ValidCount() int {
+	n := 0
+	for _, r := range im.Rows {
+		if r.Valid() {
+			n++
+		}
+	}
+	return n
+}
+
+// ErrorReport 生成错误报告：有错误的行按原样输出，末尾加上“错误”列
+// 修改后可以直接再次导入（导入时多出的列会被忽略）
+// 每个单元格都经过 EscapeCSVFormula 处理，以公式字符开头的内容前面会多出单引号，再次导入前需要去掉
+func (im UserImport) ErrorReport() []byte {
+	var buf bytes.Buffer
+	// 带 BOM，Excel 打开时按 UTF-8 识别中文
+	buf.WriteString("\uFEFF")
+	w := csv.NewWriter(&buf)
+	_ = w.Write(append(escapeCSVRow(im.Header), "错误"))
+	for _, r := range im.Rows {
+		if !r.Valid() {
+			_ = w.Write(append(escapeCSVRow(r.Record), EscapeCSVFormula(strings.Join(r.Errors, "；"))))
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// ParseUserCSV 解析并校验用户 CSV
+//
+// 参数:
+//   - r: CSV 内容，UTF-8 编码，可以带 BOM；第一行为表头，必须包含姓名列，其余列可选
+//
+// 返回值:
+//   - UserImport: 每一行的解析结果，行内的错误记录在 UserImportRow.Errors 中
+//   - error: 文件无法解析、缺少姓名列、没有数据行或超过 MaxUserImportRows 行时返回包装了 ErrInvalidCSV 的错误
+//
+// 校验规则:
+//   - 姓名和电话的规则与用户表单相同（见 ValidateUser）
+//   - 性别为 男、女、0、1 之一，可以为空
+//   - IP 为合法的 IPv4 或 IPv6 地址，可以为空
+//   - 城市不超过 50 个字符
+func ParseUserCSV(r io.Reader) (UserImport, error) {
+	var im UserImport
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return im, fmt.Errorf("%w: 无法读取表头: %v", ErrInvalidCSV, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+	}
+	im.Header = header
+
+	index := userImportIndex(header)
+	if _, ok := index["name"]; !ok {
+		return im, fmt.Errorf("%w: 缺少姓名（name）列", ErrInvalidCSV)
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im, fmt.Errorf("%w: 第 %d 行: %v", ErrInvalidCSV, line, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(im.Rows) == MaxUserImportRows {
+			return im, fmt.Errorf("%w: 一次最多导入 %d 行", ErrInvalidCSV, MaxUserImportRows)
+		}
+		im.Rows = append(im.Rows, parseUserRecord(line, record, index))
+	}
+
+	if len(im.Rows) == 0 {
+		return im, fmt.Errorf("%w: 没有数据行", ErrInvalidCSV)
+	}
+	return im, nil
+}
+
+// userImportIndex 按表头找出每个字段所在的列
+func userImportIndex(header []string) map[string]int {
+	index := make(map[string]int)
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		for field, names := range userImportColumns {
+			for _, name := range names {
+				if strings.EqualFold(h, name) {
+					index[field] = i
+				}
+			}
+		}
+	}
+	return index
+}
+
+// isBlankRecord 该行是否所有单元格都为空
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseUserRecord 解析并校验一行
+func parseUserRecord(line int, record []string, index map[string]int) UserImportRow {
+	row := UserImportRow{Line: line, Record: record}
+	get := func(field string) string {
+		if i, ok := index[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row.User.Name = get("name")
+	if msg := userNameError(row.User.Name); msg != "" {
+		row.Errors = append(row.Errors, "姓名"+msg)
+	}
+
+	switch g := get("gender"); g {
+	case "", "男", "0":
+		row.User.Gender = 0
+	case "女", "1":
+		row.User.Gender = 1
+	default:
+		row.Errors = append(row.Errors, "性别应为 男 或 女："+g)
+	}
+
+	row.User.City = get("city")
+	if utf8.RuneCountInString(row.User.City) > 50 {
+		row.Errors = append(row.Errors, "城市不能超过 50 个字符")
+	}
+
+	row.User.IP = get("ip")
+	if row.User.IP != "" && net.ParseIP(row.User.IP) == nil {
+		row.Errors = append(row.Errors, "IP 地址无效："+row.User.IP)
+	}
+
+	row.User.Phone = get("phone")
+	if msg := userPhoneError(row.User.Phone); msg != "" {
+		row.Errors = append(row.Errors, "电话"+msg)
+	}
+
+	return row
+}
+
+// ImportUsers 写入校验通过的行
+//
+// 参数:
+//   - ctx: 上下文，在事务中调用时（见 Transaction）使用事务执行，调用方应在事务中调用，任何一行写入失败时全部回滚
+//   - im: ParseUserCSV 的结果，有错误的行被跳过
+//   - createdBy: 执行导入的管理员 ID，写入每个新用户的 created_by
+//
+// 返回值:
+//   - []string: 新用户的编号，与写入顺序一致
+//   - error: 写入失败时返回数据库错误
+func ImportUsers(ctx context.Context, im UserImport, createdBy int64) ([]string, error) {
+	now := time.Now()
+	ids := make([]string, 0, len(im.Rows))
+	for _, r := range im.Rows {
+		if !r.Valid() {
+			continue
+		}
+		u := r.User
+		u.CreatedAt, u.UpdatedAt = now, now
+		u.CreatedBy = createdBy
+		if err := writer(ctx).Create(&u).Error; err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", r.Line, err)
+		}
+		ids = append(ids, strconv.FormatUint(uint64(u.ID), 10))
+	}
+	return ids, nil
+}
@@ -0,0 +1,69 @@
+// models 包 - 数据模型层
+// 本文件定义会员地理位置模型和按国家汇总新会员的方法
+// 仪表板的世界地图组件从 user_locations 表读取数据
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// UserLocation 会员地理位置模型
+// 每个会员一条记录，CreatedAt 即会员注册时间，用于按日期范围统计新会员
+type UserLocation struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 会员ID，对应 users 表的 id
+	UserID uint `gorm:"column:user_id;index"`
+
+	// Country 国家名称，使用 ECharts 世界地图中的英文名称，如 China、United States
+	// 名称不匹配时地图上不会显示该国家的数据
+	Country string `gorm:"column:country;index"`
+
+	// CreatedAt 会员注册时间，由GORM自动填充
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName 指定 UserLocation 对应的数据库表名
+func (UserLocation) TableName() string {
+	return "user_locations"
+}
+
+// CountryCount 某个国家在统计区间内的新会员数
+type CountryCount struct {
+	// Country 国家名称
+	Country string `gorm:"column:country"`
+
+	// Members 新会员数
+	Members float64 `gorm:"column:members"`
+}
+
+// NewMembersByCountry 按国家统计指定时间区间内的新会员数
+//
+// 参数:
+//   - ctx: 请求的上下文，请求取消或超时时查询随之取消
+//   - from: 区间开始时间（包含）
+//   - to: 区间结束时间（包含）
+//
+// 返回值:
+//   - []CountryCount: 按新会员数降序排列
+//
+// 注意事项:
+//   - 结果按时间区间缓存，有效期为 DefaultCacheTTL，写入会员位置时不主动失效
+func NewMembersByCountry(ctx context.Context, from, to time.Time) []CountryCount {
+	key := "user_locations:countries:" + from.Format(statisticsTimeLayout) + ":" + to.Format(statisticsTimeLayout)
+	return remember(ctx, key, func() []CountryCount {
+		var counts []CountryCount
+
+		reader(ctx).Model(&UserLocation{}).
+			Select("country, COUNT(*) AS members").
+			Where("created_at BETWEEN ? AND ?", from.Format(statisticsTimeLayout), to.Format(statisticsTimeLayout)).
+			Group("country").
+			Order("members DESC").
+			Scan(&counts)
+
+		return counts
+	})
+}
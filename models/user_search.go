@@ -0,0 +1,75 @@
+// models 包 - 数据模型层
+// 本文件实现用户列表搜索框的查询条件：同时搜索姓名、电话和城市
+// SQLite 建立了 users_fts 全文索引时使用 FTS5，否则使用 LIKE 查询
+
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// userSearchMaxTerms 搜索词最多的个数，多余的词被忽略
+const userSearchMaxTerms = 5
+
+// userSearchColumns 搜索的字段
+var userSearchColumns = []string{"name", "phone", "city"}
+
+var (
+	// userSearchFTS users_fts 全文索引是否存在，第一次搜索时检查
+	userSearchFTS     bool
+	userSearchFTSOnce sync.Once
+)
+
+// UserSearchCondition 返回搜索用户的查询条件，用于表格的 WhereRaw
+//
+// 参数:
+//   - q: 搜索框输入的内容，以空白分隔的多个词需要同时匹配
+//
+// 返回值:
+//   - string: 查询条件，字段带有 users 表名；q 为空时返回空字符串
+//   - []interface{}: 查询条件的参数
+//
+// 功能说明:
+//   - 有全文索引时每个词按前缀匹配姓名、电话或城市中的某个词，如"张"匹配"张三"，"138"匹配"13800000000"
+//   - 没有全文索引时每个词按包含匹配姓名、电话或城市
+func UserSearchCondition(q string) (string, []interface{}) {
+	terms := strings.Fields(q)
+	if len(terms) == 0 {
+		return "", nil
+	}
+	if len(terms) > userSearchMaxTerms {
+		terms = terms[:userSearchMaxTerms]
+	}
+
+	userSearchFTSOnce.Do(func() {
+		userSearchFTS = orm.Dialector.Name() == "sqlite" && orm.Migrator().HasTable("users_fts")
+	})
+	if userSearchFTS {
+		match := make([]string, len(terms))
+		for i, t := range terms {
+			match[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"*`
+		}
+		return `users.id IN (SELECT rowid FROM users_fts WHERE users_fts MATCH ?)`,
+			[]interface{}{strings.Join(match, " ")}
+	}
+
+	var (
+		conds = make([]string, 0, len(terms))
+		args  = make([]interface{}, 0, len(terms)*len(userSearchColumns))
+	)
+	for _, t := range terms {
+		ors := make([]string, len(userSearchColumns))
+		for i, c := range userSearchColumns {
+			ors[i] = "users." + c + " LIKE ? ESCAPE '!'"
+			args = append(args, "%"+escapeLike(t)+"%")
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", args
+}
+
+// escapeLike 转义 LIKE 中的通配符，转义字符为 !，各数据库的写法相同
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,85 @@
+// models 包 - 数据模型层
+// 本文件定义管理员的工作经历，表单页面中可重复的“工作经历”分组保存在 work_experiences 表中
+
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkExperience 一段工作经历
+// 该结构体映射到 work_experiences 表
+type WorkExperience struct {
+	// ID 主键字段
+	ID uint `gorm:"primaryKey"`
+
+	// UserID 管理员ID，对应 goadmin_users 表的 id
+	UserID int64 `gorm:"column:user_id"`
+
+	// SortOrder 在表单中的顺序，从 0 开始
+	SortOrder int `gorm:"column:sort_order"`
+
+	// Company 公司名称
+	Company string `gorm:"column:company"`
+
+	// Title 职位
+	Title string `gorm:"column:title"`
+
+	// StartDate 入职日期，格式为 YYYY-MM-DD
+	StartDate string `gorm:"column:start_date"`
+
+	// EndDate 离职日期，格式为 YYYY-MM-DD，为空表示至今
+	EndDate string `gorm:"column:end_date"`
+
+	// Description 工作内容
+	Description string `gorm:"column:description"`
+
+	// CreatedAt 创建时间，由GORM自动填充
+	CreatedAt time.Time
+}
+
+// TableName 指定 WorkExperience 对应的数据库表名
+func (WorkExperience) TableName() string {
+	return "work_experiences"
+}
+
+// FindWorkExperiences 读取管理员的工作经历，按表单中的顺序排列
+//
+// 注意事项:
+//   - 与表单草稿一样从主库读取，保存后立即打开表单时能看到刚保存的内容
+func FindWorkExperiences(ctx context.Context, userID int64) ([]WorkExperience, error) {
+	var list []WorkExperience
+	err := orm.WithContext(ctx).Where("user_id = ?", userID).Order("sort_order, id").Find(&list).Error
+	return list, err
+}
+
+// ReplaceWorkExperiences 用 list 替换管理员的全部工作经历
+//
+// 参数:
+//   - ctx: 请求的上下文
+//   - userID: 管理员ID
+//   - list: 新的工作经历，按顺序填充 SortOrder，忽略其中的 ID 和 UserID
+//
+// 返回值:
+//   - error: 写入失败时返回数据库错误，此时原有的工作经历不会被修改
+func ReplaceWorkExperiences(ctx context.Context, userID int64, list []WorkExperience) error {
+	return Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := writer(ctx).Where("user_id = ?", userID).Delete(&WorkExperience{}).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		rows := make([]WorkExperience, len(list))
+		for i, e := range list {
+			e.ID = 0
+			e.UserID = userID
+			e.SortOrder = i
+			rows[i] = e
+		}
+		return writer(ctx).Create(&rows).Error
+	})
+}
@@ -0,0 +1,51 @@
+// models 包 - 数据模型层
+// 本文件记录正在运行的后台任务（汇总、采集、定时导出、导入等），退出时等待它们结束后再关闭数据库连接
+
+package models
+
+import (
+	"context"
+	"sync"
+)
+
+// workers 由 RunWorker 启动、尚未结束的后台任务
+var workers sync.WaitGroup
+
+// RunWorker 在新的 goroutine 中执行后台任务，WaitWorkers 会等待其结束
+//
+// 使用示例:
+//
+//	models.RunWorker(func() {
+//	    runExportSchedule(s, true)
+//	})
+//
+// 注意事项:
+//   - 长期运行的任务需要自己监听 ctx.Done() 退出，否则 WaitWorkers 只能等到超时
+func RunWorker(fn func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		fn()
+	}()
+}
+
+// WaitWorkers 等待 RunWorker 启动的后台任务全部结束
+//
+// 参数:
+//   - ctx: 超时或取消时不再等待
+//
+// 返回值:
+//   - error: 超时或取消时返回 ctx.Err()，此时仍有任务在运行
+func WaitWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,314 @@
+// pages 包 - 页面处理器
+// 本文件实现当前管理员的个人资料页面：修改显示名称、邮箱、头像、界面语言和登录密码
+// 页面演示了如何在自定义页面中通过 auth.Auth(ctx) 读取当前登录的管理员
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/purpose168/GoAdmin-example/middleware"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/language"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// 以下地址相对于后台的路由前缀，注册路由和生成链接时经过 config.Url
+const (
+	// AccountURL 个人资料页面的地址，GET 显示表单，POST 保存名称、邮箱、头像和界面语言
+	AccountURL = "/account"
+
+	// AccountPasswordURL 修改登录密码的地址
+	AccountPasswordURL = "/account/password"
+)
+
+// accountLanguages 个人资料页面可以选择的界面语言，即 GoAdmin 支持的 language.Langs 和跟随系统
+var accountLanguages = []struct {
+	Value string
+	Label string
+}{
+	{"", "跟随系统"},
+	{language.CN, "简体中文"},
+	{language.TC, "繁體中文"},
+	{language.EN, "English"},
+	{language.JP, "日本語"},
+	{language.RU, "Русский"},
+}
+
+// AccountPage 返回当前登录管理员的个人资料页面
+//
+// 参数:
+//   - ctx: 请求上下文对象
+//
+// 返回值:
+//   - types.Panel: 个人资料表单和修改密码表单
+//   - error: 读取个人资料失败时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.AccountURL), pages.AccountPage)
+//	eng.Data("POST", config.Url(pages.AccountURL), pages.SaveAccount)
+//	eng.Data("POST", config.Url(pages.AccountPasswordURL), pages.ChangeAccountPassword)
+//
+// 注意事项:
+//   - 所有管理员都可以访问，只能修改自己的资料，不需要用户表格的权限
+//   - auth.Auth(ctx) 返回 GoAdmin 按登录会话读取的管理员，其中 Id、UserName、Name、Avatar 对应 goadmin_users 表的字段
+func AccountPage(ctx *context.Context) (types.Panel, error) {
+	user := auth.Auth(ctx)
+	profile, err := models.FindAdminProfile(ctx.Request.Context(), user.Id)
+	if err != nil {
+		return types.Panel{}, fmt.Errorf("读取个人资料失败: %v", err)
+	}
+
+	return types.Panel{
+		Content:     renderAccount(profile),
+		Title:       "个人资料",
+		Description: template.HTML(template.HTMLEscapeString(profile.Username)),
+	}, nil
+}
+
+// AccountButton 返回顶部导航栏个人资料按钮的动作，点击跳转到个人资料页面
+//
+// 使用示例:
+//
+//	eng.AddNavButtons("", icon.User, pages.AccountButton())
+func AccountButton() types.Action {
+	return action.Jump(config.Url(AccountURL))
+}
+
+// renderAccount 生成个人资料表单和修改密码表单
+func renderAccount(p models.AdminProfile) template.HTML {
+	avatar := `<p class="account-avatar-current text-muted">未上传</p>`
+	if p.Avatar != "" {
+		avatar = fmt.Sprintf(`<p class="account-avatar-current"><img src="%s" class="img-circle" style="width:60px;height:60px"></p>`,
+			template.HTMLEscapeString(config.GetStore().URL(p.Avatar)))
+	}
+	var options string
+	for _, l := range accountLanguages {
+		selected := ""
+		if l.Value == p.Language {
+			selected = " selected"
+		}
+		options += fmt.Sprintf(`<option value="%s"%s>%s</option>`, l.Value, selected, l.Label)
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="row"><div class="col-md-6">
+<div class="box box-primary">
+<div class="box-header with-border"><h3 class="box-title">基本资料</h3></div>
+<form id="account-form" class="form-horizontal" method="post" action="%s" enctype="multipart/form-data">
+  <div class="box-body">
+    <div class="form-group">
+      <label class="col-sm-3 control-label">登录名</label>
+      <div class="col-sm-8"><p class="form-control-static">%s</p></div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-name">名称</label>
+      <div class="col-sm-8"><input type="text" class="form-control" id="account-name" name="name" value="%s" maxlength="50"></div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-email">邮箱</label>
+      <div class="col-sm-8">
+        <input type="email" class="form-control" id="account-email" name="email" value="%s" maxlength="100">
+        <span class="help-block">使用 GitHub、Google 等第三方账号登录时，按该邮箱关联到当前管理员</span>
+      </div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label">头像</label>
+      <div class="col-sm-8">
+        %s
+        %s
+      </div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-language">界面语言</label>
+      <div class="col-sm-8">
+        <select class="form-control" id="account-language" name="language">%s</select>
+        <span class="help-block">影响侧边栏菜单等按请求选择语言的内容，跟随系统时使用 config.yml 中的 language</span>
+      </div>
+    </div>
+  </div>
+  <div class="box-footer"><div class="col-sm-offset-3"><button type="submit" class="btn btn-primary">保存</button></div></div>
+</form>
+</div>
+</div><div class="col-md-6">
+<div class="box box-warning">
+<div class="box-header with-border"><h3 class="box-title">修改密码</h3></div>
+<form id="account-password-form" class="form-horizontal" method="post" action="%s">
+  <div class="box-body">
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-current">当前密码</label>
+      <div class="col-sm-8"><input type="password" class="form-control" id="account-current" name="current_password" autocomplete="current-password"></div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-password">新密码</label>
+      <div class="col-sm-8"><input type="password" class="form-control" id="account-password" name="password" autocomplete="new-password"></div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-3 control-label" for="account-password-again">确认密码</label>
+      <div class="col-sm-8"><input type="password" class="form-control" id="account-password-again" name="password_again" autocomplete="new-password">
+        <span class="help-block">修改后在其他浏览器中的登录将被退出</span></div>
+    </div>
+  </div>
+  <div class="box-footer"><div class="col-sm-offset-3"><button type="submit" class="btn btn-warning">修改密码</button></div></div>
+</form>
+</div>
+</div></div><script>%s%s</script>`, config.Url(AccountURL),
+		template.HTMLEscapeString(p.Username), template.HTMLEscapeString(p.Name), template.HTMLEscapeString(p.Email), avatar, tables.AvatarCropper("avatar", ""), options,
+		config.Url(AccountPasswordURL), accountJS, tables.AvatarCropperJS()))
+}
+
+// accountJS 以 AJAX 提交两个表单，保存失败时提示原因
+// 名称、头像和语言显示在页面框架中，保存资料后刷新整个页面；修改密码后清空密码输入框
+// 头像的裁剪和上传见 tables.AvatarCropperJS
+const accountJS = template.JS(`
+(function () {
+    function fail(data) {
+        swal(data.responseJSON ? data.responseJSON.msg : '保存失败', '', 'error');
+    }
+    // 裁剪并上传新头像后，新头像的预览代替当前头像
+    $('#account-form .avatar-cropper-value').on('change', function () {
+        $('.account-avatar-current').hide();
+    });
+    $('#account-form').on('submit', function (e) {
+        e.preventDefault();
+        $.ajax({
+            method: 'post',
+            url: $(this).attr('action'),
+            data: new FormData(this),
+            processData: false,
+            contentType: false,
+            success: function () {
+                location.reload();
+            },
+            error: fail
+        });
+    });
+    $('#account-password-form').on('submit', function (e) {
+        e.preventDefault();
+        let form = this;
+        $.ajax({
+            method: 'post',
+            url: $(form).attr('action'),
+            data: $(form).serialize(),
+            success: function (data) {
+                form.reset();
+                swal(data.msg, '', 'success');
+            },
+            error: fail
+        });
+    });
+})();
+`)
+
+// SaveAccount 保存个人资料页面提交的名称、邮箱、头像和界面语言
+//
+// 请求格式:
+//
+//	POST multipart/form-data name=管理员&email=admin@example.com&language=en&avatar=avatars/xxx.jpg
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+//
+// 注意事项:
+//   - avatar 为裁剪后上传到 tables.AvatarUploadURL 返回的缩略图地址，为空时保留原头像
+//   - 保存后写入 middleware.LanguageCookie，之后的请求按所选语言显示
+func SaveAccount(ctx *context.Context) {
+	user := auth.Auth(ctx)
+	lang := ctx.FormValue("language")
+	if !validAccountLanguage(lang) {
+		accountError(ctx, http.StatusBadRequest, "不支持的界面语言: "+lang)
+		return
+	}
+
+	// 头像由裁剪控件上传到 tables.AvatarUploadURL，这里只提交缩略图的地址
+	avatar := ctx.FormValue("avatar")
+	if avatar != "" && !tables.ValidAvatarPath(avatar) {
+		accountError(ctx, http.StatusBadRequest, "头像：无效的头像地址，请重新上传")
+		return
+	}
+
+	err := models.UpdateAdminProfile(ctx.Request.Context(), user.Id, ctx.FormValue("name"), ctx.FormValue("email"), avatar, lang)
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		accountError(ctx, http.StatusBadRequest, verrs.Error())
+		return
+	case err != nil:
+		accountError(ctx, http.StatusInternalServerError, "保存个人资料失败: "+err.Error())
+		return
+	}
+
+	ctx.SetCookie(middleware.NewLanguageCookie(lang))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+	})
+}
+
+// ChangeAccountPassword 修改当前登录管理员的密码
+//
+// 请求格式:
+//
+//	POST current_password=旧密码&password=新密码&password_again=新密码
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "密码已修改"}
+//
+// 注意事项:
+//   - 新密码的规则与用户表单相同，当前密码不正确或新密码不符合规则时返回 400
+//   - 修改后该管理员在其他浏览器中的登录会话失效，当前会话保持登录
+func ChangeAccountPassword(ctx *context.Context) {
+	// 保留当前会话，其他浏览器中的登录会话被踢出
+	var sid string
+	if cookie, err := ctx.Request.Cookie(auth.DefaultCookieKey); err == nil {
+		sid = cookie.Value
+	}
+	err := models.ChangeAdminPassword(ctx.Request.Context(), auth.Auth(ctx).Id,
+		ctx.FormValue("current_password"), ctx.FormValue("password"), ctx.FormValue("password_again"), sid)
+	var verrs models.ValidationErrors
+	switch {
+	case errors.Is(err, models.ErrWrongPassword), errors.Is(err, models.ErrEmptyPassword), errors.As(err, &verrs):
+		accountError(ctx, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		accountError(ctx, http.StatusNotFound, "管理员不存在")
+		return
+	case err != nil:
+		accountError(ctx, http.StatusInternalServerError, "修改密码失败: "+err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "密码已修改",
+	})
+}
+
+// validAccountLanguage 判断是否为可以选择的界面语言
+func validAccountLanguage(lang string) bool {
+	for _, l := range accountLanguages {
+		if lang == l.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// accountError 返回个人资料页面的错误响应
+func accountError(ctx *context.Context, status int, msg string) {
+	ctx.JSON(status, map[string]interface{}{
+		"code": status,
+		"msg":  msg,
+	})
+}
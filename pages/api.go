@@ -0,0 +1,243 @@
+// pages 包 - 页面处理器
+// 本文件实现仪表板的 JSON 接口，返回与 HTML 仪表板相同的数据，
+// 供外部工具和移动端使用；以及其他服务推送统计计数的接口
+
+package pages
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+)
+
+// apiSummary 区间汇总数据
+type apiSummary struct {
+	Sales      float64 `json:"sales"`
+	Likes      float64 `json:"likes"`
+	NewMembers float64 `json:"new_members"`
+	CPU        float64 `json:"cpu"`
+}
+
+// apiOrder 最新订单
+type apiOrder struct {
+	OrderNo   string  `json:"order_no"`
+	Product   string  `json:"product"`
+	Status    string  `json:"status"`
+	Amount    float64 `json:"amount"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// apiGoal 目标完成情况
+type apiGoal struct {
+	Name    string  `json:"name"`
+	Target  float64 `json:"target"`
+	Current float64 `json:"current"`
+	Color   string  `json:"color"`
+}
+
+// apiBrowser 浏览器访问次数
+type apiBrowser struct {
+	Browser string  `json:"browser"`
+	Views   float64 `json:"views"`
+}
+
+// apiComparison 单个指标的环比结果，Change 为变化百分比，下降时为负数
+type apiComparison struct {
+	Name     string  `json:"name"`
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Change   float64 `json:"change"`
+}
+
+// apiDashboard 仪表板接口返回的数据
+type apiDashboard struct {
+	// From、To 生效的日期范围，格式为 2006-01-02
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Statistics 信息框显示的统计数据（statistics 表的第一条记录，CPU 为最近一小时的平均使用率）
+	Statistics apiSummary `json:"statistics"`
+
+	// Summary 日期范围内的汇总，PreviousSummary 为上一个等长周期的汇总
+	Summary         apiSummary `json:"summary"`
+	PreviousSummary apiSummary `json:"previous_summary"`
+
+	// SalesChart 销售折线图，Labels 与 Series 一一对应
+	// 粒度由 ?series= 指定（hour、day、week、month），不填时按日期范围的长度选择
+	SalesChart struct {
+		Granularity string    `json:"granularity"`
+		Labels      []string  `json:"labels"`
+		Series      []float64 `json:"series"`
+	} `json:"sales_chart"`
+
+	// Comparison 环比结果，粒度由 ?granularity= 指定，与描述组件显示的数据一致
+	Comparison struct {
+		Granularity string          `json:"granularity"`
+		From        string          `json:"from"`
+		To          string          `json:"to"`
+		KPIs        []apiComparison `json:"kpis"`
+	} `json:"comparison"`
+
+	Goals        []apiGoal    `json:"goals"`
+	Browsers     []apiBrowser `json:"browsers"`
+	RecentOrders []apiOrder   `json:"recent_orders"`
+}
+
+// toAPISummary 将模型的汇总结果转换为接口格式
+func toAPISummary(s models.StatisticsSummary) apiSummary {
+	return apiSummary{Sales: s.Sales, Likes: s.Likes, NewMembers: s.NewMembers, CPU: s.CPU}
+}
+
+// DashboardAPI 以 JSON 返回仪表板数据
+//
+// 参数:
+//   - ctx: 请求上下文对象，与仪表板页面一样支持 ?from=&to=&granularity= 查询参数，
+//     ?series= 指定销售折线图的粒度
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"from": "...", "to": "...", "statistics": {...}, ...}}
+//
+// 使用示例:
+//
+//	eng.Data("GET", config.Url("/api/dashboard"), pages.DashboardAPI)
+//
+// 注意事项:
+//   - 需要登录，外部工具调用时需携带后台的会话 Cookie
+//   - 数据与 HTML 仪表板来自同一组查询，同样受统计缓存影响
+func DashboardAPI(ctx *context.Context) {
+	dr := parseDateRange(ctx)
+	reqCtx := ctx.Request.Context()
+	repos := Repos
+	prev := dr.Previous()
+
+	var data apiDashboard
+	data.From = dr.From.Format(widgets.DateLayout)
+	data.To = dr.To.Format(widgets.DateLayout)
+
+	first := repos.Statistics.First(reqCtx)
+	data.Statistics = apiSummary{
+		Sales:      float64(first.Sales),
+		Likes:      float64(first.Likes),
+		NewMembers: float64(first.NewMembers),
+		CPU:        float64(repos.Statistics.LatestSystem(reqCtx).CPU),
+	}
+	data.Summary = toAPISummary(repos.Statistics.Summarize(reqCtx, dr.From, dr.To))
+	data.PreviousSummary = toAPISummary(repos.Statistics.Summarize(reqCtx, prev.From, prev.To))
+
+	g := models.ParseGranularity(ctx.Query("granularity"))
+	period, kpis := repos.Statistics.Compare(reqCtx, dr.From, dr.To, g)
+	data.Comparison.Granularity = string(g)
+	data.Comparison.From = period.From.Format(widgets.DateLayout)
+	data.Comparison.To = period.To.Format(widgets.DateLayout)
+	data.Comparison.KPIs = make([]apiComparison, 0, len(kpis))
+	for _, k := range kpis {
+		data.Comparison.KPIs = append(data.Comparison.KPIs, apiComparison{
+			Name: k.Name, Current: k.Current, Previous: k.Previous, Change: k.Change(),
+		})
+	}
+
+	points, series := repos.Statistics.SalesSeries(reqCtx, dr.From, dr.To, models.ParseSeriesGranularity(ctx.Query("series")))
+	data.SalesChart.Granularity = string(series)
+	data.SalesChart.Labels = make([]string, 0, len(points))
+	data.SalesChart.Series = make([]float64, 0, len(points))
+	for _, pt := range points {
+		data.SalesChart.Labels = append(data.SalesChart.Labels, pt.Label)
+		data.SalesChart.Series = append(data.SalesChart.Series, pt.Sales)
+	}
+
+	data.Goals = make([]apiGoal, 0)
+	for _, goal := range repos.Goals.All(reqCtx) {
+		data.Goals = append(data.Goals, apiGoal{Name: goal.Name, Target: goal.Target, Current: goal.Current, Color: goal.Color})
+	}
+
+	data.Browsers = make([]apiBrowser, 0)
+	for _, b := range repos.PageViews.BrowserUsage(reqCtx, dr.From, dr.To) {
+		data.Browsers = append(data.Browsers, apiBrowser{Browser: b.Browser, Views: b.Views})
+	}
+
+	data.RecentOrders = make([]apiOrder, 0)
+	for _, o := range repos.Orders.Recent(reqCtx, widgets.RecentOrdersLimit) {
+		data.RecentOrders = append(data.RecentOrders, apiOrder{
+			OrderNo:   o.OrderNo,
+			Product:   o.Product,
+			Status:    o.Status,
+			Amount:    o.Amount,
+			CreatedAt: o.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+		"data": data,
+	})
+}
+
+// StatisticsAPI 返回接收其他服务推送统计计数的接口
+//
+// 参数:
+//   - token: 调用方需在 Authorization 头中携带 Bearer <token>
+//
+// 请求格式:
+//
+//	POST {"likes": 3, "sales": 199.5, "new_members": 1}
+//
+// 各字段都可以省略，省略或为 0 的指标不写入；点赞数和新会员数必须是整数，所有字段不能为负数
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url("/api/statistics"), pages.StatisticsAPI(os.Getenv("STATISTICS_API_TOKEN")), true)
+//
+// 注意事项:
+//   - 调用方是其他服务，不使用后台的登录会话，注册时应跳过 GoAdmin 的登录检查，只按 token 鉴权
+//   - token 为空时所有请求都被拒绝
+func StatisticsAPI(token string) context.Handler {
+	return func(ctx *context.Context) {
+		auth := ctx.Headers("Authorization")
+		if token == "" || subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+			ctx.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"code": http.StatusUnauthorized,
+				"msg":  "token 无效",
+			})
+			return
+		}
+
+		var inc models.StatisticsIncrement
+		if err := ctx.BindJSON(&inc); err != nil {
+			ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+				"code": http.StatusBadRequest,
+				"msg":  "请求格式错误",
+			})
+			return
+		}
+
+		if err := Repos.Statistics.Increment(ctx.Request.Context(), inc); err != nil {
+			if errors.Is(err, models.ErrInvalidIncrement) {
+				ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+					"code": http.StatusBadRequest,
+					"msg":  err.Error(),
+				})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"code": http.StatusInternalServerError,
+				"msg":  "写入统计数据失败",
+			})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"code": http.StatusOK,
+			"msg":  "ok",
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// pages 包 - 页面处理器
+// 本文件实现图表示例页面：用模型中的真实数据演示柱状图、环形图、雷达图、极地图、散点图和混合图的写法
+// 仪表板只用到了折线图和饼图（见 widgets.Report 和 widgets.Browsers），编写其他图表时可以参照本页面
+
+package pages
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// ChartsURL 图表示例页面的地址，相对于后台的路由前缀
+const ChartsURL = "/charts"
+
+// chartColors 图表依次使用的颜色，与 AdminLTE 的 light-blue、green、yellow、red、aqua、purple、gray 相同
+var chartColors = []chartjs.Color{
+	"rgb(60, 141, 188)",
+	"rgb(0, 166, 90)",
+	"rgb(243, 156, 18)",
+	"rgb(221, 75, 57)",
+	"rgb(0, 192, 239)",
+	"rgb(96, 92, 168)",
+	"rgb(210, 214, 222)",
+}
+
+// chartColorsN 返回 n 个颜色，超过 chartColors 的长度时循环使用
+func chartColorsN(n int) []chartjs.Color {
+	colors := make([]chartjs.Color, n)
+	for i := range colors {
+		colors[i] = chartColors[i%len(chartColors)]
+	}
+	return colors
+}
+
+// ChartsPage 返回图表示例页面
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 from、to 为统计的日期范围，与仪表板相同
+//
+// 返回值:
+//   - types.Panel: 六个图表，每个图表下方注明数据来源和写法
+//   - error: 始终为 nil
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.ChartsURL), pages.ChartsPage)
+//
+// 注意事项:
+//   - 数据通过 Repos 读取，与仪表板组件相同
+//   - chartjs 包只提供折线图、柱状图和饼图的构造函数：
+//     环形图和极地图修改饼图的类型，混合图修改柱状图中数据集的类型，雷达图和散点图直接设置 Chart.js 的配置（见 rawChart）
+func ChartsPage(ctx *context.Context) (types.Panel, error) {
+	dr := parseDateRange(ctx)
+	c := ctx.Request.Context()
+
+	products := Repos.Orders.TopProducts(c, dr.From, dr.To, 8)
+	browsers := Repos.PageViews.BrowserUsage(c, dr.From, dr.To)
+	countries := Repos.Members.ByCountry(c, dr.From, dr.To)
+	series, _ := Repos.Statistics.SalesSeries(c, dr.From, dr.To, models.GranularityDay)
+
+	boxes := []template.HTML{
+		chartBox("柱状图：商品销售额", "chartjs.Bar()，数据来自 Repos.Orders.TopProducts", len(products) > 0, productRevenueBar(products)),
+		chartBox("环形图：浏览器占比", `chartjs.Pie()，JsContent.Type 和数据集类型改为 "doughnut"，数据来自 Repos.PageViews.BrowserUsage`, len(browsers) > 0, browserDoughnut(browsers)),
+		chartBox("雷达图：各星期几的销售额", `chartjs.NewChart() 直接设置 type 为 "radar" 的配置，数据为 Repos.Statistics.SalesSeries 按星期几汇总`, len(series) > 0, weekdayRadar(series)),
+		chartBox("极地图：各国家新会员", `chartjs.Pie()，JsContent.Type 和数据集类型改为 "polarArea"，数据来自 Repos.Members.ByCountry`, len(countries) > 0, countryPolarArea(countries)),
+		chartBox("散点图：商品订单数和销售额", `chartjs.NewChart() 直接设置 type 为 "scatter" 的配置，每个点为 {x, y}，数据来自 Repos.Orders.TopProducts`, len(products) > 0, productScatter(products)),
+		chartBox("混合图：每日销售额和 7 日均值", `chartjs.Bar()，第二个数据集的类型改为 "line"，数据来自 Repos.Statistics.SalesSeries`, len(series) > 0, salesMixed(series)),
+	}
+
+	components := tmpl.Default()
+	var content template.HTML
+	for i := 0; i < len(boxes); i += 2 {
+		cols := components.Col().SetSize(types.SizeMD(6)).SetContent(boxes[i]).GetContent()
+		if i+1 < len(boxes) {
+			cols += components.Col().SetSize(types.SizeMD(6)).SetContent(boxes[i+1]).GetContent()
+		}
+		content += components.Row().SetContent(cols).GetContent()
+	}
+
+	return types.Panel{
+		Content:     chartsRangeForm(dr) + content,
+		Title:       "图表示例",
+		Description: template.HTML("统计区间: " + dr.String()),
+	}, nil
+}
+
+// chartsRangeForm 日期范围表单，以 GET 提交到图表示例页面
+func chartsRangeForm(r widgets.DateRange) template.HTML {
+	return template.HTML(fmt.Sprintf(`<form method="get" action="%s" class="form-inline" style="margin-bottom:10px">
+  <div class="form-group">
+    <label>从</label> <input type="date" class="form-control input-sm" name="from" value="%s">
+  </div>
+  <div class="form-group">
+    <label>至</label> <input type="date" class="form-control input-sm" name="to" value="%s">
+  </div>
+  <button type="submit" class="btn btn-sm btn-primary">查询</button>
+</form>`, config.Url(ChartsURL), r.From.Format(widgets.DateLayout), r.To.Format(widgets.DateLayout)))
+}
+
+// chartBox 用盒子包裹一个图表，note 显示在盒子底部；没有数据时显示提示而不是空图表
+func chartBox(title, note string, hasData bool, chart template.HTML) template.HTML {
+	if !hasData {
+		chart = `<p class="text-center text-muted">所选日期范围内暂无数据</p>`
+	}
+	return tmpl.Default().Box().WithHeadBorder().
+		SetHeader(template.HTML(template.HTMLEscapeString(title))).
+		SetBody(chart).
+		SetFooter(template.HTML(`<small class="text-muted">` + template.HTMLEscapeString(note) + `</small>`)).
+		GetContent()
+}
+
+// rawChart 按 Chart.js 的配置（type、data、options）生成图表
+// 用于 chartjs 包没有对应构造函数、或数据集的格式与 []float64 不同的图表
+func rawChart(id string, height int, config map[string]interface{}) template.HTML {
+	js, err := json.Marshal(config)
+	if err != nil {
+		return template.HTML(`<p class="text-danger">` + template.HTMLEscapeString(err.Error()) + `</p>`)
+	}
+	c := chartjs.NewChart()
+	c.ID = id
+	c.Height = height
+	c.Js = template.JS(js)
+	return c.GetContent()
+}
+
+// productRevenueBar 柱状图：各商品的销售额
+func productRevenueBar(products []models.ProductRevenue) template.HTML {
+	labels := make([]string, len(products))
+	revenue := make([]float64, len(products))
+	for i, p := range products {
+		labels[i] = p.Product
+		revenue[i] = p.Revenue
+	}
+	return chartjs.Bar().SetID("chartsBar").SetHeight(240).SetLabels(labels).
+		AddDataSet("销售额").DSData(revenue).DSBackgroundColor(chartColors[0]).
+		GetContent()
+}
+
+// browserDoughnut 环形图：各浏览器的访问次数
+func browserDoughnut(usage []models.BrowserUsage) template.HTML {
+	labels := make([]string, len(usage))
+	views := make([]float64, len(usage))
+	for i, u := range usage {
+		labels[i] = u.Browser
+		views[i] = u.Views
+	}
+	pie := chartjs.Pie().SetID("chartsDoughnut").SetHeight(240).SetLabels(labels).
+		AddDataSet("访问次数").DSData(views).DSBackgroundColor(chartColorsN(len(views))).DSType("doughnut")
+	pie.JsContent.Type = "doughnut"
+	return pie.GetContent()
+}
+
+// weekdayLabels 雷达图的坐标轴，从星期一开始
+var weekdayLabels = []string{"星期一", "星期二", "星期三", "星期四", "星期五", "星期六", "星期日"}
+
+// weekdayRadar 雷达图：把每日销售额按星期几相加
+func weekdayRadar(series []models.SeriesPoint) template.HTML {
+	sales := make([]float64, len(weekdayLabels))
+	for _, p := range series {
+		// time.Weekday 从星期日开始，换算为从星期一开始的下标
+		sales[(int(p.Start.Weekday())+6)%7] += p.Sales
+	}
+	return rawChart("chartsRadar", 240, map[string]interface{}{
+		"type": "radar",
+		"data": map[string]interface{}{
+			"labels": weekdayLabels,
+			"datasets": []map[string]interface{}{{
+				"label":           "销售额",
+				"data":            sales,
+				"borderColor":     chartColors[1],
+				"backgroundColor": "rgba(0, 166, 90, 0.2)",
+			}},
+		},
+	})
+}
+
+// countryPolarArea 极地图：各国家的新会员数
+func countryPolarArea(counts []models.CountryCount) template.HTML {
+	labels := make([]string, len(counts))
+	members := make([]float64, len(counts))
+	for i, c := range counts {
+		labels[i] = c.Country
+		members[i] = c.Members
+	}
+	pie := chartjs.Pie().SetID("chartsPolarArea").SetHeight(240).SetLabels(labels).
+		AddDataSet("新会员").DSData(members).DSBackgroundColor(chartColorsN(len(members))).DSType("polarArea")
+	pie.JsContent.Type = "polarArea"
+	return pie.GetContent()
+}
+
+// scatterPoint 散点图中的一个点
+type scatterPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// productScatter 散点图：每个商品一个点，横轴为订单数，纵轴为销售额
+func productScatter(products []models.ProductRevenue) template.HTML {
+	points := make([]scatterPoint, len(products))
+	for i, p := range products {
+		points[i] = scatterPoint{X: float64(p.Orders), Y: p.Revenue}
+	}
+	return rawChart("chartsScatter", 240, map[string]interface{}{
+		"type": "scatter",
+		"data": map[string]interface{}{
+			"datasets": []map[string]interface{}{{
+				"label":           "商品",
+				"data":            points,
+				"backgroundColor": chartColors[3],
+			}},
+		},
+		"options": map[string]interface{}{
+			"scales": map[string]interface{}{
+				"x": map[string]interface{}{"title": map[string]interface{}{"display": true, "text": "订单数"}},
+				"y": map[string]interface{}{"title": map[string]interface{}{"display": true, "text": "销售额"}},
+			},
+		},
+	})
+}
+
+// salesMixed 混合图：每日销售额为柱，截至当天的 7 日平均销售额为线
+func salesMixed(series []models.SeriesPoint) template.HTML {
+	const window = 7
+	labels := make([]string, len(series))
+	sales := make([]float64, len(series))
+	average := make([]float64, len(series))
+	var sum float64
+	for i, p := range series {
+		labels[i] = p.Label
+		sales[i] = p.Sales
+		sum += p.Sales
+		if i >= window {
+			sum -= series[i-window].Sales
+		}
+		// 区间开始的前几天不足 7 天，按已有的天数平均
+		average[i] = sum / float64(min(i+1, window))
+	}
+	return chartjs.Bar().SetID("chartsMixed").SetHeight(240).SetLabels(labels).
+		AddDataSet("销售额").DSData(sales).DSBackgroundColor(chartColors[4]).
+		AddDataSet("7 日均值").DSType("line").DSData(average).DSBorderColor(chartColors[2]).DSBackgroundColor(chartColors[2]).
+		GetContent()
+}
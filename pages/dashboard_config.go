@@ -0,0 +1,83 @@
+// pages 包 - 页面处理器
+// 本文件实现从 config.yml 加载仪表板定义
+// 调整仪表板的组件、顺序、宽度和数据来源时只需修改配置文件并重启，无需重新编译
+
+package pages
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadDashboardsFromYAML 从 YAML 配置文件的 dashboards 配置项加载仪表板定义
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析失败、组件未注册、名称重复或宽度超出范围时返回错误
+//
+// 功能说明:
+//  1. 配置文件中没有 dashboards 配置项时保留代码中定义的 Dashboards
+//  2. 有配置项时整体替换 Dashboards，顺序即切换菜单中的顺序
+//
+// 配置示例:
+//
+//	dashboards:
+//	  - name: overview
+//	    url: /
+//	    title: 仪表板
+//	    widgets:
+//	      - name: smallboxes
+//	      - name: report
+//	        width: 12
+//	      - name: heatmap
+//	        options:
+//	          metric: orders
+//
+// 注意事项:
+//   - 必须在注册仪表板路由之前调用，新增的仪表板按 url 注册路由
+//   - url 相对于后台的路由前缀，如 /dashboard/sales，"/" 为后台首页
+//   - 与 GoAdmin 的其他配置不同，该配置项每次启动都从文件读取，不会写入 goadmin_site 表
+func LoadDashboardsFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg struct {
+		Dashboards []Dashboard `yaml:"dashboards"`
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析仪表板配置失败: %v", err)
+	}
+	if len(cfg.Dashboards) == 0 {
+		return nil
+	}
+
+	names := make(map[string]bool, len(cfg.Dashboards))
+	for _, d := range cfg.Dashboards {
+		if d.Name == "" || d.URL == "" {
+			return fmt.Errorf("仪表板配置缺少 name 或 url")
+		}
+		if names[d.Name] {
+			return fmt.Errorf("仪表板名称重复: %s", d.Name)
+		}
+		names[d.Name] = true
+
+		for _, w := range d.Widgets {
+			if _, ok := widgets.Get(w.Name); !ok {
+				return fmt.Errorf("仪表板 %s 使用了未注册的组件: %s", d.Name, w.Name)
+			}
+			if w.Width < 0 || w.Width > 12 {
+				return fmt.Errorf("仪表板 %s 的组件 %s 宽度应在 1-12 之间", d.Name, w.Name)
+			}
+		}
+	}
+
+	Dashboards = cfg.Dashboards
+	return nil
+}
@@ -0,0 +1,237 @@
+// pages 包 - 页面处理器
+// 本文件定义多个主题仪表板及其组件组合
+// 每个仪表板有独立的路由和布局，页面顶部的切换下拉菜单可以在仪表板之间跳转
+
+package pages
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// Dashboard 一个主题仪表板的定义
+// 除了在代码中修改 Dashboards，也可以在 config.yml 的 dashboards 配置项中定义（见 LoadDashboardsFromYAML）
+type Dashboard struct {
+	// Name 仪表板名称，用于保存布局和查找定义
+	Name string `yaml:"name"`
+
+	// URL 仪表板页面的访问路径，相对于后台的路由前缀，"/" 为后台首页；切换菜单按该路径跳转
+	URL string `yaml:"url"`
+
+	// Title 页面标题，同时显示在切换菜单中
+	Title string `yaml:"title"`
+
+	// Description 页面描述
+	Description string `yaml:"description"`
+
+	// Widgets 组件列表，顺序即默认的显示顺序
+	Widgets []WidgetConfig `yaml:"widgets"`
+}
+
+// WidgetConfig 仪表板中一个组件的配置
+type WidgetConfig struct {
+	// Name 组件名称，必须是通过 widgets.Register 注册过的组件
+	Name string `yaml:"name"`
+
+	// Width 默认宽度（1-12），为 0 时使用组件自身的默认宽度
+	// 管理员在页面上调整过宽度后以保存的布局为准
+	Width int `yaml:"width"`
+
+	// Options 传给组件的参数，例如热力图的数据来源 metric，支持的参数见各组件的说明
+	Options map[string]string `yaml:"options"`
+
+	// Lazy 是否在页面显示后再通过 AJAX 加载组件内容
+	// 为 nil 时按组件注册时的设置（见 widgets.Defer），图表和表格类组件默认延迟加载
+	Lazy *bool `yaml:"lazy"`
+}
+
+// deferred 判断组件是否延迟加载，并返回内容加载前的占位信息
+// 配置中强制延迟加载、但组件本身没有标记时，以组件名称作为标题
+func (c WidgetConfig) deferred() (widgets.Deferred, bool) {
+	info, ok := widgets.DeferredInfo(c.Name)
+	if c.Lazy == nil {
+		return info, ok
+	}
+	if !*c.Lazy {
+		return widgets.Deferred{}, false
+	}
+	if !ok {
+		info = widgets.Deferred{Title: c.Name, Width: 4}
+	}
+	return info, true
+}
+
+// widgetList 按名称生成使用默认宽度、没有参数的组件列表
+func widgetList(names ...string) []WidgetConfig {
+	list := make([]WidgetConfig, 0, len(names))
+	for _, name := range names {
+		list = append(list, WidgetConfig{Name: name})
+	}
+	return list
+}
+
+// Dashboards 所有仪表板的定义，顺序即切换菜单中的顺序
+//
+// 内置组件:
+//   - smallboxes: 概览小盒子
+//   - report: 总结报告（销售折线图、目标完成情况、环比描述）
+//   - orders: 信息框和最新订单
+//   - products: 最近添加的产品
+//   - top_products: 按销售额排列的热销商品，可以用 options 的 limit、days 设置数量和统计天数
+//   - tabs: 标签页和弹窗示例
+//   - browsers: 浏览器使用情况饼图
+//   - worldmap: 会员地理分布世界地图，单独占一行，不需要时可以在页面上隐藏
+//   - heatmap / orders_heatmap: 最近一年管理员操作次数 / 订单数的日历热力图
+//   - system: 最近 24 小时的 CPU、内存使用率和协程数，可以用 options 的 hours 设置小时数
+//
+// 可以在注册路由前修改该列表，增删仪表板或调整组件:
+//
+//	pages.Dashboards[0].Widgets = append(pages.Dashboards[0].Widgets, pages.WidgetConfig{Name: "weather", Width: 4})
+var Dashboards = []Dashboard{
+	{
+		Name:        "overview",
+		URL:         "/",
+		Title:       "仪表板",
+		Description: "仪表板示例",
+		Widgets:     widgetList("smallboxes", "report", "orders", "products", "tabs", "browsers", "worldmap", "heatmap"),
+	},
+	{
+		Name:        "sales",
+		URL:         "/dashboard/sales",
+		Title:       "销售仪表板",
+		Description: "销售额、订单和产品",
+		Widgets:     widgetList("report", "orders", "top_products", "orders_heatmap"),
+	},
+	{
+		Name:        "ops",
+		URL:         "/dashboard/ops",
+		Title:       "运维仪表板",
+		Description: "系统运行状态",
+		Widgets:     widgetList("smallboxes", "system", "heatmap", "tabs"),
+	},
+	{
+		Name:        "marketing",
+		URL:         "/dashboard/marketing",
+		Title:       "市场仪表板",
+		Description: "用户增长和访问来源",
+		Widgets:     widgetList("smallboxes", "browsers", "products", "worldmap"),
+	},
+}
+
+// findDashboard 根据名称查找仪表板定义
+func findDashboard(name string) (Dashboard, bool) {
+	for _, d := range Dashboards {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Dashboard{}, false
+}
+
+// NewDashboardPage 返回指定仪表板的页面处理函数
+//
+// 参数:
+//   - name: Dashboards 中定义的仪表板名称
+//
+// 返回值:
+//   - types.GetPanelInfoFn: 页面处理函数，可直接传给 eng.HTML
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url("/dashboard/sales"), pages.NewDashboardPage("sales"))
+//
+// 注意事项:
+//   - 仪表板定义在每次请求时查找，注册路由后修改 Dashboards 同样生效
+//   - 名称不存在时页面显示错误信息
+func NewDashboardPage(name string) types.GetPanelInfoFn {
+	return func(ctx *context.Context) (types.Panel, error) {
+		d, ok := findDashboard(name)
+		if !ok {
+			return types.Panel{}, fmt.Errorf("未定义的仪表板: %s", name)
+		}
+		return renderDashboard(ctx, d)
+	}
+}
+
+// renderDashboard 按仪表板定义生成页面
+//
+// 参数:
+//   - ctx: 请求上下文对象
+//   - d: 仪表板定义
+//
+// 返回值:
+//   - types.Panel: 页面面板对象
+//   - error: 包含未注册的组件时返回错误，单个组件生成失败只在该组件位置显示提示
+//
+// 功能说明:
+//  1. 从查询参数中解析日期范围和环比粒度，所有组件共享
+//  2. 并发调用组件生成函数（见 loadWidgets），延迟加载的组件只输出占位内容，
+//     配置了宽度时覆盖组件的默认宽度
+//  3. 按当前管理员在该仪表板上保存的布局排列组件
+func renderDashboard(ctx *context.Context, d Dashboard) (types.Panel, error) {
+	dr := parseDateRange(ctx)
+	g := models.ParseGranularity(ctx.Query("granularity"))
+
+	repos := Repos
+	list, err := loadWidgets(ctx.Request.Context(), d, widgets.Params{Ctx: ctx, Range: dr, Granularity: g, Repos: repos})
+	if err != nil {
+		return types.Panel{}, err
+	}
+
+	layout := repos.Layouts.Get(ctx.Request.Context(), auth.Auth(ctx).Id, d.Name)
+
+	// 首页仪表板的标题可以在系统设置中修改，设置值由管理员填写，按文本显示
+	title := template.HTML(d.Title)
+	if d.URL == "/" {
+		title = template.HTML(html.EscapeString(repos.Settings.String(ctx.Request.Context(), models.SettingDashboardTitle, d.Title)))
+	}
+
+	// Content: 依次为仪表板切换菜单、日期范围选择器和按布局排列的组件
+	return types.Panel{
+		Content:     dashboardSwitcher(d.Name, dr, g) + dateRangePicker(dr, g) + renderDashboardLayout(d.Name, list, layout),
+		Title:       title,
+		Description: template.HTML(d.Description),
+	}, nil
+}
+
+// dashboardSwitcher 生成仪表板切换下拉菜单
+// 菜单中的链接带上当前的日期范围和环比粒度，切换仪表板时保持统计区间不变
+//
+// 参数:
+//   - current: 当前仪表板名称，在菜单中高亮显示
+//   - r: 当前生效的日期范围
+//   - g: 当前生效的环比粒度
+func dashboardSwitcher(current string, r widgets.DateRange, g models.Granularity) template.HTML {
+	query := url.Values{}
+	query.Set("from", r.From.Format(widgets.DateLayout))
+	query.Set("to", r.To.Format(widgets.DateLayout))
+	query.Set("granularity", string(g))
+
+	title := ""
+	items := ""
+	for _, d := range Dashboards {
+		active := ""
+		if d.Name == current {
+			active = ` class="active"`
+			title = d.Title
+		}
+		items += fmt.Sprintf(`<li%s><a href="%s?%s">%s</a></li>`,
+			active, config.Url(d.URL), query.Encode(), template.HTMLEscapeString(d.Title))
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="btn-group pull-left" style="margin-bottom:10px">
+  <button type="button" class="btn btn-sm btn-default dropdown-toggle" data-toggle="dropdown" aria-expanded="false">
+    <i class="fa fa-dashboard"></i> %s <span class="caret"></span>
+  </button>
+  <ul class="dropdown-menu">%s</ul>
+</div>`, template.HTMLEscapeString(title), items))
+}
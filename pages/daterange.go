@@ -0,0 +1,92 @@
+// pages 包 - 页面处理器
+// 本文件定义仪表板使用的日期范围解析和选择器组件
+// 日期范围通过查询参数 ?from=&to= 传递，环比粒度通过 ?granularity= 传递，所有仪表板组件共享
+
+package pages
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+)
+
+// defaultRangeDays 未指定日期范围时默认展示的天数
+const defaultRangeDays = 30
+
+// maxRangeDays 日期范围最多包含的天数
+// 折线图按天生成标签和数据，不限制时一个请求就可以让服务端循环数百万天
+const maxRangeDays = 366
+
+// parseDateRange 从请求的查询参数中解析日期范围
+//
+// 参数:
+//   - ctx: 请求上下文对象，读取其中的 from 和 to 查询参数
+//
+// 返回值:
+//   - widgets.DateRange: 解析后的日期范围
+//
+// 功能说明:
+//  1. 参数缺失或格式错误时，使用截止到今天的最近 30 天
+//  2. 开始日期晚于结束日期时，自动交换两者
+//  3. 超过 maxRangeDays 天时保留结束日期，开始日期提前到结束日期之前 maxRangeDays-1 天
+func parseDateRange(ctx *context.Context) widgets.DateRange {
+	today := time.Now()
+	to, err := time.ParseInLocation(widgets.DateLayout, ctx.Query("to"), time.Local)
+	if err != nil {
+		to = today
+	}
+	from, err := time.ParseInLocation(widgets.DateLayout, ctx.Query("from"), time.Local)
+	if err != nil {
+		from = to.AddDate(0, 0, -(defaultRangeDays - 1))
+	}
+	if from.After(to) {
+		from, to = to, from
+	}
+	r := widgets.DateRange{
+		From: time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local),
+		To:   time.Date(to.Year(), to.Month(), to.Day(), 23, 59, 59, 0, time.Local),
+	}
+	if r.Days() > maxRangeDays {
+		r.From = time.Date(to.Year(), to.Month(), to.Day()-(maxRangeDays-1), 0, 0, 0, 0, time.Local)
+	}
+	return r
+}
+
+// dateRangePicker 生成日期范围和环比粒度选择器的 HTML
+// 选择器以 GET 方式提交到当前页面，提交后整个仪表板按新的范围重新渲染
+//
+// 参数:
+//   - r: 当前生效的日期范围，用于填充输入框的默认值
+//   - g: 当前生效的环比粒度
+//
+// 返回值:
+//   - template.HTML: 选择器表单的 HTML 内容
+func dateRangePicker(r widgets.DateRange, g models.Granularity) template.HTML {
+	options := ""
+	for _, item := range models.Granularities {
+		selected := ""
+		if item.Value == g {
+			selected = " selected"
+		}
+		options += fmt.Sprintf(`<option value="%s"%s>%s</option>`, item.Value, selected, item.Label)
+	}
+
+	return template.HTML(fmt.Sprintf(`<form method="get" class="form-inline pull-right" style="margin-bottom:10px">
+  <div class="form-group">
+    <label for="dashboard-from">从</label>
+    <input type="date" class="form-control input-sm" id="dashboard-from" name="from" value="%s">
+  </div>
+  <div class="form-group">
+    <label for="dashboard-to">至</label>
+    <input type="date" class="form-control input-sm" id="dashboard-to" name="to" value="%s">
+  </div>
+  <div class="form-group">
+    <select class="form-control input-sm" name="granularity">%s</select>
+  </div>
+  <button type="submit" class="btn btn-sm btn-primary">筛选</button>
+</form><div class="clearfix"></div>`, r.From.Format(widgets.DateLayout), r.To.Format(widgets.DateLayout), options))
+}
@@ -0,0 +1,75 @@
+package pages
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+)
+
+func TestParseDateRange(t *testing.T) {
+	today := time.Now().Format(widgets.DateLayout)
+	cases := []struct {
+		name     string
+		query    string
+		from, to string
+		days     int
+	}{
+		{"默认最近 30 天", "", time.Now().AddDate(0, 0, -(defaultRangeDays - 1)).Format(widgets.DateLayout), today, defaultRangeDays},
+		{"指定范围", "from=2024-03-01&to=2024-03-31", "2024-03-01", "2024-03-31", 31},
+		{"同一天", "from=2024-03-01&to=2024-03-01", "2024-03-01", "2024-03-01", 1},
+		{"开始晚于结束时交换", "from=2024-03-31&to=2024-03-01", "2024-03-01", "2024-03-31", 31},
+		{"格式错误的开始日期", "from=x&to=2024-03-31", "2024-03-02", "2024-03-31", defaultRangeDays},
+		{"正好 maxRangeDays 天", "from=2024-01-01&to=2024-12-31", "2024-01-01", "2024-12-31", maxRangeDays},
+		{"超过 maxRangeDays 天时截断", "from=2023-01-01&to=2024-12-31", "2024-01-01", "2024-12-31", maxRangeDays},
+		{"极大的范围", "from=0001-01-01&to=9999-12-31", "9998-12-31", "9999-12-31", maxRangeDays},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/admin?"+c.query, nil)
+			r := parseDateRange(context.NewContext(req))
+			if got := r.From.Format(widgets.DateLayout); got != c.from {
+				t.Errorf("From = %s, want %s", got, c.from)
+			}
+			if got := r.To.Format(widgets.DateLayout); got != c.to {
+				t.Errorf("To = %s, want %s", got, c.to)
+			}
+			if got := r.Days(); got != c.days {
+				t.Errorf("Days() = %d, want %d", got, c.days)
+			}
+			if got := len(r.Labels()); got != c.days {
+				t.Errorf("len(Labels()) = %d, want %d", got, c.days)
+			}
+		})
+	}
+}
+
+func TestDateRangeDays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(widgets.DateLayout, s, time.Local)
+		return d
+	}
+	cases := []struct {
+		from, to string
+		days     int
+		previous string
+	}{
+		{"2024-03-01", "2024-03-31", 31, "2024-01-30"},
+		{"2024-03-10", "2024-03-10", 1, "2024-03-09"},
+		// 跨越夏令时切换的日期
+		{"2024-03-30", "2024-04-01", 3, "2024-03-27"},
+		// 超过 time.Duration 能表示的约 292 年
+		{"1700-01-01", "1999-12-31", 109572, "1400-01-02"},
+	}
+	for _, c := range cases {
+		r := widgets.DateRange{From: day(c.from), To: day(c.to).Add(24*time.Hour - time.Second)}
+		if got := r.Days(); got != c.days {
+			t.Errorf("%s: Days() = %d, want %d", r, got, c.days)
+		}
+		if got := r.Previous().From.Format(widgets.DateLayout); got != c.previous {
+			t.Errorf("%s: Previous().From = %s, want %s", r, got, c.previous)
+		}
+	}
+}
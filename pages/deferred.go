@@ -0,0 +1,99 @@
+// pages 包 - 页面处理器
+// 本文件实现延迟加载组件的占位内容和内容接口
+// 仪表板页面中延迟加载的组件先显示加载提示，布局脚本在页面显示后
+// 请求后台路由前缀下的 /dashboard/widget 获取组件内容并替换占位内容
+
+package pages
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+)
+
+// deferredWidgetPath 延迟加载组件的内容接口路径，相对于后台的路由前缀，需要与 app.RegisterRoutes 中注册的路由一致
+const deferredWidgetPath = "/dashboard/widget"
+
+// deferredWidgetURL 返回组件内容接口的地址，带上仪表板当前的日期范围和环比粒度
+func deferredWidgetURL(dashboard, widget string, p widgets.Params) string {
+	query := url.Values{}
+	query.Set("dashboard", dashboard)
+	query.Set("widget", widget)
+	query.Set("from", p.Range.From.Format(widgets.DateLayout))
+	query.Set("to", p.Range.To.Format(widgets.DateLayout))
+	query.Set("granularity", string(p.Granularity))
+	return config.Url(deferredWidgetPath) + "?" + query.Encode()
+}
+
+// deferredPlaceholder 生成延迟加载组件的占位内容
+// 布局脚本查找 .dashboard-deferred 元素，按 data-src 加载内容后整体替换
+func deferredPlaceholder(src string, info widgets.Deferred) widgets.Widget {
+	content := template.HTML(fmt.Sprintf(`<div class="box box-default dashboard-deferred" data-src="%s">
+  <div class="box-body text-center text-muted" style="padding:40px 0">
+    <i class="fa fa-spinner fa-spin"></i> 加载中…
+  </div>
+</div>`, template.HTMLEscapeString(src)))
+
+	return widgets.Widget{Title: info.Title, Width: info.Width, Content: content}
+}
+
+// DeferredWidget 返回仪表板中某个组件的HTML内容
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数为 dashboard、widget 以及仪表板页面的 from、to、granularity
+//
+// 使用示例:
+//
+//	eng.Data("GET", config.Url("/dashboard/widget"), pages.DeferredWidget)
+//
+// 注意事项:
+//   - 组件参数从仪表板配置中读取，只能加载该仪表板中配置的组件
+//   - 组件出错或超时时同样返回 200 和错误提示，与直接渲染时的显示一致
+func DeferredWidget(ctx *context.Context) {
+	d, ok := findDashboard(ctx.Query("dashboard"))
+	if !ok {
+		ctx.HTML(http.StatusBadRequest, "未定义的仪表板")
+		return
+	}
+
+	name := ctx.Query("widget")
+	var (
+		cfg   WidgetConfig
+		found bool
+	)
+	for _, c := range d.Widgets {
+		if c.Name == name {
+			cfg, found = c, true
+			break
+		}
+	}
+	if !found {
+		ctx.HTML(http.StatusNotFound, "仪表板中没有该组件")
+		return
+	}
+
+	fn, ok := widgets.Get(cfg.Name)
+	if !ok {
+		ctx.HTML(http.StatusNotFound, "未注册的仪表板组件")
+		return
+	}
+
+	w, err := runWidget(ctx.Request.Context(), fn, widgets.Params{
+		Ctx:         ctx,
+		Range:       parseDateRange(ctx),
+		Granularity: models.ParseGranularity(ctx.Query("granularity")),
+		Options:     cfg.Options,
+		Repos:       Repos,
+	})
+	if err != nil {
+		w = widgetPlaceholder(cfg.Name, err)
+	}
+
+	ctx.HTML(http.StatusOK, string(w.Content))
+}
@@ -0,0 +1,223 @@
+// pages 包 - 页面处理器
+// 本文件实现动态表单的填写：按 form_definitions 中的定义生成表单，提交的内容保存到 form_submissions
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/language"
+	template2 "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"gorm.io/gorm"
+)
+
+// dynamicFormID 动态表单页面中表单元素的 id
+const dynamicFormID = "dynamic-form"
+
+// DynamicFormPage 填写动态表单的页面
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 id 为表单定义的编号
+//
+// 返回值:
+//   - types.Panel: 按表单定义生成的表单，标题和说明来自定义
+//   - error: 表单不存在或定义无效时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(tables.DynamicFormURL), pages.DynamicFormPage)
+//
+// 注意事项:
+//   - 表单以 AJAX 提交到 tables.DynamicFormSubmitURL（见 SubmitDynamicForm），提交成功后清空表单以便再次填写
+func DynamicFormPage(ctx *context.Context) (types.Panel, error) {
+	def, fields, err := findDynamicForm(ctx, ctx.Query("id"))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return types.Panel{}, errors.New("表单不存在")
+	case err != nil:
+		return types.Panel{}, err
+	}
+	panel, rules, err := tables.BuildDynamicForm(fields)
+	if err != nil {
+		return types.Panel{}, fmt.Errorf("表单定义无效: %v", err)
+	}
+
+	components := template2.Get(ctx, config.GetTheme())
+
+	submit := components.Button().SetType("submit").
+		SetContent("提交").
+		SetThemePrimary().
+		SetOrientationRight().
+		SetLoadingText(icon.Icon("fa-spinner fa-spin", 2) + `提交中`).
+		GetContent()
+	reset := components.Button().SetType("reset").
+		SetContent(language.GetFromHtml("Reset")).
+		SetThemeWarning().
+		SetOrientationLeft().
+		GetContent()
+
+	aform := components.Form().
+		SetId(dynamicFormID).
+		SetContent(panel.FieldsWithDefaultValue()).
+		SetPrefix(config.PrefixFixSlash()).
+		SetUrl(config.Url(tables.DynamicFormSubmitURL)).
+		SetTitle(template.HTML(template.HTMLEscapeString(def.Title))).
+		SetHiddenFields(map[string]string{
+			"form_id": strconv.FormatUint(uint64(def.ID), 10),
+		}).
+		SetAjax(`swal(data.msg, '', 'success'); form[0].reset(); form.find('select').trigger('change');`,
+			`swal(data.responseJSON ? data.responseJSON.msg : '提交失败', '', 'error');`).
+		SetOperationFooter(components.Col().GetContent() +
+			components.Col().SetSize(types.SizeMD(8)).SetContent(submit+reset).GetContent())
+
+	// 表单不经过表格模型生成，校验规则的提示直接加在页面内容后面
+	script := template.HTML("<script>" + string(rules.JS(ctx.Lang())) + "</script>")
+
+	return types.Panel{
+		Content: components.Box().
+			SetHeader(aform.GetDefaultBoxHeader(true)).
+			WithHeadBorder().
+			SetBody(aform.GetContent()).
+			GetContent() + script,
+		Title:       template.HTML(template.HTMLEscapeString(def.Title)),
+		Callbacks:   panel.Callbacks,
+		Description: template.HTML(template.HTMLEscapeString(def.Description)),
+	}, nil
+}
+
+// SubmitDynamicForm 处理动态表单的提交
+//
+// 参数:
+//   - ctx: 请求上下文对象，参数 form_id 为表单定义的编号，其余为表单字段
+//
+// 返回值（JSON）:
+//   - 200: {"code": 200, "msg": "提交成功"}
+//   - 400: 校验不通过，或选择类字段的值不在选项中；404: 表单不存在；500: 保存失败
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url(tables.DynamicFormSubmitURL), pages.SubmitDynamicForm)
+//
+// 注意事项:
+//   - 只保存定义中的字段，多选字段保存为数组，其余保存为字符串
+//   - 按提交时的定义校验；打开表单后定义被修改时，以修改后的定义为准
+func SubmitDynamicForm(ctx *context.Context) {
+	if err := ctx.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		formError(ctx, http.StatusBadRequest, "读取表单失败: "+err.Error())
+		return
+	}
+	def, fields, err := findDynamicForm(ctx, ctx.Request.PostForm.Get("form_id"))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		formError(ctx, http.StatusNotFound, "表单不存在")
+		return
+	case err != nil:
+		formError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, rules, err := tables.BuildDynamicForm(fields)
+	if err != nil {
+		formError(ctx, http.StatusBadRequest, "表单定义无效: "+err.Error())
+		return
+	}
+
+	// 多选和多值的下拉框以 field[] 提交，其余以 field 提交
+	// 没有提交的字段（如未勾选的多选）按空值校验，必填规则对它们同样生效
+	values := make(map[string][]string, len(fields))
+	for _, f := range fields {
+		v := ctx.Request.PostForm[f.Field]
+		if len(v) == 0 {
+			v = ctx.Request.PostForm[f.Field+"[]"]
+		}
+		if len(v) == 0 {
+			v = []string{""}
+		}
+		values[f.Field] = v
+	}
+	if errs := rules.Validate(ctx.Lang(), values); len(errs) > 0 {
+		formError(ctx, http.StatusBadRequest, errs.Error())
+		return
+	}
+
+	data := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		v := values[f.Field]
+		if err := checkDynamicFormOptions(f, v); err != nil {
+			formError(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		if f.Multiple() {
+			selected := make([]string, 0, len(v))
+			for _, s := range v {
+				if s != "" {
+					selected = append(selected, s)
+				}
+			}
+			data[f.Field] = selected
+		} else {
+			data[f.Field] = v[0]
+		}
+	}
+
+	if _, err := models.CreateFormSubmission(ctx.Request.Context(), def.ID, auth.Auth(ctx).Id, data); err != nil {
+		formError(ctx, http.StatusInternalServerError, "保存失败: "+err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "提交成功",
+	})
+}
+
+// findDynamicForm 按编号读取表单定义并解析字段
+// 编号无效时返回错误，表单不存在时返回 gorm.ErrRecordNotFound
+func findDynamicForm(ctx *context.Context, id string) (models.FormDefinition, []models.FormFieldDef, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return models.FormDefinition{}, nil, errors.New("无效的表单编号")
+	}
+	def, err := models.FindFormDefinition(ctx.Request.Context(), uint(n))
+	if err != nil {
+		return def, nil, err
+	}
+	fields, err := models.ParseFormFields(def.Fields)
+	if err != nil {
+		return def, nil, fmt.Errorf("表单定义无效: %v", err)
+	}
+	return def, fields, nil
+}
+
+// checkDynamicFormOptions 检查下拉框、单选、多选和开关的值是否在选项中，空值不检查（由 required 规则负责）
+func checkDynamicFormOptions(f models.FormFieldDef, values []string) error {
+	options := f.FieldOptions()
+	if len(options) == 0 {
+		return nil
+	}
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		found := false
+		for _, o := range options {
+			if o.Value == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: 选项 %q 无效", f.Label, v)
+		}
+	}
+	return nil
+}
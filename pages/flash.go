@@ -0,0 +1,65 @@
+// pages 包 - 页面处理器
+// 本文件实现一次性提示（flash）：处理表单提交后把提示写入 Cookie 并跳转，下一次打开页面时显示并清除
+// 用于不以 AJAX 提交的表单，提交后跳转回原页面，避免刷新页面时重复提交
+
+package pages
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/purpose168/GoAdmin/context"
+)
+
+// flashCookie 保存一次性提示的 Cookie 名称
+const flashCookie = "goadmin_flash"
+
+// 一次性提示的类型，对应 Bootstrap 提示框的样式
+const (
+	flashSuccess = "success"
+	flashError   = "danger"
+)
+
+// flashMessage 一次性提示
+type flashMessage struct {
+	Type string `json:"type"`
+	Msg  string `json:"msg"`
+}
+
+// setFlash 保存一次性提示，通常紧接着跳转到显示提示的页面
+// 提示只保存 5 分钟，打开页面后由 takeFlash 清除
+func setFlash(ctx *context.Context, typ, msg string) {
+	raw, _ := json.Marshal(flashMessage{Type: typ, Msg: msg})
+	ctx.SetCookie(&http.Cookie{
+		Name:     flashCookie,
+		Value:    url.QueryEscape(string(raw)),
+		Path:     "/",
+		MaxAge:   300,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// takeFlash 读取并清除一次性提示，返回提示框的 HTML；没有提示时返回空字符串
+func takeFlash(ctx *context.Context) template.HTML {
+	value := ctx.Cookie(flashCookie)
+	if value == "" {
+		return ""
+	}
+	ctx.SetCookie(&http.Cookie{Name: flashCookie, Path: "/", MaxAge: -1})
+
+	var m flashMessage
+	raw, err := url.QueryUnescape(value)
+	if err != nil || json.Unmarshal([]byte(raw), &m) != nil || m.Msg == "" {
+		return ""
+	}
+	if m.Type != flashSuccess {
+		m.Type = flashError
+	}
+	return template.HTML(fmt.Sprintf(`<div class="alert alert-%s alert-dismissible">`+
+		`<button type="button" class="close" data-dismiss="alert" aria-hidden="true">&times;</button>%s</div>`,
+		m.Type, template.HTMLEscapeString(m.Msg)))
+}
@@ -8,7 +8,19 @@
 package pages
 
 import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
 	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
 	"github.com/purpose168/GoAdmin/modules/config"
 	"github.com/purpose168/GoAdmin/modules/db"
 	"github.com/purpose168/GoAdmin/modules/language"
@@ -17,6 +29,7 @@ import (
 	"github.com/purpose168/GoAdmin/template/icon"
 	"github.com/purpose168/GoAdmin/template/types"
 	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
 )
 
 // GetFormContent 返回表单页面的内容
@@ -58,11 +71,11 @@ import (
 //	import "github.com/purpose168/GoAdmin-example/pages"
 //
 //	// 在路由中注册页面
-//	eng.HTML("GET", "/admin/form", pages.GetFormContent)
+//	eng.HTML("GET", config.Url("/form"), pages.GetFormContent)
 //
 // 注意事项:
 //   - 该函数展示了GoAdmin表单系统的各种功能
-//   - 表单提交地址为 /admin/form/update
+//   - 表单以 AJAX 提交到 /admin/form/update（见 SubmitForm），"提交并跳转"按钮以普通表单提交，结果在跳转回本页面后显示
 //   - 使用了语言包支持多语言
 //   - 表单字段可以根据需要增删或修改
 //
@@ -109,11 +122,14 @@ func GetFormContent(ctx *context.Context) (types.Panel, error) {
 		SetOrientationLeft().
 		GetContent()
 
-	// 创建第二列，包含两个按钮
+	// "提交并跳转"不经过 AJAX，以普通表单提交，提交后跳转回本页面并在上方显示结果（见 SubmitForm）
+	btn3 := template.HTML(`<button type="button" class="btn btn-default pull-right demo-form-plain-submit" style="margin-right:10px">提交并跳转</button>`)
+
+	// 创建第二列，包含三个按钮
 	// SetSize: 设置列的宽度，SizeMD(8)表示在中等屏幕上占8/12
-	// SetContent: 设置列的内容为两个按钮
+	// SetContent: 设置列的内容为三个按钮
 	col2 := components.Col().SetSize(types.SizeMD(8)).
-		SetContent(btn1 + btn2).GetContent()
+		SetContent(btn1 + btn3 + btn2).GetContent()
 
 	// 创建新的表单面板
 	// NewFormPanel: 创建一个空的表单面板
@@ -143,6 +159,10 @@ func GetFormContent(ctx *context.Context) (types.Panel, error) {
 	// form.Email: 邮箱输入框，会自动验证邮箱格式
 	panel.AddField("邮箱", "email", db.Varchar, form.Email).FieldDefault("xxxx@xxx.com")
 
+	// 添加手机号字段（文本输入）
+	// 格式由 demoFormRules 中的 regexp 规则校验
+	panel.AddField("手机号", "mobile", db.Varchar, form.Text)
+
 	// ========== 日期时间字段 ==========
 
 	// 添加生日字段（日期时间输入）
@@ -178,8 +198,9 @@ func GetFormContent(ctx *context.Context) (types.Panel, error) {
 	// form.Multifile: 多文件上传组件
 	// FieldOptionExt: 设置扩展选项
 	// maxFileCount: 最大文件数量限制为10
+	// 上传的文件按 config.yml 的 upload 配置项保存在本地或 S3 中（见 saveCertificates）
 	panel.AddField("证书", "certificate", db.Varchar, form.Multifile).FieldOptionExt(map[string]interface{}{
-		"maxFileCount": 10,
+		"maxFileCount": demoCertificateMax,
 	})
 
 	// ========== 数值字段 ==========
@@ -243,6 +264,16 @@ func main() {
 			{Value: "1"},
 		})
 
+	// 添加是否在职字段（开关控件）和公司名称字段
+	// 开关的第一个选项为打开时的值，第二个为关闭时的值
+	// 公司名称只在开关打开时显示，见页面底部的 tables.ShowWhenJS
+	panel.AddField("是否在职", "employed", db.Tinyint, form.Switch).
+		FieldOptions(types.FieldOptions{
+			{Text: "是", Value: "1"},
+			{Text: "否", Value: "0"},
+		})
+	panel.AddField("公司名称", "company", db.Varchar, form.Text)
+
 	// 添加水果字段（下拉选择框）
 	// form.SelectBox: 下拉选择框组件
 	// FieldOptions: 设置选项列表
@@ -314,35 +345,32 @@ func main() {
 	// 添加一行三个字段（省、市、区）
 	// AddRow: 添加一行多个字段
 	// FieldRowWidth: 设置字段在行中的宽度（2/12）
+	// 选项从 regions 表读取：选择省份后加载城市，选择城市后加载区域（见 tables.RegionChildren）
+	// 打开页面时选中最近一次提交的值，城市和区域的选项按已选的上一级加载
+	region := demoFormRegion(ctx)
 	panel.AddRow(func(pa *types.FormPanel) {
 		// 省份字段
-		panel.AddField("省份", "province", db.Tinyint, form.SelectSingle).
-			FieldOptions(types.FieldOptions{
-				{Text: "北京", Value: "0"},
-				{Text: "上海", Value: "1"},
-				{Text: "广东", Value: "2"},
-				{Text: "重庆", Value: "3"},
-			}).FieldRowWidth(2)
+		// FieldOnChooseAjax: 选择省份后请求下一级的选项，填入城市字段
+		panel.AddField("省份", "province", db.Int, form.SelectSingle).
+			FieldOptions(tables.RegionOptions(ctx.Request.Context(), "0")).
+			FieldDefault(region["province"]).
+			FieldOnChooseAjax("city", "/choose/region/province", tables.RegionChildren).
+			FieldRowWidth(2)
 		// 城市字段
 		// FieldHeadWidth: 设置标签宽度（2/12）
 		// FieldInputWidth: 设置输入框宽度（10/12）
-		panel.AddField("城市", "city", db.Tinyint, form.SelectSingle).
-			FieldOptions(types.FieldOptions{
-				{Text: "北京", Value: "0"},
-				{Text: "上海", Value: "1"},
-				{Text: "广州", Value: "2"},
-				{Text: "深圳", Value: "3"},
-			}).FieldRowWidth(3).FieldHeadWidth(2).FieldInputWidth(10)
+		panel.AddField("城市", "city", db.Int, form.SelectSingle).
+			FieldOptions(tables.RegionOptions(ctx.Request.Context(), region["province"])).
+			FieldDefault(region["city"]).
+			FieldOnChooseAjax("district", "/choose/region/city", tables.RegionChildren).
+			FieldRowWidth(3).FieldHeadWidth(2).FieldInputWidth(10)
 		// 区域字段
 		// FieldHeadWidth: 设置标签宽度（2/12）
 		// FieldInputWidth: 设置输入框宽度（9/12）
-		panel.AddField("区域", "district", db.Tinyint, form.SelectSingle).
-			FieldOptions(types.FieldOptions{
-				{Text: "朝阳", Value: "0"},
-				{Text: "海珠", Value: "1"},
-				{Text: "浦东", Value: "2"},
-				{Text: "宝安", Value: "3"},
-			}).FieldRowWidth(3).FieldHeadWidth(2).FieldInputWidth(9)
+		panel.AddField("区域", "district", db.Int, form.SelectSingle).
+			FieldOptions(tables.RegionOptions(ctx.Request.Context(), region["city"])).
+			FieldDefault(region["district"]).
+			FieldRowWidth(3).FieldHeadWidth(2).FieldInputWidth(9)
 	})
 
 	// ========== 多值和表格字段 ==========
@@ -359,18 +387,28 @@ func main() {
 		panel.AddField("值", "value", db.Varchar, form.Text).FieldHideLabel()
 	})
 
+	// 添加工作经历（可重复的分组）
+	// 与上面的表格不同，每一段包含多个不同类型的字段，提交后保存到 work_experiences 子表（见 demoExperiences）
+	panel.AddField("工作经历", "experiences", db.Text, form.Custom).
+		FieldCustomContent(demoExperiences.Content(workExperienceRows(ctx))).
+		FieldCustomJs(demoExperiences.JS())
+
+	// 校验规则：必填的字段显示星号，浏览器中输入时提示，提交后由 SubmitForm 再次校验
+	rules := demoFormRules()
+	rules.MarkRequired(panel)
+
 	// ========== 标签页分组 ==========
 
 	// 设置标签页分组
 	// 将所有字段分成三个标签页
 	panel.SetTabGroups(types.TabGroups{
 		// 第一个标签页: 基础输入字段
-		{"name", "age", "homepage", "email", "birthday", "time", "time_range", "date_range", "password", "ip",
+		{"name", "age", "homepage", "email", "mobile", "birthday", "time", "time_range", "date_range", "password", "ip",
 			"certificate", "currency", "rate", "reward", "content", "code"},
 		// 第二个标签页: 选择类字段
-		{"website", "snacks", "fruit", "gender", "cat", "drink", "province", "city", "district", "experience"},
+		{"website", "employed", "company", "snacks", "fruit", "gender", "cat", "drink", "province", "city", "district", "experience"},
 		// 第三个标签页: 多值字段和表格
-		{"employee", "setting"},
+		{"employee", "setting", "experiences"},
 	})
 
 	// 设置标签页标题
@@ -392,29 +430,338 @@ func main() {
 	// SetHiddenFields: 设置隐藏字段
 	// SetOperationFooter: 设置操作按钮区域
 	aform := components.Form().
+		SetId(demoFormID).
 		SetTabHeaders(headers).
 		SetTabContents(fields).
 		SetPrefix(config.PrefixFixSlash()).
-		SetUrl("/admin/form/update").
+		SetUrl(config.Url(demoFormSubmitPath)).
 		SetTitle("表单").
 		SetHiddenFields(map[string]string{
-			form2.PreviousKey: "/admin",
+			form2.PreviousKey: config.Url("/"),
 		}).
+		SetAjax(`swal(data.msg, '', 'success'); form.trigger('form-draft:clear');`,
+			`swal(data.responseJSON ? data.responseJSON.msg : '提交失败', '', 'error');`).
 		SetOperationFooter(col1 + col2)
 
+	// 字段联动显示、省市区联动和校验规则的提示
+	// 表单不经过表格模型生成，不会输出 FormPanel 的 JS，这些 JS 和 FieldOnChooseAjax 生成的 FooterHtml 直接加在页面内容后面
+	scripts := panel.FooterHtml + template.HTML("<script>"+string(tables.ShowWhenJS(
+		tables.ShowWhen{Field: "company", When: "employed", Values: []string{"1"}},
+	))+string(tables.RegionResetJS(demoRegionFields...))+
+		string(rules.JS(ctx.Lang()))+string(demoFormPlainSubmitJS)+"</script>")
+
+	// 草稿：定时自动保存，再次打开时在表单上方提示恢复（见 formDraft）
+	// 富文本和代码编辑器的内容恢复后编辑器中不显示，不保存；工作经历只恢复页面上已有的段
+	// 城市和区域的选项随上一级加载，恢复草稿时没有对应的选项，省市区都不保存
+	draftBanner, draftScript := formDraft(ctx, demoFormKey, demoFormID, "content", "code", "province", "city", "district")
+
 	// 返回页面面板
 	// Content: 页面内容，包含表单
 	// Title: 页面标题
 	// Callbacks: 回调函数
 	// Description: 页面描述
 	return types.Panel{
-		Content: components.Box().
+		Content: takeFlash(ctx) + draftBanner + components.Box().
 			SetHeader(aform.GetDefaultBoxHeader(true)).
 			WithHeadBorder().
 			SetBody(aform.GetContent()).
-			GetContent(),
+			GetContent() + scripts + draftScript,
 		Title:       "表单",
 		Callbacks:   panel.Callbacks,
 		Description: "表单示例",
 	}, nil
 }
+
+const (
+	// demoFormID 表单页面中表单元素的 id
+	demoFormID = "demo-form"
+
+	// demoFormKey 表单页面的草稿标识
+	demoFormKey = "demo"
+
+	// demoFormPath 表单页面在后台 URL 前缀下的路径，不以 AJAX 提交时跳转回该页面
+	demoFormPath = "/form"
+
+	// demoFormSubmitPath 表单提交地址在后台 URL 前缀下的路径
+	demoFormSubmitPath = "/form/update"
+)
+
+// demoFormRules 表单页面的校验规则，页面中的提示和 SubmitForm 的校验共用
+func demoFormRules() *tables.FormRules {
+	return tables.NewFormRules().
+		FieldRules("name", "姓名", "required|max:50").
+		FieldRules("age", "年龄", "numeric").
+		FieldRules("email", "邮箱", "email|max:100").
+		FieldRules("mobile", "手机号", `regexp:^1\d{10}$`).
+		FieldRules("company", "公司名称", "max:100")
+}
+
+// demoRegionFields 表单页面中联动的省、市、区，从上到下排列
+var demoRegionFields = []tables.RegionField{
+	{Field: "province", Label: "省份"},
+	{Field: "city", Label: "城市"},
+	{Field: "district", Label: "区域"},
+}
+
+// demoFormRegion 读取当前管理员最近一次提交的省、市、区，键为字段名；没有提交过时为空
+func demoFormRegion(ctx *context.Context) map[string]string {
+	region := make(map[string]string, len(demoRegionFields))
+	submission, err := models.LatestDemoFormSubmission(ctx.Request.Context(), auth.Auth(ctx).Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return region
+	}
+	if err != nil {
+		log.Printf("读取表单提交记录失败: %s\n", err)
+		return region
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(submission.Data), &data); err != nil {
+		log.Printf("解析表单提交记录 %d 失败: %s\n", submission.ID, err)
+		return region
+	}
+	for _, f := range demoRegionFields {
+		if v, ok := data[f.Field].(string); ok {
+			region[f.Field] = v
+		}
+	}
+	return region
+}
+
+// demoExperiences 表单页面中的"工作经历"，每个管理员的工作经历保存在 work_experiences 表中
+var demoExperiences = tables.RepeatableGroup{
+	Field: "experiences",
+	Label: "工作经历",
+	Max:   10,
+	Fields: []tables.GroupField{
+		{Field: "company", Label: "公司", Rules: "required|max:100"},
+		{Field: "title", Label: "职位", Rules: "required|max:100"},
+		{Field: "start_date", Label: "入职日期", Type: tables.GroupDate, Rules: "required"},
+		{Field: "end_date", Label: "离职日期（在职可不填）", Type: tables.GroupDate},
+		{Field: "description", Label: "工作内容", Type: tables.GroupTextArea, Rules: "max:500", Width: 12},
+	},
+}
+
+// workExperienceRows 读取当前管理员的工作经历，作为"工作经历"分组中已有的各段
+func workExperienceRows(ctx *context.Context) []map[string]string {
+	list, err := models.FindWorkExperiences(ctx.Request.Context(), auth.Auth(ctx).Id)
+	if err != nil {
+		log.Printf("读取工作经历失败: %s\n", err)
+		return nil
+	}
+	rows := make([]map[string]string, len(list))
+	for i, e := range list {
+		rows[i] = map[string]string{
+			"company":     e.Company,
+			"title":       e.Title,
+			"start_date":  e.StartDate,
+			"end_date":    e.EndDate,
+			"description": e.Description,
+		}
+	}
+	return rows
+}
+
+// parseWorkExperiences 解析并校验提交的工作经历，离职日期不能早于入职日期
+func parseWorkExperiences(lang string, values map[string][]string) ([]models.WorkExperience, models.ValidationErrors) {
+	rows := demoExperiences.Parse(values)
+	if errs := demoExperiences.Validate(lang, rows); len(errs) > 0 {
+		return nil, errs
+	}
+	list := make([]models.WorkExperience, len(rows))
+	var errs models.ValidationErrors
+	for i, row := range rows {
+		// 日期均为 YYYY-MM-DD，可以按字符串比较
+		if row["end_date"] != "" && row["end_date"] < row["start_date"] {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("experiences[%d][end_date]", i),
+				Label:   fmt.Sprintf("工作经历 %d 离职日期", i+1),
+				Message: "不能早于入职日期",
+			})
+		}
+		list[i] = models.WorkExperience{
+			Company:     row["company"],
+			Title:       row["title"],
+			StartDate:   row["start_date"],
+			EndDate:     row["end_date"],
+			Description: row["description"],
+		}
+	}
+	return list, errs
+}
+
+// SubmitForm 处理表单页面的提交：校验后保存到 demo_form_submissions 表
+//
+// 参数:
+//   - ctx: 请求上下文对象，表单以 multipart/form-data 提交
+//
+// 返回值:
+//   - 以 AJAX 提交（请求头 Accept 为 application/json）时返回 JSON:
+//   - 200: {"code": 200, "msg": "提交成功", "data": {"id": 提交记录的编号, "certificate": [证书的访问地址...]}}
+//   - 400: {"code": 400, "msg": 校验错误}，每个不通过的字段一条；证书过大或过多时同样返回 400
+//   - 其他方式提交时跳转回表单页面，结果以一次性提示（见 setFlash）显示在表单上方
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url("/form/update"), pages.SubmitForm)
+//
+// 注意事项:
+//   - 提交记录、"工作经历"（见 demoExperiences）在同一个事务中保存，成功后删除当前管理员的草稿
+//   - 省、市、区需为 regions 表中逐级对应的区划（见 tables.ValidateRegions），可以都不选择
+//   - 密码字段不保存
+func SubmitForm(ctx *context.Context) {
+	ajax := strings.Contains(ctx.Headers("Accept"), "application/json")
+	fail := func(status int, msg string) {
+		if ajax {
+			formError(ctx, status, msg)
+			return
+		}
+		setFlash(ctx, flashError, msg)
+		ctx.Redirect(config.Url(demoFormPath))
+	}
+
+	if err := ctx.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		fail(http.StatusBadRequest, "读取表单失败: "+err.Error())
+		return
+	}
+	if errs := demoFormRules().Validate(ctx.Lang(), ctx.Request.PostForm); len(errs) > 0 {
+		fail(http.StatusBadRequest, errs.Error())
+		return
+	}
+	errs, err := tables.ValidateRegions(ctx.Request.Context(), demoRegionFields, ctx.Request.PostForm)
+	if err != nil {
+		fail(http.StatusInternalServerError, "读取行政区划失败: "+err.Error())
+		return
+	}
+	if len(errs) > 0 {
+		fail(http.StatusBadRequest, errs.Error())
+		return
+	}
+	// 表单中有"工作经历"分组时用提交的各段替换当前管理员的工作经历，删除了全部段时清空
+	experiences := demoExperiences.Submitted(ctx.Request.PostForm)
+	var list []models.WorkExperience
+	if experiences {
+		var errs models.ValidationErrors
+		if list, errs = parseWorkExperiences(ctx.Lang(), ctx.Request.PostForm); len(errs) > 0 {
+			fail(http.StatusBadRequest, errs.Error())
+			return
+		}
+	}
+	certificates, err := saveCertificates(ctx)
+	if errors.Is(err, tables.ErrUploadTooLarge) || errors.Is(err, errTooManyCertificates) {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		fail(http.StatusInternalServerError, "保存证书失败: "+err.Error())
+		return
+	}
+
+	userID := auth.Auth(ctx).Id
+	var submission models.DemoFormSubmission
+	err = models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, _ *gorm.DB) error {
+		if experiences {
+			if err := models.ReplaceWorkExperiences(txCtx, userID, list); err != nil {
+				return fmt.Errorf("保存工作经历失败: %v", err)
+			}
+		}
+		var err error
+		submission, err = models.CreateDemoFormSubmission(txCtx, userID, demoFormData(ctx.Request.PostForm), certificates)
+		return err
+	})
+	if err != nil {
+		fail(http.StatusInternalServerError, "保存失败: "+err.Error())
+		return
+	}
+	if err := models.DeleteFormDraft(ctx.Request.Context(), userID, demoFormKey); err != nil {
+		log.Printf("删除表单 %s 的草稿失败: %s\n", demoFormKey, err)
+	}
+
+	if !ajax {
+		setFlash(ctx, flashSuccess, fmt.Sprintf("提交成功，提交记录编号为 %d", submission.ID))
+		ctx.Redirect(config.Url(demoFormPath))
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "提交成功",
+		"data": map[string]interface{}{
+			"id":          submission.ID,
+			"certificate": certificates,
+		},
+	})
+}
+
+// demoFormPlainSubmitJS "提交并跳转"按钮：绕过表单的 AJAX 提交，以 multipart/form-data 直接提交，上传的证书一并提交
+// form.submit() 不触发 submit 事件，浏览器中的校验提示同样被跳过，由 SubmitForm 校验
+const demoFormPlainSubmitJS = template.JS(`
+$('.demo-form-plain-submit').on('click', function () {
+    let form = document.getElementById('` + demoFormID + `');
+    form.enctype = 'multipart/form-data';
+    HTMLFormElement.prototype.submit.call(form);
+});
+`)
+
+// demoFormDataFields 保存到提交记录中的字段，不包括密码、证书和工作经历
+// "设置"表格的每一列以列名提交，多值字段以 field[] 或 field[values][] 提交
+var demoFormDataFields = []string{
+	"name", "age", "homepage", "email", "mobile", "birthday", "time", "time_range", "date_range", "ip",
+	"currency", "rate", "reward", "content", "code",
+	"website", "employed", "company", "snacks", "fruit", "gender", "cat", "drink", "province", "city", "district", "experience",
+	"employee", "key", "value",
+}
+
+// demoFormData 取出提交记录中保存的字段值，只有一个值的字段保存为字符串，多值字段保存为数组
+// 没有提交的字段（如未勾选的复选框）不保存
+func demoFormData(values map[string][]string) map[string]interface{} {
+	data := make(map[string]interface{}, len(demoFormDataFields))
+	for _, field := range demoFormDataFields {
+		for _, name := range []string{field, field + "[]", field + "[values][]"} {
+			v, ok := values[name]
+			if !ok {
+				continue
+			}
+			if name == field && len(v) == 1 {
+				data[field] = v[0]
+			} else {
+				data[field] = v
+			}
+			break
+		}
+	}
+	return data
+}
+
+// demoCertificateMax 表单中最多上传的证书数量，与字段的 maxFileCount 相同
+const demoCertificateMax = 10
+
+// errTooManyCertificates 上传的证书超过 demoCertificateMax 个
+var errTooManyCertificates = fmt.Errorf("证书最多上传 %d 个", demoCertificateMax)
+
+// saveCertificates 把上传的证书保存到 config.yml 的 upload 配置项指定的存储中，返回各文件的访问地址
+// 没有上传证书时返回空列表；文件过大时返回 tables.ErrUploadTooLarge，文件过多时返回 errTooManyCertificates
+func saveCertificates(ctx *context.Context) ([]string, error) {
+	urls := []string{}
+	if ctx.Request.MultipartForm == nil {
+		return urls, nil
+	}
+	files := ctx.Request.MultipartForm.File["certificate"]
+	if len(files) > demoCertificateMax {
+		return nil, errTooManyCertificates
+	}
+	for _, fh := range files {
+		url, err := tables.SaveUpload(ctx.Request.Context(), "certificates", fh)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+// formError 返回表单提交失败的 JSON
+func formError(ctx *context.Context, status int, msg string) {
+	ctx.JSON(status, map[string]interface{}{
+		"code": status,
+		"msg":  msg,
+	})
+}
@@ -0,0 +1,246 @@
+// pages 包 - 页面处理器
+// 本文件实现较长表单的草稿：填写过程中定时把表单内容保存为草稿，再次打开表单时显示"恢复草稿"的提示
+
+package pages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gorm.io/gorm"
+)
+
+// 以下地址相对于后台的路由前缀，注册路由和生成脚本时经过 config.Url
+const (
+	// FormDraftURL 保存当前管理员在表单上的草稿，参数 form 为表单的标识，data 为表单字段值的 JSON
+	FormDraftURL = "/form/draft"
+
+	// FormDraftDeleteURL 删除当前管理员在表单上的草稿，参数 form 为表单的标识
+	FormDraftDeleteURL = "/form/draft/delete"
+)
+
+// formDraftInterval 自动保存草稿的间隔，单位为毫秒；表单内容没有变化时不保存
+const formDraftInterval = "30000"
+
+// formDraftMaxSize 草稿 JSON 的最大字节数
+const formDraftMaxSize = 64 << 10
+
+// formDraftKeyPattern 表单标识的格式
+var formDraftKeyPattern = regexp.MustCompile(`^[a-z0-9_-]{1,100}$`)
+
+// formDraft 返回表单的草稿提示和自动保存的 JS，提示加在表单之前，JS 加在表单之后
+//
+// 参数:
+//   - ctx: 请求上下文对象，草稿属于当前管理员
+//   - key: 表单的标识，只能包含小写字母、数字、_ 和 -
+//   - formID: 表单元素的 id
+//   - exclude: 不保存的字段
+//
+// 功能说明:
+//  1. 有草稿时在表单上方显示提示，"恢复草稿"把草稿填回表单，"丢弃"删除草稿
+//  2. 表单内容有变化时每隔 formDraftInterval 保存一次草稿，离开页面时再保存一次
+//  3. 表单提交成功后在表单上触发 form-draft:clear 事件，不再保存当前内容并隐藏提示；服务端负责删除草稿
+//
+// 注意事项:
+//   - 密码和文件不保存；富文本和代码编辑器的内容保存在隐藏的输入框中，恢复后编辑器中不显示，不保存这些字段
+func formDraft(ctx *context.Context, key, formID string, exclude ...string) (banner, script template.HTML) {
+	var draft map[string][]string
+	d, err := models.FindFormDraft(ctx.Request.Context(), auth.Auth(ctx).Id, key)
+	switch {
+	case err == nil && json.Unmarshal([]byte(d.Data), &draft) == nil:
+		banner = template.HTML(fmt.Sprintf(`<div class="callout callout-info" id="form-draft-%s">
+  <p>有一份 %s 自动保存的草稿。
+    <button type="button" class="btn btn-xs btn-primary form-draft-restore">恢复草稿</button>
+    <button type="button" class="btn btn-xs btn-default form-draft-discard">丢弃</button>
+  </p>
+</div>`, key, d.UpdatedAt.Format("2006-01-02 15:04")))
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Printf("读取表单 %s 的草稿失败: %s\n", key, err)
+	}
+
+	if exclude == nil {
+		exclude = []string{}
+	}
+
+	// 重新序列化草稿而不是直接输出保存的内容，json.Marshal 会转义 < 和 >，内容不会提前结束 script
+	data, _ := json.Marshal(draft)
+	options, _ := json.Marshal(map[string]interface{}{
+		"key":     key,
+		"form":    formID,
+		"exclude": exclude,
+		"save":    config.Url(FormDraftURL),
+		"delete":  config.Url(FormDraftDeleteURL),
+	})
+	script = template.HTML(`<script>` + formDraftJS + `(` + string(options) + `, ` + string(data) + `);</script>`)
+	return banner, script
+}
+
+// formDraftJS 草稿的恢复和自动保存，参数为 formDraft 中的选项和草稿（没有草稿时为 null）
+// 开关（bootstrapSwitch）按其复选框是否提交恢复状态，由开关自己更新隐藏的输入框
+const formDraftJS = `
+(function (options, draft) {
+    let form = $('#' + options.form), banner = $('#form-draft-' + options.key);
+
+    function values() {
+        let data = {};
+        form.find(':input[name]').each(function () {
+            let el = $(this), name = el.attr('name');
+            if (el.is(':file, :password, :button') || options.exclude.indexOf(name) !== -1 ||
+                (name.indexOf('__') === 0 && name.indexOf('__checkbox__') !== 0) ||
+                (el.is(':checkbox, :radio') && !el.is(':checked'))) {
+                return;
+            }
+            let v = el.val();
+            data[name] = (data[name] || []).concat(Array.isArray(v) ? v : [v === null ? '' : v]);
+        });
+        return data;
+    }
+
+    function restore(data) {
+        $.each(data, function (name, vals) {
+            if (name.indexOf('__') === 0) {
+                return;
+            }
+            let el = form.find('[name="' + name + '"]');
+            if (el.is(':checkbox, :radio')) {
+                el.each(function () {
+                    $(this).prop('checked', vals.indexOf($(this).val()) !== -1);
+                });
+                if ($.fn.iCheck) {
+                    el.iCheck('update');
+                }
+            } else if (el.is('select')) {
+                el.val(el.prop('multiple') ? vals : vals[0]).trigger('change');
+            } else {
+                el.each(function (i) {
+                    if (i < vals.length) {
+                        $(this).val(vals[i]).trigger('change');
+                    }
+                });
+            }
+        });
+        form.find('.ga_checkbox').each(function () {
+            $(this).bootstrapSwitch('state', data[$(this).attr('name')] !== undefined);
+        });
+    }
+
+    let last = JSON.stringify(values());
+    function save(beacon) {
+        let data = JSON.stringify(values());
+        if (data === last) {
+            return;
+        }
+        if (beacon) {
+            let body = new FormData();
+            body.append('form', options.key);
+            body.append('data', data);
+            navigator.sendBeacon(options.save, body);
+            return;
+        }
+        $.post(options.save, {form: options.key, data: data}, function () {
+            last = data;
+        });
+    }
+
+    banner.find('.form-draft-restore').click(function () {
+        restore(draft);
+        banner.remove();
+    });
+    banner.find('.form-draft-discard').click(function () {
+        $.post(options.delete, {form: options.key}, function () {
+            banner.remove();
+        }).fail(function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '丢弃草稿失败', '', 'error');
+        });
+    });
+    form.on('form-draft:clear', function () {
+        last = JSON.stringify(values());
+        banner.remove();
+    });
+
+    // 通过 pjax 离开页面后表单已不在文档中，停止自动保存
+    let timer = setInterval(function () {
+        if (!$.contains(document, form[0])) {
+            clearInterval(timer);
+            return;
+        }
+        save(false);
+    }, ` + formDraftInterval + `);
+    $(document).one('pjax:send', function () {
+        save(false);
+    });
+    $(window).on('beforeunload', function () {
+        if ($.contains(document, form[0])) {
+            save(true);
+        }
+    });
+})`
+
+// SaveFormDraft 保存当前管理员在表单上的草稿
+//
+// 参数:
+//   - ctx: 请求上下文对象，参数 form 为表单的标识，data 为表单字段值的 JSON（键为字段名，值为字段的所有值）
+//
+// 返回值（JSON）:
+//   - 200: {"code": 200, "msg": "已保存草稿"}
+//   - 400: 表单标识或草稿的格式不正确；413: 草稿超过 64 KB；500: 写入失败
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url(pages.FormDraftURL), pages.SaveFormDraft)
+func SaveFormDraft(ctx *context.Context) {
+	key := ctx.FormValue("form")
+	if !formDraftKeyPattern.MatchString(key) {
+		formError(ctx, http.StatusBadRequest, "表单标识无效")
+		return
+	}
+	raw := ctx.FormValue("data")
+	if len(raw) > formDraftMaxSize {
+		formError(ctx, http.StatusRequestEntityTooLarge, "草稿不能超过 64 KB")
+		return
+	}
+	var draft map[string][]string
+	if err := json.Unmarshal([]byte(raw), &draft); err != nil {
+		formError(ctx, http.StatusBadRequest, "草稿格式不正确")
+		return
+	}
+	data, _ := json.Marshal(draft)
+	if err := models.SaveFormDraft(ctx.Request.Context(), auth.Auth(ctx).Id, key, string(data)); err != nil {
+		formError(ctx, http.StatusInternalServerError, "保存草稿失败: "+err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "已保存草稿",
+	})
+}
+
+// DeleteFormDraft 删除当前管理员在表单上的草稿，没有草稿时同样返回成功
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url(pages.FormDraftDeleteURL), pages.DeleteFormDraft)
+func DeleteFormDraft(ctx *context.Context) {
+	key := ctx.FormValue("form")
+	if !formDraftKeyPattern.MatchString(key) {
+		formError(ctx, http.StatusBadRequest, "表单标识无效")
+		return
+	}
+	if err := models.DeleteFormDraft(ctx.Request.Context(), auth.Auth(ctx).Id, key); err != nil {
+		formError(ctx, http.StatusInternalServerError, "丢弃草稿失败: "+err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "已丢弃草稿",
+	})
+}
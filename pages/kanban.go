@@ -0,0 +1,168 @@
+// pages 包 - 页面处理器
+// 本文件实现任务看板：按状态分列显示 tasks 表中的任务，拖动卡片到其他列即修改任务的状态
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// kanbanColors 各状态的列标题栏的颜色
+var kanbanColors = map[string]string{
+	models.TaskTodo:  "default",
+	models.TaskDoing: "primary",
+	models.TaskDone:  "success",
+}
+
+// KanbanPage 返回任务看板页面
+//
+// 参数:
+//   - ctx: 请求上下文对象
+//
+// 返回值:
+//   - types.Panel: 每个状态一列，每个任务一张卡片，卡片显示标题、优先级和负责人
+//   - error: 没有查看任务的权限或读取任务失败时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url("/kanban"), pages.KanbanPage)
+//	eng.Data("POST", config.Url(tables.TaskMoveURL), tables.MoveTask)
+//
+// 注意事项:
+//   - 查看看板需要任务列表的权限，拖动卡片需要修改任务的权限，没有修改权限时卡片不能拖动
+//   - 同一列中按优先级从高到低排列，拖动只修改状态，不调整顺序
+func KanbanPage(ctx *context.Context) (types.Panel, error) {
+	user := auth.Auth(ctx)
+	if !user.CheckPermissionByUrlMethod(config.Url("/info/tasks"), http.MethodGet, url.Values{}) {
+		return types.Panel{}, errors.New("没有查看任务的权限")
+	}
+	editable := user.CheckPermissionByUrlMethod(config.Url("/edit/tasks"), http.MethodPost, url.Values{})
+
+	board, err := models.TaskBoard(ctx.Request.Context())
+	if err != nil {
+		return types.Panel{}, fmt.Errorf("读取任务失败: %v", err)
+	}
+
+	return types.Panel{
+		Content:     renderKanban(board, editable),
+		Title:       "任务看板",
+		Description: "拖动卡片到其他列即可修改任务的状态",
+	}, nil
+}
+
+// renderKanban 生成看板的 HTML，列的顺序与 models.TaskStatuses 相同
+func renderKanban(board map[string][]models.TaskCard, editable bool) template.HTML {
+	width := 12 / len(models.TaskStatuses)
+	content := template.HTML(fmt.Sprintf(`<div class="row kanban-board" data-move="%s">`, config.Url(tables.TaskMoveURL)))
+	for _, status := range models.TaskStatuses {
+		cards := board[status]
+		var body template.HTML
+		for _, c := range cards {
+			body += renderKanbanCard(c, editable)
+		}
+		content += template.HTML(fmt.Sprintf(`<div class="col-md-%d">
+  <div class="box box-solid box-%s">
+    <div class="box-header with-border">
+      <h3 class="box-title">%s</h3>
+      <span class="badge pull-right kanban-count">%d</span>
+    </div>
+    <div class="box-body kanban-column" data-status="%s">%s</div>
+  </div>
+</div>`, width, kanbanColors[status], template.HTMLEscapeString(tables.TaskStatusText(status)), len(cards), status, body))
+	}
+	content += `</div>`
+	return content + `<style>` + template.HTML(kanbanCSS) + `</style><script>` + template.HTML(kanbanJS) + `</script>`
+}
+
+// renderKanbanCard 生成一张任务卡片，标题链接到任务的编辑页面
+func renderKanbanCard(c models.TaskCard, editable bool) template.HTML {
+	assignee := "未分配"
+	if c.AssigneeName != "" {
+		assignee = c.AssigneeName
+	}
+	id := strconv.FormatUint(uint64(c.ID), 10)
+	return template.HTML(fmt.Sprintf(`<div class="kanban-card" draggable="%t" data-id="%s">
+  <a href="%s?__goadmin_edit_pk=%s">%s</a>
+  <div class="kanban-card-meta">%s <span class="text-muted"><i class="fa fa-user"></i> %s</span></div>
+</div>`, editable, id, config.Url("/info/tasks/edit"), id, template.HTMLEscapeString(c.Title),
+		tables.TaskPriorityLabel(strconv.Itoa(c.Priority)), template.HTMLEscapeString(assignee)))
+}
+
+// kanbanCSS 卡片、放置区域和拖动中的卡片的样式
+const kanbanCSS = template.CSS(`
+.kanban-column { min-height: 120px; background: #f4f4f4; }
+.kanban-column.kanban-over { background: #e8f0f8; }
+.kanban-card { background: #fff; border: 1px solid #ddd; border-radius: 3px; padding: 8px; margin-bottom: 8px; }
+.kanban-card[draggable="true"] { cursor: move; }
+.kanban-card.kanban-dragging { opacity: 0.4; }
+.kanban-card-meta { margin-top: 6px; font-size: 12px; }
+`)
+
+// kanbanJS 看板的拖放
+// 拖放使用 HTML5 原生拖放接口，卡片放到其他列后立即移动并通过 tables.MoveTask 保存；保存失败时提示原因并刷新页面，卡片回到原来的列
+const kanbanJS = template.JS(`
+(function () {
+    let dragging = null;
+
+    function refreshCounts() {
+        $('.kanban-column').each(function () {
+            $(this).closest('.box').find('.kanban-count').text($(this).children('.kanban-card').length);
+        });
+    }
+
+    $(document).off('.kanban');
+    $(document).on('dragstart.kanban', '.kanban-card[draggable="true"]', function (e) {
+        dragging = $(this);
+        dragging.addClass('kanban-dragging');
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', String(dragging.data('id')));
+    });
+    $(document).on('dragend.kanban', '.kanban-card', function () {
+        if (dragging) {
+            dragging.removeClass('kanban-dragging');
+        }
+        dragging = null;
+        $('.kanban-column').removeClass('kanban-over');
+    });
+    $(document).on('dragover.kanban', '.kanban-column', function (e) {
+        if (dragging && dragging.closest('.kanban-column')[0] !== this) {
+            e.preventDefault();
+            $(this).addClass('kanban-over');
+        }
+    });
+    $(document).on('dragleave.kanban', '.kanban-column', function () {
+        $(this).removeClass('kanban-over');
+    });
+    $(document).on('drop.kanban', '.kanban-column', function (e) {
+        $(this).removeClass('kanban-over');
+        if (!dragging || dragging.closest('.kanban-column')[0] === this) {
+            return;
+        }
+        e.preventDefault();
+        let card = dragging;
+        $(this).append(card);
+        refreshCounts();
+        $.ajax({
+            method: 'post',
+            url: $(this).closest('.kanban-board').data('move'),
+            data: {id: card.data('id'), status: $(this).data('status')},
+            error: function (data) {
+                swal(data.responseJSON ? data.responseJSON.msg : '保存任务状态失败', '', 'error');
+                $.pjax.reload('#pjax-container');
+            }
+        });
+    });
+})();
+`)
@@ -0,0 +1,263 @@
+// pages 包 - 页面处理器
+// 本文件实现仪表板的自定义布局：组件的拖拽排序、隐藏和宽度调整
+// 布局按管理员和仪表板保存在 dashboard_layouts 表中，渲染时与默认布局合并
+
+package pages
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+)
+
+// dashboardWidget 仪表板上的一个组件
+// 在组件生成结果的基础上附加注册名称，保存布局时使用该名称
+type dashboardWidget struct {
+	widgets.Widget
+
+	// Name 组件的注册名称
+	Name string
+}
+
+// layoutWidths 允许选择的组件宽度
+var layoutWidths = []int{3, 4, 6, 8, 12}
+
+// mergeLayout 将管理员保存的布局与默认组件列表合并
+//
+// 参数:
+//   - list: 默认组件列表，顺序为默认显示顺序
+//   - saved: 管理员保存的布局，可以为 nil
+//
+// 返回值:
+//   - []models.LayoutWidget: 合并后的布局
+//
+// 功能说明:
+//  1. 先按保存的顺序排列保存过的组件，忽略已不存在的组件
+//  2. 保存布局之后新增的组件按默认宽度追加在末尾
+//  3. 宽度不在 1-12 范围内时使用组件的默认宽度
+func mergeLayout(list []dashboardWidget, saved []models.LayoutWidget) []models.LayoutWidget {
+	defaults := make(map[string]dashboardWidget, len(list))
+	for _, w := range list {
+		defaults[w.Name] = w
+	}
+
+	merged := make([]models.LayoutWidget, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, item := range saved {
+		w, ok := defaults[item.Name]
+		if !ok || seen[item.Name] {
+			continue
+		}
+		if item.Width < 1 || item.Width > 12 {
+			item.Width = w.Width
+		}
+		merged = append(merged, item)
+		seen[item.Name] = true
+	}
+	for _, w := range list {
+		if !seen[w.Name] {
+			merged = append(merged, models.LayoutWidget{Name: w.Name, Width: w.Width})
+		}
+	}
+	return merged
+}
+
+// renderDashboardLayout 按布局渲染仪表板组件
+//
+// 参数:
+//   - dashboard: 仪表板名称，保存布局时提交给服务端
+//   - list: 默认组件列表
+//   - saved: 管理员保存的布局，为 nil 时使用默认布局
+//
+// 返回值:
+//   - template.HTML: 包含全部组件、隐藏组件列表和布局编辑脚本的HTML
+//
+// 渲染结构:
+//   - 每个组件包裹在 .dashboard-widget 列中，带有拖拽手柄、宽度选择和隐藏按钮
+//   - 隐藏的组件仍然输出但不显示，可以在顶部的"已隐藏"列表中恢复
+//   - 任何修改都会立即通过 AJAX 保存到 /admin/dashboard/layout?dashboard=<名称>
+//   - 延迟加载组件的占位内容在页面显示后通过 AJAX 替换为组件内容
+func renderDashboardLayout(dashboard string, list []dashboardWidget, saved []models.LayoutWidget) template.HTML {
+	byName := make(map[string]dashboardWidget, len(list))
+	for _, w := range list {
+		byName[w.Name] = w
+	}
+
+	var body, hidden template.HTML
+	for _, item := range mergeLayout(list, saved) {
+		w := byName[item.Name]
+
+		options := ""
+		for _, width := range layoutWidths {
+			selected := ""
+			if width == item.Width {
+				selected = " selected"
+			}
+			options += fmt.Sprintf(`<option value="%d"%s>%d/12</option>`, width, selected, width)
+		}
+
+		style := ""
+		if item.Hidden {
+			style = ` style="display:none"`
+			hidden += template.HTML(fmt.Sprintf(
+				`<a href="javascript:void(0)" class="btn btn-xs btn-default dashboard-widget-show" data-widget="%s">%s</a> `,
+				w.Name, template.HTMLEscapeString(w.Title)))
+		}
+
+		body += template.HTML(fmt.Sprintf(`<div class="col-md-%d dashboard-widget" data-widget="%s" data-width="%d" data-hidden="%t"%s>
+  <div class="dashboard-widget-toolbar text-right" style="margin-bottom:4px">
+    <span class="dashboard-widget-handle pull-left" draggable="true" style="cursor:move" title="拖拽排序"><i class="fa fa-arrows"></i> %s</span>
+    <select class="dashboard-widget-width input-sm">%s</select>
+    <a href="javascript:void(0)" class="btn btn-xs btn-default dashboard-widget-hide" title="隐藏"><i class="fa fa-eye-slash"></i></a>
+  </div>
+  %s
+</div>`, item.Width, w.Name, item.Width, item.Hidden, style, template.HTMLEscapeString(w.Title), options, w.Content))
+	}
+
+	if hidden != "" {
+		hidden = `<div class="dashboard-hidden-widgets" style="margin-bottom:10px">已隐藏: ` + hidden + `</div>`
+	}
+
+	return hidden + template.HTML(fmt.Sprintf(`<div class="row dashboard-widgets" data-dashboard="%s" data-save="%s">`,
+		dashboard, config.Url(dashboardLayoutPath))) +
+		body + `</div>` + dashboardLayoutJS
+}
+
+// dashboardLayoutPath 保存布局的接口路径，相对于后台的路由前缀，需要与 app.RegisterRoutes 中注册的路由一致
+const dashboardLayoutPath = "/dashboard/layout"
+
+// dashboardLayoutJS 布局编辑脚本，保存布局的地址来自容器的 data-save 属性
+// 拖拽使用 HTML5 原生拖放接口，保存时按 DOM 顺序收集所有组件的布局
+const dashboardLayoutJS = template.HTML(`<script>
+(function () {
+    var container = $('.dashboard-widgets');
+    var dragging = null;
+
+    function save(reload) {
+        var layout = [];
+        container.children('.dashboard-widget').each(function () {
+            layout.push({
+                name: $(this).data('widget'),
+                width: parseInt($(this).attr('data-width'), 10),
+                hidden: $(this).attr('data-hidden') === 'true'
+            });
+        });
+        $.ajax({
+            url: container.data('save') + '?dashboard=' + encodeURIComponent(container.data('dashboard')),
+            type: 'post',
+            contentType: 'application/json',
+            data: JSON.stringify(layout),
+            success: function () {
+                if (reload) {
+                    location.reload();
+                }
+            }
+        });
+    }
+
+    container.on('dragstart', '.dashboard-widget-handle', function (e) {
+        dragging = $(this).closest('.dashboard-widget')[0];
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', $(dragging).data('widget'));
+    });
+    container.on('dragover', '.dashboard-widget', function (e) {
+        e.preventDefault();
+    });
+    container.on('drop', '.dashboard-widget', function (e) {
+        e.preventDefault();
+        if (dragging && dragging !== this) {
+            var rect = this.getBoundingClientRect();
+            if (e.originalEvent.clientX < rect.left + rect.width / 2) {
+                $(this).before(dragging);
+            } else {
+                $(this).after(dragging);
+            }
+            save(false);
+        }
+        dragging = null;
+    });
+    container.on('change', '.dashboard-widget-width', function () {
+        $(this).closest('.dashboard-widget').attr('data-width', $(this).val());
+        save(true);
+    });
+    container.on('click', '.dashboard-widget-hide', function () {
+        $(this).closest('.dashboard-widget').attr('data-hidden', 'true');
+        save(true);
+    });
+    $('.dashboard-widget-show').on('click', function () {
+        container.children('[data-widget="' + $(this).data('widget') + '"]').attr('data-hidden', 'false');
+        save(true);
+    });
+
+    // 加载延迟加载组件的内容，隐藏的组件不加载（恢复显示时页面会刷新）
+    container.children('.dashboard-widget[data-hidden="false"]').find('.dashboard-deferred').each(function () {
+        var placeholder = $(this);
+        $.get(placeholder.data('src')).done(function (html) {
+            placeholder.replaceWith(html);
+        }).fail(function () {
+            placeholder.find('.box-body').html('<span class="text-red">加载失败，请刷新页面重试</span>');
+        });
+    });
+})();
+</script>`)
+
+// SaveDashboardLayout 保存当前管理员在某个仪表板上的布局
+// 该处理器接收布局编辑脚本提交的 JSON 数组，并写入 dashboard_layouts 表
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 dashboard 为仪表板名称，请求体为 []models.LayoutWidget 的 JSON
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url("/dashboard/layout"), pages.SaveDashboardLayout)
+//
+// 注意事项:
+//   - 需要注册在认证中间件之后，以便获取当前登录的管理员
+//   - 宽度会被限制在 1-12 之间，组件名称在渲染时与默认组件合并校验
+func SaveDashboardLayout(ctx *context.Context) {
+	dashboard := ctx.Query("dashboard")
+	if _, ok := findDashboard(dashboard); !ok {
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  "未定义的仪表板",
+		})
+		return
+	}
+
+	var items []models.LayoutWidget
+	if err := ctx.BindJSON(&items); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  "布局格式错误",
+		})
+		return
+	}
+
+	for i := range items {
+		if items[i].Width < 1 {
+			items[i].Width = 1
+		}
+		if items[i].Width > 12 {
+			items[i].Width = 12
+		}
+	}
+
+	if err := Repos.Layouts.Save(ctx.Request.Context(), auth.Auth(ctx).Id, dashboard, items); err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code": http.StatusInternalServerError,
+			"msg":  "保存布局失败",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+	})
+}
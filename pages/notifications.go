@@ -0,0 +1,314 @@
+// pages 包 - 页面处理器
+// 本文件实现顶部导航栏的通知铃铛（铃铛按钮本身，以及它定时读取未读通知数的接口），
+// 和当前管理员的通知中心：分页查看自己的通知，逐条或全部标为已读、删除
+
+package pages
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// 以下地址相对于后台的路由前缀，注册路由和生成链接时经过 config.Url
+const (
+	// NotificationsURL 通知中心的地址，查询参数 filter=unread 时只显示未读通知，page 为页码
+	NotificationsURL = "/notifications"
+
+	// NotificationReadURL 将一条通知标为已读
+	NotificationReadURL = "/notifications/read"
+
+	// NotificationReadAllURL 将当前管理员的全部通知标为已读
+	NotificationReadAllURL = "/notifications/read_all"
+
+	// NotificationDeleteURL 删除一条通知
+	NotificationDeleteURL = "/notifications/delete"
+
+	// UnreadNotificationsURL 当前管理员的未读通知数，顶部导航栏的铃铛定时读取
+	UnreadNotificationsURL = "/api/notifications/unread"
+)
+
+// NotificationBellTitle 铃铛按钮的标题，只有一个显示未读数的角标，没有未读通知时隐藏
+const NotificationBellTitle = template.HTML(`<span class="label label-warning notification-count" style="display:none"></span>`)
+
+// notificationPollInterval 铃铛读取未读数的间隔，单位为毫秒
+const notificationPollInterval = "30000"
+
+// notificationBellJS 打开页面时和之后每隔 notificationPollInterval 读取一次未读数，更新铃铛上的角标
+// 通知中心或通知列表中标为已读、删除后触发 notifications:refresh 事件，角标立即更新
+func notificationBellJS() template.JS {
+	return template.JS(`
+(function () {
+    function refresh() {
+        $.get('` + config.Url(UnreadNotificationsURL) + `', function (data) {
+            let n = data && data.data ? data.data.unread : 0;
+            let badge = $('.notification-count');
+            badge.text(n > 99 ? '99+' : n).toggle(n > 0);
+        });
+    }
+    refresh();
+    setInterval(refresh, ` + notificationPollInterval + `);
+    $(document).on('notifications:refresh', refresh);
+})();
+`)
+}
+
+// NotificationBell 返回铃铛按钮的动作：点击跳转到通知中心，并在页面上定时刷新未读数
+//
+// 使用示例:
+//
+//	eng.AddNavButtons(pages.NotificationBellTitle, icon.Bell, pages.NotificationBell())
+func NotificationBell() types.Action {
+	jump := action.Jump(config.Url(NotificationsURL))
+	jump.JS = notificationBellJS()
+	return jump
+}
+
+// UnreadNotifications 返回当前登录管理员的未读通知数
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"unread": 3}}
+//
+// 注意事项:
+//   - 需要后台登录会话，每个管理员只能看到自己的未读数
+func UnreadNotifications(ctx *context.Context) {
+	n := models.UnreadNotificationCount(ctx.Request.Context(), auth.Auth(ctx).Id)
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+		"data": map[string]interface{}{"unread": n},
+	})
+}
+
+// NotificationsPage 返回当前登录管理员的通知中心
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 filter=unread 时只显示未读通知，page 为页码
+//
+// 返回值:
+//   - types.Panel: 通知列表，未读通知加粗显示，每条通知可以标为已读或删除
+//   - error: 读取通知失败时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.NotificationsURL), pages.NotificationsPage)
+//	eng.Data("POST", config.Url(pages.NotificationReadURL), pages.ReadNotification)
+//	eng.Data("POST", config.Url(pages.NotificationReadAllURL), pages.ReadAllNotifications)
+//	eng.Data("POST", config.Url(pages.NotificationDeleteURL), pages.DeleteNotification)
+//
+// 注意事项:
+//   - 所有管理员都可以访问，只能看到和处理自己的通知；给其他管理员发送通知使用站内通知表格（/admin/info/notifications）
+func NotificationsPage(ctx *context.Context) (types.Panel, error) {
+	adminID := auth.Auth(ctx).Id
+	unreadOnly := ctx.Query("filter") == "unread"
+	page, _ := strconv.Atoi(ctx.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	c := ctx.Request.Context()
+	list, total, err := models.ListNotifications(c, adminID, unreadOnly, page)
+	if err != nil {
+		return types.Panel{}, fmt.Errorf("读取通知失败: %v", err)
+	}
+	unread := models.UnreadNotificationCount(c, adminID)
+
+	return types.Panel{
+		Content:     renderNotifications(list, total, unread, unreadOnly, page),
+		Title:       "通知中心",
+		Description: template.HTML(fmt.Sprintf("%d 条未读", unread)),
+	}, nil
+}
+
+// renderNotifications 生成通知中心的 HTML：顶部为全部/未读切换和“全部标为已读”按钮，底部为翻页
+func renderNotifications(list []models.Notification, total, unread int64, unreadOnly bool, page int) template.HTML {
+	filter, allActive, unreadActive := "", " active", ""
+	if unreadOnly {
+		filter, allActive, unreadActive = "unread", "", " active"
+	}
+	center := config.Url(NotificationsURL)
+
+	var items template.HTML
+	for _, n := range list {
+		items += renderNotificationItem(n)
+	}
+	if len(list) == 0 {
+		items = `<li class="list-group-item text-center text-muted">暂无通知</li>`
+	}
+
+	pages := int((total + models.NotificationsPageSize - 1) / models.NotificationsPageSize)
+	var pager template.HTML
+	if pages > 1 {
+		prev, next := "", ""
+		if page <= 1 {
+			prev = " disabled"
+		}
+		if page >= pages {
+			next = " disabled"
+		}
+		pager = template.HTML(fmt.Sprintf(`<ul class="pager" style="margin:10px 0 0">
+  <li class="previous%s"><a href="%s?filter=%s&page=%d">上一页</a></li>
+  <li><span class="text-muted" style="border:none">第 %d / %d 页</span></li>
+  <li class="next%s"><a href="%s?filter=%s&page=%d">下一页</a></li>
+</ul>`, prev, center, filter, page-1, page, pages, next, center, filter, page+1))
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="box box-primary notification-center">
+  <div class="box-header with-border">
+    <div class="btn-group">
+      <a class="btn btn-default btn-sm%s" href="%s">全部</a>
+      <a class="btn btn-default btn-sm%s" href="%s?filter=unread">未读 <span class="badge">%d</span></a>
+    </div>
+    <button type="button" class="btn btn-primary btn-sm pull-right notification-read-all"%s><i class="fa fa-check"></i> 全部标为已读</button>
+  </div>
+  <div class="box-body">
+    <ul class="list-group" style="margin-bottom:0">%s</ul>
+    %s
+  </div>
+</div>`, allActive, center, unreadActive, center, unread, disabledIf(unread == 0), items, pager)) +
+		`<script>` + template.HTML(notificationsJS()) + `</script>`
+}
+
+// renderNotificationItem 生成一条通知，未读通知加粗并显示“标为已读”按钮
+func renderNotificationItem(n models.Notification) template.HTML {
+	title := template.HTMLEscapeString(n.Title)
+	read := ""
+	if !n.IsRead {
+		title = "<strong>" + title + "</strong>"
+		read = `<button type="button" class="btn btn-default btn-xs notification-read" title="标为已读"><i class="fa fa-check"></i></button> `
+	}
+	return template.HTML(fmt.Sprintf(`<li class="list-group-item" data-id="%d">
+  <div class="pull-right">%s<button type="button" class="btn btn-default btn-xs notification-delete" title="删除"><i class="fa fa-trash"></i></button></div>
+  %s %s
+  <div class="text-muted small">%s</div>
+</li>`, n.ID, read, tables.NotificationLevelLabel(n.Level), title, n.CreatedAt.Format("2006-01-02 15:04")))
+}
+
+// disabledIf 条件成立时返回按钮的 disabled 属性
+func disabledIf(cond bool) string {
+	if cond {
+		return " disabled"
+	}
+	return ""
+}
+
+// notificationsJS 通知中心的按钮：操作成功后通过 PJAX 刷新列表，并通知顶部铃铛立即更新未读数
+func notificationsJS() template.JS {
+	return template.JS(`
+(function () {
+    function post(url, data) {
+        $.ajax({
+            method: 'post',
+            url: url,
+            data: data,
+            success: function () {
+                $.pjax.reload('#pjax-container');
+                $(document).trigger('notifications:refresh');
+            },
+            error: function (data) {
+                swal(data.responseJSON ? data.responseJSON.msg : '操作失败', '', 'error');
+            }
+        });
+    }
+    $('.notification-center .notification-read').on('click', function () {
+        post('` + config.Url(NotificationReadURL) + `', {id: $(this).closest('li').data('id')});
+    });
+    $('.notification-center .notification-delete').on('click', function () {
+        let id = $(this).closest('li').data('id');
+        swal({
+            title: '确定删除这条通知吗？',
+            type: 'warning',
+            showCancelButton: true,
+            confirmButtonColor: '#DD6B55',
+            confirmButtonText: '删除',
+            cancelButtonText: '取消'
+        }, function () {
+            post('` + config.Url(NotificationDeleteURL) + `', {id: id});
+        });
+    });
+    $('.notification-center .notification-read-all').on('click', function () {
+        post('` + config.Url(NotificationReadAllURL) + `', {});
+    });
+})();
+`)
+}
+
+// ReadNotification 将当前管理员的一条通知标为已读
+//
+// 请求格式:
+//
+//	POST id=12
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+func ReadNotification(ctx *context.Context) {
+	notificationAction(ctx, models.MarkNotificationRead)
+}
+
+// DeleteNotification 删除当前管理员的一条通知
+//
+// 请求格式:
+//
+//	POST id=12
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+func DeleteNotification(ctx *context.Context) {
+	notificationAction(ctx, models.DeleteNotification)
+}
+
+// notificationAction 按提交的 id 对当前管理员的一条通知执行 fn
+// id 无效时返回 400，通知不存在或属于其他管理员时返回 404
+func notificationAction(ctx *context.Context, fn func(c stdctx.Context, adminID int64, id uint) error) {
+	id, err := strconv.ParseUint(ctx.FormValue("id"), 10, 64)
+	if err != nil {
+		notificationJSON(ctx, http.StatusBadRequest, "无效的通知编号")
+		return
+	}
+	err = fn(ctx.Request.Context(), auth.Auth(ctx).Id, uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		notificationJSON(ctx, http.StatusNotFound, "通知不存在")
+	case err != nil:
+		notificationJSON(ctx, http.StatusInternalServerError, "操作失败: "+err.Error())
+	default:
+		notificationJSON(ctx, http.StatusOK, "ok")
+	}
+}
+
+// ReadAllNotifications 将当前管理员的全部未读通知标为已读
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "已将 3 条通知标为已读"}
+func ReadAllNotifications(ctx *context.Context) {
+	n, err := models.MarkAllNotificationsRead(ctx.Request.Context(), auth.Auth(ctx).Id)
+	if err != nil {
+		notificationJSON(ctx, http.StatusInternalServerError, "标记已读失败: "+err.Error())
+		return
+	}
+	notificationJSON(ctx, http.StatusOK, fmt.Sprintf("已将 %d 条通知标为已读", n))
+}
+
+// notificationJSON 返回通知中心接口的响应
+func notificationJSON(ctx *context.Context, status int, msg string) {
+	ctx.JSON(status, map[string]interface{}{
+		"code": status,
+		"msg":  msg,
+	})
+}
@@ -0,0 +1,384 @@
+// pages 包 - 页面处理器
+// 本文件实现第三方账号登录：在登录页面增加 GitHub、Google、Gitee 的登录按钮，按 OAuth 2.0 授权码流程取得
+// 第三方账号的信息后关联或创建管理员（见 models.OAuthLogin），再与密码登录一样写入 GoAdmin 的登录会话
+
+package pages
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/middleware"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	gmodels "github.com/purpose168/GoAdmin/plugins/admin/models"
+	gtemplate "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/login"
+	"gopkg.in/yaml.v2"
+)
+
+// oauthStateCookie 保存授权请求 state 参数的 Cookie 名称，回调时核对，防止跨站请求伪造
+const oauthStateCookie = "goadmin_oauth_state"
+
+// OAuthConfig 第三方账号登录配置，对应 config.yml 的 oauth 配置项
+type OAuthConfig struct {
+	// RedirectBase 回调地址的前缀，如 https://admin.example.com，需要与在第三方平台登记的回调地址一致；
+	// 为空时按请求的协议和 Host 生成，经过反向代理时以 X-Forwarded-Proto 为准
+	RedirectBase string `yaml:"redirect_base"`
+
+	// AutoProvision 没有对应的管理员时是否自动创建
+	AutoProvision bool `yaml:"auto_provision"`
+
+	// DefaultRole 自动创建的管理员的角色，goadmin_roles 表的 slug
+	DefaultRole string `yaml:"default_role"`
+
+	// AllowedDomains 只为这些域名的邮箱自动创建管理员，为空表示不限制；开启自动创建时建议填写
+	AllowedDomains []string `yaml:"allowed_domains"`
+
+	// Providers 第三方平台的应用凭据，键为 github、google、gitee，未配置 client_id 的平台不显示登录按钮
+	Providers map[string]OAuthProviderConfig `yaml:"providers"`
+}
+
+// OAuthProviderConfig 在第三方平台注册的 OAuth 应用的凭据
+type OAuthProviderConfig struct {
+	// ClientID 应用的 Client ID
+	ClientID string `yaml:"client_id"`
+
+	// ClientSecret 应用的 Client Secret，为空时读取环境变量 OAUTH_<平台>_CLIENT_SECRET，如 OAUTH_GITHUB_CLIENT_SECRET
+	ClientSecret string `yaml:"client_secret"`
+}
+
+// DefaultOAuthConfig 配置文件中没有 oauth 配置项时使用的默认配置，不显示第三方账号登录按钮
+var DefaultOAuthConfig = OAuthConfig{
+	DefaultRole: "operator",
+}
+
+var (
+	// oauthCfg 第三方账号登录配置，由 LoadOAuthConfigFromYAML 读取
+	oauthCfg = DefaultOAuthConfig
+
+	// oauthEnabled 已配置的第三方平台，按登录按钮的显示顺序排列
+	oauthEnabled []*oauthProvider
+)
+
+// LoadOAuthConfigFromYAML 从 YAML 配置文件的 oauth 配置项读取第三方账号登录配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析失败、平台名称不支持、配置了 client_id 但没有 client_secret，
+//     或开启自动创建但没有指定 default_role 时返回错误
+//
+// 配置示例:
+//
+//	oauth:
+//	  redirect_base: https://admin.example.com
+//	  auto_provision: true
+//	  default_role: operator
+//	  allowed_domains: [example.com]
+//	  providers:
+//	    github:
+//	      client_id: xxx
+//	      client_secret: xxx
+func LoadOAuthConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		OAuth OAuthConfig `yaml:"oauth"`
+	}{OAuth: DefaultOAuthConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析第三方账号登录配置失败: %v", err)
+	}
+	c := cfg.OAuth
+	if c.AutoProvision && c.DefaultRole == "" {
+		return errors.New("第三方账号登录配置开启了 auto_provision，但没有指定 default_role")
+	}
+
+	for name := range c.Providers {
+		if _, ok := oauthProviderByName(name); !ok {
+			return fmt.Errorf("第三方账号登录配置中不支持的平台: %s，可选 github、google、gitee", name)
+		}
+	}
+	var enabled []*oauthProvider
+	for _, p := range oauthProviders {
+		pc := c.Providers[p.Name]
+		if pc.ClientID == "" {
+			continue
+		}
+		if pc.ClientSecret == "" {
+			pc.ClientSecret = os.Getenv("OAUTH_" + strings.ToUpper(p.Name) + "_CLIENT_SECRET")
+		}
+		if pc.ClientSecret == "" {
+			return fmt.Errorf("第三方账号登录配置中 %s 没有 client_secret", p.Name)
+		}
+		provider := *p
+		provider.clientID, provider.clientSecret = pc.ClientID, pc.ClientSecret
+		enabled = append(enabled, &provider)
+	}
+
+	oauthCfg, oauthEnabled = c, enabled
+	return nil
+}
+
+// OAuthProviderNames 返回已配置的第三方平台，如 github，用于注册登录和回调路由
+func OAuthProviderNames() []string {
+	names := make([]string, 0, len(oauthEnabled))
+	for _, p := range oauthEnabled {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// OAuthLoginURL 返回第三方平台的登录地址，跳转到第三方平台的授权页面，地址包含后台的路由前缀
+func OAuthLoginURL(name string) string {
+	return config.Url("/oauth/" + name + "/login")
+}
+
+// OAuthCallbackURL 返回第三方平台授权后的回调地址，在第三方平台登记应用时填写 redirect_base 加上该地址
+// 地址包含后台的路由前缀，修改前缀后需要在第三方平台更新登记的回调地址
+func OAuthCallbackURL(name string) string {
+	return config.Url("/oauth/" + name + "/callback")
+}
+
+// enabledOAuthProvider 返回已配置的第三方平台
+func enabledOAuthProvider(name string) *oauthProvider {
+	for _, p := range oauthEnabled {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// OAuthLogin 返回第三方平台登录地址的处理器：生成 state 参数保存到 Cookie，然后跳转到第三方平台的授权页面
+//
+// 参数:
+//   - name: 第三方平台，OAuthProviderNames 返回的名称之一
+//
+// 返回值:
+//   - context.Handler: 处理器，注册时不需要登录
+//
+// 使用示例:
+//
+//	for _, name := range pages.OAuthProviderNames() {
+//	    eng.Data("GET", pages.OAuthLoginURL(name), pages.OAuthLogin(name), true)
+//	    eng.Data("GET", pages.OAuthCallbackURL(name), pages.OAuthCallback(name, eng.DefaultConnection()), true)
+//	}
+func OAuthLogin(name string) context.Handler {
+	return func(ctx *context.Context) {
+		p := enabledOAuthProvider(name)
+		if p == nil {
+			oauthFail(ctx, http.StatusNotFound, "没有配置该第三方平台")
+			return
+		}
+
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			oauthFail(ctx, http.StatusInternalServerError, "生成授权请求失败，请稍后再试")
+			return
+		}
+		state := hex.EncodeToString(buf)
+		// 第三方平台跳转回来是跨站的顶级导航，SameSite=Lax 的 Cookie 会随之发送
+		ctx.SetCookie(&http.Cookie{
+			Name:     oauthStateCookie,
+			Value:    state,
+			Path:     OAuthCallbackURL(name),
+			MaxAge:   600,
+			HttpOnly: true,
+			Secure:   requestScheme(ctx) == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+		ctx.Redirect(p.authCodeURL(state, oauthRedirectURI(ctx, name)))
+	}
+}
+
+// OAuthCallback 返回第三方平台授权后回调地址的处理器
+//
+// 参数:
+//   - name: 第三方平台，OAuthProviderNames 返回的名称之一
+//   - conn: GoAdmin 数据库连接，登录会话保存在其中的 goadmin_session 表
+//
+// 返回值:
+//   - context.Handler: 处理器，注册时不需要登录
+//
+// 功能说明:
+//  1. 核对 state 参数，用授权码换取访问令牌，再读取第三方账号的用户 ID、已验证的邮箱和名称
+//  2. 按 models.OAuthLogin 关联或创建管理员，写入登录会话后跳转到仪表板
+//  3. 用户拒绝授权、没有对应的管理员或请求第三方平台失败时显示错误页面
+//
+// 注意事项:
+//   - 邮箱只使用第三方平台标记为已验证的邮箱，未验证的邮箱不会用于关联管理员
+func OAuthCallback(name string, conn db.Connection) context.Handler {
+	return func(ctx *context.Context) {
+		p := enabledOAuthProvider(name)
+		if p == nil {
+			oauthFail(ctx, http.StatusNotFound, "没有配置该第三方平台")
+			return
+		}
+
+		state := ctx.Cookie(oauthStateCookie)
+		ctx.SetCookie(&http.Cookie{Name: oauthStateCookie, Path: OAuthCallbackURL(name), MaxAge: -1})
+		if e := ctx.Query("error"); e != "" {
+			oauthFail(ctx, http.StatusBadRequest, "没有在"+p.Label+"完成授权: "+e)
+			return
+		}
+		if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(ctx.Query("state"))) != 1 {
+			oauthFail(ctx, http.StatusBadRequest, "授权请求已过期或无效，请返回登录页面重新登录")
+			return
+		}
+		code := ctx.Query("code")
+		if code == "" {
+			oauthFail(ctx, http.StatusBadRequest, "缺少授权码，请返回登录页面重新登录")
+			return
+		}
+
+		reqCtx := ctx.Request.Context()
+		token, err := p.exchange(reqCtx, code, oauthRedirectURI(ctx, name))
+		if err != nil {
+			log.Printf("%s 登录换取访问令牌失败: %v\n", p.Label, err)
+			oauthFail(ctx, http.StatusBadGateway, "请求"+p.Label+"失败，请稍后再试")
+			return
+		}
+		identity, err := p.identity(reqCtx, p, token)
+		if err != nil {
+			log.Printf("%s 登录读取用户信息失败: %v\n", p.Label, err)
+			oauthFail(ctx, http.StatusBadGateway, "读取"+p.Label+"账号信息失败，请稍后再试")
+			return
+		}
+
+		userID, created, err := models.OAuthLogin(reqCtx, identity, models.OAuthProvisioning{
+			Enabled: oauthCfg.AutoProvision,
+			Role:    oauthCfg.DefaultRole,
+			Domains: oauthCfg.AllowedDomains,
+		})
+		switch {
+		case errors.Is(err, models.ErrOAuthNoAdmin):
+			oauthFail(ctx, http.StatusForbidden, err.Error())
+			return
+		case errors.Is(err, models.ErrOAuthProvision):
+			// 角色配置错误或登录名冲突，错误中的原因是写给用户看的
+			log.Printf("%s 登录自动创建管理员失败: %v\n", p.Label, err)
+			oauthFail(ctx, http.StatusInternalServerError, err.Error())
+			return
+		case err != nil:
+			// 数据库等内部错误只写入日志，不展示给用户
+			log.Printf("%s 登录关联管理员失败: %v\n", p.Label, err)
+			oauthFail(ctx, http.StatusInternalServerError, "登录失败，请稍后再试")
+			return
+		}
+		if created {
+			log.Printf("%s 账号 %s 登录，已自动创建管理员 %d\n", p.Label, identity.Email, userID)
+		}
+
+		user := gmodels.User().SetConn(conn).Find(userID)
+		if user.IsEmpty() {
+			oauthFail(ctx, http.StatusForbidden, models.ErrOAuthNoAdmin.Error())
+			return
+		}
+		if err := auth.SetCookie(ctx, user, conn); err != nil {
+			log.Printf("%s 登录写入会话失败: %v\n", p.Label, err)
+			oauthFail(ctx, http.StatusInternalServerError, "登录失败，请稍后再试")
+			return
+		}
+		ctx.Redirect(config.Url("/"))
+	}
+}
+
+// oauthRedirectURI 返回在授权请求和换取令牌时使用的回调地址
+func oauthRedirectURI(ctx *context.Context, name string) string {
+	base := strings.TrimSuffix(oauthCfg.RedirectBase, "/")
+	if base == "" {
+		base = requestScheme(ctx) + "://" + ctx.Request.Host
+	}
+	return base + OAuthCallbackURL(name)
+}
+
+// requestScheme 返回请求的协议，经过反向代理时以 X-Forwarded-Proto 为准
+func requestScheme(ctx *context.Context) string {
+	if proto := ctx.Headers("X-Forwarded-Proto"); proto != "" {
+		return strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	if ctx.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// oauthFail 显示第三方账号登录失败的页面，页面中的链接返回仪表板，未登录时跳转到登录页面
+func oauthFail(ctx *context.Context, status int, msg string) {
+	ctx.HTML(status, middleware.ErrorPage(status, "第三方账号登录失败", msg))
+}
+
+// oauthButton 登录页面中的第三方账号登录按钮
+type oauthButton struct {
+	Label string
+	URL   string
+}
+
+// oauthLoginComponent 登录页面组件，在 GoAdmin 默认登录页面的基础上增加第三方账号登录按钮
+// 页面使用的样式和脚本仍由 GoAdmin 的登录组件提供
+type oauthLoginComponent struct {
+	*login.Login
+	tmpl *template.Template
+}
+
+// OAuthLoginComponent 读取登录页面模板，返回带有第三方账号登录按钮的登录页面组件
+//
+// 参数:
+//   - path: 登录页面模板文件，通常为 ./html/login.tmpl，模板名称为 login_oauth
+//
+// 返回值:
+//   - gtemplate.Component: 通过 template.AddLoginComp 替换 GoAdmin 默认的登录页面
+//   - error: 读取或解析模板失败时返回错误
+//
+// 使用示例:
+//
+//	comp, err := pages.OAuthLoginComponent("./html/login.tmpl")
+//	if err != nil {
+//	    return err
+//	}
+//	template.AddLoginComp(comp)
+//
+// 注意事项:
+//   - 模板在启动时解析一次，修改后需要重启；模板中通过 oauthButtons 函数读取已配置的第三方平台
+func OAuthLoginComponent(path string) (gtemplate.Component, error) {
+	buttons := func() []oauthButton {
+		res := make([]oauthButton, 0, len(oauthEnabled))
+		for _, p := range oauthEnabled {
+			res = append(res, oauthButton{Label: p.Label, URL: OAuthLoginURL(p.Name)})
+		}
+		return res
+	}
+	tmpl, err := template.New("login_oauth").
+		Funcs(login.DefaultFuncMap).
+		Funcs(template.FuncMap{"oauthButtons": buttons}).
+		ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析登录页面模板失败: %v", err)
+	}
+	if tmpl.Lookup("login_oauth") == nil {
+		return nil, fmt.Errorf("登录页面模板 %s 中没有定义 login_oauth", path)
+	}
+	return &oauthLoginComponent{Login: login.GetLoginComponent(), tmpl: tmpl}, nil
+}
+
+// GetTemplate 返回登录页面模板，GoAdmin 显示登录页面时调用
+func (c *oauthLoginComponent) GetTemplate() (*template.Template, string) {
+	return c.tmpl, "login_oauth"
+}
@@ -0,0 +1,272 @@
+// pages 包 - 页面处理器
+// 本文件定义第三方账号登录支持的平台：GitHub、Google 和 Gitee 的授权地址、令牌地址，以及读取用户 ID、已验证邮箱的接口
+
+package pages
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+// oauthClient 请求第三方平台使用的 HTTP 客户端
+var oauthClient = &http.Client{Timeout: 10 * time.Second}
+
+// oauthProvider 支持的第三方平台
+type oauthProvider struct {
+	// Name 平台名称，用于配置项和登录、回调地址，如 github
+	Name string
+
+	// Label 登录按钮和提示中显示的名称
+	Label string
+
+	// AuthURL 授权页面的地址
+	AuthURL string
+
+	// TokenURL 用授权码换取访问令牌的地址
+	TokenURL string
+
+	// Scopes 申请的权限，需要能读取用户的邮箱
+	Scopes []string
+
+	// identity 使用访问令牌读取第三方账号的信息
+	identity func(ctx stdctx.Context, p *oauthProvider, token string) (models.OAuthIdentity, error)
+
+	// clientID, clientSecret 应用的凭据，由 LoadOAuthConfigFromYAML 填写
+	clientID, clientSecret string
+}
+
+// oauthProviders 支持的第三方平台，按登录按钮的显示顺序排列
+var oauthProviders = []*oauthProvider{
+	{
+		Name:     "github",
+		Label:    "GitHub",
+		AuthURL:  "https://github.com/login/oauth/authorize",
+		TokenURL: "https://github.com/login/oauth/access_token",
+		Scopes:   []string{"read:user", "user:email"},
+		identity: githubIdentity,
+	},
+	{
+		Name:     "google",
+		Label:    "Google",
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		Scopes:   []string{"openid", "email", "profile"},
+		identity: googleIdentity,
+	},
+	{
+		Name:     "gitee",
+		Label:    "Gitee",
+		AuthURL:  "https://gitee.com/oauth/authorize",
+		TokenURL: "https://gitee.com/oauth/token",
+		Scopes:   []string{"user_info", "emails"},
+		identity: giteeIdentity,
+	},
+}
+
+// oauthProviderByName 按名称返回支持的第三方平台
+func oauthProviderByName(name string) (*oauthProvider, bool) {
+	for _, p := range oauthProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// authCodeURL 返回跳转到授权页面的地址
+func (p *oauthProvider) authCodeURL(state, redirectURI string) string {
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+	return p.AuthURL + "?" + q.Encode()
+}
+
+// exchange 用授权码换取访问令牌
+func (p *oauthProvider) exchange(ctx stdctx.Context, code, redirectURI string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub 默认以表单格式返回令牌，指定 Accept 后返回 JSON
+	req.Header.Set("Accept", "application/json")
+
+	var res struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := oauthDo(req, &res); err != nil {
+		return "", err
+	}
+	if res.Error != "" {
+		return "", fmt.Errorf("%s: %s", res.Error, res.ErrorDescription)
+	}
+	if res.AccessToken == "" {
+		return "", errors.New("响应中没有 access_token")
+	}
+	return res.AccessToken, nil
+}
+
+// oauthGet 以访问令牌请求第三方平台的接口，把 JSON 响应解析到 v
+// GitHub 和 Google 使用 Authorization 请求头，Gitee 使用 access_token 查询参数（见 giteeIdentity）
+func oauthGet(ctx stdctx.Context, rawURL, token string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	return oauthDo(req, v)
+}
+
+// oauthDo 发送请求并把 JSON 响应解析到 v，状态码不是 2xx 时返回包含响应开头部分的错误
+func oauthDo(req *http.Request, v interface{}) error {
+	// GitHub 的接口要求 User-Agent
+	req.Header.Set("User-Agent", "GoAdmin-example")
+	resp, err := oauthClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s 返回 %d: %s", req.Method, req.URL.Host+req.URL.Path, resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 的响应失败: %v", req.URL.Host+req.URL.Path, err)
+	}
+	return nil
+}
+
+// githubIdentity 读取 GitHub 账号：用户 ID、名称和头像来自 /user，已验证的主邮箱来自 /user/emails
+func githubIdentity(ctx stdctx.Context, p *oauthProvider, token string) (models.OAuthIdentity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := oauthGet(ctx, "https://api.github.com/user", token, &user); err != nil {
+		return models.OAuthIdentity{}, err
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := oauthGet(ctx, "https://api.github.com/user/emails", token, &emails); err != nil {
+		return models.OAuthIdentity{}, err
+	}
+
+	id := models.OAuthIdentity{
+		Provider: p.Name,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     firstNonEmpty(user.Name, user.Login),
+		Avatar:   user.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			id.Email = e.Email
+		}
+	}
+	return id, nil
+}
+
+// googleIdentity 读取 Google 账号：OpenID Connect 的 userinfo 接口同时返回用户 ID、邮箱和邮箱是否已验证
+func googleIdentity(ctx stdctx.Context, p *oauthProvider, token string) (models.OAuthIdentity, error) {
+	var user struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := oauthGet(ctx, "https://openidconnect.googleapis.com/v1/userinfo", token, &user); err != nil {
+		return models.OAuthIdentity{}, err
+	}
+	if user.Sub == "" {
+		return models.OAuthIdentity{}, errors.New("响应中没有 sub")
+	}
+
+	id := models.OAuthIdentity{Provider: p.Name, Subject: user.Sub, Name: user.Name, Avatar: user.Picture}
+	if user.EmailVerified {
+		id.Email = user.Email
+	}
+	return id, nil
+}
+
+// giteeIdentity 读取 Gitee 账号：用户 ID、名称和头像来自 /user，已确认的主邮箱来自 /emails
+func giteeIdentity(ctx stdctx.Context, p *oauthProvider, token string) (models.OAuthIdentity, error) {
+	q := "?access_token=" + url.QueryEscape(token)
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := oauthGet(ctx, "https://gitee.com/api/v5/user"+q, "", &user); err != nil {
+		return models.OAuthIdentity{}, err
+	}
+	var emails []struct {
+		Email string   `json:"email"`
+		State string   `json:"state"`
+		Scope []string `json:"scope"`
+	}
+	if err := oauthGet(ctx, "https://gitee.com/api/v5/emails"+q, "", &emails); err != nil {
+		return models.OAuthIdentity{}, err
+	}
+
+	id := models.OAuthIdentity{
+		Provider: p.Name,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     firstNonEmpty(user.Name, user.Login),
+		Avatar:   user.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.State != "confirmed" {
+			continue
+		}
+		for _, s := range e.Scope {
+			if s == "primary" {
+				id.Email = e.Email
+			}
+		}
+	}
+	return id, nil
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
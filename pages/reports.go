@@ -0,0 +1,489 @@
+// pages 包 - 页面处理器
+// 本文件实现参数化报表：报表在代码中实现 Report 接口并通过 RegisterReport 注册，
+// 管理员在报表页面选择报表、填写参数后查看结果表格和图表，并可以按同样的参数导出
+//
+// 使用示例:
+//
+//	func init() {
+//	    pages.RegisterReport("sales_daily", salesDailyReport{})
+//	}
+
+package pages
+
+import (
+	stdctx "context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// ReportsURL 报表页面的地址，与 ReportExportURL 一样相对于后台的路由前缀
+const ReportsURL = "/reports"
+
+// ReportExportURL 导出报表的地址，查询参数与报表页面相同，另加 format 指定文件格式
+const ReportExportURL = "/reports/export"
+
+// 报表参数的输入方式
+const (
+	// ReportParamText 文本框
+	ReportParamText = "text"
+
+	// ReportParamDate 日期选择，值的格式为 2006-01-02
+	ReportParamDate = "date"
+
+	// ReportParamNumber 数字输入框
+	ReportParamNumber = "number"
+
+	// ReportParamSelect 下拉选择，选项见 ReportParam.Options
+	ReportParamSelect = "select"
+)
+
+// 报表图表的类型
+const (
+	// ReportChartLine 折线图，适合按时间排列的数据
+	ReportChartLine = "line"
+
+	// ReportChartBar 柱状图，适合按类别比较的数据
+	ReportChartBar = "bar"
+)
+
+// ReportParam 报表的一个参数
+type ReportParam struct {
+	// Name 参数名，即页面地址中的查询参数名，不能是 report 或 format
+	Name string
+
+	// Label 表单中显示的名称
+	Label string
+
+	// Type 输入方式，见 ReportParamText 等常量，为空时使用文本框
+	Type string
+
+	// Default 默认值，页面地址中没有该参数时使用
+	Default string
+
+	// Options 下拉选择的选项，Type 为 ReportParamSelect 时使用
+	Options types.FieldOptions
+}
+
+// ReportColumn 报表结果的一列
+type ReportColumn struct {
+	// Field 查询结果中的列名
+	Field string
+
+	// Head 表头
+	Head string
+
+	// Numeric 是否为数值列，数值列右对齐，小数保留两位
+	Numeric bool
+}
+
+// ReportChart 报表的图表
+type ReportChart struct {
+	// Type 图表类型，见 ReportChartLine 等常量
+	Type string
+
+	// Label 作为横轴标签的列名
+	Label string
+
+	// Values 作为数据的列名，每列一个数据集，须为数值列
+	Values []string
+}
+
+// Report 参数化报表
+// 实现需要是无状态的，同一个实例会被并发调用
+type Report interface {
+	// Title 报表名称，显示在报表列表和页面标题中
+	Title() string
+
+	// Description 报表的说明，显示在参数表单上方
+	Description() string
+
+	// Params 报表的参数，按顺序显示在参数表单中
+	Params() []ReportParam
+
+	// Columns 结果表格的列，按顺序显示
+	Columns() []ReportColumn
+
+	// Chart 报表的图表，返回 nil 时只显示表格
+	Chart() *ReportChart
+
+	// Query 按参数查询报表的数据，通常直接返回 models.ReportRows 的结果：每行以列名为键，以及结果是否被截断
+	// values 包含 Params 中的全部参数，页面没有提交的参数为默认值；参数无效时返回的错误显示在页面上
+	Query(ctx stdctx.Context, values map[string]string) ([]map[string]interface{}, bool, error)
+}
+
+var (
+	// reportsMu 保护 reports 和 reportNames 的并发读写
+	reportsMu sync.RWMutex
+
+	// reports 报表名称到报表的映射
+	reports = make(map[string]Report)
+
+	// reportNames 按注册顺序排列的报表名称，即报表列表中的顺序
+	reportNames []string
+)
+
+// RegisterReport 注册一个报表
+//
+// 参数:
+//   - name: 报表名称，用于页面地址中的 report 参数，建议使用小写字母和下划线
+//   - r: 报表
+//
+// 注意事项:
+//   - 通常在 init 函数中调用
+//   - r 为 nil 或名称重复注册时会 panic，与 widgets.Register 的行为一致
+func RegisterReport(name string, r Report) {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+
+	if r == nil {
+		panic("pages: RegisterReport report is nil")
+	}
+	if _, dup := reports[name]; dup {
+		panic("pages: RegisterReport called twice for report " + name)
+	}
+	reports[name] = r
+	reportNames = append(reportNames, name)
+}
+
+// findReport 按名称查找报表，name 为空时返回第一个注册的报表
+func findReport(name string) (string, Report, bool) {
+	reportsMu.RLock()
+	defer reportsMu.RUnlock()
+
+	if name == "" && len(reportNames) > 0 {
+		name = reportNames[0]
+	}
+	r, ok := reports[name]
+	return name, r, ok
+}
+
+// reportParamValues 从请求中读取报表的参数，没有提交的参数使用默认值
+func reportParamValues(ctx *context.Context, r Report) map[string]string {
+	values := make(map[string]string)
+	for _, p := range r.Params() {
+		v, ok := ctx.Request.URL.Query()[p.Name]
+		if ok && len(v) > 0 {
+			values[p.Name] = strings.TrimSpace(v[0])
+		} else {
+			values[p.Name] = p.Default
+		}
+	}
+	return values
+}
+
+// ReportsPage 返回报表页面
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 report 为报表名称，其余为报表的参数
+//
+// 返回值:
+//   - types.Panel: 左侧为报表列表，右侧为参数表单、图表、结果表格和导出按钮
+//   - error: 报表不存在时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.ReportsURL), pages.ReportsPage)
+//	eng.Data("GET", config.Url(pages.ReportExportURL), pages.ExportReport)
+//
+// 注意事项:
+//   - 没有指定报表时显示第一个注册的报表，打开页面即按默认参数查询
+//   - 查询失败（包括参数无效）时在结果的位置显示错误，参数表单保留已填写的值
+func ReportsPage(ctx *context.Context) (types.Panel, error) {
+	name, r, ok := findReport(ctx.Query("report"))
+	if !ok {
+		if name == "" {
+			return types.Panel{
+				Content: `<p class="text-muted">还没有注册报表，见 pages.RegisterReport</p>`,
+				Title:   "报表",
+			}, nil
+		}
+		return types.Panel{}, fmt.Errorf("未定义的报表: %s", name)
+	}
+
+	values := reportParamValues(ctx, r)
+	var result template.HTML
+	rows, truncated, err := r.Query(ctx.Request.Context(), values)
+	if err != nil {
+		result = template.HTML(fmt.Sprintf(`<div class="alert alert-danger">%s</div>`, template.HTMLEscapeString(err.Error())))
+	} else {
+		result = renderReportResult(name, r, values, rows, truncated)
+	}
+
+	content := template.HTML(`<div class="row"><div class="col-md-2">`) + reportMenu(name) +
+		`</div><div class="col-md-10">` + reportForm(name, r, values) + result + `</div></div>`
+
+	return types.Panel{
+		Content:     content,
+		Title:       template.HTML(template.HTMLEscapeString(r.Title())),
+		Description: "报表",
+	}, nil
+}
+
+// reportMenu 报表列表，当前报表高亮显示
+func reportMenu(current string) template.HTML {
+	reportsMu.RLock()
+	defer reportsMu.RUnlock()
+
+	items := template.HTML(`<ul class="nav nav-pills nav-stacked">`)
+	for _, name := range reportNames {
+		active := ""
+		if name == current {
+			active = ` class="active"`
+		}
+		items += template.HTML(fmt.Sprintf(`<li%s><a href="%s?report=%s">%s</a></li>`,
+			active, config.Url(ReportsURL), url.QueryEscape(name), template.HTMLEscapeString(reports[name].Title())))
+	}
+	return items + `</ul>`
+}
+
+// reportForm 报表的参数表单，以 GET 提交到报表页面
+func reportForm(name string, r Report, values map[string]string) template.HTML {
+	var fields template.HTML
+	for _, p := range r.Params() {
+		value := template.HTMLEscapeString(values[p.Name])
+		var input string
+		switch p.Type {
+		case ReportParamSelect:
+			var options string
+			for _, o := range p.Options {
+				selected := ""
+				if o.Value == values[p.Name] {
+					selected = " selected"
+				}
+				options += fmt.Sprintf(`<option value="%s"%s>%s</option>`,
+					template.HTMLEscapeString(o.Value), selected, template.HTMLEscapeString(o.Text))
+			}
+			input = fmt.Sprintf(`<select class="form-control input-sm" name="%s">%s</select>`, p.Name, options)
+		case ReportParamDate, ReportParamNumber:
+			input = fmt.Sprintf(`<input type="%s" class="form-control input-sm" name="%s" value="%s">`, p.Type, p.Name, value)
+		default:
+			input = fmt.Sprintf(`<input type="text" class="form-control input-sm" name="%s" value="%s">`, p.Name, value)
+		}
+		fields += template.HTML(fmt.Sprintf(`<div class="form-group" style="margin-right:10px">
+  <label>%s</label> %s
+</div>`, template.HTMLEscapeString(p.Label), input))
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="box box-default">
+  <div class="box-body">
+    <p class="text-muted">%s</p>
+    <form method="get" action="%s" class="form-inline">
+      <input type="hidden" name="report" value="%s">
+      %s
+      <button type="submit" class="btn btn-sm btn-primary">查询</button>
+    </form>
+  </div>
+</div>`, template.HTMLEscapeString(r.Description()), config.Url(ReportsURL), template.HTMLEscapeString(name), fields))
+}
+
+// reportExportFormats 报表结果下方的导出按钮
+var reportExportFormats = []struct {
+	format string
+	text   string
+}{
+	{models.ExportCSV, "CSV"},
+	{models.ExportXLSX, "Excel"},
+	{models.ExportPDF, "PDF"},
+}
+
+// renderReportResult 生成图表、结果表格和导出按钮
+func renderReportResult(name string, r Report, values map[string]string, rows []map[string]interface{}, truncated bool) template.HTML {
+	if len(rows) == 0 {
+		return `<div class="box box-default"><div class="box-body text-center text-muted">没有符合条件的数据</div></div>`
+	}
+
+	query := url.Values{"report": {name}}
+	for k, v := range values {
+		query.Set(k, v)
+	}
+	var buttons template.HTML
+	for _, f := range reportExportFormats {
+		query.Set("format", f.format)
+		buttons += template.HTML(fmt.Sprintf(`<a class="btn btn-sm btn-default" href="%s?%s" target="_blank"><i class="fa fa-download"></i> %s</a> `,
+			config.Url(ReportExportURL), template.HTMLEscapeString(query.Encode()), f.text))
+	}
+
+	columns := r.Columns()
+	head := template.HTML("<tr>")
+	for _, c := range columns {
+		head += template.HTML(fmt.Sprintf(`<th%s>%s</th>`, reportAlign(c), template.HTMLEscapeString(c.Head)))
+	}
+	head += "</tr>"
+	var body template.HTML
+	for _, row := range rows {
+		body += "<tr>"
+		for _, c := range columns {
+			body += template.HTML(fmt.Sprintf(`<td%s>%s</td>`, reportAlign(c), template.HTMLEscapeString(reportCellText(row[c.Field], c.Numeric))))
+		}
+		body += "</tr>"
+	}
+
+	footer := template.HTML(fmt.Sprintf("共 %d 行", len(rows)))
+	if truncated {
+		footer = template.HTML(fmt.Sprintf("结果超过 %d 行，只显示前 %d 行", models.ReportMaxRows, models.ReportMaxRows))
+	}
+
+	return reportChart(r, rows) + template.HTML(fmt.Sprintf(`<div class="box box-default">
+  <div class="box-header with-border">
+    <h3 class="box-title">查询结果</h3>
+    <div class="box-tools pull-right">%s</div>
+  </div>
+  <div class="box-body table-responsive no-padding">
+    <table class="table table-hover table-bordered"><thead>%s</thead><tbody>%s</tbody></table>
+  </div>
+  <div class="box-footer text-muted">%s</div>
+</div>`, buttons, head, body, footer))
+}
+
+// reportChartColors 图表中各数据集依次使用的颜色
+var reportChartColors = []string{
+	"rgba(60,141,188,1)",
+	"rgba(0,166,90,1)",
+	"rgba(243,156,18,1)",
+	"rgba(221,75,57,1)",
+}
+
+// reportChart 按报表的图表定义生成图表，报表没有图表时返回空
+func reportChart(r Report, rows []map[string]interface{}) template.HTML {
+	c := r.Chart()
+	if c == nil || len(c.Values) == 0 {
+		return ""
+	}
+
+	heads := make(map[string]string)
+	for _, col := range r.Columns() {
+		heads[col.Field] = col.Head
+	}
+	labels := make([]string, len(rows))
+	for i, row := range rows {
+		labels[i] = reportCellText(row[c.Label], false)
+	}
+	series := make([][]float64, len(c.Values))
+	for i, field := range c.Values {
+		series[i] = make([]float64, len(rows))
+		for j, row := range rows {
+			series[i][j], _ = strconv.ParseFloat(fmt.Sprint(row[field]), 64)
+		}
+	}
+
+	var chart template.HTML
+	if c.Type == ReportChartBar {
+		bar := chartjs.Bar().SetID("reportchart").SetHeight(240).SetLabels(labels)
+		for i, field := range c.Values {
+			color := chartjs.Color(reportChartColors[i%len(reportChartColors)])
+			bar = bar.AddDataSet(heads[field]).DSData(series[i]).DSBackgroundColor(color).DSBorderColor(color)
+		}
+		chart = bar.GetContent()
+	} else {
+		line := chartjs.Line().SetID("reportchart").SetHeight(240).SetLabels(labels)
+		for i, field := range c.Values {
+			line = line.AddDataSet(heads[field]).DSData(series[i]).DSFill(false).
+				DSBorderColor(chartjs.Color(reportChartColors[i%len(reportChartColors)])).DSLineTension(0.1)
+		}
+		chart = line.GetContent()
+	}
+
+	return tmpl.Default().Box().WithHeadBorder().SetHeader("图表").SetBody(chart).GetContent()
+}
+
+// reportAlign 数值列右对齐
+func reportAlign(c ReportColumn) string {
+	if c.Numeric {
+		return ` class="text-right"`
+	}
+	return ""
+}
+
+// reportCellText 单元格的文字，数值列的小数保留两位，整数不显示小数
+func reportCellText(v interface{}, numeric bool) string {
+	if v == nil {
+		return ""
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	s := fmt.Sprint(v)
+	if !numeric {
+		return s
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	if f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// ExportReport 按报表页面的参数导出报表的结果
+//
+// 路由: GET /admin/reports/export?report={报表名称}&format={csv|xlsx|pdf}&{报表的参数}
+//
+// 注意事项:
+//   - 文件的内容与页面上的结果表格相同，最多 models.ReportMaxRows 行
+//   - PDF 的页眉中列出本次使用的参数
+func ExportReport(ctx *context.Context) {
+	name, r, ok := findReport(ctx.Query("report"))
+	if !ok || ctx.Query("report") == "" {
+		ctx.HTML(http.StatusNotFound, "报表不存在")
+		return
+	}
+	format := ctx.Query("format")
+	contentType := tables.ExportContentType(format)
+	if contentType == "" {
+		ctx.HTML(http.StatusBadRequest, "不支持的导出格式")
+		return
+	}
+
+	values := reportParamValues(ctx, r)
+	rows, _, err := r.Query(ctx.Request.Context(), values)
+	if err != nil {
+		ctx.HTML(http.StatusBadRequest, template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	data := table.PanelInfo{Title: r.Title()}
+	for _, c := range r.Columns() {
+		data.Thead = append(data.Thead, types.TheadItem{Head: c.Head, Field: c.Field})
+	}
+	for _, row := range rows {
+		item := make(map[string]types.InfoItem, len(data.Thead))
+		for _, c := range r.Columns() {
+			text := reportCellText(row[c.Field], c.Numeric)
+			item[c.Field] = types.InfoItem{Content: template.HTML(template.HTMLEscapeString(text)), Value: text}
+		}
+		data.InfoList = append(data.InfoList, item)
+	}
+
+	var summary []string
+	for _, p := range r.Params() {
+		summary = append(summary, p.Label+"："+values[p.Name])
+	}
+	buf, err := tables.EncodeExport(format, data, name, []string{strings.Join(summary, "；")}, true)
+	if err != nil {
+		ctx.HTML(http.StatusInternalServerError, "生成文件失败: "+template.HTMLEscapeString(err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s", r.Title(), time.Now().Format("20060102150405"), format)
+	ctx.DataWithHeaders(http.StatusOK, map[string]string{
+		"Content-Type":        contentType,
+		"Content-Disposition": "attachment; filename*=UTF-8''" + url.PathEscape(fileName),
+	}, buf)
+}
@@ -0,0 +1,140 @@
+// pages 包 - 页面处理器
+// 本文件定义报表页面内置的销售报表：按天汇总的订单日报和按商品汇总的商品销量
+
+package pages
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+func init() {
+	RegisterReport("sales_daily", salesDailyReport{})
+	RegisterReport("product_sales", productSalesReport{})
+}
+
+// reportDateParams 起止日期参数，默认为最近 defaultRangeDays 天
+func reportDateParams() []ReportParam {
+	today := time.Now()
+	return []ReportParam{
+		{Name: "from", Label: "从", Type: ReportParamDate, Default: today.AddDate(0, 0, -(defaultRangeDays - 1)).Format(widgets.DateLayout)},
+		{Name: "to", Label: "至", Type: ReportParamDate, Default: today.Format(widgets.DateLayout)},
+	}
+}
+
+// reportDateRange 解析起止日期参数，返回 [from 当天 0 点, to 次日 0 点)
+func reportDateRange(values map[string]string) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(widgets.DateLayout, values["from"], time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期无效: %s", values["from"])
+	}
+	to, err := time.ParseInLocation(widgets.DateLayout, values["to"], time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("结束日期无效: %s", values["to"])
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("开始日期不能晚于结束日期")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// salesDailyReport 订单日报：按天统计订单数和销售额，可以按订单状态筛选
+type salesDailyReport struct{}
+
+func (salesDailyReport) Title() string { return "订单日报" }
+
+func (salesDailyReport) Description() string {
+	return "按下单日期统计每天的订单数、销售额和客单价，没有订单的日期不显示"
+}
+
+func (salesDailyReport) Params() []ReportParam {
+	return append(reportDateParams(), ReportParam{
+		Name: "status", Label: "订单状态", Type: ReportParamSelect,
+		Options: types.FieldOptions{
+			{Value: "", Text: "全部"},
+			{Value: models.OrderPending, Text: models.OrderPending},
+			{Value: models.OrderShipped, Text: models.OrderShipped},
+			{Value: models.OrderDone, Text: models.OrderDone},
+		},
+	})
+}
+
+func (salesDailyReport) Columns() []ReportColumn {
+	return []ReportColumn{
+		{Field: "day", Head: "日期"},
+		{Field: "orders", Head: "订单数", Numeric: true},
+		{Field: "revenue", Head: "销售额", Numeric: true},
+		{Field: "average", Head: "客单价", Numeric: true},
+	}
+}
+
+func (salesDailyReport) Chart() *ReportChart {
+	return &ReportChart{Type: ReportChartLine, Label: "day", Values: []string{"revenue"}}
+}
+
+func (salesDailyReport) Query(ctx stdctx.Context, values map[string]string) ([]map[string]interface{}, bool, error) {
+	from, to, err := reportDateRange(values)
+	if err != nil {
+		return nil, false, err
+	}
+	day := models.ReportDateExpr("created_at")
+	query := `SELECT ` + day + ` AS day, COUNT(*) AS orders, SUM(amount) AS revenue, AVG(amount) AS average
+FROM orders WHERE created_at >= ? AND created_at < ?`
+	args := []interface{}{from, to}
+	if status := values["status"]; status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` GROUP BY ` + day + ` ORDER BY day`
+	return models.ReportRows(ctx, query, args...)
+}
+
+// productSalesReport 商品销量：按订单明细统计每个商品的销量和销售额，取销售额最高的若干个商品
+type productSalesReport struct{}
+
+func (productSalesReport) Title() string { return "商品销量" }
+
+func (productSalesReport) Description() string {
+	return "按订单明细统计每个商品的销量和销售额，按销售额从高到低排列"
+}
+
+func (productSalesReport) Params() []ReportParam {
+	return append(reportDateParams(), ReportParam{Name: "limit", Label: "商品数", Type: ReportParamNumber, Default: "10"})
+}
+
+func (productSalesReport) Columns() []ReportColumn {
+	return []ReportColumn{
+		{Field: "product", Head: "商品"},
+		{Field: "quantity", Head: "销量", Numeric: true},
+		{Field: "revenue", Head: "销售额", Numeric: true},
+	}
+}
+
+func (productSalesReport) Chart() *ReportChart {
+	return &ReportChart{Type: ReportChartBar, Label: "product", Values: []string{"revenue"}}
+}
+
+func (productSalesReport) Query(ctx stdctx.Context, values map[string]string) ([]map[string]interface{}, bool, error) {
+	from, to, err := reportDateRange(values)
+	if err != nil {
+		return nil, false, err
+	}
+	limit, err := strconv.Atoi(values["limit"])
+	if err != nil || limit <= 0 || limit > models.ReportMaxRows {
+		return nil, false, fmt.Errorf("商品数应为 1 到 %d 之间的整数", models.ReportMaxRows)
+	}
+	rows, truncated, err := models.ReportRows(ctx, `SELECT product, SUM(quantity) AS quantity, SUM(quantity * price) AS revenue
+FROM order_items WHERE created_at >= ? AND created_at < ?
+GROUP BY product ORDER BY revenue DESC`, from, to)
+	if err == nil && len(rows) > limit {
+		rows, truncated = rows[:limit], false
+	}
+	return rows, truncated, err
+}
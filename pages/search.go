@@ -0,0 +1,176 @@
+// pages 包 - 页面处理器
+// 本文件实现全局搜索页面和顶部导航栏的搜索框：同时在用户、文章、作者等表格中搜索，按表格分组显示结果
+
+package pages
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+)
+
+// SearchURL 全局搜索页面的地址，查询参数 q 为搜索内容，相对于后台的路由前缀
+const SearchURL = "/search"
+
+// searchLimit 每个表格最多显示的结果数
+const searchLimit = 10
+
+// searchGroup 一个表格的搜索结果
+type searchGroup struct {
+	tables.Searchable
+	hits []models.SearchHit
+	more bool
+	err  error
+}
+
+// SearchPage 返回全局搜索页面
+//
+// 参数:
+//   - ctx: 请求上下文对象，查询参数 q 为搜索内容
+//
+// 返回值:
+//   - types.Panel: 搜索框和按表格分组的结果，每条结果链接到记录的编辑页面
+//   - error: 始终为 nil，单个表格查询失败时在该分组中显示错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.SearchURL), pages.SearchPage)
+//	eng.AddNavButtons("", icon.Search, pages.SearchButton())
+//
+// 注意事项:
+//   - 搜索范围为通过 tables.RegisterSearch 注册的表格，只搜索当前管理员有列表权限的表格；
+//     与列表相同，非超级管理员在用户、文章等表格中只能搜索到自己创建的记录
+//   - 各表格的查询同时执行，页面的等待时间取决于最慢的一个表格
+func SearchPage(ctx *context.Context) (types.Panel, error) {
+	q := strings.TrimSpace(ctx.Query("q"))
+	panel := types.Panel{
+		Title:       "全局搜索",
+		Description: "在用户、文章、作者等表格中搜索",
+	}
+	if q == "" {
+		panel.Content = searchForm(q)
+		return panel, nil
+	}
+
+	user := auth.Auth(ctx)
+	var groups []*searchGroup
+	for _, s := range tables.Searchables() {
+		if user.CheckPermissionByUrlMethod(config.Url("/info/"+s.Key), http.MethodGet, url.Values{}) {
+			groups = append(groups, &searchGroup{Searchable: s})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *searchGroup, spec models.SearchSpec) {
+			defer wg.Done()
+			g.hits, g.more, g.err = models.Search(ctx.Request.Context(), spec, q, searchLimit)
+		}(g, g.ScopedSpec(ctx))
+	}
+	wg.Wait()
+
+	panel.Content = searchForm(q) + renderSearchResults(groups)
+	panel.Description = template.HTML("搜索: " + template.HTMLEscapeString(q))
+	return panel, nil
+}
+
+// searchForm 搜索框，以 GET 提交到全局搜索页面
+func searchForm(q string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<form method="get" action="%s" style="margin-bottom:10px">
+  <div class="input-group">
+    <input type="text" name="q" class="form-control" placeholder="输入姓名、标题、邮箱等，多个词以空格分隔" value="%s" autofocus>
+    <span class="input-group-btn"><button type="submit" class="btn btn-primary"><i class="fa fa-search"></i> 搜索</button></span>
+  </div>
+</form>`, config.Url(SearchURL), template.HTMLEscapeString(q)))
+}
+
+// renderSearchResults 有结果的表格各一个盒子，没有结果的表格在最后汇总为一行
+func renderSearchResults(groups []*searchGroup) template.HTML {
+	var found, empty template.HTML
+	for _, g := range groups {
+		if g.err == nil && len(g.hits) == 0 {
+			empty += template.HTML(fmt.Sprintf(`<span class="label label-default" style="margin-right:5px">%s 0</span>`,
+				template.HTMLEscapeString(g.Title)))
+			continue
+		}
+		found += renderSearchGroup(g)
+	}
+	if found == "" && empty == "" {
+		return `<p class="text-muted">没有可以搜索的表格</p>`
+	}
+	if found == "" {
+		found = `<p class="text-muted">没有找到匹配的记录</p>`
+	}
+	if empty != "" {
+		empty = `<p class="text-muted">没有结果: ` + empty + `</p>`
+	}
+	return found + empty
+}
+
+// renderSearchGroup 一个表格的搜索结果
+func renderSearchGroup(g *searchGroup) template.HTML {
+	var body template.HTML
+	if g.err != nil {
+		body = template.HTML(`<p class="text-danger">搜索失败: ` + template.HTMLEscapeString(g.err.Error()) + `</p>`)
+	} else {
+		body = `<div class="list-group" style="margin-bottom:0">`
+		for _, h := range g.hits {
+			title := h.Title
+			if title == "" {
+				title = "#" + h.ID
+			}
+			detail := ""
+			if h.Detail != "" {
+				detail = `<p class="list-group-item-text text-muted">` + template.HTMLEscapeString(h.Detail) + `</p>`
+			}
+			body += template.HTML(fmt.Sprintf(`<a class="list-group-item" href="%s"><h5 class="list-group-item-heading">%s <small>#%s</small></h5>%s</a>`,
+				config.Url("/info/"+g.Key+"/edit?__goadmin_edit_pk="+url.QueryEscape(h.ID)),
+				template.HTMLEscapeString(title), template.HTMLEscapeString(h.ID), detail))
+		}
+		body += `</div>`
+	}
+
+	// 超过 searchLimit 条时只显示前 searchLimit 条，数量后加 +
+	count := strconv.Itoa(len(g.hits))
+	if g.more {
+		count += "+"
+	}
+	return template.HTML(fmt.Sprintf(`<div class="box box-default">
+  <div class="box-header with-border">
+    <h3 class="box-title">%s <span class="badge">%s</span></h3>
+    <div class="box-tools pull-right"><a class="btn btn-box-tool" href="%s">打开列表</a></div>
+  </div>
+  <div class="box-body">%s</div>
+</div>`, template.HTMLEscapeString(g.Title), count, config.Url("/info/"+g.Key), body))
+}
+
+// searchNavForm 顶部导航栏中的搜索框，作为搜索按钮的附加内容显示在按钮之后，窄屏时隐藏只保留按钮
+func searchNavForm() template.HTML {
+	return template.HTML(`<li class="hidden-xs hidden-sm">
+  <form method="get" action="` + config.Url(SearchURL) + `" class="navbar-form" style="margin:8px 0;padding:0 5px">
+    <input type="text" name="q" class="form-control input-sm" placeholder="全局搜索" style="width:160px">
+  </form>
+</li>`)
+}
+
+// SearchButton 返回顶部导航栏搜索按钮的动作：点击进入全局搜索页面，宽屏时按钮后面显示搜索框
+//
+// 使用示例:
+//
+//	eng.AddNavButtons("", icon.Search, pages.SearchButton())
+func SearchButton() types.Action {
+	return action.Jump(config.Url(SearchURL), searchNavForm())
+}
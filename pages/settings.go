@@ -0,0 +1,212 @@
+// pages 包 - 页面处理器
+// 本文件实现站点设置页面：在一个表单中修改站点标题、Logo、每页条数和维护模式，保存到 settings 表
+// 与 /admin/form 的示例表单不同，这里的表单由 SaveSiteSettings 处理提交并真正写入数据库
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// SiteSettingsURL 站点设置页面的地址，GET 显示表单，POST 保存，相对于后台的路由前缀
+const SiteSettingsURL = "/site_settings"
+
+// maxPageSize 每页条数的上限
+const maxPageSize = 200
+
+// SiteSettingsPage 返回站点设置页面
+//
+// 参数:
+//   - ctx: 请求上下文对象
+//
+// 返回值:
+//   - types.Panel: 站点设置表单，显示各设置项的当前值
+//   - error: 没有查看系统设置的权限时返回错误
+//
+// 使用示例:
+//
+//	eng.HTML("GET", config.Url(pages.SiteSettingsURL), pages.SiteSettingsPage)
+//	eng.Data("POST", config.Url(pages.SiteSettingsURL), pages.SaveSiteSettings)
+//
+// 注意事项:
+//   - 查看需要系统设置列表的权限，保存需要修改系统设置的权限，没有修改权限时表单只读
+//   - 这些设置项也可以在系统设置表格（/admin/info/settings）中逐项修改
+func SiteSettingsPage(ctx *context.Context) (types.Panel, error) {
+	user := auth.Auth(ctx)
+	if !user.CheckPermissionByUrlMethod(config.Url("/info/settings"), http.MethodGet, url.Values{}) {
+		return types.Panel{}, errors.New("没有查看系统设置的权限")
+	}
+	editable := user.CheckPermissionByUrlMethod(config.Url("/edit/settings"), http.MethodPost, url.Values{})
+
+	c := ctx.Request.Context()
+	title := models.Settings.String(c, models.SettingSiteTitle, "")
+	logoURL := models.Settings.String(c, models.SettingLogoURL, "")
+	pageSize := models.Settings.Int(c, models.SettingDefaultPageSize, 10)
+	maintenance := models.Settings.Bool(c, models.SettingMaintenance, false)
+
+	return types.Panel{
+		Content:     renderSiteSettings(title, logoURL, pageSize, maintenance, editable),
+		Title:       "站点设置",
+		Description: "站点标题、Logo、每页条数和维护模式",
+	}, nil
+}
+
+// renderSiteSettings 生成站点设置表单
+func renderSiteSettings(title, logoURL string, pageSize int, maintenance, editable bool) template.HTML {
+	disabled, checked, preview := "", "", ""
+	if !editable {
+		disabled = " disabled"
+	}
+	if maintenance {
+		checked = " checked"
+	}
+	if logoURL != "" {
+		preview = fmt.Sprintf(`<img src="%s" style="max-height:40px;margin-top:6px">`, template.HTMLEscapeString(logoURL))
+	}
+
+	content := template.HTML(fmt.Sprintf(`<div class="box box-primary">
+<form id="site-settings-form" class="form-horizontal" method="post" action="%s">
+  <div class="box-body">
+    <div class="form-group">
+      <label class="col-sm-2 control-label" for="site_title">站点标题</label>
+      <div class="col-sm-8">
+        <input type="text" class="form-control" id="site_title" name="site_title" value="%s"%s>
+        <span class="help-block">浏览器标签页和侧边栏顶部显示的标题，留空时使用 config.yml 中的 title</span>
+      </div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-2 control-label" for="logo_url">Logo 地址</label>
+      <div class="col-sm-8">
+        <input type="text" class="form-control" id="logo_url" name="logo_url" value="%s" placeholder="/uploads/logo.png 或 https://..."%s>
+        <span class="help-block">填写后侧边栏顶部显示该图片，只能是以 / 开头的本站路径或 http(s) 地址</span>
+        %s
+      </div>
+    </div>
+    <div class="form-group">
+      <label class="col-sm-2 control-label" for="default_page_size">每页条数</label>
+      <div class="col-sm-3">
+        <input type="number" class="form-control" id="default_page_size" name="default_page_size" min="1" max="%d" value="%d"%s>
+        <span class="help-block">数据表格默认每页显示的条数</span>
+      </div>
+    </div>
+    <div class="form-group">
+      <div class="col-sm-offset-2 col-sm-8">
+        <div class="checkbox">
+          <label><input type="checkbox" name="maintenance_mode" value="true"%s%s> 维护模式</label>
+        </div>
+        <span class="help-block">开启后只有超级管理员可以使用后台，其他管理员看到维护提示</span>
+      </div>
+    </div>
+  </div>`, config.Url(SiteSettingsURL),
+		template.HTMLEscapeString(title), disabled,
+		template.HTMLEscapeString(logoURL), disabled, preview,
+		maxPageSize, pageSize, disabled,
+		checked, disabled))
+
+	if editable {
+		content += `<div class="box-footer"><div class="col-sm-offset-2"><button type="submit" class="btn btn-primary">保存</button></div></div>`
+	}
+	return content + `</form></div><script>` + template.HTML(siteSettingsJS) + `</script>`
+}
+
+// siteSettingsJS 以 AJAX 提交站点设置表单，保存失败时提示原因，成功后刷新整个页面
+const siteSettingsJS = template.JS(`
+(function () {
+    $('#site-settings-form').on('submit', function (e) {
+        e.preventDefault();
+        $.ajax({
+            method: 'post',
+            url: $(this).attr('action'),
+            data: $(this).serialize(),
+            success: function () {
+                // 标题和 Logo 在页面框架中，PJAX 只刷新内容区域，这里刷新整个页面
+                location.reload();
+            },
+            error: function (data) {
+                swal(data.responseJSON ? data.responseJSON.msg : '保存失败', '', 'error');
+            }
+        });
+    });
+})();
+`)
+
+// SaveSiteSettings 保存站点设置页面提交的设置
+//
+// 请求格式:
+//
+//	POST site_title=示例后台&logo_url=/uploads/logo.png&default_page_size=20&maintenance_mode=true
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+//
+// 注意事项:
+//   - 需要修改系统设置的权限
+//   - 没有提交 maintenance_mode 时关闭维护模式（复选框未勾选时浏览器不提交该字段）
+//   - 通过 tables.SaveSettings 保存，与在系统设置表格中修改一样写入审计日志
+func SaveSiteSettings(ctx *context.Context) {
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/edit/settings"), http.MethodPost, url.Values{}) {
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"code": http.StatusForbidden,
+			"msg":  "没有修改系统设置的权限",
+		})
+		return
+	}
+
+	values, err := siteSettingsValues(ctx)
+	if err == nil {
+		err = tables.SaveSettings(ctx, values)
+	}
+	switch {
+	case errors.Is(err, models.ErrInvalidSetting):
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code": http.StatusInternalServerError,
+			"msg":  "保存站点设置失败: " + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+	})
+}
+
+// siteSettingsValues 读取并校验提交的站点设置，返回设置项的键到值
+// 校验失败时返回包装了 models.ErrInvalidSetting 的错误
+func siteSettingsValues(ctx *context.Context) (map[string]string, error) {
+	logoURL := strings.TrimSpace(ctx.FormValue("logo_url"))
+	if logoURL != "" && !strings.HasPrefix(logoURL, "/") &&
+		!strings.HasPrefix(logoURL, "http://") && !strings.HasPrefix(logoURL, "https://") {
+		return nil, fmt.Errorf("%w: Logo 地址只能是以 / 开头的本站路径或 http(s) 地址", models.ErrInvalidSetting)
+	}
+	pageSize, err := strconv.Atoi(strings.TrimSpace(ctx.FormValue("default_page_size")))
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("%w: 每页条数应为 1 到 %d 之间的整数", models.ErrInvalidSetting, maxPageSize)
+	}
+	return map[string]string{
+		models.SettingSiteTitle:       strings.TrimSpace(ctx.FormValue("site_title")),
+		models.SettingLogoURL:         logoURL,
+		models.SettingDefaultPageSize: strconv.Itoa(pageSize),
+		models.SettingMaintenance:     strconv.FormatBool(ctx.FormValue("maintenance_mode") == "true"),
+	}, nil
+}
@@ -0,0 +1,135 @@
+// pages 包 - 页面处理器
+// 本文件实现仪表板组件的并发加载
+// 各组件的统计查询相互独立，并发执行后页面耗时取决于最慢的组件而不是所有组件之和；
+// 单个组件超时或出错时只在该组件的位置显示提示，其余组件正常显示
+
+package pages
+
+import (
+	stdctx "context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/pages/widgets"
+	"golang.org/x/sync/errgroup"
+)
+
+// WidgetTimeout 单个组件的最长加载时间，超时的组件显示超时提示
+var WidgetTimeout = 5 * time.Second
+
+// WidgetConcurrency 同时加载的组件数上限
+// SQLite 同一时间只允许一个写入，读取并发过高也只会在数据库锁上排队，因此不宜设置过大
+var WidgetConcurrency = 4
+
+// loadWidgets 并发调用组件生成函数
+//
+// 参数:
+//   - parent: 请求的上下文，请求取消时不再等待尚未完成的组件
+//   - d: 仪表板定义
+//   - params: 所有组件共享的参数，Options 按组件配置替换
+//
+// 返回值:
+//   - []dashboardWidget: 与 d.Widgets 顺序一致，出错、超时或 panic 的组件替换为错误提示，
+//     延迟加载的组件替换为加载中的占位内容（见 deferredPlaceholder）
+//   - error: 包含未注册的组件时返回错误，此时不加载任何组件
+//
+// 注意事项:
+//   - 超时后组件的数据库查询通过 Params.Context 取消，但组件生成函数本身仍会执行到结束，结果被丢弃
+func loadWidgets(parent stdctx.Context, d Dashboard, params widgets.Params) ([]dashboardWidget, error) {
+	configs := d.Widgets
+	fns := make([]widgets.WidgetFunc, len(configs))
+	for i, cfg := range configs {
+		fn, ok := widgets.Get(cfg.Name)
+		if !ok {
+			return nil, fmt.Errorf("未注册的仪表板组件: %s", cfg.Name)
+		}
+		fns[i] = fn
+	}
+
+	list := make([]dashboardWidget, len(configs))
+
+	var g errgroup.Group
+	g.SetLimit(WidgetConcurrency)
+	for i, cfg := range configs {
+		if info, ok := cfg.deferred(); ok {
+			w := deferredPlaceholder(deferredWidgetURL(d.Name, cfg.Name, params), info)
+			if cfg.Width > 0 {
+				w.Width = cfg.Width
+			}
+			list[i] = dashboardWidget{Name: cfg.Name, Widget: w}
+			continue
+		}
+
+		i, cfg := i, cfg
+		g.Go(func() error {
+			p := params
+			p.Options = cfg.Options
+
+			w, err := runWidget(parent, fns[i], p)
+			if err != nil {
+				w = widgetPlaceholder(cfg.Name, err)
+			}
+			if cfg.Width > 0 {
+				w.Width = cfg.Width
+			}
+			list[i] = dashboardWidget{Name: cfg.Name, Widget: w}
+
+			// 组件的错误已转换为提示，不返回给 errgroup，避免一个组件失败导致其他组件被取消
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return list, nil
+}
+
+// runWidget 在独立的 goroutine 中调用组件生成函数，最多等待 WidgetTimeout
+// 组件 panic 时转换为错误返回，不影响整个进程
+func runWidget(parent stdctx.Context, fn widgets.WidgetFunc, p widgets.Params) (widgets.Widget, error) {
+	ctx, cancel := stdctx.WithTimeout(parent, WidgetTimeout)
+	defer cancel()
+
+	type result struct {
+		w   widgets.Widget
+		err error
+	}
+	// 缓冲为 1，超时返回后组件 goroutine 写入结果时不会阻塞
+	done := make(chan result, 1)
+
+	p.Context = ctx
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("组件执行出错: %v", r)}
+			}
+		}()
+		w, err := fn(p)
+		done <- result{w: w, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.w, r.err
+	case <-ctx.Done():
+		if ctx.Err() == stdctx.DeadlineExceeded {
+			return widgets.Widget{}, fmt.Errorf("加载超时（超过 %s）", WidgetTimeout)
+		}
+		return widgets.Widget{}, ctx.Err()
+	}
+}
+
+// widgetPlaceholder 生成加载失败的组件占位内容
+// 保留组件名称，使布局中该组件的位置、宽度和隐藏状态保持不变
+func widgetPlaceholder(name string, err error) widgets.Widget {
+	content := template.HTML(fmt.Sprintf(`<div class="box box-default">
+  <div class="box-body">
+    <div class="callout callout-danger" style="margin-bottom:0">
+      <h4><i class="fa fa-warning"></i> %s 加载失败</h4>
+      <p>%s</p>
+    </div>
+  </div>
+</div>`, template.HTMLEscapeString(name), template.HTMLEscapeString(err.Error())))
+
+	return widgets.Widget{Title: name, Width: 4, Content: content}
+}
@@ -0,0 +1,111 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"浏览器使用情况"组件
+package widgets
+
+import (
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/chart_legend"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+func init() {
+	Register("browsers", Browsers)
+	Defer("browsers", "浏览器使用情况", 4)
+}
+
+// browserColors 浏览器类型对应的颜色
+// legend 为 AdminLTE 的颜色名（图例使用 text-<颜色> 样式），chart 为饼图扇区的同色 RGB 值
+var browserColors = map[string]struct {
+	legend string
+	chart  chartjs.Color
+}{
+	"Chrome": {"red", "rgb(221, 75, 57)"},
+	"IE":     {"green", "rgb(0, 166, 90)"},
+	"火狐":     {"yellow", "rgb(243, 156, 18)"},
+	"Safari": {"blue", "rgb(0, 115, 183)"},
+	"欧朋":     {"light-blue", "rgb(60, 141, 188)"},
+	"Edge":   {"aqua", "rgb(0, 192, 239)"},
+	"其他":     {"gray", "rgb(210, 214, 222)"},
+}
+
+// Browsers 浏览器使用情况组件
+// 使用饼图和图例展示日期范围内各浏览器的访问占比
+// 数据来自 page_views 表，由 middleware.PageViewTracker 记录
+//
+// 返回值:
+//   - Widget: 默认宽度为 4 的组件
+func Browsers(p Params) (Widget, error) {
+	components := tmpl.Default()
+	colComp := components.Col()
+
+	usage := p.Repos.PageViews.BrowserUsage(p.Context, p.Range.From, p.Range.To)
+
+	/**************************
+	 * Pie Chart - 饼图组件
+	/**************************/
+
+	var (
+		labels  = make([]string, 0, len(usage))
+		data    = make([]float64, 0, len(usage))
+		colors  = make([]chartjs.Color, 0, len(usage))
+		legends = make([]map[string]string, 0, len(usage))
+	)
+	for _, u := range usage {
+		c, ok := browserColors[u.Browser]
+		if !ok {
+			c = browserColors["其他"]
+		}
+		labels = append(labels, u.Browser)
+		data = append(data, u.Views)
+		colors = append(colors, c.chart)
+		legends = append(legends, map[string]string{
+			"label": " " + u.Browser,
+			"color": c.legend,
+		})
+	}
+
+	var body template.HTML
+	if len(usage) == 0 {
+		body = `<p class="text-center text-muted">所选日期范围内暂无访问数据</p>`
+	} else {
+		// 创建饼图组件
+		// chartjs.Pie(): 创建Chart.js饼图实例
+		// SetLabels: 设置标签（浏览器名称）
+		// DSData: 设置每种浏览器的访问次数
+		// DSBackgroundColor: 设置每个扇区的背景色，与图例颜色一致
+		pie := chartjs.Pie().
+			SetHeight(170).
+			SetLabels(labels).
+			SetID("pieChart").
+			AddDataSet("浏览器").
+			DSData(data).
+			DSBackgroundColor(colors).
+			GetContent()
+
+		// 创建图例组件，顺序与饼图扇区一致（按访问次数降序）
+		legend := chart_legend.New().SetData(legends).GetContent()
+
+		body = components.Row().
+			SetContent(colComp.SetSize(types.SizeMD(8)).
+				SetContent(pie).
+				GetContent() + colComp.SetSize(types.SizeMD(4)).
+				SetContent(legend).
+				GetContent()).GetContent()
+	}
+
+	// 创建危险主题的盒子组件包裹饼图和图例
+	// SetTheme("danger"): 设置主题为危险样式（红色）
+	// WithHeadBorder: 显示头部边框
+	// SetHeader: 设置盒子标题
+	// SetBody: 设置盒子内容（饼图和图例，或无数据提示）
+	// SetFooter: 设置底部内容（查看所有用户链接）
+	boxDanger := components.Box().SetTheme("danger").WithHeadBorder().SetHeader("浏览器使用情况").
+		SetBody(body).
+		SetFooter(`<p class="text-center"><a href="javascript:void(0)" class="uppercase">查看所有用户</a></p>`).
+		GetContent()
+
+	return Widget{Title: "浏览器使用情况", Width: 4, Content: boxDanger}, nil
+}
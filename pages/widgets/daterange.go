@@ -0,0 +1,61 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件定义组件共享的日期范围类型和统计辅助函数
+package widgets
+
+import "time"
+
+// DateLayout 日期的文本格式
+// 与 HTML5 的 <input type="date"> 提交格式以及 SQLite 的 DATE() 结果一致
+const DateLayout = "2006-01-02"
+
+// DateRange 仪表板的日期范围
+// From 为开始日期当天的 00:00:00，To 为结束日期当天的 23:59:59
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Days 返回日期范围内包含的天数
+// 按 UTC 的日历日期计算，避免夏令时切换导致相差一小时而少算一天；
+// 使用 Unix 秒数相减，time.Duration 最多只能表示约 292 年，跨度更大时 Sub 的结果不正确
+func (r DateRange) Days() int {
+	from := time.Date(r.From.Year(), r.From.Month(), r.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(r.To.Year(), r.To.Month(), r.To.Day(), 0, 0, 0, 0, time.UTC)
+	return int((to.Unix()-from.Unix())/(24*60*60)) + 1
+}
+
+// Previous 返回紧邻当前范围之前、长度相同的日期范围
+// 用于计算环比变化，例如 3月1日-3月31日 的上一周期为 1月30日-2月29日
+func (r DateRange) Previous() DateRange {
+	days := r.Days()
+	return DateRange{
+		From: r.From.AddDate(0, 0, -days),
+		To:   r.To.AddDate(0, 0, -days),
+	}
+}
+
+// Labels 返回日期范围内每一天的标签（格式 01-02），用作折线图的 X 轴
+func (r DateRange) Labels() []string {
+	labels := make([]string, 0, r.Days())
+	for d := r.From; !d.After(r.To); d = d.AddDate(0, 0, 1) {
+		labels = append(labels, d.Format("01-02"))
+	}
+	return labels
+}
+
+// String 返回便于展示的日期范围文本，例如 "2024-01-01 - 2024-01-31"
+func (r DateRange) String() string {
+	return r.From.Format(DateLayout) + " - " + r.To.Format(DateLayout)
+}
+
+// progressPercent 计算进度百分比，结果限制在 0 到 100 之间
+func progressPercent(current, target float64) int {
+	if target <= 0 {
+		return 0
+	}
+	p := int(current / target * 100)
+	if p > 100 {
+		return 100
+	}
+	return p
+}
@@ -0,0 +1,57 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件定义延迟加载组件的标记
+// 图表、表格等查询较重的组件在页面中先输出占位内容，页面显示后再通过 AJAX 获取，
+// 缩短仪表板首次显示的时间
+package widgets
+
+// Deferred 延迟加载组件在内容加载前显示所需的信息
+type Deferred struct {
+	// Title 组件名称，与组件生成结果的 Title 一致，显示在组件工具栏中
+	Title string
+
+	// Width 默认宽度，与组件生成结果的 Width 一致
+	Width int
+}
+
+// deferred 延迟加载的组件名称到占位信息的映射，与 registry 共用 mu
+var deferred = make(map[string]Deferred)
+
+// Defer 将组件标记为默认延迟加载
+//
+// 参数:
+//   - name: 已注册的组件名称
+//   - title: 组件名称，内容加载前组件还没有生成，工具栏显示该名称
+//   - width: 默认宽度，内容加载前按该宽度占位
+//
+// 使用示例:
+//
+//	func init() {
+//	    Register("report", Report)
+//	    Defer("report", "总结报告", 12)
+//	}
+//
+// 注意事项:
+//   - 组件未注册时会 panic，应在 Register 之后调用
+//   - 仪表板配置中可以用 lazy 覆盖该设置
+func Defer(name, title string, width int) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registry[name]; !ok {
+		panic("widgets: Defer called for unregistered widget " + name)
+	}
+	deferred[name] = Deferred{Title: title, Width: width}
+}
+
+// DeferredInfo 获取组件的延迟加载信息
+//
+// 返回值:
+//   - Deferred: 占位信息
+//   - bool: 组件是否标记为默认延迟加载
+func DeferredInfo(name string) (Deferred, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	d, ok := deferred[name]
+	return d, ok
+}
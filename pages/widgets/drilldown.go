@@ -0,0 +1,37 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件定义从仪表板组件下钻到数据表格页面的辅助函数
+package widgets
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+)
+
+// filterTimeLayout 表格时间范围筛选参数的格式，与 DatetimeRange 筛选表单的提交格式一致
+const filterTimeLayout = "2006-01-02 15:04:05"
+
+// TableURL 返回按日期范围筛选的数据表格地址
+//
+// 参数:
+//   - prefix: 表格在 tables.Generators 中注册的前缀，如 users、orders
+//   - field: 按时间范围筛选的字段，该字段必须以 form.DatetimeRange 设置为可筛选
+//
+// 返回值:
+//   - string: 带后台 URL 前缀的地址，例如 /admin/info/users?created_at_start__goadmin=...&created_at_end__goadmin=...
+func (r DateRange) TableURL(prefix, field string) string {
+	query := url.Values{}
+	query.Set(field+parameter.FilterRangeParamStartSuffix, r.From.Format(filterTimeLayout))
+	query.Set(field+parameter.FilterRangeParamEndSuffix, r.To.Format(filterTimeLayout))
+	return config.Url("/info/"+prefix) + "?" + query.Encode()
+}
+
+// drillDown 将组件内容包裹为链接，点击后跳转到 href
+// 链接继承内容原有的文字颜色，不改变组件的外观
+func drillDown(href string, content template.HTML) template.HTML {
+	return template.HTML(fmt.Sprintf(`<a href="%s" style="color:inherit;display:block" title="查看明细">%s</a>`,
+		template.HTMLEscapeString(href), content))
+}
@@ -0,0 +1,47 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件定义基于 ECharts 的组件共用的脚本地址和加载函数
+package widgets
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ECharts 脚本地址
+// 主题没有内置 ECharts，默认从 CDN 加载；无法访问外网的部署可以改为本地静态文件地址，
+// 例如放在 ./uploads 目录下并改为 /uploads/echarts.min.js
+var (
+	// EChartsURL ECharts 主脚本地址
+	// 使用 4.x 版本，5.x 起不再随包发布世界地图数据
+	EChartsURL = "https://cdn.jsdelivr.net/npm/echarts@4.9.0/dist/echarts.min.js"
+
+	// EChartsWorldURL ECharts 世界地图数据脚本地址
+	EChartsWorldURL = "https://cdn.jsdelivr.net/npm/echarts@4.9.0/map/js/world.js"
+)
+
+// loadScripts 生成按顺序加载脚本后调用 callback 的 JS 代码
+// 同一页面上的多个组件共享加载状态，每个脚本只会请求一次，
+// 避免重复加载 ECharts 覆盖已注册的地图数据
+//
+// 参数:
+//   - callback: 脚本全部加载完成后调用的 JS 函数名
+//   - urls: 按依赖顺序排列的脚本地址
+func loadScripts(callback string, urls ...string) string {
+	list, _ := json.Marshal(urls)
+	return fmt.Sprintf(`window.dashboardLoadScripts = window.dashboardLoadScripts || (function () {
+        var loaded = {};
+        return function (urls, cb) {
+            var chain = $.when();
+            $.each(urls, function (_, url) {
+                chain = chain.then(function () {
+                    if (!loaded[url]) {
+                        loaded[url] = $.ajax({url: url, dataType: 'script', cache: true});
+                    }
+                    return loaded[url];
+                });
+            });
+            chain.done(cb);
+        };
+    })();
+    dashboardLoadScripts(%s, %s);`, list, callback)
+}
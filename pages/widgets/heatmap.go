@@ -0,0 +1,137 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现 GitHub 风格的日历热力图组件
+package widgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	tmpl "github.com/purpose168/GoAdmin/template"
+)
+
+func init() {
+	Register("heatmap", Heatmap)
+	Register("orders_heatmap", OrdersHeatmap)
+	Defer("heatmap", "日历热力图", 12)
+	Defer("orders_heatmap", heatmapTitles[models.MetricOrders]+"热力图", 12)
+}
+
+// heatmapTitles 热力图支持的指标及其标题
+var heatmapTitles = map[string]string{
+	models.MetricAdminActions: "管理员操作",
+	models.MetricOrders:       "每日订单",
+}
+
+// Heatmap 日历热力图组件
+// 展示最近一年每天的计数，数据来自 daily_counts 表
+//
+// 参数:
+//   - p: 组件参数（热力图固定展示最近一年，不使用日期范围）
+//
+// 组件参数（p.Options）:
+//   - metric: 数据来源指标，admin_actions（默认，管理员操作次数）或 orders（订单数）
+//
+// 返回值:
+//   - Widget: 默认宽度为 12 的组件
+func Heatmap(p Params) (Widget, error) {
+	metric := p.Options["metric"]
+	if metric == "" {
+		metric = models.MetricAdminActions
+	}
+	title, ok := heatmapTitles[metric]
+	if !ok {
+		return Widget{}, fmt.Errorf("热力图不支持的指标: %s", metric)
+	}
+	return calendarHeatmap(p, "heatmap-"+metric, title, metric)
+}
+
+// OrdersHeatmap 订单热力图组件，等同于 metric 为 orders 的 Heatmap
+func OrdersHeatmap(p Params) (Widget, error) {
+	return calendarHeatmap(p, "heatmap-"+models.MetricOrders, heatmapTitles[models.MetricOrders], models.MetricOrders)
+}
+
+// calendarHeatmap 生成某个指标最近一年的日历热力图
+//
+// 参数:
+//   - p: 组件参数，使用其中的 Context 和 Repos 查询每日计数
+//   - id: 图表容器的元素ID，同一页面上的多个热力图必须不同
+//   - title: 组件标题
+//   - metric: daily_counts 表中的指标名称
+func calendarHeatmap(p Params, id, title, metric string) (Widget, error) {
+	components := tmpl.Default()
+
+	to := time.Now()
+	from := to.AddDate(-1, 0, 1)
+
+	// ECharts 日历热力图的数据格式为 [["2006-01-02", 计数], ...]
+	counts := p.Repos.DailyCounts.Between(p.Context, metric, from, to)
+	data := make([][]interface{}, 0, len(counts))
+	maxCount := 1
+	for _, c := range counts {
+		data = append(data, []interface{}{c.Day, c.Count})
+		if c.Count > maxCount {
+			maxCount = c.Count
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Widget{}, err
+	}
+
+	body := template.HTML(fmt.Sprintf(`<div id="%s" style="height:200px"></div>
+<script>
+(function () {
+    var data = %s;
+    function render() {
+        var chart = echarts.init(document.getElementById(%q));
+        chart.setOption({
+            tooltip: {
+                formatter: function (p) {
+                    return p.value[0] + ': ' + p.value[1];
+                }
+            },
+            visualMap: {
+                min: 0,
+                max: %d,
+                type: 'piecewise',
+                orient: 'horizontal',
+                left: 'center',
+                bottom: 0,
+                inRange: {color: ['#ebedf0', '#9be9a8', '#40c463', '#30a14e', '#216e39']}
+            },
+            calendar: {
+                top: 30,
+                left: 40,
+                right: 10,
+                cellSize: ['auto', 13],
+                range: [%q, %q],
+                itemStyle: {borderWidth: 2, borderColor: '#fff'},
+                dayLabel: {nameMap: 'cn'},
+                monthLabel: {nameMap: 'cn'},
+                yearLabel: {show: false}
+            },
+            series: [{
+                type: 'heatmap',
+                coordinateSystem: 'calendar',
+                data: data
+            }]
+        });
+        $(window).on('resize', function () {
+            chart.resize();
+        });
+    }
+    %s
+})();
+</script>`, id, encoded, id, maxCount, from.Format(DateLayout), to.Format(DateLayout), loadScripts("render", EChartsURL)))
+
+	box := components.Box().WithHeadBorder().
+		SetHeader(template.HTML(title + "（最近一年）")).
+		SetBody(body).
+		GetContent()
+
+	return Widget{Title: title + "热力图", Width: 12, Content: box}, nil
+}
@@ -0,0 +1,166 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"最新订单"组件
+package widgets
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/infobox"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/color"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// RecentOrdersLimit 最新订单组件显示的订单数
+const RecentOrdersLimit = 5
+
+func init() {
+	Register("orders", Orders)
+	Defer("orders", "最新订单", 8)
+}
+
+// Orders 最新订单组件
+// 第一行为CPU使用率、点赞、销售额、新会员四个信息框，下方为 orders 表中最新的订单
+// 点击销售额和新会员信息框分别跳转到按当前日期范围筛选的订单和用户列表
+//
+// 参数:
+//   - p: 组件参数，日期范围只用于下钻链接
+//
+// 返回值:
+//   - Widget: 默认宽度为 8 的组件
+func Orders(p Params) (Widget, error) {
+	components := tmpl.Default()
+	colComp := components.Col()
+
+	// 获取统计数据
+	// p.Repos.Statistics.First: 查询第一条统计记录
+	// 返回值: 包含CPU使用率、点赞数、销售额、新会员数等统计信息
+	// 如果数据库中没有记录，返回零值结构体
+	statics := p.Repos.Statistics.First(p.Context)
+
+	// 获取最近一小时的系统资源
+	// p.Repos.Statistics.LatestSystem: 查询系统资源采集任务最近写入的一小时
+	// 采集任务没有运行过时返回零值结构体
+	system := p.Repos.Statistics.LatestSystem(p.Context)
+
+	/**************************
+	 * Info Box
+	/**************************/
+
+	// 创建CPU使用率信息框
+	// system.CPUTmpl(): 将最近一小时的平均CPU使用率转换为HTML格式
+	// SetText: 设置显示文本为"CPU使用率"
+	// SetColor: 设置颜色为青色(Aqua)
+	// SetNumber: 显示CPU使用率数值，并添加百分号后缀
+	// SetIcon: 设置图标为齿轮图标
+	infobox1 := infobox.New().
+		SetText("CPU使用率").
+		SetColor(color.Aqua).
+		SetNumber(system.CPUTmpl() + "<small>%</small>").
+		SetIcon("ion-ios-gear-outline").
+		GetContent()
+
+	// 创建点赞数信息框
+	// statics.LikesTmpl(): 将点赞数转换为HTML格式
+	// SetText: 设置显示文本为"点赞"
+	// SetColor: 设置颜色为红色(Red)
+	// SetNumber: 显示点赞数，并添加美元符号后缀
+	// SetIcon: 设置图标为Google Plus图标
+	infobox2 := infobox.New().
+		SetText("点赞").
+		SetColor(color.Red).
+		SetNumber(statics.LikesTmpl() + "<small>$</small>").
+		SetIcon(icon.GooglePlus).
+		GetContent()
+
+	// 创建销售额信息框
+	// statics.SalesTmpl(): 将销售额转换为HTML格式
+	// SetText: 设置显示文本为"销售额"
+	// SetColor: 设置颜色为绿色(Green)
+	// SetNumber: 显示销售额数值
+	// SetIcon: 设置图标为购物车图标
+	// drillDown: 点击后跳转到同一日期范围内的订单列表
+	infobox3 := drillDown(p.Range.TableURL("orders", "created_at"), infobox.New().
+		SetText("销售额").
+		SetColor(color.Green).
+		SetNumber(statics.SalesTmpl()).
+		SetIcon("ion-ios-cart-outline").
+		GetContent())
+
+	// 创建新会员数信息框
+	// statics.NewMembersTmpl(): 将新会员数转换为HTML格式
+	// SetText: 设置显示文本为"新会员"
+	// SetColor: 设置颜色为黄色(Yellow)
+	// SetNumber: 显示新会员数数值
+	// SetIcon: 设置图标为用户群组图标
+	// drillDown: 点击后跳转到同一日期范围内注册的用户列表
+	infobox4 := drillDown(p.Range.TableURL("users", "created_at"), infobox.New().
+		SetText("新会员").
+		SetColor(color.Yellow).
+		SetNumber(statics.NewMembersTmpl()).
+		SetIcon("ion-ios-people-outline").
+		GetContent())
+
+	var size = types.SizeMD(3).SM(6).XS(12)
+	// 设置列的响应式宽度
+	// SizeMD(3): 在中等屏幕上占3/12宽度
+	// SM(6): 在小屏幕上占6/12宽度
+	// XS(12): 在超小屏幕上占12/12宽度（全宽）
+	infoboxCol1 := colComp.SetSize(size).SetContent(infobox1).GetContent()
+	infoboxCol2 := colComp.SetSize(size).SetContent(infobox2).GetContent()
+	infoboxCol3 := colComp.SetSize(size).SetContent(infobox3).GetContent()
+	infoboxCol4 := colComp.SetSize(size).SetContent(infobox4).GetContent()
+	// 创建第一行，包含4个信息框
+	row1 := components.Row().SetContent(infoboxCol1 + infoboxCol2 + infoboxCol3 + infoboxCol4).GetContent()
+
+	/**************************
+	 * Box - 订单表格
+	/**************************/
+
+	// 创建表格组件显示最新订单
+	// SetType("table"): 设置表格类型为标准表格
+	// SetInfoList: 设置表格数据，每行是一个map，键是列名，值是单元格内容
+	orders := p.Repos.Orders.Recent(p.Context, RecentOrdersLimit)
+	rows := make([]map[string]types.InfoItem, 0, len(orders))
+	for _, o := range orders {
+		rows = append(rows, map[string]types.InfoItem{
+			"订单ID": {Content: template.HTML(template.HTMLEscapeString(o.OrderNo))},
+			"商品":   {Content: template.HTML(template.HTMLEscapeString(o.Product))},
+			"状态":   {Content: template.HTML(template.HTMLEscapeString(o.Status))},
+			"金额":   {Content: template.HTML(fmt.Sprintf("¥%.2f", o.Amount))},
+		})
+	}
+
+	var table template.HTML
+	if len(rows) == 0 {
+		table = `<p class="text-center text-muted">暂无订单</p>`
+	} else {
+		table = components.Table().SetType("table").SetInfoList(rows).SetThead(types.Thead{
+			// 设置表头
+			{Head: "订单ID"},
+			{Head: "商品"},
+			{Head: "状态"},
+			{Head: "金额"},
+		}).GetContent()
+	}
+
+	// 创建盒子组件包裹表格
+	// WithHeadBorder: 显示头部边框
+	// SetHeader: 设置盒子标题
+	// SetHeadColor: 设置头部背景色
+	// SetBody: 设置盒子内容（表格）
+	// SetFooter: 设置底部内容（操作按钮），"查看所有新订单"跳转到当前日期范围内的订单列表
+	boxInfo := components.Box().
+		WithHeadBorder().
+		SetHeader("最新订单").
+		SetHeadColor("#f7f7f7").
+		SetBody(table).
+		SetFooter(template.HTML(`<div class="clearfix"><a href="javascript:void(0)" class="btn btn-sm btn-info btn-flat pull-left">处理订单</a><a href="` +
+			template.HTMLEscapeString(p.Range.TableURL("orders", "created_at")) +
+			`" class="btn btn-sm btn-default btn-flat pull-right">查看所有新订单</a> </div>`)).
+		GetContent()
+
+	return Widget{Title: "最新订单", Width: 8, Content: row1 + boxInfo}, nil
+}
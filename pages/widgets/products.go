@@ -0,0 +1,72 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"最近添加的产品"组件
+package widgets
+
+import (
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/productlist"
+	tmpl "github.com/purpose168/GoAdmin/template"
+)
+
+func init() {
+	Register("products", Products)
+}
+
+// Products 最近添加的产品组件
+// 使用产品列表展示最近添加的产品
+//
+// 返回值:
+//   - Widget: 默认宽度为 4 的组件
+func Products(p Params) (Widget, error) {
+	components := tmpl.Default()
+
+	/**************************
+	 * Product List - 产品列表
+	/**************************/
+
+	// 创建产品列表组件
+	// SetData: 设置产品数据，每个产品包含图片、标题、标签、描述等信息
+	productList := productlist.New().SetData([]map[string]string{
+		{
+			"img":         "//adminlte.io/themes/AdminLTE/dist/img/default-50x50.gif",
+			"title":       "GoAdmin",
+			"has_tabel":   "true",
+			"labeltype":   "warning",
+			"label":       "免费",
+			"description": `一个帮助您构建数据可视化系统的框架`,
+		}, {
+			"img":         "//adminlte.io/themes/AdminLTE/dist/img/default-50x50.gif",
+			"title":       "GoAdmin",
+			"has_tabel":   "true",
+			"labeltype":   "warning",
+			"label":       "免费",
+			"description": `一个帮助您构建数据可视化系统的框架`,
+		}, {
+			"img":         "//adminlte.io/themes/AdminLTE/dist/img/default-50x50.gif",
+			"title":       "GoAdmin",
+			"has_tabel":   "true",
+			"labeltype":   "warning",
+			"label":       "免费",
+			"description": `一个帮助您构建数据可视化系统的框架`,
+		}, {
+			"img":         "//adminlte.io/themes/AdminLTE/dist/img/default-50x50.gif",
+			"title":       "GoAdmin",
+			"has_tabel":   "true",
+			"labeltype":   "warning",
+			"label":       "免费",
+			"description": `一个帮助您构建数据可视化系统的框架`,
+		},
+	}).GetContent()
+
+	// 创建警告主题的盒子组件包裹产品列表
+	// SetTheme("warning"): 设置主题为警告样式（黄色）
+	// WithHeadBorder: 显示头部边框
+	// SetHeader: 设置盒子标题
+	// SetBody: 设置盒子内容（产品列表）
+	// SetFooter: 设置底部内容（查看所有产品链接）
+	boxWarning := components.Box().SetTheme("warning").WithHeadBorder().SetHeader("最近添加的产品").
+		SetBody(productList).
+		SetFooter(`<a href="javascript:void(0)" class="uppercase">查看所有产品</a>`).
+		GetContent()
+
+	return Widget{Title: "最近添加的产品", Width: 4, Content: boxWarning}, nil
+}
@@ -0,0 +1,164 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"总结报告"组件
+package widgets
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/description"
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/progress_group"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+func init() {
+	Register("report", Report)
+	Defer("report", "总结报告", 12)
+}
+
+// Report 总结报告组件
+// 包含销售折线图、目标完成进度条和环比描述
+// 折线图按日期范围聚合，环比描述按 p.Granularity 比较，目标完成进度条读取 goals 表
+//
+// 参数:
+//   - p: 组件参数，使用其中的 Range 作为统计区间、Granularity 作为环比粒度
+//
+// 组件选项:
+//   - series: 折线图的粒度，hour、day、week 或 month，不填时按日期范围的长度选择
+//
+// 返回值:
+//   - Widget: 默认宽度为 12 的组件
+func Report(p Params) (Widget, error) {
+	components := tmpl.Default()
+	colComp := components.Col()
+
+	dr := p.Range
+
+	/**************************
+	 * Box - 销售折线图和目标完成进度条
+	/**************************/
+
+	// 创建折线图组件
+	// chartjs.Line(): 创建Chart.js折线图实例
+	line := chartjs.Line()
+
+	// 按粒度汇总区间内的销售额，没有数据的周期补 0
+	labels, salesData := SalesSeries(p.Context, p.Repos.Statistics, dr, models.ParseSeriesGranularity(p.Options["series"]))
+
+	// 配置折线图
+	// SetID: 设置图表ID，用于在HTML中引用
+	// SetHeight: 设置图表高度（像素）
+	// SetTitle: 设置图表标题，显示当前日期范围
+	// SetLabels: 设置X轴标签（每个周期的开始时间）
+	// AddDataSet: 添加数据集
+	// DSData: 设置数据集的数值（每个周期的销售额）
+	// DSFill: 设置是否填充区域（false表示不填充）
+	// DSBorderColor: 设置线条颜色
+	// DSLineTension: 设置线条张力（0.1表示轻微曲线）
+	lineChart := line.
+		SetID("salechart").
+		SetHeight(180).
+		SetTitle(template.HTML("销售额: " + dr.String())).
+		SetLabels(labels).
+		AddDataSet("销售额").
+		DSData(salesData).
+		DSFill(false).
+		DSBorderColor("rgba(60,141,188,1)").
+		DSLineTension(0.1).
+		GetContent()
+
+	// 创建进度条组件的标题
+	title := `<p class="text-center"><strong>目标完成情况</strong></p>`
+
+	// 目标完成进度条
+	// 每个进度条对应 goals 表中的一条记录，在管理后台的"目标"表格中维护
+	// SetTitle: 设置进度条标题
+	// SetColor: 设置进度条颜色
+	// SetDenominator: 设置分母（目标值）
+	// SetMolecular: 设置分子（当前完成值）
+	// SetPercent: 设置完成百分比
+	progressGroups := template.HTML(title)
+	for _, g := range p.Repos.Goals.All(p.Context) {
+		progressGroups += progress_group.New().
+			SetTitle(template.HTML(template.HTMLEscapeString(g.Name))).
+			SetColor(template.HTML(template.HTMLEscapeString(g.Color))).
+			SetDenominator(int(g.Target)).
+			SetMolecular(int(g.Current)).
+			SetPercent(progressPercent(g.Current, g.Target)).
+			GetContent()
+	}
+
+	// 创建内部第一列，包含折线图
+	boxInternalCol1 := colComp.SetContent(lineChart).SetSize(types.SizeMD(8)).GetContent()
+
+	// 创建内部第二列，包含目标完成进度条
+	boxInternalCol2 := colComp.
+		SetContent(progressGroups).
+		SetSize(types.SizeMD(4)).
+		GetContent()
+
+	// 创建内部第一行，包含折线图和进度条
+	boxInternalRow := components.Row().SetContent(boxInternalCol1 + boxInternalCol2).GetContent()
+
+	// 环比描述组件
+	// 当前周期和上一周期按 p.Granularity 划分，由 p.Repos.Statistics.Compare 计算
+	// SetPercent: 设置相对上一周期的变化百分比
+	// SetNumber: 设置当前周期的数值
+	// SetTitle: 设置标题
+	// SetArrow: 设置箭头方向（up表示上升，down表示下降）
+	// SetColor: 设置颜色（green表示增长，red表示下降）
+	// SetBorder: 设置边框位置（最后一个之外都显示右边框）
+	_, kpis := p.Repos.Statistics.Compare(p.Context, dr.From, dr.To, p.Granularity)
+	numberFormats := []string{"¥%.0f", "%.0f", "%.0f", "%.1f%%"}
+	size2 := types.SizeSM(3).XS(6)
+	var descriptions template.HTML
+	for i, k := range kpis {
+		arrow, clr := "up", "green"
+		if !k.Up() {
+			arrow, clr = "down", "red"
+		}
+		d := description.New().
+			SetPercent(template.HTML(fmt.Sprintf("%.0f", math.Abs(k.Change())))).
+			SetNumber(template.HTML(fmt.Sprintf(numberFormats[i], k.Current))).
+			SetTitle(template.HTML(k.Name)).
+			SetArrow(arrow).
+			SetColor(template.HTML(clr))
+		if i < len(kpis)-1 {
+			d = d.SetBorder("right")
+		}
+		descriptions += colComp.SetContent(d.GetContent()).SetSize(size2).GetContent()
+	}
+
+	// 创建内部第二行，包含4个描述组件
+	boxInternalRow2 := components.Row().SetContent(descriptions).GetContent()
+
+	// 创建盒子组件包裹内部内容
+	// WithHeadBorder: 显示头部边框
+	// SetHeader: 设置盒子标题
+	// SetBody: 设置盒子内容（折线图和进度条）
+	// SetFooter: 设置底部内容（描述组件）
+	box := components.Box().WithHeadBorder().SetHeader(template.HTML("总结报告（" + dr.String() + "）")).
+		SetBody(boxInternalRow).
+		SetFooter(boxInternalRow2).
+		GetContent()
+
+	return Widget{Title: "总结报告", Width: 12, Content: box}, nil
+}
+
+// SalesSeries 返回日期范围内按粒度划分的坐标轴标签和销售额，两者一一对应
+// g 为 models.GranularityRange 时按日期范围的长度选择粒度
+func SalesSeries(ctx context.Context, repo models.StatisticsRepo, r DateRange, g models.Granularity) (labels []string, sales []float64) {
+	points, _ := repo.SalesSeries(ctx, r.From, r.To, g)
+	labels = make([]string, 0, len(points))
+	sales = make([]float64, 0, len(points))
+	for _, pt := range points {
+		labels = append(labels, pt.Label)
+		sales = append(sales, pt.Sales)
+	}
+	return labels, sales
+}
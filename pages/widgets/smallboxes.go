@@ -0,0 +1,52 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"概览"组件
+package widgets
+
+import (
+	"github.com/purpose168/GoAdmin-themes/adminlte/components/smallbox"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+func init() {
+	Register("smallboxes", SmallBoxes)
+}
+
+// SmallBoxes 概览组件
+// 一行四个小盒子，展示关键数值
+//
+// 返回值:
+//   - Widget: 默认宽度为 12 的组件
+func SmallBoxes(p Params) (Widget, error) {
+	components := tmpl.Default()
+	colComp := components.Col()
+
+	// 设置列的响应式宽度: 中等屏幕 3/12，小屏幕 6/12，超小屏幕全宽
+	var size = types.SizeMD(3).SM(6).XS(12)
+
+	/**************************
+	 * Small Box - 小盒子组件
+	/**************************/
+
+	// 创建4个小盒子组件
+	// SetColor: 设置盒子颜色
+	// SetIcon: 设置图标
+	// SetUrl: 设置点击跳转的URL
+	// SetTitle: 设置标题
+	// SetValue: 设置显示的数值
+	smallbox1 := smallbox.New().SetColor("blue").SetIcon("ion-ios-gear-outline").SetUrl("/").SetTitle("新用户").SetValue("345￥").GetContent()
+	smallbox2 := smallbox.New().SetColor("yellow").SetIcon("ion-ios-cart-outline").SetUrl("/").SetTitle("新用户").SetValue("80%").GetContent()
+	smallbox3 := smallbox.New().SetColor("red").SetIcon("fa-user").SetUrl("/").SetTitle("新用户").SetValue("645￥").GetContent()
+	smallbox4 := smallbox.New().SetColor("green").SetIcon("ion-ios-cart-outline").SetUrl("/").SetTitle("新用户").SetValue("889￥").GetContent()
+
+	// 创建4个列，每个列包含一个小盒子
+	col1 := colComp.SetSize(size).SetContent(smallbox1).GetContent()
+	col2 := colComp.SetSize(size).SetContent(smallbox2).GetContent()
+	col3 := colComp.SetSize(size).SetContent(smallbox3).GetContent()
+	col4 := colComp.SetSize(size).SetContent(smallbox4).GetContent()
+
+	// 创建第三行，包含4个小盒子
+	row3 := components.Row().SetContent(col1 + col2 + col3 + col4).GetContent()
+
+	return Widget{Title: "概览", Width: 12, Content: row3}, nil
+}
@@ -0,0 +1,100 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"系统资源"组件
+package widgets
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/chartjs"
+)
+
+func init() {
+	Register("system", System)
+	Defer("system", "系统资源", 6)
+}
+
+// SystemHours 系统资源组件默认显示的小时数
+const SystemHours = 24
+
+// System 系统资源组件
+// 折线图显示最近若干小时 CPU、内存使用率和协程数的每小时平均值，数据由 models.StartSystemStatsCollector 采集
+// 不跟随仪表板的日期范围，系统资源只关心最近的运行状态
+//
+// 参数:
+//   - p: 组件参数
+//
+// 组件参数（p.Options）:
+//   - hours: 显示最近 N 小时，默认为 SystemHours
+//
+// 返回值:
+//   - Widget: 默认宽度为 6 的组件
+func System(p Params) (Widget, error) {
+	components := tmpl.Default()
+
+	hours := SystemHours
+	if s := p.Options["hours"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return Widget{}, fmt.Errorf("系统资源的 hours 参数无效: %s", s)
+		}
+		hours = n
+	}
+
+	now := time.Now()
+	points := p.Repos.Statistics.SystemSeries(p.Context, now.Add(-time.Duration(hours-1)*time.Hour), now)
+
+	var body template.HTML
+	if len(points) == 0 {
+		body = `<p class="text-center text-muted">暂无采样数据，采集任务启动后每分钟写入一次</p>`
+	} else {
+		labels := make([]string, 0, len(points))
+		cpu := make([]float64, 0, len(points))
+		memory := make([]float64, 0, len(points))
+		goroutines := make([]float64, 0, len(points))
+		for _, pt := range points {
+			labels = append(labels, pt.Label)
+			cpu = append(cpu, pt.CPU)
+			memory = append(memory, pt.Memory)
+			goroutines = append(goroutines, pt.Goroutines)
+		}
+
+		// 使用率和协程数共用一个坐标轴，协程数较大时使用率的折线会被压扁，可以点击图例隐藏协程数
+		body = chartjs.Line().
+			SetID("systemchart").
+			SetHeight(180).
+			SetLabels(labels).
+			AddDataSet("CPU (%)").
+			DSData(cpu).
+			DSFill(false).
+			DSBorderColor("rgba(0,192,239,1)").
+			DSLineTension(0.1).
+			AddDataSet("内存 (%)").
+			DSData(memory).
+			DSFill(false).
+			DSBorderColor("rgba(243,156,18,1)").
+			DSLineTension(0.1).
+			AddDataSet("协程数").
+			DSData(goroutines).
+			DSFill(false).
+			DSBorderColor("rgba(0,166,90,1)").
+			DSLineTension(0.1).
+			GetContent()
+	}
+
+	// 底部显示最近一小时的平均值
+	latest := p.Repos.Statistics.LatestSystem(p.Context)
+	footer := template.HTML(fmt.Sprintf(`<p class="text-center">CPU <b>%d%%</b> · 内存 <b>%d%%</b> · 协程 <b>%d</b></p>`,
+		latest.CPU, latest.Memory, latest.Goroutines))
+
+	box := components.Box().WithHeadBorder().
+		SetHeader(template.HTML(fmt.Sprintf("系统资源（最近 %d 小时）", hours))).
+		SetBody(body).
+		SetFooter(footer).
+		GetContent()
+
+	return Widget{Title: "系统资源", Width: 6, Content: box}, nil
+}
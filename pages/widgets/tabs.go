@@ -0,0 +1,102 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"标签页"组件
+package widgets
+
+import (
+	"html/template"
+
+	tmpl "github.com/purpose168/GoAdmin/template"
+)
+
+func init() {
+	Register("tabs", Tabs)
+}
+
+// Tabs 标签页组件
+// 包含三个示例标签页，以及一个打开示例弹窗的按钮和弹窗本身
+//
+// 返回值:
+//   - Widget: 默认宽度为 8 的组件
+func Tabs(p Params) (Widget, error) {
+	components := tmpl.Default()
+
+	/**************************
+	 * Tabs - 标签页组件
+	/**************************/
+
+	// 创建标签页组件
+	// SetData: 设置标签页数据，每个标签页包含标题和内容
+	tabs := components.Tabs().SetData([]map[string]template.HTML{
+		{
+			"title": "标签页1",
+			"content": template.HTML(`<b>如何使用:</b>
+
+                <p>与原始的Bootstrap标签页完全相同，除了您应该使用
+                  自定义包装器 <code>.nav-tabs-custom</code> 来实现这种样式。</p>
+                一种美妙的宁静占据了我的整个灵魂，
+                就像这些我全心全意享受的春天的甜美早晨。
+                我独自一人，在这个地方感受到存在的魅力，
+                这个地方是为像我这样的灵魂的幸福而创造的。我很快乐，
+                我亲爱的朋友，如此沉浸在单纯的宁静存在的精致感觉中，
+                以至于我忽视了我的天赋。在目前这一刻，我无法画出一笔
+                ；然而我觉得我从未像现在这样成为一个伟大的艺术家。`),
+		}, {
+			"title": "标签页2",
+			"content": template.HTML(`
+                欧洲语言属于同一个家族。它们各自的存在是一个神话。
+                对于科学、音乐、体育等，欧洲使用相同的词汇。这些语言仅在
+                语法、发音和最常见的词汇上有所不同。每个人都意识到为什么
+                一种新的通用语言是可取的：人们可以拒绝支付昂贵的翻译费用。为了
+                实现这一点，需要统一的语法、发音和更常见的
+                词汇。如果几种语言融合，结果语言的语法将比
+                个别语言的语法更简单和规则。
+              `),
+		}, {
+			"title": "标签页3",
+			"content": template.HTML(`
+                Lorem Ipsum 只是印刷和排版行业的虚拟文本。
+                自1500年代以来，Lorem Ipsum 一直是行业的标准虚拟文本，
+                当时一位不知名的印刷商拿了一个字样盘并将其打乱以制作一个字样样本书。
+                它不仅存活了五个世纪，还跨越了电子排版的飞跃，
+                基本上保持不变。它在1960年代随着包含Lorem Ipsum段落的Letraset
+                表的发布而流行起来，最近又随着桌面出版软件
+                如Aldus PageMaker（包括Lorem Ipsum版本）而流行。
+                <br><br>
+                <b>技术说明：</b>
+                <p>Lorem Ipsum 是一种虚拟文本，用于在印刷和排版行业中填充空间。
+                它不包含任何实际含义，只是用来展示排版效果。</p>
+              `),
+		},
+	}).GetContent()
+
+	// 创建弹窗触发按钮
+	// data-toggle="modal": 设置为模态框触发器
+	// data-target: 指定要打开的模态框ID
+	buttonTest := `<button type="button" class="btn btn-primary" data-toggle="modal" data-target="#exampleModal" data-whatever="@mdo">为 @mdo 打开弹窗</button>`
+
+	// 创建弹窗表单内容
+	// 包含收件人和消息输入框
+	popupForm := `<form>
+          <div class="form-group">
+            <label for="recipient-name" class="col-form-label">收件人:</label>
+            <input type="text" class="form-control" id="recipient-name">
+          </div>
+          <div class="form-group">
+            <label for="message-text" class="col-form-label">消息:</label>
+            <textarea class="form-control" id="message-text"></textarea>
+          </div>
+        </form>`
+
+	// 创建弹窗组件
+	// SetID: 设置弹窗ID，用于在按钮中引用
+	// SetFooter: 设置底部按钮文本
+	// SetTitle: 设置弹窗标题
+	// SetBody: 设置弹窗内容（表单）
+	popup := components.Popup().SetID("exampleModal").
+		SetFooter("保存更改").
+		SetTitle("这是一个弹窗").
+		SetBody(template.HTML(popupForm)).
+		GetContent()
+
+	return Widget{Title: "标签页", Width: 8, Content: tabs + template.HTML(buttonTest) + popup}, nil
+}
@@ -0,0 +1,92 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"热销商品排行"组件
+package widgets
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	tmpl "github.com/purpose168/GoAdmin/template"
+)
+
+func init() {
+	Register("top_products", TopProducts)
+}
+
+// TopProductsLimit 热销商品排行默认显示的商品数
+const TopProductsLimit = 5
+
+// topProductColors 排名对应的进度条颜色，超出部分使用最后一个颜色
+var topProductColors = []string{"red", "yellow", "aqua", "green", "light-blue"}
+
+// TopProducts 热销商品排行组件
+// 按销售额列出销售最好的商品，并以进度条显示每个商品占总销售额的百分比
+//
+// 参数:
+//   - p: 组件参数，默认统计仪表板当前选择的日期范围
+//
+// 组件参数（p.Options）:
+//   - limit: 显示的商品数，默认为 TopProductsLimit
+//   - days: 统计最近 N 天（包含今天），设置后不再跟随仪表板的日期范围
+//
+// 返回值:
+//   - Widget: 默认宽度为 4 的组件
+func TopProducts(p Params) (Widget, error) {
+	components := tmpl.Default()
+
+	limit := TopProductsLimit
+	if s := p.Options["limit"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return Widget{}, fmt.Errorf("热销商品排行的 limit 参数无效: %s", s)
+		}
+		limit = n
+	}
+
+	dr := p.Range
+	if s := p.Options["days"]; s != "" {
+		days, err := strconv.Atoi(s)
+		if err != nil || days <= 0 {
+			return Widget{}, fmt.Errorf("热销商品排行的 days 参数无效: %s", s)
+		}
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		dr = DateRange{From: today.AddDate(0, 0, 1-days), To: today.Add(24*time.Hour - time.Second)}
+	}
+
+	products := p.Repos.Orders.TopProducts(p.Context, dr.From, dr.To, limit)
+
+	var body template.HTML
+	if len(products) == 0 {
+		body = `<p class="text-center text-muted">所选日期范围内暂无订单</p>`
+	} else {
+		// 每个商品一行：排名、名称、销售额，下方进度条的长度为占总销售额的百分比
+		for i, pr := range products {
+			color := topProductColors[len(topProductColors)-1]
+			if i < len(topProductColors) {
+				color = topProductColors[i]
+			}
+			body += template.HTML(fmt.Sprintf(`<div class="progress-group">
+    <span class="progress-text"><span class="badge bg-%s">%d</span> %s</span>
+    <span class="progress-number"><b>%.2f</b> (%.1f%%)</span>
+    <div class="progress sm">
+        <div class="progress-bar progress-bar-%s" style="width: %.1f%%"></div>
+    </div>
+</div>`, color, i+1, template.HTMLEscapeString(pr.Product), pr.Revenue, pr.Share, color, pr.Share))
+		}
+	}
+
+	// 创建成功主题的盒子组件包裹排行列表
+	// SetTheme("success"): 设置主题为成功样式（绿色）
+	// SetFooter: 跳转到按同一时间范围筛选的订单列表
+	boxSuccess := components.Box().SetTheme("success").WithHeadBorder().
+		SetHeader(template.HTML("热销商品（" + dr.String() + "）")).
+		SetBody(body).
+		SetFooter(template.HTML(fmt.Sprintf(`<p class="text-center"><a href="%s" class="uppercase">查看所有订单</a></p>`,
+			template.HTMLEscapeString(dr.TableURL("orders", "created_at"))))).
+		GetContent()
+
+	return Widget{Title: "热销商品", Width: 4, Content: boxSuccess}, nil
+}
@@ -0,0 +1,118 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件定义组件注册表，新组件通过 Register 注册后即可按名称组合到仪表板中，
+// 无需修改 DashboardPage
+//
+// 使用示例:
+//
+//	package mywidgets
+//
+//	func init() {
+//	    widgets.Register("weather", func(p widgets.Params) (widgets.Widget, error) {
+//	        return widgets.Widget{Title: "天气", Width: 4, Content: "<p>晴</p>"}, nil
+//	    })
+//	}
+package widgets
+
+import (
+	stdctx "context"
+	"html/template"
+	"sort"
+	"sync"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+)
+
+// Widget 组件的渲染结果
+type Widget struct {
+	// Title 组件名称，显示在组件工具栏和隐藏列表中
+	Title string
+
+	// Width 默认宽度（1-12），对应 Bootstrap 栅格的列数
+	Width int
+
+	// Content 组件的HTML内容
+	Content template.HTML
+}
+
+// Params 渲染组件时传入的参数
+type Params struct {
+	// Ctx 当前请求的上下文
+	Ctx *context.Context
+
+	// Context 查询数据库时使用的上下文，组件加载超时或请求取消时随之取消
+	Context stdctx.Context
+
+	// Range 仪表板当前选择的日期范围，依赖统计数据的组件应按该范围聚合
+	Range DateRange
+
+	// Granularity 环比计算的周期粒度，由查询参数 granularity 指定
+	Granularity models.Granularity
+
+	// Options 仪表板配置中为该组件设置的参数，没有配置时为 nil
+	Options map[string]string
+
+	// Repos 读取数据使用的仓储，组件不应直接调用 models 包的查询函数
+	Repos models.Repositories
+}
+
+// WidgetFunc 组件生成函数
+// 同一页面的组件并发生成，函数中不能修改 Params 和包级变量；
+// 返回错误、panic 或超时时只在该组件的位置显示错误提示
+type WidgetFunc func(p Params) (Widget, error)
+
+var (
+	// mu 保护 registry 的并发读写
+	mu sync.RWMutex
+
+	// registry 组件名称到生成函数的映射
+	registry = make(map[string]WidgetFunc)
+)
+
+// Register 注册一个仪表板组件
+//
+// 参数:
+//   - name: 组件名称，在仪表板组件列表和布局中引用该名称
+//   - fn: 组件生成函数
+//
+// 注意事项:
+//   - 通常在 init 函数中调用
+//   - fn 为 nil 或名称重复注册时会 panic，与 database/sql 注册驱动的行为一致
+func Register(name string, fn WidgetFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if fn == nil {
+		panic("widgets: Register widget func is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("widgets: Register called twice for widget " + name)
+	}
+	registry[name] = fn
+}
+
+// Get 根据名称获取已注册的组件生成函数
+//
+// 返回值:
+//   - WidgetFunc: 组件生成函数
+//   - bool: 组件是否已注册
+func Get(name string) (WidgetFunc, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	fn, ok := registry[name]
+	return fn, ok
+}
+
+// Names 返回所有已注册组件的名称，按字母顺序排列
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,97 @@
+// Package widgets 提供仪表板组件（Widget）的注册和实现
+// 本文件实现"会员地理分布"世界地图组件
+package widgets
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	tmpl "github.com/purpose168/GoAdmin/template"
+)
+
+func init() {
+	Register("worldmap", WorldMap)
+	Defer("worldmap", "会员地理分布", 12)
+}
+
+// worldMapItem ECharts 地图系列的数据项
+type worldMapItem struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// WorldMap 会员地理分布组件
+// 在世界地图上按颜色深浅展示日期范围内各国家的新会员数，数据来自 user_locations 表
+//
+// 参数:
+//   - p: 组件参数，使用其中的 Range 作为统计区间
+//
+// 返回值:
+//   - Widget: 默认宽度为 12 的组件，单独占一行
+func WorldMap(p Params) (Widget, error) {
+	components := tmpl.Default()
+
+	counts := p.Repos.Members.ByCountry(p.Context, p.Range.From, p.Range.To)
+
+	items := make([]worldMapItem, 0, len(counts))
+	maxMembers := float64(1)
+	for _, c := range counts {
+		items = append(items, worldMapItem{Name: c.Country, Value: c.Members})
+		if c.Members > maxMembers {
+			maxMembers = c.Members
+		}
+	}
+
+	// json.Marshal 会转义 <、>、&，可以安全地嵌入 <script> 中
+	data, err := json.Marshal(items)
+	if err != nil {
+		return Widget{}, err
+	}
+
+	body := template.HTML(`<p class="text-center text-muted">所选日期范围内暂无会员地理位置数据</p>`)
+	if len(counts) > 0 {
+		body = template.HTML(fmt.Sprintf(`<div id="worldMap" style="height:400px"></div>
+<script>
+(function () {
+    var data = %s;
+    function render() {
+        var chart = echarts.init(document.getElementById('worldMap'));
+        chart.setOption({
+            tooltip: {
+                trigger: 'item',
+                formatter: function (p) {
+                    return p.name + ': ' + (isNaN(p.value) ? 0 : p.value);
+                }
+            },
+            visualMap: {
+                min: 0,
+                max: %.0f,
+                text: ['多', '少'],
+                calculable: true,
+                inRange: {color: ['#d2d6de', '#3c8dbc']}
+            },
+            series: [{
+                name: '新会员',
+                type: 'map',
+                map: 'world',
+                roam: true,
+                data: data
+            }]
+        });
+        $(window).on('resize', function () {
+            chart.resize();
+        });
+    }
+    %s
+})();
+</script>`, data, maxMembers, loadScripts("render", EChartsURL, EChartsWorldURL)))
+	}
+
+	box := components.Box().WithHeadBorder().
+		SetHeader(template.HTML("会员地理分布（" + p.Range.String() + "）")).
+		SetBody(body).
+		GetContent()
+
+	return Widget{Title: "会员地理分布", Width: 12, Content: box}, nil
+}
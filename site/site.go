@@ -0,0 +1,124 @@
+// Package site 实现后台前缀之外的公开站点
+// 公开站点与管理后台使用同一个程序和数据库，展示后台中维护的文章，页面使用 Gin 的模板渲染，不需要登录
+package site
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin-example/tables"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gorm.io/gorm"
+)
+
+// pageSize 首页每页显示的文章数
+const pageSize = 10
+
+// Register 注册公开站点的路由和模板
+//
+// 参数:
+//   - r: Gin 路由器
+//   - templates: 模板文件的匹配模式，通常为 ./html/site/*.tmpl
+//
+// 路由:
+//   - GET /: 首页，按发布日期从新到旧列出文章，查询参数 page 为页码
+//   - GET /posts/:id: 文章详情
+//
+// 使用示例:
+//
+//	site.Register(r, "./html/site/*.tmpl")
+//
+// 注意事项:
+//   - 使用 Gin 的 LoadHTMLGlob 加载模板，GoAdmin 的页面不使用 Gin 的模板，两者互不影响
+//   - 文章正文的格式取决于后台使用的编辑器（见 tables.PostsEditor），Markdown 原文在这里渲染为 HTML
+func Register(r *gin.Engine, templates string) {
+	r.SetFuncMap(template.FuncMap{
+		"postContent": postContent,
+		"authorName":  authorName,
+	})
+	r.LoadHTMLGlob(templates)
+
+	r.GET("/", index)
+	r.GET("/posts/:id", showPost)
+}
+
+// index 首页
+func index(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	posts, total, err := models.ListPublicPosts(c.Request.Context(), page, pageSize)
+	if err != nil {
+		log.Printf("读取文章失败: %s\n", err)
+		c.HTML(http.StatusInternalServerError, "error.tmpl", pageData(gin.H{"Message": "读取文章失败，请稍后再试。"}))
+		return
+	}
+
+	pages := int((total + pageSize - 1) / pageSize)
+	c.HTML(http.StatusOK, "index.tmpl", pageData(gin.H{
+		"Posts":    posts,
+		"Page":     page,
+		"PrevPage": page - 1,
+		"NextPage": page + 1,
+		"HasPrev":  page > 1,
+		"HasNext":  page < pages,
+	}))
+}
+
+// showPost 文章详情
+func showPost(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.tmpl", pageData(gin.H{"Message": "文章不存在。"}))
+		return
+	}
+	post, err := models.FindPublicPost(c.Request.Context(), uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.HTML(http.StatusNotFound, "error.tmpl", pageData(gin.H{"Message": "文章不存在或已被删除。"}))
+		return
+	case err != nil:
+		log.Printf("读取文章 %d 失败: %s\n", id, err)
+		c.HTML(http.StatusInternalServerError, "error.tmpl", pageData(gin.H{"Message": "读取文章失败，请稍后再试。"}))
+		return
+	}
+	c.HTML(http.StatusOK, "post.tmpl", pageData(gin.H{"Title": post.Title, "Post": post}))
+}
+
+// pageData 在模板数据中加入所有页面共用的站点标题和后台地址，页面标题 Title 为空时只显示站点标题
+func pageData(data gin.H) gin.H {
+	data["SiteTitle"] = config.GetTitle()
+	data["AdminURL"] = config.Url("/")
+	return data
+}
+
+// postContent 按后台使用的编辑器把文章正文转换为 HTML
+// 富文本编辑器保存的正文本身就是 HTML，但可能由任何有写入权限的管理员或 API 密钥保存，
+// 公开站点与后台同源，按白名单过滤后输出（见 tables.SanitizeHTML）
+func postContent(content string) template.HTML {
+	if tables.PostsEditor == tables.EditorMarkdown {
+		return tables.RenderMarkdown(content)
+	}
+	return tables.SanitizeHTML(content)
+}
+
+// authorName 作者的姓名，作者不存在时返回“佚名”
+func authorName(p models.PublicPost) string {
+	name := p.AuthorFirstName
+	if p.AuthorLastName != "" {
+		if name != "" {
+			name += " "
+		}
+		name += p.AuthorLastName
+	}
+	if name == "" {
+		return "佚名"
+	}
+	return name
+}
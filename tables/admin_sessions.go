@@ -0,0 +1,132 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现登录会话（admin_sessions）的表格：列出管理员在各个浏览器中的登录，可以把不再使用或可疑的会话踢出
+package tables
+
+import (
+	"errors"
+	"html"
+	"html/template"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// init 在 Generators 中注册 admin_sessions 前缀
+// 访问路径: /admin/info/admin_sessions
+// 功能: 查看登录会话的 IP、浏览器和最近活动时间，踢出会话
+func init() {
+	Register("admin_sessions", GetAdminSessionsTable)
+}
+
+// GetAdminSessionsTable 获取登录会话表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 只列出 GoAdmin 中仍然有效的会话（goadmin_session 中存在），按最近活动时间倒序排列
+//   - 会话信息由 middleware.SessionTracker 记录，登录后的第一个请求出现在列表中，最近活动时间每分钟最多更新一次
+//   - 超级管理员可以看到和踢出所有管理员的会话，其他管理员只能看到和踢出自己的会话
+//   - "踢出"删除 GoAdmin 的会话，该浏览器下一次请求时回到登录页面；当前会话需要通过退出登录结束
+func GetAdminSessionsTable(ctx *context.Context) (adminSessionsTable table.Table) {
+
+	adminSessionsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetEditable(false).SetDeletable(false))
+
+	user := auth.Auth(ctx)
+	var currentID string
+	if cookie, err := ctx.Request.Cookie(auth.DefaultCookieKey); err == nil && cookie.Value != "" {
+		currentID = strconv.FormatUint(uint64(models.AdminSessionID(ctx.Request.Context(), cookie.Value)), 10)
+	}
+
+	info := adminSessionsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("last_active_at").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Value == currentID {
+				return template.HTML(html.EscapeString(value.Value) + ` <span class="label label-success">当前会话</span>`)
+			}
+			return value.Value
+		})
+
+	info.AddField("管理员", "name", db.Varchar).FieldJoin(types.Join{
+		Field:     "user_id",
+		JoinField: "id",
+		Table:     "goadmin_users",
+	}).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("IP", "ip", db.Varchar).FieldFilterable()
+
+	info.AddField("浏览器", "user_agent", db.Varchar).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return template.HTML(`<span title="` + html.EscapeString(value.Value) + `">` +
+				html.EscapeString(models.BrowserFamily(value.Value)) + `</span>`)
+		})
+
+	info.AddField("开始时间", "created_at", db.Datetime).FieldSortable()
+
+	info.AddField("最近活动", "last_active_at", db.Datetime).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	// 退出登录、过期和被踢出的会话已经从 goadmin_session 中删除
+	// 管理员的条件同样写在 WhereRaw 中，地址中的筛选参数（如 ?user_id=1）不能覆盖它
+	if user.IsSuperAdmin() {
+		info.WhereRaw("admin_sessions.sid IN (SELECT sid FROM goadmin_session)")
+	} else {
+		info.WhereRaw("admin_sessions.sid IN (SELECT sid FROM goadmin_session) AND admin_sessions.user_id = ?", user.Id)
+		guardRowAccess(ctx, adminSessionsTable, func(ids []string) error {
+			s, err := models.FindAdminSession(ctx.Request.Context(), ids[0])
+			if err != nil {
+				return err
+			}
+			if s.UserID != user.Id {
+				return errors.New("只能查看自己的会话")
+			}
+			return nil
+		})
+	}
+
+	info.AddActionButton(ctx, "踢出", action.Ajax("/admin/admin_sessions/kick",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id := ctx.FormValue("id")
+			s, err := models.FindAdminSession(ctx.Request.Context(), id)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, "会话已经结束", ""
+			}
+			if err != nil {
+				return false, "读取会话失败: " + err.Error(), ""
+			}
+			if current := auth.Auth(ctx); !current.IsSuperAdmin() && s.UserID != current.Id {
+				return false, "只能踢出自己的会话", ""
+			}
+			if cookie, err := ctx.Request.Cookie(auth.DefaultCookieKey); err == nil && cookie.Value == s.Sid {
+				return false, "不能踢出当前会话，请使用退出登录", ""
+			}
+
+			ids := []string{id}
+			before := snapshot(ctx.Request.Context(), "admin_sessions", "id", ids)
+			if err := models.KickAdminSession(ctx.Request.Context(), s.Sid); err != nil {
+				return false, "踢出失败: " + err.Error(), ""
+			}
+			auditChange(ctx, "admin_sessions", "id", models.AuditDelete, ids, before)
+			return true, "已踢出，该浏览器需要重新登录", ""
+		}))
+
+	info.SetTable("admin_sessions").SetTitle("登录会话").SetDescription("管理员在各个浏览器中的登录")
+
+	return
+}
@@ -0,0 +1,55 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+)
+
+func TestAdminSessionsOfOtherAdmin(t *testing.T) {
+	conn := openTestDB(t)
+	const operator = 2
+	for _, s := range []struct {
+		sid    string
+		userID int
+		ip     string
+	}{{"test-admin", 1, "10.0.0.1"}, {"test-operator", operator, "10.0.0.2"}} {
+		if _, err := conn.Exec(`INSERT INTO goadmin_session (sid, "values") VALUES (?, ?)`, s.sid, "{}"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(`INSERT INTO admin_sessions (sid, user_id, ip, last_active_at, created_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, s.sid, s.userID, s.ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		userID int64
+		query  string
+		rows   int
+	}{
+		{"普通管理员只看到自己的会话", operator, "", 1},
+		{"筛选参数不能覆盖管理员的条件", operator, "?user_id=1", 0},
+		{"超级管理员看到所有会话", 1, "", 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := testContext(t, conn, c.userID, "/admin/info/admin_sessions"+c.query)
+			tb := Generators["admin_sessions"](ctx)
+			info := tb.GetInfo()
+			data, err := tb.GetData(ctx, parameter.GetParam(ctx.Request.URL, info.DefaultPageSize, info.SortField, info.GetSort()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.InfoList) != c.rows {
+				t.Fatalf("列表有 %d 行，want %d", len(data.InfoList), c.rows)
+			}
+			for _, row := range data.InfoList {
+				if c.userID != 1 && row["ip"].Value != "10.0.0.2" {
+					t.Fatalf("列表中有其他管理员的会话: %s", row["ip"].Value)
+				}
+			}
+		})
+	}
+}
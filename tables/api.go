@@ -0,0 +1,490 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表格的 JSON 接口：以 API 密钥或登录接口签发的访问令牌（见 api_token.go）代替登录会话，
+// 列表、读取、新增、修改和删除 Generators 中的表格，与后台使用同一个表格模型，
+// 表单校验、按创建人的限制和审计日志都与后台的操作相同
+package tables
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	adminModels "github.com/purpose168/GoAdmin/plugins/admin/models"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// JSON 接口的地址，:__prefix 为表格前缀，:__id 为记录的主键
+const (
+	// APITableURL GET 列表，POST 新增
+	APITableURL = "/api/v1/tables/:__prefix"
+
+	// APIRecordURL GET 读取，PUT 修改，DELETE 删除
+	APIRecordURL = "/api/v1/tables/:__prefix/:__id"
+)
+
+// apiIDKey 路由中记录主键的参数名，适配器把路由参数转换为同名的查询参数
+const apiIDKey = "__id"
+
+// apiMaxPageSize 列表接口每页最多返回的条数
+const apiMaxPageSize = 100
+
+// apiExcludedTables 不能通过接口访问的表格
+// API 密钥只能在后台签发和吊销，否则泄露的密钥可以为自己签发新的密钥；
+// 登录会话只对后台登录的管理员有意义，接口使用的令牌不产生会话
+var apiExcludedTables = map[string]bool{
+	"api_keys":       true,
+	"admin_sessions": true,
+}
+
+// apiTableAllowed 判断表格是否可以通过接口访问
+func apiTableAllowed(prefix string) bool {
+	_, ok := Generators[prefix]
+	return ok && !apiExcludedTables[prefix]
+}
+
+// apiTableOptions 可以通过接口访问的表格，API 密钥表单的"表格"选项
+func apiTableOptions() types.FieldOptions {
+	prefixes := make([]string, 0, len(Generators))
+	for p := range Generators {
+		if apiTableAllowed(p) {
+			prefixes = append(prefixes, p)
+		}
+	}
+	sort.Strings(prefixes)
+	options := make(types.FieldOptions, 0, len(prefixes))
+	for _, p := range prefixes {
+		options = append(options, types.FieldOption{Value: p, Text: p})
+	}
+	return options
+}
+
+// APIAuth 返回 JSON 接口鉴权的中间件，包装表格接口的处理器
+//
+// 参数:
+//   - conn: GoAdmin 的数据库连接，用于读取管理员的角色和权限
+//
+// 功能说明:
+//  1. 从 Authorization: Bearer <令牌> 或 X-API-Key 请求头中读取令牌，gak_ 开头的为 API 密钥，其他的为登录接口签发的访问令牌
+//  2. API 密钥验证是否有效（见 models.AuthenticateAPIKey）：GET 请求需要 read 权限范围，其他请求需要 write，
+//     路由中有表格前缀时检查密钥是否允许访问该表格；以签发人作为当前管理员
+//  3. 访问令牌校验签名和有效期（见 models.ParseAPIToken），表格需要在 api.tables 配置中；以登录的管理员作为当前管理员
+//  4. 之后的处理器通过 auth.Auth 取得当前管理员，按该管理员的角色和权限检查能否访问表格
+//
+// 使用示例:
+//
+//	apiAuth := tables.APIAuth(eng.DefaultConnection())
+//	eng.Data("GET", tables.APITableURL, apiAuth(tables.APIList), true)
+//
+// 注意事项:
+//   - 注册时应跳过 GoAdmin 的登录检查（noAuth 为 true），只按令牌鉴权
+//   - 管理员被删除后令牌随之失效；管理员的权限变化立即生效
+func APIAuth(conn db.Connection) func(context.Handler) context.Handler {
+	return func(h context.Handler) context.Handler {
+		return func(ctx *context.Context) {
+			token := apiKeyFromRequest(ctx)
+			if token == "" {
+				apiJSON(ctx, http.StatusUnauthorized, "缺少 API 密钥或访问令牌", nil)
+				return
+			}
+			var (
+				userID int64
+				ok     bool
+			)
+			if strings.HasPrefix(token, models.APIKeyTokenPrefix) {
+				userID, ok = apiKeyUser(ctx, token)
+			} else {
+				userID, ok = apiTokenUser(ctx, token)
+			}
+			if !ok {
+				return
+			}
+
+			user := adminModels.User().SetConn(conn).Find(userID)
+			if user.IsEmpty() {
+				apiJSON(ctx, http.StatusUnauthorized, "管理员已被删除", nil)
+				return
+			}
+			ctx.SetUserValue("user", user.WithRoles().WithPermissions())
+			h(ctx)
+		}
+	}
+}
+
+// apiKeyUser 验证 API 密钥的有效期、权限范围和允许的表格，返回签发人的 ID；不满足时写入响应并返回 false
+func apiKeyUser(ctx *context.Context, token string) (int64, bool) {
+	key, err := models.AuthenticateAPIKey(ctx.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAPIKey) {
+			apiJSON(ctx, http.StatusUnauthorized, err.Error(), nil)
+			return 0, false
+		}
+		apiJSON(ctx, http.StatusInternalServerError, "验证 API 密钥失败", nil)
+		return 0, false
+	}
+
+	scope := models.APIScopeWrite
+	if ctx.Method() == http.MethodGet || ctx.Method() == http.MethodHead {
+		scope = models.APIScopeRead
+	}
+	if !key.HasScope(scope) {
+		apiJSON(ctx, http.StatusForbidden, fmt.Sprintf("API 密钥没有 %s 权限范围", scope), nil)
+		return 0, false
+	}
+	if prefix := ctx.Query(parameter.Prefix); prefix != "" && !key.AllowsTable(prefix) {
+		apiJSON(ctx, http.StatusForbidden, fmt.Sprintf("API 密钥不能访问表格 %s", prefix), nil)
+		return 0, false
+	}
+	return key.CreatedBy, true
+}
+
+// apiTokenUser 校验访问令牌和 api.tables 配置，返回登录的管理员 ID；不满足时写入响应并返回 false
+// 没有配置签名密钥时不接受访问令牌
+func apiTokenUser(ctx *context.Context, token string) (int64, bool) {
+	if !APILoginEnabled() {
+		apiJSON(ctx, http.StatusUnauthorized, models.ErrInvalidAPIKey.Error(), nil)
+		return 0, false
+	}
+	id, err := models.ParseAPIToken([]byte(apiCfg.JWTSecret), token, time.Now())
+	if err != nil {
+		apiJSON(ctx, http.StatusUnauthorized, err.Error(), nil)
+		return 0, false
+	}
+	if prefix := ctx.Query(parameter.Prefix); prefix != "" && !apiTokenAllowsTable(prefix) {
+		apiJSON(ctx, http.StatusForbidden, fmt.Sprintf("表格 %s 不能通过登录令牌访问", prefix), nil)
+		return 0, false
+	}
+	return id, true
+}
+
+// apiKeyFromRequest 从请求头中读取 API 密钥或访问令牌，Authorization 优先
+func apiKeyFromRequest(ctx *context.Context) string {
+	if h := ctx.Headers("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return strings.TrimSpace(ctx.Headers("X-API-Key"))
+}
+
+// APIList 返回表格的一页数据
+//
+// 路由: GET /api/v1/tables/{表格前缀}?__page=1&__pageSize=20&__sort=id&__sort_type=desc&{筛选条件}
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"page": 1, "page_size": 20, "has_more": true, "items": [{"id": "1", ...}]}}
+//
+// 注意事项:
+//   - 筛选条件与列表页地址中的查询参数相同；每页最多 apiMaxPageSize 条
+//   - 返回列表中显示的列（不包括 FieldHide 隐藏的列），值为数据库中的原始值，统一为字符串
+//   - 非超级管理员只能看到自己创建的记录等限制与列表页相同
+func APIList(ctx *context.Context) {
+	t, ok := apiTable(ctx, "/info/", http.MethodGet, "查看")
+	if !ok {
+		return
+	}
+	info := t.GetInfo()
+	params := parameter.GetParam(ctx.Request.URL, info.DefaultPageSize, info.SortField, info.GetSort())
+	if params.PageSizeInt <= 0 || params.PageSizeInt > apiMaxPageSize {
+		params.PageSize, params.PageSizeInt = strconv.Itoa(apiMaxPageSize), apiMaxPageSize
+	}
+	if params.PageInt <= 0 {
+		params.Page, params.PageInt = "1", 1
+	}
+	pageSize := params.PageSizeInt
+
+	// 多读一条判断是否还有下一页，不需要额外的 count 查询
+	params.PageSizeInt++
+	params.PageSize = strconv.Itoa(params.PageSizeInt)
+	data, err := t.GetData(ctx, params.WithIsAll(false))
+	if err != nil {
+		apiJSON(ctx, http.StatusInternalServerError, "读取数据失败: "+err.Error(), nil)
+		return
+	}
+
+	items := apiRows(data)
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+	apiJSON(ctx, http.StatusOK, "ok", map[string]interface{}{
+		"page":      params.PageInt,
+		"page_size": pageSize,
+		"has_more":  hasMore,
+		"items":     items,
+	})
+}
+
+// APIGet 返回一条记录，字段与列表接口相同
+//
+// 路由: GET /api/v1/tables/{表格前缀}/{主键}
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"id": "1", ...}}
+func APIGet(ctx *context.Context) {
+	t, ok := apiTable(ctx, "/info/", http.MethodGet, "查看")
+	if !ok {
+		return
+	}
+	row, ok := apiFindRow(ctx, t)
+	if !ok {
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", row)
+}
+
+// APICreate 新增一条记录，与后台的新增表单使用同一个表单：校验、默认值的处理和审计日志都相同
+//
+// 路由: POST /api/v1/tables/{表格前缀}
+//
+// 请求格式:
+//
+//	{"name": "张三", "tags": ["1", "2"], "status": 1}
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"id": "10"}}
+//
+// 注意事项:
+//   - 只能提交新增表单中的字段；多选字段提交数组，其他值按表单提交的文字处理
+//   - 校验失败时返回 422，data.errors 中为每个字段的错误；不支持上传文件
+func APICreate(ctx *context.Context) {
+	t, ok := apiTable(ctx, "/new/", http.MethodPost, "新增")
+	if !ok {
+		return
+	}
+	if !t.GetCanAdd() {
+		apiJSON(ctx, http.StatusForbidden, "该表格不允许新增", nil)
+		return
+	}
+	f := t.GetActualNewForm()
+	values, ok := apiFormValues(ctx, f, true)
+	if !ok {
+		return
+	}
+	if err := t.InsertData(ctx, values); err != nil {
+		apiSaveError(ctx, err)
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", map[string]interface{}{"id": apiInsertedID(ctx, values, t.GetPrimaryKey().Name)})
+}
+
+// APIUpdate 修改一条记录，与后台的编辑表单使用同一个表单
+//
+// 路由: PUT /api/v1/tables/{表格前缀}/{主键}
+//
+// 请求格式与 APICreate 相同，返回 {"code": 200, "msg": "ok"}
+//
+// 注意事项:
+//   - 没有提交的字段保持不变，但多选字段（如文章的标签）与编辑表单一样，没有提交时会被清空
+//   - 编辑表单中不能修改的字段不能提交
+func APIUpdate(ctx *context.Context) {
+	t, ok := apiTable(ctx, "/edit/", http.MethodPost, "修改")
+	if !ok {
+		return
+	}
+	if !t.GetEditable() {
+		apiJSON(ctx, http.StatusForbidden, "该表格不允许修改", nil)
+		return
+	}
+	if _, ok := apiFindRow(ctx, t); !ok {
+		return
+	}
+	values, ok := apiFormValues(ctx, t.GetForm(), false)
+	if !ok {
+		return
+	}
+	values.Add(t.GetPrimaryKey().Name, ctx.Query(apiIDKey))
+	if err := t.UpdateData(ctx, values); err != nil {
+		apiSaveError(ctx, err)
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", nil)
+}
+
+// APIDelete 删除一条记录，软删除的表格与列表中的删除一样只做软删除
+//
+// 路由: DELETE /api/v1/tables/{表格前缀}/{主键}
+func APIDelete(ctx *context.Context) {
+	t, ok := apiTable(ctx, "/delete/", http.MethodPost, "删除")
+	if !ok {
+		return
+	}
+	if !t.GetDeletable() {
+		apiJSON(ctx, http.StatusForbidden, "该表格不允许删除", nil)
+		return
+	}
+	if _, ok := apiFindRow(ctx, t); !ok {
+		return
+	}
+	if err := t.DeleteData(ctx.Query(apiIDKey)); err != nil {
+		apiSaveError(ctx, err)
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", nil)
+}
+
+// apiTable 按路由中的前缀生成表格，并检查当前管理员有没有对应后台页面的权限
+// path 为后台页面的路径（如 /info/），与前缀拼接后按 method 检查；不满足时写入响应并返回 false
+func apiTable(ctx *context.Context, path, method, action string) (table.Table, bool) {
+	prefix := ctx.Query(parameter.Prefix)
+	if !apiTableAllowed(prefix) {
+		apiJSON(ctx, http.StatusNotFound, "表格不存在", nil)
+		return nil, false
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url(path+prefix), method, url.Values{}) {
+		apiJSON(ctx, http.StatusForbidden, "没有"+action+"该表格的权限", nil)
+		return nil, false
+	}
+	return Generators[prefix](ctx), true
+}
+
+// apiFindRow 按路由中的主键读取一条记录
+// 与列表使用同一个查询，按创建人的限制、软删除等列表中看不到的记录同样读取不到，返回 404
+func apiFindRow(ctx *context.Context, t table.Table) (map[string]string, bool) {
+	id := ctx.Query(apiIDKey)
+	if id == "" || strings.Contains(id, ",") {
+		apiJSON(ctx, http.StatusNotFound, "记录不存在", nil)
+		return nil, false
+	}
+	info := t.GetInfo()
+	q := url.Values{t.GetPrimaryKey().Name: {id}, parameter.PageSize: {"1"}}
+	params := parameter.GetParam(&url.URL{RawQuery: q.Encode()}, 1, info.SortField, info.GetSort())
+	data, err := t.GetData(ctx, params.WithIsAll(false))
+	if err != nil {
+		apiJSON(ctx, http.StatusInternalServerError, "读取数据失败: "+err.Error(), nil)
+		return nil, false
+	}
+	rows := apiRows(data)
+	if len(rows) == 0 {
+		apiJSON(ctx, http.StatusNotFound, "记录不存在", nil)
+		return nil, false
+	}
+	return rows[0], true
+}
+
+// apiRows 把列表数据转换为接口返回的行，键为字段名，值为原始值
+// 只包含列表中显示的字段，FieldHide 隐藏的字段不会查出；只用于显示、没有对应数据列的字段值为空
+func apiRows(data table.PanelInfo) []map[string]string {
+	rows := make([]map[string]string, len(data.InfoList))
+	for i, item := range data.InfoList {
+		row := make(map[string]string, len(data.Thead))
+		for _, c := range data.Thead {
+			if c.Hide {
+				continue
+			}
+			row[c.Field] = item[c.Field].Value
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// apiFormValues 把请求的 JSON 对象转换为表单提交的值
+// 只接受表单中的字段：新增时不能提交新增表单中隐藏的字段，修改时不能提交编辑表单中不能修改的字段；
+// 多选字段按浏览器提交的格式加上 [] 后缀。不满足时写入响应并返回 false
+func apiFormValues(ctx *context.Context, f *types.FormPanel, create bool) (form2.Values, bool) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&body); err != nil {
+		apiJSON(ctx, http.StatusBadRequest, "请求格式错误，请提交 JSON 对象", nil)
+		return nil, false
+	}
+
+	values := make(form2.Values, len(body))
+	for k, v := range body {
+		field := f.FieldList.FindByFieldName(k)
+		if field == nil || strings.HasPrefix(k, "__") ||
+			(create && field.NotAllowAdd) || (!create && (field.NotAllowEdit || !field.Editable)) {
+			apiJSON(ctx, http.StatusBadRequest, fmt.Sprintf("字段 %s 不存在或不能提交", k), nil)
+			return nil, false
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		strs := make([]string, len(list))
+		for i, item := range list {
+			strs[i] = apiFormText(item)
+		}
+		if field.FormType.IsMultiSelect() {
+			k += "[]"
+		}
+		values[k] = strs
+	}
+	return values, true
+}
+
+// apiFormText 把 JSON 中的值转换为表单提交的文字：null 为空，布尔值为 1、0，对象按 JSON 提交
+func apiFormText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// apiInsertedID 返回新增记录的主键
+// 由 withTxPostHook 写入的表格从 savedIDKey 读取；GoAdmin 直接写入的表格在有 PostHook 时把主键写回提交的值，
+// 其他情况取不到主键，返回空字符串
+func apiInsertedID(ctx *context.Context, values form2.Values, pk string) string {
+	if id, ok := ctx.UserValue[savedIDKey].(string); ok {
+		return id
+	}
+	if id := values.Get(pk); id != "0" {
+		return id
+	}
+	return ""
+}
+
+// apiSaveError 返回写入失败的原因：字段校验失败为 422，不是自己创建的记录为 403，其他为 400
+func apiSaveError(ctx *context.Context, err error) {
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]map[string]string, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = map[string]string{"field": fe.Field, "label": fe.Label, "message": fe.Message}
+		}
+		apiJSON(ctx, http.StatusUnprocessableEntity, err.Error(), map[string]interface{}{"errors": fields})
+	case errors.Is(err, models.ErrNotOwner):
+		apiJSON(ctx, http.StatusForbidden, err.Error(), nil)
+	default:
+		apiJSON(ctx, http.StatusBadRequest, err.Error(), nil)
+	}
+}
+
+// apiJSON 以 {"code": 状态码, "msg": 说明, "data": 数据} 的格式返回，data 为 nil 时省略
+func apiJSON(ctx *context.Context, status int, msg string, data interface{}) {
+	res := map[string]interface{}{
+		"code": status,
+		"msg":  msg,
+	}
+	if data != nil {
+		res["data"] = data
+	}
+	ctx.JSON(status, res)
+}
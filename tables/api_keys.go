@@ -0,0 +1,243 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现 API 密钥的签发和吊销：密钥用于调用 JSON 接口（见 api.go），代表签发它的管理员，
+// 可以限制为只读或只写，以及只能访问部分表格；密钥明文只在签发时显示一次
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// apiKeyScopes API 密钥的权限范围选项
+var apiKeyScopes = types.FieldOptions{
+	{Value: models.APIScopeRead, Text: "读取"},
+	{Value: models.APIScopeWrite, Text: "写入"},
+}
+
+// apiKeyTTLs 签发时可以选择的有效期，值为天数，0 表示永不过期
+var apiKeyTTLs = types.FieldOptions{
+	{Value: "30", Text: "30 天"},
+	{Value: "90", Text: "90 天"},
+	{Value: "365", Text: "1 年"},
+	{Value: "0", Text: "永不过期"},
+}
+
+// init 在 Generators 中注册 api_keys 前缀
+// 访问路径: /admin/info/api_keys
+// 功能: 签发和吊销调用 JSON 接口的 API 密钥
+func init() {
+	Register("api_keys", withAudit(GetAPIKeysTable))
+}
+
+// GetAPIKeysTable 获取 API 密钥表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 新增表单中显示随机生成的密钥，保存后只保留 SHA-256 和开头部分，明文无法再查看
+//   - 接口以签发人的角色和权限访问表格，非超级管理员只能看到、修改和吊销自己签发的密钥（见 withOwnership）
+//   - 签发后可以修改名称、权限范围和表格，密钥本身和有效期不能修改；"吊销"后密钥立即失效
+func GetAPIKeysTable(ctx *context.Context) (apiKeysTable table.Table) {
+
+	apiKeysTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := apiKeysTable.GetInfo().SetSortField("id").SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("密钥", "prefix", db.Varchar).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return template.HTML("<code>" + html.EscapeString(value.Value) + "…</code>")
+		})
+
+	info.AddField("权限范围", "scopes", db.Varchar).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return apiKeyOptionText(apiKeyScopes, value.Value)
+		})
+
+	info.AddField("表格", "tables", db.Varchar).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if strings.TrimSpace(value.Value) == "" {
+				return "全部"
+			}
+			return strings.ReplaceAll(value.Value, ",", "、")
+		})
+
+	info.AddField("状态", "revoked_at", db.Datetime).FieldDisplay(apiKeyStatus)
+
+	info.AddField("过期时间", "expires_at", db.Datetime).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Value == "" {
+				return "永不过期"
+			}
+			return value.Value
+		})
+
+	info.AddField("最近使用", "last_used_at", db.Datetime)
+
+	info.AddField("创建时间", "created_at", db.Datetime)
+
+	info.AddActionButton(ctx, "吊销", action.Ajax("/admin/api_keys/revoke",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			ids := []string{ctx.FormValue("id")}
+			if err := checkOwner(ctx, "api_keys", ids); err != nil {
+				return false, err.Error(), ""
+			}
+			before := snapshot(ctx.Request.Context(), "api_keys", "id", ids)
+			ok, err := models.RevokeAPIKey(ctx.Request.Context(), ids[0])
+			if err != nil {
+				return false, "吊销失败: " + err.Error(), ""
+			}
+			if !ok {
+				return false, "密钥已经吊销", ""
+			}
+			auditChange(ctx, "api_keys", "id", models.AuditRevoke, ids, before)
+			return true, "已吊销，使用该密钥的请求将被拒绝", ""
+		}))
+
+	info.SetTable("api_keys").SetTitle("API 密钥").SetDescription("调用 JSON 接口的密钥")
+
+	// 每次打开新增表单生成一个新的密钥；生成失败时为空，提交时由 validateAPIKey 拒绝
+	token, _ := models.NewAPIKeyToken()
+
+	formList := apiKeysTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust().
+		FieldHelpMsg("说明密钥的用途，如库存同步脚本")
+
+	formList.AddField("密钥", "key", db.Varchar, form.Text).
+		FieldDefault(token).FieldDisplayButCanNotEditWhenCreate().FieldDisableWhenUpdate().
+		FieldHelpMsg("只显示这一次，请复制后妥善保管；调用接口时放在 Authorization: Bearer 或 X-API-Key 请求头中")
+
+	formList.AddField("权限范围", "scopes", db.Varchar, form.Checkbox).
+		FieldOptions(apiKeyScopes).FieldDefault(models.APIScopeRead).FieldMust().
+		FieldHelpMsg("读取：查询列表和单条记录；写入：新增、修改和删除记录")
+
+	formList.AddField("表格", "tables", db.Varchar, form.Select).
+		FieldOptions(apiTableOptions()).
+		FieldHelpMsg("留空表示可以访问你有权限的全部表格")
+
+	formList.AddField("有效期", "ttl", db.Varchar, form.SelectSingle).
+		FieldOptions(apiKeyTTLs).FieldDefault("90").FieldDisableWhenUpdate()
+
+	formList.SetTable("api_keys").SetTitle("API 密钥").SetDescription("调用 JSON 接口的密钥")
+
+	formList.SetPostValidator(validateAPIKey)
+
+	// 明文只用于计算 SHA-256 和显示用的开头部分，不写入数据库
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if key := values.Get("key"); key != "" {
+			values.Add("key_hash", models.HashAPIKey(key))
+			values.Add("prefix", models.APIKeyDisplayPrefix(key))
+			values.Delete("key")
+		}
+		return values
+	})
+
+	// 表单通过 models.SaveRow 写入，在同一个事务中补上创建和修改时间
+	// 有效期只在新增时提交（修改时不显示），此时按有效期设置过期时间
+	withTxPostHook(ctx, apiKeysTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		ttl := values.Get("ttl")
+		if ttl == "" {
+			return models.TouchAPIKey(ctx, id)
+		}
+		days, _ := strconv.Atoi(ttl)
+		return models.SetAPIKeyExpiry(ctx, id, time.Duration(days)*24*time.Hour)
+	})
+
+	// 签发人决定接口使用的权限，由 withOwnership 在新增时设置，不能修改
+	withOwnership(ctx, apiKeysTable)
+
+	return
+}
+
+// validateAPIKey 校验 API 密钥的表单
+// 新增时密钥需要是新增表单中生成的格式，有效期需要是可选的值
+func validateAPIKey(values form2.Values) error {
+	if values.IsInsertPost() {
+		if !models.ValidAPIKeyToken(values.Get("key")) {
+			return errors.New("密钥格式无效，请刷新页面重新生成")
+		}
+		if !hasFieldOption(apiKeyTTLs, values.Get("ttl")) {
+			return errors.New("有效期无效")
+		}
+	}
+	scopes := values["scopes[]"]
+	if len(scopes) == 0 {
+		return errors.New("至少选择一个权限范围")
+	}
+	for _, s := range scopes {
+		if !hasFieldOption(apiKeyScopes, s) {
+			return fmt.Errorf("权限范围 %s 无效", s)
+		}
+	}
+	for _, p := range values["tables[]"] {
+		if p != "" && !apiTableAllowed(p) {
+			return fmt.Errorf("表格 %s 不存在或不能通过接口访问", p)
+		}
+	}
+	return nil
+}
+
+// hasFieldOption 判断 value 是否为选项中的一个值
+func hasFieldOption(options types.FieldOptions, value string) bool {
+	for _, o := range options {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyOptionText 返回逗号分隔的选项值对应的文字，以顿号连接，未知的值原样显示
+func apiKeyOptionText(options types.FieldOptions, value string) string {
+	var texts []string
+	for _, v := range strings.Split(value, ",") {
+		text := v
+		for _, o := range options {
+			if o.Value == v {
+				text = o.Text
+			}
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "、")
+}
+
+// apiKeyStatus 显示密钥的状态：已吊销、已过期或有效
+func apiKeyStatus(value types.FieldModel) interface{} {
+	if value.Value != "" {
+		return template.HTML(`<span class="label label-default">已吊销</span>`)
+	}
+	if t, ok := exportRowTime(value.Row["expires_at"]); ok && !time.Now().Before(t) {
+		return template.HTML(`<span class="label label-warning">已过期</span>`)
+	}
+	return template.HTML(`<span class="label label-success">有效</span>`)
+}
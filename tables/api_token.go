@@ -0,0 +1,243 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现 JSON 接口的登录：移动端和单页应用用管理员的登录名和密码换取访问令牌（JWT）和刷新令牌，
+// 之后以访问令牌调用表格接口（见 api.go），权限与该管理员在后台中相同
+package tables
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	adminModels "github.com/purpose168/GoAdmin/plugins/admin/models"
+	"gopkg.in/yaml.v2"
+)
+
+// JSON 接口登录相关的地址，均为 POST，请求体为 JSON 对象
+const (
+	// APILoginURL 登录，提交 username、password
+	APILoginURL = "/api/v1/auth/login"
+
+	// APIRefreshURL 刷新，提交 refresh_token
+	APIRefreshURL = "/api/v1/auth/refresh"
+
+	// APILogoutURL 退出登录，提交 refresh_token
+	APILogoutURL = "/api/v1/auth/logout"
+)
+
+// apiMinSecretLength 签名密钥的最短长度
+const apiMinSecretLength = 32
+
+// APIConfig JSON 接口登录令牌的配置，对应 config.yml 的 api 配置项
+type APIConfig struct {
+	// JWTSecret 签发访问令牌的 HS256 密钥，至少 32 个字符；为空时读取环境变量 API_JWT_SECRET，
+	// 都为空时不开放登录接口，表格接口只接受 API 密钥。修改后已签发的访问令牌全部失效
+	JWTSecret string `yaml:"jwt_secret"`
+
+	// AccessTTL 访问令牌的有效期，访问令牌签发后无法吊销，应设置得较短
+	AccessTTL time.Duration `yaml:"access_ttl"`
+
+	// RefreshTTL 刷新令牌的有效期，在此期间没有刷新需要重新登录
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+
+	// Tables 访问令牌可以访问的表格前缀，为空表示管理员有权限的全部表格
+	Tables []string `yaml:"tables"`
+}
+
+// DefaultAPIConfig 配置文件中没有 api 配置项时使用的默认配置，不开放登录接口
+var DefaultAPIConfig = APIConfig{
+	AccessTTL:  15 * time.Minute,
+	RefreshTTL: 30 * 24 * time.Hour,
+}
+
+// apiCfg JSON 接口登录令牌的配置，由 LoadAPIConfigFromYAML 读取
+var apiCfg = DefaultAPIConfig
+
+// LoadAPIConfigFromYAML 从 YAML 配置文件的 api 配置项读取 JSON 接口登录令牌的配置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析失败、密钥太短、有效期不是正数，或表格不存在、不能通过接口访问时返回错误
+//
+// 配置示例:
+//
+//	api:
+//	  jwt_secret: 至少 32 个字符的随机字符串
+//	  access_ttl: 15m
+//	  refresh_ttl: 720h
+//	  tables: [users, posts]
+func LoadAPIConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		API APIConfig `yaml:"api"`
+	}{API: DefaultAPIConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析接口配置失败: %v", err)
+	}
+	c := cfg.API
+	if c.JWTSecret == "" {
+		c.JWTSecret = os.Getenv("API_JWT_SECRET")
+	}
+	if c.JWTSecret != "" && len(c.JWTSecret) < apiMinSecretLength {
+		return fmt.Errorf("接口配置的 jwt_secret 至少需要 %d 个字符", apiMinSecretLength)
+	}
+	if c.AccessTTL <= 0 || c.RefreshTTL <= 0 {
+		return errors.New("接口配置的 access_ttl 和 refresh_ttl 必须大于 0")
+	}
+	for _, p := range c.Tables {
+		if !apiTableAllowed(p) {
+			return fmt.Errorf("接口配置的 tables 中的表格 %s 不存在或不能通过接口访问", p)
+		}
+	}
+
+	apiCfg = c
+	return nil
+}
+
+// APILoginEnabled 是否配置了签名密钥，开放登录、刷新和退出登录接口
+func APILoginEnabled() bool {
+	return apiCfg.JWTSecret != ""
+}
+
+// apiTokenAllowsTable 判断访问令牌是否可以访问该表格，配置的 tables 为空时不限制
+func apiTokenAllowsTable(prefix string) bool {
+	if len(apiCfg.Tables) == 0 {
+		return true
+	}
+	for _, p := range apiCfg.Tables {
+		if p == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// APILogin 用管理员的登录名和密码换取访问令牌和刷新令牌
+//
+// 路由: POST /api/v1/auth/login
+//
+// 请求格式:
+//
+//	{"username": "admin", "password": "admin"}
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"access_token": "eyJ...", "token_type": "Bearer", "expires_in": 900,
+//	  "refresh_token": "...", "refresh_expires_in": 2592000}}
+//
+// 注意事项:
+//   - 登录名或密码不正确时返回 401，不区分登录名是否存在
+func APILogin(ctx *context.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		apiJSON(ctx, http.StatusBadRequest, "请求格式错误，请提交 JSON 对象", nil)
+		return
+	}
+	id, err := models.AuthenticateAdmin(ctx.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidLogin) {
+			apiJSON(ctx, http.StatusUnauthorized, err.Error(), nil)
+			return
+		}
+		apiJSON(ctx, http.StatusInternalServerError, "登录失败，请稍后再试", nil)
+		return
+	}
+	refresh, err := models.IssueRefreshToken(ctx.Request.Context(), id, apiCfg.RefreshTTL)
+	if err != nil {
+		apiJSON(ctx, http.StatusInternalServerError, "登录失败，请稍后再试", nil)
+		return
+	}
+	apiTokens(ctx, id, refresh)
+}
+
+// APIRefresh 返回刷新接口的处理器：用刷新令牌换取新的访问令牌和刷新令牌
+//
+// 路由: POST /api/v1/auth/refresh
+//
+// 请求格式:
+//
+//	{"refresh_token": "..."}
+//
+// 返回格式与 APILogin 相同
+//
+// 注意事项:
+//   - 刷新令牌只能使用一次，使用后需要保存返回的新刷新令牌；旧令牌再次使用时该管理员的全部刷新令牌失效
+//   - 管理员被删除或修改了密码后刷新失败，需要重新登录
+func APIRefresh(conn db.Connection) context.Handler {
+	return func(ctx *context.Context) {
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+			apiJSON(ctx, http.StatusBadRequest, "请求格式错误，请提交 JSON 对象", nil)
+			return
+		}
+		id, refresh, err := models.RotateRefreshToken(ctx.Request.Context(), req.RefreshToken, apiCfg.RefreshTTL)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidAPIToken) {
+				apiJSON(ctx, http.StatusUnauthorized, err.Error(), nil)
+				return
+			}
+			apiJSON(ctx, http.StatusInternalServerError, "刷新失败，请稍后再试", nil)
+			return
+		}
+		if adminModels.User().SetConn(conn).Find(id).IsEmpty() {
+			apiJSON(ctx, http.StatusUnauthorized, "管理员已被删除", nil)
+			return
+		}
+		apiTokens(ctx, id, refresh)
+	}
+}
+
+// APILogout 吊销刷新令牌，客户端同时丢弃访问令牌；访问令牌在过期前仍然有效
+//
+// 路由: POST /api/v1/auth/logout
+//
+// 请求格式:
+//
+//	{"refresh_token": "..."}
+func APILogout(ctx *context.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		apiJSON(ctx, http.StatusBadRequest, "请求格式错误，请提交 JSON 对象", nil)
+		return
+	}
+	if err := models.RevokeRefreshToken(ctx.Request.Context(), req.RefreshToken); err != nil {
+		apiJSON(ctx, http.StatusInternalServerError, "退出登录失败，请稍后再试", nil)
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", nil)
+}
+
+// apiTokens 为管理员签发访问令牌，与刷新令牌一起返回
+func apiTokens(ctx *context.Context, userID int64, refresh string) {
+	access, err := models.SignAPIToken([]byte(apiCfg.JWTSecret), userID, time.Now(), apiCfg.AccessTTL)
+	if err != nil {
+		apiJSON(ctx, http.StatusInternalServerError, "签发令牌失败", nil)
+		return
+	}
+	apiJSON(ctx, http.StatusOK, "ok", map[string]interface{}{
+		"access_token":       access,
+		"token_type":         "Bearer",
+		"expires_in":         int(apiCfg.AccessTTL / time.Second),
+		"refresh_token":      refresh,
+		"refresh_expires_in": int(apiCfg.RefreshTTL / time.Second),
+	})
+}
@@ -0,0 +1,171 @@
+// Package tables 提供数据库表格模型定义
+// 本文件为表格的新增、修改、删除写入审计日志（见 models.AuditLog）
+package tables
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"log"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// withAudit 返回写入审计日志的表格生成函数
+//
+// 参数:
+//   - gen: 原来的表格生成函数
+//
+// 返回值:
+//   - table.Generator: 生成的表格在新增、修改、删除记录后写入审计日志
+//
+// 功能说明:
+//  1. 修改前通过 PreProcessFn、删除前通过 PreDeleteFn 读取整行数据作为修改前的快照
+//  2. 提交成功后通过 PostHook、DeleteHookWithRes 重新读取整行数据作为修改后的快照
+//  3. 表格原有的钩子保留，在审计之后执行
+//
+// 使用示例:
+//
+//	func init() {
+//	    Register("users", withAudit(GetUserTable))
+//	}
+//
+// 注意事项:
+//   - GoAdmin 在请求结束后异步执行提交和删除的钩子，审计日志稍后才会写入，写入失败只记录日志
+//   - 设置了 UpdateFn、InsertFn 的表单不在这里审计，写入函数需要自行记录，withTxPostHook 在事务中记录
+func withAudit(gen table.Generator) table.Generator {
+	return func(ctx *context.Context) table.Table {
+		t := gen(ctx)
+		auditTable(ctx, t)
+		return t
+	}
+}
+
+// auditTable 为表格的表单和列表挂上审计日志的钩子
+// 表格每个请求生成一次，闭包中的快照只属于当前请求
+func auditTable(ctx *context.Context, t table.Table) {
+	var (
+		name   = t.GetInfo().Table
+		pk     = t.GetPrimaryKey().Name
+		before map[string]map[string]interface{}
+	)
+	if name == "" {
+		return
+	}
+
+	auditForm := func(f *types.FormPanel) {
+		// 表单自行写入（如 withTxPostHook）时由写入函数记录审计日志，这里不再重复记录
+		if f.UpdateFn != nil || f.InsertFn != nil {
+			return
+		}
+
+		preProcess := f.PreProcessFn
+		f.SetPreProcessFn(func(values form.Values) form.Values {
+			if values.IsUpdatePost() {
+				before = snapshot(ctx.Request.Context(), name, pk, []string{values.Get(pk)})
+			}
+			if preProcess != nil {
+				return preProcess(values)
+			}
+			return values
+		})
+
+		postHook := f.PostHook
+		f.SetPostHook(func(values form.Values) error {
+			if values.PostError() == nil {
+				action := models.AuditUpdate
+				if values.IsInsertPost() {
+					action = models.AuditCreate
+				}
+				auditChange(ctx, name, pk, action, []string{values.Get(pk)}, before)
+			}
+			if postHook != nil {
+				return postHook(values)
+			}
+			return nil
+		})
+	}
+
+	auditForm(t.GetForm())
+	if f := t.GetActualNewForm(); f != t.GetForm() {
+		auditForm(f)
+	}
+
+	info := t.GetInfo()
+	preDelete := info.PreDeleteFn
+	info.SetPreDeleteFn(func(ids []string) error {
+		before = snapshot(ctx.Request.Context(), name, pk, ids)
+		if preDelete != nil {
+			return preDelete(ids)
+		}
+		return nil
+	})
+
+	deleteHook := info.DeleteHookWithRes
+	info.SetDeleteHookWithRes(func(ids []string, err error) error {
+		if err == nil {
+			auditChange(ctx, name, pk, models.AuditDelete, ids, before)
+		}
+		if deleteHook != nil {
+			return deleteHook(ids, err)
+		}
+		return nil
+	})
+}
+
+// snapshot 读取记录的快照，失败时只记录日志并返回 nil
+func snapshot(ctx stdctx.Context, name, pk string, ids []string) map[string]map[string]interface{} {
+	rows, err := models.SnapshotRows(ctx, name, pk, ids)
+	if err != nil {
+		log.Printf("读取 %s 的审计快照失败: %s\n", name, err)
+		return nil
+	}
+	return rows
+}
+
+// auditChange 为每个主键写入一条审计日志
+// before 为修改前的快照，修改后的快照在这里重新读取；记录已被物理删除时修改后的快照为空
+//
+// 注意事项:
+//   - 钩子在请求结束后才执行，请求的上下文可能已经取消，数据库操作使用 context.Background()
+func auditChange(ctx *context.Context, name, pk, action string, ids []string, before map[string]map[string]interface{}) {
+	after := snapshot(stdctx.Background(), name, pk, ids)
+	if err := models.CreateAuditLogs(stdctx.Background(), auditLogs(ctx, name, action, ids, before, after)); err != nil {
+		log.Printf("写入 %s 的审计日志失败: %s\n", name, err)
+	}
+}
+
+// auditLogs 按修改前后的快照为每个主键生成一条审计日志，修改人取当前登录的管理员
+func auditLogs(ctx *context.Context, name, action string, ids []string, before, after map[string]map[string]interface{}) []models.AuditLog {
+	user := auth.Auth(ctx)
+	logs := make([]models.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		logs = append(logs, models.AuditLog{
+			Table:     name,
+			RecordID:  id,
+			Action:    action,
+			ActorID:   user.Id,
+			ActorName: user.Name,
+			Before:    auditJSON(before[id]),
+			After:     auditJSON(after[id]),
+		})
+	}
+	return logs
+}
+
+// auditJSON 返回整行数据的 JSON，row 为 nil 时返回 nil
+func auditJSON(row map[string]interface{}) *string {
+	if row == nil {
+		return nil
+	}
+	b, err := json.Marshal(row)
+	if err != nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
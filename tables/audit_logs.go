@@ -0,0 +1,163 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现审计日志（audit_logs）的只读表格，可以查看每条日志修改前后的对比
+package tables
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 audit_logs 前缀
+// 访问路径: /admin/info/audit_logs
+// 功能: 审计日志的只读表格，可以查看每次修改前后的对比；不经过 withAudit，查看日志本身不产生审计记录
+func init() {
+	Register("audit_logs", GetAuditLogsTable)
+}
+
+// GetAuditLogsTable 获取审计日志表格模型
+// 该函数创建并返回审计日志的只读表格，用于查看管理员在后台做过的修改
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 不能新增、修改和删除，审计日志只由各表格的写入钩子生成（见 withAudit）
+//   - 可以按修改人、数据表、操作类型和时间范围筛选，默认按时间倒序排列
+//   - 每行的"对比"按钮弹出修改前后逐个字段的对比，有变化的字段高亮显示
+//   - 配置了只读副本时，列表和导出从副本读取
+func GetAuditLogsTable(ctx *context.Context) table.Table {
+	return withReadReplica(ctx, newAuditLogsTable)
+}
+
+// newAuditLogsTable 使用指定的数据库连接创建审计日志表格模型
+func newAuditLogsTable(ctx *context.Context, conn string) (auditLogsTable table.Table) {
+
+	auditLogsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriverAndConnection(defaultDriver(), conn).
+		SetCanAdd(false).SetEditable(false).SetDeletable(false))
+
+	// CanAdd 只拒绝新增页面的访问，"新建"按钮需要另外隐藏
+	info := auditLogsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("id").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("时间", "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	info.AddField("修改人", "actor_name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	tableOptions := make(types.FieldOptions, 0)
+	for _, name := range models.AuditedTables(ctx.Request.Context()) {
+		tableOptions = append(tableOptions, types.FieldOption{Value: name, Text: name})
+	}
+	info.AddField("数据表", "table_name", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(tableOptions)
+
+	info.AddField("记录编号", "record_id", db.Varchar)
+
+	info.AddField("操作", "action", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(auditActionOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return auditActionLabel(value.Value)
+		})
+
+	info.AddActionButton(ctx, "对比", action.PopUp("/admin/audit_logs/diff", "修改前后对比",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			l, err := models.FindAuditLog(ctx.Request.Context(), ctx.FormValue("id"))
+			if err != nil {
+				return false, "读取审计日志失败: " + err.Error(), ""
+			}
+			changes, err := l.Changes()
+			if err != nil {
+				return false, err.Error(), ""
+			}
+			return true, "", auditDiffTable(changes)
+		}))
+
+	info.SetTable("audit_logs").SetTitle("审计日志").SetDescription("管理后台的数据修改记录")
+
+	return
+}
+
+// auditActionOptions 审计日志的操作类型选项
+var auditActionOptions = types.FieldOptions{
+	{Value: models.AuditCreate, Text: "新增"},
+	{Value: models.AuditUpdate, Text: "修改"},
+	{Value: models.AuditDelete, Text: "删除"},
+	{Value: models.AuditRestore, Text: "恢复"},
+	{Value: models.AuditMerge, Text: "合并"},
+	{Value: models.AuditRevoke, Text: "吊销"},
+}
+
+// auditActionColors 各操作类型在列表中的标签颜色
+var auditActionColors = map[string]string{
+	models.AuditCreate:  "success",
+	models.AuditUpdate:  "primary",
+	models.AuditDelete:  "danger",
+	models.AuditRestore: "warning",
+	models.AuditMerge:   "info",
+	models.AuditRevoke:  "default",
+}
+
+// auditActionLabel 将操作类型渲染为带颜色的标签，未知的类型原样显示
+func auditActionLabel(a string) template.HTML {
+	text := a
+	for _, o := range auditActionOptions {
+		if o.Value == a {
+			text = o.Text
+		}
+	}
+	color, ok := auditActionColors[a]
+	if !ok {
+		color = "default"
+	}
+	return template.HTML(fmt.Sprintf(`<span class="label label-%s">%s</span>`, color, html.EscapeString(text)))
+}
+
+// auditDiffTable 将逐个字段的对比渲染为表格，有变化的字段名加粗，修改前后的值分别标红和标绿
+func auditDiffTable(changes []models.AuditFieldChange) template.HTML {
+	if len(changes) == 0 {
+		return `<p class="text-muted">没有记录修改前后的数据</p>`
+	}
+
+	rows := make([]map[string]types.InfoItem, 0, len(changes))
+	for _, c := range changes {
+		field := template.HTML(html.EscapeString(c.Field))
+		before := template.HTML(html.EscapeString(c.Before))
+		after := template.HTML(html.EscapeString(c.After))
+		if c.Changed {
+			field = "<b>" + field + "</b>"
+			before = `<span class="text-red">` + before + `</span>`
+			after = `<span class="text-green">` + after + `</span>`
+		}
+		rows = append(rows, map[string]types.InfoItem{
+			"字段":  {Content: field},
+			"修改前": {Content: before},
+			"修改后": {Content: after},
+		})
+	}
+
+	return tmpl.Default().Table().SetType("table").SetInfoList(rows).SetThead(types.Thead{
+		{Head: "字段"},
+		{Head: "修改前"},
+		{Head: "修改后"},
+	}).GetContent()
+}
@@ -0,0 +1,164 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现作者列表的"合并"操作：选择两个重复的作者，把其中一个的文章移到另一个名下并删除它
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// authorMergeURL "合并"弹窗的地址，打开弹窗和提交合并共用
+const authorMergeURL = "/admin/authors/merge"
+
+// addAuthorMerge 在作者列表顶部添加"合并"按钮
+// 弹窗打开时不带参数，显示两个作者的选择框；选择后带上 survivor_id 和 duplicate_id 再次提交，由 mergeAuthors 执行合并
+func addAuthorMerge(ctx *context.Context, info *types.InfoPanel) {
+	info.AddButton(ctx, "合并", icon.Compress, action.PopUp(authorMergeURL, "合并重复的作者",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			if !ctx.Request.Form.Has("duplicate_id") {
+				return true, "", authorMergeForm(models.ActiveAuthors(ctx.Request.Context()))
+			}
+			return mergeAuthors(ctx, ctx.FormValue("survivor_id"), ctx.FormValue("duplicate_id"))
+		}))
+	info.AddJS(authorMergeJS)
+}
+
+// mergeAuthors 把 duplicateID 合并到 survivorID
+//
+// 功能说明:
+//   - 需要删除作者和修改文章的权限
+//   - 文章的修改、作者的软删除和审计日志在一个事务中写入，任何一步失败时全部回滚
+//   - 被合并的作者写入一条"合并"审计日志，修改后的快照中 merged_into 为保留的作者；
+//     每篇移动的文章各写入一条"修改"审计日志
+func mergeAuthors(ctx *context.Context, survivorID, duplicateID string) (success bool, msg string, data interface{}) {
+	survivor, err1 := strconv.ParseInt(survivorID, 10, 64)
+	duplicate, err2 := strconv.ParseInt(duplicateID, 10, 64)
+	if err1 != nil || err2 != nil {
+		return false, "请选择两个作者", ""
+	}
+	user := auth.Auth(ctx)
+	if !user.CheckPermissionByUrlMethod(config.Url("/delete/authors"), http.MethodPost, url.Values{}) ||
+		!user.CheckPermissionByUrlMethod(config.Url("/edit/posts"), http.MethodPost, url.Values{}) {
+		return false, "没有合并作者的权限", ""
+	}
+
+	var moved []string
+	err := models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		authorIDs := []string{duplicateID}
+		before, err := models.SnapshotRows(txCtx, "authors", "id", authorIDs)
+		if err != nil {
+			return err
+		}
+		if moved, err = models.MergeAuthors(txCtx, survivorID, duplicateID); err != nil {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "authors", "id", authorIDs)
+		if err != nil {
+			return err
+		}
+		if row := after[duplicateID]; row != nil {
+			row["merged_into"] = survivor
+		}
+		logs := auditLogs(ctx, "authors", models.AuditMerge, authorIDs, before, after)
+
+		// 文章只修改了 author_id，修改前的快照由修改后的快照换回原作者得到
+		posts, err := models.SnapshotRows(txCtx, "posts", "id", moved)
+		if err != nil {
+			return err
+		}
+		postsBefore := make(map[string]map[string]interface{}, len(posts))
+		for id, row := range posts {
+			old := make(map[string]interface{}, len(row))
+			for k, v := range row {
+				old[k] = v
+			}
+			old["author_id"] = duplicate
+			postsBefore[id] = old
+		}
+		logs = append(logs, auditLogs(ctx, "posts", models.AuditUpdate, moved, postsBefore, posts)...)
+		return models.CreateAuditLogs(txCtx, logs)
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrMergeSameAuthor) || errors.Is(err, models.ErrAuthorNotFound) {
+			return false, err.Error(), ""
+		}
+		return false, "合并失败: " + err.Error(), ""
+	}
+	return true, fmt.Sprintf("已合并，移动了 %d 篇文章", len(moved)), ""
+}
+
+// authorMergeForm 渲染"合并"弹窗的内容：保留的作者、被合并的作者和确定按钮
+func authorMergeForm(authors []models.Author) template.HTML {
+	var options strings.Builder
+	options.WriteString(`<option value="">请选择</option>`)
+	for _, a := range authors {
+		fmt.Fprintf(&options, `<option value="%d">%d - %s (%s)</option>`,
+			a.ID, a.ID, html.EscapeString(a.Name()), html.EscapeString(a.Email))
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="author-merge" data-url="%s" style="padding:10px">
+  <div class="form-group">
+    <label>保留的作者</label>
+    <select class="form-control author-merge-survivor">%s</select>
+  </div>
+  <div class="form-group">
+    <label>被合并的作者</label>
+    <select class="form-control author-merge-duplicate">%s</select>
+    <p class="help-block">被合并作者的全部文章改为保留的作者，之后该作者从列表中删除</p>
+  </div>
+  <button type="button" class="btn btn-primary author-merge-submit">合并</button>
+</div>`, action.URL(authorMergeURL), options.String(), options.String()))
+}
+
+// authorMergeJS 提交"合并"弹窗，成功后关闭弹窗并刷新列表
+const authorMergeJS = template.JS(`
+$(document).off('click.authorMerge').on('click.authorMerge', '.author-merge-submit', function () {
+    let box = $(this).closest('.author-merge');
+    let survivor = box.find('.author-merge-survivor').val();
+    let duplicate = box.find('.author-merge-duplicate').val();
+    if (!survivor || !duplicate) {
+        swal('请选择两个作者', '', 'warning');
+        return;
+    }
+    if (survivor === duplicate) {
+        swal('不能把作者合并到自身', '', 'warning');
+        return;
+    }
+    $.ajax({
+        method: 'post',
+        url: box.data('url'),
+        data: {survivor_id: survivor, duplicate_id: duplicate},
+        success: function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            if (data.code === 0) {
+                $('.modal').modal('hide');
+                swal(data.msg, '', 'success');
+                $.pjax.reload('#pjax-container');
+            } else {
+                swal(data.msg, '', 'error');
+            }
+        },
+        error: function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '合并失败', '', 'error');
+        }
+    });
+});
+`)
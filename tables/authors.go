@@ -3,8 +3,11 @@
 package tables
 
 import (
+	"github.com/purpose168/GoAdmin-example/models"
 	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
 	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
 	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
 	"github.com/purpose168/GoAdmin/template/icon"
 	"github.com/purpose168/GoAdmin/template/types"
@@ -12,6 +15,21 @@ import (
 	"github.com/purpose168/GoAdmin/template/types/form"
 )
 
+// init 在 Generators 中注册 authors 前缀
+// 访问路径: /admin/info/authors
+// 功能: 作者管理表格，支持自定义按钮和组合字段显示
+// 全局搜索: 按名、姓和邮箱搜索
+func init() {
+	Register("authors", withAudit(GetAuthorsTable))
+	RegisterSearch("authors", "作者", models.SearchSpec{
+		Table:        "authors",
+		Columns:      []string{"first_name", "last_name", "email"},
+		TitleColumns: []string{"first_name", "last_name"},
+		DetailColumn: "email",
+		SoftDelete:   true,
+	})
+}
+
 // GetAuthorsTable 获取作者表格模型
 // 该函数创建并返回一个配置完整的作者表格模型，用于管理后台的作者信息展示和编辑
 //
@@ -29,13 +47,15 @@ import (
 //   - 配置表单编辑字段（编辑视图）
 //   - 添加自定义按钮操作（查看文章列表）
 //   - 设置表格标题和描述
+//   - 添加合并重复作者的按钮
+//   - 列表从 author_stats 视图读取，显示每个作者的文章数和最近发表日期，两列都可以排序（见 authorStatsTable）
 func GetAuthorsTable(ctx *context.Context) (authorsTable table.Table) {
 
 	// 创建默认表格模型
 	// NewDefaultTable 创建一个使用默认配置的表格实例
-	// DefaultConfigWithDriver 指定数据库驱动类型为 "sqlite"
+	// DefaultConfigWithDriver 指定数据库驱动类型，取自 config.yml 中 default 数据库的 driver
 	// 支持的驱动类型: mysql, postgres, sqlite, mssql 等
-	authorsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver("sqlite"))
+	authorsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
 
 	// 如果需要使用自定义数据库连接，可以使用以下方式：
 	// authorsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriverAndConnection("mysql", "admin"))
@@ -103,6 +123,17 @@ func GetAuthorsTable(ctx *context.Context) (authorsTable table.Table) {
 	//   - db.Timestamp: 字段数据类型（时间戳）
 	info.AddField("添加时间", "added", db.Timestamp)
 
+	// 添加文章数和最近发表日期字段
+	// 这两列只存在于 author_stats 视图中，由视图按 posts 表统计，已删除的文章不计入
+	info.AddField("文章数", "post_count", db.Int).FieldSortable()
+	info.AddField("最近发表", "last_post_date", db.Date).FieldSortable().
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Value == "" {
+				return "暂无"
+			}
+			return value.Value
+		})
+
 	// 添加自定义按钮操作
 	// AddButton 在每行数据中添加一个操作按钮
 	// 参数说明:
@@ -117,7 +148,11 @@ func GetAuthorsTable(ctx *context.Context) (authorsTable table.Table) {
 	//     - "900px": 弹窗宽度
 	//     - "560px": 弹窗高度
 	info.AddButton(ctx, "文章", icon.Tv,
-		action.PopUpWithIframe("/authors/list", "文章", action.IframeData{Src: "/admin/info/posts"}, "900px", "560px"))
+		action.PopUpWithIframe("/authors/list", "文章", action.IframeData{Src: config.Url("/info/posts")}, "900px", "560px"))
+
+	// 添加合并重复作者的按钮（全局）
+	// 选择保留的作者和被合并的作者，被合并作者的文章改为保留的作者后软删除被合并的作者（见 mergeAuthors）
+	addAuthorMerge(ctx, info)
 
 	// 设置表格基本信息
 	// SetTable: 指定数据库表名
@@ -185,6 +220,61 @@ func GetAuthorsTable(ctx *context.Context) (authorsTable table.Table) {
 	// SetDescription: 设置表单描述
 	formList.SetTable("authors").SetTitle("作者").SetDescription("作者")
 
+	// 设置校验规则（见 FormRules），浏览器中输入时提示，提交后在服务端再次校验
+	NewFormRules().
+		FieldRules("first_name", "名", "required|max:50").
+		FieldRules("last_name", "姓", "max:50").
+		FieldRules("email", "邮箱", "email|max:100").
+		Apply(ctx, formList)
+
+	// 配置详情视图
+	// 详情页按表单的数据表（authors）查询，没有视图中的文章数和最近发表日期，
+	// 因此不沿用列表的字段，只显示作者本身的信息
+	detail := authorsTable.GetDetail()
+	detail.AddField("编号", "id", db.Int)
+	detail.AddField("名", "first_name", db.Varchar)
+	detail.AddField("姓", "last_name", db.Varchar)
+	detail.AddField("邮箱", "email", db.Varchar)
+	detail.AddField("出生日期", "birthdate", db.Date)
+	detail.AddField("添加时间", "added", db.Timestamp)
+	detail.SetTable("authors").SetTitle("作者详情").SetDescription("作者")
+
+	// 列表和导出从 author_stats 视图读取
+	authorsTable = authorStatsTable{Table: authorsTable}
+
 	// 返回配置好的表格模型
 	return
 }
+
+// authorStatsView 作者列表读取的视图，在 authors 的字段之外增加 post_count 和 last_post_date
+const authorStatsView = "author_stats"
+
+// authorStatsTable 列表和导出从 author_stats 视图读取的作者表格
+// 视图不能写入，表格的数据表仍为 authors：表单、详情、删除和审计日志都使用 authors 表，
+// 只在查询列表数据时临时把数据表换成视图，排序和筛选的字段都是视图中的列
+type authorStatsTable struct {
+	table.Table
+}
+
+// GetData 从视图查询列表数据，导出全部数据时也通过该方法查询
+func (t authorStatsTable) GetData(ctx *context.Context, params parameter.Parameters) (table.PanelInfo, error) {
+	info := t.GetInfo()
+	name := info.Table
+	info.Table = authorStatsView
+	defer func() { info.Table = name }()
+	return t.Table.GetData(ctx, params)
+}
+
+// GetDataWithIds 从视图查询选中的记录，用于导出选中的数据
+func (t authorStatsTable) GetDataWithIds(ctx *context.Context, params parameter.Parameters) (table.PanelInfo, error) {
+	info := t.GetInfo()
+	name := info.Table
+	info.Table = authorStatsView
+	defer func() { info.Table = name }()
+	return t.Table.GetDataWithIds(ctx, params)
+}
+
+// Copy 复制表格，复制后仍从视图读取列表
+func (t authorStatsTable) Copy() table.Table {
+	return authorStatsTable{Table: t.Table.Copy()}
+}
@@ -0,0 +1,134 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现用户头像的缩略图保存：把上传的图片转换为缩略图，按配置保存在本地上传目录或 S3 中
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules"
+	"github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/types"
+	"gopkg.in/yaml.v2"
+)
+
+// avatarMaxBytes 上传头像原图的最大字节数
+const avatarMaxBytes = 5 << 20
+
+// errAvatarTooLarge 上传的头像原图超过 avatarMaxBytes
+var errAvatarTooLarge = fmt.Errorf("图片不能超过 %d MB", avatarMaxBytes>>20)
+
+var (
+	// avatarConfig 头像的缩略图尺寸和存储方式，由 LoadAvatarConfigFromYAML 读取
+	avatarConfig = models.DefaultAvatarConfig
+
+	// avatarStorage 保存缩略图的存储，未读取配置时保存在本地上传目录
+	avatarStorage models.AvatarStorage
+)
+
+// LoadAvatarConfigFromYAML 从 YAML 配置文件的 avatar 配置项读取头像的缩略图尺寸和存储方式
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析配置失败，或存储配置不完整时返回错误
+//
+// 配置示例:
+//
+//	avatar:
+//	  size: 200
+//	  storage: s3
+//	  s3:
+//	    endpoint: https://minio.example.com:9000
+//	    region: us-east-1
+//	    bucket: avatars
+//
+// 注意事项:
+//   - 需要在 GoAdmin 读取配置之后调用，本地存储使用 store.path 作为上传目录
+func LoadAvatarConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		Avatar models.AvatarConfig `yaml:"avatar"`
+	}{Avatar: models.DefaultAvatarConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析头像配置失败: %v", err)
+	}
+	if cfg.Avatar.Size <= 0 {
+		return fmt.Errorf("头像配置的 size 应大于 0: %d", cfg.Avatar.Size)
+	}
+
+	storage, err := models.NewAvatarStorage(cfg.Avatar, config.GetStore().Path)
+	if err != nil {
+		return err
+	}
+	avatarConfig, avatarStorage = cfg.Avatar, storage
+	return nil
+}
+
+// avatarImage 返回列表中显示头像缩略图的 FieldDisplay 函数，点击缩略图在弹窗中查看
+func avatarImage(value types.FieldModel) interface{} {
+	if value.Value == "" {
+		return template.HTML(`<span class="text-muted">未上传</span>`)
+	}
+	return template.Default().Image().
+		SetSrc(template.HTML(config.GetStore().URL(value.Value))).
+		SetHeight("60").SetWidth("60").WithModal().GetContent()
+}
+
+// SaveAvatar 读取上传的头像原图，生成缩略图并保存到配置的存储中
+//
+// 参数:
+//   - ctx: 上下文，保存到 S3 时使用
+//   - r: 原图内容，超过 5 MB 时不再读取
+//
+// 返回值:
+//   - string: 缩略图的地址，保存到 goadmin_users.avatar 字段，显示时通过 config.GetStore().URL 转换
+//   - error: 图片无法识别或超过大小限制时返回 IsAvatarInputError 为 true 的错误，保存失败时返回其他错误
+//
+// 注意事项:
+//   - 用户表单和个人资料页面（pages.AccountPage）中裁剪后的头像由 AvatarUpload 通过该函数保存
+func SaveAvatar(ctx stdctx.Context, r io.Reader) (string, error) {
+	data, err := readAvatar(r)
+	if err != nil {
+		return "", err
+	}
+	thumb, err := models.ResizeAvatar(data, avatarConfig.Size)
+	if err != nil {
+		return "", err
+	}
+
+	storage := avatarStorage
+	if storage == nil {
+		if storage, err = models.NewAvatarStorage(avatarConfig, config.GetStore().Path); err != nil {
+			return "", err
+		}
+	}
+	return storage.Put(ctx, modules.Uuid()+".jpg", thumb)
+}
+
+// readAvatar 读取上传的原图，超过 avatarMaxBytes 时返回 errAvatarTooLarge
+func readAvatar(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, avatarMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > avatarMaxBytes {
+		return nil, errAvatarTooLarge
+	}
+	return data, nil
+}
+
+// IsAvatarInputError 头像错误是否由上传的文件引起，这类错误作为字段的校验错误显示
+func IsAvatarInputError(err error) bool {
+	return errors.Is(err, models.ErrInvalidImage) || errors.Is(err, errAvatarTooLarge) || errors.Is(err, errInvalidCrop)
+}
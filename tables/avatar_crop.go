@@ -0,0 +1,276 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现头像上传前的裁剪：浏览器中选择图片后按 1:1 裁剪，把裁剪后的图片上传到单独的接口，
+// 接口在服务端检查图片类型和尺寸后生成缩略图，表单只提交缩略图的地址
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+)
+
+// AvatarUploadURL 裁剪后的头像上传接口的地址（在后台 URL 前缀下的路径），需要在 main 中注册 AvatarUpload
+const AvatarUploadURL = "/avatar/upload"
+
+// 裁剪后的图片的边长范围，单位为像素
+// 浏览器按 avatar 配置项的 size 导出，这里只拒绝明显不是由裁剪控件生成的图片
+const (
+	avatarCropMinSize = 32
+	avatarCropMaxSize = 2048
+)
+
+// 裁剪控件的脚本和样式地址
+// 默认使用 Cropper.js，从 CDN 加载；无法访问外网的部署可以改为本地静态文件
+var (
+	// CropperURL Cropper.js 脚本地址
+	CropperURL = "https://cdn.jsdelivr.net/npm/cropperjs@1.6.2/dist/cropper.min.js"
+
+	// CropperCSSURL Cropper.js 样式地址
+	CropperCSSURL = "https://cdn.jsdelivr.net/npm/cropperjs@1.6.2/dist/cropper.min.css"
+)
+
+// avatarCropTypes 接口接受的图片类型，按文件内容识别，不使用请求中声明的类型
+var avatarCropTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// errInvalidCrop 上传的头像不是裁剪控件生成的正方形图片
+var errInvalidCrop = errors.New("裁剪后的头像无效")
+
+// avatarPathPattern 头像接口保存的缩略图地址：本地存储为 avatars/<UUID>.jpg，S3 在前面加上访问地址前缀
+var avatarPathPattern = regexp.MustCompile(`(^|/)avatars/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\.jpg$`)
+
+// ValidAvatarPath 地址是否由 AvatarUpload 保存，表单提交的头像地址需要先经过该检查
+func ValidAvatarPath(path string) bool {
+	return avatarPathPattern.MatchString(path)
+}
+
+// AvatarUpload 保存裁剪后的头像
+//
+// 请求格式:
+//
+//	POST multipart/form-data，文件字段为 avatar
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"path": "avatars/xxx.jpg", "url": "/uploads/avatars/xxx.jpg"}}
+//	{"code": 400, "msg": "错误信息"}
+//
+// 注意事项:
+//   - 图片必须是 JPEG、PNG 或 WebP，宽高相等且在 32 到 2048 像素之间，否则返回 400
+//   - 任何登录的管理员都可以上传，个人资料页面和用户表单共用；上传后只生成缩略图，保存表单时才写入头像字段
+func AvatarUpload(ctx *context.Context) {
+	fail := func(status int, msg string) {
+		ctx.JSON(status, map[string]interface{}{"code": status, "msg": msg})
+	}
+
+	file, _, err := ctx.Request.FormFile("avatar")
+	if err != nil {
+		fail(http.StatusBadRequest, "请选择头像")
+		return
+	}
+	defer file.Close()
+
+	data, err := readCroppedAvatar(file)
+	if IsAvatarInputError(err) {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		fail(http.StatusInternalServerError, "读取头像失败: "+err.Error())
+		return
+	}
+
+	path, err := SaveAvatar(ctx.Request.Context(), bytes.NewReader(data))
+	if IsAvatarInputError(err) {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		fail(http.StatusInternalServerError, "保存头像失败: "+err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+		"data": map[string]string{"path": path, "url": config.GetStore().URL(path)},
+	})
+}
+
+// readCroppedAvatar 读取裁剪后的图片，检查大小、类型和尺寸
+// 不符合要求时返回 IsAvatarInputError 为 true 的错误
+func readCroppedAvatar(r io.Reader) ([]byte, error) {
+	data, err := readAvatar(r)
+	if err != nil {
+		return nil, err
+	}
+	format, ok := avatarCropTypes[http.DetectContentType(data)]
+	if !ok {
+		return nil, fmt.Errorf("%w: 只支持 JPEG、PNG 和 WebP", errInvalidCrop)
+	}
+	cfg, f, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || f != format {
+		return nil, models.ErrInvalidImage
+	}
+	if cfg.Width != cfg.Height {
+		return nil, fmt.Errorf("%w: 应为正方形，实际为 %d×%d", errInvalidCrop, cfg.Width, cfg.Height)
+	}
+	if cfg.Width < avatarCropMinSize || cfg.Width > avatarCropMaxSize {
+		return nil, fmt.Errorf("%w: 边长应在 %d 到 %d 像素之间，实际为 %d", errInvalidCrop,
+			avatarCropMinSize, avatarCropMaxSize, cfg.Width)
+	}
+	return data, nil
+}
+
+// AvatarCropper 返回头像裁剪控件的 HTML
+//
+// 参数:
+//   - name: 保存缩略图地址的隐藏字段名；作为 form.Custom 字段的内容时传入 "{{.Field}}"
+//   - value: 当前的头像地址；作为 form.Custom 字段的内容时传入 "{{.Value}}"，为空时不显示预览
+//
+// 注意事项:
+//   - 需要同时在页面中加入 AvatarCropperJS
+//   - 控件中的文件选择框没有 name，原图不随表单提交
+func AvatarCropper(name, value string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<div class="avatar-cropper" style="width:100%%">
+  <input type="hidden" name="%s" class="avatar-cropper-value" value="%s">
+  <img class="avatar-cropper-preview img-circle" alt="头像" style="width:60px;height:60px;display:none;margin-bottom:6px">
+  <input type="file" class="avatar-cropper-input" accept="image/jpeg,image/png,image/gif,image/webp">
+  <span class="help-block">选择图片后按 1:1 裁剪，支持 JPEG、PNG、GIF 和 WebP，不超过 %d MB，保存为 %d×%d 的缩略图</span>
+  <div class="modal fade avatar-cropper-modal" tabindex="-1" role="dialog">
+    <div class="modal-dialog" role="document">
+      <div class="modal-content">
+        <div class="modal-header">
+          <button type="button" class="close" data-dismiss="modal" aria-label="关闭"><span aria-hidden="true">&times;</span></button>
+          <h4 class="modal-title">裁剪头像</h4>
+        </div>
+        <div class="modal-body"><div style="max-height:60vh"><img class="avatar-cropper-image" alt="" style="display:block;max-width:100%%"></div></div>
+        <div class="modal-footer">
+          <button type="button" class="btn btn-default" data-dismiss="modal">取消</button>
+          <button type="button" class="btn btn-primary avatar-cropper-confirm" data-loading-text="上传中...">确定</button>
+        </div>
+      </div>
+    </div>
+  </div>
+</div>`, template.HTMLEscapeString(name), template.HTMLEscapeString(value),
+		avatarMaxBytes>>20, avatarConfig.Size, avatarConfig.Size))
+}
+
+// AvatarCropperJS 头像裁剪控件的交互
+// 选择图片后在弹窗中按 1:1 裁剪，确定后导出为 avatar 配置项 size 边长的 JPEG，
+// 上传到 AvatarUploadURL，把返回的缩略图地址写入隐藏字段并显示预览
+func AvatarCropperJS() template.JS {
+	options, _ := json.Marshal(map[string]interface{}{
+		"script": CropperURL,
+		"css":    CropperCSSURL,
+		"upload": config.Url(AvatarUploadURL),
+		"store":  config.GetStore().URL("/"),
+		"size":   avatarConfig.Size,
+	})
+	return template.JS(`
+(function (options) {
+    function avatarURL(p) {
+        return /^https?:\/\//.test(p) ? p : options.store.replace(/\/$/, '') + '/' + p.replace(/^\//, '');
+    }
+    function load() {
+        if (!$('link[href="' + options.css + '"]').length) {
+            $('<link rel="stylesheet">').attr('href', options.css).appendTo('head');
+        }
+        return window.Cropper ? $.when() : $.ajax({url: options.script, dataType: 'script', cache: true});
+    }
+
+    $('.avatar-cropper').each(function () {
+        let root = $(this), value = root.find('.avatar-cropper-value'), preview = root.find('.avatar-cropper-preview');
+        let input = root.find('.avatar-cropper-input'), modal = root.find('.avatar-cropper-modal');
+        let image = modal.find('.avatar-cropper-image')[0], cropper = null;
+
+        function show(p) {
+            preview.attr('src', p ? avatarURL(p) : '').toggle(!!p);
+        }
+        show(value.val());
+        // 弹窗移到 body 下，避免被表单的样式遮挡
+        modal.appendTo('body');
+
+        input.on('change', function () {
+            let file = this.files[0];
+            if (!file) {
+                return;
+            }
+            if (!/^image\/(jpeg|png|gif|webp)$/.test(file.type)) {
+                swal('请选择 JPEG、PNG、GIF 或 WebP 图片', '', 'error');
+                input.val('');
+                return;
+            }
+            let reader = new FileReader();
+            reader.onload = function (e) {
+                load().done(function () {
+                    image.src = e.target.result;
+                    modal.modal('show');
+                }).fail(function () {
+                    swal('加载裁剪控件失败', '', 'error');
+                });
+            };
+            reader.readAsDataURL(file);
+        });
+
+        modal.on('shown.bs.modal', function () {
+            cropper = new Cropper(image, {aspectRatio: 1, viewMode: 1, autoCropArea: 1});
+        }).on('hidden.bs.modal', function () {
+            if (cropper) {
+                cropper.destroy();
+                cropper = null;
+            }
+            input.val('');
+        });
+
+        modal.find('.avatar-cropper-confirm').on('click', function () {
+            let btn = $(this);
+            if (!cropper) {
+                return;
+            }
+            btn.button('loading');
+            let canvas = cropper.getCroppedCanvas({
+                width: options.size,
+                height: options.size,
+                fillColor: '#fff',
+                imageSmoothingQuality: 'high'
+            });
+            canvas.toBlob(function (blob) {
+                let data = new FormData();
+                data.append('avatar', blob, 'avatar.jpg');
+                $.ajax({
+                    method: 'post',
+                    url: options.upload,
+                    data: data,
+                    processData: false,
+                    contentType: false,
+                    success: function (resp) {
+                        value.val(resp.data.path).trigger('change');
+                        show(resp.data.path);
+                        modal.modal('hide');
+                    },
+                    error: function (xhr) {
+                        swal(xhr.responseJSON ? xhr.responseJSON.msg : '上传失败', '', 'error');
+                    },
+                    complete: function () {
+                        btn.button('reset');
+                    }
+                });
+            }, 'image/jpeg', 0.92);
+        });
+    });
+})(` + string(options) + `);
+`)
+}
@@ -0,0 +1,280 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现商品分类（categories）表格的模型配置，以及商品和分类表单共用的树形分类选项
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 categories 前缀
+// 访问路径: /admin/info/categories
+// 功能: 商品分类表格，维护商品表单中的分类树
+func init() {
+	Register("categories", withAudit(GetCategoriesTable))
+}
+
+// GetCategoriesTable 获取商品分类表格模型
+// 该函数创建并返回商品分类表格模型，用于维护商品表单中可选的分类树
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表按树形结构显示全部分类，子分类缩进排在上级分类之后，点击分类前的箭头可以折叠或展开下级分类
+//   - 每行的"移动"按钮弹出可选的上级分类，只列出不会形成环的分类
+//   - 拖动行首的把手可以调整同级分类的顺序，顺序同时用于商品表单中的分类选项，见 category_reorder.go
+//   - 表单中的上级分类按树形结构缩进显示，保存时拒绝选择分类自身或其下级分类
+//   - 还有子分类的分类不能删除，GoAdmin 只提示"删除失败"，具体原因记录在日志中
+func GetCategoriesTable(ctx *context.Context) (categoriesTable table.Table) {
+
+	categoriesTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	tree := models.CategoryTree(ctx.Request.Context())
+
+	info := categoriesTable.GetInfo().HidePagination()
+
+	info.AddField("排序", "sort_order", db.Int).FieldDisplay(categoryDragHandle)
+
+	info.AddField("编号", "id", db.Int)
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike}).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return categoryTreeCell(value)
+		})
+
+	info.AddField("完整路径", "path", db.Varchar)
+
+	// 分类数量通常不多，列表一次取出整棵树，按树的先序排列，不分页
+	// 按名称筛选时只列出匹配的分类，不再缩进，完整路径一栏说明其位置
+	info.SetGetDataFn(func(param parameter.Parameters) ([]map[string]interface{}, int) {
+		keyword := param.GetFieldValue("name")
+		rows := make([]map[string]interface{}, 0, len(tree))
+		for i, n := range tree {
+			if keyword != "" && !strings.Contains(n.Name, keyword) {
+				continue
+			}
+			depth := n.Depth
+			if keyword != "" {
+				depth = 0
+			}
+			hasChildren := i+1 < len(tree) && tree[i+1].ParentID == n.ID
+			rows = append(rows, map[string]interface{}{
+				"id":           n.ID,
+				"parent_id":    n.ParentID,
+				"sort_order":   n.SortOrder,
+				"name":         n.Name,
+				"path":         n.Path,
+				"depth":        depth,
+				"has_children": hasChildren && keyword == "",
+				"tree_path":    categoryIDPath(tree, n),
+				"sortable":     keyword == "",
+			})
+		}
+		return rows, len(rows)
+	})
+
+	info.AddActionButton(ctx, "移动", action.PopUp("/admin/categories/move", "移动到",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id := ctx.FormValue("id")
+			// 弹窗打开时只带 id，显示可选的上级分类；选择后带上 parent_id 再次提交
+			if !ctx.Request.Form.Has("parent_id") {
+				return true, "", categoryMoveForm(models.CategoryTree(ctx.Request.Context()), id)
+			}
+
+			ids := []string{id}
+			before := snapshot(ctx.Request.Context(), "categories", "id", ids)
+			if err := models.MoveCategory(ctx.Request.Context(), id, ctx.FormValue("parent_id")); err != nil {
+				if errors.Is(err, models.ErrCategoryCycle) || errors.Is(err, models.ErrCategoryNotFound) {
+					return false, err.Error(), ""
+				}
+				return false, "移动分类失败: " + err.Error(), ""
+			}
+			auditChange(ctx, "categories", "id", models.AuditUpdate, ids, before)
+			return true, "已移动", ""
+		}))
+
+	info.SetPreDeleteFn(func(ids []string) error {
+		return models.CheckCategoriesDeletable(ctx.Request.Context(), ids)
+	})
+
+	info.AddCSS(categoryTreeCSS + categoryReorderCSS)
+	info.AddJS(categoryTreeJS + categoryReorderJS())
+
+	info.SetTable("categories").SetTitle("商品分类").SetDescription("商品分类")
+
+	formList := categoriesTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("上级分类", "parent_id", db.Int, form.SelectSingle).
+		FieldOptions(append(types.FieldOptions{{Value: "0", Text: "顶级分类"}}, categoryOptions(tree)...)).
+		FieldDefault("0")
+
+	// 上级分类是自身或下级分类时会形成环，环上的分类不再出现在分类树中
+	formList.SetPostValidator(func(values form2.Values) error {
+		if values.IsSingleUpdatePost() {
+			return nil
+		}
+		return models.CheckCategoryParent(ctx.Request.Context(), values.Get("id"), values.Get("parent_id"))
+	})
+
+	formList.SetTable("categories").SetTitle("商品分类").SetDescription("商品分类")
+
+	return
+}
+
+// categoryOptions 将分类树转换为下拉选项，选项文字按层级缩进
+// GoAdmin 没有树形选择控件，用缩进的单选下拉框代替，选项的顺序即树的先序
+func categoryOptions(tree []models.CategoryNode) types.FieldOptions {
+	options := make(types.FieldOptions, 0, len(tree))
+	for _, n := range tree {
+		options = append(options, types.FieldOption{Value: strconv.FormatUint(uint64(n.ID), 10), Text: categoryOptionText(n)})
+	}
+	return options
+}
+
+// categoryOptionText 返回分类在下拉框中的文字
+// 全角空格在 HTML 中不会被合并，下拉框中可以保留缩进
+func categoryOptionText(n models.CategoryNode) string {
+	if n.Depth == 0 {
+		return n.Name
+	}
+	return strings.Repeat("　", n.Depth-1) + "└ " + n.Name
+}
+
+// categoryPath 返回编号为 id 的分类的完整路径，分类不存在时返回空字符串
+func categoryPath(tree []models.CategoryNode, id string) string {
+	for _, n := range tree {
+		if strconv.FormatUint(uint64(n.ID), 10) == id {
+			return n.Path
+		}
+	}
+	return ""
+}
+
+// categoryIDPath 返回从顶级分类到 n 的编号，以 / 连接，如 "1/3/4"，列表的折叠脚本按前缀查找下级分类
+func categoryIDPath(tree []models.CategoryNode, n models.CategoryNode) string {
+	parents := make(map[uint]uint, len(tree))
+	for _, c := range tree {
+		parents[c.ID] = c.ParentID
+	}
+	ids := []string{strconv.FormatUint(uint64(n.ID), 10)}
+	for p := n.ParentID; p != 0; p = parents[p] {
+		ids = append([]string{strconv.FormatUint(uint64(p), 10)}, ids...)
+	}
+	return strings.Join(ids, "/")
+}
+
+// categoryTreeCell 渲染分类树中的名称单元格：按层级缩进，有子分类时在名称前显示折叠按钮
+func categoryTreeCell(value types.FieldModel) template.HTML {
+	depth, _ := value.Row["depth"].(int)
+	hasChildren, _ := value.Row["has_children"].(bool)
+	treePath, _ := value.Row["tree_path"].(string)
+
+	toggle := `<span class="category-toggle-placeholder"></span>`
+	if hasChildren {
+		toggle = `<a href="javascript:;" class="category-toggle"><i class="fa fa-caret-down"></i></a>`
+	}
+	return template.HTML(fmt.Sprintf(`<span class="category-node" data-tree-path="%s" style="padding-left:%dem">%s %s</span>`,
+		html.EscapeString(treePath), depth*2, toggle, html.EscapeString(value.Value)))
+}
+
+// categoryMoveForm 渲染"移动"弹窗的内容：可选的上级分类和确定按钮
+// 分类自身及其下级分类不出现在选项中，选择后向同一个地址提交 id 和 parent_id
+func categoryMoveForm(tree []models.CategoryNode, id string) template.HTML {
+	var current models.CategoryNode
+	for _, n := range tree {
+		if strconv.FormatUint(uint64(n.ID), 10) == id {
+			current = n
+		}
+	}
+
+	var options strings.Builder
+	options.WriteString(`<option value="0">顶级分类</option>`)
+	for _, n := range tree {
+		// 编号路径中含有 id 的是分类自身或其下级分类，与 models.CheckCategoryParent 的判断一致
+		if strings.Contains("/"+categoryIDPath(tree, n)+"/", "/"+id+"/") {
+			continue
+		}
+		selected := ""
+		if n.ID == current.ParentID {
+			selected = " selected"
+		}
+		fmt.Fprintf(&options, `<option value="%d"%s>%s</option>`, n.ID, selected, html.EscapeString(categoryOptionText(n)))
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="form-inline" style="padding:10px">
+  <p>将 <b>%s</b> 移动到：</p>
+  <select class="form-control category-move-parent" style="width:70%%">%s</select>
+  <button type="button" class="btn btn-primary category-move-submit" data-id="%s" data-url="%s">确定</button>
+</div>`, html.EscapeString(current.Path), options.String(), html.EscapeString(id), action.URL("/admin/categories/move")))
+}
+
+// categoryTreeCSS 分类树列表的样式
+const categoryTreeCSS = template.CSS(`
+.category-toggle, .category-toggle-placeholder { display: inline-block; width: 1em; }
+`)
+
+// categoryTreeJS 分类树列表的折叠和"移动"弹窗的提交
+// 折叠时隐藏所有 data-tree-path 以该分类路径开头的行；展开时显示全部下级分类，并将其箭头恢复为展开状态
+const categoryTreeJS = template.JS(`
+$(document).off('click.categoryTree').on('click.categoryTree', '.category-toggle', function () {
+    let node = $(this).closest('.category-node');
+    let prefix = node.data('tree-path') + '/';
+    let icon = $(this).find('i');
+    let collapse = icon.hasClass('fa-caret-down');
+    icon.toggleClass('fa-caret-down', !collapse).toggleClass('fa-caret-right', collapse);
+    $('.category-node').each(function () {
+        if (String($(this).data('tree-path')).indexOf(prefix) !== 0) {
+            return;
+        }
+        $(this).closest('tr').toggle(!collapse);
+        $(this).find('.category-toggle i').removeClass('fa-caret-right').addClass('fa-caret-down');
+    });
+});
+$(document).off('click.categoryMove').on('click.categoryMove', '.category-move-submit', function () {
+    let btn = $(this);
+    $.ajax({
+        method: 'post',
+        url: btn.data('url'),
+        data: {id: btn.data('id'), parent_id: btn.siblings('.category-move-parent').val()},
+        success: function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            if (data.code === 0) {
+                $('.modal').modal('hide');
+                $.pjax.reload('#pjax-container');
+            } else {
+                swal(data.msg, '', 'error');
+            }
+        },
+        error: function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '移动分类失败', '', 'error');
+        }
+    });
+});
+`)
@@ -0,0 +1,171 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现分类列表的拖拽排序：拖动行首的把手调整同级分类的顺序，松开后立即保存
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+	"gorm.io/gorm"
+)
+
+// CategoryReorderURL 保存分类顺序的地址（在后台 URL 前缀下的路径），需要在 main 中注册 ReorderCategories
+const CategoryReorderURL = "/categories/reorder"
+
+// ReorderCategories 保存拖拽后的同级分类顺序
+//
+// 请求格式:
+//
+//	POST parent_id=1&ids=3,2,4（ids 为该上级分类下全部子分类的编号，按新的顺序排列）
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+//
+// 注意事项:
+//   - 需要修改商品分类的权限
+//   - 同级分类在事务中逐个写入，每个分类写入一条"修改"审计日志
+//   - 其他管理员同时增删或移动了同级分类时拒绝保存，见 models.ErrCategoryOrderStale
+func ReorderCategories(ctx *context.Context) {
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/edit/categories"), http.MethodPost, url.Values{}) {
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"code": http.StatusForbidden,
+			"msg":  "没有修改商品分类的权限",
+		})
+		return
+	}
+	parentID := ctx.FormValue("parent_id")
+	if parentID == "" || ctx.FormValue("ids") == "" {
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  "缺少 parent_id 或 ids",
+		})
+		return
+	}
+	ids := strings.Split(ctx.FormValue("ids"), ",")
+
+	before := snapshot(ctx.Request.Context(), "categories", "id", ids)
+	err := models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		return models.ReorderCategories(txCtx, parentID, ids)
+	})
+	if errors.Is(err, models.ErrCategoryOrderStale) {
+		ctx.JSON(http.StatusConflict, map[string]interface{}{
+			"code": http.StatusConflict,
+			"msg":  err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code": http.StatusInternalServerError,
+			"msg":  "保存分类顺序失败: " + err.Error(),
+		})
+		return
+	}
+	auditChange(ctx, "categories", "id", models.AuditUpdate, ids, before)
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+	})
+}
+
+// categoryDragHandle 渲染行首的拖拽把手，记下分类和上级分类的编号
+// 按名称筛选时列表不是树形结构，不显示把手
+func categoryDragHandle(value types.FieldModel) interface{} {
+	if sortable, _ := value.Row["sortable"].(bool); !sortable {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(
+		`<span class="category-drag-handle" draggable="true" data-id="%v" data-parent="%v" title="拖拽排序"><i class="fa fa-arrows-v"></i></span>`,
+		value.Row["id"], value.Row["parent_id"]))
+}
+
+// categoryReorderCSS 拖拽把手和拖动中的行的样式
+const categoryReorderCSS = template.CSS(`
+.category-drag-handle { cursor: move; color: #999; padding: 0 4px; }
+tr.category-dragging { opacity: 0.4; }
+`)
+
+// categoryReorderJS 分类列表的拖拽排序
+// 拖拽使用 HTML5 原生拖放接口，只能放在同一个上级分类的其他分类上，放在目标行的上半部分时排到它之前，否则排到它之后；
+// 松开后按 DOM 顺序收集同级分类的编号并保存，保存后刷新列表，使下级分类回到上级分类之后
+func categoryReorderJS() template.JS {
+	return template.JS(`
+(function () {
+    let dragging = null;
+
+    function row(handle) {
+        return $(handle).closest('tr');
+    }
+    function handleOf(tr) {
+        return $(tr).find('.category-drag-handle');
+    }
+
+    $(document).off('.categoryReorder');
+    $(document).on('dragstart.categoryReorder', '.category-drag-handle', function (e) {
+        dragging = row(this);
+        dragging.addClass('category-dragging');
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', String($(this).data('id')));
+    });
+    $(document).on('dragend.categoryReorder', '.category-drag-handle', function () {
+        if (dragging) {
+            dragging.removeClass('category-dragging');
+        }
+        dragging = null;
+    });
+    $(document).on('dragover.categoryReorder', 'tr', function (e) {
+        let target = handleOf(this);
+        if (dragging && target.length && target.data('parent') === handleOf(dragging).data('parent')) {
+            e.preventDefault();
+        }
+    });
+    $(document).on('drop.categoryReorder', 'tr', function (e) {
+        if (!dragging || dragging[0] === this) {
+            return;
+        }
+        let parent = handleOf(dragging).data('parent');
+        if (handleOf(this).data('parent') !== parent) {
+            return;
+        }
+        e.preventDefault();
+        let rect = this.getBoundingClientRect();
+        if (e.originalEvent.clientY < rect.top + rect.height / 2) {
+            $(this).before(dragging);
+        } else {
+            $(this).after(dragging);
+        }
+
+        let ids = [];
+        $('.category-drag-handle').filter(function () {
+            return $(this).data('parent') === parent;
+        }).each(function () {
+            ids.push($(this).data('id'));
+        });
+        $.ajax({
+            method: 'post',
+            url: '` + config.Url(CategoryReorderURL) + `',
+            data: {parent_id: parent, ids: ids.join(',')},
+            success: function () {
+                $.pjax.reload('#pjax-container');
+            },
+            error: function (data) {
+                swal(data.responseJSON ? data.responseJSON.msg : '保存分类顺序失败', '', 'error');
+                $.pjax.reload('#pjax-container');
+            }
+        });
+    });
+})();
+`)
+}
@@ -0,0 +1,124 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现列表显示列的记忆：管理员在列表右上角的列选择器中选择的列按管理员保存在数据库中，之后打开列表时沿用
+package tables
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
+
+// withColumnPreferences 记住当前管理员在表格列表中选择显示的列
+//
+// 参数:
+//   - ctx: 上下文对象，用于取出当前管理员和请求的查询参数
+//   - t: 表格模型，偏好按数据表名称保存，表格前缀需要与数据表名称相同
+//
+// 功能说明:
+//  1. 列选择器提交后列表页地址带有 __columns 参数，此时把选择的列保存为当前管理员的偏好；
+//     选择了全部列时清空偏好，之后新增的列也会显示
+//  2. 打开列表页或导出 PDF、JSON Lines、XML 时地址中没有 __columns，把保存的列写入请求的查询参数，
+//     GoAdmin 随后按查询参数决定显示的列，分页、排序和筛选的链接也会带上这些列
+//  3. 列表页同时通过 columnPreferenceJS 把 __columns 补到浏览器地址中，列选择器按地址勾选已显示的列
+//
+// 注意事项:
+//   - 保存失败只记录日志，不影响列表的显示
+func withColumnPreferences(ctx *context.Context, t table.Table) {
+	if ctx.Method() != http.MethodGet {
+		return
+	}
+	var (
+		info   = t.GetInfo()
+		name   = info.Table
+		isList = ctx.Path() == config.Url("/info/"+name)
+	)
+	if !isList && ctx.Path() != config.Url(PDFExportURL) && ctx.Path() != config.Url(DataExportURL) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+	query := ctx.Request.URL.Query()
+	if _, ok := query[parameter.Columns]; ok {
+		if isList {
+			columns := selectableColumns(t, parameter.GetParam(ctx.Request.URL, info.DefaultPageSize,
+				info.SortField, info.GetSort()).Columns)
+			if err := models.SaveColumnPreference(ctx.Request.Context(), user.Id, name, columns); err != nil {
+				log.Printf("保存 %s 的显示列失败: %v", name, err)
+			}
+		}
+		return
+	}
+
+	columns := selectableColumns(t, models.GetColumnPreference(ctx.Request.Context(), user.Id, name))
+	if len(columns) == 0 {
+		return
+	}
+	query.Set(parameter.Columns, strings.Join(columns, ","))
+	ctx.Request.URL.RawQuery = query.Encode()
+	if isList {
+		info.AddJS(columnPreferenceJS(strings.Join(columns, ",")))
+	}
+}
+
+// selectableColumns 去掉不在列选择器中的字段（已删除或隐藏的列），
+// 结果为空或包含全部可选的列时返回 nil，表示显示全部列
+func selectableColumns(t table.Table, columns []string) []string {
+	chosen := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		chosen[c] = true
+	}
+
+	var (
+		result []string
+		total  int
+	)
+	for _, field := range t.GetInfo().FieldList {
+		if field.Hide || field.HideForList {
+			continue
+		}
+		// 关联表的字段在 __columns 中的名称带有关联表名，与列选择器的 data-id 一致
+		name := field.Field
+		if field.Joins.Valid() {
+			name = field.Joins.Last().GetTableName() + parameter.FilterParamJoinInfix + field.Field
+		}
+		total++
+		if chosen[name] {
+			result = append(result, name)
+		}
+	}
+	if len(result) == total {
+		return nil
+	}
+	return result
+}
+
+// columnPreferenceJS 把保存的显示列补到浏览器地址的 __columns 参数中并勾选列选择器
+// 主题按地址中的 __columns 勾选列选择器，PDF 导出等按钮也从地址读取当前显示的列
+func columnPreferenceJS(columns string) template.JS {
+	value, _ := json.Marshal(columns)
+	return template.JS(`
+(function () {
+    let columns = ` + string(value) + `;
+    if (getQueryVariable('__columns') === -1) {
+        let search = window.location.search;
+        history.replaceState(history.state, '', window.location.pathname +
+            (search ? search + '&' : '?') + '__columns=' + columns + window.location.hash);
+    }
+    $(function () {
+        let chosen = columns.split(',');
+        $('.column-select-item').each(function () {
+            $(this).iCheck(chosen.indexOf($(this).attr('data-id')) >= 0 ? 'check' : 'uncheck');
+        });
+    });
+})();
+`)
+}
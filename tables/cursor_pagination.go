@@ -0,0 +1,173 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现按主键翻页（游标分页）的表格包装，用于数据量很大的表格
+package tables
+
+import (
+	"html/template"
+	"net/url"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// 游标翻页的查询参数，值为当前页最后一条（下一页）或第一条（上一页）记录的主键
+const (
+	cursorAfter  = "__after"
+	cursorBefore = "__before"
+)
+
+// cursorTable 列表按主键翻页的表格
+// 普通的分页使用 LIMIT ... OFFSET ...，页码越大数据库需要跳过的行越多；
+// 按主键翻页时以上一页边界记录的主键作为条件，每一页都只读取 pageSize 行
+type cursorTable struct {
+	table.Table
+
+	// path 列表页的地址，只有列表页按主键翻页，导出等其他请求仍使用普通的分页
+	path string
+}
+
+// withCursorPagination 让表格列表按主键翻页
+//
+// 参数:
+//   - t: 表格模型，列表页的地址为 /info/{数据表名称}
+//
+// 返回值:
+//   - table.Table: 包装后的表格，只替换列表页的 GetData，表单、详情和导出不受影响
+//
+// 功能说明:
+//   - 按主键排序（默认排序）时，页脚只显示上一页和下一页，翻页链接带有 __after 或 __before 参数
+//   - 按其他字段排序时无法按主键翻页，仍使用普通的分页
+//
+// 注意事项:
+//   - GoAdmin 仍会统计总行数用于显示"共 N 条"，按主键翻页只避免了跳过前面的行
+func withCursorPagination(t table.Table) table.Table {
+	return cursorTable{Table: t, path: config.Url("/info/" + t.GetInfo().Table)}
+}
+
+// largeTable 数据表的行数是否超过系统设置中的 cursor_pagination_threshold，超过时列表应按主键翻页
+func largeTable(ctx *context.Context, name string) bool {
+	threshold := models.Settings.Int(ctx.Request.Context(), models.SettingCursorPaginationThreshold, 0)
+	return threshold > 0 && models.TableRowCount(ctx.Request.Context(), name) > int64(threshold)
+}
+
+// GetData 查询列表数据，列表页按主键排序时按游标读取一页
+func (t cursorTable) GetData(ctx *context.Context, params parameter.Parameters) (table.PanelInfo, error) {
+	var (
+		after  = params.GetFieldValue(cursorAfter)
+		before = params.GetFieldValue(cursorBefore)
+		pk     = t.GetPrimaryKey().Name
+	)
+	params = withoutCursor(params)
+	if ctx.Path() != t.path || params.IsAll() || (params.SortField != "" && params.SortField != pk) {
+		return t.Table.GetData(ctx, params)
+	}
+
+	// 查询条件使用 "<" 还是 ">" 取决于当前的排序方向和翻页方向：
+	// 降序时下一页的主键更小，上一页的主键更大；向前翻页时反向排序读取，再把结果倒过来
+	desc := params.SortType != "asc"
+	cursor, backward := after, false
+	if before != "" {
+		cursor, backward = before, true
+	}
+	if _, err := strconv.ParseInt(cursor, 10, 64); err != nil {
+		cursor = ""
+	}
+
+	var (
+		query = params
+		info  = t.GetInfo()
+		raw   = info.WhereRaws
+	)
+	if cursor != "" {
+		op := "<"
+		if desc == backward {
+			op = ">"
+		}
+		info.WhereRaws = andWhereRaw(raw, info.Table+"."+pk+" "+op+" ?", cursor)
+		if backward {
+			query.SortType = "desc"
+			if desc {
+				query.SortType = "asc"
+			}
+		}
+	}
+	// 多读一行判断后面是否还有数据
+	query.Page, query.PageInt = "1", 1
+	query.PageSizeInt = params.PageSizeInt + 1
+
+	data, err := t.Table.GetData(ctx, query)
+	info.WhereRaws = raw
+	if err != nil {
+		return data, err
+	}
+
+	rows := data.InfoList
+	more := len(rows) > params.PageSizeInt
+	if more {
+		rows = rows[:params.PageSizeInt]
+	}
+	if backward {
+		if !more {
+			// 向前翻到了第一页，按第一页重新读取，保证第一页总是完整的
+			return t.GetData(ctx, params)
+		}
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	data.InfoList = rows
+
+	hasPrev, hasNext := cursor != "", more
+	if backward {
+		hasNext = true
+	}
+	data.Paginator = cursorPaginator(data.Paginator, params, rows, pk, hasPrev, hasNext)
+	return data, nil
+}
+
+// Copy 复制表格，复制后仍按主键翻页
+func (t cursorTable) Copy() table.Table {
+	return cursorTable{Table: t.Table.Copy(), path: t.path}
+}
+
+// cursorPaginator 把 GoAdmin 生成的分页组件改为只有上一页、下一页，保留每页条数的选择和查询耗时
+func cursorPaginator(p types.PaginatorAttribute, params parameter.Parameters, rows types.InfoList, pk string, hasPrev, hasNext bool) types.PaginatorAttribute {
+	p = p.SetPages(nil).
+		SetPreviousClass("disabled").SetPreviousUrl(params.URLPath).
+		SetNextClass("disabled").SetNextUrl(params.URLPath).
+		SetEntriesInfo(template.HTML("数据量较大，按编号翻页"))
+
+	if len(rows) > 0 {
+		if hasPrev {
+			p.SetPreviousClass("").SetPreviousUrl(cursorURL(params, cursorBefore, rows[0][pk].Value))
+		}
+		if hasNext {
+			p.SetNextClass("").SetNextUrl(cursorURL(params, cursorAfter, rows[len(rows)-1][pk].Value))
+		}
+	}
+	return p
+}
+
+// cursorURL 返回带有游标的翻页地址，保留当前的筛选、排序、显示的列和每页条数
+func cursorURL(params parameter.Parameters, key, value string) string {
+	return params.URLPath + params.GetRouteParamStr() + "&" + key + "=" + url.QueryEscape(value) +
+		"&" + form.NoAnimationKey + "=true"
+}
+
+// withoutCursor 从查询参数中去掉游标，其余参数生成的链接（如每页条数）回到第一页
+func withoutCursor(params parameter.Parameters) parameter.Parameters {
+	fields := make(map[string][]string, len(params.Fields))
+	for k, v := range params.Fields {
+		if k != cursorAfter && k != cursorBefore {
+			fields[k] = v
+		}
+	}
+	params.Fields = fields
+	return params
+}
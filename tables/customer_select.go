@@ -0,0 +1,115 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现按输入远程搜索的客户下拉框：不在页面中列出全部客户，输入关键词后分页请求匹配的客户，
+// 用于订单表单的"所属客户"
+package tables
+
+import (
+	"errors"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/template/types"
+	selection "github.com/purpose168/GoAdmin/template/types/form/select"
+	"gorm.io/gorm"
+)
+
+const (
+	// customerSearchMinLength 开始搜索的最少字符数，少于该长度时浏览器不发送请求，接口返回空列表
+	customerSearchMinLength = 2
+
+	// customerSearchPageSize 每页的客户数，滚动到下拉框底部时加载下一页
+	customerSearchPageSize = 20
+
+	// customerSearchDelay 停止输入多久后发送请求，单位为毫秒，避免每输入一个字符请求一次
+	customerSearchDelay = 300
+)
+
+// customerSearch FieldOnSearch 的处理函数，按关键词分页搜索客户
+// 请求参数 search 为关键词，page 为页码；关键词在姓名、邮箱、电话和公司中匹配（见 models.SearchCustomers），
+// 少于 customerSearchMinLength 个字符时返回空列表
+func customerSearch(ctx *context.Context) (bool, string, interface{}) {
+	q := strings.TrimSpace(ctx.Query("search"))
+	page, _ := strconv.Atoi(ctx.Query("page"))
+
+	data := selection.Data{Results: selection.Options{}}
+	if utf8.RuneCountInString(q) < customerSearchMinLength {
+		return true, "ok", data
+	}
+	customers, more, err := models.SearchCustomers(ctx.Request.Context(), q, page, customerSearchPageSize)
+	if err != nil {
+		return false, "搜索客户失败: " + err.Error(), nil
+	}
+	for _, c := range customers {
+		data.Results = append(data.Results, selection.Option{
+			ID:   strconv.FormatUint(uint64(c.ID), 10),
+			Text: customerSelectText(c),
+		})
+	}
+	data.Pagination.More = more
+	return true, "ok", data
+}
+
+// customerSelectText 下拉框中显示的客户名称，有公司时附在姓名后，便于区分同名的客户
+func customerSelectText(c models.Customer) string {
+	if c.Company == "" {
+		return c.Name
+	}
+	return c.Name + "（" + c.Company + "）"
+}
+
+// customerSelectOptions 返回客户下拉框初始的选项：只包含当前选中的客户
+// 没有关联客户时不返回选项；客户已被删除时仍保留编号，避免保存时丢失原来的关联
+func customerSelectOptions(ctx *context.Context) types.OptionInitFn {
+	return func(val types.FieldModel) types.FieldOptions {
+		if val.Value == "" || val.Value == "0" {
+			return types.FieldOptions{}
+		}
+		text := "客户 #" + val.Value + "（已删除）"
+		c, err := models.FindCustomer(ctx.Request.Context(), val.Value)
+		if err == nil {
+			text = customerSelectText(c)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			text = "客户 #" + val.Value
+		}
+		return types.FieldOptions{{Value: val.Value, Text: text, Selected: true}}
+	}
+}
+
+// customerSelectJS 客户下拉框的 select2 配置：可以清空，输入至少 customerSearchMinLength 个字符后搜索
+// 请求的地址和参数由 FieldOnSearch 加在配置后面
+func customerSelectJS() template.JS {
+	return template.JS(`{
+    allowClear: true,
+    placeholder: '输入客户姓名、邮箱、电话或公司搜索',
+    minimumInputLength: ` + strconv.Itoa(customerSearchMinLength) + `,
+    language: {
+        inputTooShort: function () {
+            return '请至少输入 ` + strconv.Itoa(customerSearchMinLength) + ` 个字符';
+        },
+        searching: function () {
+            return '搜索中…';
+        },
+        loadingMore: function () {
+            return '加载更多…';
+        },
+        noResults: function () {
+            return '没有匹配的客户';
+        },
+        errorLoading: function () {
+            return '搜索失败';
+        }
+    }
+}`)
+}
+
+// customerIDFilter 把清空的客户下拉框转换为 0，表示订单未关联客户
+func customerIDFilter(value types.PostFieldModel) interface{} {
+	if v := value.Value.Value(); v != "" {
+		return v
+	}
+	return "0"
+}
@@ -0,0 +1,274 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现客户（customers）表格的模型配置，详情页按标签页显示客户档案、订单记录和备注；开启了通用导入（见 withImport）
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// init 在 Generators 中注册 customers 前缀
+// 访问路径: /admin/info/customers
+// 功能: 客户管理表格，详情页按标签页显示客户档案、订单记录和备注
+func init() {
+	Register("customers", withImport("customers", withAudit(GetCustomersTable)))
+}
+
+// GetCustomersTable 获取客户表格模型
+// 该函数创建并返回客户表格模型，用于管理后台的客户展示和编辑
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表关联 orders 表统计每个客户的订单数
+//   - 每行的"备注"按钮弹窗添加一条备注，备注只能添加，不能修改
+//   - 详情页分为"档案"、"订单记录"和"备注"三个标签页
+//
+// 详情页的实现:
+//   - GoAdmin 的详情页只按字段逐行显示，不支持标签页，这里只配置一个字段，
+//     在 FieldDisplay 中按客户编号读取订单和备注，渲染为标签页组件
+//   - 订单记录关联 order_items 汇总每个订单的件数，订单号链接到订单详情
+func GetCustomersTable(ctx *context.Context) (customersTable table.Table) {
+
+	customersTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := customersTable.GetInfo().SetFilterFormLayout(form.LayoutFilter)
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("姓名", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("邮箱", "email", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("电话", "phone", db.Varchar)
+
+	info.AddField("公司", "company", db.Varchar)
+
+	info.AddField("城市", "city", db.Varchar).FieldFilterable()
+
+	// 关联 orders 后，GoAdmin 按客户分组，把每个订单的编号用分隔符拼接为一个字段，这里计数显示
+	info.AddField("订单数", "id", db.Int).
+		FieldJoin(types.Join{Table: "orders", Field: "id", JoinField: "customer_id"}).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Value == "" {
+				return "0"
+			}
+			return strconv.Itoa(len(strings.Split(value.Value, types.JoinFieldValueDelimiter)))
+		})
+
+	info.AddField("创建时间", "created_at", db.Timestamp).FieldSortable()
+
+	info.AddActionButton(ctx, "备注", action.PopUp("/admin/customers/note", "添加备注",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id := ctx.FormValue("id")
+			// 弹窗打开时只带 id，显示输入框；填写后带上 content 再次提交
+			if !ctx.Request.Form.Has("content") {
+				return true, "", customerNoteForm(id)
+			}
+			err := models.AddCustomerNote(ctx.Request.Context(), id, ctx.FormValue("content"), auth.Auth(ctx).Name)
+			switch {
+			case errors.Is(err, models.ErrEmptyNote):
+				return false, err.Error(), ""
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				return false, "客户不存在", ""
+			case err != nil:
+				return false, "添加备注失败: " + err.Error(), ""
+			}
+			return true, "已添加备注", ""
+		}))
+
+	info.AddJS(customerNoteJS)
+
+	info.SetTable("customers").SetTitle("客户").SetDescription("客户")
+
+	formList := customersTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("姓名", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("邮箱", "email", db.Varchar, form.Email)
+
+	formList.AddField("电话", "phone", db.Varchar, form.Text)
+
+	formList.AddField("公司", "company", db.Varchar, form.Text)
+
+	formList.AddField("城市", "city", db.Varchar, form.Text)
+
+	formList.SetTable("customers").SetTitle("客户").SetDescription("客户")
+
+	detail := customersTable.GetDetail()
+
+	// tabs 不是 customers 表的字段，不参与查询，也不会作为主键写入详情页的隐藏字段
+	detail.AddField("客户", "tabs", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		reqCtx := ctx.Request.Context()
+		c, err := models.FindCustomer(reqCtx, value.ID)
+		if err != nil {
+			return template.HTML(`<p class="text-muted">客户不存在</p>`)
+		}
+		orders := models.CustomerOrders(reqCtx, value.ID)
+		notes := models.CustomerNotes(reqCtx, value.ID)
+		return tmpl.Default().Tabs().SetData([]map[string]template.HTML{
+			{"title": "档案", "content": customerProfile(c)},
+			{"title": template.HTML(fmt.Sprintf("订单记录 (%d)", len(orders))), "content": customerOrdersTable(c, orders)},
+			{"title": template.HTML(fmt.Sprintf("备注 (%d)", len(notes))), "content": customerNotesList(notes)},
+		}).GetContent()
+	})
+
+	detail.SetTable("customers").SetTitle("客户详情").SetDescription("客户")
+
+	return
+}
+
+// customerOptions 将客户转换为下拉选项，订单的筛选和表单共用
+func customerOptions(customers []models.Customer) types.FieldOptions {
+	options := make(types.FieldOptions, 0, len(customers))
+	for _, c := range customers {
+		options = append(options, types.FieldOption{Value: strconv.FormatUint(uint64(c.ID), 10), Text: c.Name})
+	}
+	return options
+}
+
+// customerNameDisplay 返回按客户编号显示客户姓名的 FieldDisplay 函数，未关联客户或客户已被删除时显示为空
+func customerNameDisplay(options types.FieldOptions) types.FieldFilterFn {
+	return func(value types.FieldModel) interface{} {
+		for _, o := range options {
+			if o.Value == value.Value {
+				return o.Text
+			}
+		}
+		return ""
+	}
+}
+
+// customerProfile 渲染"档案"标签页
+func customerProfile(c models.Customer) template.HTML {
+	rows := [][2]string{
+		{"姓名", c.Name},
+		{"邮箱", c.Email},
+		{"电话", c.Phone},
+		{"公司", c.Company},
+		{"城市", c.City},
+		{"创建时间", c.CreatedAt.Format("2006-01-02 15:04:05")},
+	}
+	var b strings.Builder
+	b.WriteString(`<table class="table table-bordered">`)
+	for _, r := range rows {
+		fmt.Fprintf(&b, `<tr><th style="width:120px">%s</th><td>%s</td></tr>`, r[0], html.EscapeString(r[1]))
+	}
+	b.WriteString(`</table>`)
+	return template.HTML(b.String())
+}
+
+// customerOrdersTable 渲染"订单记录"标签页，末尾显示订单金额合计和订单列表的筛选链接
+func customerOrdersTable(c models.Customer, orders []models.CustomerOrder) template.HTML {
+	id := strconv.FormatUint(uint64(c.ID), 10)
+	more := template.HTML(`<p><a href="` + config.Url("/info/orders") + `?customer_id=` + id + `">在订单列表中查看</a></p>`)
+	if len(orders) == 0 {
+		return `<p class="text-muted">暂无订单</p>` + more
+	}
+
+	var (
+		rows  = make([]map[string]types.InfoItem, 0, len(orders)+1)
+		total float64
+	)
+	for _, o := range orders {
+		total += o.Amount
+		rows = append(rows, map[string]types.InfoItem{
+			"订单号": {Content: template.HTML(fmt.Sprintf(`<a href="%s?__goadmin_detail_pk=%d">%s</a>`,
+				config.Url("/info/orders/detail"), o.ID, html.EscapeString(o.OrderNo)))},
+			"商品":   {Content: template.HTML(html.EscapeString(o.Product))},
+			"状态":   {Content: template.HTML(html.EscapeString(o.Status))},
+			"件数":   {Content: template.HTML(strconv.Itoa(o.Quantity))},
+			"金额":   {Content: template.HTML(fmt.Sprintf("¥%.2f", o.Amount))},
+			"下单时间": {Content: template.HTML(o.CreatedAt.Format("2006-01-02 15:04"))},
+		})
+	}
+	rows = append(rows, map[string]types.InfoItem{
+		"订单号": {Content: "<b>合计</b>"},
+		"金额":  {Content: template.HTML(fmt.Sprintf("<b>¥%.2f</b>", total))},
+	})
+
+	return tmpl.Default().Table().SetType("table").SetInfoList(rows).SetThead(types.Thead{
+		{Head: "订单号"},
+		{Head: "商品"},
+		{Head: "状态"},
+		{Head: "件数"},
+		{Head: "金额"},
+		{Head: "下单时间"},
+	}).GetContent() + more
+}
+
+// customerNotesList 渲染"备注"标签页，备注按纯文本显示并保留换行
+func customerNotesList(notes []models.CustomerNote) template.HTML {
+	if len(notes) == 0 {
+		return `<p class="text-muted">暂无备注，可以在客户列表中点击"备注"添加</p>`
+	}
+	var b strings.Builder
+	b.WriteString(`<ul class="list-unstyled">`)
+	for _, n := range notes {
+		fmt.Fprintf(&b, `<li style="margin-bottom:12px"><small class="text-muted">%s · %s</small><br>%s</li>`,
+			html.EscapeString(n.AuthorName), n.CreatedAt.Format("2006-01-02 15:04"),
+			strings.ReplaceAll(html.EscapeString(n.Content), "\n", "<br>"))
+	}
+	b.WriteString(`</ul>`)
+	return template.HTML(b.String())
+}
+
+// customerNoteForm 渲染"备注"弹窗的内容，填写后向同一个地址提交 id 和 content
+func customerNoteForm(id string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<div style="padding:10px">
+  <textarea class="form-control customer-note-content" rows="5" placeholder="备注内容"></textarea>
+  <button type="button" class="btn btn-primary customer-note-submit" style="margin-top:10px" data-id="%s" data-url="%s">保存</button>
+</div>`, html.EscapeString(id), action.URL("/admin/customers/note")))
+}
+
+// customerNoteJS "备注"弹窗的提交
+const customerNoteJS = template.JS(`
+$(document).off('click.customerNote').on('click.customerNote', '.customer-note-submit', function () {
+    let btn = $(this);
+    $.ajax({
+        method: 'post',
+        url: btn.data('url'),
+        data: {id: btn.data('id'), content: btn.siblings('.customer-note-content').val()},
+        success: function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            if (data.code === 0) {
+                $('.modal').modal('hide');
+                swal(data.msg, '', 'success');
+            } else {
+                swal(data.msg, '', 'error');
+            }
+        },
+        error: function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '添加备注失败', '', 'error');
+        }
+    });
+});
+`)
@@ -0,0 +1,232 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现 JSON Lines 和 XML 导出：允许导出的表格在列表"导出"下拉菜单中增加这两种格式，
+// 按列表当前的筛选、排序和显示的列导出，单元格与 PDF 导出一样是列表中显示的文字
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
+
+// DataExportURL 导出 JSON Lines 和 XML 的地址（在后台 URL 前缀下的路径）
+// __prefix 为表格前缀，__format 为导出格式（models.ExportJSONL 或 models.ExportXML），其余查询参数与列表页相同
+const DataExportURL = "/data_export"
+
+// dataExportFormatKey 地址中表示导出格式的查询参数
+const dataExportFormatKey = "__format"
+
+// dataExportMenu "导出"下拉菜单中追加的格式，按顺序显示
+var dataExportMenu = []struct {
+	format string
+	text   string
+}{
+	{models.ExportJSONL, "JSON Lines"},
+	{models.ExportXML, "XML"},
+}
+
+// withDataExport 在允许导出的表格的"导出"下拉菜单中追加 JSON Lines 和 XML
+// 表格没有开启导出（Exportable 为 false）时不添加，与 GoAdmin 自带的 Excel 导出一致
+func withDataExport(prefix string, gen table.Generator) table.Generator {
+	return func(ctx *context.Context) table.Table {
+		t := gen(ctx)
+		if info := t.GetInfo(); !info.IsHideExportButton {
+			info.AddJS(dataExportJS(prefix))
+		}
+		return t
+	}
+}
+
+// dataExportJS 在"导出"下拉菜单末尾添加各格式的菜单项，点击时带上列表页当前的查询参数下载
+func dataExportJS(prefix string) template.JS {
+	var items string
+	for _, m := range dataExportMenu {
+		href := config.Url(DataExportURL) + "?" + url.Values{parameter.Prefix: {prefix}, dataExportFormatKey: {m.format}}.Encode()
+		items += fmt.Sprintf(`<li><a href="#" class="data-export" data-href="%s">%s</a></li>`,
+			html.EscapeString(href), html.EscapeString(m.text))
+	}
+	menu, _ := json.Marshal(`<li role="separator" class="divider"></li>` + items)
+	return template.JS(`
+$(function () {
+    let menu = $('#export-btn-1').closest('ul');
+    if (menu.length && !menu.find('.data-export').length) {
+        menu.append(` + string(menu) + `);
+    }
+});
+$(document).off('click.dataExport').on('click.dataExport', 'a.data-export', function () {
+    let query = window.location.search.replace(/^\?/, '');
+    let href = $(this).data('href');
+    window.location.href = query ? href + '&' + query : href;
+    return false;
+});
+`)
+}
+
+// ExportData 导出表格的 JSON Lines 或 XML 文件
+// 与 ExportPDF 相同，导出列表当前显示的列，按当前的筛选和排序读取全部数据（最多 pdfExportMaxRows 行）
+//
+// 路由: GET /admin/data_export?__prefix={表格前缀}&__format={jsonl|xml}&{列表页的查询参数}
+//
+// 注意事项:
+//   - 只能导出 Generators 中开启了导出的表格，当前管理员需要有该表格列表页的权限
+//   - 单元格导出列表中显示的文字，HTML 标签被去掉；表格设置了 ExportValue 时导出原始值
+func ExportData(ctx *context.Context) {
+	prefix := ctx.Query(parameter.Prefix)
+	gen, ok := Generators[prefix]
+	if !ok {
+		ctx.HTML(http.StatusNotFound, "表格不存在")
+		return
+	}
+	format := ctx.Query(dataExportFormatKey)
+	if format != models.ExportJSONL && format != models.ExportXML {
+		ctx.HTML(http.StatusBadRequest, "不支持的导出格式")
+		return
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/info/"+prefix), http.MethodGet, url.Values{}) {
+		ctx.HTML(http.StatusForbidden, "没有查看该表格的权限")
+		return
+	}
+
+	t := gen(ctx)
+	info := t.GetInfo()
+	if info.IsHideExportButton {
+		ctx.HTML(http.StatusForbidden, "该表格不允许导出")
+		return
+	}
+	data, _, err := loadExportData(ctx, t, ctx.Request.URL)
+	if err != nil {
+		ctx.HTML(http.StatusInternalServerError, "读取数据失败: "+html.EscapeString(err.Error()))
+		return
+	}
+
+	buf, err := EncodeExport(format, data, prefix, nil, info.IsExportValue())
+	if err != nil {
+		ctx.HTML(http.StatusInternalServerError, "生成文件失败: "+html.EscapeString(err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s", data.Title, time.Now().Format("20060102150405"), format)
+	ctx.DataWithHeaders(http.StatusOK, map[string]string{
+		"Content-Type":        exportContentTypes[format],
+		"Content-Disposition": "attachment; filename*=UTF-8''" + url.PathEscape(fileName),
+	}, buf)
+}
+
+// EncodeExport 按文件格式生成导出的文件
+// 列表的 JSON Lines 和 XML 导出、定时导出以及报表页面（pages.ReportsPage）共用
+//
+// 参数:
+//   - format: 文件格式，见 models.ExportCSV 等常量
+//   - data: 列表数据，导出 Thead 中没有隐藏的列
+//   - name: XML 根元素的 name 属性，通常为表格前缀
+//   - summary: PDF 页眉中标题下方的说明，每项一行，其他格式忽略
+//   - exportValue: 为 true 时导出单元格的原始值，否则导出列表中显示的文字
+//
+// 返回值:
+//   - []byte: 文件内容
+//   - error: 格式不支持或生成失败时返回错误
+func EncodeExport(format string, data table.PanelInfo, name string, summary []string, exportValue bool) ([]byte, error) {
+	switch format {
+	case models.ExportCSV:
+		return exportCSV(data, exportValue), nil
+	case models.ExportXLSX:
+		return exportXLSX(data, exportValue)
+	case models.ExportPDF:
+		return renderTablePDF(data, summary, exportValue)
+	case models.ExportJSONL:
+		return exportJSONL(data, exportValue)
+	case models.ExportXML:
+		return exportXML(data, name, exportValue)
+	}
+	return nil, fmt.Errorf("不支持的导出格式: %s", format)
+}
+
+// ExportContentType 返回文件格式的 MIME 类型，格式不支持时返回空字符串
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
+
+// exportJSONL 生成 JSON Lines：每行一个 JSON 对象，键为字段名，按列表中列的顺序排列
+func exportJSONL(data table.PanelInfo, exportValue bool) ([]byte, error) {
+	columns, rows := exportColumns(data), exportTableRows(data, exportValue)
+	var buf bytes.Buffer
+	for _, row := range rows {
+		buf.WriteByte('{')
+		for i, c := range columns {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(c.Field)
+			if err != nil {
+				return nil, err
+			}
+			value, err := json.Marshal(row[i])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// xmlTable XML 导出的根元素
+type xmlTable struct {
+	XMLName xml.Name `xml:"table"`
+	Name    string   `xml:"name,attr"`
+	Title   string   `xml:"title,attr"`
+	Rows    []xmlRow `xml:"row"`
+}
+
+// xmlRow XML 导出中的一行
+type xmlRow struct {
+	Fields []xmlField `xml:"field"`
+}
+
+// xmlField XML 导出中的一个单元格，name 为字段名，head 为列表中的表头
+type xmlField struct {
+	Name  string `xml:"name,attr"`
+	Head  string `xml:"head,attr"`
+	Value string `xml:",chardata"`
+}
+
+// exportXML 生成 XML：根元素 table 下每行一个 row，每个单元格一个 field
+//
+// 输出示例:
+//
+//	<table name="orders" title="订单">
+//	  <row>
+//	    <field name="id" head="编号">1</field>
+//	  </row>
+//	</table>
+func exportXML(data table.PanelInfo, prefix string, exportValue bool) ([]byte, error) {
+	columns, rows := exportColumns(data), exportTableRows(data, exportValue)
+	doc := xmlTable{Name: prefix, Title: data.Title, Rows: make([]xmlRow, len(rows))}
+	for i, row := range rows {
+		doc.Rows[i].Fields = make([]xmlField, len(columns))
+		for j, c := range columns {
+			doc.Rows[i].Fields[j] = xmlField{Name: c.Field, Head: c.Head, Value: row[j]}
+		}
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
@@ -0,0 +1,84 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表单页面提交记录（demo_form_submissions）的只读表格
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 demo_form_submissions 前缀
+// 访问路径: /admin/info/demo_form_submissions
+// 功能: 表单页面（/admin/form）的提交记录
+func init() {
+	Register("demo_form_submissions", GetDemoFormSubmissionsTable)
+}
+
+// GetDemoFormSubmissionsTable 获取表单页面提交记录表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 不能新增和修改，提交记录只由表单页面写入（见 pages.SubmitForm）
+//   - "内容"以"字段名: 值"列出其余字段，"证书"列出上传的文件链接
+func GetDemoFormSubmissionsTable(ctx *context.Context) (demoFormSubmissionsTable table.Table) {
+
+	demoFormSubmissionsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetEditable(false))
+
+	info := demoFormSubmissionsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("id").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+	info.AddField("姓名", "name", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+	info.AddField("邮箱", "email", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("内容", "data", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		return formSubmissionData(value.Value, nil)
+	})
+
+	info.AddField("证书", "certificates", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		return certificateLinks(value.Value)
+	})
+
+	// 姓名列已经使用了 name，提交人显示登录名
+	info.AddField("提交人", "username", db.Varchar).FieldJoin(types.Join{
+		Field:     "user_id",
+		JoinField: "id",
+		Table:     "goadmin_users",
+	})
+
+	info.AddField("提交时间", "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	info.SetTable("demo_form_submissions").SetTitle("表单提交记录").SetDescription("表单页面的提交记录")
+
+	return
+}
+
+// certificateLinks 把证书地址的 JSON 数组渲染为链接列表，每个链接在新窗口中打开
+func certificateLinks(raw string) template.HTML {
+	var urls []string
+	if err := json.Unmarshal([]byte(raw), &urls); err != nil {
+		return template.HTML(template.HTMLEscapeString(raw))
+	}
+	links := make([]string, len(urls))
+	for i, u := range urls {
+		links[i] = fmt.Sprintf(`<a href="%s" target="_blank" rel="noopener">证书 %d</a>`, template.HTMLEscapeString(u), i+1)
+	}
+	return template.HTML(strings.Join(links, "<br>"))
+}
@@ -0,0 +1,105 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现动态表单的生成：按 form_definitions 中保存的字段定义生成表单面板和校验规则
+package tables
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// 以下地址为在后台 URL 前缀下的路径，注册路由和生成链接时经过 config.Url
+const (
+	// DynamicFormURL 填写动态表单的页面，查询参数 id 为表单定义的编号
+	DynamicFormURL = "/forms"
+
+	// DynamicFormSubmitURL 动态表单的提交地址
+	DynamicFormSubmitURL = "/forms/submit"
+)
+
+// dynamicFormTypes 字段类型对应的表单控件和数据类型
+var dynamicFormTypes = map[string]struct {
+	form form.Type
+	db   db.DatabaseType
+}{
+	models.FormFieldText:     {form.Text, db.Varchar},
+	models.FormFieldTextArea: {form.TextArea, db.Text},
+	models.FormFieldNumber:   {form.Number, db.Int},
+	models.FormFieldEmail:    {form.Email, db.Varchar},
+	models.FormFieldDate:     {form.Date, db.Varchar},
+	models.FormFieldSelect:   {form.SelectSingle, db.Varchar},
+	models.FormFieldRadio:    {form.Radio, db.Varchar},
+	models.FormFieldCheckbox: {form.Checkbox, db.Varchar},
+	models.FormFieldSwitch:   {form.Switch, db.Varchar},
+}
+
+// BuildDynamicForm 按字段定义生成表单面板和校验规则
+//
+// 参数:
+//   - fields: 字段定义，通常来自 models.ParseFormFields
+//
+// 返回值:
+//   - *types.FormPanel: 按定义的顺序添加了字段的表单面板
+//   - *FormRules: 字段的校验规则；邮箱和数字字段自动加上 email 和 numeric 规则
+//   - error: 字段类型未知或校验规则无效时返回错误
+//
+// 使用示例:
+//
+//	fields, err := models.ParseFormFields(def.Fields)
+//	panel, rules, err := tables.BuildDynamicForm(fields)
+//	content := components.Form().SetContent(panel.FieldsWithDefaultValue())
+func BuildDynamicForm(fields []models.FormFieldDef) (*types.FormPanel, *FormRules, error) {
+	panel := types.NewFormPanel()
+	rules := NewFormRules()
+	for _, f := range fields {
+		t, ok := dynamicFormTypes[f.Type]
+		if !ok {
+			return nil, nil, fmt.Errorf("字段 %s 的类型 %q 无效", f.Field, f.Type)
+		}
+		panel.AddField(f.Label, f.Field, t.db, t.form)
+
+		if options := f.FieldOptions(); len(options) > 0 {
+			fieldOptions := make(types.FieldOptions, len(options))
+			for i, o := range options {
+				fieldOptions[i] = types.FieldOption{Value: o.Value, Text: o.Text}
+			}
+			panel.FieldOptions(fieldOptions)
+		}
+		if f.Default != "" {
+			panel.FieldDefault(f.Default)
+		}
+		if f.Help != "" {
+			panel.FieldHelpMsg(template.HTML(template.HTMLEscapeString(f.Help)))
+		}
+
+		// 隐含的规则放在前面，regexp 规则必须是最后一条
+		r := f.Rules
+		switch f.Type {
+		case models.FormFieldEmail:
+			r = joinFieldRules("email", r)
+		case models.FormFieldNumber:
+			r = joinFieldRules("numeric", r)
+		}
+		if r == "" {
+			continue
+		}
+		if err := ParseFieldRules(r); err != nil {
+			return nil, nil, fmt.Errorf("字段 %s 的校验规则无效: %v", f.Field, err)
+		}
+		rules.FieldRules(f.Field, f.Label, r)
+	}
+	rules.MarkRequired(panel)
+	return panel, rules, nil
+}
+
+// joinFieldRules 把规则 a 加在规则 b 之前
+func joinFieldRules(a, b string) string {
+	if b == "" {
+		return a
+	}
+	return a + "|" + b
+}
@@ -0,0 +1,110 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现定时导出执行记录（export_runs）的只读表格，按时执行和手动执行各对应一行
+package tables
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 export_runs 前缀
+// 访问路径: /admin/info/export_runs
+// 功能: 定时导出的执行记录，定时导出列表中的"执行记录"按定时导出筛选后打开
+func init() {
+	Register("export_runs", GetExportRunsTable)
+}
+
+// GetExportRunsTable 获取定时导出执行记录表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 不能新增、修改和删除，执行记录只由调度写入（见 StartExportScheduler）
+//   - 失败的记录在"失败原因"中显示生成文件或发送邮件时的错误
+func GetExportRunsTable(ctx *context.Context) (exportRunsTable table.Table) {
+
+	exportRunsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetEditable(false).SetDeletable(false))
+
+	info := exportRunsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("id").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("定时导出", "schedule_id", db.Int).
+		FieldFilterable().
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return template.HTML(fmt.Sprintf(`<a href="%s?__goadmin_edit_pk=%s">#%s</a>`,
+				config.Url("/info/export_schedules/edit"), value.Value, value.Value))
+		})
+
+	info.AddField("开始时间", "started_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	info.AddField("状态", "status", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(exportRunStatusOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return exportRunStatusLabel(value.Value)
+		})
+
+	info.AddField("方式", "manual", db.Tinyint).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Value == "1" || value.Value == "true" {
+				return "手动"
+			}
+			return "按时"
+		})
+
+	info.AddField("文件", "file_name", db.Varchar)
+
+	info.AddField("行数", "row_count", db.Int)
+
+	info.AddField("收件人", "recipients", db.Varchar)
+
+	info.AddField("失败原因", "error", db.Varchar)
+
+	info.AddField("结束时间", "finished_at", db.Timestamp)
+
+	info.SetTable("export_runs").SetTitle("执行记录").SetDescription("定时导出每次生成文件和发送邮件的结果")
+
+	return
+}
+
+// exportRunStatusOptions 执行状态的选项
+var exportRunStatusOptions = types.FieldOptions{
+	{Value: models.ExportRunRunning, Text: "执行中"},
+	{Value: models.ExportRunSuccess, Text: "已发送"},
+	{Value: models.ExportRunFailed, Text: "失败"},
+}
+
+// exportRunStatusColors 各执行状态在列表中的标签颜色
+var exportRunStatusColors = map[string]string{
+	models.ExportRunRunning: "info",
+	models.ExportRunSuccess: "success",
+	models.ExportRunFailed:  "danger",
+}
+
+// exportRunStatusLabel 将执行状态渲染为带颜色的标签
+func exportRunStatusLabel(status string) template.HTML {
+	for _, o := range exportRunStatusOptions {
+		if o.Value == status {
+			return template.HTML(fmt.Sprintf(`<span class="label label-%s">%s</span>`, exportRunStatusColors[status], o.Text))
+		}
+	}
+	return template.HTML(template.HTMLEscapeString(status))
+}
@@ -0,0 +1,576 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现定时导出：按 cron 表达式定期导出一个表格（筛选和排序与列表页的地址相同），
+// 生成 CSV、XLSX、PDF、JSON Lines 或 XML 文件后通过邮件发给收件人，每次执行记入执行记录（export_runs）
+package tables
+
+import (
+	"bytes"
+	stdctx "context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	adminModels "github.com/purpose168/GoAdmin/plugins/admin/models"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+)
+
+// exportMailer 发送定时导出邮件的发送器，由 LoadMailConfigFromYAML 创建
+// 未配置邮件服务器时每次执行都记为失败，执行记录中注明原因
+var exportMailer *models.Mailer
+
+// exportConn GoAdmin 的数据库连接，执行定时导出时用它读取创建人的角色和权限，由 StartExportScheduler 设置
+var exportConn db.Connection
+
+// exportFormats 定时导出的文件格式
+var exportFormats = types.FieldOptions{
+	{Value: models.ExportCSV, Text: "CSV"},
+	{Value: models.ExportXLSX, Text: "Excel（XLSX）"},
+	{Value: models.ExportPDF, Text: "PDF"},
+	{Value: models.ExportJSONL, Text: "JSON Lines"},
+	{Value: models.ExportXML, Text: "XML"},
+}
+
+// exportContentTypes 各文件格式的 MIME 类型，用作邮件附件和下载文件的类型
+var exportContentTypes = map[string]string{
+	models.ExportCSV:   "text/csv",
+	models.ExportXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	models.ExportPDF:   "application/pdf",
+	models.ExportJSONL: "application/x-ndjson",
+	models.ExportXML:   "application/xml",
+}
+
+// LoadMailConfigFromYAML 从 YAML 配置文件的 mail 配置项读取发送邮件的 SMTP 服务器
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析配置失败，或发件人地址无效时返回错误
+//
+// 配置示例:
+//
+//	mail:
+//	  host: smtp.example.com
+//	  port: 587
+//	  username: noreply@example.com
+//	  password: xxx
+//	  from: GoAdmin <noreply@example.com>
+func LoadMailConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		Mail models.MailConfig `yaml:"mail"`
+	}{Mail: models.DefaultMailConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析邮件配置失败: %v", err)
+	}
+	if cfg.Mail.Host == "" {
+		log.Println("未配置 mail.host，定时导出无法发送邮件")
+	}
+
+	mailer, err := models.NewMailer(cfg.Mail)
+	if err != nil {
+		return err
+	}
+	exportMailer = mailer
+	return nil
+}
+
+// init 在 Generators 中注册 export_schedules 前缀
+// 访问路径: /admin/info/export_schedules
+// 功能: 定时导出的配置，每行可以手动执行或查看执行记录
+func init() {
+	Register("export_schedules", withAudit(GetExportSchedulesTable))
+}
+
+// GetExportSchedulesTable 获取定时导出表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 筛选条件填写列表页地址中 ? 之后的部分，也可以直接粘贴列表页的完整地址
+//   - 导出时以创建人的身份和权限读取表格，非超级管理员只能看到和修改自己创建的定时导出（见 withOwnership）
+//   - "立即执行"在后台导出并发送一次，不影响按时执行的时间
+func GetExportSchedulesTable(ctx *context.Context) (exportSchedulesTable table.Table) {
+
+	exportSchedulesTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := exportSchedulesTable.GetInfo().SetSortField("id").SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("表格", "prefix", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(exportPrefixOptions())
+
+	info.AddField("格式", "format", db.Varchar).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return exportFormatText(value.Value)
+		})
+
+	info.AddField("执行时间", "cron", db.Varchar).
+		FieldDisplay(exportScheduleNextRun)
+
+	info.AddField("收件人", "recipients", db.Varchar)
+
+	info.AddField("启用", "enabled", db.Tinyint).FieldBool("1", "0")
+
+	info.AddField("最近执行", "last_run_at", db.Datetime)
+
+	info.AddField("修改时间", "updated_at", db.Datetime).FieldHide()
+
+	info.AddActionButton(ctx, "立即执行", action.Ajax("/admin/export_schedules/run",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id := ctx.FormValue("id")
+			if err := checkOwner(ctx, "export_schedules", []string{id}); err != nil {
+				return false, err.Error(), ""
+			}
+			s, err := models.FindExportSchedule(ctx.Request.Context(), id)
+			if err != nil {
+				return false, "读取定时导出失败: " + err.Error(), ""
+			}
+			models.RunWorker(func() { runExportSchedule(s, true) })
+			return true, "已开始执行，结果见执行记录", ""
+		}))
+
+	info.AddActionButton(ctx, "执行记录", action.Jump(config.Url("/info/export_runs")+"?schedule_id={%id}"))
+
+	info.SetTable("export_schedules").SetTitle("定时导出").SetDescription("按时导出表格并通过邮件发送")
+
+	formList := exportSchedulesTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust().
+		FieldHelpMsg("用作邮件主题和附件的文件名")
+
+	formList.AddField("表格", "prefix", db.Varchar, form.SelectSingle).
+		FieldOptions(exportPrefixOptions()).FieldMust()
+
+	formList.AddField("筛选条件", "query", db.Varchar, form.Text).
+		FieldHelpMsg("在列表页设置好筛选和排序后，粘贴浏览器地址栏中的地址；留空时导出全部数据")
+
+	formList.AddField("格式", "format", db.Varchar, form.SelectSingle).
+		FieldOptions(exportFormats).FieldDefault(models.ExportCSV).FieldMust()
+
+	formList.AddField("执行时间", "cron", db.Varchar, form.Text).FieldMust().
+		FieldHelpMsg("cron 表达式：分 时 日 月 星期，如 0 8 * * 1 表示每周一 8:00；也可以填写 @daily、@weekly、@monthly")
+
+	formList.AddField("收件人", "recipients", db.Varchar, form.TextArea).FieldMust().
+		FieldHelpMsg("邮件地址，多个地址用逗号或换行分隔")
+
+	formList.AddField("启用", "enabled", db.Tinyint, form.Switch).
+		FieldOptions(types.FieldOptions{
+			{Value: "1", Text: "是"},
+			{Value: "0", Text: "否"},
+		}).FieldDefault("1")
+
+	formList.SetTable("export_schedules").SetTitle("定时导出").SetDescription("按时导出表格并通过邮件发送")
+
+	formList.SetPostValidator(func(values form2.Values) error {
+		return validateExportSchedule(ctx, values)
+	})
+
+	// 筛选条件可以粘贴完整的地址，只保存 ? 之后的部分
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if q := values.Get("query"); strings.Contains(q, "?") {
+			values["query"] = []string{q[strings.Index(q, "?")+1:]}
+		}
+		return values
+	})
+
+	// 表单通过 models.SaveRow 写入，在同一个事务中补上创建和修改时间，修改时间决定下一次执行的时间
+	withTxPostHook(ctx, exportSchedulesTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		return models.TouchExportSchedule(ctx, id)
+	})
+
+	// 创建人决定导出时使用的权限，由 withOwnership 在新增时设置，不能修改
+	withOwnership(ctx, exportSchedulesTable)
+
+	return
+}
+
+// validateExportSchedule 校验定时导出的表单
+// 当前管理员需要有导出表格的查看权限，否则可以借定时导出读取没有权限的数据
+func validateExportSchedule(ctx *context.Context, values form2.Values) error {
+	prefix := values.Get("prefix")
+	if _, ok := Generators[prefix]; !ok {
+		return fmt.Errorf("表格 %s 不存在", prefix)
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/info/"+prefix), http.MethodGet, url.Values{}) {
+		return errors.New("没有查看该表格的权限")
+	}
+	if _, ok := exportContentTypes[values.Get("format")]; !ok {
+		return errors.New("文件格式无效")
+	}
+	if _, err := models.ParseCron(values.Get("cron")); err != nil {
+		return err
+	}
+	q := values.Get("query")
+	if i := strings.Index(q, "?"); i >= 0 {
+		q = q[i+1:]
+	}
+	if _, err := url.ParseQuery(q); err != nil {
+		return fmt.Errorf("筛选条件无效: %v", err)
+	}
+	to, err := models.ParseMailAddresses(values.Get("recipients"))
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return errors.New("收件人不能为空")
+	}
+	return nil
+}
+
+// exportPrefixOptions 可以导出的表格，即 Generators 中的全部前缀
+func exportPrefixOptions() types.FieldOptions {
+	prefixes := make([]string, 0, len(Generators))
+	for p := range Generators {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	options := make(types.FieldOptions, 0, len(prefixes))
+	for _, p := range prefixes {
+		options = append(options, types.FieldOption{Value: p, Text: p})
+	}
+	return options
+}
+
+// exportFormatText 文件格式的显示文字，未知的格式原样显示
+func exportFormatText(format string) string {
+	for _, o := range exportFormats {
+		if o.Value == format {
+			return o.Text
+		}
+	}
+	return format
+}
+
+// exportScheduleNextRun 在 cron 表达式下方显示下一次执行的时间，停用或表达式无效时注明
+func exportScheduleNextRun(value types.FieldModel) interface{} {
+	s := models.ExportSchedule{Cron: value.Value}
+	s.UpdatedAt, _ = exportRowTime(value.Row["updated_at"])
+	if t, ok := exportRowTime(value.Row["last_run_at"]); ok {
+		s.LastRunAt = &t
+	}
+
+	var note string
+	next, err := s.NextRun()
+	switch {
+	case err != nil:
+		note = `<span class="text-red">` + html.EscapeString(err.Error()) + `</span>`
+	case fmt.Sprint(value.Row["enabled"]) != "1":
+		note = "已停用"
+	case next.IsZero():
+		note = "不会执行"
+	default:
+		note = "下次：" + next.Format("2006-01-02 15:04")
+	}
+	return template.HTML(fmt.Sprintf(`<code>%s</code><br><small class="text-muted">%s</small>`, html.EscapeString(value.Value), note))
+}
+
+// exportRowLayouts 列表行中时间字段可能的格式：SQLite 驱动保存的带时区格式和 MySQL 等返回的格式
+var exportRowLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// exportRowTime 解析列表行中的时间字段，字段为空或格式无法识别时返回 false
+func exportRowTime(v interface{}) (time.Time, bool) {
+	if t, ok := v.(time.Time); ok {
+		return t, true
+	}
+	for _, l := range exportRowLayouts {
+		if t, err := time.ParseInLocation(l, fmt.Sprint(v), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// StartExportScheduler 启动定时导出的调度
+//
+// 参数:
+//   - ctx: 取消后停止调度，正在执行的导出会执行完
+//   - conn: GoAdmin 的数据库连接，用于读取创建人的角色和权限
+//   - interval: 检查的间隔，cron 表达式精确到分钟，通常为 time.Minute
+//
+// 注意事项:
+//   - 必须在 models.Init 之后调用
+//   - 到期的定时导出依次执行，先通过 models.ClaimExportSchedule 认领，多个实例同时运行时只有一个实例发送
+func StartExportScheduler(ctx stdctx.Context, conn db.Connection, interval time.Duration) {
+	exportConn = conn
+	models.RunWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				runDueExports(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// runDueExports 执行到期的定时导出
+func runDueExports(now time.Time) {
+	bg := stdctx.Background()
+	due, err := models.DueExportSchedules(bg, now)
+	if err != nil {
+		log.Printf("读取到期的定时导出失败: %s\n", err)
+		return
+	}
+	for _, s := range due {
+		claimed, err := models.ClaimExportSchedule(bg, s.ID, now)
+		if err != nil {
+			log.Printf("认领定时导出 %d 失败: %s\n", s.ID, err)
+			continue
+		}
+		if claimed {
+			runExportSchedule(s, false)
+		}
+	}
+}
+
+// runExportSchedule 执行一次定时导出：生成文件、发送邮件，结果写入执行记录
+// 失败时只记录在执行记录中，不重试，下一次按时执行时重新生成
+func runExportSchedule(s models.ExportSchedule, manual bool) {
+	bg := stdctx.Background()
+	run := models.ExportRun{ScheduleID: s.ID, Recipients: s.Recipients, Manual: manual}
+	if err := models.CreateExportRun(bg, &run); err != nil {
+		log.Printf("写入定时导出 %d 的执行记录失败: %s\n", s.ID, err)
+		return
+	}
+
+	var (
+		file      models.MailAttachment
+		rows      int
+		truncated bool
+		err       error
+	)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		if ferr := models.FinishExportRun(bg, run.ID, file.Name, rows, err); ferr != nil {
+			log.Printf("更新定时导出 %d 的执行记录失败: %s\n", s.ID, ferr)
+		}
+	}()
+
+	file, rows, truncated, err = exportScheduleFile(s)
+	if err != nil {
+		return
+	}
+	to, err := models.ParseMailAddresses(s.Recipients)
+	if err != nil {
+		return
+	}
+	var note string
+	if truncated {
+		note = fmt.Sprintf("\n注意：PDF 最多导出 %d 行，超出的数据没有包含在附件中，需要全部数据时请改用 CSV 等格式。", pdfExportMaxRows)
+	}
+	err = exportMailer.Send(models.Mail{
+		To:      to,
+		Subject: s.Name,
+		Body: fmt.Sprintf("%s\n\n表格：%s\n行数：%d\n生成时间：%s\n%s\n本邮件由定时导出自动发送，附件为导出的文件。",
+			s.Name, s.Prefix, rows, time.Now().Format("2006-01-02 15:04:05"), note),
+		Attachments: []models.MailAttachment{file},
+	})
+}
+
+// exportScheduleFile 以创建人的身份读取表格并生成文件
+//
+// 返回值:
+//   - models.MailAttachment: 生成的文件
+//   - int: 导出的行数
+//   - bool: PDF 的数据超过 pdfExportMaxRows 行、只导出了前 pdfExportMaxRows 行时为 true
+//   - error: 表格不存在、创建人已被删除或没有查看权限、读取数据或生成文件失败时返回错误
+func exportScheduleFile(s models.ExportSchedule) (models.MailAttachment, int, bool, error) {
+	var file models.MailAttachment
+	gen, ok := Generators[s.Prefix]
+	if !ok {
+		return file, 0, false, fmt.Errorf("表格 %s 不存在", s.Prefix)
+	}
+
+	user := adminModels.User().SetConn(exportConn).Find(s.CreatedBy)
+	if user.IsEmpty() {
+		return file, 0, false, errors.New("创建人已被删除")
+	}
+	user = user.WithRoles().WithPermissions()
+	path := config.Url("/info/" + s.Prefix)
+	if !user.CheckPermissionByUrlMethod(path, http.MethodGet, url.Values{}) {
+		return file, 0, false, errors.New("创建人没有查看该表格的权限")
+	}
+
+	// 表格按请求生成，这里构造一个与列表页相同地址的请求，当前管理员为创建人
+	req, err := http.NewRequest(http.MethodGet, path+"?"+s.Query, nil)
+	if err != nil {
+		return file, 0, false, fmt.Errorf("筛选条件无效: %v", err)
+	}
+	ctx := context.NewContext(req)
+	ctx.SetUserValue("user", user)
+
+	t := gen(ctx)
+	data, params, truncated, err := loadScheduleExportData(ctx, t, req.URL, s.Format)
+	if err != nil {
+		return file, 0, false, fmt.Errorf("读取数据失败: %v", err)
+	}
+	exportValue := t.GetInfo().IsExportValue()
+
+	file.Data, err = EncodeExport(s.Format, data, s.Prefix, pdfSummary(data, params), exportValue)
+	if err != nil {
+		return file, 0, false, fmt.Errorf("生成文件失败: %v", err)
+	}
+	file.Name = fmt.Sprintf("%s-%s.%s", s.Name, time.Now().Format("20060102150405"), s.Format)
+	file.ContentType = exportContentTypes[s.Format]
+	return file, len(data.InfoList), truncated, nil
+}
+
+// loadScheduleExportData 按地址中的筛选和排序读取定时导出的数据
+// 每次读取 pdfExportMaxRows 行，直到读完全部数据；PDF 与列表页的导出一样最多 pdfExportMaxRows 行，
+// 多读一页用来判断是否还有更多数据，超出的部分不导出
+//
+// 返回值:
+//   - table.PanelInfo: 表格数据，InfoList 为读取到的全部行
+//   - parameter.Parameters: 读取时使用的查询参数，用于生成 PDF 页眉
+//   - bool: PDF 的数据被截断时为 true
+//   - error: 读取数据失败时返回错误
+func loadScheduleExportData(ctx *context.Context, t table.Table, u *url.URL, format string) (table.PanelInfo, parameter.Parameters, bool, error) {
+	info := t.GetInfo()
+	params := parameter.GetParam(u, info.DefaultPageSize, info.SortField, info.GetSort())
+	params.PageSize, params.PageSizeInt = strconv.Itoa(pdfExportMaxRows), pdfExportMaxRows
+
+	var data table.PanelInfo
+	for page := 1; ; page++ {
+		params.Page, params.PageInt = strconv.Itoa(page), page
+		pageData, err := t.GetData(ctx, params.WithIsAll(false))
+		if err != nil {
+			return data, params, false, err
+		}
+		if page == 1 {
+			data = pageData
+		} else {
+			data.InfoList = append(data.InfoList, pageData.InfoList...)
+		}
+
+		if format == models.ExportPDF && len(data.InfoList) > pdfExportMaxRows {
+			data.InfoList = data.InfoList[:pdfExportMaxRows]
+			return data, params, true, nil
+		}
+		if len(pageData.InfoList) < pdfExportMaxRows {
+			return data, params, false, nil
+		}
+	}
+}
+
+// exportColumns 返回列表中显示的列
+func exportColumns(data table.PanelInfo) types.Thead {
+	columns := make(types.Thead, 0, len(data.Thead))
+	for _, h := range data.Thead {
+		if !h.Hide {
+			columns = append(columns, h)
+		}
+	}
+	return columns
+}
+
+// exportHeads 返回列表中显示的列的表头
+func exportHeads(data table.PanelInfo) []string {
+	columns := exportColumns(data)
+	heads := make([]string, len(columns))
+	for i, c := range columns {
+		heads[i] = c.Head
+	}
+	return heads
+}
+
+// exportTableRows 返回每一行中显示的列的文字，顺序与 exportColumns 相同，单元格的文字与 PDF 导出相同
+func exportTableRows(data table.PanelInfo, exportValue bool) [][]string {
+	columns := exportColumns(data)
+	rows := make([][]string, len(data.InfoList))
+	for i, item := range data.InfoList {
+		rows[i] = make([]string, len(columns))
+		for j, c := range columns {
+			rows[i][j] = pdfCellText(item[c.Field], exportValue)
+		}
+	}
+	return rows
+}
+
+// exportCSV 生成带 BOM 的 CSV，Excel 打开时按 UTF-8 识别中文
+func exportCSV(data table.PanelInfo, exportValue bool) []byte {
+	heads, rows := exportHeads(data), exportTableRows(data, exportValue)
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+	w := csv.NewWriter(&buf)
+	_ = w.Write(escapeCSVRow(heads))
+	for _, row := range rows {
+		_ = w.Write(escapeCSVRow(row))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// escapeCSVRow 对一行的每个单元格调用 models.EscapeCSVFormula，防止导出的数据在 Excel 中被当作公式执行
+func escapeCSVRow(row []string) []string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = models.EscapeCSVFormula(v)
+	}
+	return cells
+}
+
+// exportXLSX 生成只有一个工作表的 Excel 工作簿，第一行为表头
+func exportXLSX(data table.PanelInfo, exportValue bool) ([]byte, error) {
+	heads, rows := exportHeads(data), exportTableRows(data, exportValue)
+	book := excelize.NewFile()
+	sheet := book.GetSheetName(1)
+	book.SetSheetRow(sheet, "A1", &heads)
+	for i := range rows {
+		book.SetSheetRow(sheet, "A"+strconv.Itoa(i+2), &rows[i])
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,15 +1,158 @@
 // Package tables 提供数据库表格模型定义
-// 本文件实现外部数据源表格的模型配置，演示如何从非数据库来源获取数据
+// 本文件实现外部数据源表格的模型配置，演示如何从非数据库来源获取和写入数据
 package tables
 
 import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
 	"github.com/purpose168/GoAdmin/context"
 	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
 	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
 	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
 	"github.com/purpose168/GoAdmin/template/types/form"
+	"gopkg.in/yaml.v2"
 )
 
+// externalAPI 外部数据表格的远程接口，由 LoadExternalConfigFromYAML 创建；
+// 未配置 external.url 时为 nil，表格显示内置的示例数据
+var externalAPI *models.ExternalAPI
+
+// externalFilterFields 外部数据表格中可筛选的字段，筛选条件以字段名作为查询参数转发给远程接口
+var externalFilterFields = []string{"id", "title"}
+
+// LoadExternalConfigFromYAML 从 YAML 配置文件的 external 配置项读取外部数据表格的远程接口
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析配置失败，或 url 不是 http(s) 地址时返回错误
+//
+// 配置示例:
+//
+//	external:
+//	  url: https://api.example.com/articles
+//	  timeout: 5s
+//	  headers:
+//	    Authorization: Bearer xxx
+//	  list_path: data.items
+//	  total_path: data.total
+func LoadExternalConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		External models.ExternalAPIConfig `yaml:"external"`
+	}{External: models.DefaultExternalAPIConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析外部数据配置失败: %v", err)
+	}
+	if cfg.External.URL == "" {
+		externalAPI = nil
+		return nil
+	}
+
+	api, err := models.NewExternalAPI(cfg.External)
+	if err != nil {
+		return err
+	}
+	externalAPI = api
+	return nil
+}
+
+// externalList 把列表的分页、排序和筛选转发给远程接口，请求失败时记录日志并显示空列表
+// 成功时在列表顶部显示数据的获取时间，读取缓存时可以看出数据已经缓存了多久
+func externalList(ctx stdctx.Context, info *types.InfoPanel, param parameter.Parameters) ([]map[string]interface{}, int) {
+	query := models.ExternalQuery{
+		Page:      param.PageInt,
+		PageSize:  param.PageSizeInt,
+		SortField: param.SortField,
+		SortType:  param.SortType,
+		Filters:   make(map[string]string),
+	}
+	for _, field := range externalFilterFields {
+		if v := param.GetFieldValue(field); v != "" {
+			query.Filters[field] = v
+		}
+	}
+
+	page, err := externalAPI.List(ctx, query)
+	if err != nil {
+		log.Printf("获取外部数据失败: %v", err)
+		return nil, 0
+	}
+	info.SetHeaderHtml(info.HeaderHtml + externalUpdatedBadge(page.FetchedAt))
+	return page.Rows, page.Total
+}
+
+// externalUpdatedBadge 显示数据获取时间的标签，如"数据更新于 12 秒前"
+func externalUpdatedBadge(fetchedAt time.Time) template.HTML {
+	return template.HTML(fmt.Sprintf(`<span class="label label-default pull-left" style="margin:8px 10px 0 0" title="%s">数据更新于 %d 秒前</span>`,
+		fetchedAt.Format("2006-01-02 15:04:05"), int(time.Since(fetchedAt).Seconds())))
+}
+
+// externalRefreshJS "刷新"按钮的回调，清除缓存后重新加载列表
+const externalRefreshJS = template.JS(`if (data.code === 0) {
+    $.pjax.reload('#pjax-container');
+} else {
+    swal(data.msg, '', 'error');
+}`)
+
+// externalDetail 按编号从远程接口获取一条记录，请求失败或记录不存在时返回空结果
+func externalDetail(ctx stdctx.Context, param parameter.Parameters) ([]map[string]interface{}, int) {
+	row, err := externalAPI.Get(ctx, param.PK())
+	if err != nil {
+		log.Printf("获取外部数据 %s 失败: %v", param.PK(), err)
+		return nil, 0
+	}
+	return []map[string]interface{}{row}, 1
+}
+
+// errExternalReadOnly 未配置远程接口时，内置的示例数据不能修改
+var errExternalReadOnly = errors.New("未配置外部数据接口（external.url），示例数据不能修改")
+
+// externalRow 把表单提交的值转换为发送给远程接口的记录
+// 包含表单中的全部字段，留空的字段为空字符串，由远程接口校验；主键放在地址中，不在请求体中重复
+func externalRow(f *types.FormPanel, values form2.Values) map[string]interface{} {
+	row := make(map[string]interface{})
+	for _, field := range f.FieldList {
+		if field.Field == "id" {
+			continue
+		}
+		row[field.Field] = values.Get(field.Field)
+	}
+	return row
+}
+
+// externalFormError 把远程接口的校验错误中的字段名换成表单中的字段名称，其他错误原样返回
+func externalFormError(f *types.FormPanel, err error) error {
+	var invalid *models.ExternalValidationError
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	named := &models.ExternalValidationError{Message: invalid.Message, Fields: make(map[string][]string, len(invalid.Fields))}
+	for field, msgs := range invalid.Fields {
+		if head := f.FieldList.FindByFieldName(field); head != nil {
+			field = head.Head
+		}
+		named.Fields[field] = msgs
+	}
+	return named
+}
+
 // GetExternalTable 获取外部数据源表格模型
 // 该函数创建并返回一个从外部数据源获取数据的表格模型
 //
@@ -59,7 +202,8 @@ func GetExternalTable(ctx *context.Context) (externalTable table.Table) {
 	//   - "Title": 字段显示名称
 	//   - "title": 数据字段名（对应外部数据中的键名）
 	//   - db.Varchar: 字段数据类型（可变长字符串）
-	info.AddField("标题", "title", db.Varchar)
+	// FieldFilterable: 设置该字段可筛选，筛选条件转发给远程接口
+	info.AddField("标题", "title", db.Varchar).FieldFilterable()
 
 	// 设置表格基本信息和数据获取函数
 	// SetTable: 指定表名标识符（用于路由和权限控制，不对应真实数据库表）
@@ -71,14 +215,22 @@ func GetExternalTable(ctx *context.Context) (externalTable table.Table) {
 	//     - []map[string]interface{}: 数据列表，每个 map 代表一行数据
 	//     - int: 总记录数（用于分页计算）
 	//
-	// 在实际应用中，这里可以调用 API、查询缓存或执行其他数据获取逻辑
+	// 配置了 external.url 时由 externalList 调用远程接口，使用请求的上下文，客户端断开后请求随之取消
+	reqCtx := ctx.Request.Context()
 	info.SetTable("external").
 		SetTitle("外部数据").
 		SetDescription("外部数据").
 		SetGetDataFn(func(param parameter.Parameters) ([]map[string]interface{}, int) {
-			// 返回模拟的外部数据
-			// 在实际应用中，这里应该调用外部 API 或其他数据源
-			// 例如: api.GetExternalData(param.Page, param.PageSize, param.SortField)
+			// 客户端已经断开时不再获取数据
+			if reqCtx.Err() != nil {
+				return nil, 0
+			}
+
+			if externalAPI != nil {
+				return externalList(reqCtx, info, param)
+			}
+
+			// 未配置远程接口时返回模拟的外部数据
 			return []map[string]interface{}{
 				{
 					"id":    10,
@@ -96,6 +248,16 @@ func GetExternalTable(ctx *context.Context) (externalTable table.Table) {
 			}, 10 // 总记录数，用于分页计算
 		})
 
+	// 添加刷新按钮
+	// 列表数据按查询参数缓存 external.cache_ttl，点击后清除缓存并重新请求远程接口
+	info.AddButton(ctx, "刷新", icon.Refresh, action.Ajax("/admin/external/refresh",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			if externalAPI != nil {
+				externalAPI.ClearCache()
+			}
+			return true, "", ""
+		}).SetSuccessJS(externalRefreshJS))
+
 	// 获取表单配置对象
 	// GetForm 返回表格的表单配置器，用于配置编辑/添加视图的字段
 	formList := externalTable.GetForm()
@@ -124,6 +286,33 @@ func GetExternalTable(ctx *context.Context) (externalTable table.Table) {
 	// SetDescription: 设置表单描述
 	formList.SetTable("external").SetTitle("外部数据").SetDescription("外部数据")
 
+	// 设置写入函数
+	// 新增、修改和删除不写数据库，而是分别以 POST、PUT、DELETE 发送给远程接口
+	// 远程接口返回的校验错误（400 或 422）显示在表单上，字段名换成表单中的名称
+	formList.SetInsertFn(func(values form2.Values) error {
+		if externalAPI == nil {
+			return errExternalReadOnly
+		}
+		return externalFormError(formList, externalAPI.Create(reqCtx, externalRow(formList, values)))
+	})
+	formList.SetUpdateFn(func(values form2.Values) error {
+		if externalAPI == nil {
+			return errExternalReadOnly
+		}
+		return externalFormError(formList, externalAPI.Update(reqCtx, values.Get("id"), externalRow(formList, values)))
+	})
+	info.SetDeleteFn(func(ids []string) error {
+		if externalAPI == nil {
+			return errExternalReadOnly
+		}
+		for _, id := range ids {
+			if err := externalAPI.Delete(reqCtx, id); err != nil {
+				return fmt.Errorf("删除 %s 失败: %v", id, err)
+			}
+		}
+		return nil
+	})
+
 	// 获取详情视图配置对象
 	// GetDetail 返回表格的详情视图配置器，用于配置详情页面的字段和内容
 	// 详情视图用于展示单条记录的详细信息
@@ -139,14 +328,20 @@ func GetExternalTable(ctx *context.Context) (externalTable table.Table) {
 	//     - []map[string]interface{}: 数据列表（详情视图通常只返回一条记录）
 	//     - int: 记录数（详情视图通常为 1）
 	//
-	// 在实际应用中，这里应该根据 ID 从外部数据源获取单条记录的详细信息
+	// 配置了 external.url 时由 externalDetail 根据 ID 从远程接口获取单条记录
 	detail.SetTable("external").
 		SetTitle("外部数据").
 		SetDescription("外部数据").
 		SetGetDataFn(func(param parameter.Parameters) ([]map[string]interface{}, int) {
-			// 返回模拟的单条记录详情数据
-			// 在实际应用中，这里应该根据 param.Id 从外部数据源获取单条记录
-			// 例如: api.GetExternalDetail(param.Id)
+			if reqCtx.Err() != nil {
+				return nil, 0
+			}
+
+			if externalAPI != nil {
+				return externalDetail(reqCtx, param)
+			}
+
+			// 未配置远程接口时返回模拟的单条记录详情数据
 			return []map[string]interface{}{
 				{
 					"id":    10,
@@ -0,0 +1,365 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表单字段的校验规则：以 "required|max:50|regexp:^1\d{10}$" 的形式声明规则，
+// 同一份规则生成浏览器中的提示和服务端校验，错误信息按当前界面语言显示
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/language"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// fieldRuleNumeric numeric 规则的格式：可以带负号和小数部分的数字
+var fieldRuleNumeric = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// fieldRuleMessages 各界面语言的错误信息，键为 language.Langs 中的语言和规则名，min 和 max 的 %d 为规则的参数
+// 没有当前语言时使用简体中文
+var fieldRuleMessages = map[string]map[string]string{
+	language.CN: {
+		"required": "不能为空",
+		"min":      "至少 %d 个字符",
+		"max":      "不能超过 %d 个字符",
+		"regexp":   "格式不正确",
+		"email":    "邮箱格式不正确",
+		"numeric":  "必须是数字",
+	},
+	language.TC: {
+		"required": "不能為空",
+		"min":      "至少 %d 個字元",
+		"max":      "不能超過 %d 個字元",
+		"regexp":   "格式不正確",
+		"email":    "郵箱格式不正確",
+		"numeric":  "必須是數字",
+	},
+	language.EN: {
+		"required": "is required",
+		"min":      "must be at least %d characters",
+		"max":      "must be at most %d characters",
+		"regexp":   "is not in the correct format",
+		"email":    "must be a valid email address",
+		"numeric":  "must be a number",
+	},
+	language.JP: {
+		"required": "必須項目です",
+		"min":      "%d 文字以上で入力してください",
+		"max":      "%d 文字以内で入力してください",
+		"regexp":   "形式が正しくありません",
+		"email":    "メールアドレスの形式が正しくありません",
+		"numeric":  "数字で入力してください",
+	},
+	language.RU: {
+		"required": "обязательное поле",
+		"min":      "не менее %d символов",
+		"max":      "не более %d символов",
+		"regexp":   "неверный формат",
+		"email":    "неверный адрес электронной почты",
+		"numeric":  "должно быть числом",
+	},
+}
+
+// fieldRule 一条校验规则
+type fieldRule struct {
+	// name 规则名：required、min、max、regexp、email 或 numeric
+	name string
+
+	// n min 和 max 的字符数
+	n int
+
+	// re regexp 的正则表达式
+	re *regexp.Regexp
+}
+
+// fieldRules 一个字段的校验规则
+type fieldRules struct {
+	field string
+	label string
+	rules []fieldRule
+}
+
+// FormRules 表单各字段的校验规则
+//
+// 支持的规则（多条规则以 | 分隔）:
+//   - required: 必填
+//   - min:n / max:n: 至少 / 至多 n 个字符
+//   - regexp:表达式: 匹配正则表达式；表达式可以包含 |，因此必须是最后一条规则
+//   - email: 邮箱格式
+//   - numeric: 数字，可以带负号和小数部分
+//
+// 除 required 外，空值不检查其他规则
+//
+// 使用示例:
+//
+//	rules := NewFormRules().
+//		FieldRules("name", "姓名", "required|max:50").
+//		FieldRules("phone", "手机号", `regexp:^1\d{10}$`)
+//	rules.Apply(ctx, formList)
+type FormRules struct {
+	fields []fieldRules
+}
+
+// NewFormRules 返回没有规则的 FormRules
+func NewFormRules() *FormRules {
+	return &FormRules{}
+}
+
+// FieldRules 设置字段的校验规则
+//
+// 参数:
+//   - field: 表单字段名
+//   - label: 字段在表单中显示的名称，用于服务端的错误信息
+//   - rules: 以 | 分隔的规则，例如 "required|max:50"
+//
+// 返回值:
+//   - *FormRules: 自身，便于链式调用
+//
+// 注意事项:
+//   - 规则写在代码中，不认识的规则或无效的参数直接 panic
+func (r *FormRules) FieldRules(field, label, rules string) *FormRules {
+	parsed, err := parseFieldRules(rules)
+	if err != nil {
+		panic("tables: " + err.Error())
+	}
+	r.fields = append(r.fields, fieldRules{field: field, label: label, rules: parsed})
+	return r
+}
+
+// ParseFieldRules 检查规则的写法，用于保存在数据库中、由管理员填写的规则（见动态表单）
+// 规则无效时返回说明原因的错误
+func ParseFieldRules(rules string) error {
+	_, err := parseFieldRules(rules)
+	return err
+}
+
+// parseFieldRules 解析以 | 分隔的规则
+func parseFieldRules(s string) ([]fieldRule, error) {
+	var rules []fieldRule
+	for s != "" {
+		part := s
+		if strings.HasPrefix(s, "regexp:") {
+			s = ""
+		} else if i := strings.IndexByte(s, '|'); i >= 0 {
+			part, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+
+		name, arg, _ := strings.Cut(part, ":")
+		rule := fieldRule{name: name}
+		switch name {
+		case "required", "email", "numeric":
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("规则 %q 的字符数无效", part)
+			}
+			rule.n = n
+		case "regexp":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("规则 %q 的正则表达式无效: %v", part, err)
+			}
+			rule.re = re
+		default:
+			return nil, fmt.Errorf("不认识的规则 %q", part)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Validate 按规则校验提交的字段
+//
+// 参数:
+//   - lang: 错误信息的语言，通常为 ctx.Lang()，为空时使用 config.yml 的 language
+//   - values: 提交的字段值，键为字段名；只校验提交了的字段，列表中单独修改一个字段时只校验该字段
+//
+// 返回值:
+//   - models.ValidationErrors: 每个不通过的字段一条（该字段第一条不通过的规则），按设置规则的顺序排列，全部通过时为 nil
+func (r *FormRules) Validate(lang string, values map[string][]string) models.ValidationErrors {
+	messages := fieldRuleMessagesFor(lang)
+	var errs models.ValidationErrors
+	for _, f := range r.fields {
+		v, ok := values[f.field]
+		if !ok {
+			continue
+		}
+		value := ""
+		if len(v) > 0 {
+			value = strings.TrimSpace(v[0])
+		}
+		if msg := f.check(value, messages); msg != "" {
+			errs = append(errs, models.FieldError{Field: f.field, Label: f.label, Message: msg})
+		}
+	}
+	return errs
+}
+
+// check 返回第一条不通过的规则的错误信息，全部通过时返回空字符串
+func (f fieldRules) check(value string, messages map[string]string) string {
+	for _, rule := range f.rules {
+		if value == "" {
+			if rule.name == "required" {
+				return messages["required"]
+			}
+			continue
+		}
+		ok := true
+		switch rule.name {
+		case "min":
+			ok = utf8.RuneCountInString(value) >= rule.n
+		case "max":
+			ok = utf8.RuneCountInString(value) <= rule.n
+		case "regexp":
+			ok = rule.re.MatchString(value)
+		case "email":
+			addr, err := mail.ParseAddress(value)
+			ok = err == nil && addr.Address == value
+		case "numeric":
+			ok = fieldRuleNumeric.MatchString(value)
+		}
+		if !ok {
+			return fieldRuleMessage(rule, messages)
+		}
+	}
+	return ""
+}
+
+// fieldRuleMessage 规则不通过时的错误信息
+func fieldRuleMessage(rule fieldRule, messages map[string]string) string {
+	if rule.name == "min" || rule.name == "max" {
+		return fmt.Sprintf(messages[rule.name], rule.n)
+	}
+	return messages[rule.name]
+}
+
+// fieldRuleMessagesFor 返回语言的错误信息，lang 为空时使用 config.yml 的 language
+func fieldRuleMessagesFor(lang string) map[string]string {
+	if lang == "" {
+		lang = config.GetLanguage()
+	}
+	if messages, ok := fieldRuleMessages[language.FixedLanguageKey(lang)]; ok {
+		return messages
+	}
+	return fieldRuleMessages[language.CN]
+}
+
+// JS 返回在浏览器中提示规则的 JS
+// 输入时按规则检查字段，不通过时通过 setCustomValidity 设置错误信息，浏览器在提交时提示并阻止提交
+// 正则表达式按 JS 的语法解析，无法解析时只在服务端检查；邮箱只做简单的检查，以服务端为准
+//
+// 参数:
+//   - lang: 错误信息的语言，与 Validate 相同
+func (r *FormRules) JS(lang string) template.JS {
+	type jsRule struct {
+		Name    string `json:"name"`
+		N       int    `json:"n,omitempty"`
+		Pattern string `json:"pattern,omitempty"`
+		Message string `json:"message"`
+	}
+	messages := fieldRuleMessagesFor(lang)
+	fields := make(map[string][]jsRule, len(r.fields))
+	for _, f := range r.fields {
+		for _, rule := range f.rules {
+			j := jsRule{Name: rule.name, N: rule.n, Message: fieldRuleMessage(rule, messages)}
+			if rule.re != nil {
+				j.Pattern = rule.re.String()
+			}
+			fields[f.field] = append(fields[f.field], j)
+		}
+	}
+	data, _ := json.Marshal(fields)
+	return template.JS(`
+(function (fields) {
+    function check(rule, v) {
+        switch (rule.name) {
+            case 'min': return Array.from(v).length >= rule.n;
+            case 'max': return Array.from(v).length <= rule.n;
+            case 'email': return /^[^\s@]+@[^\s@]+$/.test(v);
+            case 'numeric': return /^-?[0-9]+(\.[0-9]+)?$/.test(v);
+            case 'regexp':
+                try {
+                    return new RegExp(rule.pattern).test(v);
+                } catch (e) {
+                    return true;
+                }
+        }
+        return true;
+    }
+    $.each(fields, function (field, rules) {
+        function validate() {
+            let v = $.trim($(this).val() || ''), msg = '';
+            for (let i = 0; i < rules.length && msg === ''; i++) {
+                if (v === '') {
+                    msg = rules[i].name === 'required' ? rules[i].message : '';
+                } else if (!check(rules[i], v)) {
+                    msg = rules[i].message;
+                }
+            }
+            this.setCustomValidity(msg);
+        }
+        $('[name="' + field + '"]').on('input change', validate).each(validate);
+    });
+})(` + string(data) + `);
+`)
+}
+
+// Apply 把规则用于表格的表单
+//
+// 参数:
+//   - ctx: 上下文对象，错误信息使用请求的界面语言
+//   - f: 表格的表单
+//
+// 功能说明:
+//  1. 有 required 规则的字段标记为必填
+//  2. 表单底部加入 JS 中的浏览器提示
+//  3. 在表单原有的校验函数之前按规则校验，不通过时不再执行原有的校验函数
+//
+// 注意事项:
+//   - 需在 SetPostValidator 之后调用，否则规则的校验会被覆盖
+//   - 手动生成的表单（例如 pages.GetFormContent）不输出 FormPanel 的 JS，也不执行校验函数，
+//     使用 MarkRequired 和 JS，并在提交的处理器中调用 Validate
+func (r *FormRules) Apply(ctx *context.Context, f *types.FormPanel) {
+	r.MarkRequired(f)
+
+	lang := ctx.Lang()
+	f.AddJS(r.JS(lang))
+
+	validator := f.Validator
+	f.SetPostValidator(func(values form2.Values) error {
+		if errs := r.Validate(lang, values); len(errs) > 0 {
+			return errs
+		}
+		if validator != nil {
+			return validator(values)
+		}
+		return nil
+	})
+}
+
+// MarkRequired 把有 required 规则的字段标记为必填，标签前显示星号，输入框带有 required 属性
+func (r *FormRules) MarkRequired(f *types.FormPanel) {
+	for _, rules := range r.fields {
+		for _, rule := range rules.rules {
+			if rule.name != "required" {
+				continue
+			}
+			for i := range f.FieldList {
+				if f.FieldList[i].Field == rules.field {
+					f.FieldList[i].Must = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现文件库（files）表格的模型配置，浏览、上传和删除上传目录中的文件
+package tables
+
+import (
+	stdctx "context"
+	"fmt"
+	"html"
+	"html/template"
+	"mime"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// init 在 Generators 中注册 files 前缀
+// 访问路径: /admin/info/files
+// 功能: 文件库表格，浏览上传目录中的文件，删除记录时一并删除文件
+func init() {
+	Register("files", withAudit(GetFilesTable))
+}
+
+// GetFilesTable 获取文件库表格模型
+// 该函数创建并返回文件库表格模型，文件保存在上传目录（config.yml 的 store.path，默认 ./uploads）中，
+// files 表记录每个文件的名称、大小、类型和上传人
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表中的图片显示缩略图，其他文件显示图标；可以按名称、类型和上传人筛选
+//   - 每行的"预览"按钮弹窗显示图片、音视频或 PDF，"复制链接"按钮将文件的完整地址复制到剪贴板
+//   - 删除时同时删除记录和磁盘上的文件，见 models.DeleteFiles
+//   - 顶部的"扫描目录"按钮登记上传目录中还没有记录的文件，如通过其他表单上传的图片
+//   - 上传文件后在同一个事务中补全大小、类型和上传人，编辑时只能修改名称
+func GetFilesTable(ctx *context.Context) (filesTable table.Table) {
+
+	filesTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	store := config.GetStore()
+
+	info := filesTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("缩略图", "path", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		mimeType, _ := value.Row["mime_type"].(string)
+		return fileThumbnail(models.File{Path: value.Value, MimeType: mimeType}, store.URL(value.Value))
+	})
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("大小", "size", db.Int).FieldSortable().FieldFileSize()
+
+	// 类型按前缀匹配，输入 image/ 可以筛选出全部图片
+	info.AddField("类型", "mime_type", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("上传人", "uploader_name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("上传时间", "created_at", db.Timestamp).FieldSortable()
+
+	info.AddActionButton(ctx, "预览", action.PopUp("/admin/files/preview", "预览",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			f, err := models.FindFile(ctx.Request.Context(), ctx.FormValue("id"))
+			if err != nil {
+				return false, "读取文件失败: " + err.Error(), ""
+			}
+			return true, "", filePreview(f, store.URL(f.Path))
+		}))
+
+	// 剪贴板只能由浏览器写入，处理函数返回完整地址，由按钮的回调复制；浏览器拒绝时弹窗显示地址供手动复制
+	info.AddActionButton(ctx, "复制链接", action.Ajax("/admin/files/copy_url",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			f, err := models.FindFile(ctx.Request.Context(), ctx.FormValue("id"))
+			if err != nil {
+				return false, "读取文件失败: " + err.Error(), ""
+			}
+			return true, "", fileAbsoluteURL(ctx, store.URL(f.Path))
+		}).SetSuccessJS(fileCopyURLJS))
+
+	info.AddButton(ctx, "扫描目录", icon.Refresh, action.Ajax("/admin/files/sync",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			n, err := models.SyncFiles(ctx.Request.Context(), store.Path)
+			if err != nil {
+				return false, "扫描上传目录失败: " + err.Error(), ""
+			}
+			return true, fmt.Sprintf("新登记 %d 个文件", n), ""
+		}).SetSuccessJS(fileSyncJS))
+
+	info.SetDeleteFn(func(ids []string) error {
+		return models.DeleteFiles(ctx.Request.Context(), store.Path, ids)
+	})
+
+	info.SetTable("files").SetTitle("文件库").SetDescription("上传的文件")
+
+	formList := filesTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldHelpMsg("留空时使用文件名")
+
+	formList.AddField("文件", "path", db.Varchar, form.File).FieldMust().FieldNotAllowEdit()
+
+	formList.SetTable("files").SetTitle("文件库").SetDescription("上传的文件")
+
+	// 上传的文件由 GoAdmin 保存到上传目录，表单只写入了路径，大小、类型和上传人在同一个事务中补全
+	// 编辑时不提交文件，不再更新
+	withTxPostHook(ctx, filesTable, func(txCtx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		if values.Get("path") == "" {
+			return nil
+		}
+		user := auth.Auth(ctx)
+		return models.RecordUpload(txCtx, id, store.Path, user.Id, user.Name)
+	})
+
+	return
+}
+
+// fileAbsoluteURL 将站内地址补全为带协议和域名的完整地址，已经是完整地址（如使用对象存储）时原样返回
+func fileAbsoluteURL(ctx *context.Context, u string) string {
+	if !strings.HasPrefix(u, "/") {
+		return u
+	}
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.Request.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host + u
+}
+
+// fileThumbnail 渲染列表中的缩略图，非图片文件显示图标
+func fileThumbnail(f models.File, u string) template.HTML {
+	if f.IsImage() {
+		return template.HTML(fmt.Sprintf(`<img src="%s" style="max-width:60px;max-height:60px">`, html.EscapeString(u)))
+	}
+	return `<i class="fa fa-file-o fa-2x text-muted"></i>`
+}
+
+// filePreview 按文件类型渲染预览弹窗的内容，浏览器无法直接显示的文件只提供下载链接
+// 上传的文件与后台同源，iframe 只加载 PDF 和纯文本，HTML 等可以执行脚本的文件不预览（SVG 作为图片显示，其中的脚本不会执行）；
+// 纯文本另外加上 sandbox，即使被浏览器当作 HTML 解析也不能执行脚本
+func filePreview(f models.File, u string) template.HTML {
+	src := html.EscapeString(u)
+	mimeType, _, _ := mime.ParseMediaType(f.MimeType)
+	var body string
+	switch {
+	case f.IsImage():
+		body = `<img src="` + src + `" style="max-width:100%">`
+	case strings.HasPrefix(f.MimeType, "video/"):
+		body = `<video src="` + src + `" controls style="max-width:100%"></video>`
+	case strings.HasPrefix(f.MimeType, "audio/"):
+		body = `<audio src="` + src + `" controls></audio>`
+	case mimeType == "application/pdf":
+		body = `<iframe src="` + src + `" style="width:100%;height:480px;border:0"></iframe>`
+	case mimeType == "text/plain":
+		body = `<iframe src="` + src + `" sandbox style="width:100%;height:480px;border:0"></iframe>`
+	default:
+		body = `<p class="text-muted">该类型的文件无法预览</p>`
+	}
+	return template.HTML(fmt.Sprintf(`<div style="text-align:center">%s<p style="margin-top:10px">%s · %s · <a href="%s" target="_blank" download>下载</a></p></div>`,
+		body, html.EscapeString(f.Name), html.EscapeString(f.MimeType), src))
+}
+
+// fileCopyURLJS "复制链接"按钮的回调，data.data 为文件的完整地址
+const fileCopyURLJS = template.JS(`if (data.code !== 0) {
+    swal(data.msg, '', 'error');
+    return;
+}
+let url = data.data;
+let manual = function () {
+    swal({title: '请手动复制链接', text: url, type: 'info'});
+};
+if (navigator.clipboard && window.isSecureContext) {
+    navigator.clipboard.writeText(url).then(function () {
+        swal('已复制链接', url, 'success');
+    }, manual);
+} else {
+    manual();
+}`)
+
+// fileSyncJS "扫描目录"按钮的回调，登记完成后刷新列表
+const fileSyncJS = template.JS(`if (data.code === 0) {
+    swal(data.msg, '', 'success');
+    $.pjax.reload('#pjax-container');
+} else {
+    swal(data.msg, '', 'error');
+}`)
@@ -0,0 +1,279 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现动态表单定义（form_definitions）的表格，表单中的字段设计器逐行编辑字段，保存为 JSON
+package tables
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// init 在 Generators 中注册 form_definitions 前缀
+// 访问路径: /admin/info/form_definitions
+// 功能: 设计动态表单，列表中的"填写"打开按定义生成的表单，"提交记录"查看填写的内容
+func init() {
+	Register("form_definitions", withAudit(GetFormDefinitionsTable))
+}
+
+// GetFormDefinitionsTable 获取动态表单定义表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 字段在设计器中逐行编辑：字段名、标签、类型、选项、默认值、校验规则和说明，也可以切换为直接编辑 JSON
+//   - 保存前按 models.ParseFormFields 和 ParseFieldRules 检查，保存的 JSON 统一格式化
+//   - 删除表单时一并删除它的提交记录，见 models.DeleteFormDefinitions
+func GetFormDefinitionsTable(ctx *context.Context) (formDefinitionsTable table.Table) {
+
+	formDefinitionsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := formDefinitionsTable.GetInfo().SetSortField("id").SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("标题", "title", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("说明", "description", db.Varchar)
+
+	info.AddField("字段数", "fields", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		fields, err := models.ParseFormFields(value.Value)
+		if err != nil {
+			return template.HTML(`<span class="label label-danger">定义无效</span>`)
+		}
+		return len(fields)
+	})
+
+	info.AddField("修改时间", "updated_at", db.Datetime).FieldSortable()
+
+	info.AddActionButton(ctx, "填写", action.Jump(config.Url(DynamicFormURL)+"?id={%id}"))
+
+	info.AddActionButton(ctx, "提交记录", action.Jump(config.Url("/info/form_submissions")+"?form_id={%id}"))
+
+	info.SetDeleteFn(func(ids []string) error {
+		return models.DeleteFormDefinitions(ctx.Request.Context(), ids)
+	})
+
+	info.SetTable("form_definitions").SetTitle("动态表单").SetDescription("在后台设计的表单")
+
+	formList := formDefinitionsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("标题", "title", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("说明", "description", db.Varchar, form.TextArea)
+
+	formList.AddField("字段", "fields", db.Text, form.Custom).
+		FieldCustomContent(formDesigner).
+		FieldCustomJs(formDesignerJS()).
+		FieldDefault("[]").
+		FieldHelpMsg(template.HTML(`选项以逗号分隔，值和显示文本以冒号分隔，如 <code>1:是, 0:否</code>；` +
+			`校验规则如 <code>required|max:50</code>，见“表单校验规则”`))
+
+	formList.SetTable("form_definitions").SetTitle("动态表单").SetDescription("在后台设计的表单")
+
+	NewFormRules().
+		FieldRules("title", "标题", "required|max:100").
+		FieldRules("description", "说明", "max:500").
+		Apply(ctx, formList)
+
+	formList.SetPostValidator(withFormFieldsCheck(formList.Validator))
+
+	// 保存格式化后的字段定义，字段名和标签去掉首尾空白
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if fields, err := models.ParseFormFields(values.Get("fields")); err == nil {
+			data, _ := json.MarshalIndent(fields, "", "  ")
+			values["fields"] = []string{string(data)}
+		}
+		return values
+	})
+
+	// 表单通过 models.SaveRow 写入，在同一个事务中补上创建和修改时间
+	withTxPostHook(ctx, formDefinitionsTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		return models.TouchFormDefinition(ctx, id)
+	})
+
+	return
+}
+
+// withFormFieldsCheck 在表单原有的校验之后检查字段定义和每个字段的校验规则
+// 列表中单独修改标题等字段时不提交字段定义，不做检查
+func withFormFieldsCheck(validator types.FormPostFn) types.FormPostFn {
+	return func(values form2.Values) error {
+		if validator != nil {
+			if err := validator(values); err != nil {
+				return err
+			}
+		}
+		if _, ok := values["fields"]; !ok {
+			return nil
+		}
+		fields, err := models.ParseFormFields(values.Get("fields"))
+		if err != nil {
+			return models.ValidationErrors{{Field: "fields", Label: "字段", Message: err.Error()}}
+		}
+		if _, _, err := BuildDynamicForm(fields); err != nil {
+			return models.ValidationErrors{{Field: "fields", Label: "字段", Message: err.Error()}}
+		}
+		return nil
+	}
+}
+
+// formDesigner 字段设计器的内容：隐藏的 JSON 输入框随表单提交，表格中的每一行对应一个字段
+// 作为 form.Custom 字段的内容，由 GoAdmin 按 html/template 填入字段名和当前值
+const formDesigner = template.HTML(`<div class="form-designer" style="width:100%">
+  <textarea name="{{.Field}}" class="form-control {{.Field}} form-designer-json" rows="16" style="display:none;font-family:monospace">{{.Value}}</textarea>
+  <table class="table table-bordered table-condensed form-designer-table">
+    <thead>
+      <tr>
+        <th>字段名</th><th>标签</th><th style="width:110px">类型</th><th>选项</th><th>默认值</th><th>校验规则</th><th>说明</th><th style="width:100px"></th>
+      </tr>
+    </thead>
+    <tbody></tbody>
+  </table>
+  <button type="button" class="btn btn-sm btn-default form-designer-add"><i class="fa fa-plus"></i> 添加字段</button>
+  <a href="javascript:void(0)" class="form-designer-toggle" style="margin-left:10px">编辑 JSON</a>
+</div>`)
+
+// formDesignerJS 设计器的交互：打开表单时把 JSON 展开为表格，表格中的任何修改立即写回 JSON
+// 切换为编辑 JSON 时直接修改隐藏的输入框，切换回表格时重新解析
+func formDesignerJS() template.JS {
+	fieldTypes := make([]map[string]string, len(models.FormFieldTypes))
+	for i, t := range models.FormFieldTypes {
+		fieldTypes[i] = map[string]string{"type": t.Type, "name": t.Name}
+	}
+	data, _ := json.Marshal(fieldTypes)
+	return template.JS(fmt.Sprintf(`
+(function (types) {
+    let root = $('.form-designer'), source = root.find('.form-designer-json'), body = root.find('tbody');
+    let keys = ['field', 'label', 'type', 'options', 'default', 'rules', 'help'];
+
+    function optionsText(options) {
+        return (options || []).map(function (o) {
+            return o.value === o.text ? o.value : o.value + ':' + o.text;
+        }).join(', ');
+    }
+    function parseOptions(s) {
+        return s.split(/[,，\n]/).map(function (p) {
+            return p.trim();
+        }).filter(function (p) {
+            return p !== '';
+        }).map(function (p) {
+            let i = p.indexOf(':');
+            return i < 0 ? {value: p, text: p} : {value: p.slice(0, i).trim(), text: p.slice(i + 1).trim()};
+        });
+    }
+
+    function addRow(f) {
+        let row = $('<tr>');
+        keys.forEach(function (key) {
+            let input;
+            if (key === 'type') {
+                input = $('<select class="form-control input-sm">');
+                types.forEach(function (t) {
+                    input.append($('<option>').val(t.type).text(t.name));
+                });
+            } else {
+                input = $('<input type="text" class="form-control input-sm">');
+            }
+            input.attr('data-key', key);
+            input.val(key === 'options' ? optionsText(f.options) : (f[key] || (key === 'type' ? 'text' : '')));
+            row.append($('<td>').append(input));
+        });
+        row.append($('<td>').append(
+            '<button type="button" class="btn btn-xs btn-default form-designer-up"><i class="fa fa-arrow-up"></i></button> ' +
+            '<button type="button" class="btn btn-xs btn-default form-designer-down"><i class="fa fa-arrow-down"></i></button> ' +
+            '<button type="button" class="btn btn-xs btn-danger form-designer-remove"><i class="fa fa-trash"></i></button>'));
+        body.append(row);
+    }
+
+    function render() {
+        let fields;
+        try {
+            fields = JSON.parse(source.val() || '[]');
+        } catch (e) {
+            return false;
+        }
+        body.empty();
+        (Array.isArray(fields) ? fields : []).forEach(addRow);
+        return true;
+    }
+
+    function sync() {
+        let fields = body.find('tr').map(function () {
+            let f = {};
+            $(this).find('[data-key]').each(function () {
+                let key = $(this).data('key'), v = $(this).val().trim();
+                if (key === 'options') {
+                    if (v !== '') {
+                        f.options = parseOptions(v);
+                    }
+                } else if (v !== '' || key === 'field' || key === 'label' || key === 'type') {
+                    f[key] = v;
+                }
+            });
+            return f;
+        }).get();
+        source.val(JSON.stringify(fields, null, 2));
+    }
+
+    body.on('input change', 'input, select', sync);
+    body.on('click', '.form-designer-remove', function () {
+        $(this).closest('tr').remove();
+        sync();
+    });
+    body.on('click', '.form-designer-up', function () {
+        let row = $(this).closest('tr');
+        row.prev().before(row);
+        sync();
+    });
+    body.on('click', '.form-designer-down', function () {
+        let row = $(this).closest('tr');
+        row.next().after(row);
+        sync();
+    });
+    root.find('.form-designer-add').click(function () {
+        addRow({type: 'text'});
+        sync();
+    });
+    root.find('.form-designer-toggle').click(function () {
+        if (source.is(':visible')) {
+            if (!render()) {
+                swal('JSON 格式不正确', '', 'error');
+                return;
+            }
+            source.hide();
+            root.find('.form-designer-table, .form-designer-add').show();
+            $(this).text('编辑 JSON');
+        } else {
+            source.show();
+            root.find('.form-designer-table, .form-designer-add').hide();
+            $(this).text('返回表格');
+        }
+    });
+
+    if (!render()) {
+        root.find('.form-designer-toggle').click();
+    }
+})(%s);
+`, data))
+}
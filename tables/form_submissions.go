@@ -0,0 +1,142 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现动态表单提交记录（form_submissions）的只读表格
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 form_submissions 前缀
+// 访问路径: /admin/info/form_submissions
+// 功能: 动态表单的提交记录，动态表单列表中的"提交记录"按表单筛选后打开
+func init() {
+	Register("form_submissions", GetFormSubmissionsTable)
+}
+
+// GetFormSubmissionsTable 获取动态表单提交记录表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 不能新增和修改，提交记录只由填写表单写入（见 pages.SubmitDynamicForm）
+//   - "内容"按表单当前的定义显示字段标签，定义中已删除的字段以字段名显示
+//   - 删除表单定义时一并删除它的提交记录，见 models.DeleteFormDefinitions
+func GetFormSubmissionsTable(ctx *context.Context) (formSubmissionsTable table.Table) {
+
+	formSubmissionsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetEditable(false))
+
+	info := formSubmissionsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("id").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("表单编号", "form_id", db.Int).
+		FieldFilterable().
+		FieldHide()
+
+	info.AddField("表单", "title", db.Varchar).FieldJoin(types.Join{
+		Field:     "form_id",
+		JoinField: "id",
+		Table:     "form_definitions",
+	}).FieldDisplay(func(value types.FieldModel) interface{} {
+		return template.HTML(fmt.Sprintf(`<a href="%s?__goadmin_edit_pk=%v">%s</a>`,
+			config.Url("/info/form_definitions/edit"), value.Row["form_id"], template.HTMLEscapeString(value.Value)))
+	})
+
+	// 同一页的记录通常属于同一个表单，每个表单的定义只读取一次
+	defs := make(map[string][]models.FormFieldDef)
+	info.AddField("内容", "data", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		formID := fmt.Sprint(value.Row["form_id"])
+		if _, ok := defs[formID]; !ok {
+			defs[formID] = formSubmissionFields(ctx, formID)
+		}
+		return formSubmissionData(value.Value, defs[formID])
+	})
+
+	info.AddField("提交人", "name", db.Varchar).FieldJoin(types.Join{
+		Field:     "submitted_by",
+		JoinField: "id",
+		Table:     "goadmin_users",
+	}).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("提交时间", "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	info.SetTable("form_submissions").SetTitle("提交记录").SetDescription("动态表单的提交记录")
+
+	return
+}
+
+// formSubmissionFields 读取表单当前的字段定义，表单不存在或定义无效时返回 nil
+func formSubmissionFields(ctx *context.Context, formID string) []models.FormFieldDef {
+	id, err := strconv.ParseUint(formID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	def, err := models.FindFormDefinition(ctx.Request.Context(), uint(id))
+	if err != nil {
+		return nil
+	}
+	fields, _ := models.ParseFormFields(def.Fields)
+	return fields
+}
+
+// formSubmissionData 把提交的 JSON 渲染为"标签: 值"的列表，多选的值以逗号分隔
+// 先按表单定义的顺序显示定义中的字段，再按字段名顺序显示定义中已删除的字段
+func formSubmissionData(raw string, fields []models.FormFieldDef) template.HTML {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return template.HTML(template.HTMLEscapeString(raw))
+	}
+
+	var b strings.Builder
+	write := func(label string, value interface{}) {
+		var v string
+		if values, ok := value.([]interface{}); ok {
+			parts := make([]string, len(values))
+			for i, p := range values {
+				parts[i] = fmt.Sprint(p)
+			}
+			v = strings.Join(parts, ", ")
+		} else {
+			v = fmt.Sprint(value)
+		}
+		fmt.Fprintf(&b, `<div><strong>%s:</strong> %s</div>`, template.HTMLEscapeString(label), template.HTMLEscapeString(v))
+	}
+
+	for _, f := range fields {
+		if v, ok := data[f.Field]; ok {
+			write(f.Label, v)
+			delete(data, f.Field)
+		}
+	}
+	rest := make([]string, 0, len(data))
+	for k := range data {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		write(k, data[k])
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,73 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现目标（goals）表格的模型配置，仪表板的目标完成进度条从该表读取
+package tables
+
+import (
+	"html"
+
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 goals 前缀
+// 访问路径: /admin/info/goals
+// 功能: 目标管理表格，仪表板的目标完成进度条从这里读取
+func init() {
+	Register("goals", withAudit(GetGoalsTable))
+}
+
+// GetGoalsTable 获取目标表格模型
+// 该函数创建并返回目标表格模型，用于在管理后台维护仪表板上的目标
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表视图显示每个目标的完成进度
+//   - 表单中的颜色字段使用取色器，决定仪表板进度条的颜色
+func GetGoalsTable(ctx *context.Context) (goalsTable table.Table) {
+
+	goalsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := goalsTable.GetInfo()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar)
+
+	info.AddField("目标值", "target", db.Decimal)
+
+	info.AddField("当前值", "current", db.Decimal)
+
+	// 颜色显示为色块，与仪表板上的进度条颜色一致
+	info.AddField("颜色", "color", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		c := html.EscapeString(value.Value)
+		return `<span style="display:inline-block;width:40px;height:12px;background-color:` + c + `"></span> ` + c
+	})
+
+	info.SetTable("goals").SetTitle("目标").SetDescription("仪表板目标完成情况")
+
+	formList := goalsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("目标值", "target", db.Decimal, form.Number).FieldMust()
+
+	formList.AddField("当前值", "current", db.Decimal, form.Number).FieldDefault("0")
+
+	formList.AddField("颜色", "color", db.Varchar, form.Color).FieldDefault("#76b2d4")
+
+	formList.SetTable("goals").SetTitle("目标").SetDescription("仪表板目标完成情况")
+
+	return
+}
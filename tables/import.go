@@ -0,0 +1,642 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现通用的表格导入：表格通过 withImport 加上"导入"按钮后，可以上传 CSV 或 XLSX 文件，
+// 选择文件中的列与表单字段的对应关系，先试运行校验每一行，确认后分批写入并显示进度，每次导入记入导入历史
+package tables
+
+import (
+	stdctx "context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// ImportURL 导入各步骤提交的地址（在后台 URL 前缀下的路径），需要在 main 中注册 Import
+const ImportURL = "/import"
+
+// ImportProgressURL 查询导入进度的地址（在后台 URL 前缀下的路径），需要在 main 中注册 ImportProgress
+const ImportProgressURL = "/import/progress"
+
+// importBatchSize 每个批次写入的行数，每个批次一个事务，提交后更新一次进度
+const importBatchSize = 100
+
+// importPreviewErrors 试运行结果中最多列出的错误行数，全部错误可以下载错误报告查看
+const importPreviewErrors = 100
+
+// importPreviewSamples 试运行结果中列出的校验通过的行数，用来确认列的对应关系是否正确
+const importPreviewSamples = 5
+
+// importables 通过 withImport 开启导入的表格前缀，Import 只接受这些表格
+var importables = map[string]bool{}
+
+// importDateLayouts 日期和时间字段可以使用的格式，依次尝试
+var importDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// withImport 为表格开启通用导入，在表格顶部添加"导入"按钮
+//
+// 参数:
+//   - prefix: 表格前缀，与 Register 使用的前缀相同
+//   - gen: 原来的表格生成函数
+//
+// 返回值:
+//   - table.Generator: 生成的表格带有"导入"按钮
+//
+// 使用示例:
+//
+//	func init() {
+//	    Register("tags", withImport("tags", withAudit(GetTagsTable)))
+//	}
+//
+// 功能说明:
+//   - 可以导入的字段取自新增表单：隐藏的、不允许新增的以及文件等无法从单元格得到的字段除外
+//   - 校验规则同样来自表单：必填、下拉选项（单元格可以填选项的值或文字）、数字、日期，以及表单的 PostValidator
+//   - 校验通过的行通过 models.SaveRow 写入表单的数据表，每行写入一条"新增"审计日志
+//
+// 注意事项:
+//   - 导入不经过表单的 InsertFn 和 PostHook，设置了 InsertFn 的表格（如外部数据源）不要开启导入
+//   - 前缀不在 Generators 中时导入请求返回 404
+func withImport(prefix string, gen table.Generator) table.Generator {
+	importables[prefix] = true
+	return func(ctx *context.Context) table.Table {
+		t := gen(ctx)
+		t.GetInfo().AddButton(ctx, "导入", icon.Upload, action.PopUp("/admin/import/"+prefix, "导入"+t.GetInfo().Title,
+			func(ctx *context.Context) (success bool, msg string, data interface{}) {
+				return true, "", importForm(prefix)
+			})).AddJS(importJS)
+		return t
+	}
+}
+
+// importRow 导入文件中一行的校验结果
+type importRow struct {
+	// Line 在文件中的行号
+	Line int
+
+	// Values 字段名到写入值的映射，没有对应列且没有默认值的字段不写入
+	Values map[string]interface{}
+
+	// Errors 该行的校验错误
+	Errors []string
+}
+
+// importPlan 一次导入的字段、列的对应关系和逐行的校验结果
+type importPlan struct {
+	// Table 写入的数据表
+	Table string
+
+	// PK 数据表的主键
+	PK string
+
+	// Fields 可以导入的表单字段
+	Fields types.FormFields
+
+	// Mapping 字段名到文件中列序号的映射，没有对应列的字段不在其中
+	Mapping map[string]int
+
+	// Sheet 导入文件的内容
+	Sheet models.ImportSheet
+
+	// Rows 每一行的校验结果，与 Sheet.Records 一一对应
+	Rows []importRow
+}
+
+// valid 返回校验通过的行
+func (p importPlan) valid() []importRow {
+	rows := make([]importRow, 0, len(p.Rows))
+	for _, r := range p.Rows {
+		if len(r.Errors) == 0 {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// Import 处理导入对话框各步骤的提交
+//
+// 请求格式（multipart/form-data）:
+//
+//	__prefix: 表格前缀
+//	file:     导入文件，每一步都重新上传同一个文件，服务端不保存上传的文件
+//	step:     columns 读取表头，返回列与字段的对应关系
+//	          preview 按提交的对应关系试运行，返回逐行的校验结果，不写入数据
+//	          import  重新校验后开始分批写入，返回导入历史的编号，进度通过 ImportProgress 查询
+//	map_{字段名}: 字段对应的列序号（从 0 开始），为空表示该字段不导入；preview 和 import 需要
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": "<html>"}              columns、preview
+//	{"code": 200, "msg": "ok", "data": {"id": 1, "total": 10}}  import
+//
+// 注意事项:
+//   - 需要该表格的新增权限
+//   - 文件无法读取、没有校验通过的行时返回 400
+func Import(ctx *context.Context) {
+	prefix := ctx.FormValue(parameter.Prefix)
+	gen, ok := Generators[prefix]
+	if !ok || !importables[prefix] {
+		importJSON(ctx, http.StatusNotFound, "表格不存在或没有开启导入", nil)
+		return
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/new/"+prefix), http.MethodPost, url.Values{}) {
+		importJSON(ctx, http.StatusForbidden, "没有新增该表格数据的权限", nil)
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		importJSON(ctx, http.StatusBadRequest, "请选择 CSV 或 XLSX 文件", nil)
+		return
+	}
+	defer file.Close()
+	sheet, err := models.ReadImportFile(header.Filename, file)
+	if err != nil {
+		importJSON(ctx, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	t := gen(ctx)
+	fields := importFields(t.GetForm())
+	if ctx.FormValue("step") == "columns" {
+		importJSON(ctx, http.StatusOK, "ok", importMappingForm(fields, sheet.Header))
+		return
+	}
+
+	plan := newImportPlan(t, fields, sheet, importMapping(ctx, fields, len(sheet.Header)))
+	if ctx.FormValue("step") != "import" {
+		importJSON(ctx, http.StatusOK, "ok", importPreview(plan))
+		return
+	}
+
+	rows := plan.valid()
+	if len(rows) == 0 {
+		importJSON(ctx, http.StatusBadRequest, "没有校验通过的行", nil)
+		return
+	}
+	user := auth.Auth(ctx)
+	l := models.ImportLog{
+		Prefix:    prefix,
+		Table:     plan.Table,
+		FileName:  header.Filename,
+		Total:     len(rows),
+		Skipped:   len(plan.Rows) - len(rows),
+		AdminID:   user.Id,
+		AdminName: user.Name,
+	}
+	if err := models.CreateImportLog(ctx.Request.Context(), &l); err != nil {
+		importJSON(ctx, http.StatusInternalServerError, "创建导入历史失败: "+err.Error(), nil)
+		return
+	}
+	models.RunWorker(func() { runImport(ctx, l.ID, plan.Table, plan.PK, rows) })
+
+	importJSON(ctx, http.StatusOK, "ok", map[string]interface{}{"id": l.ID, "total": l.Total})
+}
+
+// ImportProgress 查询导入进度
+//
+// 请求格式:
+//
+//	GET /admin/import/progress?id=1
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"status": "running", "total": 10, "imported": 5, "skipped": 2, "error": ""}}
+func ImportProgress(ctx *context.Context) {
+	l, err := models.FindImportLog(ctx.Request.Context(), ctx.Query("id"))
+	if err != nil {
+		importJSON(ctx, http.StatusNotFound, "导入历史不存在", nil)
+		return
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/new/"+l.Prefix), http.MethodPost, url.Values{}) {
+		importJSON(ctx, http.StatusForbidden, "没有新增该表格数据的权限", nil)
+		return
+	}
+	importJSON(ctx, http.StatusOK, "ok", map[string]interface{}{
+		"status":   l.Status,
+		"total":    l.Total,
+		"imported": l.Imported,
+		"skipped":  l.Skipped,
+		"error":    l.Error,
+	})
+}
+
+// importJSON 按数据接口的统一格式返回
+func importJSON(ctx *context.Context, code int, msg string, data interface{}) {
+	ctx.JSON(code, map[string]interface{}{
+		"code": code,
+		"msg":  msg,
+		"data": data,
+	})
+}
+
+// runImport 在后台分批写入校验通过的行
+// 每个批次在一个事务中写入数据和审计日志，提交后更新导入历史中的进度；
+// 某个批次失败时该批次回滚，之后的批次不再写入，导入历史记为失败，之前提交的批次保留
+//
+// 注意事项:
+//   - 请求在开始写入后立即返回，数据库操作使用 context.Background()
+func runImport(ctx *context.Context, id uint, name, pk string, rows []importRow) {
+	bg := stdctx.Background()
+	var cause error
+	defer func() {
+		if r := recover(); r != nil {
+			cause = fmt.Errorf("%v", r)
+		}
+		if err := models.FinishImportLog(bg, id, cause); err != nil {
+			log.Printf("更新导入历史 %d 失败: %s\n", id, err)
+		}
+	}()
+
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		cause = models.Transaction(bg, func(txCtx stdctx.Context, tx *gorm.DB) error {
+			ids := make([]string, 0, end-start)
+			for _, r := range rows[start:end] {
+				newID, err := models.SaveRow(txCtx, name, pk, "", r.Values)
+				if err != nil {
+					return fmt.Errorf("第 %d 行: %v", r.Line, err)
+				}
+				ids = append(ids, newID)
+			}
+			after, err := models.SnapshotRows(txCtx, name, pk, ids)
+			if err != nil {
+				return err
+			}
+			return models.CreateAuditLogs(txCtx, auditLogs(ctx, name, models.AuditCreate, ids, nil, after))
+		})
+		if cause != nil {
+			return
+		}
+		if err := models.UpdateImportProgress(bg, id, end); err != nil {
+			log.Printf("更新导入历史 %d 的进度失败: %s\n", id, err)
+		}
+	}
+}
+
+// importFields 返回新增表单中可以导入的字段
+func importFields(f *types.FormPanel) types.FormFields {
+	fields := make(types.FormFields, 0, len(f.FieldList))
+	for _, field := range f.FieldList {
+		if field.NotAllowAdd || field.Hide || field.CreateHide || field.FormType.IsFile() ||
+			field.FormType.IsCustom() || field.FormType.IsTable() || field.FormType.IsRange() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// importMapping 读取提交的对应关系，列序号无效的字段视为不导入
+func importMapping(ctx *context.Context, fields types.FormFields, columns int) map[string]int {
+	mapping := make(map[string]int, len(fields))
+	for _, f := range fields {
+		i, err := strconv.Atoi(ctx.FormValue("map_" + f.Field))
+		if err == nil && i >= 0 && i < columns {
+			mapping[f.Field] = i
+		}
+	}
+	return mapping
+}
+
+// importGuess 按表头猜测字段对应的列：表头与字段名或字段名称相同（不区分大小写）
+func importGuess(f types.FormField, header []string) int {
+	for i, h := range header {
+		if strings.EqualFold(h, f.Field) || strings.EqualFold(h, f.Head) {
+			return i
+		}
+	}
+	return -1
+}
+
+// newImportPlan 按对应关系逐行校验，不写入数据
+func newImportPlan(t table.Table, fields types.FormFields, sheet models.ImportSheet, mapping map[string]int) importPlan {
+	f := t.GetForm()
+	plan := importPlan{
+		Table:   f.Table,
+		PK:      t.GetPrimaryKey().Name,
+		Fields:  fields,
+		Mapping: mapping,
+		Sheet:   sheet,
+		Rows:    make([]importRow, 0, len(sheet.Records)),
+	}
+	for _, rec := range sheet.Records {
+		plan.Rows = append(plan.Rows, importRecordRow(f, fields, mapping, rec))
+	}
+	return plan
+}
+
+// importRecordRow 校验一行
+// 单元格为空时使用表单字段的默认值；逐个字段校验通过后再交给表单的 PostValidator，
+// 提交的值与新增表单提交的值相同，PostValidator 中的 IsInsertPost 为 true
+func importRecordRow(f *types.FormPanel, fields types.FormFields, mapping map[string]int, rec models.ImportRecord) importRow {
+	row := importRow{Line: rec.Line, Values: make(map[string]interface{}, len(fields))}
+	values := form2.Values{}
+	for _, field := range fields {
+		v := ""
+		if i, ok := mapping[field.Field]; ok {
+			v = rec.Cell(i)
+		}
+		if v == "" {
+			v = string(field.Default)
+		}
+		if v == "" {
+			if field.Must {
+				row.Errors = append(row.Errors, field.Head+"不能为空")
+			}
+			continue
+		}
+		v, msg := importValue(field, v)
+		if msg != "" {
+			row.Errors = append(row.Errors, field.Head+msg)
+			continue
+		}
+		row.Values[field.Field] = v
+		values.Add(field.Field, v)
+	}
+	if len(row.Errors) == 0 && f.Validator != nil {
+		values.Add(form2.PostTypeKey, "1")
+		if err := f.Validator(values); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+	}
+	return row
+}
+
+// importValue 按字段的选项和类型转换单元格的值，不合法时返回错误说明
+func importValue(f types.FormField, v string) (string, string) {
+	if len(f.Options) > 0 {
+		if !f.FormType.IsMultiSelect() {
+			return importOption(f.Options, v)
+		}
+		parts := strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '，' })
+		for i, p := range parts {
+			value, msg := importOption(f.Options, strings.TrimSpace(p))
+			if msg != "" {
+				return "", msg
+			}
+			parts[i] = value
+		}
+		return strings.Join(parts, ","), ""
+	}
+
+	switch {
+	case db.Contains(f.TypeName, db.IntTypeList):
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "", "应为整数：" + v
+		}
+	case db.Contains(f.TypeName, db.FloatTypeList), db.Contains(f.TypeName, db.UintTypeList):
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", "应为数字：" + v
+		}
+	case f.FormType.IsDateTime(), f.FormType.IsDate():
+		layout := "2006-01-02 15:04:05"
+		if f.FormType.IsDate() {
+			layout = "2006-01-02"
+		}
+		for _, l := range importDateLayouts {
+			if t, err := time.ParseInLocation(l, v, time.Local); err == nil {
+				return t.Format(layout), ""
+			}
+		}
+		return "", "应为日期，如 " + time.Now().Format(layout) + "：" + v
+	}
+	return v, ""
+}
+
+// importOption 单元格可以填选项的值或文字，返回选项的值
+func importOption(options types.FieldOptions, v string) (string, string) {
+	texts := make([]string, 0, len(options))
+	for _, o := range options {
+		// 去掉树形选项（如商品分类）的缩进和连接符，单元格中只需要填写名称
+		text := strings.TrimLeft(html.UnescapeString(pdfTagPattern.ReplaceAllString(string(o.Text), "")), " 　└├─│")
+		if v == o.Value || strings.EqualFold(v, text) {
+			return o.Value, ""
+		}
+		texts = append(texts, text)
+	}
+	if len(texts) > 10 {
+		return "", "不是可选的值：" + v
+	}
+	return "", "应为 " + strings.Join(texts, "、") + " 之一：" + v
+}
+
+// importForm 渲染"导入"弹窗：选择文件、列的对应关系、试运行结果和导入进度依次显示在文件下方
+func importForm(prefix string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<div class="table-import" style="padding:10px" data-url="%s" data-progress="%s" data-prefix="%s">
+  <p class="text-muted">支持 CSV（UTF-8 编码）和 XLSX 文件，第一行为表头，一次最多导入 %d 行。
+  <a href="%s" target="_blank">导入历史</a></p>
+  <input type="file" class="table-import-file" accept=".csv,.xlsx,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet">
+  <button type="button" class="btn btn-default table-import-columns" style="margin-top:10px">下一步</button>
+  <div class="table-import-mapping" style="margin-top:10px"></div>
+  <div class="table-import-result" style="margin-top:10px;max-height:360px;overflow:auto"></div>
+</div>`, config.Url(ImportURL), config.Url(ImportProgressURL), html.EscapeString(prefix), models.MaxImportRows,
+		config.Url("/info/import_logs?prefix="+url.QueryEscape(prefix))))
+}
+
+// importMappingForm 渲染列与字段的对应关系，每个字段一个下拉框，按表头预先选好
+func importMappingForm(fields types.FormFields, header []string) template.HTML {
+	var b strings.Builder
+	b.WriteString(`<p>选择每个字段对应文件中的哪一列，标 * 的字段必填：</p><table class="table table-condensed"><tbody>`)
+	for _, f := range fields {
+		must := ""
+		if f.Must {
+			must = ` <span class="text-red">*</span>`
+		}
+		guess := importGuess(f, header)
+		fmt.Fprintf(&b, `<tr><td style="width:30%%">%s%s</td><td><select class="form-control input-sm" name="map_%s"><option value="">（不导入）</option>`,
+			html.EscapeString(f.Head), must, html.EscapeString(f.Field))
+		for i, h := range header {
+			selected := ""
+			if i == guess {
+				selected = " selected"
+			}
+			if h == "" {
+				h = fmt.Sprintf("第 %d 列", i+1)
+			}
+			fmt.Fprintf(&b, `<option value="%d"%s>%s</option>`, i, selected, html.EscapeString(h))
+		}
+		b.WriteString(`</select></td></tr>`)
+	}
+	b.WriteString(`</tbody></table><button type="button" class="btn btn-default table-import-preview">试运行</button>`)
+	return template.HTML(b.String())
+}
+
+// importPreview 渲染试运行的结果：列出有错误的行和前几行校验通过的数据，存在校验通过的行时显示"导入"按钮
+func importPreview(p importPlan) template.HTML {
+	var b strings.Builder
+	valid := p.valid()
+	failed := len(p.Rows) - len(valid)
+	fmt.Fprintf(&b, `<p>共 %d 行，校验通过 <b class="text-green">%d</b> 行，有错误 <b class="text-red">%d</b> 行（有错误的行不会导入）</p>`,
+		len(p.Rows), len(valid), failed)
+
+	if failed > 0 {
+		b.WriteString(`<table class="table table-bordered table-condensed"><thead><tr><th>行号</th><th>错误</th></tr></thead><tbody>`)
+		n := 0
+		for _, r := range p.Rows {
+			if len(r.Errors) == 0 {
+				continue
+			}
+			if n == importPreviewErrors {
+				fmt.Fprintf(&b, `<tr><td colspan="2" class="text-muted">只列出前 %d 行，全部错误见错误报告</td></tr>`, importPreviewErrors)
+				break
+			}
+			fmt.Fprintf(&b, `<tr class="danger"><td>%d</td><td>%s</td></tr>`, r.Line, html.EscapeString(strings.Join(r.Errors, "；")))
+			n++
+		}
+		b.WriteString(`</tbody></table>`)
+		fmt.Fprintf(&b, `<p><a class="btn btn-default btn-sm" download="导入错误报告.csv" href="data:text/csv;charset=utf-8;base64,%s"><i class="fa fa-download"></i> 下载错误报告</a></p>`,
+			base64.StdEncoding.EncodeToString(importErrorReport(p)))
+	}
+
+	if len(valid) > 0 {
+		b.WriteString(`<p class="text-muted">校验通过的数据（前几行）：</p><table class="table table-bordered table-condensed"><thead><tr><th>行号</th>`)
+		for _, f := range p.Fields {
+			fmt.Fprintf(&b, `<th>%s</th>`, html.EscapeString(f.Head))
+		}
+		b.WriteString(`</tr></thead><tbody>`)
+		for i, r := range valid {
+			if i == importPreviewSamples {
+				break
+			}
+			fmt.Fprintf(&b, `<tr><td>%d</td>`, r.Line)
+			for _, f := range p.Fields {
+				v, _ := r.Values[f.Field].(string)
+				fmt.Fprintf(&b, `<td>%s</td>`, html.EscapeString(v))
+			}
+			b.WriteString(`</tr>`)
+		}
+		b.WriteString(`</tbody></table>`)
+		fmt.Fprintf(&b, `<button type="button" class="btn btn-primary table-import-submit">导入 %d 行</button>`, len(valid))
+	}
+	b.WriteString(`<div class="table-import-progress" style="margin-top:10px"></div>`)
+	return template.HTML(b.String())
+}
+
+// importErrorReport 生成错误报告：有错误的行按原样输出，末尾加上"错误"列，修改后可以直接再次导入
+func importErrorReport(p importPlan) []byte {
+	errs := make([]string, len(p.Rows))
+	for i, r := range p.Rows {
+		errs[i] = strings.Join(r.Errors, "；")
+	}
+	return p.Sheet.ErrorReport(errs)
+}
+
+// importJS "导入"弹窗的各个步骤：读取表头、试运行、导入并轮询进度，导入结束后刷新列表
+// 每一步都上传同一个文件和当前的对应关系；更换文件后清空之后的步骤
+const importJS = template.JS(`
+(function () {
+    function submit(box, step, done) {
+        let file = box.find('.table-import-file')[0].files[0];
+        if (!file) {
+            swal('请选择 CSV 或 XLSX 文件', '', 'warning');
+            return;
+        }
+        let fd = new FormData();
+        fd.append('__prefix', box.data('prefix'));
+        fd.append('file', file);
+        fd.append('step', step);
+        box.find('.table-import-mapping select').each(function () {
+            fd.append($(this).attr('name'), $(this).val());
+        });
+        $.ajax({
+            method: 'post',
+            url: box.data('url'),
+            data: fd,
+            processData: false,
+            contentType: false,
+            success: function (data) {
+                if (typeof (data) === "string") {
+                    data = JSON.parse(data);
+                }
+                done(data.data);
+            },
+            error: function (data) {
+                box.find(':disabled').prop('disabled', false);
+                swal(data.responseJSON ? data.responseJSON.msg : '导入失败', '', 'error');
+            }
+        });
+    }
+
+    function poll(box, id) {
+        $.get(box.data('progress'), {id: id}, function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            let p = data.data;
+            let percent = p.total ? Math.floor(p.imported * 100 / p.total) : 100;
+            box.find('.table-import-progress').html(
+                '<div class="progress"><div class="progress-bar progress-bar-striped active" style="width:' + percent + '%"></div></div>' +
+                '<p>已写入 ' + p.imported + ' / ' + p.total + ' 行</p>');
+            if (p.status === 'running') {
+                setTimeout(function () {
+                    poll(box, id);
+                }, 1000);
+                return;
+            }
+            box.find('.progress-bar').removeClass('active');
+            if (p.status === 'done') {
+                swal('已导入 ' + p.imported + ' 行', p.skipped ? p.skipped + ' 行有错误未导入' : '', 'success');
+            } else {
+                swal('导入失败，已写入 ' + p.imported + ' 行', p.error, 'error');
+            }
+            $.pjax.reload('#pjax-container');
+        });
+    }
+
+    $(document).off('.tableImport');
+    $(document).on('click.tableImport', '.table-import-columns', function () {
+        let box = $(this).closest('.table-import');
+        submit(box, 'columns', function (html) {
+            box.find('.table-import-mapping').html(html);
+            box.find('.table-import-result').empty();
+        });
+    });
+    $(document).on('click.tableImport', '.table-import-preview', function () {
+        let box = $(this).closest('.table-import');
+        submit(box, 'preview', function (html) {
+            box.find('.table-import-result').html(html);
+        });
+    });
+    $(document).on('click.tableImport', '.table-import-submit', function () {
+        let box = $(this).closest('.table-import');
+        let button = $(this).prop('disabled', true);
+        box.find('.table-import-mapping select, .table-import-preview').prop('disabled', true);
+        submit(box, 'import', function (data) {
+            button.remove();
+            poll(box, data.id);
+        });
+    });
+    $(document).on('change.tableImport', '.table-import-file', function () {
+        let box = $(this).closest('.table-import');
+        box.find('.table-import-mapping, .table-import-result').empty();
+    });
+})();
+`)
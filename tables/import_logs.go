@@ -0,0 +1,121 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现导入历史（import_logs）的只读表格，每次通过"导入"弹窗开始的导入对应一行
+package tables
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 import_logs 前缀
+// 访问路径: /admin/info/import_logs
+// 功能: 导入历史的只读表格，"导入"弹窗中的"导入历史"链接按表格前缀筛选后打开
+func init() {
+	Register("import_logs", GetImportLogsTable)
+}
+
+// GetImportLogsTable 获取导入历史表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 不能新增、修改和删除，导入历史只由导入过程写入（见 withImport）
+//   - 可以按表格、状态和开始时间筛选，默认按时间倒序排列
+//   - 导入中的记录显示已写入的行数，刷新列表可以看到最新的进度
+func GetImportLogsTable(ctx *context.Context) (importLogsTable table.Table) {
+
+	importLogsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetEditable(false).SetDeletable(false))
+
+	info := importLogsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).
+		SetSortField("id").SetSortDesc().HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("开始时间", "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	info.AddField("表格", "prefix", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(importPrefixOptions())
+
+	info.AddField("文件", "file_name", db.Varchar)
+
+	info.AddField("状态", "status", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(importStatusOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return importStatusLabel(value.Value)
+		})
+
+	info.AddField("已写入 / 校验通过", "imported", db.Int).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return fmt.Sprintf("%v / %v", value.Value, value.Row["total"])
+		})
+
+	info.AddField("校验通过", "total", db.Int).FieldHide()
+
+	info.AddField("跳过", "skipped", db.Int)
+
+	info.AddField("失败原因", "error", db.Varchar)
+
+	info.AddField("管理员", "admin_name", db.Varchar)
+
+	info.AddField("结束时间", "finished_at", db.Timestamp)
+
+	info.SetTable("import_logs").SetTitle("导入历史").SetDescription("各表格通过文件导入数据的记录")
+
+	return
+}
+
+// importPrefixOptions 开启了导入的表格前缀，用于按表格筛选
+func importPrefixOptions() types.FieldOptions {
+	prefixes := make([]string, 0, len(importables))
+	for p := range importables {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	options := make(types.FieldOptions, 0, len(prefixes))
+	for _, p := range prefixes {
+		options = append(options, types.FieldOption{Value: p, Text: p})
+	}
+	return options
+}
+
+// importStatusOptions 导入状态的选项
+var importStatusOptions = types.FieldOptions{
+	{Value: models.ImportRunning, Text: "导入中"},
+	{Value: models.ImportDone, Text: "完成"},
+	{Value: models.ImportFailed, Text: "失败"},
+}
+
+// importStatusColors 各导入状态在列表中的标签颜色
+var importStatusColors = map[string]string{
+	models.ImportRunning: "info",
+	models.ImportDone:    "success",
+	models.ImportFailed:  "danger",
+}
+
+// importStatusLabel 将导入状态渲染为带颜色的标签
+func importStatusLabel(status string) template.HTML {
+	for _, o := range importStatusOptions {
+		if o.Value == status {
+			return template.HTML(fmt.Sprintf(`<span class="label label-%s">%s</span>`, importStatusColors[status], o.Text))
+		}
+	}
+	return template.HTML(template.HTMLEscapeString(status))
+}
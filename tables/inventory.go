@@ -0,0 +1,138 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现库存（inventory）表格的模型配置，与商品表格共用 products 表，只维护库存和安全库存
+package tables
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	editType "github.com/purpose168/GoAdmin/template/types/table"
+)
+
+// init 在 Generators 中注册 inventory 前缀
+// 访问路径: /admin/info/inventory
+// 功能: 库存表格，与商品表格共用 products 表，库存不足的商品高亮并可以查看补货提醒
+func init() {
+	Register("inventory", withAudit(GetInventoryTable))
+}
+
+// GetInventoryTable 获取库存表格模型
+// 该函数创建并返回库存表格模型，用于查看和调整商品的库存
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 库存低于安全库存的行显示为警告色，库存可以在列表中直接修改
+//   - 顶部的"补货提醒"按钮弹窗列出全部库存不足的商品及补足到安全库存的数量
+//   - 不能新增和删除，商品在商品表格中维护
+//
+// 行颜色的实现:
+//   - FieldDisplay 只能渲染单元格，状态列给库存不足的单元格加上 inventory-low 标记，
+//     由 inventoryJS 为所在的行加上 Bootstrap 的 warning 样式
+func GetInventoryTable(ctx *context.Context) (inventoryTable table.Table) {
+
+	inventoryTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).
+		SetCanAdd(false).SetDeletable(false))
+
+	info := inventoryTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).HideNewButton().HideDetailButton()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("商品", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("库存", "stock", db.Int).FieldSortable().FieldEditAble(editType.Text)
+
+	info.AddField("安全库存", "min_stock", db.Int).FieldSortable().FieldEditAble(editType.Text)
+
+	// status 不是 products 表的字段，按同一行的库存和安全库存计算
+	info.AddField("状态", "status", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		p := models.Product{Stock: rowInt(value.Row["stock"]), MinStock: rowInt(value.Row["min_stock"])}
+		if p.LowStock() {
+			return fmt.Sprintf(`<span class="label label-warning inventory-low">库存不足，缺 %d</span>`, p.Shortage())
+		}
+		return `<span class="label label-success">充足</span>`
+	})
+
+	info.AddButton(ctx, "补货提醒", icon.Bell, action.PopUp("/admin/inventory/low_stock", "补货提醒",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			return true, "", lowStockTable(models.LowStockProducts(ctx.Request.Context()))
+		}))
+
+	info.AddJS(inventoryJS)
+
+	info.SetTable("products").SetTitle("库存").SetDescription("商品库存")
+
+	formList := inventoryTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit()
+
+	formList.AddField("商品", "name", db.Varchar, form.Default).FieldNotAllowEdit()
+
+	formList.AddField("库存", "stock", db.Int, form.Number).FieldMust()
+
+	formList.AddField("安全库存", "min_stock", db.Int, form.Number).FieldMust()
+
+	formList.SetTable("products").SetTitle("库存").SetDescription("商品库存")
+
+	return
+}
+
+// rowInt 将列表行中的整数字段转换为 int，SQLite 读出的整数为 int64
+func rowInt(v interface{}) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case int:
+		return n
+	default:
+		i, _ := strconv.Atoi(fmt.Sprint(v))
+		return i
+	}
+}
+
+// lowStockTable 渲染补货提醒弹窗中的商品列表
+func lowStockTable(products []models.Product) template.HTML {
+	if len(products) == 0 {
+		return `<p class="text-muted">所有商品的库存都不低于安全库存</p>`
+	}
+
+	rows := make([]map[string]types.InfoItem, 0, len(products))
+	for _, p := range products {
+		rows = append(rows, map[string]types.InfoItem{
+			"商品":   {Content: template.HTML(html.EscapeString(p.Name))},
+			"库存":   {Content: template.HTML(strconv.Itoa(p.Stock))},
+			"安全库存": {Content: template.HTML(strconv.Itoa(p.MinStock))},
+			"需补货":  {Content: template.HTML(fmt.Sprintf(`<b class="text-red">%d</b>`, p.Shortage()))},
+		})
+	}
+
+	return tmpl.Default().Table().SetType("table").SetInfoList(rows).SetThead(types.Thead{
+		{Head: "商品"},
+		{Head: "库存"},
+		{Head: "安全库存"},
+		{Head: "需补货"},
+	}).GetContent()
+}
+
+// inventoryJS 为库存不足的行加上警告色，列表通过 pjax 刷新后重新执行
+const inventoryJS = template.JS(`
+$('.inventory-low').closest('tr').addClass('warning');
+`)
@@ -0,0 +1,325 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现 JSON 字段：表单中使用带语法高亮的代码编辑器编辑，保存前检查 JSON 格式并按结构约束校验，
+// 列表中格式化显示
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// JSON 值的类型，与 JSON Schema 的 type 相同
+const (
+	JSONObject  = "object"
+	JSONArray   = "array"
+	JSONString  = "string"
+	JSONNumber  = "number"
+	JSONInteger = "integer"
+	JSONBoolean = "boolean"
+)
+
+// jsonTypeNames 各类型在说明和错误信息中的名称
+var jsonTypeNames = map[string]string{
+	JSONObject:  "对象",
+	JSONArray:   "数组",
+	JSONString:  "字符串",
+	JSONNumber:  "数字",
+	JSONInteger: "整数",
+	JSONBoolean: "布尔值",
+}
+
+// JSONSchema JSON 字段的结构约束，是 JSON Schema 的一个子集
+//
+// 支持的约束:
+//   - Type: 值的类型，为空时不限制
+//   - Properties / Required: 对象的属性和必须有的属性；设置了 Properties 时，
+//     不在其中的属性视为错误，除非 AdditionalProperties 为 true
+//   - Items: 数组中每一项的约束
+//
+// 使用示例:
+//
+//	schema := &JSONSchema{Type: JSONObject, Properties: map[string]*JSONSchema{
+//		"brand": {Type: JSONString, Description: "品牌"},
+//	}}
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties bool                   `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+}
+
+// Validate 按约束校验 JSON 解码后的值，返回第一处不符合约束的位置和原因
+func (s *JSONSchema) Validate(v interface{}) error {
+	return s.validate("", v)
+}
+
+// validate 校验 path 处的值，path 为以 . 分隔的属性名和 [n] 形式的数组下标
+func (s *JSONSchema) validate(path string, v interface{}) error {
+	at := func(format string, args ...interface{}) error {
+		msg := fmt.Sprintf(format, args...)
+		if path == "" {
+			return errors.New(msg)
+		}
+		return fmt.Errorf("%s %s", path, msg)
+	}
+
+	if s.Type != "" && !jsonTypeMatches(s.Type, v) {
+		return at("应为%s", jsonTypeNames[s.Type])
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				return at("缺少属性 %s", name)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child, ok := s.Properties[k]
+			if !ok {
+				if s.Properties != nil && !s.AdditionalProperties {
+					return at("不支持属性 %s", k)
+				}
+				continue
+			}
+			if err := child.validate(joinJSONPath(path, k), val[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range val {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Help 列出对象的属性、类型和说明，用作表单字段下方的提示
+func (s *JSONSchema) Help() template.HTML {
+	if len(s.Properties) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		p := s.Properties[name]
+		part := "<code>" + template.HTMLEscapeString(name) + "</code>"
+		if p.Description != "" {
+			part += " " + template.HTMLEscapeString(p.Description)
+		}
+		if t := jsonTypeNames[p.Type]; t != "" {
+			part += "（" + t + "）"
+		}
+		if required[name] {
+			part += "，必填"
+		}
+		parts[i] = part
+	}
+	return template.HTML("支持的属性：" + strings.Join(parts, "；"))
+}
+
+// jsonTypeMatches 判断值是否为 JSON Schema 中的类型；integer 为没有小数部分的数字
+func jsonTypeMatches(t string, v interface{}) bool {
+	switch t {
+	case JSONObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case JSONArray:
+		_, ok := v.([]interface{})
+		return ok
+	case JSONString:
+		_, ok := v.(string)
+		return ok
+	case JSONNumber:
+		_, ok := v.(float64)
+		return ok
+	case JSONInteger:
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case JSONBoolean:
+		_, ok := v.(bool)
+		return ok
+	}
+	return false
+}
+
+// joinJSONPath 在路径后加上属性名
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// AddJSONField 在表单中添加 JSON 字段，使用带语法高亮的代码编辑器编辑
+//
+// 参数:
+//   - f: 表单面板
+//   - head: 字段在表单中显示的名称
+//   - field: 表单字段名，对应数据库中的 text 字段
+//   - schema: 值的结构约束，为 nil 时只检查 JSON 格式
+//
+// 返回值:
+//   - *types.FormPanel: 自身，当前字段为新添加的 JSON 字段，便于继续设置
+//
+// 功能说明:
+//  1. 编辑器中输入时检查 JSON 格式，格式不正确时在编辑器下方提示
+//  2. 提交后在服务端用 json.Valid 检查格式，再按 schema 校验，不通过时不保存
+//  3. 保存格式化后的 JSON，空值保存为 {}
+//
+// 注意事项:
+//   - 在表单原有的校验之后校验，之后调用 SetPostValidator 会覆盖这里的校验；与 FormRules.Apply 一样放在最后调用
+//   - 代码编辑器修改内容后以 encodeURIComponent 编码提交，没有修改时提交原文，这里两种都能处理
+func AddJSONField(f *types.FormPanel, head, field string, schema *JSONSchema) *types.FormPanel {
+	validator := f.Validator
+	f.SetPostValidator(func(values form2.Values) error {
+		if validator != nil {
+			if err := validator(values); err != nil {
+				return err
+			}
+		}
+		if _, ok := values[field]; !ok {
+			return nil
+		}
+		if _, err := normalizeJSONField(values.Get(field), schema); err != nil {
+			return models.ValidationErrors{{Field: field, Label: head, Message: err.Error()}}
+		}
+		return nil
+	})
+
+	preProcess := f.PreProcessFn
+	f.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if preProcess != nil {
+			values = preProcess(values)
+		}
+		if _, ok := values[field]; ok {
+			if s, err := normalizeJSONField(values.Get(field), schema); err == nil {
+				values[field] = []string{s}
+			}
+		}
+		return values
+	})
+
+	f.AddJS(jsonFieldJS(field))
+
+	f.AddField(head, field, db.Text, form.Code).
+		FieldOptionExtJS(`
+	theme = "monokai";
+	font_size = 14;
+	language = "json";
+	options = {useWorker: false};
+`).
+		FieldDefault("{}")
+	if schema != nil {
+		f.FieldHelpMsg(schema.Help())
+	}
+	return f
+}
+
+// normalizeJSONField 解码提交的值，检查格式并按约束校验，返回格式化后的 JSON
+func normalizeJSONField(raw string, schema *JSONSchema) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !json.Valid([]byte(raw)) {
+		// 原文不是 JSON 时按 encodeURIComponent 编码的内容解码；对象和数组编码后不再是有效的 JSON，两种情况不会混淆
+		if decoded, err := url.PathUnescape(raw); err == nil {
+			raw = strings.TrimSpace(decoded)
+		}
+	}
+	if raw == "" {
+		raw = "{}"
+	}
+	if !json.Valid([]byte(raw)) {
+		return "", errors.New("不是有效的 JSON")
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return "", errors.New("不是有效的 JSON")
+	}
+	if schema != nil {
+		if err := schema.Validate(v); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(raw), "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// FormatJSON 将 JSON 渲染为格式化后的 <pre>，用作列表中 JSON 字段的显示；不是 JSON 时按原文显示
+func FormatJSON(value string) template.HTML {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(value), "", "  "); err != nil {
+		return template.HTML(template.HTMLEscapeString(value))
+	}
+	return template.HTML(`<pre style="margin:0;max-height:160px;overflow:auto;font-size:12px">` +
+		template.HTMLEscapeString(buf.String()) + `</pre>`)
+}
+
+// jsonFieldJS 编辑器内容变化时检查 JSON 格式，在编辑器下方显示错误
+func jsonFieldJS(field string) template.JS {
+	name, _ := json.Marshal(field)
+	return template.JS(`
+(function (field) {
+    let el = document.getElementById(field);
+    if (!el || !window.ace) {
+        return;
+    }
+    let editor = ace.edit(el), tip = $('<p class="text-danger json-field-error" style="margin:5px 0 0"></p>').insertAfter(el);
+    function check() {
+        let v = editor.getValue().trim();
+        try {
+            if (v !== '') {
+                JSON.parse(v);
+            }
+            tip.text('');
+        } catch (e) {
+            tip.text('JSON 格式不正确：' + e.message);
+        }
+    }
+    editor.session.on('change', check);
+    check();
+})(` + string(name) + `);
+`)
+}
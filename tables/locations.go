@@ -0,0 +1,324 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现地点（locations）表格：表单中在地图上选择坐标，列表中显示坐标所在位置的地图缩略图
+package tables
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// 地图的脚本、样式和瓦片地址
+// 默认使用 Leaflet 和 OpenStreetMap 的瓦片，从 CDN 加载；无法访问外网的部署可以改为本地静态文件和自建的瓦片服务
+var (
+	// LeafletURL Leaflet 脚本地址
+	LeafletURL = "https://cdn.jsdelivr.net/npm/leaflet@1.9.4/dist/leaflet.js"
+
+	// LeafletCSSURL Leaflet 样式地址
+	LeafletCSSURL = "https://cdn.jsdelivr.net/npm/leaflet@1.9.4/dist/leaflet.css"
+
+	// MapTileURL 地图瓦片地址，{z}、{x}、{y} 为缩放级别和瓦片坐标，表单中的地图和列表中的缩略图共用
+	MapTileURL = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+)
+
+const (
+	// mapThumbnailZoom 列表中缩略图的缩放级别，约为街区的范围
+	mapThumbnailZoom = 14
+
+	// mapThumbnailWidth / mapThumbnailHeight 缩略图的宽和高，单位为像素
+	mapThumbnailWidth  = 160
+	mapThumbnailHeight = 100
+
+	// mapTileSize 瓦片的边长，单位为像素
+	mapTileSize = 256
+)
+
+// init 在 Generators 中注册 locations 前缀
+// 访问路径: /admin/info/locations
+// 功能: 地点管理表格，表单中在地图上点击或拖动标记选择坐标
+func init() {
+	Register("locations", withAudit(GetLocationsTable))
+}
+
+// GetLocationsTable 获取地点表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 表单中的"位置"是一个组合控件：地图和纬度、经度两个输入框，分别保存到 latitude 和 longitude 字段
+//   - 在地图上点击或拖动标记时更新输入框，修改输入框时移动标记
+//   - 列表中显示以坐标为中心的地图缩略图，点击在 OpenStreetMap 中打开
+func GetLocationsTable(ctx *context.Context) (locationsTable table.Table) {
+
+	locationsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := locationsTable.GetInfo().SetSortField("id").SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("地址", "address", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	// 坐标列显示"纬度, 经度"，地图列显示缩略图
+	info.AddField("坐标", "latitude", db.Real).FieldDisplay(func(value types.FieldModel) interface{} {
+		return fmt.Sprintf("%.6f, %.6f", rowFloat(value.Row["latitude"]), rowFloat(value.Row["longitude"]))
+	})
+
+	info.AddField("地图", "longitude", db.Real).FieldDisplay(func(value types.FieldModel) interface{} {
+		return mapThumbnail(rowFloat(value.Row["latitude"]), rowFloat(value.Row["longitude"]))
+	})
+
+	info.AddField("修改时间", "updated_at", db.Datetime).FieldSortable()
+
+	info.SetTable("locations").SetTitle("地点").SetDescription("在地图上选择坐标的地点")
+
+	formList := locationsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("地址", "address", db.Varchar, form.Text)
+
+	// 组合控件以 latitude 为字段名，其中的经度输入框没有字段名，与隐藏的 longitude 字段同步
+	// longitude 必须在表单中，编辑时 GoAdmin 只读取表单中的字段
+	formList.AddField("位置", "latitude", db.Real, form.Custom).
+		FieldCustomContent(geoPicker).
+		FieldCustomJs(geoPickerJS()).
+		FieldMust().
+		FieldHelpMsg("在地图上点击或拖动标记选择位置，也可以直接填写坐标")
+
+	formList.AddField("经度", "longitude", db.Real, form.Text).FieldHide()
+
+	formList.SetTable("locations").SetTitle("地点").SetDescription("在地图上选择坐标的地点")
+
+	NewFormRules().
+		FieldRules("name", "名称", "required|max:100").
+		FieldRules("address", "地址", "max:255").
+		Apply(ctx, formList)
+
+	formList.SetPostValidator(withCoordinatesCheck(formList.Validator))
+
+	// 表单通过 models.SaveRow 写入，在同一个事务中补上创建和修改时间
+	withTxPostHook(ctx, locationsTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		return models.TouchLocation(ctx, id)
+	})
+
+	return
+}
+
+// withCoordinatesCheck 在表单原有的校验之后检查纬度和经度的范围
+func withCoordinatesCheck(validator types.FormPostFn) types.FormPostFn {
+	return func(values form2.Values) error {
+		if validator != nil {
+			if err := validator(values); err != nil {
+				return err
+			}
+		}
+		if _, ok := values["latitude"]; !ok {
+			return nil
+		}
+		var errs models.ValidationErrors
+		if lat, err := strconv.ParseFloat(strings.TrimSpace(values.Get("latitude")), 64); err != nil || lat < -90 || lat > 90 {
+			errs = append(errs, models.FieldError{Field: "latitude", Label: "纬度", Message: "应为 -90 到 90 之间的数字"})
+		}
+		if lng, err := strconv.ParseFloat(strings.TrimSpace(values.Get("longitude")), 64); err != nil || lng < -180 || lng > 180 {
+			errs = append(errs, models.FieldError{Field: "longitude", Label: "经度", Message: "应为 -180 到 180 之间的数字"})
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+}
+
+// rowFloat 将列表行中的值转换为浮点数，SQLite 的 real 字段读出为 float64
+func rowFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	default:
+		f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+		return f
+	}
+}
+
+// mapThumbnail 渲染以坐标为中心的地图缩略图
+// 按 Web 墨卡托投影计算坐标所在的瓦片和像素位置，用覆盖缩略图的几张瓦片拼接，中心显示标记，不依赖静态地图服务
+func mapThumbnail(lat, lng float64) template.HTML {
+	n := math.Exp2(mapThumbnailZoom)
+	// 超出墨卡托投影范围的纬度按边界处理
+	lat = math.Max(math.Min(lat, 85.0511), -85.0511)
+	rad := lat * math.Pi / 180
+	px := (lng + 180) / 360 * n * mapTileSize
+	py := (1 - math.Log(math.Tan(rad)+1/math.Cos(rad))/math.Pi) / 2 * n * mapTileSize
+
+	left := int(math.Floor(px)) - mapThumbnailWidth/2
+	top := int(math.Floor(py)) - mapThumbnailHeight/2
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<a href="https://www.openstreetmap.org/?mlat=%.6f&amp;mlon=%.6f#map=16/%.6f/%.6f" target="_blank" rel="noopener"`+
+		` style="display:block;position:relative;overflow:hidden;width:%dpx;height:%dpx;background:#e5e3df">`,
+		lat, lng, lat, lng, mapThumbnailWidth, mapThumbnailHeight)
+	tiles := int(n)
+	for ty := floorDiv(top, mapTileSize); ty <= floorDiv(top+mapThumbnailHeight-1, mapTileSize); ty++ {
+		if ty < 0 || ty >= tiles {
+			continue
+		}
+		for tx := floorDiv(left, mapTileSize); tx <= floorDiv(left+mapThumbnailWidth-1, mapTileSize); tx++ {
+			// 经度超过 180 度时瓦片横向循环
+			x := (tx%tiles + tiles) % tiles
+			src := strings.NewReplacer("{z}", strconv.Itoa(mapThumbnailZoom), "{x}", strconv.Itoa(x), "{y}", strconv.Itoa(ty)).
+				Replace(MapTileURL)
+			fmt.Fprintf(&b, `<img src="%s" alt="" loading="lazy" style="position:absolute;left:%dpx;top:%dpx;width:%dpx;height:%dpx;max-width:none">`,
+				template.HTMLEscapeString(src), tx*mapTileSize-left, ty*mapTileSize-top, mapTileSize, mapTileSize)
+		}
+	}
+	fmt.Fprintf(&b, `<i class="fa fa-map-marker text-red" style="position:absolute;left:%dpx;top:%dpx;font-size:24px;text-shadow:0 0 2px #fff"></i></a>`,
+		mapThumbnailWidth/2-7, mapThumbnailHeight/2-22)
+	return template.HTML(b.String())
+}
+
+// floorDiv 向下取整的整数除法，a 为负数时与 / 不同
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// geoPicker 位置控件的内容：上方为地图，下方为纬度和经度的输入框
+// 作为 form.Custom 字段的内容，由 GoAdmin 按 html/template 填入字段名和纬度；经度由 JS 从隐藏的 longitude 字段读取
+const geoPicker = template.HTML(`<div class="geo-picker" style="width:100%">
+  <div class="geo-picker-map" style="height:320px;border:1px solid #d2d6de;margin-bottom:8px"></div>
+  <div class="row">
+    <div class="col-sm-5">
+      <div class="input-group">
+        <span class="input-group-addon">纬度</span>
+        <input type="number" name="{{.Field}}" value="{{.Value}}" class="form-control geo-picker-lat" step="any" min="-90" max="90" required>
+      </div>
+    </div>
+    <div class="col-sm-5">
+      <div class="input-group">
+        <span class="input-group-addon">经度</span>
+        <input type="number" class="form-control geo-picker-lng" step="any" min="-180" max="180" required>
+      </div>
+    </div>
+    <div class="col-sm-2">
+      <button type="button" class="btn btn-default btn-block geo-picker-locate" title="使用浏览器的定位"><i class="fa fa-crosshairs"></i> 当前位置</button>
+    </div>
+  </div>
+</div>`)
+
+// geoPickerJS 位置控件的交互：按需加载 Leaflet，地图、标记和输入框互相同步
+// 没有坐标时地图以北京为中心，选择位置后才出现标记
+func geoPickerJS() template.JS {
+	options, _ := json.Marshal(map[string]interface{}{
+		"script": LeafletURL,
+		"css":    LeafletCSSURL,
+		"tiles":  MapTileURL,
+		"center": []float64{39.9042, 116.4074},
+	})
+	return template.JS(`
+(function (options) {
+    let root = $('.geo-picker'), lat = root.find('.geo-picker-lat'), lng = root.find('.geo-picker-lng');
+    let hidden = root.closest('form').find('input[type=hidden][name=longitude]');
+    lng.val(hidden.val());
+    lng.on('input change', function () {
+        hidden.val(lng.val());
+    });
+
+    if (!$('link[href="' + options.css + '"]').length) {
+        $('<link rel="stylesheet">').attr('href', options.css).appendTo('head');
+    }
+    let load = window.L ? $.when() : $.ajax({url: options.script, dataType: 'script', cache: true});
+    load.done(function () {
+        let has = lat.val() !== '' && lng.val() !== '';
+        let map = L.map(root.find('.geo-picker-map')[0]).setView(has ? [lat.val(), lng.val()] : options.center, has ? 15 : 11);
+        L.tileLayer(options.tiles, {
+            maxZoom: 19,
+            attribution: '&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a>'
+        }).addTo(map);
+
+        let marker = null;
+        function place(latlng, pan) {
+            if (!marker) {
+                marker = L.marker(latlng, {draggable: true}).addTo(map);
+                marker.on('dragend', function () {
+                    fill(marker.getLatLng());
+                });
+            } else {
+                marker.setLatLng(latlng);
+            }
+            if (pan) {
+                map.panTo(latlng);
+            }
+        }
+        function fill(latlng) {
+            let wrapped = latlng.wrap();
+            lat.val(wrapped.lat.toFixed(6));
+            lng.val(wrapped.lng.toFixed(6));
+            hidden.val(lng.val());
+        }
+
+        if (has) {
+            place([lat.val(), lng.val()], false);
+        }
+        map.on('click', function (e) {
+            place(e.latlng, false);
+            fill(e.latlng);
+        });
+        lat.add(lng).on('change', function () {
+            let a = parseFloat(lat.val()), b = parseFloat(lng.val());
+            if (!isNaN(a) && !isNaN(b) && Math.abs(a) <= 90 && Math.abs(b) <= 180) {
+                place([a, b], true);
+            }
+        });
+        root.find('.geo-picker-locate').click(function () {
+            if (!navigator.geolocation) {
+                swal('浏览器不支持定位', '', 'error');
+                return;
+            }
+            navigator.geolocation.getCurrentPosition(function (pos) {
+                let latlng = L.latLng(pos.coords.latitude, pos.coords.longitude);
+                place(latlng, true);
+                fill(latlng);
+            }, function (err) {
+                swal('定位失败', err.message, 'error');
+            });
+        });
+        // 表单在隐藏的标签页或弹窗中打开时，显示后重新计算地图的大小
+        setTimeout(function () {
+            map.invalidateSize();
+        }, 200);
+    }).fail(function () {
+        root.find('.geo-picker-map').html('<p class="text-muted" style="padding:12px">地图加载失败，可以直接填写坐标</p>');
+    });
+})(` + string(options) + `);
+`)
+}
@@ -0,0 +1,71 @@
+// Package tables 提供数据库表格模型定义
+// 本文件替换 GoAdmin 自带的管理员表格（manager、normal_manager）：在编辑表单中重置密码后，踢出该管理员的登录
+package tables
+
+import (
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
+
+// ManagerGenerators 返回 GoAdmin 管理员表格的生成函数，表格本身仍由 GoAdmin 的 SystemTable 生成
+//
+// 参数:
+//   - conn: 返回 GoAdmin 数据库连接的函数，通常传入 eng.DefaultConnection，在请求时才调用
+//
+// 返回值:
+//   - table.GeneratorList: manager 和 normal_manager 两个前缀的生成函数
+//
+// 使用示例:
+//
+//	eng.AddGenerators(tables.Generators, tables.ManagerGenerators(eng.DefaultConnection))
+//
+// 注意事项:
+//   - GoAdmin 注册自带表格时不覆盖已经存在的前缀，这里注册的生成函数优先生效
+//   - 管理员的密码被重置时通常意味着账号可能已经泄露，保存后该管理员的全部会话被踢出、刷新令牌全部吊销（见 models.RevokeAdminLogins）；
+//     重置自己的密码时保留当前会话，与个人资料页面的修改密码相同
+func ManagerGenerators(conn func() db.Connection) table.GeneratorList {
+	return table.GeneratorList{
+		"manager": func(ctx *context.Context) table.Table {
+			return withLoginRevoke(ctx, table.NewSystemTable(conn(), config.Get()).GetManagerTable(ctx))
+		},
+		"normal_manager": func(ctx *context.Context) table.Table {
+			return withLoginRevoke(ctx, table.NewSystemTable(conn(), config.Get()).GetNormalManagerTable(ctx))
+		},
+	}
+}
+
+// withLoginRevoke 包装管理员表格的修改函数，提交了新密码并保存成功后踢出该管理员的登录
+func withLoginRevoke(ctx *context.Context, t table.Table) table.Table {
+	f := t.GetForm()
+	update := f.UpdateFn
+	if update == nil {
+		return t
+	}
+	f.SetUpdateFn(func(values form2.Values) error {
+		if err := update(values); err != nil {
+			return err
+		}
+		if values.Get("password") == "" {
+			return nil
+		}
+		id, err := strconv.ParseInt(values.Get("id"), 10, 64)
+		if err != nil {
+			return err
+		}
+		var keepSID string
+		if id == auth.Auth(ctx).Id {
+			if cookie, err := ctx.Request.Cookie(auth.DefaultCookieKey); err == nil {
+				keepSID = cookie.Value
+			}
+		}
+		return models.RevokeAdminLogins(ctx.Request.Context(), id, keepSID)
+	})
+	return t
+}
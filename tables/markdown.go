@@ -0,0 +1,142 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现文章内容的 Markdown 编辑模式：config.yml 中的开关、Markdown 渲染和编辑器的实时预览接口
+package tables
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"gopkg.in/yaml.v2"
+)
+
+// 文章内容的编辑器
+const (
+	// EditorRichText 富文本编辑器，内容以 HTML 保存，默认使用
+	EditorRichText = "richtext"
+
+	// EditorMarkdown Markdown 编辑器，内容以 Markdown 原文保存，显示时渲染为 HTML
+	EditorMarkdown = "markdown"
+)
+
+// PostsEditor 文章表单中内容字段使用的编辑器，由 LoadPostsConfigFromYAML 从配置文件读取
+var PostsEditor = EditorRichText
+
+// MarkdownPreviewURL 编辑器实时预览请求的接口地址（在后台 URL 前缀下的路径），需要在 main 中注册 MarkdownPreview
+const MarkdownPreviewURL = "/posts/markdown/preview"
+
+// markdown Markdown 渲染器，启用表格、删除线、任务列表等 GFM 扩展
+// 未开启 html.WithUnsafe，原文中的 HTML 标签会被忽略，显示时不会执行其中的脚本
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// LoadPostsConfigFromYAML 从 YAML 配置文件的 posts 配置项读取文章表格的设置
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析失败，或 editor 不是 richtext、markdown 之一时返回错误
+//
+// 配置示例:
+//
+//	posts:
+//	  editor: markdown
+//
+// 注意事项:
+//   - 没有 posts 配置项或 editor 为空时使用富文本编辑器
+//   - 切换编辑器不会转换已保存的内容，富文本保存的 HTML 在 Markdown 模式下会按原文显示
+func LoadPostsConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg struct {
+		Posts struct {
+			Editor string `yaml:"editor"`
+		} `yaml:"posts"`
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析文章配置失败: %v", err)
+	}
+
+	switch cfg.Posts.Editor {
+	case "":
+		PostsEditor = EditorRichText
+	case EditorRichText, EditorMarkdown:
+		PostsEditor = cfg.Posts.Editor
+	default:
+		return fmt.Errorf("文章配置的 editor 应为 %s 或 %s: %s", EditorRichText, EditorMarkdown, cfg.Posts.Editor)
+	}
+	return nil
+}
+
+// RenderMarkdown 将 Markdown 原文渲染为 HTML，渲染失败时返回转义后的原文
+func RenderMarkdown(src string) template.HTML {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(src), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(src))
+	}
+	return template.HTML(buf.String())
+}
+
+// MarkdownPreview 返回 Markdown 编辑器的实时预览
+//
+// 请求格式:
+//
+//	POST content=<Markdown 原文>
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": {"html": "<p>...</p>"}}
+//
+// 使用示例:
+//
+//	eng.Data("POST", config.Url(tables.MarkdownPreviewURL), tables.MarkdownPreview)
+func MarkdownPreview(ctx *context.Context) {
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+		"data": map[string]interface{}{"html": RenderMarkdown(ctx.FormValue("content"))},
+	})
+}
+
+// markdownEditor Markdown 编辑器的内容：左侧编辑原文，右侧显示预览
+// 作为 form.Custom 字段的内容，由 GoAdmin 按 html/template 填入字段名和当前值
+const markdownEditor = template.HTML(`<div class="row markdown-editor" style="width:100%">
+  <div class="col-md-6">
+    <textarea name="{{.Field}}" class="form-control {{.Field}} markdown-source" rows="18" placeholder="支持 Markdown 语法">{{.Value}}</textarea>
+  </div>
+  <div class="col-md-6">
+    <div class="markdown-preview" style="min-height:380px;max-height:380px;overflow:auto;padding:6px 12px;border:1px solid #d2d6de;background:#fafafa"></div>
+  </div>
+</div>`)
+
+// markdownEditorJS 输入停止 300 毫秒后请求预览，打开表单时先预览一次当前内容
+func markdownEditorJS() template.JS {
+	return template.JS(`
+(function () {
+    let source = $('.markdown-source');
+    let preview = $('.markdown-preview');
+    let timer = null;
+    function render() {
+        $.post('` + config.Url(MarkdownPreviewURL) + `', {content: source.val()}, function (data) {
+            if (data.code === 200) {
+                preview.html(data.data.html);
+            }
+        });
+    }
+    source.on('input', function () {
+        clearTimeout(timer);
+        timer = setTimeout(render, 300);
+    });
+    render();
+})();
+`)
+}
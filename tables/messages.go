@@ -0,0 +1,143 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现站内信（messages）表格的模型配置，列表为当前登录管理员的收件箱
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 messages 前缀
+// 访问路径: /admin/info/messages
+// 功能: 管理员之间的站内信，列表只显示发给当前管理员的站内信
+func init() {
+	Register("messages", withAudit(GetMessagesTable))
+}
+
+// GetMessagesTable 获取站内信表格模型
+// 该函数创建并返回站内信表格模型，列表只显示发给当前登录管理员的站内信
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 按管理员隔离数据:
+//   - GoAdmin 每次请求都会调用生成函数，这里从 ctx 取出当前管理员，
+//     列表通过 WhereRaw 只查询 recipient_id 为该管理员的记录，地址中的筛选参数（如 ?recipient_id=3）不能覆盖这个条件
+//   - 列表的条件不作用于按编号读取的详情页和删除：
+//     详情按钮被隐藏，改为“查看”弹窗通过 models.ReadMessage 按收件人读取；
+//     直接访问详情地址时由 guardRowAccess 校验收件人，不是发给自己的站内信显示为空白，
+//     删除前通过 models.CheckMessageRecipient 校验，不能删除别人的站内信
+//   - 写信时发件人取当前管理员，站内信发出后不能修改
+func GetMessagesTable(ctx *context.Context) (messagesTable table.Table) {
+
+	user := auth.Auth(ctx)
+
+	messagesTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()).SetEditable(false))
+
+	info := messagesTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).SetSortDesc().HideDetailButton()
+
+	info.WhereRaw("messages.recipient_id = ?", user.Id)
+	guardRowAccess(ctx, messagesTable, func(ids []string) error {
+		return models.CheckMessageRecipient(ctx.Request.Context(), ids, user.Id)
+	})
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("发件人", "sender_name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("主题", "subject", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike}).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			if value.Row["is_read"] == int64(0) {
+				return template.HTML("<b>" + html.EscapeString(value.Value) + "</b>")
+			}
+			return value.Value
+		})
+
+	info.AddField("状态", "is_read", db.Tinyint).FieldDisplay(func(value types.FieldModel) interface{} {
+		if value.Value == "1" {
+			return template.HTML(`<span class="label label-default">已读</span>`)
+		}
+		return template.HTML(`<span class="label label-primary">未读</span>`)
+	}).FieldFilterable(types.FilterType{FormType: form.SelectSingle}).FieldFilterOptions(types.FieldOptions{
+		{Value: "0", Text: "未读"},
+		{Value: "1", Text: "已读"},
+	})
+
+	info.AddField("发送时间", "created_at", db.Timestamp).FieldSortable()
+
+	info.AddActionButton(ctx, "查看", action.PopUp("/admin/messages/read", "站内信",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			m, err := models.ReadMessage(ctx.Request.Context(), ctx.FormValue("id"), auth.Auth(ctx).Id)
+			if err != nil {
+				if errors.Is(err, models.ErrNotRecipient) {
+					return false, err.Error(), ""
+				}
+				return false, "读取站内信失败: " + err.Error(), ""
+			}
+			return true, "", messageView(m)
+		}))
+
+	info.SetPreDeleteFn(func(ids []string) error {
+		return models.CheckMessageRecipient(ctx.Request.Context(), ids, user.Id)
+	})
+
+	info.SetTable("messages").SetTitle("收件箱").SetDescription("发给我的站内信")
+
+	formList := messagesTable.GetForm()
+
+	formList.AddField("收件人", "recipient_id", db.Int, form.SelectSingle).
+		FieldOptionsFromTable("goadmin_users", "name", "id").FieldMust()
+
+	formList.AddField("主题", "subject", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("正文", "content", db.Text, form.TextArea).FieldMust()
+
+	// 发件人是提交表单的管理员，不在表单中填写
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if values.IsInsertPost() {
+			now := time.Now().Format("2006-01-02 15:04:05")
+			values.Add("sender_id", fmt.Sprint(user.Id))
+			values.Add("sender_name", user.Name)
+			values.Add("created_at", now)
+			values.Add("updated_at", now)
+		}
+		return values
+	})
+
+	formList.SetTable("messages").SetTitle("写信").SetDescription("给其他管理员发送站内信")
+
+	return
+}
+
+// messageView 渲染“查看”弹窗中的站内信，正文保留换行
+func messageView(m models.Message) template.HTML {
+	content := strings.ReplaceAll(html.EscapeString(m.Content), "\n", "<br>")
+	return template.HTML(fmt.Sprintf(`<div style="padding:10px">
+  <h4>%s</h4>
+  <p class="text-muted">%s 发送于 %s</p>
+  <hr>
+  <div>%s</div>
+</div>`, html.EscapeString(m.Subject), html.EscapeString(m.SenderName),
+		m.CreatedAt.Format("2006-01-02 15:04:05"), content))
+}
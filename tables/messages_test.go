@@ -0,0 +1,52 @@
+package tables
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+)
+
+func TestMessagesInboxOfOtherAdmin(t *testing.T) {
+	conn := openTestDB(t)
+	const operator = 2
+	res, err := conn.Exec(`INSERT INTO messages (sender_id, sender_name, recipient_id, subject, content, is_read, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, operator, "operator", 1, "secret-subject", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+
+	cases := []string{"", "?recipient_id=1", "?messages.recipient_id=1", "?sender_id=2"}
+	for _, query := range cases {
+		t.Run("列表"+query, func(t *testing.T) {
+			ctx := testContext(t, conn, operator, "/admin/info/messages"+query)
+			tb := Generators["messages"](ctx)
+			info := tb.GetInfo()
+			data, err := tb.GetData(ctx, parameter.GetParam(ctx.Request.URL, info.DefaultPageSize, info.SortField, info.GetSort()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, row := range data.InfoList {
+				if string(row["id"].Content) == strconv.FormatInt(id, 10) {
+					t.Fatal("收件箱中显示了发给别人的站内信")
+				}
+			}
+		})
+	}
+
+	t.Run("详情", func(t *testing.T) {
+		pk := strconv.FormatInt(id, 10)
+		ctx := testContext(t, conn, operator, "/admin/info/messages/detail?__goadmin_detail_pk="+pk)
+		tb := Generators["messages"](ctx)
+		info, err := tb.GetDataWithId(parameter.GetParam(ctx.Request.URL, 10, "id", "desc").WithPKs(pk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range info.FieldList {
+			if f.Value == "secret-subject" {
+				t.Fatal("详情中显示了发给别人的站内信")
+			}
+		}
+	})
+}
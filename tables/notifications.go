@@ -0,0 +1,151 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现站内通知（notifications）表格的模型配置
+package tables
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	editType "github.com/purpose168/GoAdmin/template/types/table"
+)
+
+// init 在 Generators 中注册 notifications 前缀
+// 访问路径: /admin/info/notifications
+// 功能: 站内通知表格，可以给管理员发送通知，或把自己的通知全部标为已读
+func init() {
+	Register("notifications", withAudit(GetNotificationsTable))
+}
+
+// GetNotificationsTable 获取站内通知表格模型
+// 该函数创建并返回站内通知表格模型，用于管理后台发送和查看通知
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 接收人关联 goadmin_users 显示管理员名称，列表中可以直接切换已读状态
+//   - “全部标为已读”按钮只处理当前登录管理员的通知，其他管理员的通知不受影响
+//   - 顶部导航栏的铃铛通过 /admin/api/notifications/unread 读取未读数，见 pages.UnreadNotifications
+//   - 每个管理员在通知中心（pages.NotificationsPage）中查看和处理自己的通知，点击铃铛即进入
+func GetNotificationsTable(ctx *context.Context) (notificationsTable table.Table) {
+
+	notificationsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := notificationsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("标题", "title", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("级别", "level", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(notificationLevelOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return NotificationLevelLabel(value.Value)
+		})
+
+	info.AddField("已读", "is_read", db.Tinyint).FieldDisplay(func(value types.FieldModel) interface{} {
+		if value.Value == "1" {
+			return "已读"
+		}
+		return "未读"
+	}).FieldEditAble(editType.Switch).FieldEditOptions(types.FieldOptions{
+		{Value: "0", Text: "未读"},
+		{Value: "1", Text: "已读"},
+	}).FieldFilterable(types.FilterType{FormType: form.SelectSingle}).FieldFilterOptions(types.FieldOptions{
+		{Value: "0", Text: "未读"},
+		{Value: "1", Text: "已读"},
+	})
+
+	info.AddField("接收人", "name", db.Varchar).FieldJoin(types.Join{
+		Field:     "admin_id",
+		JoinField: "id",
+		Table:     "goadmin_users",
+	}).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("创建时间", "created_at", db.Timestamp).FieldSortable()
+
+	info.AddButton(ctx, "全部标为已读", icon.Check, action.Ajax("/admin/notifications/read_all",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			n, err := models.MarkAllNotificationsRead(ctx.Request.Context(), auth.Auth(ctx).Id)
+			if err != nil {
+				return false, "标记已读失败: " + err.Error(), ""
+			}
+			return true, fmt.Sprintf("已将 %d 条通知标为已读", n), ""
+		}).SetSuccessJS(notificationsReadJS))
+
+	info.SetTable("notifications").SetTitle("站内通知").SetDescription("站内通知")
+
+	formList := notificationsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("接收人", "admin_id", db.Int, form.SelectSingle).
+		FieldOptionsFromTable("goadmin_users", "name", "id").FieldMust()
+
+	formList.AddField("标题", "title", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("级别", "level", db.Varchar, form.SelectSingle).
+		FieldOptions(notificationLevelOptions).FieldDefault(models.NotificationInfo).FieldMust()
+
+	// 表单按行写入，不会自动填充时间，新建时补上创建时间，修改时更新修改时间
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		now := time.Now().Format("2006-01-02 15:04:05")
+		if values.IsInsertPost() {
+			values.Add("created_at", now)
+		}
+		if !values.IsSingleUpdatePost() {
+			values.Add("updated_at", now)
+		}
+		return values
+	})
+
+	formList.SetTable("notifications").SetTitle("站内通知").SetDescription("站内通知")
+
+	return
+}
+
+// notificationLevelOptions 通知级别选项，筛选和表单共用
+var notificationLevelOptions = types.FieldOptions{
+	{Value: models.NotificationInfo, Text: "消息"},
+	{Value: models.NotificationSuccess, Text: "成功"},
+	{Value: models.NotificationWarning, Text: "警告"},
+	{Value: models.NotificationDanger, Text: "紧急"},
+}
+
+// NotificationLevelLabel 将通知级别渲染为同色标签，未知级别显示为灰色，通知中心（pages.NotificationsPage）也使用该标签
+func NotificationLevelLabel(level string) template.HTML {
+	for _, o := range notificationLevelOptions {
+		if o.Value == level {
+			return template.HTML(fmt.Sprintf(`<span class="label label-%s">%s</span>`, level, o.Text))
+		}
+	}
+	return template.HTML(`<span class="label label-default">` + html.EscapeString(level) + `</span>`)
+}
+
+// notificationsReadJS 标记完成后刷新列表，并通知顶部铃铛立即更新未读数
+const notificationsReadJS = template.JS(`if (data.code === 0) {
+    swal(data.msg, '', 'success');
+    $.pjax.reload('#pjax-container');
+    $(document).trigger('notifications:refresh');
+} else {
+    swal(data.msg, '', 'error');
+}`)
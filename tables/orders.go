@@ -0,0 +1,208 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现订单（orders）表格的模型配置，支持按下单时间范围筛选，详情页列出订单明细（order_items）
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	tmpl "github.com/purpose168/GoAdmin/template"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 orders 前缀
+// 访问路径: /admin/info/orders
+// 功能: 订单管理表格，仪表板的销售额信息框会带上日期范围跳转到这里
+func init() {
+	Register("orders", withAudit(GetOrdersTable))
+}
+
+// GetOrdersTable 获取订单表格模型
+// 该函数创建并返回订单表格模型，用于管理后台的订单展示和编辑
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 订单号支持模糊筛选，状态支持下拉筛选
+//   - 下单时间支持日期时间范围筛选，仪表板的销售额信息框通过
+//     created_at_start__goadmin / created_at_end__goadmin 查询参数跳转到该表格
+//   - 列表关联 order_items 显示每个订单的商品件数，详情页以嵌套表格列出明细和累计金额
+//   - 每行的"发货""送达"按钮按 待处理 → 已发货 → 已送达 的顺序推进订单状态
+//   - 配置了只读副本时，列表和导出从副本读取
+func GetOrdersTable(ctx *context.Context) table.Table {
+	return withReadReplica(ctx, newOrdersTable)
+}
+
+// newOrdersTable 使用指定的数据库连接创建订单表格模型
+func newOrdersTable(ctx *context.Context, conn string) (ordersTable table.Table) {
+
+	ordersTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriverAndConnection(defaultDriver(), conn))
+
+	info := ordersTable.GetInfo().SetFilterFormLayout(form.LayoutFilter)
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	// 订单号使用 LIKE 模糊匹配
+	info.AddField("订单号", "order_no", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("商品", "product", db.Varchar)
+
+	// 客户名称按编号从客户列表中查找，不与 order_items 一起关联，避免分组后名称按明细数重复
+	customers := customerOptions(models.AllCustomers(ctx.Request.Context()))
+	info.AddField("客户", "customer_id", db.Int).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(customers).
+		FieldDisplay(customerNameDisplay(customers))
+
+	info.AddField("状态", "status", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(orderStatusOptions)
+
+	info.AddField("金额", "amount", db.Decimal).FieldSortable()
+
+	// 关联 order_items 后，GoAdmin 按订单分组，把每条明细的数量用分隔符拼接为一个字段，这里求和显示
+	info.AddField("件数", "quantity", db.Int).
+		FieldJoin(types.Join{Table: "order_items", Field: "id", JoinField: "order_id"}).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			total := 0
+			for _, q := range strings.Split(value.Value, types.JoinFieldValueDelimiter) {
+				n, _ := strconv.Atoi(q)
+				total += n
+			}
+			return strconv.Itoa(total)
+		})
+
+	// 下单时间使用日期时间范围筛选，仪表板的下钻链接依赖该筛选项
+	info.AddField("下单时间", "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
+
+	// 状态流转按钮显示在每一行，当前状态不符合时由 models.TransitionOrder 拒绝并提示原因
+	info.AddActionButton(ctx, "发货", orderTransitionAction("ship", models.OrderShipped))
+	info.AddActionButton(ctx, "送达", orderTransitionAction("deliver", models.OrderDone))
+
+	info.SetTable("orders").SetTitle("订单").SetDescription("订单")
+
+	formList := ordersTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("订单号", "order_no", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("商品", "product", db.Varchar, form.Text)
+
+	// 客户较多时不在页面中列出全部客户，输入关键词后远程搜索（见 customerSearch），选项只包含当前选中的客户
+	// FieldOnSearch: 停止输入 300 毫秒后请求匹配的客户，滚动到下拉框底部时加载下一页
+	// 清空后保存为 0，表示未关联客户
+	formList.AddField("所属客户", "customer_id", db.Int, form.SelectSingle).
+		FieldOptionInitFn(customerSelectOptions(ctx)).
+		FieldOptionExtJS(customerSelectJS()).
+		FieldOnSearch("/search/customer", customerSearch, customerSearchDelay).
+		FieldPostFilterFn(customerIDFilter).
+		FieldDefault("0")
+
+	formList.AddField("状态", "status", db.Varchar, form.SelectSingle).
+		FieldOptions(orderStatusOptions).FieldDefault("待处理")
+
+	formList.AddField("金额", "amount", db.Decimal, form.Currency)
+
+	formList.AddField("下单时间", "created_at", db.Timestamp, form.Datetime)
+
+	formList.SetTable("orders").SetTitle("订单").SetDescription("订单")
+
+	detail := ordersTable.GetDetail()
+
+	detail.AddField("编号", "id", db.Int)
+	detail.AddField("订单号", "order_no", db.Varchar)
+	detail.AddField("客户", "customer_id", db.Int).FieldDisplay(customerNameDisplay(customers))
+	detail.AddField("状态", "status", db.Varchar)
+	detail.AddField("金额", "amount", db.Decimal)
+	detail.AddField("下单时间", "created_at", db.Timestamp)
+
+	// items 不是 orders 表的字段，不参与查询，只按主键读取明细并渲染为表格
+	detail.AddField("明细", "items", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		return orderItemsTable(models.OrderItems(ctx.Request.Context(), value.ID))
+	})
+
+	detail.SetTable("orders").SetTitle("订单详情").SetDescription("订单")
+
+	return
+}
+
+// orderStatusOptions 订单状态选项，筛选和表单共用
+var orderStatusOptions = types.FieldOptions{
+	{Value: models.OrderPending, Text: models.OrderPending},
+	{Value: models.OrderShipped, Text: models.OrderShipped},
+	{Value: models.OrderDone, Text: models.OrderDone},
+}
+
+// orderTransitionAction 返回将订单流转到 to 状态的行按钮动作，流转成功后写入审计日志
+// 按钮请求 /admin/orders/<name>，name 只用于区分路由
+func orderTransitionAction(name, to string) types.Action {
+	return action.Ajax("/admin/orders/"+name,
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			ids := []string{ctx.FormValue("id")}
+			before := snapshot(ctx.Request.Context(), "orders", "id", ids)
+			if err := models.TransitionOrder(ctx.Request.Context(), ids[0], to); err != nil {
+				if errors.Is(err, models.ErrOrderTransition) {
+					return false, err.Error(), ""
+				}
+				return false, "修改订单状态失败: " + err.Error(), ""
+			}
+			auditChange(ctx, "orders", "id", models.AuditUpdate, ids, before)
+			return true, "订单已" + strings.TrimPrefix(to, "已"), ""
+		})
+}
+
+// orderItemsTable 将订单明细渲染为嵌套表格，每行显示小计和到该行为止的累计金额
+func orderItemsTable(items []models.OrderItem) template.HTML {
+	if len(items) == 0 {
+		return `<p class="text-muted">暂无明细</p>`
+	}
+
+	var (
+		rows    = make([]map[string]types.InfoItem, 0, len(items)+1)
+		running float64
+		count   int
+	)
+	for _, it := range items {
+		running += it.Subtotal()
+		count += it.Quantity
+		rows = append(rows, map[string]types.InfoItem{
+			"商品": {Content: template.HTML(html.EscapeString(it.Product))},
+			"单价": {Content: template.HTML(fmt.Sprintf("¥%.2f", it.Price))},
+			"数量": {Content: template.HTML(strconv.Itoa(it.Quantity))},
+			"小计": {Content: template.HTML(fmt.Sprintf("¥%.2f", it.Subtotal()))},
+			"累计": {Content: template.HTML(fmt.Sprintf("¥%.2f", running))},
+		})
+	}
+	rows = append(rows, map[string]types.InfoItem{
+		"商品": {Content: "<b>合计</b>"},
+		"数量": {Content: template.HTML(fmt.Sprintf("<b>%d</b>", count))},
+		"累计": {Content: template.HTML(fmt.Sprintf("<b>¥%.2f</b>", running))},
+	})
+
+	return tmpl.Default().Table().SetType("table").SetInfoList(rows).SetThead(types.Thead{
+		{Head: "商品"},
+		{Head: "单价"},
+		{Head: "数量"},
+		{Head: "小计"},
+		{Head: "累计"},
+	}).GetContent()
+}
@@ -0,0 +1,145 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现按创建人限制记录的访问：非超级管理员只能看到、修改和删除自己创建的用户和文章
+package tables
+
+import (
+	"strconv"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/constant"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// ownerField 保存创建人的字段，值为创建记录的管理员 ID
+const ownerField = "created_by"
+
+// ownedTables 按创建人限制修改的数据表，表中需要有 created_by 字段
+// 表格自定义的操作（如软删除的"恢复"、用户的"重置密码"）通过 checkOwner 按这里的配置校验
+var ownedTables = map[string]bool{
+	"users": true,
+	"posts": true,
+
+	// 定时导出以创建人的权限读取表格，只能由创建人修改
+	"export_schedules": true,
+
+	// API 密钥以签发人的权限访问表格，只能由签发人修改和吊销
+	"api_keys": true,
+}
+
+// withOwnership 让表格的记录只能由创建人修改和删除
+//
+// 参数:
+//   - ctx: 上下文对象，用于取出当前管理员
+//   - t: 表格模型，需在 withTxPostHook 之后调用（created_by 由它设置的写入函数写入），数据表需要在 ownedTables 中
+//
+// 功能说明:
+//  1. 新增记录时把 created_by 设置为当前管理员，表单提交的 created_by 被忽略，创建人不能修改
+//  2. 非超级管理员的列表通过 WhereRaw 只查询自己创建的记录；不使用 Where，
+//     GoAdmin 在请求带有同名的筛选参数（如 ?created_by=1）时会跳过 Where 的条件
+//  3. 详情和编辑页面按编号读取记录，不经过列表的条件，由 guardRowAccess 检查创建人
+//  4. 修改（包括列表中的单字段修改）在校验时、删除在 PreDeleteFn 中按编号检查创建人，
+//     直接提交别人的记录编号同样会被拒绝
+//
+// 注意事项:
+//   - 超级管理员不受限制；created_by 为 0 的记录（增加该字段之前创建的）只有超级管理员可以修改
+func withOwnership(ctx *context.Context, t table.Table) {
+	var (
+		user = auth.Auth(ctx)
+		info = t.GetInfo()
+		name = info.Table
+		pk   = t.GetPrimaryKey().Name
+	)
+
+	if !user.IsSuperAdmin() {
+		info.WhereRaws = andWhereRaw(info.WhereRaws, name+"."+ownerField+" = ?", user.Id)
+		guardRowAccess(ctx, t, func(ids []string) error {
+			return checkOwner(ctx, name, ids)
+		})
+	}
+
+	ownForm := func(f *types.FormPanel) {
+		// 按提交的主键检查，不依赖可以伪造的新增标记；新增表单不提交主键
+		validator := f.Validator
+		f.SetPostValidator(func(values form2.Values) error {
+			if id := values.Get(pk); id != "" {
+				if err := checkOwner(ctx, name, []string{id}); err != nil {
+					return err
+				}
+			}
+			if validator != nil {
+				return validator(values)
+			}
+			return nil
+		})
+
+		insert, update := f.InsertFn, f.UpdateFn
+		f.SetInsertFn(func(values form2.Values) error {
+			values.Delete(ownerField)
+			values.Add(ownerField, strconv.FormatInt(user.Id, 10))
+			return insert(values)
+		})
+		f.SetUpdateFn(func(values form2.Values) error {
+			values.Delete(ownerField)
+			return update(values)
+		})
+	}
+
+	ownForm(t.GetForm())
+	if f := t.GetActualNewForm(); f != t.GetForm() {
+		ownForm(f)
+	}
+
+	preDelete := info.PreDeleteFn
+	info.SetPreDeleteFn(func(ids []string) error {
+		if err := checkOwner(ctx, name, ids); err != nil {
+			return err
+		}
+		if preDelete != nil {
+			return preDelete(ids)
+		}
+		return nil
+	})
+}
+
+// checkOwner 检查当前管理员能否修改这些记录
+// 超级管理员和不在 ownedTables 中的数据表不受限制，否则记录都需要是当前管理员创建的，不是时返回 models.ErrNotOwner
+func checkOwner(ctx *context.Context, table string, ids []string) error {
+	user := auth.Auth(ctx)
+	if user.IsSuperAdmin() || !ownedTables[table] {
+		return nil
+	}
+	return models.CheckOwner(ctx.Request.Context(), table, ids, user.Id)
+}
+
+// guardRowAccess 请求的记录不能由当前管理员查看时，让表格按编号读取记录时返回空数据
+//
+// 参数:
+//   - ctx: 上下文对象，从中取出请求的记录编号
+//   - t: 表格模型
+//   - check: 检查当前管理员能否查看这些记录，不能时返回错误
+//
+// 功能说明:
+//   - GoAdmin 的详情、编辑页面以及表单提交失败后重新显示的表单通过 GetDataWithId 按编号读取记录，
+//     不经过列表的 Where 和 WhereRaw，只隐藏详情按钮也可以直接访问这些地址
+//   - 表格每次请求都会重新生成，这里在生成时检查请求中的记录编号，不能查看时替换详情的 GetDataFn，
+//     页面中只显示空白的字段
+func guardRowAccess(ctx *context.Context, t table.Table, check func(ids []string) error) {
+	ids := []string{
+		ctx.Query(constant.DetailPKKey),
+		ctx.Query(constant.EditPKKey),
+		ctx.FormValue(t.GetPrimaryKey().Name),
+	}
+	for _, id := range ids {
+		if id != "" && check([]string{id}) != nil {
+			t.GetDetail().SetGetDataFn(func(parameter.Parameters) ([]map[string]interface{}, int) {
+				return nil, 0
+			})
+			return
+		}
+	}
+}
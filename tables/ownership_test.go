@@ -0,0 +1,170 @@
+package tables
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	_ "github.com/purpose168/GoAdmin-themes/sword"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	_ "github.com/purpose168/GoAdmin/modules/db/drivers/sqlite"
+	"github.com/purpose168/GoAdmin/modules/service"
+	adminModels "github.com/purpose168/GoAdmin/plugins/admin/models"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
+
+var (
+	testConnOnce sync.Once
+	testConn     db.Connection
+)
+
+// openTestDB 复制示例数据库 admin.db 到临时目录并初始化 GoAdmin 和数据模型，整个测试只初始化一次
+func openTestDB(t *testing.T) db.Connection {
+	t.Helper()
+	testConnOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "tables-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "admin.db")
+		if err := copyFile("../admin.db", file); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.Initialize(&config.Config{
+			Databases: config.DatabaseList{"default": {Driver: "sqlite", File: file}},
+			UrlPrefix: "admin",
+			Language:  "cn",
+			Theme:     "sword",
+		})
+		testConn = db.GetConnectionByDriver("sqlite").InitDB(cfg.Databases)
+		srv := service.GetServices()
+		srv.Add("sqlite", testConn)
+		table.SetServices(srv)
+		models.Init(testConn, models.DefaultORMConfig)
+	})
+	if testConn == nil {
+		t.Fatal("初始化测试数据库失败")
+	}
+	return testConn
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// testContext 构造以 userID 对应的管理员身份访问 path 的请求
+func testContext(t *testing.T, conn db.Connection, userID int64, path string) *context.Context {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.NewContext(req)
+	ctx.SetUserValue("user", adminModels.User().SetConn(conn).Find(userID).WithRoles().WithPermissions())
+	return ctx
+}
+
+func TestOwnershipListIgnoresOwnerFilter(t *testing.T) {
+	conn := openTestDB(t)
+	// 示例数据中管理员 1 为超级管理员，2 为普通管理员
+	const operator = 2
+	for _, owner := range []int{1, 1, operator} {
+		if _, err := conn.Exec(`INSERT INTO users (name, created_by) VALUES (?, ?)`, "owned", owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 筛选参数与创建人的条件同时生效，筛选别人创建的记录时列表为空
+	cases := []struct {
+		name  string
+		query string
+		rows  int
+	}{
+		{"不带参数", "", 1},
+		{"筛选别人创建的记录", "?created_by=1", 0},
+		{"带表名的筛选参数", "?users.created_by=1", 1},
+		{"筛选自己创建的记录", "?created_by=2", 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := testContext(t, conn, operator, "/admin/info/users"+c.query)
+			tb := Generators["users"](ctx)
+			info := tb.GetInfo()
+			params := parameter.GetParam(ctx.Request.URL, info.DefaultPageSize, info.SortField, info.GetSort())
+			data, err := tb.GetData(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.InfoList) != c.rows {
+				t.Fatalf("列表有 %d 行，want %d", len(data.InfoList), c.rows)
+			}
+			ids := make([]string, 0, len(data.InfoList))
+			for _, row := range data.InfoList {
+				ids = append(ids, string(row["id"].Content))
+			}
+			if err := models.CheckOwner(ctx.Request.Context(), "users", ids, operator); err != nil {
+				t.Fatalf("列表中有别人创建的记录: %v", err)
+			}
+		})
+	}
+}
+
+func TestOwnershipDetailOfOthersRecord(t *testing.T) {
+	conn := openTestDB(t)
+	const operator = 2
+	res, err := conn.Exec(`INSERT INTO users (name, created_by) VALUES (?, ?)`, "secret-name", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+
+	cases := []struct {
+		name    string
+		userID  int64
+		visible bool
+	}{
+		{"普通管理员查看别人创建的记录", operator, false},
+		{"超级管理员不受限制", 1, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := "/admin/info/users/detail?__goadmin_detail_pk=" + strconv.FormatInt(id, 10)
+			ctx := testContext(t, conn, c.userID, path)
+			tb := Generators["users"](ctx)
+			info, err := tb.GetDataWithId(parameter.GetParam(ctx.Request.URL, 10, "id", "desc").WithPKs(strconv.FormatInt(id, 10)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found bool
+			for _, f := range info.FieldList {
+				if f.Field == "name" && f.Value == "secret-name" {
+					found = true
+				}
+			}
+			if found != c.visible {
+				t.Fatalf("详情中显示了记录: %v, want %v", found, c.visible)
+			}
+		})
+	}
+}
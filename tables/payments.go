@@ -0,0 +1,191 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现支付记录（payments）表格的模型配置，每行的收款、退款、作废按钮按支付状态显示
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 payments 前缀
+// 访问路径: /admin/info/payments
+// 功能: 支付记录表格，每行按支付状态显示收款、退款、作废按钮
+func init() {
+	Register("payments", withAudit(GetPaymentsTable))
+}
+
+// GetPaymentsTable 获取支付记录表格模型
+// 该函数创建并返回支付记录表格模型，用于管理后台查看支付记录并推进其状态
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 新建的支付记录处于已授权状态，状态不能在表单中修改
+//   - 每行只显示当前状态允许的操作：已授权可以收款或作废，已收款可以退款
+//   - 操作由 models.TransitionPayment 在服务端再次校验，成功后写入审计日志并刷新列表
+//
+// 按钮的显示:
+//   - GoAdmin 的行按钮对所有行相同，状态列在 data-actions 中列出允许的操作，
+//     paymentActionsJS 隐藏其余按钮；按钮标题中的 payment-action 标记用于识别按钮
+func GetPaymentsTable(ctx *context.Context) (paymentsTable table.Table) {
+
+	paymentsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := paymentsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter)
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("订单号", "order_no", db.Varchar).FieldJoin(types.Join{
+		Field:     "order_id",
+		JoinField: "id",
+		Table:     "orders",
+	}).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("金额", "amount", db.Real).FieldSortable().FieldDisplay(func(value types.FieldModel) interface{} {
+		amount, _ := strconv.ParseFloat(value.Value, 64)
+		return fmt.Sprintf("¥%.2f", amount)
+	})
+
+	info.AddField("支付方式", "method", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(paymentMethodOptions)
+
+	info.AddField("状态", "status", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(paymentStatusOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return paymentStatusLabel(value.Value)
+		})
+
+	info.AddField("创建时间", "created_at", db.Timestamp).FieldSortable()
+
+	info.AddActionButton(ctx, paymentActionTitle(models.PaymentCapture, "收款"), paymentAction(models.PaymentCapture, "已收款"))
+	info.AddActionButton(ctx, paymentActionTitle(models.PaymentRefund, "退款"), paymentAction(models.PaymentRefund, "已退款"))
+	info.AddActionButton(ctx, paymentActionTitle(models.PaymentVoid, "作废"), paymentAction(models.PaymentVoid, "已作废"))
+
+	info.AddJS(paymentActionsJS)
+
+	info.SetTable("payments").SetTitle("支付记录").SetDescription("支付记录")
+
+	formList := paymentsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("订单", "order_id", db.Int, form.SelectSingle).
+		FieldOptionsFromTable("orders", "order_no", "id").FieldMust()
+
+	formList.AddField("金额", "amount", db.Real, form.Currency).FieldMust()
+
+	formList.AddField("支付方式", "method", db.Varchar, form.SelectSingle).
+		FieldOptions(paymentMethodOptions).FieldMust()
+
+	// 表单按行写入，不会自动填充时间，新建时补上创建时间，修改时更新修改时间
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		now := time.Now().Format("2006-01-02 15:04:05")
+		if values.IsInsertPost() {
+			values.Add("created_at", now)
+		}
+		if !values.IsSingleUpdatePost() {
+			values.Add("updated_at", now)
+		}
+		return values
+	})
+
+	formList.SetTable("payments").SetTitle("支付记录").SetDescription("支付记录")
+
+	return
+}
+
+// paymentMethodOptions 支付方式选项，筛选和表单共用
+var paymentMethodOptions = types.FieldOptions{
+	{Value: "支付宝", Text: "支付宝"},
+	{Value: "微信", Text: "微信"},
+	{Value: "银行卡", Text: "银行卡"},
+}
+
+// paymentStatusOptions 支付状态选项
+var paymentStatusOptions = types.FieldOptions{
+	{Value: models.PaymentAuthorized, Text: models.PaymentAuthorized},
+	{Value: models.PaymentCaptured, Text: models.PaymentCaptured},
+	{Value: models.PaymentRefunded, Text: models.PaymentRefunded},
+	{Value: models.PaymentVoided, Text: models.PaymentVoided},
+}
+
+// paymentStatusColors 各支付状态的标签颜色
+var paymentStatusColors = map[string]string{
+	models.PaymentAuthorized: "info",
+	models.PaymentCaptured:   "success",
+	models.PaymentRefunded:   "warning",
+	models.PaymentVoided:     "default",
+}
+
+// paymentStatusLabel 将支付状态渲染为标签，data-actions 列出该状态允许的操作
+func paymentStatusLabel(status string) template.HTML {
+	color, ok := paymentStatusColors[status]
+	if !ok {
+		color = "default"
+	}
+	return template.HTML(fmt.Sprintf(`<span class="label label-%s payment-status" data-actions="%s">%s</span>`,
+		color, strings.Join(models.PaymentActions(status), ","), html.EscapeString(status)))
+}
+
+// paymentActionTitle 返回支付操作按钮的标题，带有识别按钮用的标记
+func paymentActionTitle(name, title string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<span class="payment-action" data-action="%s">%s</span>`, name, title))
+}
+
+// paymentAction 返回执行支付操作的行按钮动作，成功后写入审计日志
+// 按钮请求 /admin/payments/<name>，done 为成功后的提示
+func paymentAction(name, done string) types.Action {
+	return action.Ajax("/admin/payments/"+name,
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			ids := []string{ctx.FormValue("id")}
+			before := snapshot(ctx.Request.Context(), "payments", "id", ids)
+			if err := models.TransitionPayment(ctx.Request.Context(), ids[0], name); err != nil {
+				if errors.Is(err, models.ErrPaymentTransition) {
+					return false, err.Error(), ""
+				}
+				return false, "修改支付状态失败: " + err.Error(), ""
+			}
+			auditChange(ctx, "payments", "id", models.AuditUpdate, ids, before)
+			return true, done, ""
+		}).SetSuccessJS(paymentActionDoneJS)
+}
+
+// paymentActionsJS 按状态列的 data-actions 隐藏每行不允许的操作按钮
+const paymentActionsJS = template.JS(`
+$('.payment-status').each(function () {
+    let allowed = String($(this).data('actions') || '').split(',');
+    $(this).closest('tr').find('.payment-action').each(function () {
+        $(this).closest('li').toggle(allowed.indexOf($(this).data('action')) >= 0);
+    });
+});
+`)
+
+// paymentActionDoneJS 支付操作完成后刷新列表，按钮按新的状态重新显示
+const paymentActionDoneJS = template.JS(`if (data.code === 0) {
+    swal(data.msg, '', 'success');
+    $.pjax.reload('#pjax-container');
+} else {
+    swal(data.msg, '', 'error');
+}`)
@@ -0,0 +1,343 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表格的 PDF 导出：每个表格顶部的"PDF"按钮按列表当前的筛选、排序和显示的列导出分页的 PDF
+package tables
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gopkg.in/yaml.v2"
+)
+
+// PDFExportURL 导出 PDF 的地址（在后台 URL 前缀下的路径），__prefix 为表格前缀，其余查询参数与列表页相同
+const PDFExportURL = "/pdf_export"
+
+// pdfExportMaxRows 一次最多导出的行数，超出的部分不导出，在页眉中注明
+const pdfExportMaxRows = 5000
+
+// pdfFont 导出使用的 TrueType 字体，由 LoadPDFConfigFromYAML 读取
+// 为空时使用 PDF 内置的 Helvetica 字体，内置字体只包含西文字符，中文会显示为问号
+var pdfFont []byte
+
+// LoadPDFConfigFromYAML 从 YAML 配置文件的 pdf 配置项读取导出 PDF 使用的字体
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析配置失败，或配置的字体文件无法读取时返回错误
+//
+// 配置示例:
+//
+//	pdf:
+//	  font: ./fonts/NotoSansSC-Regular.ttf
+//
+// 注意事项:
+//   - 字体必须是 TrueType（.ttf）格式，不支持字体集合（.ttc）和 OpenType CFF（.otf）
+//   - 字体在启动时读入内存，修改字体文件后需要重启
+func LoadPDFConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg struct {
+		PDF struct {
+			Font string `yaml:"font"`
+		} `yaml:"pdf"`
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析 PDF 配置失败: %v", err)
+	}
+
+	if cfg.PDF.Font == "" {
+		pdfFont = nil
+		log.Println("未配置 pdf.font，导出的 PDF 中的中文将无法显示")
+		return nil
+	}
+	pdfFont, err = ioutil.ReadFile(cfg.PDF.Font)
+	if err != nil {
+		return fmt.Errorf("读取 PDF 字体失败: %v", err)
+	}
+	return nil
+}
+
+// withPDFExport 在表格顶部添加"PDF"按钮，在新窗口中打开当前列表的 PDF
+// 按钮的地址只带表格前缀，点击时由 pdfExportJS 补上列表页当前的查询参数
+func withPDFExport(prefix string, gen table.Generator) table.Generator {
+	return func(ctx *context.Context) table.Table {
+		t := gen(ctx)
+		t.GetInfo().AddButton(ctx, "PDF", icon.FilePdfO,
+			action.JumpWithTarget(config.Url(PDFExportURL)+"?"+parameter.Prefix+"="+url.QueryEscape(prefix), "_blank")).
+			AddJS(pdfExportJS())
+		return t
+	}
+}
+
+// pdfExportJS 打开 PDF 前把列表页地址中的筛选、排序和显示的列追加到按钮的地址上
+func pdfExportJS() template.JS {
+	return template.JS(`
+$(document).off('click.pdfExport').on('click.pdfExport', 'a[href^="` + config.Url(PDFExportURL) + `"]', function () {
+    let base = $(this).attr('href').split('&')[0];
+    let query = window.location.search.replace(/^\?/, '');
+    $(this).attr('href', query ? base + '&' + query : base);
+});
+`)
+}
+
+// ExportPDF 导出表格的 PDF
+// 查询参数与列表页相同，导出的是列表当前显示的列，按当前的筛选和排序读取全部数据（最多 pdfExportMaxRows 行）
+//
+// 路由: GET /admin/pdf_export?__prefix={表格前缀}&{列表页的查询参数}
+//
+// 注意事项:
+//   - 只能导出 Generators 中的表格，当前管理员需要有该表格列表页的权限
+//   - 单元格导出列表中显示的文字，HTML 标签被去掉；表格设置了 ExportValue 时导出原始值
+func ExportPDF(ctx *context.Context) {
+	prefix := ctx.Query(parameter.Prefix)
+	gen, ok := Generators[prefix]
+	if !ok {
+		ctx.HTML(http.StatusNotFound, "表格不存在")
+		return
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/info/"+prefix), http.MethodGet, url.Values{}) {
+		ctx.HTML(http.StatusForbidden, "没有查看该表格的权限")
+		return
+	}
+
+	t := gen(ctx)
+	info := t.GetInfo()
+	data, params, err := loadExportData(ctx, t, ctx.Request.URL)
+	if err != nil {
+		ctx.HTML(http.StatusInternalServerError, "读取数据失败: "+html.EscapeString(err.Error()))
+		return
+	}
+
+	buf, err := renderTablePDF(data, pdfSummary(data, params), info.IsExportValue())
+	if err != nil {
+		ctx.HTML(http.StatusInternalServerError, "生成 PDF 失败: "+html.EscapeString(err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.pdf", data.Title, time.Now().Format("20060102150405"))
+	ctx.DataWithHeaders(http.StatusOK, map[string]string{
+		"Content-Type":        "application/pdf",
+		"Content-Disposition": "inline; filename*=UTF-8''" + url.PathEscape(fileName),
+	}, buf)
+}
+
+// loadExportData 按地址中的筛选和排序从第一页开始读取表格的数据，最多 pdfExportMaxRows 行
+// PDF 导出和定时导出（见 runExportSchedule）共用，导出的内容与列表页按同样的条件显示的内容一致
+func loadExportData(ctx *context.Context, t table.Table, u *url.URL) (table.PanelInfo, parameter.Parameters, error) {
+	info := t.GetInfo()
+	params := parameter.GetParam(u, info.DefaultPageSize, info.SortField, info.GetSort())
+	params.Page, params.PageInt = "1", 1
+	params.PageSize, params.PageSizeInt = strconv.Itoa(pdfExportMaxRows), pdfExportMaxRows
+
+	data, err := t.GetData(ctx, params.WithIsAll(false))
+	return data, params, err
+}
+
+// pdfSummary 生成页眉中的筛选条件和排序说明，每项一行
+func pdfSummary(data table.PanelInfo, params parameter.Parameters) []string {
+	var filters []string
+	for _, f := range data.FilterFormData {
+		if f.Hide {
+			continue
+		}
+		var value string
+		switch {
+		case f.FormType.IsRange():
+			if string(f.Value) == "" && f.Value2 == "" {
+				continue
+			}
+			value = string(f.Value) + " ~ " + f.Value2
+		case f.FormType.IsSelect():
+			var selected []string
+			for _, o := range f.Options {
+				if o.Selected {
+					selected = append(selected, o.Text)
+				}
+			}
+			value = strings.Join(selected, "、")
+		default:
+			value = string(f.Value)
+		}
+		if value != "" {
+			filters = append(filters, f.Head+"："+value)
+		}
+	}
+
+	lines := make([]string, 0, 3)
+	if len(filters) > 0 {
+		lines = append(lines, "筛选条件："+strings.Join(filters, "；"))
+	} else {
+		lines = append(lines, "筛选条件：无")
+	}
+
+	sortHead := params.SortField
+	for _, h := range data.Thead {
+		if h.Field == params.SortField {
+			sortHead = h.Head
+		}
+	}
+	order := "降序"
+	if params.SortType == "asc" {
+		order = "升序"
+	}
+	lines = append(lines, fmt.Sprintf("排序：%s %s", sortHead, order))
+
+	rows := fmt.Sprintf("共 %d 行", len(data.InfoList))
+	if len(data.InfoList) == pdfExportMaxRows {
+		rows = fmt.Sprintf("最多导出 %d 行", pdfExportMaxRows)
+	}
+	lines = append(lines, fmt.Sprintf("%s · 导出时间：%s", rows, time.Now().Format("2006-01-02 15:04:05")))
+	return lines
+}
+
+// pdfTagPattern 匹配 HTML 标签，单元格内容去掉标签后导出
+var pdfTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// pdfCellText 单元格导出的文字
+func pdfCellText(item types.InfoItem, exportValue bool) string {
+	if exportValue {
+		return item.Value
+	}
+	s := html.UnescapeString(pdfTagPattern.ReplaceAllString(string(item.Content), " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// renderTablePDF 把列表数据渲染为 A4 的 PDF，超过 6 列时横向排版
+// 每一页的页眉重复标题、筛选说明和表头，页脚显示页码；列宽按内容的宽度分配，放不下的内容被截断
+func renderTablePDF(data table.PanelInfo, summary []string, exportValue bool) ([]byte, error) {
+	heads := make(types.Thead, 0, len(data.Thead))
+	for _, h := range data.Thead {
+		if !h.Hide {
+			heads = append(heads, h)
+		}
+	}
+
+	orientation := "P"
+	if len(heads) > 6 {
+		orientation = "L"
+	}
+	pdf := fpdf.New(orientation, "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+
+	family, tr := "Helvetica", pdf.UnicodeTranslatorFromDescriptor("")
+	if pdfFont != nil {
+		pdf.AddUTF8FontFromBytes("export", "", pdfFont)
+		family, tr = "export", func(s string) string { return s }
+	}
+
+	rows := make([][]string, len(data.InfoList))
+	for i, item := range data.InfoList {
+		rows[i] = make([]string, len(heads))
+		for j, h := range heads {
+			rows[i][j] = pdfCellText(item[h.Field], exportValue)
+		}
+	}
+
+	// 列宽按表头和内容的最大宽度按比例分配到整个版心
+	pdf.SetFont(family, "", 8)
+	pageW, pageH := pdf.GetPageSize()
+	avail := pageW - 20
+	widths := make([]float64, len(heads))
+	var total float64
+	for j, h := range heads {
+		w := pdf.GetStringWidth(tr(h.Head))
+		for _, r := range rows {
+			if cw := pdf.GetStringWidth(tr(r[j])); cw > w {
+				w = cw
+			}
+		}
+		// 单列最宽不超过版心的 40%，避免长文本挤占其他列
+		if w > avail*0.4 {
+			w = avail * 0.4
+		}
+		widths[j] = w + 4
+		total += widths[j]
+	}
+	for j := range widths {
+		widths[j] = widths[j] / total * avail
+	}
+
+	const rowH = 6
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont(family, "", 14)
+		pdf.CellFormat(0, 8, tr(data.Title), "", 1, "L", false, 0, "")
+		pdf.SetFont(family, "", 8)
+		pdf.SetTextColor(100, 100, 100)
+		for _, line := range summary {
+			pdf.MultiCell(0, 4, tr(line), "", "L", false)
+		}
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+		pdf.SetFillColor(230, 230, 230)
+		for j, h := range heads {
+			pdf.CellFormat(widths[j], rowH, pdfFit(pdf, tr, h.Head, widths[j]), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(family, "", 8)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+
+	pdf.AddPage()
+	pdf.SetFont(family, "", 8)
+	for i, r := range rows {
+		// 整行放不下时先换页，避免一行被拆到两页
+		if pdf.GetY()+rowH > pageH-15 {
+			pdf.AddPage()
+			pdf.SetFont(family, "", 8)
+		}
+		pdf.SetFillColor(248, 248, 248)
+		for j, text := range r {
+			pdf.CellFormat(widths[j], rowH, pdfFit(pdf, tr, text, widths[j]), "1", 0, "L", i%2 == 1, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfFit 截断放不下的文字，末尾加省略号，返回经过 tr 转换、可以直接写入的文字
+// 使用内置字体时 tr 把文字转换为单字节编码，因此按转换前的字符截断
+func pdfFit(pdf *fpdf.Fpdf, tr func(string) string, text string, width float64) string {
+	width -= 2
+	if pdf.GetStringWidth(tr(text)) <= width {
+		return tr(text)
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(tr(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return tr(string(runes) + "...")
+}
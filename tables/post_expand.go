@@ -0,0 +1,95 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现文章列表的行展开：点击"展开"后在该行下方显示文章的完整内容和作者信息，不需要打开详情页
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// addPostExpander 在文章列表每一行添加"展开"按钮
+// 第一次点击时请求文章内容并插入到该行下方，再次点击收起，收起时不再请求
+func addPostExpander(ctx *context.Context, info *types.InfoPanel) {
+	info.AddActionButton(ctx, "展开", action.Ajax("/admin/posts/expand", expandPost).
+		SetParameterJS(postExpandToggleJS).
+		SetSuccessJS(postExpandJS))
+}
+
+// expandPost 返回文章展开后显示的 HTML
+//
+// 请求参数:
+//   - id: 文章编号
+//
+// 注意事项:
+//   - 需要文章列表的查看权限；非超级管理员只能展开自己创建的文章，与列表的范围一致
+func expandPost(ctx *context.Context) (success bool, msg string, data interface{}) {
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/info/posts"), http.MethodGet, url.Values{}) {
+		return false, "没有查看文章的权限", ""
+	}
+	id := ctx.FormValue("id")
+	if err := checkOwner(ctx, "posts", []string{id}); err != nil {
+		return false, "文章不存在", ""
+	}
+	post, author, err := models.FindPostWithAuthor(ctx.Request.Context(), id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false, "文章不存在", ""
+	case err != nil:
+		return false, "读取文章失败: " + err.Error(), ""
+	}
+	return true, "", string(postExpandHTML(post, author))
+}
+
+// postExpandHTML 渲染展开的内容：左侧为作者信息，右侧为文章正文
+// Markdown 模式下渲染为 HTML，富文本模式下保存的 HTML 按白名单过滤后显示（见 SanitizeHTML）
+func postExpandHTML(post models.Post, author models.PostAuthor) template.HTML {
+	authorHTML := `<p class="text-muted">作者不存在</p>`
+	if author.ID != 0 {
+		authorHTML = fmt.Sprintf(`<dl>
+  <dt>姓名</dt><dd><a href="%s?__goadmin_detail_pk=%d">%s %s</a></dd>
+  <dt>邮箱</dt><dd>%s</dd>
+</dl>`, config.Url("/info/authors/detail"), author.ID, html.EscapeString(author.FirstName), html.EscapeString(author.LastName),
+			html.EscapeString(author.Email))
+	}
+
+	content := SanitizeHTML(post.Content)
+	if PostsEditor == EditorMarkdown {
+		content = RenderMarkdown(post.Content)
+	}
+
+	return template.HTML(fmt.Sprintf(`<div class="row" style="padding: 10px 15px;">
+  <div class="col-md-3"><h5><b>作者</b></h5>%s</div>
+  <div class="col-md-9"><h5><b>%s</b></h5><p class="text-muted">%s</p><div>%s</div></div>
+</div>`, authorHTML, html.EscapeString(post.Title), html.EscapeString(post.Description), content))
+}
+
+// postExpandToggleJS 已经展开时收起并结束点击处理，不再发送请求
+const postExpandToggleJS = template.JS(`
+let expanded = $(this).closest('tr').next('.post-expand-row');
+if (expanded.length > 0) {
+    expanded.remove();
+    return;
+}
+`)
+
+// postExpandJS 把返回的内容插入到被点击行的下方，占满整行
+const postExpandJS = template.JS(`if (data.code === 0) {
+    let row = $(event.target).closest('tr');
+    row.next('.post-expand-row').remove();
+    $('<tr class="post-expand-row"><td colspan="' + row.children('td').length + '"></td></tr>')
+        .insertAfter(row).children('td').html(data.data);
+} else {
+    swal(data.msg, '', 'error');
+}`)
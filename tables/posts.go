@@ -3,15 +3,38 @@
 package tables
 
 import (
+	stdctx "context"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
 	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
 	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
 	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
 	"github.com/purpose168/GoAdmin/template"
 	"github.com/purpose168/GoAdmin/template/types"
 	"github.com/purpose168/GoAdmin/template/types/form"
 	editType "github.com/purpose168/GoAdmin/template/types/table"
+	"gorm.io/gorm"
 )
 
+// init 在 Generators 中注册 posts 前缀
+// 访问路径: /admin/info/posts
+// 功能: 文章管理表格，支持富文本编辑、表格关联等功能
+// 全局搜索: 按标题和描述搜索
+func init() {
+	Register("posts", withAudit(GetPostsTable))
+	RegisterSearch("posts", "文章", models.SearchSpec{
+		Table:        "posts",
+		Columns:      []string{"title", "description"},
+		TitleColumns: []string{"title"},
+		DetailColumn: "description",
+		SoftDelete:   true,
+	})
+}
+
 // GetPostsTable 获取文章表格模型
 // 该函数创建并返回一个配置完整的文章表格模型，用于管理后台的文章信息展示和编辑
 //
@@ -33,15 +56,18 @@ import (
 // 核心特性:
 //   - 表格关联：通过 FieldJoin 关联 authors 表获取作者信息
 //   - 自定义显示：使用 FieldDisplay 创建链接和组合字段
-//   - 富文本编辑：使用 form.RichText 支持富文本内容编辑
+//   - 富文本编辑：使用 form.RichText 支持富文本内容编辑，也可以在 config.yml 中切换为 Markdown 编辑器
 //   - 文件上传：通过 FieldEnableFileUpload 支持图片等文件上传
 //   - AJAX 提交：通过 EnableAjax 实现异步表单提交
+//   - 标签：列表按标签颜色显示文章的标签，表单中可以多选，post_tags 与文章在同一个事务中写入
+//   - 记录归属：非超级管理员只能修改和删除自己创建的文章（见 withOwnership）
+//   - 行展开：点击"展开"在列表中直接查看文章正文和作者信息（见 post_expand.go）
 func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 
 	// 创建默认表格模型
 	// NewDefaultTable 创建一个使用默认配置的表格实例
-	// DefaultConfigWithDriver 指定数据库驱动类型为 "sqlite"
-	postsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver("sqlite"))
+	// DefaultConfigWithDriver 指定数据库驱动类型，取自 config.yml 中 default 数据库的 driver
+	postsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
 
 	// 获取信息展示配置对象
 	// GetInfo 返回表格的信息展示配置器，用于配置列表视图的字段
@@ -77,10 +103,10 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 		return template.Default().
 			Link().
 			// 设置链接 URL
-			// /admin/info/authors/detail: 作者详情页路由
+			// /info/authors/detail: 作者详情页在后台 URL 前缀下的路由
 			// __goadmin_detail_pk: GoAdmin 框架的主键参数名
 			// value.Value: 当前字段的值（作者 ID）
-			SetURL("/admin/info/authors/detail?__goadmin_detail_pk=" + value.Value).
+			SetURL(config.Url("/info/authors/detail") + "?__goadmin_detail_pk=" + value.Value).
 			// 设置链接显示内容
 			// template.HTML 将字符串转换为 HTML 类型
 			SetContent(template.HTML(value.Value)).
@@ -157,7 +183,15 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 	//   - db.Varchar: 字段数据类型（可变长字符串）
 	// FieldEditAble: 设置字段在列表视图中可编辑
 	//   editType.Textarea: 使用文本域编辑器
-	info.AddField("内容", "content", db.Varchar).FieldEditAble(editType.Textarea)
+	//
+	// 使用 Markdown 编辑器时（见 PostsEditor），内容为 Markdown 原文，列表和详情中渲染为 HTML，不再支持在列表中直接编辑
+	if PostsEditor == EditorMarkdown {
+		info.AddField("内容", "content", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+			return RenderMarkdown(value.Value)
+		})
+	} else {
+		info.AddField("内容", "content", db.Varchar).FieldEditAble(editType.Textarea)
+	}
 
 	// 添加 Date 字段
 	// 参数说明:
@@ -166,12 +200,28 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 	//   - db.Varchar: 字段数据类型（可变长字符串）
 	info.AddField("日期", "date", db.Varchar)
 
+	// 添加标签字段
+	// tags 不是 posts 表的列，查询时跳过，按文章编号读取 post_tags 后显示为彩色标签
+	info.AddField("标签", "tags", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		chips := make([]string, 0)
+		for _, t := range models.PostTags(ctx.Request.Context(), value.ID) {
+			chips = append(chips, tagChip(t.Name, t.Color))
+		}
+		return strings.Join(chips, " ")
+	})
+
 	// 设置表格基本信息
 	// SetTable: 指定数据库表名
 	// SetTitle: 设置表格标题（显示在页面头部）
 	// SetDescription: 设置表格描述
 	info.SetTable("posts").SetTitle("文章").SetDescription("文章")
 
+	// 启用软删除：删除只写入 deleted_at，可通过"已删除"筛选项找回并恢复
+	withSoftDelete(ctx, info, "posts", &models.Post{})
+
+	// 添加"展开"按钮：在行下方显示文章的完整内容和作者信息（见 addPostExpander）
+	addPostExpander(ctx, info)
+
 	// 获取表单配置对象
 	// GetForm 返回表格的表单配置器，用于配置编辑/添加视图的字段
 	formList := postsTable.GetForm()
@@ -209,8 +259,17 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 	//   - db.Varchar: 字段数据类型
 	//   - form.RichText: 表单字段类型（富文本编辑器）
 	// FieldEnableFileUpload: 启用文件上传功能
-	//   允许在富文本编辑器中插入图片、视频等文件
-	formList.AddField("内容", "content", db.Varchar, form.RichText).FieldEnableFileUpload()
+	//   允许在富文本编辑器中插入图片，图片按 config.yml 的 upload 配置项保存在本地或 S3 中（见 RichTextUploadHandler）
+	//
+	// 配置为 Markdown 编辑器时改用自定义字段：左侧编辑 Markdown 原文，右侧通过 MarkdownPreview 实时预览
+	if PostsEditor == EditorMarkdown {
+		formList.AddField("内容", "content", db.Varchar, form.Custom).
+			FieldCustomContent(markdownEditor).
+			FieldCustomJs(markdownEditorJS())
+	} else {
+		formList.AddField("内容", "content", db.Varchar, form.RichText).
+			FieldEnableFileUpload(formList.OperationURL("/file/upload"), RichTextUploadHandler("posts"))
+	}
 
 	// 添加 Date 字段到表单
 	// 参数说明:
@@ -220,6 +279,26 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 	//   - form.Datetime: 表单字段类型（日期时间选择器）
 	formList.AddField("日期", "date", db.Varchar, form.Datetime)
 
+	// 添加 Tags 字段到表单（多选下拉框）
+	// 选项为 tags 表中的全部标签；编辑时按 post_tags 选中文章已有的标签
+	// 该字段不写入 posts 表，由下面的事务钩子写入 post_tags
+	tagOptions := make(types.FieldOptions, 0)
+	for _, t := range models.AllTags(ctx.Request.Context()) {
+		tagOptions = append(tagOptions, types.FieldOption{Value: strconv.FormatUint(uint64(t.ID), 10), Text: t.Name})
+	}
+	formList.AddField("标签", "tags", db.Varchar, form.Select).
+		FieldOptions(tagOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			ids := make([]string, 0)
+			if value.ID == "" {
+				return ids
+			}
+			for _, t := range models.PostTags(ctx.Request.Context(), value.ID) {
+				ids = append(ids, strconv.FormatUint(uint64(t.ID), 10))
+			}
+			return ids
+		})
+
 	// 启用 AJAX 表单提交
 	// EnableAjax 启用异步表单提交功能
 	// 参数说明:
@@ -234,6 +313,28 @@ func GetPostsTable(ctx *context.Context) (postsTable table.Table) {
 	// SetDescription: 设置表单描述
 	formList.SetTable("posts").SetTitle("文章").SetDescription("文章")
 
+	// 设置校验规则（见 FormRules），浏览器中输入时提示，提交后在服务端再次校验
+	NewFormRules().
+		FieldRules("title", "标题", "required|max:255").
+		FieldRules("description", "描述", "max:500").
+		Apply(ctx, formList)
+
+	// 设置事务中的表单钩子
+	// 文章写入后在同一个事务中用所选标签替换 post_tags，任一步失败时文章和标签都不会被修改
+	// 列表中单独修改内容时不提交标签，此时保留原有标签
+	withTxPostHook(ctx, postsTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
+		if values.IsSingleUpdatePost() {
+			return nil
+		}
+		return models.SetPostTags(ctx, id, values["tags[]"])
+	})
+
+	// 非超级管理员只能看到、修改和删除自己创建的文章（见 withOwnership）
+	withOwnership(ctx, postsTable)
+
+	// 记住管理员在列选择器中选择的列，下次打开列表时沿用（见 withColumnPreferences）
+	withColumnPreferences(ctx, postsTable)
+
 	// 返回配置好的表格模型
 	return
 }
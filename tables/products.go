@@ -0,0 +1,146 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现商品（products）表格的模型配置，分类从 categories 表的分类树中选择
+package tables
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 products 前缀
+// 访问路径: /admin/info/products
+// 功能: 商品管理表格，分类从分类树中选择，列表显示分类的完整路径；导入时分类可以填写分类名称
+func init() {
+	Register("products", withImport("products", withAudit(GetProductsTable)))
+}
+
+// GetProductsTable 获取商品表格模型
+// 该函数创建并返回商品表格模型，用于管理后台的商品展示和编辑
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表关联 categories 表，分类列显示从顶级分类开始的完整路径，如"电子产品 / 电脑 / 笔记本"
+//   - 可以按分类筛选，只匹配所选的分类，不包含其下级分类
+//   - 表单中的分类按树形结构缩进显示（见 categoryOptions）
+//   - 商品图片可以上传多张，以逗号分隔保存在 images 字段
+//   - 扩展属性以 JSON 保存在 metadata 字段，表单中用 JSON 编辑器编辑（见 AddJSONField），列表中格式化显示
+func GetProductsTable(ctx *context.Context) (productsTable table.Table) {
+
+	productsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	// 分类树在每个请求中读取一次，列表的路径和表单的选项共用
+	tree := models.CategoryTree(ctx.Request.Context())
+	options := categoryOptions(tree)
+
+	info := productsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter)
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	// SQLite 的 real 字段读出为 float64，GoAdmin 只按 Real 等浮点类型转换，Decimal 会显示为 0
+	info.AddField("价格", "price", db.Real).FieldSortable().FieldDisplay(func(value types.FieldModel) interface{} {
+		price, _ := strconv.ParseFloat(value.Value, 64)
+		return fmt.Sprintf("¥%.2f", price)
+	})
+
+	info.AddField("库存", "stock", db.Int).FieldSortable()
+
+	// category_id 只用于筛选和计算分类路径，不单独显示
+	info.AddField("分类编号", "category_id", db.Int).FieldHide().
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(options)
+
+	// 关联得到的是分类自身的名称，显示时换成完整路径；分类已被删除时显示为空
+	info.AddField("分类", "name", db.Varchar).FieldJoin(types.Join{
+		Field:     "category_id",
+		JoinField: "id",
+		Table:     "categories",
+	}).FieldDisplay(func(value types.FieldModel) interface{} {
+		if path := categoryPath(tree, fmt.Sprint(value.Row["category_id"])); path != "" {
+			return path
+		}
+		return value.Value
+	})
+
+	info.AddField("图片", "images", db.Varchar).FieldCarousel(func(value string) []string {
+		if value == "" {
+			return nil
+		}
+		images := strings.Split(value, ",")
+		for i, img := range images {
+			images[i] = config.GetStore().URL(img)
+		}
+		return images
+	}, 100, 80)
+
+	info.AddField("扩展属性", "metadata", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		return FormatJSON(value.Value)
+	})
+
+	info.SetTable("products").SetTitle("商品").SetDescription("商品")
+
+	formList := productsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("价格", "price", db.Real, form.Currency).FieldMust()
+
+	formList.AddField("库存", "stock", db.Int, form.Number).FieldDefault("0")
+
+	formList.AddField("安全库存", "min_stock", db.Int, form.Number).FieldDefault("10").
+		FieldHelpMsg("库存低于该值时出现在库存列表的补货提醒中")
+
+	formList.AddField("分类", "category_id", db.Int, form.SelectSingle).
+		FieldOptions(options).FieldMust()
+
+	formList.AddField("图片", "images", db.Varchar, form.Multifile)
+
+	// 扩展属性在代码编辑器中以 JSON 编辑，保存前按 ProductMetadataSchema 校验
+	AddJSONField(formList, "扩展属性", "metadata", ProductMetadataSchema)
+
+	formList.SetTable("products").SetTitle("商品").SetDescription("商品")
+
+	return
+}
+
+// ProductMetadataSchema 商品扩展属性的结构，attributes 中可以填写任意的规格参数
+var ProductMetadataSchema = &JSONSchema{
+	Type: JSONObject,
+	Properties: map[string]*JSONSchema{
+		"brand":  {Type: JSONString, Description: "品牌"},
+		"origin": {Type: JSONString, Description: "产地"},
+		"weight": {Type: JSONNumber, Description: "重量，单位千克"},
+		"dimensions": {
+			Type:        JSONObject,
+			Description: "尺寸，单位厘米，包含 length、width、height",
+			Properties: map[string]*JSONSchema{
+				"length": {Type: JSONNumber},
+				"width":  {Type: JSONNumber},
+				"height": {Type: JSONNumber},
+			},
+			Required: []string{"length", "width", "height"},
+		},
+		"tags":       {Type: JSONArray, Description: "标签", Items: &JSONSchema{Type: JSONString}},
+		"attributes": {Type: JSONObject, Description: "其他规格参数"},
+	},
+}
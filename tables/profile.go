@@ -4,7 +4,6 @@ package tables
 
 import (
 	"path/filepath"
-	"strings"
 
 	"github.com/purpose168/GoAdmin/context"
 	"github.com/purpose168/GoAdmin/modules/db"
@@ -13,6 +12,13 @@ import (
 	"github.com/purpose168/GoAdmin/template/types/form"
 )
 
+// init 在 Generators 中注册 profile 前缀
+// 访问路径: /admin/info/profile
+// 功能: 用户档案表格，演示多种字段类型（轮播图、进度条、状态点等）
+func init() {
+	Register("profile", withAudit(GetProfileTable))
+}
+
 // GetProfileTable 获取用户档案表格模型
 // 该函数创建并返回一个配置完整的用户档案表格模型，用于管理后台的用户档案信息展示和编辑
 //
@@ -33,6 +39,7 @@ import (
 // 核心特性:
 //   - 字段筛选：通过 FieldFilterable 支持字段筛选
 //   - 字段复制：通过 FieldCopyable 支持一键复制字段内容
+//   - 二维码：通过 fieldQRCode 把 UUID 显示为服务端生成的二维码
 //   - 布尔字段：通过 FieldBool 显示布尔值
 //   - 轮播图：通过 FieldCarousel 显示图片轮播
 //   - 状态点：通过 FieldDot 显示带颜色标记的状态
@@ -43,8 +50,8 @@ func GetProfileTable(ctx *context.Context) table.Table {
 
 	// 创建默认表格模型
 	// NewDefaultTable 创建一个使用默认配置的表格实例
-	// DefaultConfigWithDriver 指定数据库驱动类型为 "sqlite"
-	profile := table.NewDefaultTable(ctx, table.DefaultConfigWithDriver("sqlite"))
+	// DefaultConfigWithDriver 指定数据库驱动类型，取自 config.yml 中 default 数据库的 driver
+	profile := table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
 
 	// 获取信息展示配置对象
 	// GetInfo 返回表格的信息展示配置器，用于配置列表视图的字段
@@ -68,6 +75,11 @@ func GetProfileTable(ctx *context.Context) table.Table {
 	// FieldCopyable: 设置该字段可复制（显示复制按钮，点击后复制到剪贴板）
 	info.AddField("UUID", "uuid", db.Varchar).FieldCopyable()
 
+	// 添加 UUID 二维码字段
+	// uuid_qrcode 不是 profile 表的列，查询时跳过，显示时读取同一行的 uuid
+	// fieldQRCode: 在服务端把 UUID 生成为二维码图片，点击图片在弹窗中放大
+	info.AddField("二维码", "uuid_qrcode", db.Varchar).FieldDisplay(fieldQRCode("uuid", 60))
+
 	// 添加 Pass 字段（布尔字段）
 	// 参数说明:
 	//   - "Pass": 字段显示名称
@@ -82,16 +94,12 @@ func GetProfileTable(ctx *context.Context) table.Table {
 	// 参数说明:
 	//   - "Photos": 字段显示名称
 	//   - "photos": 数据库字段名
-	//   - db.Varchar: 字段数据类型（可变长字符串，存储逗号分隔的图片 URL）
+	//   - db.Varchar: 字段数据类型（可变长字符串，存储逗号分隔的图片路径或 URL）
 	// FieldCarousel: 将字段显示为图片轮播
-	//   - 回调函数: 将逗号分隔的字符串转换为字符串切片
-	//     strings.Split: 按逗号分割字符串
+	//   - profilePhotoURLs: 将逗号分隔的字符串转换为图片地址，表单上传的照片为上传目录中的路径
 	//   - 150: 轮播图宽度（像素）
 	//   - 100: 轮播图高度（像素）
-	info.AddField("照片", "photos", db.Varchar).FieldCarousel(func(value string) []string {
-		// 将逗号分隔的图片 URL 切片转换为字符串数组
-		return strings.Split(value, ",")
-	}, 150, 100)
+	info.AddField("照片", "photos", db.Varchar).FieldCarousel(profilePhotoURLs, 150, 100)
 
 	// 添加 Finish State 字段（带状态点的自定义显示）
 	// 参数说明:
@@ -185,9 +193,11 @@ func GetProfileTable(ctx *context.Context) table.Table {
 	//   - "Photos": 字段显示名称
 	//   - "photos": 数据库字段名
 	//   - db.Varchar: 字段数据类型
-	//   - form.Text: 表单字段类型（文本输入框）
-	//   注意: 这里使用文本框输入逗号分隔的图片 URL
-	formList.AddField("照片", "photos", db.Varchar, form.Text)
+	//   - form.Custom: 自定义字段，内容为多图上传控件（见 profile_photos.go）
+	//   选择的照片逐张上传到 ProfilePhotoUpload 并显示进度，字段保存逗号分隔的照片路径，与列表的轮播图共用
+	formList.AddField("照片", "photos", db.Varchar, form.Custom).
+		FieldCustomContent(profilePhotosField).
+		FieldCustomJs(profilePhotosJS())
 
 	// 添加 Resume 字段到表单
 	// 参数说明:
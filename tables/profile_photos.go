@@ -0,0 +1,179 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现用户档案表单中照片的多图上传：选择图片后逐张上传并显示进度，表单只提交已上传照片的路径
+package tables
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules"
+)
+
+// ProfilePhotoUploadURL 照片上传接口的地址（在后台 URL 前缀下的路径），需要在 main 中注册 ProfilePhotoUpload
+const ProfilePhotoUploadURL = "/profile/photos/upload"
+
+// profilePhotoDir 照片保存在上传目录下的子目录
+const profilePhotoDir = "photos"
+
+// profilePhotoMaxBytes 单张照片的最大字节数
+const profilePhotoMaxBytes = 10 << 20
+
+// profilePhotoExt 图片格式对应的扩展名，按文件内容识别格式，不使用上传的文件名
+var profilePhotoExt = map[string]string{
+	"jpeg": ".jpg",
+	"png":  ".png",
+	"gif":  ".gif",
+	"webp": ".webp",
+}
+
+// profilePhotoURLs 把 photos 字段的值拆分为照片地址
+// 字段中保存的是逗号分隔的路径，新上传的照片为上传目录中的相对路径，之前录入的完整地址原样保留
+func profilePhotoURLs(value string) []string {
+	urls := make([]string, 0)
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			urls = append(urls, config.GetStore().URL(p))
+		}
+	}
+	return urls
+}
+
+// ProfilePhotoUpload 保存表单中上传的一张照片
+//
+// 请求格式:
+//
+//	POST multipart/form-data，文件字段为 file_data（上传控件没有 name 时使用的字段名）
+//
+// 返回格式（bootstrap-fileinput 的异步上传格式）:
+//
+//	{"path": "photos/xxx.jpg", "initialPreview": ["/uploads/photos/xxx.jpg"], "initialPreviewConfig": [...]}
+//	{"error": "错误信息"}
+//
+// 注意事项:
+//   - 需要新增或修改用户档案的权限
+//   - 照片上传后即保存在上传目录中，表单未提交或之后移除的照片不会自动删除
+//   - 移除照片时控件向同一个地址提交 key，这里只返回成功，由表单脚本从字段中去掉该照片
+func ProfilePhotoUpload(ctx *context.Context) {
+	user := auth.Auth(ctx)
+	if !user.CheckPermissionByUrlMethod(config.Url("/new/profile"), http.MethodPost, url.Values{}) &&
+		!user.CheckPermissionByUrlMethod(config.Url("/edit/profile"), http.MethodPost, url.Values{}) {
+		ctx.JSON(http.StatusOK, map[string]interface{}{"error": "没有修改用户档案的权限"})
+		return
+	}
+	if ctx.FormValue("key") != "" {
+		ctx.JSON(http.StatusOK, map[string]interface{}{})
+		return
+	}
+
+	path, err := saveProfilePhoto(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"path":                 path,
+		"initialPreview":       []string{config.GetStore().URL(path)},
+		"initialPreviewConfig": []map[string]string{{"key": path, "url": config.Url(ProfilePhotoUploadURL)}},
+	})
+}
+
+// saveProfilePhoto 把上传的照片保存到上传目录的 photos 子目录，返回相对于上传目录的路径
+func saveProfilePhoto(ctx *context.Context) (string, error) {
+	file, _, err := ctx.Request.FormFile("file_data")
+	if err != nil {
+		return "", fmt.Errorf("请选择照片")
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(file, profilePhotoMaxBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > profilePhotoMaxBytes {
+		return "", fmt.Errorf("照片不能超过 %d MB", profilePhotoMaxBytes>>20)
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	ext, ok := profilePhotoExt[format]
+	if err != nil || !ok {
+		return "", models.ErrInvalidImage
+	}
+
+	dir := filepath.Join(config.GetStore().Path, profilePhotoDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := modules.Uuid() + ext
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return "", err
+	}
+	return profilePhotoDir + "/" + name, nil
+}
+
+// profilePhotosField 照片字段的内容：保存路径的隐藏字段和多图上传控件
+// 上传控件没有 name，选择的文件不随表单提交；作为 form.Custom 字段的内容，由 GoAdmin 填入字段名和当前值
+const profilePhotosField = template.HTML(`<div class="profile-photos" style="width:100%">
+  <input type="hidden" name="{{.Field}}" class="profile-photos-value" value="{{.Value}}">
+  <input type="file" class="profile-photos-input" accept="image/*" multiple>
+  <span class="help-block">可以一次选择多张照片，选择后自动上传；支持 JPEG、PNG、GIF 和 WebP，单张不超过 10 MB</span>
+</div>`)
+
+// profilePhotosJS 初始化多图上传控件
+// 已保存的照片显示为初始预览，选择的照片逐张异步上传并显示进度条；
+// 上传成功后把返回的路径加入隐藏字段，移除照片时从隐藏字段中去掉，提交表单时保存隐藏字段中的路径
+func profilePhotosJS() template.JS {
+	return template.JS(`
+(function () {
+    let value = $('.profile-photos-value');
+    let input = $('.profile-photos-input');
+    let store = ` + fmt.Sprintf("%q", config.GetStore().URL("/")) + `;
+    let paths = value.val() ? value.val().split(',').filter(function (p) { return p !== ''; }) : [];
+    function save() {
+        value.val(paths.join(','));
+    }
+    function photoURL(p) {
+        return p.indexOf('http') === 0 ? p : store.replace(/\/$/, '') + '/' + p.replace(/^\//, '');
+    }
+    input.fileinput({
+        uploadUrl: '` + config.Url(ProfilePhotoUploadURL) + `',
+        uploadAsync: true,
+        fileActionSettings: {showUpload: false},
+        showUpload: false,
+        showRemove: false,
+        overwriteInitial: false,
+        initialPreviewAsData: true,
+        initialPreviewFileType: 'image',
+        initialPreview: paths.map(photoURL),
+        initialPreviewConfig: paths.map(function (p) {
+            return {key: p, url: '` + config.Url(ProfilePhotoUploadURL) + `'};
+        }),
+        maxFileSize: ` + fmt.Sprint(profilePhotoMaxBytes>>10) + `,
+        allowedFileTypes: ['image'],
+        msgPlaceholder: '选择照片...',
+        browseLabel: '选择照片'
+    }).on('filebatchselected', function () {
+        input.fileinput('upload');
+    }).on('fileuploaded', function (event, data) {
+        if (data.response && data.response.path) {
+            paths.push(data.response.path);
+            save();
+        }
+    }).on('filedeleted', function (event, key) {
+        paths = paths.filter(function (p) { return p !== key; });
+        save();
+    });
+})();
+`)
+}
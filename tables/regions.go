@@ -0,0 +1,140 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现省、市、区三级联动的下拉框：选项从 regions 表按上级逐级加载，
+// 选择上一级后通过 FieldOnChooseAjax 读取下一级的选项（见表单页面的"省份"、"城市"、"区域"）
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/template/types"
+	selection "github.com/purpose168/GoAdmin/template/types/form/select"
+	"gorm.io/gorm"
+)
+
+// RegionField 三级联动中的一个下拉框
+type RegionField struct {
+	// Field 字段名
+	Field string
+
+	// Label 字段显示的名称，用于校验错误信息
+	Label string
+}
+
+// RegionOptions 返回上级为 parent 的行政区划选项，parent 为 "0" 时返回全部省
+// parent 为空（上一级没有选择）、不是有效的代码或读取失败时返回空的选项，页面仍可打开，只是下拉框中没有可选的项
+//
+// 使用示例:
+//
+//	panel.AddField("省份", "province", db.Int, form.SelectSingle).
+//		FieldOptions(tables.RegionOptions(ctx.Request.Context(), "0")).
+//		FieldDefault(province).
+//		FieldOnChooseAjax("city", "/choose/region/province", tables.RegionChildren)
+func RegionOptions(ctx stdctx.Context, parent string) types.FieldOptions {
+	options := types.FieldOptions{}
+	id, ok := parseRegionID(parent)
+	if !ok {
+		return options
+	}
+	list, err := models.FindRegions(ctx, id)
+	if err != nil {
+		return options
+	}
+	for _, r := range list {
+		options = append(options, types.FieldOption{Text: r.Name, Value: strconv.FormatUint(uint64(r.ID), 10)})
+	}
+	return options
+}
+
+// RegionChildren FieldOnChooseAjax 的处理函数，返回所选区划的下一级选项
+// 请求参数 value 为所选的代码，没有选择时返回空列表
+func RegionChildren(ctx *context.Context) (bool, string, interface{}) {
+	options := selection.Options{}
+	id, ok := parseRegionID(ctx.FormValue("value"))
+	if !ok {
+		return true, "ok", options
+	}
+	list, err := models.FindRegions(ctx.Request.Context(), id)
+	if err != nil {
+		return false, "读取行政区划失败: " + err.Error(), nil
+	}
+	for _, r := range list {
+		options = append(options, selection.Option{Text: r.Name, ID: strconv.FormatUint(uint64(r.ID), 10)})
+	}
+	return true, "ok", options
+}
+
+// RegionResetJS 选择上一级后清空再下一级的下拉框
+// FieldOnChooseAjax 只重新加载紧邻的下一级，如修改省份后城市被清空，区域仍保留原来的选项，由这里清空
+func RegionResetJS(fields ...RegionField) template.JS {
+	var b strings.Builder
+	for i := 0; i+2 < len(fields); i++ {
+		fmt.Fprintf(&b, "$('select.%s').on('select2:select', function () {\n", fields[i].Field)
+		for _, f := range fields[i+2:] {
+			fmt.Fprintf(&b, "    $('select.%s').html('<option value=\"\" selected=\"selected\"></option>').val('').select2();\n", f.Field)
+		}
+		b.WriteString("});\n")
+	}
+	return template.JS(b.String())
+}
+
+// ValidateRegions 检查提交的各级区划：每一级都要存在，且是上一级的下级；选择了下一级时上一级必填
+//
+// 参数:
+//   - ctx: 上下文
+//   - fields: 从上到下的各级字段，第 i 个字段的级别为 i+1
+//   - values: 提交的参数
+//
+// 返回值:
+//   - models.ValidationErrors: 第一个不通过的字段一条；都没有选择时为空
+//   - error: 读取区划失败时返回数据库错误
+func ValidateRegions(ctx stdctx.Context, fields []RegionField, values map[string][]string) (models.ValidationErrors, error) {
+	var parent uint
+	for i, f := range fields {
+		value := ""
+		if v := values[f.Field]; len(v) > 0 {
+			value = strings.TrimSpace(v[0])
+		}
+		if value == "" {
+			for _, next := range fields[i+1:] {
+				if v := values[next.Field]; len(v) > 0 && strings.TrimSpace(v[0]) != "" {
+					return models.ValidationErrors{{Field: f.Field, Label: f.Label, Message: "请先选择" + f.Label}}, nil
+				}
+			}
+			return nil, nil
+		}
+
+		invalid := models.ValidationErrors{{Field: f.Field, Label: f.Label, Message: "不是有效的选项"}}
+		id, ok := parseRegionID(value)
+		if !ok {
+			return invalid, nil
+		}
+		r, err := models.FindRegion(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Level != i+1 || r.ParentID != parent {
+			return invalid, nil
+		}
+		parent = r.ID
+	}
+	return nil, nil
+}
+
+// parseRegionID 解析行政区划代码，空字符串等无效的代码返回 false
+func parseRegionID(s string) (uint, bool) {
+	id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
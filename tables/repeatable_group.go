@@ -0,0 +1,289 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表单中可重复的字段分组：每一段包含同样的几个字段，可以添加、删除和调整顺序，
+// 提交后解析为多行数据，通常保存到子表中（见表单页面的"工作经历"）
+package tables
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+// 分组中字段的输入框类型
+const (
+	GroupText     = "text"
+	GroupDate     = "date"
+	GroupTextArea = "textarea"
+)
+
+// GroupField 可重复分组中的一个字段
+type GroupField struct {
+	// Field 字段名，提交时的参数名为"分组字段名[序号][字段名]"
+	Field string
+
+	// Label 字段显示的名称
+	Label string
+
+	// Type 输入框类型：GroupText、GroupDate 或 GroupTextArea，为空时为 GroupText
+	// 日期使用浏览器的日期输入框，以 YYYY-MM-DD 提交
+	Type string
+
+	// Rules 校验规则，格式与 FormRules 相同；浏览器中按 required、min、max 提示，提交后全部规则在服务端校验
+	Rules string
+
+	// Width 输入框在一段中所占的栅格宽度（共 12），为 0 时为 6
+	Width int
+}
+
+// RepeatableGroup 可重复的字段分组
+//
+// 使用示例:
+//
+//	experiences := tables.RepeatableGroup{
+//		Field: "experiences",
+//		Label: "工作经历",
+//		Fields: []tables.GroupField{
+//			{Field: "company", Label: "公司", Rules: "required|max:100"},
+//			{Field: "start_date", Label: "入职日期", Type: tables.GroupDate},
+//		},
+//	}
+//	panel.AddField("工作经历", "experiences", db.Text, form.Custom).
+//		FieldCustomContent(experiences.Content(rows)).
+//		FieldCustomJs(experiences.JS())
+//	// 提交后
+//	rows := experiences.Parse(values)
+//	errs := experiences.Validate(ctx.Lang(), rows)
+type RepeatableGroup struct {
+	// Field 分组的字段名
+	Field string
+
+	// Label 每一段的标题，显示为"标题 1"、"标题 2"……
+	Label string
+
+	// Fields 每一段中的字段
+	Fields []GroupField
+
+	// Max 最多的段数，为 0 时不限制
+	Max int
+}
+
+// Content 返回分组的 HTML，rows 为已有的各段，键为字段名
+// 值中的 { 转义为 HTML 实体：自定义字段的内容会作为模板解析，不能出现 {{
+func (g RepeatableGroup) Content(rows []map[string]string) template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="repeatable-group" data-field="%s" data-max="%d" style="width:100%%">`, g.Field, g.Max)
+	// 标记分组在表单中，没有任何一段时也能区分"删除了全部"和"表单中没有这个分组"
+	fmt.Fprintf(&b, `<input type="hidden" name="%s" value="">`, g.Field)
+	b.WriteString(`<div class="repeatable-group-items">`)
+	for i, row := range rows {
+		b.WriteString(g.item(strconv.Itoa(i), row))
+	}
+	b.WriteString(`</div>`)
+	fmt.Fprintf(&b, `<template class="repeatable-group-template">%s</template>`, g.item("__index__", nil))
+	fmt.Fprintf(&b, `<button type="button" class="btn btn-sm btn-default repeatable-group-add"><i class="fa fa-plus"></i> 添加%s</button>`,
+		template.HTMLEscapeString(g.Label))
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
+
+// item 返回一段的 HTML，index 为提交时的序号
+func (g RepeatableGroup) item(index string, row map[string]string) string {
+	var b strings.Builder
+	b.WriteString(`<div class="box box-default box-solid repeatable-group-item" style="margin-bottom:10px">`)
+	fmt.Fprintf(&b, `<div class="box-header with-border"><h3 class="box-title">%s <span class="repeatable-group-no"></span></h3>`,
+		template.HTMLEscapeString(g.Label))
+	b.WriteString(`<div class="box-tools pull-right">` +
+		`<button type="button" class="btn btn-box-tool repeatable-group-up" title="上移"><i class="fa fa-arrow-up"></i></button>` +
+		`<button type="button" class="btn btn-box-tool repeatable-group-down" title="下移"><i class="fa fa-arrow-down"></i></button>` +
+		`<button type="button" class="btn btn-box-tool repeatable-group-remove" title="删除"><i class="fa fa-times"></i></button>` +
+		`</div></div><div class="box-body"><div class="row">`)
+	for _, f := range g.Fields {
+		width := f.Width
+		if width == 0 {
+			width = 6
+		}
+		name := fmt.Sprintf("%s[%s][%s]", g.Field, index, f.Field)
+		value := escapeGroupValue(row[f.Field])
+		attrs := groupFieldAttrs(f)
+		fmt.Fprintf(&b, `<div class="col-sm-%d form-group" style="margin-bottom:8px"><label>%s</label>`, width, template.HTMLEscapeString(f.Label))
+		switch f.Type {
+		case GroupTextArea:
+			fmt.Fprintf(&b, `<textarea name="%s" class="form-control" rows="3"%s>%s</textarea>`, name, attrs, value)
+		case GroupDate:
+			fmt.Fprintf(&b, `<input type="date" name="%s" value="%s" class="form-control"%s>`, name, value, attrs)
+		default:
+			fmt.Fprintf(&b, `<input type="text" name="%s" value="%s" class="form-control"%s>`, name, value, attrs)
+		}
+		b.WriteString(`</div>`)
+	}
+	b.WriteString(`</div></div></div>`)
+	return b.String()
+}
+
+// groupFieldAttrs 把 required、min、max 规则转换为输入框的属性，由浏览器在提交时检查
+func groupFieldAttrs(f GroupField) string {
+	rules, err := parseFieldRules(f.Rules)
+	if err != nil {
+		panic("tables: " + err.Error())
+	}
+	var attrs string
+	for _, rule := range rules {
+		switch rule.name {
+		case "required":
+			attrs += " required"
+		case "min":
+			attrs += fmt.Sprintf(` minlength="%d"`, rule.n)
+		case "max":
+			attrs += fmt.Sprintf(` maxlength="%d"`, rule.n)
+		}
+	}
+	return attrs
+}
+
+// escapeGroupValue 转义字段值，{ 转义为 &#123;，避免内容作为模板解析时出错
+func escapeGroupValue(s string) string {
+	return strings.ReplaceAll(template.HTMLEscapeString(s), "{", "&#123;")
+}
+
+// JS 返回分组的交互：添加、删除、上移和下移，每次变化后按顺序重新编号
+func (g RepeatableGroup) JS() template.JS {
+	return template.JS(`
+(function () {
+    let root = $('.repeatable-group[data-field="` + g.Field + `"]'), items = root.find('.repeatable-group-items');
+    let field = root.data('field'), max = parseInt(root.data('max'), 10) || 0;
+
+    function renumber() {
+        items.children('.repeatable-group-item').each(function (i) {
+            $(this).find('.repeatable-group-no').text(i + 1);
+            $(this).find('[name]').each(function () {
+                this.name = this.name.replace(/^([^\[]+)\[[^\]]*\]/, '$1[' + i + ']');
+            });
+        });
+        root.find('.repeatable-group-add').toggle(max === 0 || items.children().length < max);
+    }
+
+    root.find('.repeatable-group-add').click(function () {
+        let html = root.find('.repeatable-group-template').html().split('__index__').join(items.children().length);
+        items.append(html);
+        renumber();
+    });
+    items.on('click', '.repeatable-group-remove', function () {
+        $(this).closest('.repeatable-group-item').remove();
+        renumber();
+    });
+    items.on('click', '.repeatable-group-up', function () {
+        let item = $(this).closest('.repeatable-group-item');
+        item.prev().before(item);
+        renumber();
+    });
+    items.on('click', '.repeatable-group-down', function () {
+        let item = $(this).closest('.repeatable-group-item');
+        item.next().after(item);
+        renumber();
+    });
+    renumber();
+})();
+`)
+}
+
+// groupParamPattern 分组参数名的格式：分组字段名[序号][字段名]
+var groupParamPattern = regexp.MustCompile(`^([^\[\]]+)\[(\d+)\]\[([^\[\]]+)\]$`)
+
+// Submitted 表单中是否包含该分组；不包含时不应修改已保存的数据
+func (g RepeatableGroup) Submitted(values map[string][]string) bool {
+	_, ok := values[g.Field]
+	return ok
+}
+
+// Parse 从提交的参数中解析各段，按序号排列
+// 值去掉首尾空白，所有字段都为空的段被忽略；不在 Fields 中的字段被忽略
+func (g RepeatableGroup) Parse(values map[string][]string) []map[string]string {
+	known := make(map[string]bool, len(g.Fields))
+	for _, f := range g.Fields {
+		known[f.Field] = true
+	}
+
+	byIndex := make(map[int]map[string]string)
+	for name, v := range values {
+		m := groupParamPattern.FindStringSubmatch(name)
+		if m == nil || m[1] != g.Field || !known[m[3]] || len(v) == 0 {
+			continue
+		}
+		i, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		if byIndex[i] == nil {
+			byIndex[i] = make(map[string]string, len(g.Fields))
+		}
+		byIndex[i][m[3]] = strings.TrimSpace(v[0])
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for i := range byIndex {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	rows := make([]map[string]string, 0, len(indexes))
+	for _, i := range indexes {
+		row := byIndex[i]
+		empty := true
+		for _, f := range g.Fields {
+			if row[f.Field] != "" {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Validate 按字段的规则校验各段，日期字段还需为 YYYY-MM-DD
+//
+// 参数:
+//   - lang: 错误信息的语言，与 FormRules.Validate 相同
+//   - rows: Parse 的结果
+//
+// 返回值:
+//   - models.ValidationErrors: 每个不通过的字段一条，标签为"标题 n 字段名"；段数超过 Max 时只返回一条
+func (g RepeatableGroup) Validate(lang string, rows []map[string]string) models.ValidationErrors {
+	if g.Max > 0 && len(rows) > g.Max {
+		return models.ValidationErrors{{Field: g.Field, Label: g.Label, Message: fmt.Sprintf("最多 %d 段", g.Max)}}
+	}
+
+	messages := fieldRuleMessagesFor(lang)
+	var errs models.ValidationErrors
+	for i, row := range rows {
+		for _, f := range g.Fields {
+			rules, err := parseFieldRules(f.Rules)
+			if err != nil {
+				panic("tables: " + err.Error())
+			}
+			value := row[f.Field]
+			msg := fieldRules{field: f.Field, label: f.Label, rules: rules}.check(value, messages)
+			if msg == "" && f.Type == GroupDate && value != "" {
+				if _, err := time.Parse("2006-01-02", value); err != nil {
+					msg = messages["regexp"]
+				}
+			}
+			if msg != "" {
+				errs = append(errs, models.FieldError{
+					Field:   fmt.Sprintf("%s[%d][%s]", g.Field, i, f.Field),
+					Label:   fmt.Sprintf("%s %d %s", g.Label, i+1, f.Label),
+					Message: msg,
+				})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,57 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现从只读副本读取列表和导出数据的表格包装
+package tables
+
+import (
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
+
+// connGenerator 按数据库连接名创建表格模型的函数
+type connGenerator func(ctx *context.Context, conn string) table.Table
+
+// replicaTable 列表和导出从只读副本读取的表格
+// 嵌入的表格使用主库，负责表单、详情和所有写入；reader 只用于 GetData 和 GetDataWithIds
+type replicaTable struct {
+	table.Table
+
+	// reader 使用只读副本连接创建的同一个表格
+	reader table.Table
+}
+
+// GetData 从只读副本查询列表数据，导出全部数据时也通过该方法查询
+func (t replicaTable) GetData(ctx *context.Context, params parameter.Parameters) (table.PanelInfo, error) {
+	return t.reader.GetData(ctx, params)
+}
+
+// GetDataWithIds 从只读副本查询选中的记录，用于导出选中的数据
+func (t replicaTable) GetDataWithIds(ctx *context.Context, params parameter.Parameters) (table.PanelInfo, error) {
+	return t.reader.GetDataWithIds(ctx, params)
+}
+
+// Copy 复制表格，副本连接一并复制
+func (t replicaTable) Copy() table.Table {
+	return replicaTable{Table: t.Table.Copy(), reader: t.reader.Copy()}
+}
+
+// withReadReplica 创建列表和导出从只读副本读取的表格
+//
+// 参数:
+//   - ctx: 上下文对象
+//   - gen: 表格生成函数，分别以 default 和副本的连接名各调用一次，两次生成的表格配置必须相同
+//
+// 返回值:
+//   - table.Table: 没有配置只读副本时直接返回使用主库的表格
+//
+// 注意事项:
+//   - 副本存在复制延迟，刚保存的记录可能要稍后才出现在列表中；编辑页面的数据仍从主库读取
+func withReadReplica(ctx *context.Context, gen connGenerator) table.Table {
+	primary := gen(ctx, table.DefaultConnectionName)
+	conn := models.ReadConnection()
+	if conn == table.DefaultConnectionName {
+		return primary
+	}
+	return replicaTable{Table: primary, reader: gen(ctx, conn)}
+}
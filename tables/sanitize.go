@@ -0,0 +1,221 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现富文本 HTML 的白名单过滤：文章正文由有写入权限的任何管理员、第三方账号自动创建的管理员和 API 密钥保存，
+// 显示前只保留排版用的标签和属性，去掉脚本、事件属性和 javascript: 等链接
+package tables
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sanitizeGlobalAttrs 所有允许的标签都可以使用的属性
+var sanitizeGlobalAttrs = map[string]bool{"title": true, "style": true}
+
+// sanitizeTags 允许的标签及各自额外允许的属性，不在其中的标签去掉标签本身、保留其中的文字
+var sanitizeTags = map[string]map[string]bool{
+	"a": {"href": true}, "img": {"src": true, "alt": true, "width": true, "height": true},
+	"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"b": nil, "strong": nil, "i": nil, "em": nil, "u": nil, "s": nil, "strike": nil, "del": nil, "ins": nil,
+	"sub": nil, "sup": nil, "small": nil, "mark": nil, "abbr": nil, "font": nil,
+	"blockquote": {"cite": true}, "q": {"cite": true}, "cite": nil, "code": nil, "pre": nil, "kbd": nil,
+	"ul": nil, "ol": {"start": true}, "li": nil, "dl": nil, "dt": nil, "dd": nil,
+	"table": nil, "caption": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil,
+	"th": {"colspan": true, "rowspan": true}, "td": {"colspan": true, "rowspan": true},
+	"figure": nil, "figcaption": nil,
+}
+
+// sanitizeDropped 连同内容一起去掉的标签，其中的文字不是正文
+var sanitizeDropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Noscript: true, atom.Template: true, atom.Textarea: true, atom.Select: true,
+	atom.Svg: true, atom.Math: true, atom.Title: true, atom.Head: true,
+}
+
+// sanitizeStyles style 属性中允许的声明及其取值，富文本编辑器用它们设置对齐、颜色和缩进
+var sanitizeStyles = map[string]func(string) bool{
+	"text-align":       oneOf("left", "center", "right", "justify"),
+	"text-decoration":  oneOf("underline", "line-through", "none"),
+	"font-weight":      oneOf("bold", "normal"),
+	"font-style":       oneOf("italic", "normal"),
+	"color":            cssColor,
+	"background-color": cssColor,
+	"text-indent":      cssLength,
+	"padding-left":     cssLength,
+}
+
+// SanitizeHTML 按白名单过滤富文本编辑器保存的 HTML，返回可以直接输出到页面的内容
+//
+// 参数:
+//   - src: 富文本编辑器保存的 HTML
+//
+// 返回值:
+//   - template.HTML: 过滤后的 HTML，标签按 HTML5 规范补全和配对，不会影响页面其余部分的结构
+//
+// 功能说明:
+//   - 只保留 sanitizeTags 中的标签，script、style、iframe 等连同内容一起去掉，其他标签只保留文字
+//   - 属性只保留 title、style 和各标签允许的属性，on* 事件属性、class、id 等一律去掉
+//   - href、src、cite 只允许 http、https 和相对地址，链接另外允许 mailto；其他协议（javascript:、data: 等）整个属性去掉
+//   - style 只保留 sanitizeStyles 中取值合法的声明
+//   - 链接统一加上 rel="nofollow noopener noreferrer"
+//
+// 注意事项:
+//   - 正文保存时不过滤，数据库中仍是编辑器提交的原文；所有直接输出富文本正文的地方都需要经过该函数
+func SanitizeHTML(src string) template.HTML {
+	nodes, err := xhtml.ParseFragment(strings.NewReader(src), &xhtml.Node{
+		Type:     xhtml.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return template.HTML(html.EscapeString(src))
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		sanitizeNode(&b, n)
+	}
+	return template.HTML(b.String())
+}
+
+// sanitizeNode 输出过滤后的节点及其子节点
+func sanitizeNode(b *strings.Builder, n *xhtml.Node) {
+	switch n.Type {
+	case xhtml.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case xhtml.ElementNode:
+	default:
+		// 注释、文档类型等不输出
+		return
+	}
+
+	if sanitizeDropped[n.DataAtom] {
+		return
+	}
+	allowed, ok := sanitizeTags[n.Data]
+	if !ok || n.Namespace != "" {
+		sanitizeChildren(b, n)
+		return
+	}
+
+	b.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !(sanitizeGlobalAttrs[a.Key] || allowed[a.Key]) {
+			continue
+		}
+		v, ok := sanitizeAttr(n.Data, a.Key, a.Val)
+		if ok {
+			b.WriteString(" " + a.Key + `="` + html.EscapeString(v) + `"`)
+		}
+	}
+	if n.Data == "a" {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	b.WriteString(">")
+	if sanitizeVoid(n.DataAtom) {
+		return
+	}
+	sanitizeChildren(b, n)
+	b.WriteString("</" + n.Data + ">")
+}
+
+// sanitizeChildren 依次输出过滤后的子节点
+func sanitizeChildren(b *strings.Builder, n *xhtml.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitizeNode(b, c)
+	}
+}
+
+// sanitizeVoid 判断是否为没有内容和结束标签的空元素
+func sanitizeVoid(a atom.Atom) bool {
+	return a == atom.Br || a == atom.Hr || a == atom.Img
+}
+
+// sanitizeAttr 检查属性的值，返回过滤后的值，不合法时第二个返回值为 false
+func sanitizeAttr(tag, key, val string) (string, bool) {
+	switch key {
+	case "href", "src", "cite":
+		return val, safeURL(val, tag == "a" && key == "href")
+	case "width", "height", "colspan", "rowspan", "start":
+		n, err := strconv.Atoi(strings.TrimSpace(val))
+		return strconv.Itoa(n), err == nil && n >= 0 && n <= 10000
+	case "style":
+		v := sanitizeStyle(val)
+		return v, v != ""
+	}
+	return val, true
+}
+
+// safeURL 判断地址是否只使用 http、https 或相对地址，mailto 为 true 时另外允许 mailto:
+// 解析失败（如含有控制字符）的地址一律拒绝，浏览器会忽略其中的空白字符把它当作 javascript: 执行
+func safeURL(raw string, mailto bool) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return true
+	case "mailto":
+		return mailto
+	}
+	return false
+}
+
+// sanitizeStyle 只保留 style 属性中 sanitizeStyles 允许的声明
+func sanitizeStyle(style string) string {
+	var kept []string
+	for _, decl := range strings.Split(style, ";") {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(decl[:i]))
+		val := strings.ToLower(strings.TrimSpace(decl[i+1:]))
+		if check, ok := sanitizeStyles[prop]; ok && check(val) {
+			kept = append(kept, prop+": "+val)
+		}
+	}
+	return strings.Join(kept, "; ")
+}
+
+// oneOf 返回判断 CSS 取值是否为给定关键字之一的函数
+func oneOf(values ...string) func(string) bool {
+	return func(v string) bool {
+		for _, allowed := range values {
+			if v == allowed {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// cssColor 判断是否为颜色名、#rgb/#rrggbb 或 rgb()/rgba()，不允许 url() 等其他函数
+func cssColor(v string) bool {
+	if strings.HasPrefix(v, "#") {
+		return (len(v) == 4 || len(v) == 7) && strings.Trim(v[1:], "0123456789abcdef") == ""
+	}
+	if strings.HasPrefix(v, "rgb(") || strings.HasPrefix(v, "rgba(") {
+		inner := strings.TrimSuffix(v[strings.IndexByte(v, '(')+1:], ")")
+		return strings.HasSuffix(v, ")") && strings.Trim(inner, "0123456789., %") == ""
+	}
+	return v != "" && strings.Trim(v, "abcdefghijklmnopqrstuvwxyz") == ""
+}
+
+// cssLength 判断是否为带 px、em 或 % 单位的非负长度
+func cssLength(v string) bool {
+	for _, unit := range []string{"px", "em", "%"} {
+		if n := strings.TrimSuffix(v, unit); n != v {
+			f, err := strconv.ParseFloat(n, 64)
+			return err == nil && f >= 0 && f <= 1000
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Package tables 提供数据库表格模型定义
+// 本文件定义全局搜索（pages.SearchPage）的搜索范围，各表格文件在 init 中通过 RegisterSearch 注册自己的搜索字段
+package tables
+
+import (
+	"fmt"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+)
+
+// Searchable 全局搜索中的一个表格
+type Searchable struct {
+	// Key 表格的 URL 前缀，与 Register 注册时相同，搜索结果链接到该表格的记录
+	Key string
+
+	// Title 搜索结果中分组的标题
+	Title string
+
+	// Spec 搜索的字段和结果的显示方式
+	Spec models.SearchSpec
+}
+
+// searchables 注册的搜索范围，按注册的顺序显示
+var searchables []Searchable
+
+// RegisterSearch 把一个表格加入全局搜索，在表格所在文件的 init 中调用
+//
+// 使用示例:
+//
+//	func init() {
+//	    Register("posts", withAudit(GetPostsTable))
+//	    RegisterSearch("posts", "文章", models.SearchSpec{Table: "posts", Columns: []string{"title"}, TitleColumns: []string{"title"}})
+//	}
+//
+// 注意事项:
+//   - 表格必须已经通过 Register 注册，重复注册时 panic
+func RegisterSearch(key, title string, spec models.SearchSpec) {
+	if _, ok := Generators[key]; !ok {
+		panic(fmt.Sprintf("tables: 表格前缀 %q 未注册，不能加入全局搜索", key))
+	}
+	for _, s := range searchables {
+		if s.Key == key {
+			panic(fmt.Sprintf("tables: 表格前缀 %q 重复加入全局搜索", key))
+		}
+	}
+	searchables = append(searchables, Searchable{Key: key, Title: title, Spec: spec})
+}
+
+// Searchables 返回全局搜索的范围
+func Searchables() []Searchable {
+	return searchables
+}
+
+// ScopedSpec 返回当前管理员搜索该表格时使用的配置
+// 与列表相同，非超级管理员在 ownedTables 中的数据表只能搜索到自己创建的记录（见 withOwnership）
+func (s Searchable) ScopedSpec(ctx *context.Context) models.SearchSpec {
+	spec := s.Spec
+	if user := auth.Auth(ctx); !user.IsSuperAdmin() && ownedTables[spec.Table] {
+		spec.OwnerColumn, spec.OwnerID = ownerField, user.Id
+	}
+	return spec
+}
@@ -0,0 +1,46 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/purpose168/GoAdmin-example/models"
+)
+
+func TestSearchScopedToOwner(t *testing.T) {
+	conn := openTestDB(t)
+	const operator = 2
+	for _, owner := range []int{1, operator} {
+		if _, err := conn.Exec(`INSERT INTO users (name, email, created_by) VALUES (?, ?, ?)`,
+			"scoped-search", "scoped@example.com", owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var users Searchable
+	for _, s := range Searchables() {
+		if s.Key == "users" {
+			users = s
+		}
+	}
+
+	cases := []struct {
+		name   string
+		userID int64
+		hits   int
+	}{
+		{"普通管理员只搜索到自己创建的用户", operator, 1},
+		{"超级管理员搜索全部用户", 1, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := testContext(t, conn, c.userID, "/admin/search?q=scoped-search")
+			hits, _, err := models.Search(ctx.Request.Context(), users.ScopedSpec(ctx), "scoped-search", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != c.hits {
+				t.Fatalf("搜索到 %d 条，want %d", len(hits), c.hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现系统设置（settings）表格的模型配置，编辑设置值时的控件随设置项的类型切换
+package tables
+
+import (
+	stdctx "context"
+	"fmt"
+	"html"
+	"html/template"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 settings 前缀
+// 访问路径: /admin/info/settings
+// 功能: 系统设置表格，编辑设置值的控件随类型切换，页面通过 models.Settings 读取设置
+func init() {
+	Register("settings", withAudit(GetSettingsTable))
+}
+
+// GetSettingsTable 获取系统设置表格模型
+// 该函数创建并返回系统设置表格模型，用于在管理后台修改 models.Settings 读取的设置
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 按类型切换控件:
+//   - GoAdmin 的表单字段类型是固定的，这里为每种类型各准备一个编辑控件（value_bool 等，不对应数据库字段），
+//     settingEditorJS 只显示与所选类型对应的控件
+//   - 提交时按类型取出对应控件的值，经 models.NormalizeSettingValue 校验后写入 value 字段
+//   - 保存或删除后清除设置缓存，页面立即读到新的设置，站点标题和 Logo 重新应用到 GoAdmin 配置（见 UseSiteConfig）
+func GetSettingsTable(ctx *context.Context) (settingsTable table.Table) {
+
+	settingsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := settingsTable.GetInfo().SetFilterFormLayout(form.LayoutFilter)
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("键", "key", db.Varchar).FieldSortable().
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("类型", "type", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(settingTypeOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			for _, o := range settingTypeOptions {
+				if o.Value == value.Value {
+					return o.Text
+				}
+			}
+			return value.Value
+		})
+
+	info.AddField("值", "value", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		return settingValueDisplay(fmt.Sprint(value.Row["type"]), value.Value)
+	})
+
+	info.AddField("说明", "description", db.Varchar)
+
+	info.AddField("更新时间", "updated_at", db.Timestamp).FieldSortable()
+
+	info.SetDeleteHookWithRes(func(ids []string, err error) error {
+		models.Settings.Invalidate()
+		applySiteSettings(stdctx.Background())
+		return nil
+	})
+
+	info.SetTable("settings").SetTitle("系统设置").SetDescription("系统设置")
+
+	formList := settingsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("键", "key", db.Varchar, form.Text).FieldMust().FieldDisplayButCanNotEditWhenUpdate()
+
+	formList.AddField("类型", "type", db.Varchar, form.SelectSingle).
+		FieldOptions(settingTypeOptions).FieldDefault(models.SettingText).FieldMust()
+
+	// value 字段隐藏，提交时由 PreProcessFn 填写；放在表单中是为了编辑时读出当前值，供下面的编辑控件显示
+	formList.AddField("值", "value", db.Text, form.Default).FieldHide()
+
+	formList.AddField("值", "value_"+models.SettingBool, db.Varchar, form.Switch).
+		FieldOptions(types.FieldOptions{
+			{Value: "true", Text: "开"},
+			{Value: "false", Text: "关"},
+		}).FieldDefault("false").FieldDisplay(settingEditorValue(models.SettingBool, "false"))
+
+	formList.AddField("值", "value_"+models.SettingNumber, db.Varchar, form.Number).
+		FieldDefault("0").FieldDisplay(settingEditorValue(models.SettingNumber, "0"))
+
+	formList.AddField("值", "value_"+models.SettingColor, db.Varchar, form.Color).
+		FieldDefault("#3c8dbc").FieldDisplay(settingEditorValue(models.SettingColor, "#3c8dbc"))
+
+	formList.AddField("值", "value_"+models.SettingText, db.Varchar, form.Text).
+		FieldDisplay(settingEditorValue(models.SettingText, ""))
+
+	formList.AddField("说明", "description", db.Varchar, form.Text)
+
+	formList.AddJS(settingEditorJS)
+
+	formList.SetPostValidator(func(values form2.Values) error {
+		_, err := models.NormalizeSettingValue(values.Get("type"), values.Get("value_"+values.Get("type")))
+		return err
+	})
+
+	// 校验已通过，这里只取出所选类型对应控件的值
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		typ := values.Get("type")
+		v, _ := models.NormalizeSettingValue(typ, values.Get("value_"+typ))
+		values.Add("value", v)
+		values.Add("updated_at", time.Now().Format("2006-01-02 15:04:05"))
+		return values
+	})
+
+	formList.SetPostHook(func(values form2.Values) error {
+		models.Settings.Invalidate()
+		applySiteSettings(stdctx.Background())
+		return nil
+	})
+
+	formList.SetTable("settings").SetTitle("系统设置").SetDescription("系统设置")
+
+	return
+}
+
+// settingTypeOptions 设置项类型选项，筛选和表单共用
+var settingTypeOptions = types.FieldOptions{
+	{Value: models.SettingBool, Text: "开关"},
+	{Value: models.SettingNumber, Text: "数字"},
+	{Value: models.SettingColor, Text: "颜色"},
+	{Value: models.SettingText, Text: "文本"},
+}
+
+// settingEditorValue 返回编辑控件的 FieldDisplay：设置项是该类型时显示当前值，否则显示 def
+func settingEditorValue(typ, def string) types.FieldFilterFn {
+	return func(value types.FieldModel) interface{} {
+		if fmt.Sprint(value.Row["type"]) == typ {
+			return fmt.Sprint(value.Row["value"])
+		}
+		return def
+	}
+}
+
+// settingValueDisplay 按类型渲染列表中的设置值：开关显示为标签，颜色显示色块
+func settingValueDisplay(typ, value string) interface{} {
+	switch typ {
+	case models.SettingBool:
+		if value == "true" {
+			return template.HTML(`<span class="label label-success">开</span>`)
+		}
+		return template.HTML(`<span class="label label-default">关</span>`)
+	case models.SettingColor:
+		v := html.EscapeString(value)
+		return template.HTML(fmt.Sprintf(`<span style="display:inline-block;width:14px;height:14px;vertical-align:middle;background:%s"></span> %s`, v, v))
+	}
+	return value
+}
+
+// settingEditorJS 只显示与所选类型对应的编辑控件
+// GoAdmin 的颜色控件把当前值放在 placeholder 中、value 为空，不修改颜色直接提交时会提交空值，这里先把当前值填入
+const settingEditorJS = template.JS(`
+(function () {
+    $('input.value_color').val(function (i, v) {
+        return v || $(this).attr('placeholder');
+    }).trigger('change');
+    let types = ['bool', 'number', 'color', 'text'];
+    function toggle() {
+        let current = $('select.type').val() || 'text';
+        types.forEach(function (t) {
+            $("label[for='value_" + t + "']").parent().toggle(t === current);
+        });
+    }
+    $('select.type').on('change', toggle);
+    toggle();
+})();
+`)
+
+// withDefaultPageSize 使用系统设置中的 default_page_size 作为表格默认每页显示的条数
+// 设置项不存在或不是正整数时保留表格自己的设置
+func withDefaultPageSize(gen table.Generator) table.Generator {
+	return func(ctx *context.Context) table.Table {
+		t := gen(ctx)
+		if n := models.Settings.Int(ctx.Request.Context(), models.SettingDefaultPageSize, 0); n > 0 {
+			t.GetInfo().SetDefaultPageSize(n)
+		}
+		return t
+	}
+}
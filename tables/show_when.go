@@ -0,0 +1,84 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表单字段的显示条件：声明“某个字段取某些值时才显示另一个字段”，由生成的 JS 在页面上切换显示
+package tables
+
+import (
+	"encoding/json"
+	"html/template"
+
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// ShowWhen 表单字段的显示条件
+//
+// 使用示例:
+//
+//	// 选择了国家才显示城市
+//	tables.ShowWhen{Field: "city", When: "country"}
+//	// “是否在职”开关打开时才显示公司名称
+//	tables.ShowWhen{Field: "company", When: "employed", Values: []string{"1"}}
+type ShowWhen struct {
+	// Field 按条件显示的字段
+	Field string `json:"field"`
+
+	// When 决定是否显示的字段，可以是文本框、下拉框、单选框或开关
+	When string `json:"when"`
+
+	// Values When 取其中任一值时显示 Field；为空时 When 有值即显示
+	// 开关的值为选项中的 Value，多选字段的值以逗号连接
+	Values []string `json:"values,omitempty"`
+}
+
+// FieldShowWhen 为表格的表单添加显示条件
+//
+// 参数:
+//   - f: 表格的表单，条件的 JS 加在表单底部
+//   - rules: 显示条件，同一个字段有多个条件时需要全部满足
+//
+// 使用示例:
+//
+//	FieldShowWhen(formList, ShowWhen{Field: "city", When: "country"})
+//
+// 注意事项:
+//   - 只控制字段是否显示，隐藏的字段仍然随表单提交，需要时在 SetPreProcessFn 中按条件去掉
+func FieldShowWhen(f *types.FormPanel, rules ...ShowWhen) {
+	f.AddJS(ShowWhenJS(rules...))
+}
+
+// ShowWhenJS 返回按显示条件切换字段的 JS
+// 用于不经过表格模型生成的表单（例如 pages.GetFormContent），表格的表单使用 FieldShowWhen
+func ShowWhenJS(rules ...ShowWhen) template.JS {
+	data, _ := json.Marshal(rules)
+	return template.JS(`
+(function (rules) {
+    function value(name) {
+        let input = $('[name="' + name + '"]');
+        if (input.is(':radio')) {
+            return input.filter(':checked').val() || '';
+        }
+        let v = input.val();
+        if (Array.isArray(v)) {
+            return v.join(',');
+        }
+        return v || '';
+    }
+    function visible(rule) {
+        let v = value(rule.when);
+        return rule.values ? rule.values.indexOf(v) !== -1 : v !== '';
+    }
+    function apply() {
+        let shown = {};
+        rules.forEach(function (rule) {
+            shown[rule.field] = (shown[rule.field] !== false) && visible(rule);
+        });
+        $.each(shown, function (field, show) {
+            $("label[for='" + field + "']").closest('.form-group').toggle(show);
+        });
+    }
+    rules.forEach(function (rule) {
+        $('[name="' + rule.when + '"]').on('change input', apply);
+    });
+    $(apply);
+})(` + string(data) + `);
+`)
+}
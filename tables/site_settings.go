@@ -0,0 +1,130 @@
+// Package tables 提供数据库表格模型定义
+// 本文件使系统设置中的站点标题和 Logo 生效，并提供站点设置页面（pages.SiteSettingsPage）保存设置使用的函数
+package tables
+
+import (
+	stdctx "context"
+	"fmt"
+	"html/template"
+	"log"
+	"sync"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"gorm.io/gorm"
+)
+
+// siteConfig 站点标题和 Logo 生效的 GoAdmin 配置，由 UseSiteConfig 设置
+// title、logo、miniLogo 为设置前的配置，设置项为空时恢复为这些值
+var siteConfig struct {
+	sync.Mutex
+	cfg      *config.Config
+	title    string
+	logo     template.HTML
+	miniLogo template.HTML
+}
+
+// UseSiteConfig 使系统设置中的站点标题（site_title）和 Logo（logo_url）生效
+//
+// 参数:
+//   - ctx: 上下文，读取设置使用
+//   - cfg: GoAdmin 引擎使用的配置，必须是引擎持有的同一个实例，修改后页面立即使用新的标题和 Logo
+//
+// 使用示例:
+//
+//	tables.UseSiteConfig(context.Background(), config.GetService(eng.Services.Get("config")))
+//
+// 注意事项:
+//   - 在 eng.Use 和 models.Init 之后调用，此时 cfg 已合并数据库中 GoAdmin 自带的站点配置
+//   - 之后在系统设置表格或站点设置页面中修改这两个设置项时自动重新应用
+func UseSiteConfig(ctx stdctx.Context, cfg *config.Config) {
+	siteConfig.Lock()
+	siteConfig.cfg = cfg
+	siteConfig.title = cfg.Title
+	siteConfig.logo = cfg.Logo
+	siteConfig.miniLogo = cfg.MiniLogo
+	siteConfig.Unlock()
+
+	applySiteSettings(ctx)
+}
+
+// applySiteSettings 把当前的站点标题和 Logo 写入 GoAdmin 配置，没有调用过 UseSiteConfig 时什么也不做
+// 标题同时作为侧边栏顶部的文字，设置了 Logo 时侧边栏改为显示 Logo 图片
+func applySiteSettings(ctx stdctx.Context) {
+	siteConfig.Lock()
+	defer siteConfig.Unlock()
+
+	cfg := siteConfig.cfg
+	if cfg == nil {
+		return
+	}
+
+	title := models.Settings.String(ctx, models.SettingSiteTitle, "")
+	logoURL := models.Settings.String(ctx, models.SettingLogoURL, "")
+
+	// Config.Update 会重置没有传入的结构体字段（日志、动画等），先取出完整的配置再修改
+	m := cfg.ToMap()
+	m["title"] = siteConfig.title
+	m["logo"] = string(siteConfig.logo)
+	m["mini_logo"] = string(siteConfig.miniLogo)
+	if title != "" {
+		m["title"] = title
+		m["logo"] = template.HTMLEscapeString(title)
+	}
+	if logoURL != "" {
+		img := fmt.Sprintf(`<img src="%s" alt="%s" style="max-height:40px">`,
+			template.HTMLEscapeString(logoURL), template.HTMLEscapeString(m["title"]))
+		m["logo"] = img
+		m["mini_logo"] = img
+	}
+	if err := cfg.Update(m); err != nil {
+		log.Printf("应用站点设置失败: %s\n", err)
+	}
+}
+
+// SaveSettings 修改多个系统设置项，站点设置页面（pages.SiteSettingsPage）提交时调用
+//
+// 参数:
+//   - ctx: 请求上下文，审计日志的修改人取当前登录的管理员
+//   - values: 设置项的键到提交的值
+//
+// 返回值:
+//   - error: 设置项不存在或值无效时返回包装了 models.ErrInvalidSetting 的错误，此时不修改任何设置项
+//
+// 注意事项:
+//   - 修改和审计日志在同一个事务中写入，审计日志与在系统设置表格中修改相同，每个有变化的设置项一条
+//   - 提交后清除设置缓存，并重新应用站点标题和 Logo
+func SaveSettings(ctx *context.Context, values map[string]string) error {
+	err := models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		// 先按键读出主键，取修改前的快照
+		var keys []string
+		for k := range values {
+			keys = append(keys, k)
+		}
+		var ids []string
+		if err := tx.Model(&models.Setting{}).Where(map[string]interface{}{"key": keys}).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		before, err := models.SnapshotRows(txCtx, "settings", "id", ids)
+		if err != nil {
+			return err
+		}
+		changed, err := models.SaveSettings(txCtx, values)
+		if err != nil || len(changed) == 0 {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "settings", "id", changed)
+		if err != nil {
+			return err
+		}
+		return models.CreateAuditLogs(txCtx, auditLogs(ctx, "settings", models.AuditUpdate, changed, before, after))
+	})
+	if err != nil {
+		return err
+	}
+
+	models.Settings.Invalidate()
+	applySiteSettings(ctx.Request.Context())
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表格的软删除：删除只写入 deleted_at，已删除的记录可以筛选出来并恢复
+package tables
+
+import (
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/parameter"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// deletedFilterField 软删除使用的字段，也是"已删除"筛选项的查询参数名
+const deletedFilterField = "deleted_at"
+
+// withSoftDelete 为表格启用软删除
+//
+// 参数:
+//   - ctx: 上下文对象，用于判断当前是否在查看已删除的记录
+//   - info: 表格的信息展示配置
+//   - table: 数据表名，表格关联了其他表时用于限定 deleted_at 所属的表
+//   - model: 对应的 GORM 模型，如 &models.User{}，删除和恢复通过 models.SoftDelete、models.Restore 执行
+//
+// 功能说明:
+//  1. 列表默认只显示 deleted_at 为空的记录
+//  2. 删除操作改为写入删除时间
+//  3. 增加"已删除"筛选项，选中后只列出已删除的记录
+//  4. 列出已删除的记录时，每行显示"恢复"按钮，恢复操作写入审计日志；按创建人限制的表只能恢复自己创建的记录（见 checkOwner）
+func withSoftDelete(ctx *context.Context, info *types.InfoPanel, table string, model interface{}) {
+	// 筛选了 deleted_at 时 GoAdmin 会跳过同名字段上的默认条件，因此两者不会同时生效
+	info.Where(table+"."+deletedFilterField, "IS", nil)
+
+	// 筛选条件为 deleted_at > ''，即不为空：选项的值由 Process 换成空字符串，比较运算符在查询前补上
+	// 不使用 FilterType.Operator，它会在筛选表单中多渲染一个无法隐藏的下拉框
+	info.AddFilter("已删除", deletedFilterField, db.Timestamp, func(param *parameter.Parameters) {
+		if param.GetFieldValue(deletedFilterField) != "" {
+			param.Fields[deletedFilterField+parameter.FilterParamOperatorSuffix] = []string{types.FilterOperatorGreater.Value()}
+		}
+	}, types.FilterType{
+		FormType: form.SelectSingle,
+		Process:  func(string) string { return "" },
+	}).FieldFilterOptions(types.FieldOptions{{Value: "1", Text: "只看已删除"}})
+
+	info.SetDeleteFn(func(ids []string) error {
+		_, err := models.SoftDelete(ctx.Request.Context(), model, ids)
+		return err
+	})
+
+	// 恢复按钮只在已删除的列表中显示
+	if ctx.Query(deletedFilterField) == "" {
+		return
+	}
+	info.AddActionButton(ctx, "恢复", action.Ajax("/admin/"+table+"/restore",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			ids := []string{ctx.FormValue("id")}
+			if err := checkOwner(ctx, table, ids); err != nil {
+				return false, err.Error(), ""
+			}
+			before := snapshot(ctx.Request.Context(), table, "id", ids)
+			n, err := models.Restore(ctx.Request.Context(), model, ids)
+			if err != nil {
+				return false, "恢复失败: " + err.Error(), ""
+			}
+			if n == 0 {
+				return false, "记录未被删除", ""
+			}
+			auditChange(ctx, table, "id", models.AuditRestore, ids, before)
+			return true, "已恢复", ""
+		}))
+}
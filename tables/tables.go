@@ -1,8 +1,13 @@
 // Package tables 提供数据库表格模型定义和生成器映射
-// 本文件定义了所有表格模型的生成器映射，用于路由到对应的表格处理函数
+// 本文件定义了表格生成器的注册表，各表格文件在 init 中通过 Register 注册自己的生成函数
 package tables
 
-import "github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+import (
+	"fmt"
+
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+)
 
 // Generators 表格生成器映射表
 //
@@ -31,33 +36,46 @@ import "github.com/purpose168/GoAdmin/plugins/admin/modules/table"
 //	"authors" => http://localhost:9033/admin/info/authors
 //
 // 使用说明:
-//  1. 添加新表格时，需要在对应的文件中实现表格生成函数
-//  2. 在此映射表中添加新的键值对
+//  1. 添加新表格时，在对应的文件中实现表格生成函数
+//  2. 在同一个文件的 init 中调用 Register 注册，不需要修改本文件
 //  3. 框架会自动根据 URL 前缀路由到对应的生成函数
 //
 // 注意事项:
-//   - 键名必须唯一，不能重复
-//   - 键名建议使用小写字母和下划线
-//   - 生成函数必须符合 table.Generator 类型签名
-//   - 生成函数接收 context.Context 参数，返回 table.Table 对象
-var Generators = map[string]table.Generator{
-	// "posts" 前缀映射到 GetPostsTable 函数
-	// 访问路径: /admin/info/posts
-	// 功能: 文章管理表格，支持富文本编辑、表格关联等功能
-	"posts": GetPostsTable,
-
-	// "users" 前缀映射到 GetUserTable 函数
-	// 访问路径: /admin/info/users
-	// 功能: 用户管理表格
-	"users": GetUserTable,
+//   - 不要直接修改该映射表，通过 Register 注册，main 在所有 init 执行完之后读取
+//   - 对应真实数据表的生成函数使用 withAudit 包装，管理后台的新增、修改、删除会写入 audit_logs
+var Generators = map[string]table.Generator{}
 
-	// "authors" 前缀映射到 GetAuthorsTable 函数
-	// 访问路径: /admin/info/authors
-	// 功能: 作者管理表格，支持自定义按钮和组合字段显示
-	"authors": GetAuthorsTable,
+// Register 注册一个表格生成函数，在表格所在文件的 init 中调用
+//
+// 参数:
+//   - key: 表格的 URL 前缀，建议使用小写字母和下划线
+//   - gen: 表格生成函数
+//
+// 功能说明:
+//   - 生成函数统一使用 withDefaultPageSize 包装，默认每页条数取自系统设置
+//   - 统一添加"PDF"导出按钮，见 withPDFExport
+//   - 允许导出的表格在"导出"下拉菜单中增加 JSON Lines 和 XML，见 withDataExport
+//
+// 使用示例:
+//
+//	func init() {
+//	    Register("tags", withAudit(GetTagsTable))
+//	}
+//
+// 注意事项:
+//   - 前缀为空或重复注册时 panic，启动时即可发现两个文件使用了同一个前缀
+func Register(key string, gen table.Generator) {
+	if key == "" || gen == nil {
+		panic("tables: 注册表格时前缀和生成函数不能为空")
+	}
+	if _, ok := Generators[key]; ok {
+		panic(fmt.Sprintf("tables: 表格前缀 %q 重复注册", key))
+	}
+	Generators[key] = withDataExport(key, withPDFExport(key, withDefaultPageSize(gen)))
+}
 
-	// "profile" 前缀映射到 GetProfileTable 函数
-	// 访问路径: /admin/info/profile
-	// 功能: 用户档案表格，演示多种字段类型（轮播图、进度条、状态点等）
-	"profile": GetProfileTable,
+// defaultDriver 返回 config.yml 中 default 数据库的驱动类型，表格生成函数统一用它创建表格
+// GoAdmin 按驱动类型查找数据库连接，与 models.Init 打开的数据库保持一致，切换到 MySQL 等数据库时不需要修改表格
+func defaultDriver() string {
+	return config.GetDatabases().GetDefault().Driver
 }
@@ -0,0 +1,78 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现标签（tags）表格的模型配置，文章表单从这里维护的标签中选择
+package tables
+
+import (
+	"html"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/db"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/form"
+)
+
+// init 在 Generators 中注册 tags 前缀
+// 访问路径: /admin/info/tags
+// 功能: 文章标签表格，文章表单中可以为文章选择多个标签；标签较多时可以从文件批量导入
+func init() {
+	Register("tags", withImport("tags", withAudit(GetTagsTable)))
+}
+
+// GetTagsTable 获取标签表格模型
+// 该函数创建并返回标签表格模型，用于维护文章可选的标签
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 列表中的标签按其颜色显示，与文章列表中的样式一致
+//   - 删除标签时一并删除文章与该标签的关联，见 models.DeleteTags
+func GetTagsTable(ctx *context.Context) (tagsTable table.Table) {
+
+	tagsTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := tagsTable.GetInfo()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("名称", "name", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike}).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			color, _ := value.Row["color"].(string)
+			return tagChip(value.Value, color)
+		})
+
+	info.AddField("颜色", "color", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		return html.EscapeString(value.Value)
+	})
+
+	info.SetDeleteFn(func(ids []string) error {
+		return models.DeleteTags(ctx.Request.Context(), ids)
+	})
+
+	info.SetTable("tags").SetTitle("标签").SetDescription("文章标签")
+
+	formList := tagsTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("名称", "name", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("颜色", "color", db.Varchar, form.Color).FieldDefault("#3c8dbc")
+
+	formList.SetTable("tags").SetTitle("标签").SetDescription("文章标签")
+
+	return
+}
+
+// tagChip 将标签渲染为带背景色的小标签，名称和颜色均经过转义
+func tagChip(name, color string) string {
+	return `<span class="label" style="background-color:` + html.EscapeString(color) + `">` + html.EscapeString(name) + `</span>`
+}
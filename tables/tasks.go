@@ -0,0 +1,234 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现任务（tasks）表格的模型配置，看板页面（pages.KanbanPage）按状态分列显示这些任务
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/modules/db"
+	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"github.com/purpose168/GoAdmin/template/types/form"
+	"gorm.io/gorm"
+)
+
+// TaskMoveURL 看板中拖动卡片后保存任务状态的地址（在后台 URL 前缀下的路径），需要在 main 中注册 MoveTask
+const TaskMoveURL = "/kanban/move"
+
+// init 在 Generators 中注册 tasks 前缀
+// 访问路径: /admin/info/tasks
+// 功能: 任务表格，列表顶部的"看板"按钮打开按状态分列的看板页面
+func init() {
+	Register("tasks", withAudit(GetTasksTable))
+}
+
+// GetTasksTable 获取任务表格模型
+//
+// 参数:
+//
+//	ctx: 上下文对象，包含请求信息和配置
+//
+// 返回值:
+//
+//	table.Table: 配置好的表格模型对象
+//
+// 功能说明:
+//   - 负责人关联 goadmin_users 显示管理员名称
+//   - 状态也可以在看板中拖动卡片修改，两处的修改都写入审计日志
+func GetTasksTable(ctx *context.Context) (tasksTable table.Table) {
+
+	tasksTable = table.NewDefaultTable(ctx, table.DefaultConfigWithDriver(defaultDriver()))
+
+	info := tasksTable.GetInfo().SetFilterFormLayout(form.LayoutFilter).SetSortDesc()
+
+	info.AddField("编号", "id", db.Int).FieldSortable()
+
+	info.AddField("标题", "title", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("负责人", "name", db.Varchar).FieldJoin(types.Join{
+		Field:     "assignee_id",
+		JoinField: "id",
+		Table:     "goadmin_users",
+	}).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
+	info.AddField("状态", "status", db.Varchar).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(TaskStatusOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return TaskStatusText(value.Value)
+		})
+
+	info.AddField("优先级", "priority", db.Int).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(TaskPriorityOptions).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return TaskPriorityLabel(value.Value)
+		})
+
+	info.AddField("修改时间", "updated_at", db.Timestamp).FieldSortable()
+
+	info.AddButton(ctx, "看板", icon.Th, action.Jump(config.Url("/kanban")))
+
+	info.SetTable("tasks").SetTitle("任务").SetDescription("任务列表")
+
+	formList := tasksTable.GetForm()
+
+	formList.AddField("编号", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+
+	formList.AddField("标题", "title", db.Varchar, form.Text).FieldMust()
+
+	formList.AddField("负责人", "assignee_id", db.Int, form.SelectSingle).
+		FieldOptionsFromTable("goadmin_users", "name", "id")
+
+	formList.AddField("状态", "status", db.Varchar, form.SelectSingle).
+		FieldOptions(TaskStatusOptions).FieldDefault(models.TaskTodo).FieldMust()
+
+	formList.AddField("优先级", "priority", db.Int, form.SelectSingle).
+		FieldOptions(TaskPriorityOptions).FieldDefault(strconv.Itoa(models.TaskPriorityNormal)).FieldMust()
+
+	// 表单按行写入，不会自动填充时间，新建时补上创建时间，修改时更新修改时间
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		now := time.Now().Format("2006-01-02 15:04:05")
+		if values.IsInsertPost() {
+			values.Add("created_at", now)
+		}
+		if !values.IsSingleUpdatePost() {
+			values.Add("updated_at", now)
+		}
+		return values
+	})
+
+	formList.SetTable("tasks").SetTitle("任务").SetDescription("任务列表")
+
+	return
+}
+
+// TaskStatusOptions 任务状态选项，顺序与 models.TaskStatuses 相同，看板的列标题也使用这里的文字
+var TaskStatusOptions = types.FieldOptions{
+	{Value: models.TaskTodo, Text: "待处理"},
+	{Value: models.TaskDoing, Text: "进行中"},
+	{Value: models.TaskDone, Text: "已完成"},
+}
+
+// TaskPriorityOptions 任务优先级选项
+var TaskPriorityOptions = types.FieldOptions{
+	{Value: strconv.Itoa(models.TaskPriorityHigh), Text: "高"},
+	{Value: strconv.Itoa(models.TaskPriorityNormal), Text: "中"},
+	{Value: strconv.Itoa(models.TaskPriorityLow), Text: "低"},
+}
+
+// taskPriorityColors 各优先级在列表和看板中的标签颜色
+var taskPriorityColors = map[string]string{
+	strconv.Itoa(models.TaskPriorityHigh):   "danger",
+	strconv.Itoa(models.TaskPriorityNormal): "warning",
+	strconv.Itoa(models.TaskPriorityLow):    "default",
+}
+
+// TaskStatusText 任务状态的显示文字，未知的状态原样显示
+func TaskStatusText(status string) string {
+	for _, o := range TaskStatusOptions {
+		if o.Value == status {
+			return o.Text
+		}
+	}
+	return status
+}
+
+// TaskPriorityLabel 将任务优先级渲染为带颜色的标签
+func TaskPriorityLabel(priority string) template.HTML {
+	for _, o := range TaskPriorityOptions {
+		if o.Value == priority {
+			return template.HTML(fmt.Sprintf(`<span class="label label-%s">%s</span>`, taskPriorityColors[priority], o.Text))
+		}
+	}
+	return template.HTML(template.HTMLEscapeString(priority))
+}
+
+// MoveTask 保存看板（pages.KanbanPage）中拖动卡片后的任务状态
+//
+// 请求格式:
+//
+//	POST id=3&status=doing
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok"}
+//
+// 注意事项:
+//   - 需要修改任务的权限
+//   - 修改和审计日志在同一个事务中写入，审计日志的操作类型为"修改"，与在任务表单中修改状态相同
+func MoveTask(ctx *context.Context) {
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url("/edit/tasks"), http.MethodPost, url.Values{}) {
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"code": http.StatusForbidden,
+			"msg":  "没有修改任务的权限",
+		})
+		return
+	}
+	id, status := ctx.FormValue("id"), ctx.FormValue("status")
+	if id == "" || status == "" {
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  "缺少 id 或 status",
+		})
+		return
+	}
+
+	err := models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		ids := []string{id}
+		before, err := models.SnapshotRows(txCtx, "tasks", "id", ids)
+		if err != nil {
+			return err
+		}
+		if err := models.MoveTask(txCtx, id, status); err != nil {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "tasks", "id", ids)
+		if err != nil {
+			return err
+		}
+		return models.CreateAuditLogs(txCtx, auditLogs(ctx, "tasks", models.AuditUpdate, ids, before, after))
+	})
+	switch {
+	case errors.Is(err, models.ErrInvalidTaskStatus):
+		ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+		})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, map[string]interface{}{
+			"code": http.StatusNotFound,
+			"msg":  "任务不存在，可能已被删除",
+		})
+		return
+	case err != nil:
+		log.Printf("保存任务 %s 的状态失败: %s\n", id, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code": http.StatusInternalServerError,
+			"msg":  "保存任务状态失败: " + err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+	})
+}
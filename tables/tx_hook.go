@@ -0,0 +1,150 @@
+// Package tables 提供数据库表格模型定义
+// 本文件让表单的写入和提交后的处理在同一个数据库事务中执行
+package tables
+
+import (
+	stdctx "context"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/form"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
+	"github.com/purpose168/GoAdmin/template/types"
+	"gorm.io/gorm"
+)
+
+// txPostHook 事务中执行的表单提交处理
+//
+// 参数:
+//   - ctx: 携带事务的上下文，传给 models 包的写入函数时在同一个事务中执行
+//   - tx: 当前事务
+//   - id: 写入记录的主键，新增时为数据库生成的主键
+//   - values: 表单提交的值，列表中单字段修改时 values.IsSingleUpdatePost 为 true
+//
+// 返回值:
+//   - error: 返回错误时回滚表单的写入，错误信息显示在表单上
+type txPostHook func(ctx stdctx.Context, tx *gorm.DB, id string, values form.Values) error
+
+// savedIDKey 新增记录后在请求的 UserValue 中保存主键的键名，JSON 接口据此返回新记录的编号（见 apiInsertedID）
+const savedIDKey = "tables.saved_id"
+
+// withTxPostHook 让表格的表单写入、审计日志和 hook 在同一个事务中执行
+//
+// 参数:
+//   - ctx: 上下文对象
+//   - t: 表格模型，需在表单字段和数据表配置完成后调用
+//   - hook: 写入记录之后、提交事务之前执行的处理，如创建关联记录
+//
+// 功能说明:
+//  1. 通过 SetUpdateFn、SetInsertFn 接管表单的修改和新增，改为由 models.SaveRow 写入
+//  2. 写入前后在事务中读取快照，审计日志随事务一起提交（withAudit 不再处理这类表单）
+//  3. hook 返回错误时整个事务回滚，表单提交失败并显示错误，不会留下只写了一半的数据
+//
+// 与 SetPostHook 的区别:
+//   - PostHook 在写入提交之后异步执行，返回的错误只记录日志，无法撤销已经写入的数据
+//
+// 注意事项:
+//   - 表单由 ORM 写入，字段名需与数据表的列名一致，表中不存在的字段会被忽略
+//   - 新增记录依赖数据库返回的自增主键，见 models.SaveRow
+func withTxPostHook(ctx *context.Context, t table.Table, hook txPostHook) {
+	var (
+		pk     = t.GetPrimaryKey().Name
+		single bool
+	)
+
+	txForm := func(f *types.FormPanel) {
+		// 传给 UpdateFn 的值已经去掉了单字段更新的标记，在预处理时记下来
+		preProcess := f.PreProcessFn
+		f.SetPreProcessFn(func(values form.Values) form.Values {
+			single = values.IsSingleUpdatePost()
+			if preProcess != nil {
+				return preProcess(values)
+			}
+			return values
+		})
+
+		f.SetUpdateFn(func(values form.Values) error {
+			// 把标记放回去，hook 可以通过 values.IsSingleUpdatePost 区分列表中的单字段修改；formRow 会跳过该标记
+			if single {
+				values.Add(form.PostIsSingleUpdateKey, "1")
+			}
+			return saveInTx(ctx, f, pk, values.Get(pk), formRow(f, pk, values, !single), values, hook)
+		})
+		f.SetInsertFn(func(values form.Values) error {
+			return saveInTx(ctx, f, pk, "", formRow(f, pk, values, true), values, hook)
+		})
+	}
+
+	txForm(t.GetForm())
+	if f := t.GetActualNewForm(); f != t.GetForm() {
+		txForm(f)
+	}
+}
+
+// saveInTx 在一个事务中写入记录、记录审计日志并执行 hook，id 为空时新增记录
+func saveInTx(ctx *context.Context, f *types.FormPanel, pk, id string, row map[string]interface{},
+	values form.Values, hook txPostHook) error {
+	return models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		action := models.AuditCreate
+		var before map[string]map[string]interface{}
+		if id != "" {
+			action = models.AuditUpdate
+			rows, err := models.SnapshotRows(txCtx, f.Table, pk, []string{id})
+			if err != nil {
+				return err
+			}
+			before = rows
+		}
+
+		savedID, err := models.SaveRow(txCtx, f.Table, pk, id, row)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			ctx.SetUserValue(savedIDKey, savedID)
+		}
+
+		after, err := models.SnapshotRows(txCtx, f.Table, pk, []string{savedID})
+		if err != nil {
+			return err
+		}
+		if err := models.CreateAuditLogs(txCtx, auditLogs(ctx, f.Table, action, []string{savedID}, before, after)); err != nil {
+			return err
+		}
+
+		if hook == nil {
+			return nil
+		}
+		return hook(txCtx, tx, savedID, values)
+	})
+}
+
+// formRow 把表单提交的值转换为写入数据表的一行
+// 多个值按字段的 DefaultOptionDelimiter 拼接；fullForm 为 true 时未提交的多选字段视为清空，
+// 与 GoAdmin 默认的写入方式一致，列表中单个字段的修改只写入该字段
+func formRow(f *types.FormPanel, pk string, values form.Values, fullForm bool) map[string]interface{} {
+	row := make(map[string]interface{}, len(values))
+
+	if fullForm {
+		for _, field := range f.FieldList {
+			if field.FormType.IsMultiSelect() {
+				row[field.Field] = ""
+			}
+		}
+	}
+
+	for k, v := range values {
+		k = strings.ReplaceAll(k, "[]", "")
+		if k == pk || strings.HasPrefix(k, "__") {
+			continue
+		}
+		delimiter := ","
+		if field := f.FieldList.FindByFieldName(k); field != nil {
+			delimiter = modules.SetDefault(field.DefaultOptionDelimiter, ",")
+		}
+		row[k] = strings.Join(modules.RemoveBlankFromArray(v), delimiter)
+	}
+	return row
+}
@@ -0,0 +1,200 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现表单上传文件的保存：按 config.yml 的 upload 配置项保存在本地上传目录或 S3 兼容的对象存储中，
+// 返回公开访问的地址；表单页面的"证书"和文章内容中插入的图片都通过它保存
+package tables
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/plugins/admin/modules"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// uploadConfig 上传文件的大小限制和存储方式，由 LoadUploadConfigFromYAML 读取
+	uploadConfig = models.DefaultUploadConfig
+
+	// uploadStorage 保存上传文件的存储，未读取配置时保存在本地上传目录
+	uploadStorage models.Storage
+)
+
+// ErrUploadTooLarge 上传的文件超过 upload 配置项的 max_size
+var ErrUploadTooLarge = errors.New("文件过大")
+
+// LoadUploadConfigFromYAML 从 YAML 配置文件的 upload 配置项读取上传文件的大小限制和存储方式
+//
+// 参数:
+//   - path: 配置文件路径，通常与 GoAdmin 共用 ./config.yml
+//
+// 返回值:
+//   - error: 读取或解析配置失败，或存储配置不完整时返回错误
+//
+// 配置示例:
+//
+//	upload:
+//	  storage: s3
+//	  max_size: 10
+//	  s3:
+//	    endpoint: http://127.0.0.1:9000
+//	    region: us-east-1
+//	    bucket: goadmin
+//
+// 注意事项:
+//   - 需要在 GoAdmin 读取配置之后调用，本地存储使用 store.path 作为上传目录
+//   - 只影响通过 SaveUpload 保存的文件，表格中的 form.File 字段仍由 GoAdmin 的 file_upload_engine 保存
+func LoadUploadConfigFromYAML(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := struct {
+		Upload models.UploadConfig `yaml:"upload"`
+	}{Upload: models.DefaultUploadConfig}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return fmt.Errorf("解析上传配置失败: %v", err)
+	}
+	if cfg.Upload.MaxSize <= 0 {
+		return fmt.Errorf("上传配置的 max_size 应大于 0: %d", cfg.Upload.MaxSize)
+	}
+
+	storage, err := models.NewStorage(cfg.Upload.Storage, cfg.Upload.S3, config.GetStore().Path, "upload")
+	if err != nil {
+		return err
+	}
+	uploadConfig, uploadStorage = cfg.Upload, storage
+	return nil
+}
+
+// SaveUpload 把表单上传的文件保存到配置的存储中
+//
+// 参数:
+//   - ctx: 上下文，保存到 S3 时使用
+//   - dir: 保存的子目录，如 posts；文件名为随机生成的 UUID，保留原扩展名
+//   - fh: 上传的文件
+//
+// 返回值:
+//   - string: 公开访问的地址，本地存储为 /uploads/dir/xxx.ext 形式，S3 为完整的 URL
+//   - error: 超过大小限制时返回 ErrUploadTooLarge，保存失败时返回其他错误
+func SaveUpload(ctx stdctx.Context, dir string, fh *multipart.FileHeader) (string, error) {
+	data, contentType, err := readUpload(fh)
+	if err != nil {
+		return "", err
+	}
+	return putUpload(ctx, dir, path.Ext(fh.Filename), contentType, data)
+}
+
+// putUpload 以随机的文件名保存到 dir 目录，返回公开访问的地址
+func putUpload(ctx stdctx.Context, dir, ext, contentType string, data []byte) (string, error) {
+	storage := uploadStorage
+	if storage == nil {
+		var err error
+		if storage, err = models.NewStorage(uploadConfig.Storage, uploadConfig.S3, config.GetStore().Path, "upload"); err != nil {
+			return "", err
+		}
+	}
+	stored, err := storage.Put(ctx, dir+"/"+modules.Uuid()+strings.ToLower(ext), contentType, data)
+	if err != nil {
+		return "", err
+	}
+	return config.GetStore().URL(stored), nil
+}
+
+// readUpload 读取上传的文件，返回内容和类型
+// 类型按扩展名判断，无法判断时按内容识别
+func readUpload(fh *multipart.FileHeader) ([]byte, string, error) {
+	maxBytes := int64(uploadConfig.MaxSize) << 20
+	if fh.Size > maxBytes {
+		return nil, "", fmt.Errorf("%w: %s 超过 %d MB", ErrUploadTooLarge, fh.Filename, uploadConfig.MaxSize)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(f, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("%w: %s 超过 %d MB", ErrUploadTooLarge, fh.Filename, uploadConfig.MaxSize)
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(path.Ext(fh.Filename)))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// RichTextUploadHandler 返回富文本编辑器上传图片的处理函数，图片保存到配置的存储中
+//
+// 参数:
+//   - dir: 保存的子目录
+//
+// 返回值（JSON，wangEditor 的格式）:
+//   - {"errno": 0, "data": [图片地址...]}
+//   - 没有上传文件时 errno 为 400，超过大小限制时为 413，不是图片时为 415，保存失败时为 500，msg 为原因
+//
+// 使用示例:
+//
+//	formList.AddField("内容", "content", db.Varchar, form.RichText).
+//		FieldEnableFileUpload(formList.OperationURL("/file/upload"), RichTextUploadHandler("posts"))
+//
+// 注意事项:
+//   - 按文件内容判断是否为图片，不接受 SVG，避免在文章中插入脚本
+func RichTextUploadHandler(dir string) context.Handler {
+	return func(ctx *context.Context) {
+		fail := func(errno int, msg string) {
+			ctx.JSON(http.StatusOK, map[string]interface{}{"errno": errno, "msg": msg})
+		}
+		if ctx.Request.MultipartForm == nil {
+			if err := ctx.Request.ParseMultipartForm(32 << 20); err != nil {
+				fail(http.StatusBadRequest, "读取上传的文件失败: "+err.Error())
+				return
+			}
+		}
+		files := ctx.Request.MultipartForm.File["file"]
+		if len(files) == 0 {
+			fail(http.StatusBadRequest, "没有上传文件")
+			return
+		}
+
+		urls := make([]string, 0, len(files))
+		for _, fh := range files {
+			data, _, err := readUpload(fh)
+			if errors.Is(err, ErrUploadTooLarge) {
+				fail(http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			if err != nil {
+				fail(http.StatusInternalServerError, err.Error())
+				return
+			}
+			contentType := http.DetectContentType(data)
+			if !strings.HasPrefix(contentType, "image/") {
+				fail(http.StatusUnsupportedMediaType, fh.Filename+" 不是图片")
+				return
+			}
+			url, err := putUpload(ctx.Request.Context(), dir, path.Ext(fh.Filename), contentType, data)
+			if err != nil {
+				fail(http.StatusInternalServerError, err.Error())
+				return
+			}
+			urls = append(urls, url)
+		}
+		ctx.JSON(http.StatusOK, map[string]interface{}{"errno": 0, "data": urls})
+	}
+}
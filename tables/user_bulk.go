@@ -0,0 +1,216 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现用户列表的批量操作：勾选多个用户后批量启用、禁用、删除或导出
+package tables
+
+import (
+	stdctx "context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+	"github.com/purpose168/GoAdmin/template/icon"
+	"github.com/purpose168/GoAdmin/template/types"
+	"github.com/purpose168/GoAdmin/template/types/action"
+	"gorm.io/gorm"
+)
+
+// userBulkURL 批量操作的地址，所有批量按钮共用，op 为操作名称，ids 为勾选的用户编号（逗号分隔）
+const userBulkURL = "/admin/users/bulk"
+
+// errBadBulkIDs 提交的用户编号不是正整数
+var errBadBulkIDs = errors.New("勾选的用户编号无效")
+
+// userBulkOp 一种批量操作
+type userBulkOp struct {
+	// title、icon 按钮的文字和图标
+	title string
+	icon  string
+
+	// path、method 执行该操作需要的权限，与列表中单条记录的对应操作相同
+	path   string
+	method string
+
+	// audit 写入审计日志的操作类型，为空时不修改数据（导出）
+	audit string
+
+	// apply 在事务中执行操作，返回实际修改的用户数
+	apply func(ctx stdctx.Context, ids []string) (int64, error)
+
+	// done 成功后的提示，%d 为修改的用户数
+	done string
+}
+
+// userBulkOps 批量操作，键为按钮提交的 op
+var userBulkOps = map[string]userBulkOp{
+	"enable": {
+		title: "启用", icon: icon.Check, path: "/edit/users", method: http.MethodPost, audit: models.AuditUpdate,
+		apply: func(ctx stdctx.Context, ids []string) (int64, error) {
+			return models.SetUsersStatus(ctx, ids, models.UserEnabled)
+		},
+		done: "已启用 %d 个用户",
+	},
+	"disable": {
+		title: "禁用", icon: icon.Ban, path: "/edit/users", method: http.MethodPost, audit: models.AuditUpdate,
+		apply: func(ctx stdctx.Context, ids []string) (int64, error) {
+			return models.SetUsersStatus(ctx, ids, models.UserDisabled)
+		},
+		done: "已禁用 %d 个用户",
+	},
+	"delete": {
+		title: "删除", icon: icon.Trash, path: "/delete/users", method: http.MethodPost, audit: models.AuditDelete,
+		apply: func(ctx stdctx.Context, ids []string) (int64, error) {
+			return models.SoftDelete(ctx, &models.User{}, ids)
+		},
+		done: "已删除 %d 个用户",
+	},
+	"export": {
+		title: "导出所选", icon: icon.Download, path: "/info/users", method: http.MethodGet,
+	},
+}
+
+// userBulkOrder 按钮在列表顶部的顺序
+var userBulkOrder = []string{"enable", "disable", "delete", "export"}
+
+// addUserBulkActions 在用户列表顶部添加批量操作按钮
+// 按钮通过 GoAdmin 的 Ajax 操作提交列表中勾选的行（selectedRows），由 bulkUsers 统一处理
+func addUserBulkActions(ctx *context.Context, info *types.InfoPanel) {
+	for _, name := range userBulkOrder {
+		op := userBulkOps[name]
+		act := action.Ajax(userBulkURL, bulkUsers).AddData(map[string]interface{}{"op": name})
+		switch name {
+		case "delete":
+			act = act.WithAlert(action.AlertData{
+				Title:              "确定删除勾选的用户吗？",
+				Type:               "warning",
+				ShowCancelButton:   true,
+				ConfirmButtonColor: "#DD6B55",
+				ConfirmButtonText:  "删除",
+				CancelButtonText:   "取消",
+			}).SetSuccessJS(userBulkDoneJS)
+		case "export":
+			act = act.SetSuccessJS(userBulkExportJS)
+		default:
+			act = act.SetSuccessJS(userBulkDoneJS)
+		}
+		info.AddButton(ctx, template.HTML(op.title), op.icon, act)
+	}
+}
+
+// bulkUsers 执行批量操作
+//
+// 请求参数:
+//   - op: 操作名称，见 userBulkOps
+//   - ids: 勾选的用户编号，逗号分隔
+//
+// 功能说明:
+//   - 检查当前管理员是否有对应的权限，非超级管理员只能操作自己创建的用户（见 checkOwner）
+//   - 启用、禁用和删除在一个事务中执行并为发生变化的用户写入审计日志，任何一步失败时全部回滚
+//   - 导出返回 CSV 的 base64 内容，由 userBulkExportJS 在浏览器中下载
+func bulkUsers(ctx *context.Context) (success bool, msg string, data interface{}) {
+	op, ok := userBulkOps[ctx.FormValue("op")]
+	if !ok {
+		return false, "未知的批量操作", ""
+	}
+	ids, err := parseBulkIDs(ctx.FormValue("ids"))
+	if err != nil {
+		return false, err.Error(), ""
+	}
+	if len(ids) == 0 {
+		return false, "请先勾选用户", ""
+	}
+	if !auth.Auth(ctx).CheckPermissionByUrlMethod(config.Url(op.path), op.method, url.Values{}) {
+		return false, "没有" + op.title + "用户的权限", ""
+	}
+	if err := checkOwner(ctx, "users", ids); err != nil {
+		return false, err.Error(), ""
+	}
+
+	if op.audit == "" {
+		content, err := models.ExportUsersCSV(ctx.Request.Context(), ids)
+		if err != nil {
+			return false, "导出失败: " + err.Error(), ""
+		}
+		return true, "", map[string]string{
+			"filename": "用户-" + time.Now().Format("20060102150405") + ".csv",
+			"content":  base64.StdEncoding.EncodeToString(content),
+		}
+	}
+
+	var n int64
+	err = models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		before, err := models.SnapshotRows(txCtx, "users", "id", ids)
+		if err != nil {
+			return err
+		}
+		if n, err = op.apply(txCtx, ids); err != nil {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "users", "id", ids)
+		if err != nil {
+			return err
+		}
+		// 状态没有变化的用户（如禁用已经禁用的用户）不写审计日志
+		changed := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if !reflect.DeepEqual(before[id], after[id]) {
+				changed = append(changed, id)
+			}
+		}
+		return models.CreateAuditLogs(txCtx, auditLogs(ctx, "users", op.audit, changed, before, after))
+	})
+	if err != nil {
+		return false, op.title + "失败: " + err.Error(), ""
+	}
+	return true, fmt.Sprintf(op.done, n), ""
+}
+
+// parseBulkIDs 解析逗号分隔的用户编号，去掉空项和重复项
+func parseBulkIDs(s string) ([]string, error) {
+	var (
+		ids  = make([]string, 0)
+		seen = make(map[string]bool)
+	)
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, errBadBulkIDs
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// userBulkDoneJS 批量修改完成后刷新列表
+const userBulkDoneJS = template.JS(`if (data.code === 0) {
+    swal(data.msg, '', 'success');
+    $.pjax.reload('#pjax-container');
+} else {
+    swal(data.msg, '', 'error');
+}`)
+
+// userBulkExportJS 把返回的 CSV 作为文件下载
+const userBulkExportJS = template.JS(`if (data.code === 0) {
+    let link = document.createElement('a');
+    link.href = 'data:text/csv;charset=utf-8;base64,' + data.data.content;
+    link.download = data.data.filename;
+    document.body.appendChild(link);
+    link.click();
+    link.remove();
+} else {
+    swal(data.msg, '', 'error');
+}`)
@@ -0,0 +1,137 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现新增用户前的重复检查：提交新增表单前按电话和邮箱查找已有的用户，
+// 找到时提示并可以直接打开已有用户的编辑页面，确认后仍然可以继续新增
+package tables
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
+)
+
+// UserDuplicatesURL 按电话和邮箱查找已有用户的接口地址（在后台 URL 前缀下的路径），需要在 main 中注册 UserDuplicates
+const UserDuplicatesURL = "/users/duplicates"
+
+// UserDuplicates 查找电话或邮箱与新增表单中相同的用户
+//
+// 请求格式:
+//
+//	GET /admin/users/duplicates?phone=电话&email=邮箱
+//
+// 返回格式:
+//
+//	{"code": 200, "msg": "ok", "data": [{"id": 1, "name": "张三", "phone": "...", "email": "...",
+//		"matches": ["电话"], "url": "/admin/info/users/edit?__goadmin_edit_pk=1"}]}
+//
+// 注意事项:
+//   - 只是提示，不阻止新增；邮箱重复时保存仍会被 SetPostValidator 拒绝
+//   - 非超级管理员只能看到自己创建的用户（见 withOwnership），这里同样只查找自己创建的用户
+func UserDuplicates(ctx *context.Context) {
+	var createdBy int64
+	if user := auth.Auth(ctx); !user.IsSuperAdmin() {
+		createdBy = user.Id
+	}
+	phone, email := strings.TrimSpace(ctx.Query("phone")), strings.TrimSpace(ctx.Query("email"))
+	users, err := models.FindDuplicateUsers(ctx.Request.Context(), phone, email, createdBy)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"code": http.StatusInternalServerError,
+			"msg":  "查找重复用户失败: " + err.Error(),
+		})
+		return
+	}
+
+	list := make([]map[string]interface{}, len(users))
+	for i, u := range users {
+		var matches []string
+		if phone != "" && u.Phone == phone {
+			matches = append(matches, "电话")
+		}
+		if email != "" && u.Email == email {
+			matches = append(matches, "邮箱")
+		}
+		list[i] = map[string]interface{}{
+			"id":      u.ID,
+			"name":    u.Name,
+			"phone":   u.Phone,
+			"email":   u.Email,
+			"matches": matches,
+			"url":     config.Url("/info/users/edit?__goadmin_edit_pk=" + strconv.FormatUint(uint64(u.ID), 10)),
+		}
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  "ok",
+		"data": list,
+	})
+}
+
+// userDuplicatesJS 新增用户表单提交前的重复检查
+// 填写了电话或邮箱时先请求 UserDuplicatesURL，没有重复时直接提交；有重复时列出已有的用户，
+// 点击用户打开其编辑页面，"仍然新增"继续提交。检查失败时不阻止提交
+// 编辑表单中同样会加入这段 JS，按表单的提交地址只处理新增表单
+func userDuplicatesJS() template.JS {
+	return template.JS(`
+(function () {
+    let form = $('form[action$="/new/users"]');
+    if (!form.length) {
+        return;
+    }
+    let checked = false;
+
+    function submit() {
+        checked = true;
+        form.trigger('submit');
+    }
+    function escape(s) {
+        return $('<div>').text(s || '').html();
+    }
+
+    // 绑定在表单上，先于 GoAdmin 在 document 上的提交处理执行
+    form.on('submit', function (e) {
+        if (checked) {
+            checked = false;
+            return;
+        }
+        let phone = $.trim(form.find('[name="phone"]').val() || ''), email = $.trim(form.find('[name="email"]').val() || '');
+        if (phone === '' && email === '') {
+            return;
+        }
+        e.preventDefault();
+        e.stopImmediatePropagation();
+        $.ajax({
+            url: '` + config.Url(UserDuplicatesURL) + `',
+            data: {phone: phone, email: email},
+            dataType: 'json'
+        }).done(function (resp) {
+            let list = resp.data || [];
+            if (!list.length) {
+                submit();
+                return;
+            }
+            let html = '<ul style="text-align:left">';
+            $.each(list, function (i, u) {
+                html += '<li><a href="' + escape(u.url) + '">#' + u.id + ' ' + escape(u.name) + '</a>' +
+                    '（' + escape((u.matches || []).join('、')) + '相同：' + escape([u.phone, u.email].filter(Boolean).join(' / ')) + '）</li>';
+            });
+            html += '</ul><p>点击用户打开已有的记录，或者仍然新增。</p>';
+            swal({
+                title: '可能已存在相同的用户',
+                text: html,
+                html: true,
+                type: 'warning',
+                showCancelButton: true,
+                confirmButtonText: '仍然新增',
+                cancelButtonText: '返回修改'
+            }, submit);
+        }).fail(submit);
+    });
+})();
+`)
+}
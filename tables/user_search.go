@@ -0,0 +1,61 @@
+// Package tables 提供数据库表格模型定义
+// 本文件实现用户列表上方的搜索框：输入一次即可同时搜索姓名、电话和城市
+package tables
+
+import (
+	"html/template"
+
+	"github.com/purpose168/GoAdmin-example/models"
+	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/template/types"
+)
+
+// userSearchKey 搜索内容的查询参数
+// 不是表格的字段，GoAdmin 会把它保留在分页、排序和导出的链接中，不会当作筛选条件
+const userSearchKey = "__search"
+
+// withUserSearch 在用户列表上方添加搜索框，并按查询参数中的搜索内容过滤列表
+// 搜索条件与筛选表单的条件同时生效，条件的写法见 models.UserSearchCondition
+func withUserSearch(ctx *context.Context, info *types.InfoPanel) {
+	q := ctx.Query(userSearchKey)
+	if raw, args := models.UserSearchCondition(q); raw != "" {
+		info.WhereRaws = andWhereRaw(info.WhereRaws, raw, args...)
+	}
+
+	info.SetHeaderHtml(info.HeaderHtml + template.HTML(`<form class="user-search-form pull-left" style="margin-right: 10px;">
+    <div class="input-group input-group-sm" style="width: 260px;">
+        <input type="text" name="`+userSearchKey+`" class="form-control" placeholder="搜索姓名、电话或城市" value="`+
+		template.HTMLEscapeString(q)+`">
+        <span class="input-group-btn">
+            <button type="submit" class="btn btn-default"><i class="fa fa-search"></i></button>
+        </span>
+    </div>
+</form>`))
+	info.AddJS(userSearchJS)
+}
+
+// andWhereRaw 把查询条件以 and 追加到表格已有的 WhereRaw 中
+func andWhereRaw(w types.WhereRaw, raw string, args ...interface{}) types.WhereRaw {
+	if w.Raw == "" {
+		return types.WhereRaw{Raw: raw, Args: args}
+	}
+	return types.WhereRaw{Raw: "(" + w.Raw + ") and " + raw, Args: append(append([]interface{}{}, w.Args...), args...)}
+}
+
+// userSearchJS 提交搜索框时保留地址中的筛选、排序和显示的列，回到第一页重新加载列表
+const userSearchJS = template.JS(`
+$(document).off('submit.userSearch').on('submit.userSearch', '.user-search-form', function (e) {
+    e.preventDefault();
+    let params = new URLSearchParams(window.location.search);
+    ['` + userSearchKey + `', '__page', '` + cursorAfter + `', '` + cursorBefore + `'].forEach(function (key) {
+        params.delete(key);
+    });
+    let q = $.trim($(this).find('input[name="` + userSearchKey + `"]').val());
+    if (q !== '') {
+        params.set('` + userSearchKey + `', q);
+    }
+    // 主题按逗号拆分 __columns，保持逗号不被编码
+    let query = params.toString().replace(/%2C/g, ',');
+    $.pjax({url: window.location.pathname + (query ? '?' + query : ''), container: '#pjax-container'});
+});
+`)
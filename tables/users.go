@@ -3,21 +3,47 @@
 package tables
 
 import (
+	stdctx "context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html"
+	template2 "html/template"
+	"strconv"
+	"strings"
+
+	"github.com/purpose168/GoAdmin-example/models"
 
 	"github.com/purpose168/GoAdmin/context"
+	"github.com/purpose168/GoAdmin/modules/auth"
+	"github.com/purpose168/GoAdmin/modules/config"
 	"github.com/purpose168/GoAdmin/modules/db"
 	form2 "github.com/purpose168/GoAdmin/plugins/admin/modules/form"
 	"github.com/purpose168/GoAdmin/plugins/admin/modules/table"
-	"github.com/purpose168/GoAdmin/template"
 	"github.com/purpose168/GoAdmin/template/icon"
 	"github.com/purpose168/GoAdmin/template/types"
 	"github.com/purpose168/GoAdmin/template/types/action"
 	"github.com/purpose168/GoAdmin/template/types/form"
 	selection "github.com/purpose168/GoAdmin/template/types/form/select"
 	editType "github.com/purpose168/GoAdmin/template/types/table"
+	"gorm.io/gorm"
 )
 
+// init 在 Generators 中注册 users 前缀
+// 访问路径: /admin/info/users
+// 功能: 用户管理表格
+// 全局搜索: 按姓名、电话、邮箱和城市搜索
+func init() {
+	Register("users", withAudit(GetUserTable))
+	RegisterSearch("users", "用户", models.SearchSpec{
+		Table:        "users",
+		Columns:      []string{"name", "phone", "email", "city"},
+		TitleColumns: []string{"name"},
+		DetailColumn: "email",
+		SoftDelete:   true,
+	})
+}
+
 // GetUserTable 获取用户表格模型
 // 该函数创建并返回一个配置完整的用户表格模型，用于管理后台的用户信息展示和编辑
 //
@@ -40,20 +66,34 @@ import (
 //   - 自定义表格配置：通过 table.Config 配置表格的各种属性
 //   - 可编辑字段：通过 FieldEditAble 支持列表视图直接编辑
 //   - 开关按钮：通过 editType.Switch 实现开关切换
-//   - 图片显示：通过 Image 组件显示头像
+//   - 头像上传：选择图片后先按 1:1 裁剪，上传后转换为缩略图，按配置保存在本地或 S3（见 avatar_crop.go），列表中通过 Image 组件显示
 //   - 级联选择：通过 FieldOnChooseAjax 实现国家-城市级联选择
 //   - 表单分组：通过 TabGroups 实现表单标签页分组
 //   - 多种操作：Jump、Ajax、PopUp、PopUpWithIframe 等多种操作类型
-//   - 表单钩子：通过 SetPostHook 实现表单提交后的自定义处理
-func GetUserTable(ctx *context.Context) (userTable table.Table) {
+//   - 服务端校验：通过 SetPostValidator 校验姓名、电话和邮箱，不通过时逐个字段显示错误
+//   - 表单钩子：通过 withTxPostHook 在写入用户的同一个事务中执行自定义处理
+//   - 批量操作：勾选多个用户后批量启用、禁用、删除或导出（见 user_bulk.go）
+//   - 搜索框：列表上方的搜索框同时搜索姓名、电话和城市，SQLite 支持 FTS5 时使用全文索引（见 user_search.go）
+//   - 记录归属：非超级管理员只能修改和删除自己创建的用户（见 withOwnership）
+//   - 只读副本：配置了只读副本时列表和导出从副本读取（见 withReadReplica）
+//   - 游标分页：用户数超过系统设置 cursor_pagination_threshold 时列表按编号翻页（见 withCursorPagination）
+func GetUserTable(ctx *context.Context) table.Table {
+	t := withReadReplica(ctx, newUserTable)
+	if largeTable(ctx, "users") {
+		return withCursorPagination(t)
+	}
+	return t
+}
+
+// newUserTable 使用指定的数据库连接创建用户表格模型
+func newUserTable(ctx *context.Context, conn string) (userTable table.Table) {
 
 	// 创建自定义配置的表格模型
 	// table.Config 允许自定义表格的各种配置选项
 	userTable = table.NewDefaultTable(ctx, table.Config{
-		// Driver: 指定数据库驱动类型
-		// db.DriverSqlite: 使用 SQLite 数据库
-		// 其他选项: db.DriverMysql, db.DriverPostgresql, db.DriverMssql
-		Driver: db.DriverSqlite,
+		// Driver: 指定数据库驱动类型，取自 config.yml 中 default 数据库的 driver
+		// 可能的值: db.DriverSqlite, db.DriverMysql, db.DriverPostgresql, db.DriverMssql
+		Driver: defaultDriver(),
 
 		// CanAdd: 是否允许添加新记录
 		// true: 显示"添加"按钮，允许用户添加新记录
@@ -76,9 +116,9 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 		Exportable: true,
 
 		// Connection: 指定数据库连接名称
-		// table.DefaultConnectionName: 使用默认数据库连接
+		// 主库为 table.DefaultConnectionName，读取列表时为只读副本的连接名
 		// 可以在配置文件中定义多个数据库连接，然后在此处指定使用哪个连接
-		Connection: table.DefaultConnectionName,
+		Connection: conn,
 
 		// PrimaryKey: 配置主键信息
 		// Type: 主键数据类型（db.Int 表示整数类型）
@@ -184,6 +224,10 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	// FieldFilterable: 设置该字段可筛选（默认使用精确匹配）
 	info.AddField("电话", "phone", db.Varchar).FieldFilterable()
 
+	// 添加 Email 字段（支持模糊筛选）
+	info.AddField("邮箱", "email", db.Varchar).
+		FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+
 	// 添加 City 字段（支持筛选）
 	// 参数说明:
 	//   - "City": 字段显示名称
@@ -196,19 +240,19 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	// 参数说明:
 	//   - "Avatar": 字段显示名称
 	//   - "avatar": 数据库字段名
-	//   - db.Varchar: 字段数据类型（可变长字符串，存储图片 URL）
+	//   - db.Varchar: 字段数据类型（可变长字符串，存储缩略图的相对路径或 URL）
 	// FieldDisplay: 使用自定义函数显示字段内容
-	//   template.Default().Image(): 创建图片组件
-	//     SetSrc: 设置图片源 URL
-	//     SetHeight: 设置图片高度
-	//     SetWidth: 设置图片宽度
-	//     WithModal: 点击图片时显示模态框（大图预览）
-	//     GetContent: 生成图片的 HTML 内容
-	info.AddField("头像", "avatar", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
-		return template.Default().Image().
-			SetSrc(`//quick.go-admin.cn/demo/assets/dist/img/gopher_avatar.png`).
-			SetHeight("120").SetWidth("120").WithModal().GetContent()
-	})
+	//   avatarImage: 用 Image 组件显示缩略图，点击时在模态框中查看；未上传头像时显示"未上传"
+	info.AddField("头像", "avatar", db.Varchar).FieldDisplay(avatarImage)
+
+	// 添加 Status 字段（启用状态，支持筛选）
+	// 可以在列表中勾选多个用户后通过顶部的"启用"、"禁用"按钮批量修改（见 addUserBulkActions）
+	info.AddField("状态", "status", db.Tinyint).FieldDisplay(func(value types.FieldModel) interface{} {
+		if value.Value == strconv.Itoa(models.UserDisabled) {
+			return template2.HTML(`<span class="label label-default">禁用</span>`)
+		}
+		return template2.HTML(`<span class="label label-success">启用</span>`)
+	}).FieldFilterable(types.FilterType{FormType: form.SelectSingle}).FieldFilterOptions(userStatusOptions())
 
 	// 添加 CreatedAt 字段（时间戳，支持日期范围筛选）
 	// 参数说明:
@@ -258,6 +302,33 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 			return true, "", "<h2>你好世界</h2>"
 		}))
 
+	// 添加重置密码弹窗按钮
+	// 弹窗打开时只带 id，显示新密码和确认密码的输入框；填写后带上 password 和 password_again 再次提交
+	// 新密码的规则与表单相同，修改在一个事务中写入并记录审计日志
+	info.AddActionButton(ctx, "重置密码", action.PopUp("/admin/users/reset_password", "重置密码",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id := ctx.FormValue("id")
+			if err := checkOwner(ctx, "users", []string{id}); err != nil {
+				return false, err.Error(), ""
+			}
+			if !ctx.Request.Form.Has("password") {
+				return true, "", userResetPasswordForm(id)
+			}
+			err := resetUserPassword(ctx, id, ctx.FormValue("password"), ctx.FormValue("password_again"))
+			var verrs models.ValidationErrors
+			switch {
+			case errors.Is(err, models.ErrEmptyPassword), errors.As(err, &verrs):
+				return false, err.Error(), ""
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				return false, "用户不存在", ""
+			case err != nil:
+				return false, "重置密码失败: " + err.Error(), ""
+			}
+			return true, "已重置密码", ""
+		}))
+
+	info.AddJS(userResetPasswordJS)
+
 	// 添加全局操作按钮（表格顶部的操作按钮）
 	// AddButton 在表格顶部添加一个操作按钮
 
@@ -297,7 +368,7 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	//     - "900px": 弹窗宽度
 	//     - "480px": 弹窗高度
 	info.AddButton(ctx, "iframe", icon.Tv, action.PopUpWithIframe("/admin/iframe", "Iframe 示例",
-		action.IframeData{Src: "/admin/info/profile/new"}, "900px", "480px"))
+		action.IframeData{Src: config.Url("/info/profile/new")}, "900px", "480px"))
 
 	// 添加 AJAX 按钮示例（全局）
 	// 参数说明:
@@ -312,6 +383,42 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 			return true, "成功", ""
 		}))
 
+	// 添加 CSV 导入按钮（全局）
+	// 弹窗打开时不带文件，显示上传表单；选择文件后带上 step=preview 提交，返回逐行的校验结果，
+	// 有错误的行标红；确认后带上 step=import 再次提交同一个文件，校验通过的行在一个事务中写入，
+	// 有错误的行不写入，弹窗中提供错误报告下载，修改后可以重新导入
+	info.AddButton(ctx, "导入", icon.Upload, action.PopUp("/admin/users/import", "导入用户",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			step := ctx.FormValue("step")
+			if step == "" {
+				return true, "", userImportForm()
+			}
+			file, _, err := ctx.Request.FormFile("file")
+			if err != nil {
+				return false, "请选择 CSV 文件", ""
+			}
+			defer file.Close()
+			im, err := models.ParseUserCSV(file)
+			if err != nil {
+				return false, err.Error(), ""
+			}
+			if step != "import" {
+				return true, "", userImportPreview(im)
+			}
+			n, err := importUsers(ctx, im)
+			if err != nil {
+				return false, "导入失败: " + err.Error(), ""
+			}
+			return true, fmt.Sprintf("已导入 %d 个用户", n), userImportResult(im, n)
+		}))
+
+	info.AddJS(userImportJS)
+
+	// 添加批量操作按钮
+	// 在列表中勾选多个用户后，可以批量启用、禁用、删除或导出为 CSV
+	// 所有按钮提交到同一个地址，启用、禁用和删除在一个事务中执行（见 bulkUsers）
+	addUserBulkActions(ctx, info)
+
 	// 添加批量选择框（表格顶部的批量操作选择框）
 	// AddSelectBox 添加一个批量选择框，用于批量操作
 	// 参数说明:
@@ -332,6 +439,12 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	// SetDescription: 设置表格描述
 	info.SetTable("users").SetTitle("用户").SetDescription("用户")
 
+	// 启用软删除：删除只写入 deleted_at，可通过"已删除"筛选项找回并恢复
+	withSoftDelete(ctx, info, "users", &models.User{})
+
+	// 添加搜索框：输入一次同时搜索姓名、电话和城市，与筛选条件同时生效（见 withUserSearch）
+	withUserSearch(ctx, info)
+
 	// 获取表单配置对象
 	// GetForm 返回表格的表单配置器，用于配置编辑/添加视图的字段
 	formList := userTable.GetForm()
@@ -378,6 +491,10 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 			{Text: "女", Value: "1"},
 		}).FieldDefault("0")
 
+	// 添加 Status 字段到表单，新用户默认启用
+	formList.AddField("状态", "status", db.Tinyint, form.Radio).
+		FieldOptions(userStatusOptions()).FieldDefault(strconv.Itoa(models.UserEnabled))
+
 	// 添加 Phone 字段到表单
 	// 参数说明:
 	//   - "Phone": 字段显示名称
@@ -386,14 +503,49 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	//   - form.Text: 表单字段类型（文本输入框）
 	formList.AddField("电话", "phone", db.Varchar, form.Text)
 
+	// 添加 Email 字段到表单
+	// form.Email: 邮箱输入框，格式和是否重复在服务端校验（见下方的 SetPostValidator）
+	formList.AddField("邮箱", "email", db.Varchar, form.Email)
+
+	// 新增用户提交前按电话和邮箱查找已有的用户，有重复时提示并可以打开已有的记录（见 UserDuplicates）
+	formList.AddJS(userDuplicatesJS())
+
+	// 添加 Avatar 字段到表单
+	// form.Custom: 头像裁剪控件，选择图片后在浏览器中裁剪，上传到 AvatarUploadURL 生成缩略图，表单只提交缩略图的地址
+	formList.AddField("头像", "avatar", db.Varchar, form.Custom).
+		FieldCustomContent(AvatarCropper("{{.Field}}", "{{.Value}}")).
+		FieldCustomJs(AvatarCropperJS())
+
+	// 添加 Password 和确认密码字段到表单
+	// FieldDisplay 返回空字符串，编辑时不回显已保存的哈希；留空表示不修改密码
+	// FieldPostFilterFn: 写入前把密码替换为 bcrypt 哈希，数据库中不保存明文
+	// password_again 不是 users 表的字段，只用于校验，写入时被忽略
+	formList.AddField("密码", "password", db.Varchar, form.Password).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return ""
+		}).
+		FieldHelpMsg("新增时必填，编辑时留空表示不修改").
+		FieldPostFilterFn(func(value types.PostFieldModel) interface{} {
+			hash, err := models.HashPassword(value.Value.Value())
+			if err != nil {
+				// 校验已限制了密码的长度，这里不会失败；万一失败时不能写入明文
+				panic("hash password failed: " + err.Error())
+			}
+			return hash
+		})
+	formList.AddField("确认密码", "password_again", db.Varchar, form.Password).
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return ""
+		})
+
 	// 添加 Country 字段到表单（单选下拉框，支持级联选择）
 	// 参数说明:
 	//   - "Country": 字段显示名称
-	//   - "country": 数据库字段名
+	//   - "country": 表单字段名，users 表中没有该字段，只用于选择城市
 	//   - db.Tinyint: 字段数据类型
 	//   - form.SelectSingle: 表单字段类型（单选下拉框）
 	// FieldOptions: 设置下拉框选项
-	// FieldDefault: 设置默认值
+	// FieldDisplay: 编辑时按已保存的城市反查国家，新建时不选择国家
 	// FieldOnChooseAjax: 设置级联选择（当选择国家时，动态加载城市列表）
 	//   - "city": 级联字段名（城市字段）
 	//   - "/choose/country": AJAX 请求路由
@@ -404,50 +556,17 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 			{Text: "美国", Value: "1"},
 			{Text: "英国", Value: "2"},
 			{Text: "加拿大", Value: "3"},
-		}).FieldDefault("0").FieldOnChooseAjax("city", "/choose/country",
+		}).FieldDisplay(func(value types.FieldModel) interface{} {
+		return userCountryOf(value.Row["city"])
+	}).FieldOnChooseAjax("city", "/choose/country",
 		func(ctx *context.Context) (bool, string, interface{}) {
-			// 获取用户选择的国家值
-			country := ctx.FormValue("value")
-			// 创建城市选项列表
-			var data = make(selection.Options, 0)
-			// 根据选择的国家返回对应的城市列表
-			switch country {
-			case "0": // 中国
-				data = selection.Options{
-					{Text: "北京", ID: "beijing"},
-					{Text: "上海", ID: "shangHai"},
-					{Text: "广州", ID: "guangZhou"},
-					{Text: "深圳", ID: "shenZhen"},
-				}
-			case "1": // 美国
-				data = selection.Options{
-					{Text: "洛杉矶", ID: "los angeles"},
-					{Text: "华盛顿特区", ID: "washington, dc"},
-					{Text: "纽约", ID: "new york"},
-					{Text: "拉斯维加斯", ID: "las vegas"},
-				}
-			case "2": // 英国
-				data = selection.Options{
-					{Text: "伦敦", ID: "london"},
-					{Text: "剑桥", ID: "cambridge"},
-					{Text: "曼彻斯特", ID: "manchester"},
-					{Text: "利物浦", ID: "liverpool"},
-				}
-			case "3": // 加拿大
-				data = selection.Options{
-					{Text: "温哥华", ID: "vancouver"},
-					{Text: "多伦多", ID: "toronto"},
-				}
-			default: // 默认（中国）
-				data = selection.Options{
-					{Text: "北京", ID: "beijing"},
-					{Text: "上海", ID: "shangHai"},
-					{Text: "广州", ID: "guangZhou"},
-					{Text: "深圳", ID: "shenZhen"},
-				}
+			// 根据选择的国家返回对应的城市列表，未知的国家返回中国的城市
+			cities, ok := userCities[ctx.FormValue("value")]
+			if !ok {
+				cities = userCities["0"]
 			}
 			// 返回成功状态、消息和城市选项列表
-			return true, "ok", data
+			return true, "ok", cities
 		})
 
 	// 添加 City 字段到表单（单选下拉框，动态初始化）
@@ -466,6 +585,9 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 			}
 		})
 
+	// 选择了国家才显示城市（见 ShowWhen）
+	FieldShowWhen(formList, ShowWhen{Field: "city", When: "country"})
+
 	// 添加 Custom Field 字段到表单（自定义字段，带后置过滤函数）
 	// 参数说明:
 	//   - "Custom Field": 字段显示名称
@@ -510,8 +632,8 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	// SetTabHeaders: 设置标签页的标题
 	//   参数: 各个标签页的标题
 	userTable.GetForm().SetTabGroups(types.
-		NewTabGroups("id", "ip", "name", "gender", "country", "city").
-		AddGroup("phone", "role", "created_at", "updated_at")).
+		NewTabGroups("id", "ip", "name", "gender", "status", "avatar", "country", "city", "password", "password_again").
+		AddGroup("phone", "email", "role", "created_at", "updated_at")).
 		SetTabHeaders("档案1", "档案2")
 
 	// 设置表单基本信息
@@ -520,18 +642,291 @@ func GetUserTable(ctx *context.Context) (userTable table.Table) {
 	// SetDescription: 设置表单描述
 	formList.SetTable("users").SetTitle("用户").SetDescription("用户")
 
-	// 设置表单后置钩子
-	// SetPostHook 设置表单提交后的回调函数
+	// 设置服务端校验
+	// 校验在写入之前执行，不通过时不写入，表单上方逐个字段列出错误（见 models.ValidateUser）
+	// 列表中单独修改姓名时只提交了姓名，只校验提交了的字段
+	// 头像在选择后已经由 AvatarUpload 校验并保存为缩略图，这里只检查提交的地址
+	formList.SetPostValidator(func(values form2.Values) error {
+		fields := make(map[string]string)
+		for _, key := range []string{"name", "phone", "email", "password", "password_again"} {
+			if _, ok := values[key]; ok {
+				fields[key] = values.Get(key)
+			}
+		}
+		errs, err := models.ValidateUser(ctx.Request.Context(), values.Get("id"), fields)
+		if err != nil {
+			return err
+		}
+		// 头像只能是裁剪控件上传后返回的地址，或者是未修改的原头像
+		if avatar := values.Get("avatar"); avatar != "" && !ValidAvatarPath(avatar) {
+			current, err := models.UserAvatar(ctx.Request.Context(), values.Get("id"))
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if avatar != current {
+				errs = append(errs, models.FieldError{Field: "avatar", Label: "头像", Message: "无效的头像地址，请重新上传"})
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	})
+
+	// 编辑时密码留空表示不修改：去掉这两个字段，不经过哈希，也不会把已保存的密码覆盖为空
+	// 必须在 withTxPostHook 之前设置，withTxPostHook 会在此基础上包装预处理函数
+	formList.SetPreProcessFn(func(values form2.Values) form2.Values {
+		if values.Get("password") == "" {
+			values.Delete("password")
+			values.Delete("password_again")
+		}
+		return values
+	})
+
+	// 设置事务中的表单钩子
+	// withTxPostHook 让用户记录的写入、审计日志和钩子在同一个事务中执行
+	// ctx stdctx.Context: 携带事务的上下文，传给 models 包的写入函数时在同一个事务中执行
+	// id string: 写入的用户 ID，新增时为数据库生成的 ID
 	// values form2.Values: 表单提交的所有字段值
-	// 返回值: error: 如果返回错误，表单提交失败；如果返回 nil，表单提交成功
-	// 使用场景: 数据验证、数据处理、发送通知等
-	formList.SetPostHook(func(values form2.Values) error {
+	// 返回值: error: 如果返回错误，整个事务回滚，表单提交失败；如果返回 nil，事务提交
+	// 使用场景: 创建关联记录、同步更新其他表等需要与用户记录一起成功或失败的处理
+	withTxPostHook(ctx, userTable, func(ctx stdctx.Context, tx *gorm.DB, id string, values form2.Values) error {
 		// 打印表单提交的值（用于调试）
-		fmt.Println("userTable.GetForm().PostHook", values)
-		// 返回 nil 表示表单提交成功
+		fmt.Println("userTable.GetForm().PostHook", id, values)
+		// 返回 nil 表示提交事务
 		return nil
 	})
 
+	// 非超级管理员只能看到、修改和删除自己创建的用户（见 withOwnership）
+	withOwnership(ctx, userTable)
+
+	// 记住管理员在列选择器中选择的列，下次打开列表时沿用（见 withColumnPreferences）
+	withColumnPreferences(ctx, userTable)
+
 	// 返回配置好的表格模型
 	return
 }
+
+// userCities 各国家的城市选项，键为表单中国家的选项值
+var userCities = map[string]selection.Options{
+	"0": { // 中国
+		{Text: "北京", ID: "beijing"},
+		{Text: "上海", ID: "shangHai"},
+		{Text: "广州", ID: "guangZhou"},
+		{Text: "深圳", ID: "shenZhen"},
+	},
+	"1": { // 美国
+		{Text: "洛杉矶", ID: "los angeles"},
+		{Text: "华盛顿特区", ID: "washington, dc"},
+		{Text: "纽约", ID: "new york"},
+		{Text: "拉斯维加斯", ID: "las vegas"},
+	},
+	"2": { // 英国
+		{Text: "伦敦", ID: "london"},
+		{Text: "剑桥", ID: "cambridge"},
+		{Text: "曼彻斯特", ID: "manchester"},
+		{Text: "利物浦", ID: "liverpool"},
+	},
+	"3": { // 加拿大
+		{Text: "温哥华", ID: "vancouver"},
+		{Text: "多伦多", ID: "toronto"},
+	},
+}
+
+// userCountryOf 返回城市所属国家的选项值，城市为空或不在 userCities 中时返回空字符串
+func userCountryOf(city interface{}) string {
+	var c string
+	switch v := city.(type) {
+	case string:
+		c = v
+	case []byte:
+		c = string(v)
+	}
+	if c == "" {
+		return ""
+	}
+	for country, cities := range userCities {
+		for _, o := range cities {
+			if o.ID == c {
+				return country
+			}
+		}
+	}
+	return ""
+}
+
+// userStatusOptions 启用状态的选项，列表筛选和表单共用
+func userStatusOptions() types.FieldOptions {
+	return types.FieldOptions{
+		{Value: strconv.Itoa(models.UserEnabled), Text: "启用"},
+		{Value: strconv.Itoa(models.UserDisabled), Text: "禁用"},
+	}
+}
+
+// importUsers 在一个事务中写入校验通过的行，并为每个新用户写入一条审计日志，任何一步失败时全部回滚
+func importUsers(ctx *context.Context, im models.UserImport) (int, error) {
+	var n int
+	err := models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		ids, err := models.ImportUsers(txCtx, im, auth.Auth(ctx).Id)
+		if err != nil {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "users", "id", ids)
+		if err != nil {
+			return err
+		}
+		n = len(ids)
+		return models.CreateAuditLogs(txCtx, auditLogs(ctx, "users", models.AuditCreate, ids, nil, after))
+	})
+	return n, err
+}
+
+// resetUserPassword 在一个事务中重置密码并写入审计日志，日志中的密码以摘要显示
+func resetUserPassword(ctx *context.Context, id, password, confirm string) error {
+	return models.Transaction(ctx.Request.Context(), func(txCtx stdctx.Context, tx *gorm.DB) error {
+		ids := []string{id}
+		before, err := models.SnapshotRows(txCtx, "users", "id", ids)
+		if err != nil {
+			return err
+		}
+		if err := models.ResetUserPassword(txCtx, id, password, confirm); err != nil {
+			return err
+		}
+		after, err := models.SnapshotRows(txCtx, "users", "id", ids)
+		if err != nil {
+			return err
+		}
+		return models.CreateAuditLogs(txCtx, auditLogs(ctx, "users", models.AuditUpdate, ids, before, after))
+	})
+}
+
+// userResetPasswordForm 渲染"重置密码"弹窗的内容，填写后向同一个地址提交 id、password 和 password_again
+func userResetPasswordForm(id string) template2.HTML {
+	return template2.HTML(fmt.Sprintf(`<div class="user-reset-password" style="padding:10px" data-id="%s" data-url="%s">
+  <input type="password" class="form-control user-password" placeholder="新密码，至少 8 个字符" autocomplete="new-password">
+  <input type="password" class="form-control user-password-again" style="margin-top:10px" placeholder="确认密码" autocomplete="new-password">
+  <button type="button" class="btn btn-primary user-reset-password-submit" style="margin-top:10px">保存</button>
+</div>`, html.EscapeString(id), action.URL("/admin/users/reset_password")))
+}
+
+// userResetPasswordJS "重置密码"弹窗的提交
+const userResetPasswordJS = template2.JS(`
+$(document).off('click.userResetPassword').on('click.userResetPassword', '.user-reset-password-submit', function () {
+    let box = $(this).closest('.user-reset-password');
+    $.ajax({
+        method: 'post',
+        url: box.data('url'),
+        data: {
+            id: box.data('id'),
+            password: box.find('.user-password').val(),
+            password_again: box.find('.user-password-again').val()
+        },
+        success: function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            if (data.code === 0) {
+                $('.modal').modal('hide');
+                swal(data.msg, '', 'success');
+            } else {
+                swal(data.msg, '', 'error');
+            }
+        },
+        error: function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '重置密码失败', '', 'error');
+        }
+    });
+});
+`)
+
+// userImportForm 渲染"导入"弹窗的上传表单，预览和导入的结果显示在表单下方
+func userImportForm() template2.HTML {
+	return template2.HTML(fmt.Sprintf(`<div class="user-import" style="padding:10px" data-url="%s">
+  <p class="text-muted">CSV 文件使用 UTF-8 编码，第一行为表头：姓名、性别、城市、IP、电话（也可以使用 name、gender、city、ip、phone），
+  姓名必填，性别填写 男 或 女，一次最多导入 %d 行</p>
+  <input type="file" class="user-import-file" accept=".csv,text/csv">
+  <button type="button" class="btn btn-default user-import-preview" style="margin-top:10px">预览</button>
+  <div class="user-import-result" style="margin-top:10px;max-height:420px;overflow:auto"></div>
+</div>`, action.URL("/admin/users/import"), models.MaxUserImportRows))
+}
+
+// userImportPreview 渲染校验结果，有错误的行标红并列出错误；存在校验通过的行时显示"导入"按钮
+func userImportPreview(im models.UserImport) template2.HTML {
+	var b strings.Builder
+	valid := im.ValidCount()
+	fmt.Fprintf(&b, `<p>共 %d 行，校验通过 <b class="text-green">%d</b> 行，有错误 <b class="text-red">%d</b> 行</p>`,
+		len(im.Rows), valid, len(im.Rows)-valid)
+	b.WriteString(`<table class="table table-bordered table-condensed"><thead><tr><th>行号</th><th>姓名</th><th>性别</th><th>城市</th><th>IP</th><th>电话</th><th>错误</th></tr></thead><tbody>`)
+	for _, r := range im.Rows {
+		class, gender := "", "男"
+		if !r.Valid() {
+			class = ` class="danger"`
+		}
+		if r.User.Gender == 1 {
+			gender = "女"
+		}
+		fmt.Fprintf(&b, `<tr%s><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td class="text-red">%s</td></tr>`,
+			class, r.Line, html.EscapeString(r.User.Name), gender, html.EscapeString(r.User.City),
+			html.EscapeString(r.User.IP), html.EscapeString(r.User.Phone), html.EscapeString(strings.Join(r.Errors, "；")))
+	}
+	b.WriteString(`</tbody></table>`)
+	if valid > 0 {
+		fmt.Fprintf(&b, `<button type="button" class="btn btn-primary user-import-submit">导入 %d 行</button>`, valid)
+	}
+	return template2.HTML(b.String())
+}
+
+// userImportResult 渲染导入结果，有错误的行时附上错误报告的下载链接
+// 报告直接编码在链接中，不需要在服务端保存文件
+func userImportResult(im models.UserImport, n int) template2.HTML {
+	failed := len(im.Rows) - n
+	if failed == 0 {
+		return template2.HTML(fmt.Sprintf(`<p class="text-green">已导入 %d 个用户</p>`, n))
+	}
+	return template2.HTML(fmt.Sprintf(`<p>已导入 %d 个用户，%d 行有错误未导入。</p>
+<a class="btn btn-default" download="用户导入错误报告.csv" href="data:text/csv;charset=utf-8;base64,%s"><i class="fa fa-download"></i> 下载错误报告</a>`,
+		n, failed, base64.StdEncoding.EncodeToString(im.ErrorReport())))
+}
+
+// userImportJS "导入"弹窗的预览和提交，两步都上传同一个文件，导入成功后刷新列表
+const userImportJS = template2.JS(`
+$(document).off('click.userImport').on('click.userImport', '.user-import-preview, .user-import-submit', function () {
+    let box = $(this).closest('.user-import');
+    let file = box.find('.user-import-file')[0].files[0];
+    if (!file) {
+        swal('请选择 CSV 文件', '', 'warning');
+        return;
+    }
+    let step = $(this).hasClass('user-import-submit') ? 'import' : 'preview';
+    let fd = new FormData();
+    fd.append('file', file);
+    fd.append('step', step);
+    $.ajax({
+        method: 'post',
+        url: box.data('url'),
+        data: fd,
+        processData: false,
+        contentType: false,
+        success: function (data) {
+            if (typeof (data) === "string") {
+                data = JSON.parse(data);
+            }
+            if (data.code !== 0) {
+                swal(data.msg, '', 'error');
+                return;
+            }
+            box.find('.user-import-result').html(data.data);
+            if (step === 'import') {
+                swal(data.msg, '', 'success');
+                $.pjax.reload('#pjax-container');
+            }
+        },
+        error: function (data) {
+            swal(data.responseJSON ? data.responseJSON.msg : '导入失败', '', 'error');
+        }
+    });
+});
+$(document).off('change.userImport').on('change.userImport', '.user-import-file', function () {
+    $(this).closest('.user-import').find('.user-import-result').empty();
+});
+`)